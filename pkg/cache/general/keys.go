@@ -58,6 +58,14 @@ var (
 	KubeWorkloadKey = newGeneralKey(KubeWorkload, 6*time.Hour, [2]int{0, 30 * 60})
 	// KubePodKey is the  detail cache key
 	KubePodKey = newGeneralKey(KubePod, 6*time.Hour, [2]int{0, 30 * 60})
+	// KubeServiceKey is the kube service detail cache key
+	KubeServiceKey = newGeneralKey(KubeService, 6*time.Hour, [2]int{0, 30 * 60})
+	// KubeIngressKey is the kube ingress detail cache key
+	KubeIngressKey = newGeneralKey(KubeIngress, 6*time.Hour, [2]int{0, 30 * 60})
+	// KubeConfigMapKey is the kube configMap detail cache key
+	KubeConfigMapKey = newGeneralKey(KubeConfigMap, 6*time.Hour, [2]int{0, 30 * 60})
+	// KubePersistentVolumeClaimKey is the kube persistentVolumeClaim detail cache key
+	KubePersistentVolumeClaimKey = newGeneralKey(KubePersistentVolumeClaim, 6*time.Hour, [2]int{0, 30 * 60})
 )
 
 // newGeneralKey new general Key
@@ -79,24 +87,28 @@ func newGeneralKey(resource ResType, expireSeconds time.Duration, expireRangeSec
 }
 
 var cacheKeyMap = map[ResType]*Key{
-	Host:             HostKey,
-	ModuleHostRel:    ModuleHostRelKey,
-	Biz:              BizKey,
-	Set:              SetKey,
-	Module:           ModuleKey,
-	Process:          ProcessKey,
-	ProcessRelation:  ProcessRelationKey,
-	BizSet:           BizSetKey,
-	Plat:             PlatKey,
-	Project:          ProjectKey,
-	ObjectInstance:   ObjInstKey,
-	MainlineInstance: MainlineInstKey,
-	InstAsst:         InstAsstKey,
-	KubeCluster:      KubeClusterKey,
-	KubeNode:         KubeNodeKey,
-	KubeNamespace:    KubeNamespaceKey,
-	KubeWorkload:     KubeWorkloadKey,
-	KubePod:          KubePodKey,
+	Host:                      HostKey,
+	ModuleHostRel:             ModuleHostRelKey,
+	Biz:                       BizKey,
+	Set:                       SetKey,
+	Module:                    ModuleKey,
+	Process:                   ProcessKey,
+	ProcessRelation:           ProcessRelationKey,
+	BizSet:                    BizSetKey,
+	Plat:                      PlatKey,
+	Project:                   ProjectKey,
+	ObjectInstance:            ObjInstKey,
+	MainlineInstance:          MainlineInstKey,
+	InstAsst:                  InstAsstKey,
+	KubeCluster:               KubeClusterKey,
+	KubeNode:                  KubeNodeKey,
+	KubeNamespace:             KubeNamespaceKey,
+	KubeWorkload:              KubeWorkloadKey,
+	KubePod:                   KubePodKey,
+	KubeService:               KubeServiceKey,
+	KubeIngress:               KubeIngressKey,
+	KubeConfigMap:             KubeConfigMapKey,
+	KubePersistentVolumeClaim: KubePersistentVolumeClaimKey,
 }
 
 // GetCacheKeyByResType get general resource detail cache key by resource type
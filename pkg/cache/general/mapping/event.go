@@ -24,24 +24,28 @@ import (
 )
 
 var cursorTypeMap = map[general.ResType]watch.CursorType{
-	general.Host:             watch.Host,
-	general.ModuleHostRel:    watch.ModuleHostRelation,
-	general.Biz:              watch.Biz,
-	general.Set:              watch.Set,
-	general.Module:           watch.Module,
-	general.Process:          watch.Process,
-	general.ProcessRelation:  watch.ProcessInstanceRelation,
-	general.BizSet:           watch.BizSet,
-	general.Plat:             watch.Plat,
-	general.Project:          watch.Project,
-	general.ObjectInstance:   watch.ObjectBase,
-	general.MainlineInstance: watch.MainlineInstance,
-	general.InstAsst:         watch.InstAsst,
-	general.KubeCluster:      watch.KubeCluster,
-	general.KubeNode:         watch.KubeNode,
-	general.KubeNamespace:    watch.KubeNamespace,
-	general.KubeWorkload:     watch.KubeWorkload,
-	general.KubePod:          watch.KubePod,
+	general.Host:                      watch.Host,
+	general.ModuleHostRel:             watch.ModuleHostRelation,
+	general.Biz:                       watch.Biz,
+	general.Set:                       watch.Set,
+	general.Module:                    watch.Module,
+	general.Process:                   watch.Process,
+	general.ProcessRelation:           watch.ProcessInstanceRelation,
+	general.BizSet:                    watch.BizSet,
+	general.Plat:                      watch.Plat,
+	general.Project:                   watch.Project,
+	general.ObjectInstance:            watch.ObjectBase,
+	general.MainlineInstance:          watch.MainlineInstance,
+	general.InstAsst:                  watch.InstAsst,
+	general.KubeCluster:               watch.KubeCluster,
+	general.KubeNode:                  watch.KubeNode,
+	general.KubeNamespace:             watch.KubeNamespace,
+	general.KubeWorkload:              watch.KubeWorkload,
+	general.KubePod:                   watch.KubePod,
+	general.KubeService:               watch.KubeService,
+	general.KubeIngress:               watch.KubeIngress,
+	general.KubeConfigMap:             watch.KubeConfigMap,
+	general.KubePersistentVolumeClaim: watch.KubePersistentVolumeClaim,
 }
 
 // GetCursorTypeByResType get event watch cursor type by resource type
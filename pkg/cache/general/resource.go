@@ -61,6 +61,14 @@ const (
 	KubeWorkload ResType = "kube_workload"
 	// KubePod is the resource type for kube pod cache, its event detail is pod info with containers in it
 	KubePod ResType = "kube_pod"
+	// KubeService is the resource type for kube service cache
+	KubeService ResType = "kube_service"
+	// KubeIngress is the resource type for kube ingress cache
+	KubeIngress ResType = "kube_ingress"
+	// KubeConfigMap is the resource type for kube configMap cache
+	KubeConfigMap ResType = "kube_config_map"
+	// KubePersistentVolumeClaim is the resource type for kube persistentVolumeClaim cache
+	KubePersistentVolumeClaim ResType = "kube_persistent_volume_claim"
 )
 
 // SupportedResTypeMap is a map whose key is resource type that is supported by general resource cache
@@ -0,0 +1,383 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package subscription defines the resource change event webhook subscription related types
+package subscription
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+)
+
+const (
+	// BKTableNameEventSubscription is the event webhook subscription table
+	BKTableNameEventSubscription = "cc_EventSubscription"
+	// BKTableNameEventDelivery is the event webhook delivery history table
+	BKTableNameEventDelivery = "cc_EventDelivery"
+	// BKTableNameEventDeadLetter is the table that stores the events that are failed to deliver after all retries
+	BKTableNameEventDeadLetter = "cc_EventDeadLetter"
+)
+
+// subscription related field names
+const (
+	IDField             = "id"
+	NameField           = "name"
+	CallbackURLField    = "callback_url"
+	ResourcesField      = "resources"
+	EventTypesField     = "event_types"
+	FilterField         = "filter"
+	SecretField         = "secret"
+	BatchSizeField      = "batch_size"
+	MaxRetryField       = "max_retry"
+	TimeoutField        = "timeout"
+	EnabledField        = "enabled"
+	CursorsField        = "cursors"
+	SubscriptionIDField = "subscription_id"
+	StatusField         = "status"
+	ResourceField       = "resource"
+	DeliveryIDField     = "delivery_id"
+	ErrorField          = "error"
+)
+
+// webhook request headers
+const (
+	// HeaderSubscriptionID is the header of the subscription id
+	HeaderSubscriptionID = "X-Bkcmdb-Subscription-Id"
+	// HeaderDeliveryID is the header of the delivery id, it's the same for all the retries of one delivery
+	HeaderDeliveryID = "X-Bkcmdb-Delivery-Id"
+	// HeaderTimestamp is the header of the unix timestamp when the request is signed
+	HeaderTimestamp = "X-Bkcmdb-Timestamp"
+	// HeaderSignature is the header of the signature, its value is 'sha256=' + hex(hmac-sha256(secret,
+	// timestamp + '.' + body)), the receiver should verify it with the subscription secret
+	HeaderSignature = "X-Bkcmdb-Signature"
+)
+
+// subscription option limits and defaults
+const (
+	DefaultBatchSize = 50
+	MaxBatchSize     = 500
+	DefaultMaxRetry  = 3
+	MaxRetryLimit    = 10
+	DefaultTimeout   = 10
+	MaxTimeout       = 60
+	// MaxSubscriptionLimit is the max number of subscriptions of a supplier account
+	MaxSubscriptionLimit = 100
+	// MaxRedeliverLimit is the max number of dead letters that can be redelivered at once
+	MaxRedeliverLimit = 100
+)
+
+// SubscriptionData is the user defined config of a subscription
+type SubscriptionData struct {
+	Name string `json:"name" bson:"name"`
+	// CallbackURL is the http endpoint that receives the events
+	CallbackURL string `json:"callback_url" bson:"callback_url"`
+	// Resources is the resources to watch
+	Resources []watch.CursorType `json:"resources" bson:"resources"`
+	// EventTypes is the event types to push, empty means all
+	EventTypes []watch.EventType `json:"event_types" bson:"event_types"`
+	// Filter filters the events by the event detail, the rule field is the json path of the event detail field
+	Filter *filter.Expression `json:"filter,omitempty" bson:"filter,omitempty"`
+	// Secret is used to sign the request so that the receiver can verify it, it is never returned
+	Secret string `json:"secret,omitempty" bson:"secret"`
+	// BatchSize is the max number of events in one request
+	BatchSize int `json:"batch_size" bson:"batch_size"`
+	// MaxRetry is the max retry times of a failed request, the events are stored as dead letters afterwards
+	MaxRetry int `json:"max_retry" bson:"max_retry"`
+	// Timeout is the request timeout in seconds
+	Timeout int `json:"timeout" bson:"timeout"`
+	// Enabled defines whether the events are pushed
+	Enabled bool `json:"enabled" bson:"enabled"`
+}
+
+// Subscription is the event webhook subscription
+type Subscription struct {
+	ID               int64 `json:"id" bson:"id"`
+	SubscriptionData `json:",inline" bson:",inline"`
+	// Cursors is the map of resource to the cursor of the last delivered event
+	Cursors         map[watch.CursorType]string `json:"cursors" bson:"cursors"`
+	SupplierAccount string                      `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator         string                      `json:"creator" bson:"creator"`
+	Modifier        string                      `json:"modifier" bson:"modifier"`
+	CreateTime      time.Time                   `json:"create_time" bson:"create_time"`
+	LastTime        time.Time                   `json:"last_time" bson:"last_time"`
+}
+
+// Validate SubscriptionData, and set the default values
+func (s *SubscriptionData) Validate(isUpdate bool) ccErr.RawErrorInfo {
+	if s.Name == "" || len(s.Name) > common.NameFieldMaxLength {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{NameField}}
+	}
+
+	callbackURL, err := url.Parse(s.CallbackURL)
+	if err != nil || (callbackURL.Scheme != "http" && callbackURL.Scheme != "https") || callbackURL.Host == "" {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{CallbackURLField}}
+	}
+
+	if len(s.Resources) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{ResourcesField}}
+	}
+
+	supported := make(map[watch.CursorType]bool)
+	for _, resource := range watch.ListCursorTypes() {
+		supported[resource] = true
+	}
+	for _, resource := range s.Resources {
+		if !supported[resource] {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+				Args: []interface{}{fmt.Sprintf("%s(%s)", ResourcesField, resource)}}
+		}
+		// mark as not supported to check the duplicated resources
+		supported[resource] = false
+	}
+
+	for _, eventType := range s.EventTypes {
+		if err := eventType.Validate(); err != nil {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{EventTypesField}}
+		}
+	}
+
+	if s.Filter != nil {
+		validateOpt := filter.NewDefaultExprOpt(make(map[string]enumor.FieldType))
+		validateOpt.IgnoreRuleFields = true
+		if err := s.Filter.Validate(validateOpt); err != nil {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+				Args: []interface{}{fmt.Sprintf("%s is invalid, err: %v", FilterField, err)}}
+		}
+	}
+
+	// secret is not changed if it is not set when updating
+	if (!isUpdate && s.Secret == "") || len(s.Secret) > 256 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{SecretField}}
+	}
+
+	if s.BatchSize == 0 {
+		s.BatchSize = DefaultBatchSize
+	}
+	if s.BatchSize < 0 || s.BatchSize > MaxBatchSize {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{BatchSizeField}}
+	}
+
+	if s.MaxRetry < 0 || s.MaxRetry > MaxRetryLimit {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{MaxRetryField}}
+	}
+
+	if s.Timeout == 0 {
+		s.Timeout = DefaultTimeout
+	}
+	if s.Timeout < 0 || s.Timeout > MaxTimeout {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{TimeoutField}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// Match checks if the event should be pushed to the subscription
+func (s *SubscriptionData) Match(event *watch.WatchEventDetail) (bool, error) {
+	if len(s.EventTypes) > 0 {
+		matched := false
+		for _, eventType := range s.EventTypes {
+			if eventType == event.EventType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if s.Filter == nil || s.Filter.RuleFactory == nil {
+		return true, nil
+	}
+
+	detail, ok := event.Detail.(watch.JsonString)
+	if !ok || len(detail) == 0 {
+		return false, nil
+	}
+	return s.Filter.Match(filter.JsonString(detail))
+}
+
+// UpdateSubscriptionOpt is the event subscription update option
+type UpdateSubscriptionOpt struct {
+	ID   int64             `json:"id"`
+	Data *SubscriptionData `json:"data"`
+}
+
+// Validate UpdateSubscriptionOpt
+func (o *UpdateSubscriptionOpt) Validate() ccErr.RawErrorInfo {
+	if o.ID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{IDField}}
+	}
+
+	if o.Data == nil {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"data"}}
+	}
+
+	return o.Data.Validate(true)
+}
+
+// DeleteSubscriptionOpt is the event subscription delete option
+type DeleteSubscriptionOpt struct {
+	ID int64 `json:"id"`
+}
+
+// Validate DeleteSubscriptionOpt
+func (o *DeleteSubscriptionOpt) Validate() ccErr.RawErrorInfo {
+	if o.ID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{IDField}}
+	}
+	return ccErr.RawErrorInfo{}
+}
+
+// ListSubscriptionOpt is the event subscription list option
+type ListSubscriptionOpt struct {
+	IDs  []int64           `json:"ids"`
+	Page metadata.BasePage `json:"page"`
+}
+
+// Validate ListSubscriptionOpt
+func (o *ListSubscriptionOpt) Validate() ccErr.RawErrorInfo {
+	if len(o.IDs) > common.BKMaxPageSize {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrExceedMaxOperationRecordsAtOnce,
+			Args: []interface{}{common.BKMaxPageSize}}
+	}
+	return o.Page.ValidateWithEnableCount(false, common.BKMaxPageSize)
+}
+
+// ListSubscriptionResult is the event subscription list result
+type ListSubscriptionResult struct {
+	Count uint64         `json:"count"`
+	Info  []Subscription `json:"info"`
+}
+
+// DeliveryStatus is the status of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliverySuccess means the events are delivered successfully
+	DeliverySuccess DeliveryStatus = "success"
+	// DeliveryFailed means the events are failed to deliver after all retries, they're stored as dead letters
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is the delivery history of a batch of events
+type Delivery struct {
+	ID             int64            `json:"id" bson:"id"`
+	SubscriptionID int64            `json:"subscription_id" bson:"subscription_id"`
+	Resource       watch.CursorType `json:"resource" bson:"resource"`
+	Status         DeliveryStatus   `json:"status" bson:"status"`
+	EventCount     int              `json:"event_count" bson:"event_count"`
+	// StartCursor and EndCursor are the cursors of the first and the last event in the delivery
+	StartCursor string `json:"start_cursor" bson:"start_cursor"`
+	EndCursor   string `json:"end_cursor" bson:"end_cursor"`
+	// Attempts is the number of requests that are sent
+	Attempts int `json:"attempts" bson:"attempts"`
+	// StatusCode is the http status code of the last request, 0 means no response is received
+	StatusCode int `json:"status_code" bson:"status_code"`
+	// Error is the error message of the last request
+	Error string `json:"error" bson:"error"`
+	// Duration is the total time cost of the delivery in milliseconds
+	Duration        int64     `json:"duration" bson:"duration"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+}
+
+// DeadLetter is the events that are failed to deliver after all retries
+type DeadLetter struct {
+	ID             int64            `json:"id" bson:"id"`
+	SubscriptionID int64            `json:"subscription_id" bson:"subscription_id"`
+	DeliveryID     int64            `json:"delivery_id" bson:"delivery_id"`
+	Resource       watch.CursorType `json:"resource" bson:"resource"`
+	// Payload is the request body of the failed delivery
+	Payload         string    `json:"payload" bson:"payload"`
+	Error           string    `json:"error" bson:"error"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+}
+
+// Payload is the webhook request body
+type Payload struct {
+	SubscriptionID int64                     `json:"subscription_id"`
+	DeliveryID     int64                     `json:"delivery_id"`
+	Resource       watch.CursorType          `json:"resource"`
+	Events         []*watch.WatchEventDetail `json:"events"`
+}
+
+// ListDeliveryOpt is the delivery history or dead letter list option
+type ListDeliveryOpt struct {
+	SubscriptionID int64             `json:"subscription_id"`
+	Status         DeliveryStatus    `json:"status,omitempty"`
+	Page           metadata.BasePage `json:"page"`
+}
+
+// Validate ListDeliveryOpt
+func (o *ListDeliveryOpt) Validate() ccErr.RawErrorInfo {
+	if o.SubscriptionID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{SubscriptionIDField}}
+	}
+
+	switch o.Status {
+	case "", DeliverySuccess, DeliveryFailed:
+	default:
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{StatusField}}
+	}
+
+	return o.Page.ValidateWithEnableCount(false, common.BKMaxPageSize)
+}
+
+// ListDeliveryResult is the delivery history list result
+type ListDeliveryResult struct {
+	Count uint64     `json:"count"`
+	Info  []Delivery `json:"info"`
+}
+
+// ListDeadLetterResult is the dead letter list result
+type ListDeadLetterResult struct {
+	Count uint64       `json:"count"`
+	Info  []DeadLetter `json:"info"`
+}
+
+// RedeliverOpt is the option to redeliver the dead letters
+type RedeliverOpt struct {
+	SubscriptionID int64   `json:"subscription_id"`
+	IDs            []int64 `json:"ids"`
+}
+
+// Validate RedeliverOpt
+func (o *RedeliverOpt) Validate() ccErr.RawErrorInfo {
+	if o.SubscriptionID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{SubscriptionIDField}}
+	}
+
+	if len(o.IDs) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"ids"}}
+	}
+
+	if len(o.IDs) > MaxRedeliverLimit {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrExceedMaxOperationRecordsAtOnce,
+			Args: []interface{}{MaxRedeliverLimit}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
@@ -0,0 +1,464 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package formula parses and evaluates the arithmetic expressions used by the computed attributes.
+// An expression is made of numbers, field identifiers, the operators + - * / % and parentheses,
+// and the functions abs, round, floor, ceil, min, max and coalesce, e.g. "bk_cpu * 2 + coalesce(bk_mem, 0)".
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+
+	"configcenter/src/common/util"
+)
+
+// maxExpressionLength is the max length of an expression
+const maxExpressionLength = 512
+
+// Formula is a parsed expression that can be evaluated against an instance.
+type Formula struct {
+	expr   string
+	root   node
+	fields []string
+}
+
+// Parse parses the expression into a formula.
+func Parse(expr string) (*Formula, error) {
+	if len(expr) == 0 {
+		return nil, errors.New("expression is empty")
+	}
+
+	if len(expr) > maxExpressionLength {
+		return nil, fmt.Errorf("expression length %d exceeds max length %d", len(expr), maxExpressionLength)
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, fields: make(map[string]struct{})}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.end() {
+		return nil, fmt.Errorf("unexpected token %s at position %d", p.peek().val, p.peek().pos)
+	}
+
+	fields := make([]string, 0, len(p.fields))
+	for _, tok := range tokens {
+		if tok.kind != identToken {
+			continue
+		}
+		if _, exists := p.fields[tok.val]; exists {
+			fields = append(fields, tok.val)
+			delete(p.fields, tok.val)
+		}
+	}
+
+	return &Formula{expr: expr, root: root, fields: fields}, nil
+}
+
+// String returns the original expression.
+func (f *Formula) String() string {
+	return f.expr
+}
+
+// Fields returns the fields referenced by the expression, in the order of their first appearance.
+func (f *Formula) Fields() []string {
+	return f.fields
+}
+
+// Evaluate calculates the expression with the field values of the data. The result is nil if a referenced
+// field has no value or a division by zero occurs, otherwise it is a float64.
+func (f *Formula) Evaluate(data map[string]interface{}) (interface{}, error) {
+	val, ok, err := f.root.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || math.IsNaN(val) || math.IsInf(val, 0) {
+		return nil, nil
+	}
+	return val, nil
+}
+
+type tokenKind int
+
+const (
+	numberToken tokenKind = iota
+	identToken
+	operatorToken
+	lparenToken
+	rparenToken
+	commaToken
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+func tokenize(expr string) ([]token, error) {
+	runes := []rune(expr)
+	tokens := make([]token, 0)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: numberToken, val: string(runes[start:i]), pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: identToken, val: string(runes[start:i]), pos: start})
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '%':
+			tokens = append(tokens, token{kind: operatorToken, val: string(r), pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: lparenToken, val: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: rparenToken, val: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: commaToken, val: ",", pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("invalid character %q at position %d", r, i)
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	idx    int
+	fields map[string]struct{}
+}
+
+func (p *parser) end() bool {
+	return p.idx >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.idx]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.idx]
+	p.idx++
+	return tok
+}
+
+// parseExpr parses expr := term (('+'|'-') term)*
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.end() && p.peek().kind == operatorToken && (p.peek().val == "+" || p.peek().val == "-") {
+		op := p.next().val
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseTerm parses term := unary (('*'|'/'|'%') unary)*
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.end() && p.peek().kind == operatorToken && p.peek().val != "+" && p.peek().val != "-" {
+		op := p.next().val
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary parses unary := ('-'|'+') unary | primary
+func (p *parser) parseUnary() (node, error) {
+	if !p.end() && p.peek().kind == operatorToken && (p.peek().val == "-" || p.peek().val == "+") {
+		op := p.next().val
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return operand, nil
+		}
+		return &negNode{operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses primary := number | field | function '(' args ')' | '(' expr ')'
+func (p *parser) parsePrimary() (node, error) {
+	if p.end() {
+		return nil, errors.New("unexpected end of expression")
+	}
+
+	tok := p.next()
+	switch tok.kind {
+	case numberToken:
+		val, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", tok.val, tok.pos)
+		}
+		return &numberNode{val: val}, nil
+
+	case identToken:
+		if p.end() || p.peek().kind != lparenToken {
+			p.fields[tok.val] = struct{}{}
+			return &fieldNode{field: tok.val}, nil
+		}
+		return p.parseCall(tok)
+
+	case lparenToken:
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.end() || p.peek().kind != rparenToken {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", tok.pos)
+		}
+		p.next()
+		return inner, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected token %s at position %d", tok.val, tok.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, exists := functions[name.val]
+	if !exists {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.val, name.pos)
+	}
+
+	// skip the left parenthesis
+	p.next()
+
+	args := make([]node, 0)
+	for {
+		if p.end() {
+			return nil, fmt.Errorf("missing ')' for function %s", name.val)
+		}
+
+		if p.peek().kind == rparenToken && len(args) == 0 {
+			p.next()
+			break
+		}
+
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		if p.end() {
+			return nil, fmt.Errorf("missing ')' for function %s", name.val)
+		}
+
+		tok := p.next()
+		if tok.kind == rparenToken {
+			break
+		}
+		if tok.kind != commaToken {
+			return nil, fmt.Errorf("unexpected token %s at position %d", tok.val, tok.pos)
+		}
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs > 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("function %s got invalid argument count %d", name.val, len(args))
+	}
+
+	return &callNode{name: name.val, fn: fn, args: args}, nil
+}
+
+// node is a node of the expression syntax tree, eval returns the value and whether the value exists.
+type node interface {
+	eval(data map[string]interface{}) (float64, bool, error)
+}
+
+type numberNode struct {
+	val float64
+}
+
+func (n *numberNode) eval(map[string]interface{}) (float64, bool, error) {
+	return n.val, true, nil
+}
+
+type fieldNode struct {
+	field string
+}
+
+func (n *fieldNode) eval(data map[string]interface{}) (float64, bool, error) {
+	val, exists := data[n.field]
+	if !exists || val == nil || val == "" {
+		return 0, false, nil
+	}
+
+	num, err := util.GetFloat64ByInterface(val)
+	if err != nil {
+		return 0, false, fmt.Errorf("field %s value %v is not numeric", n.field, val)
+	}
+	return num, true, nil
+}
+
+type negNode struct {
+	operand node
+}
+
+func (n *negNode) eval(data map[string]interface{}) (float64, bool, error) {
+	val, ok, err := n.operand.eval(data)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	return -val, true, nil
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binaryNode) eval(data map[string]interface{}) (float64, bool, error) {
+	left, ok, err := n.left.eval(data)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+
+	right, ok, err := n.right.eval(data)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+
+	switch n.op {
+	case "+":
+		return left + right, true, nil
+	case "-":
+		return left - right, true, nil
+	case "*":
+		return left * right, true, nil
+	case "/":
+		if right == 0 {
+			return 0, false, nil
+		}
+		return left / right, true, nil
+	case "%":
+		if right == 0 {
+			return 0, false, nil
+		}
+		return math.Mod(left, right), true, nil
+	default:
+		return 0, false, fmt.Errorf("unsupported operator %s", n.op)
+	}
+}
+
+type function struct {
+	minArgs int
+	// maxArgs is the max argument count, 0 means unlimited
+	maxArgs int
+	// call is called with all the argument values when all of them exist
+	call func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs":   {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return math.Abs(args[0]) }},
+	"round": {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return math.Round(args[0]) }},
+	"floor": {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return math.Floor(args[0]) }},
+	"ceil":  {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return math.Ceil(args[0]) }},
+	"min": {minArgs: 1, call: func(args []float64) float64 {
+		res := args[0]
+		for _, arg := range args[1:] {
+			res = math.Min(res, arg)
+		}
+		return res
+	}},
+	"max": {minArgs: 1, call: func(args []float64) float64 {
+		res := args[0]
+		for _, arg := range args[1:] {
+			res = math.Max(res, arg)
+		}
+		return res
+	}},
+	// coalesce is evaluated specially by callNode, it returns the first argument that has value
+	"coalesce": {minArgs: 1},
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(data map[string]interface{}) (float64, bool, error) {
+	if n.name == "coalesce" {
+		for _, arg := range n.args {
+			val, ok, err := arg.eval(data)
+			if err != nil {
+				return 0, false, err
+			}
+			if ok {
+				return val, true, nil
+			}
+		}
+		return 0, false, nil
+	}
+
+	values := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		val, ok, err := arg.eval(data)
+		if err != nil || !ok {
+			return 0, ok, err
+		}
+		values[i] = val
+	}
+
+	return n.fn.call(values), true, nil
+}
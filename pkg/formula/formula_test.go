@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package formula
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	data := map[string]interface{}{
+		"bk_cpu":      int64(8),
+		"bk_mem":      16384.0,
+		"bk_disk":     "100",
+		"empty":       nil,
+		"bk_cpu_core": 2,
+	}
+
+	cases := []struct {
+		expr   string
+		expect interface{}
+	}{
+		{expr: "1 + 2 * 3", expect: 7.0},
+		{expr: "(1 + 2) * 3", expect: 9.0},
+		{expr: "-bk_cpu + 10", expect: 2.0},
+		{expr: "bk_cpu * bk_cpu_core", expect: 16.0},
+		{expr: "bk_mem / 1024", expect: 16.0},
+		{expr: "bk_disk % 7", expect: 2.0},
+		{expr: "round(10 / 3)", expect: 3.0},
+		{expr: "floor(2.7) + ceil(2.1) + abs(-1)", expect: 6.0},
+		{expr: "max(bk_cpu, bk_cpu_core, 3) - min(bk_cpu, bk_cpu_core)", expect: 6.0},
+		{expr: "coalesce(empty, not_exist, bk_cpu)", expect: 8.0},
+		{expr: "empty + 1", expect: nil},
+		{expr: "bk_cpu / 0", expect: nil},
+	}
+
+	for _, c := range cases {
+		f, err := Parse(c.expr)
+		require.NoError(t, err, c.expr)
+
+		val, err := f.Evaluate(data)
+		require.NoError(t, err, c.expr)
+		require.Equal(t, c.expect, val, c.expr)
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := Parse("bk_cpu * 2 + coalesce(bk_mem, bk_cpu) - bk_disk")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bk_cpu", "bk_mem", "bk_disk"}, f.Fields())
+
+	invalid := []string{"", "1 +", "(1 + 2", "unknown(1)", "abs(1, 2)", "max()", "1 $ 2", "1 2", "1..2"}
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+
+	f, err = Parse("bk_name + 1")
+	require.NoError(t, err)
+	_, err = f.Evaluate(map[string]interface{}{"bk_name": "host"})
+	require.Error(t, err)
+}
@@ -363,6 +363,11 @@ authServer:
   appCode: $auth_app_code
   #cmdb项目在蓝鲸权限中心的应用密钥
   appSecret: $auth_app_secret
+  #鉴权模式, iam为使用蓝鲸权限中心鉴权, local为使用cmdb内置的基于角色的鉴权, 默认为iam
+  mode: iam
+  local:
+    #local模式下拥有所有权限的管理员用户, 可配置多个, 用,(逗号)分割
+    adminUsers: admin
   authCenter:
      # 权限中心tls配置
      tls:
@@ -396,6 +401,35 @@ cloudServer:
     # 同步周期,最小为5分钟
     syncPeriodMinutes: 5
 
+#hostServer专属配置
+hostServer:
+  # 动态分组导出文件
+  dynamicGroupExport:
+    # 导出文件保留天数，默认为7天
+    retentionDays: 7
+    # 每个动态分组最多保留的导出文件个数，为0时不限制
+    maxFilesPerGroup: 10
+  hostApplyReconcile:
+    # 已开启巡检的业务的主机属性自动应用巡检间隔，单位为分钟，默认为60分钟
+    intervalMinutes: 60
+
+# 模型字段加密存储配置，配置后可将单行文本、多行文本类型的模型字段设置为加密存储，字段值在写入数据库前使用activeKey对应的密钥加密
+# 轮换密钥时，在keys中新增密钥并将activeKey改为新密钥的版本，旧密钥需保留至admin_server的重新加密任务(/migrate/attribute/reencrypt)执行完成
+#attributeCrypto:
+#  # 当前用于加密的密钥版本
+#  activeKey: v1
+#  keys:
+#    # 密钥版本，会记录在加密后的字段值中，用于解密时选择密钥
+#    - version: v1
+#      # 加密算法类型，枚举值：CLASSIC（国际算法）、SHANGMI（国密算法）
+#      algorithm: CLASSIC
+#      # 使用AES-GCM算法时所需的配置
+#      aesGcm:
+#        key:
+#      # 使用SM4算法时所需的配置
+#      sm4:
+#        key:
+
 #datacollection专属配置
 datacollection:
   hostsnap:
@@ -409,6 +443,17 @@ datacollection:
     rateLimiter:
       qps: 40
       burst: 100
+    # 主机快照不更新到主机上的属性字段，需为bk_cpu,bk_mem,bk_disk,bk_os_name等采集字段，如配置成[bk_os_name]
+    # 这些字段的采集值与主机上手工维护的值不一致时会记录到主机属性偏离报告中
+    ignoreUpdateFields: []
+    # 主机采集属性的变更历史配置
+    history:
+      # 是否记录主机采集属性的变更历史和偏离报告，默认为false
+      enabled: false
+      # 采集值未变化时，每隔多少分钟刷新一次该值的最后出现时间，默认值为60，最小值为1，以分钟为单位
+      recordIntervalMinutes: 60
+      # 主机采集属性的变更历史和偏离报告的保留天数，默认值为30，最小值为1，以天为单位
+      retentionDays: 30
 
 # 监控配置， monitor配置项必须存在
 monitor:
@@ -496,6 +541,14 @@ apiServer:
     enabled: false
     # jwt公钥
     publicKey:
+  # api-server限流的优先级队列配置，限流规则本身通过cmdb_ctl limiter命令管理
+  limiter:
+    # 同时处理的最大请求数，超过后按规则的优先级(high、normal、low)排队，为0时不开启优先级排队
+    maxInFlight: 0
+    # 排队的最大请求数，队列满时优先丢弃低优先级的请求
+    maxQueueLength: 1000
+    # 请求排队的最长等待时间，单位为秒
+    queueTimeoutSeconds: 5
 
 # 直接调用gse服务相关配置
 gse:
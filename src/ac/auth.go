@@ -23,7 +23,10 @@ import (
 	"net/http"
 
 	"configcenter/src/ac/iam"
+	"configcenter/src/ac/local"
 	"configcenter/src/ac/meta"
+	"configcenter/src/apimachinery"
+	"configcenter/src/common/auth"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/auth_server/sdk/types"
 	"configcenter/src/storage/dal/redis"
@@ -73,3 +76,48 @@ type AuthorizeInterface interface {
 	BatchRegisterResourceCreatorAction(ctx context.Context, h http.Header, input metadata.IamInstancesWithCreator) (
 		[]metadata.IamCreatorActionPolicy, error)
 }
+
+// NewAuthorizer new authorizer of the configured auth mode, uses BlueKing IAM by default, and uses the built-in
+// role based authorizer if the auth mode is local
+func NewAuthorizer(clientSet apimachinery.ClientSetInterface) AuthorizeInterface {
+	if local.GetAuthMode("authServer") == local.AuthModeLocal {
+		return local.NewAuthorizer(clientSet, local.ParseConfigFromKV("authServer"))
+	}
+	return iam.NewAuthorizer(clientSet)
+}
+
+// EnableIAM checks if the authorization is enabled and uses BlueKing IAM, the IAM client, views and system instances
+// are only needed in this case
+func EnableIAM() bool {
+	return auth.EnableAuthorize() && local.GetAuthMode("authServer") == local.AuthModeIAM
+}
+
+// NewViewer new iam viewer if IAM is enabled, otherwise returns a viewer that does nothing, because there is no iam
+// view to operate in the local auth mode
+func NewViewer(clientSet apimachinery.ClientSetInterface, iamCli *iam.IAM) Viewer {
+	if !EnableIAM() {
+		return new(noopViewer)
+	}
+	return iam.NewViewer(clientSet, iamCli)
+}
+
+// noopViewer is the viewer that does nothing
+type noopViewer struct{}
+
+// CreateView do nothing
+func (v *noopViewer) CreateView(_ context.Context, _ http.Header, _ []metadata.Object, _ redis.Client,
+	_ string) error {
+	return nil
+}
+
+// DeleteView do nothing
+func (v *noopViewer) DeleteView(_ context.Context, _ http.Header, _ []metadata.Object, _ redis.Client,
+	_ string) error {
+	return nil
+}
+
+// UpdateView do nothing
+func (v *noopViewer) UpdateView(_ context.Context, _ http.Header, _ []metadata.Object, _ redis.Client,
+	_ string) error {
+	return nil
+}
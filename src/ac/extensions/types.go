@@ -43,8 +43,8 @@ type AuthManager struct {
 func NewAuthManager(clientSet apimachinery.ClientSetInterface, iamCli *iam.IAM) *AuthManager {
 	return &AuthManager{
 		clientSet:                    clientSet,
-		Authorizer:                   iam.NewAuthorizer(clientSet),
-		Viewer:                       iam.NewViewer(clientSet, iamCli),
+		Authorizer:                   ac.NewAuthorizer(clientSet),
+		Viewer:                       ac.NewViewer(clientSet, iamCli),
 		RegisterModuleEnabled:        false,
 		RegisterSetEnabled:           false,
 		SkipReadAuthorization:        true,
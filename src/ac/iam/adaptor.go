@@ -107,6 +107,7 @@ var ccIamResTypeMap = map[meta.ResourceType]TypeID{
 	meta.KubePodWorkload:          TypeID(""),
 	meta.KubePod:                  TypeID(""),
 	meta.KubeContainer:            TypeID(""),
+	meta.KubeNsResource:           TypeID(""),
 	meta.FieldTemplate:            FieldGroupingTemplate,
 	meta.FulltextSearch:           TypeID(""),
 	meta.IDRuleIncrID:             TypeID(""),
@@ -417,23 +418,27 @@ var resourceActionMap = map[meta.ResourceType]map[meta.Action]ActionID{
 		meta.ModelTopologyOperation: EditBusinessLayer,
 	},
 	meta.EventWatch: {
-		meta.WatchHost:             WatchHostEvent,
-		meta.WatchHostRelation:     WatchHostRelationEvent,
-		meta.WatchBiz:              WatchBizEvent,
-		meta.WatchSet:              WatchSetEvent,
-		meta.WatchModule:           WatchModuleEvent,
-		meta.WatchProcess:          WatchProcessEvent,
-		meta.WatchCommonInstance:   WatchCommonInstanceEvent,
-		meta.WatchMainlineInstance: WatchMainlineInstanceEvent,
-		meta.WatchInstAsst:         WatchInstAsstEvent,
-		meta.WatchBizSet:           WatchBizSetEvent,
-		meta.WatchPlat:             WatchPlatEvent,
-		meta.WatchKubeCluster:      WatchKubeClusterEvent,
-		meta.WatchKubeNode:         WatchKubeNodeEvent,
-		meta.WatchKubeNamespace:    WatchKubeNamespaceEvent,
-		meta.WatchKubeWorkload:     WatchKubeWorkloadEvent,
-		meta.WatchKubePod:          WatchKubePodEvent,
-		meta.WatchProject:          WatchProjectEvent,
+		meta.WatchHost:                      WatchHostEvent,
+		meta.WatchHostRelation:              WatchHostRelationEvent,
+		meta.WatchBiz:                       WatchBizEvent,
+		meta.WatchSet:                       WatchSetEvent,
+		meta.WatchModule:                    WatchModuleEvent,
+		meta.WatchProcess:                   WatchProcessEvent,
+		meta.WatchCommonInstance:            WatchCommonInstanceEvent,
+		meta.WatchMainlineInstance:          WatchMainlineInstanceEvent,
+		meta.WatchInstAsst:                  WatchInstAsstEvent,
+		meta.WatchBizSet:                    WatchBizSetEvent,
+		meta.WatchPlat:                      WatchPlatEvent,
+		meta.WatchKubeCluster:               WatchKubeClusterEvent,
+		meta.WatchKubeNode:                  WatchKubeNodeEvent,
+		meta.WatchKubeNamespace:             WatchKubeNamespaceEvent,
+		meta.WatchKubeWorkload:              WatchKubeWorkloadEvent,
+		meta.WatchKubePod:                   WatchKubePodEvent,
+		meta.WatchKubeService:               WatchKubeServiceEvent,
+		meta.WatchKubeIngress:               WatchKubeIngressEvent,
+		meta.WatchKubeConfigMap:             WatchKubeConfigMapEvent,
+		meta.WatchKubePersistentVolumeClaim: WatchKubePersistentVolumeClaimEvent,
+		meta.WatchProject:                   WatchProjectEvent,
 	},
 	meta.UserCustom: {
 		meta.Find:   Skip,
@@ -585,6 +590,12 @@ var resourceActionMap = map[meta.ResourceType]map[meta.Action]ActionID{
 	meta.KubeContainer: {
 		meta.Find: ViewBusinessResource,
 	},
+	meta.KubeNsResource: {
+		meta.Find:   ViewBusinessResource,
+		meta.Update: EditContainerNamespace,
+		meta.Delete: DeleteContainerNamespace,
+		meta.Create: CreateContainerNamespace,
+	},
 	meta.Project: {
 		meta.Find:   ViewProject,
 		meta.Update: EditProject,
@@ -428,6 +428,18 @@ func genResourceManageActionGroups() []ActionGroup {
 						{
 							ID: WatchKubePodEvent,
 						},
+						{
+							ID: WatchKubeServiceEvent,
+						},
+						{
+							ID: WatchKubeIngressEvent,
+						},
+						{
+							ID: WatchKubeConfigMapEvent,
+						},
+						{
+							ID: WatchKubePersistentVolumeClaimEvent,
+						},
 						{
 							ID: WatchProjectEvent,
 						},
@@ -136,6 +136,10 @@ var ActionIDNameMap = map[ActionID]string{
 	WatchKubeNamespaceEvent:             "容器命名空间事件监听",
 	WatchKubeWorkloadEvent:              "容器工作负载事件监听",
 	WatchKubePodEvent:                   "容器Pod事件监听",
+	WatchKubeServiceEvent:               "容器Service事件监听",
+	WatchKubeIngressEvent:               "容器Ingress事件监听",
+	WatchKubeConfigMapEvent:             "容器ConfigMap事件监听",
+	WatchKubePersistentVolumeClaimEvent: "容器PersistentVolumeClaim事件监听",
 	WatchProjectEvent:                   "项目事件监听",
 	GlobalSettings:                      "全局设置",
 	ManageHostAgentID:                   "主机AgentID管理",
@@ -1533,6 +1537,34 @@ func genKubeEventWatchActions() []ResourceAction {
 			Type:    View,
 			Version: 1,
 		},
+		{
+			ID:      WatchKubeServiceEvent,
+			Name:    ActionIDNameMap[WatchKubeServiceEvent],
+			NameEn:  "Kube Service Event Listen",
+			Type:    View,
+			Version: 1,
+		},
+		{
+			ID:      WatchKubeIngressEvent,
+			Name:    ActionIDNameMap[WatchKubeIngressEvent],
+			NameEn:  "Kube Ingress Event Listen",
+			Type:    View,
+			Version: 1,
+		},
+		{
+			ID:      WatchKubeConfigMapEvent,
+			Name:    ActionIDNameMap[WatchKubeConfigMapEvent],
+			NameEn:  "Kube ConfigMap Event Listen",
+			Type:    View,
+			Version: 1,
+		},
+		{
+			ID:      WatchKubePersistentVolumeClaimEvent,
+			Name:    ActionIDNameMap[WatchKubePersistentVolumeClaimEvent],
+			NameEn:  "Kube PersistentVolumeClaim Event Listen",
+			Type:    View,
+			Version: 1,
+		},
 	}
 }
 
@@ -536,6 +536,14 @@ const (
 	WatchKubeWorkloadEvent ActionID = "watch_kube_workload"
 	// WatchKubePodEvent watch kube pod event action id, its event detail includes containers in it
 	WatchKubePodEvent ActionID = "watch_kube_pod"
+	// WatchKubeServiceEvent watch kube service event action id
+	WatchKubeServiceEvent ActionID = "watch_kube_service"
+	// WatchKubeIngressEvent watch kube ingress event action id
+	WatchKubeIngressEvent ActionID = "watch_kube_ingress"
+	// WatchKubeConfigMapEvent watch kube configMap event action id
+	WatchKubeConfigMapEvent ActionID = "watch_kube_config_map"
+	// WatchKubePersistentVolumeClaimEvent watch kube persistentVolumeClaim event action id
+	WatchKubePersistentVolumeClaimEvent ActionID = "watch_kube_persistent_volume_claim"
 
 	// CreateFieldGroupingTemplate create field grouping template action id
 	CreateFieldGroupingTemplate = "create_field_grouping_template"
@@ -0,0 +1,63 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package local
+
+import (
+	"strings"
+
+	cc "configcenter/src/common/backbone/configcenter"
+)
+
+const (
+	// AuthModeIAM authorizes with BlueKing IAM, it is the default mode
+	AuthModeIAM = "iam"
+	// AuthModeLocal authorizes with the roles stored in cmdb by the built-in local authorizer
+	AuthModeLocal = "local"
+)
+
+// Config is the local authorizer config
+type Config struct {
+	// AdminUsers is the users that have all the permissions without any role
+	AdminUsers []string
+}
+
+// GetAuthMode get the configured auth mode, returns iam mode if it is not set
+func GetAuthMode(prefix string) string {
+	mode, err := cc.String(prefix + ".mode")
+	if err != nil || len(mode) == 0 {
+		return AuthModeIAM
+	}
+	return mode
+}
+
+// ParseConfigFromKV parse local authorizer config
+func ParseConfigFromKV(prefix string) Config {
+	cfg := Config{AdminUsers: make([]string, 0)}
+
+	adminUsers, err := cc.String(prefix + ".local.adminUsers")
+	if err != nil {
+		return cfg
+	}
+
+	for _, user := range strings.Split(adminUsers, ",") {
+		user = strings.TrimSpace(user)
+		if len(user) > 0 {
+			cfg.AdminUsers = append(cfg.AdminUsers, user)
+		}
+	}
+	return cfg
+}
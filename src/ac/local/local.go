@@ -0,0 +1,296 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package local is the built-in role based authorizer that works without BlueKing IAM, it authorizes users with
+// the roles and role bindings stored in cmdb, the actions and resources are the same as the ones registered to IAM
+package local
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/ac/meta"
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/auth_server/sdk/types"
+)
+
+type authorizer struct {
+	coreService coreservice.CoreServiceClientInterface
+	adminUsers  map[string]struct{}
+}
+
+// NewAuthorizer new local authorizer
+func NewAuthorizer(clientSet apimachinery.ClientSetInterface, cfg Config) *authorizer {
+	adminUsers := make(map[string]struct{})
+	for _, user := range cfg.AdminUsers {
+		adminUsers[user] = struct{}{}
+	}
+
+	return &authorizer{coreService: clientSet.CoreService(), adminUsers: adminUsers}
+}
+
+// AuthorizeBatch batch authorization, the user must have permission on all the resources of each decision
+func (a *authorizer) AuthorizeBatch(ctx context.Context, h http.Header, user meta.UserInfo,
+	resources ...meta.ResourceAttribute) ([]types.Decision, error) {
+	return a.authorizeBatch(ctx, h, false, user, resources...)
+}
+
+// AuthorizeAnyBatch batch authorization will pass if the user has the action's permission on any resource
+func (a *authorizer) AuthorizeAnyBatch(ctx context.Context, h http.Header, user meta.UserInfo,
+	resources ...meta.ResourceAttribute) ([]types.Decision, error) {
+	return a.authorizeBatch(ctx, h, true, user, resources...)
+}
+
+func (a *authorizer) authorizeBatch(ctx context.Context, h http.Header, anyMode bool, user meta.UserInfo,
+	resources ...meta.ResourceAttribute) ([]types.Decision, error) {
+
+	rid := httpheader.GetRid(h)
+
+	decisions := make([]types.Decision, len(resources))
+	if !auth.EnableAuthorize() || a.isAdmin(user.UserName) {
+		for i := range decisions {
+			decisions[i].Authorized = true
+		}
+		return decisions, nil
+	}
+
+	var policies []metadata.AuthPolicy
+	for index, resource := range resources {
+		if resource.Action == meta.SkipAction {
+			decisions[index].Authorized = true
+			continue
+		}
+
+		action, iamResources, err := iam.AdaptAuthOptions(&resource)
+		if err != nil {
+			blog.Errorf("adaptor cmdb resource to iam failed, err: %v, rid: %s", err, rid)
+			return nil, err
+		}
+
+		if action == iam.Skip {
+			decisions[index].Authorized = true
+			continue
+		}
+
+		// policies are only fetched once for all the resources, and only when they are needed
+		if policies == nil {
+			policies, err = a.getUserPolicies(ctx, h, user.UserName)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		decisions[index].Authorized, err = isAuthorized(policies, action, iamResources, anyMode)
+		if err != nil {
+			blog.Errorf("check if user %s is authorized to %s failed, err: %v, rid: %s", user.UserName, action,
+				err, rid)
+			return nil, err
+		}
+	}
+
+	return decisions, nil
+}
+
+// ListAuthorizedResources list the ids of the resources that the user is authorized to operate
+func (a *authorizer) ListAuthorizedResources(ctx context.Context, h http.Header,
+	input meta.ListAuthorizedResourcesParam) (*types.AuthorizeList, error) {
+
+	if !auth.EnableAuthorize() || a.isAdmin(input.UserName) {
+		return &types.AuthorizeList{IsAny: true}, nil
+	}
+
+	rscType, err := iam.ConvertResourceType(input.ResourceType, 0)
+	if err != nil {
+		blog.Errorf("convert resource type %s failed, err: %v, rid: %s", input.ResourceType, err,
+			httpheader.GetRid(h))
+		return nil, err
+	}
+
+	action, err := iam.ConvertResourceAction(input.ResourceType, input.Action, input.BizID)
+	if err != nil {
+		blog.Errorf("convert resource action failed, input: %+v, err: %v, rid: %s", input, err, httpheader.GetRid(h))
+		return nil, err
+	}
+
+	policies, err := a.getUserPolicies(ctx, h, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, isAny := listAuthorizedIDs(policies, action, *rscType, input.BizID)
+	return &types.AuthorizeList{Ids: ids, IsAny: isAny}, nil
+}
+
+// GetNoAuthSkipUrl returns empty url, permissions are applied by asking the administrators to bind roles
+func (a *authorizer) GetNoAuthSkipUrl(ctx context.Context, h http.Header,
+	input *metadata.IamPermission) (string, error) {
+	return "", nil
+}
+
+// GetPermissionToApply get the permissions that the user needs to operate the resources
+func (a *authorizer) GetPermissionToApply(ctx context.Context, h http.Header,
+	input []meta.ResourceAttribute) (*metadata.IamPermission, error) {
+
+	permission := &metadata.IamPermission{
+		SystemID:   iam.SystemIDCMDB,
+		SystemName: iam.SystemNameCMDB,
+		Actions:    make([]metadata.IamAction, 0),
+	}
+
+	actionIndexMap := make(map[iam.ActionID]int)
+	for idx := range input {
+		if input[idx].Action == meta.SkipAction {
+			continue
+		}
+
+		action, resources, err := iam.AdaptAuthOptions(&input[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		if action == iam.Skip {
+			continue
+		}
+
+		if _, exists := actionIndexMap[action]; !exists {
+			actionIndexMap[action] = len(permission.Actions)
+			permission.Actions = append(permission.Actions, metadata.IamAction{
+				ID:                   string(action),
+				Name:                 getActionName(action),
+				RelatedResourceTypes: make([]metadata.IamResourceType, 0),
+			})
+		}
+		iamAction := &permission.Actions[actionIndexMap[action]]
+
+		for _, resource := range resources {
+			instance, err := genResourceInstance(resource)
+			if err != nil {
+				return nil, err
+			}
+
+			iamAction.RelatedResourceTypes = addRelatedInstance(iamAction.RelatedResourceTypes,
+				string(resource.Type), instance)
+		}
+	}
+
+	return permission, nil
+}
+
+// RegisterResourceCreatorAction local authorizer do not grant the creator any permission
+func (a *authorizer) RegisterResourceCreatorAction(ctx context.Context, h http.Header,
+	input metadata.IamInstanceWithCreator) ([]metadata.IamCreatorActionPolicy, error) {
+	return make([]metadata.IamCreatorActionPolicy, 0), nil
+}
+
+// BatchRegisterResourceCreatorAction local authorizer do not grant the creator any permission
+func (a *authorizer) BatchRegisterResourceCreatorAction(ctx context.Context, h http.Header,
+	input metadata.IamInstancesWithCreator) ([]metadata.IamCreatorActionPolicy, error) {
+	return make([]metadata.IamCreatorActionPolicy, 0), nil
+}
+
+func (a *authorizer) isAdmin(user string) bool {
+	_, exists := a.adminUsers[user]
+	return exists
+}
+
+// getUserPolicies get the policies of all the roles that are bound to the user
+func (a *authorizer) getUserPolicies(ctx context.Context, h http.Header, user string) ([]metadata.AuthPolicy,
+	error) {
+
+	opt := &metadata.ListUserAuthPolicyOption{User: user}
+	policies, err := a.coreService.AuthRole().ListUserPolicy(ctx, h, opt)
+	if err != nil {
+		blog.Errorf("list user %s auth policies failed, err: %v, rid: %s", user, err, httpheader.GetRid(h))
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func getActionName(action iam.ActionID) string {
+	if name, exists := iam.ActionIDNameMap[action]; exists {
+		return name
+	}
+	return string(action)
+}
+
+func getTypeName(rscType iam.TypeID) string {
+	if name, exists := iam.ResourceTypeIDMap[rscType]; exists {
+		return name
+	}
+	return string(rscType)
+}
+
+// genResourceInstance generate iam resource instance by the resource's path and itself
+func genResourceInstance(resource types.Resource) ([]metadata.IamResourceInstance, error) {
+	instance := make([]metadata.IamResourceInstance, 0)
+	if resource.Attribute != nil {
+		if path, exists := resource.Attribute[types.IamPathKey]; exists {
+			iamPath, ok := path.([]string)
+			if !ok {
+				return nil, fmt.Errorf("iam path(%v) is not string array type", path)
+			}
+
+			ancestors, err := iam.ParseIamPathToAncestors(iamPath)
+			if err != nil {
+				return nil, err
+			}
+			instance = append(instance, ancestors...)
+		}
+	}
+
+	if len(resource.ID) > 0 {
+		instance = append(instance, metadata.IamResourceInstance{
+			Type:     string(resource.Type),
+			TypeName: getTypeName(iam.TypeID(resource.Type)),
+			ID:       resource.ID,
+		})
+	}
+
+	return instance, nil
+}
+
+func addRelatedInstance(rscTypes []metadata.IamResourceType, rscType string,
+	instance []metadata.IamResourceInstance) []metadata.IamResourceType {
+
+	for idx := range rscTypes {
+		if rscTypes[idx].Type == rscType {
+			if len(instance) > 0 {
+				rscTypes[idx].Instances = append(rscTypes[idx].Instances, instance)
+			}
+			return rscTypes
+		}
+	}
+
+	related := metadata.IamResourceType{
+		SystemID:   iam.SystemIDCMDB,
+		SystemName: iam.SystemNameCMDB,
+		Type:       rscType,
+		TypeName:   getTypeName(iam.TypeID(rscType)),
+	}
+	if len(instance) > 0 {
+		related.Instances = [][]metadata.IamResourceInstance{instance}
+	}
+
+	return append(rscTypes, related)
+}
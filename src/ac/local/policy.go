@@ -0,0 +1,149 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package local
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/auth_server/sdk/types"
+)
+
+// ValidatePolicies validate that the policies' actions and resource types are defined in iam model
+func ValidatePolicies(policies []metadata.AuthPolicy) error {
+	for _, policy := range policies {
+		actionID := iam.ActionID(policy.Action)
+		if _, exists := iam.ActionIDNameMap[actionID]; !exists && !strings.Contains(policy.Action,
+			iam.IAMSysInstTypePrefix) {
+			return fmt.Errorf("action %s is invalid", policy.Action)
+		}
+
+		for _, resource := range policy.Resources {
+			typeID := iam.TypeID(resource.Type)
+			if _, exists := iam.ResourceTypeIDMap[typeID]; !exists && !iam.IsIAMSysInstance(typeID) {
+				return fmt.Errorf("resource type %s of action %s is invalid", resource.Type, policy.Action)
+			}
+		}
+	}
+	return nil
+}
+
+// isAuthorized checks if the policies grant the action on all the resources, if anyMode is set, the action is
+// granted as long as there is a policy of the action no matter which resources it is scoped to
+func isAuthorized(policies []metadata.AuthPolicy, action iam.ActionID, resources []types.Resource,
+	anyMode bool) (bool, error) {
+
+	for _, policy := range policies {
+		if policy.Action != string(action) {
+			continue
+		}
+
+		if anyMode || len(policy.Resources) == 0 || len(resources) == 0 {
+			return true, nil
+		}
+
+		allCovered := true
+		for _, resource := range resources {
+			covered, err := isResourceCovered(policy.Resources, resource)
+			if err != nil {
+				return false, err
+			}
+
+			if !covered {
+				allCovered = false
+				break
+			}
+		}
+
+		if allCovered {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isResourceCovered checks if the resource or one of its ancestors is in the policy resources
+func isResourceCovered(policyResources []metadata.AuthPolicyResource, resource types.Resource) (bool, error) {
+	instances := make([]metadata.AuthPolicyResource, 0)
+	if len(resource.ID) > 0 {
+		instances = append(instances, metadata.AuthPolicyResource{Type: string(resource.Type), ID: resource.ID})
+	}
+
+	if resource.Attribute != nil {
+		if path, exists := resource.Attribute[types.IamPathKey]; exists {
+			iamPath, ok := path.([]string)
+			if !ok {
+				return false, fmt.Errorf("iam path(%v) is not string array type", path)
+			}
+
+			ancestors, err := iam.ParseIamPathToAncestors(iamPath)
+			if err != nil {
+				return false, err
+			}
+
+			for _, ancestor := range ancestors {
+				instances = append(instances, metadata.AuthPolicyResource{Type: ancestor.Type, ID: ancestor.ID})
+			}
+		}
+	}
+
+	for _, instance := range instances {
+		for _, policyResource := range policyResources {
+			if policyResource.Type == instance.Type && policyResource.ID == instance.ID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// listAuthorizedIDs list the ids of the resources of the type that the policies grant the action on, returns
+// isAny as true if the action is granted on all the resources of the type in the business(or the whole system
+// if bizID is not set)
+func listAuthorizedIDs(policies []metadata.AuthPolicy, action iam.ActionID, rscType iam.TypeID,
+	bizID int64) (ids []string, isAny bool) {
+
+	bizIDStr := strconv.FormatInt(bizID, 10)
+	ids = make([]string, 0)
+	for _, policy := range policies {
+		if policy.Action != string(action) {
+			continue
+		}
+
+		if len(policy.Resources) == 0 {
+			return nil, true
+		}
+
+		for _, resource := range policy.Resources {
+			if bizID > 0 && resource.Type == string(iam.Business) && resource.ID == bizIDStr {
+				return nil, true
+			}
+
+			if resource.Type == string(rscType) {
+				ids = append(ids, resource.ID)
+			}
+		}
+	}
+
+	return util.StrArrayUnique(ids), false
+}
@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package local
+
+import (
+	"testing"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/auth_server/sdk/types"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	policies := []metadata.AuthPolicy{
+		{Action: string(iam.EditBusinessHost), Resources: []metadata.AuthPolicyResource{{Type: "biz", ID: "3"}}},
+		{Action: string(iam.ViewBusinessResource)},
+	}
+
+	hostInBiz3 := types.Resource{Type: "host", ID: "10",
+		Attribute: map[string]interface{}{types.IamPathKey: []string{"/biz,3/set,5/module,7/"}}}
+	hostInBiz4 := types.Resource{Type: "host", ID: "11",
+		Attribute: map[string]interface{}{types.IamPathKey: []string{"/biz,4/set,6/module,8/"}}}
+
+	testCases := []struct {
+		name      string
+		action    iam.ActionID
+		resources []types.Resource
+		anyMode   bool
+		expected  bool
+	}{
+		{name: "scoped to ancestor", action: iam.EditBusinessHost, resources: []types.Resource{hostInBiz3},
+			expected: true},
+		{name: "out of scope", action: iam.EditBusinessHost, resources: []types.Resource{hostInBiz4}},
+		{name: "partly out of scope", action: iam.EditBusinessHost,
+			resources: []types.Resource{hostInBiz3, hostInBiz4}},
+		{name: "any mode", action: iam.EditBusinessHost, resources: []types.Resource{hostInBiz4}, anyMode: true,
+			expected: true},
+		{name: "unscoped policy", action: iam.ViewBusinessResource,
+			resources: []types.Resource{{Type: "biz", ID: "9"}}, expected: true},
+		{name: "scoped to itself", action: iam.EditBusinessHost,
+			resources: []types.Resource{{Type: "biz", ID: "3"}}, expected: true},
+		{name: "no policy", action: iam.FindBusiness, resources: []types.Resource{hostInBiz3},
+			anyMode: true},
+	}
+
+	for _, testCase := range testCases {
+		authorized, err := isAuthorized(policies, testCase.action, testCase.resources, testCase.anyMode)
+		if err != nil {
+			t.Errorf("%s: check authorized failed, err: %v", testCase.name, err)
+			continue
+		}
+
+		if authorized != testCase.expected {
+			t.Errorf("%s: expect authorized %v, but got %v", testCase.name, testCase.expected, authorized)
+		}
+	}
+}
+
+func TestListAuthorizedIDs(t *testing.T) {
+	policies := []metadata.AuthPolicy{
+		{Action: string(iam.EditBusinessHost), Resources: []metadata.AuthPolicyResource{{Type: "biz", ID: "3"},
+			{Type: "host", ID: "10"}, {Type: "host", ID: "11"}}},
+		{Action: string(iam.EditBusinessHost), Resources: []metadata.AuthPolicyResource{{Type: "host", ID: "10"}}},
+	}
+
+	ids, isAny := listAuthorizedIDs(policies, iam.EditBusinessHost, "host", 0)
+	if isAny || len(ids) != 2 {
+		t.Errorf("expect ids [10 11], but got %v, is any: %v", ids, isAny)
+	}
+
+	_, isAny = listAuthorizedIDs(policies, iam.EditBusinessHost, "host", 3)
+	if !isAny {
+		t.Errorf("expect all hosts in business 3 are authorized")
+	}
+
+	ids, isAny = listAuthorizedIDs(policies, iam.FindBusiness, "host", 0)
+	if isAny || len(ids) != 0 {
+		t.Errorf("expect no authorized ids, but got %v, is any: %v", ids, isAny)
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	valid := []metadata.AuthPolicy{
+		{Action: string(iam.EditBusinessHost), Resources: []metadata.AuthPolicyResource{{Type: "biz", ID: "3"}}},
+		{Action: string(iam.GenDynamicActionID(iam.Edit, 5)),
+			Resources: []metadata.AuthPolicyResource{{Type: iam.IAMSysInstTypePrefix + "5", ID: "1"}}},
+	}
+	if err := ValidatePolicies(valid); err != nil {
+		t.Errorf("expect policies are valid, but got err: %v", err)
+	}
+
+	if err := ValidatePolicies([]metadata.AuthPolicy{{Action: "not_exist"}}); err == nil {
+		t.Errorf("expect invalid action is rejected")
+	}
+
+	invalidType := []metadata.AuthPolicy{{Action: string(iam.EditBusinessHost),
+		Resources: []metadata.AuthPolicyResource{{Type: "not_exist", ID: "1"}}}}
+	if err := ValidatePolicies(invalidType); err == nil {
+		t.Errorf("expect invalid resource type is rejected")
+	}
+}
@@ -211,6 +211,14 @@ const (
 	WatchKubeWorkload Action = "kube_workload"
 	// WatchKubePod watch kube pod event cc action
 	WatchKubePod Action = "kube_pod"
+	// WatchKubeService watch kube service event cc action
+	WatchKubeService Action = "kube_service"
+	// WatchKubeIngress watch kube ingress event cc action
+	WatchKubeIngress Action = "kube_ingress"
+	// WatchKubeConfigMap watch kube configMap event cc action
+	WatchKubeConfigMap Action = "kube_config_map"
+	// WatchKubePersistentVolumeClaim watch kube persistentVolumeClaim event cc action
+	WatchKubePersistentVolumeClaim Action = "kube_persistent_volume_claim"
 
 	// ViewBusinessResource view business related resources action, including business and business collection resources
 	ViewBusinessResource Action = "viewBusinessResource"
@@ -94,6 +94,9 @@ const (
 	// KubeContainer auth resource type in CMDB
 	KubeContainer ResourceType = "kube_container"
 
+	// KubeNsResource auth resource type in CMDB, including service, ingress, configMap and persistentVolumeClaim
+	KubeNsResource ResourceType = "kube_ns_resource"
+
 	// below are specific workload auth resource types in CMDB, reserved for later use
 
 	// KubeDeployment auth resource type in CMDB
@@ -23,7 +23,12 @@ import (
 	"configcenter/src/ac/meta"
 )
 
-var findSystemConfigRegexp = regexp.MustCompile(`^/api/v3/admin/find/system_config/platform_setting/[^\s/]+/?$`)
+var (
+	findSystemConfigRegexp      = regexp.MustCompile(`^/api/v3/admin/find/system_config/platform_setting/[^\s/]+/?$`)
+	updateAuthRoleRegexp        = regexp.MustCompile(`^/api/v3/update/auth_role/[0-9]+/?$`)
+	deleteAuthRoleRegexp        = regexp.MustCompile(`^/api/v3/delete/auth_role/[0-9]+/?$`)
+	deleteAuthRoleBindingRegexp = regexp.MustCompile(`^/api/v3/delete/auth_role_binding/[0-9]+/?$`)
+)
 
 func (ps *parseStream) adminRelated() *parseStream {
 	if ps.shouldReturn() {
@@ -32,6 +37,8 @@ func (ps *parseStream) adminRelated() *parseStream {
 
 	ps.ConfigAdmin()
 	ps.PlatformSettingConfigAuth()
+	ps.AuthRoleAuth()
+	ps.ManifestAuth()
 
 	return ps
 }
@@ -84,3 +91,95 @@ func (ps *parseStream) PlatformSettingConfigAuth() *parseStream {
 	return ParseStreamWithFramework(ps, PlatformSettingConfig)
 
 }
+
+// AuthRoleConfigs is the auth configs of the built-in local authorizer's role management apis, the roles decide
+// all the users' permissions, so they are managed by the users who can manage the platform configs
+var AuthRoleConfigs = []AuthConfig{
+	{
+		Name:           "createAuthRole",
+		Description:    "创建鉴权角色",
+		Pattern:        "/api/v3/create/auth_role",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "updateAuthRole",
+		Description:    "更新鉴权角色",
+		Regex:          updateAuthRoleRegexp,
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "deleteAuthRole",
+		Description:    "删除鉴权角色",
+		Regex:          deleteAuthRoleRegexp,
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "findAuthRole",
+		Description:    "查询鉴权角色",
+		Pattern:        "/api/v3/findmany/auth_role",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "createAuthRoleBinding",
+		Description:    "创建鉴权角色绑定",
+		Pattern:        "/api/v3/create/auth_role_binding",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "deleteAuthRoleBinding",
+		Description:    "删除鉴权角色绑定",
+		Regex:          deleteAuthRoleBindingRegexp,
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "findAuthRoleBinding",
+		Description:    "查询鉴权角色绑定",
+		Pattern:        "/api/v3/findmany/auth_role_binding",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	},
+}
+
+// AuthRoleAuth role management auth
+func (ps *parseStream) AuthRoleAuth() *parseStream {
+	return ParseStreamWithFramework(ps, AuthRoleConfigs)
+}
+
+// ManifestConfigs is the auth configs of the topology manifest apis, the manifest covers the models and the topology
+// of all the businesses, so it is managed by the users who can manage the platform configs
+var ManifestConfigs = []AuthConfig{
+	{
+		Name:           "exportManifest",
+		Description:    "导出拓扑清单",
+		Pattern:        "/api/v3/find/topo/manifest",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "planManifest",
+		Description:    "预览拓扑清单变更",
+		Pattern:        "/api/v3/find/topo/manifest/plan",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "applyManifest",
+		Description:    "应用拓扑清单",
+		Pattern:        "/api/v3/update/topo/manifest/apply",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	},
+}
+
+// ManifestAuth topology manifest auth
+func (ps *parseStream) ManifestAuth() *parseStream {
+	return ParseStreamWithFramework(ps, ManifestConfigs)
+}
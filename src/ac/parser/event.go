@@ -37,7 +37,8 @@ func (ps *parseStream) eventRelated() *parseStream {
 	ps.watch().
 		syncHostIdentifier().
 		pushHostIdentifier().
-		findHostIdentifierPushResult()
+		findHostIdentifierPushResult().
+		eventSubscription()
 	return ps
 }
 
@@ -178,3 +179,35 @@ func (ps *parseStream) findHostIdentifierPushResult() *parseStream {
 
 	return ps
 }
+
+// event subscription apis authorize the watched resources of the subscription in event server
+var eventSubscriptionPatterns = map[string]string{
+	"/api/v3/event/create/subscription":                http.MethodPost,
+	"/api/v3/event/update/subscription":                http.MethodPut,
+	"/api/v3/event/delete/subscription":                http.MethodDelete,
+	"/api/v3/event/findmany/subscription":              http.MethodPost,
+	"/api/v3/event/findmany/subscription/delivery":     http.MethodPost,
+	"/api/v3/event/findmany/subscription/dead_letter":  http.MethodPost,
+	"/api/v3/event/redeliver/subscription/dead_letter": http.MethodPost,
+}
+
+func (ps *parseStream) eventSubscription() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	for pattern, method := range eventSubscriptionPatterns {
+		if ps.hitPattern(pattern, method) {
+			ps.Attribute.Resources = []meta.ResourceAttribute{
+				{
+					Basic: meta.Basic{
+						Action: meta.SkipAction,
+					},
+				},
+			}
+			return ps
+		}
+	}
+
+	return ps
+}
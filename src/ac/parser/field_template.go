@@ -193,6 +193,34 @@ var FieldTemplateAuthConfigs = []AuthConfig{
 		HTTPMethod:     http.MethodPost,
 		ResourceAction: meta.SkipAction,
 	},
+	{
+		Name:           "ListFieldTemplateVersion",
+		Description:    "查询字段组合模版版本列表",
+		Pattern:        "/api/v3/findmany/field_template/version",
+		HTTPMethod:     http.MethodPost,
+		ResourceAction: meta.SkipAction,
+	},
+	{
+		Name:           "CompareFieldTemplateVersion",
+		Description:    "对比字段组合模版两个版本的差异",
+		Pattern:        "/api/v3/find/field_template/version/difference",
+		HTTPMethod:     http.MethodPost,
+		ResourceAction: meta.SkipAction,
+	},
+	{
+		Name:           "UpgradeObjFieldTemplateVersion",
+		Description:    "升级模型绑定的字段组合模版版本",
+		Pattern:        "/api/v3/update/field_template/object/version",
+		HTTPMethod:     http.MethodPut,
+		ResourceAction: meta.SkipAction,
+	},
+	{
+		Name:           "RollbackFieldTemplate",
+		Description:    "回滚字段组合模版到指定版本",
+		Pattern:        "/api/v3/update/field_template/rollback",
+		HTTPMethod:     http.MethodPut,
+		ResourceAction: meta.SkipAction,
+	},
 }
 
 func (ps *parseStream) fieldTemplate() *parseStream {
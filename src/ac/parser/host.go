@@ -26,6 +26,7 @@ func (ps *parseStream) hostRelated() *parseStream {
 		host().
 		hostTransfer().
 		dynamicGrouping().
+		dynamicGroupExport().
 		userCustom().
 		hostFavorite().
 		findObjectIdentifier().
@@ -231,6 +232,100 @@ func (ps *parseStream) dynamicGrouping() *parseStream {
 	return ps
 }
 
+var (
+	exportDynamicGroupRegexp       = regexp.MustCompile(`^/api/v3/dynamicgroup/export/[0-9]+/[^\s/]+/?$`)
+	searchDynamicGroupExportRegexp = regexp.MustCompile(
+		`^/api/v3/dynamicgroup/export/search/(file|schedule)/[0-9]+/?$`)
+	findDynamicGroupExportChunkRegexp = regexp.MustCompile(
+		`^/api/v3/dynamicgroup/export/file/[0-9]+/[^\s/]+/[0-9]+/chunk/[0-9]+/?$`)
+	deleteDynamicGroupExportFileRegexp = regexp.MustCompile(
+		`^/api/v3/dynamicgroup/export/file/[0-9]+/[^\s/]+/[0-9]+/?$`)
+	createDynamicGroupExportScheduleRegexp = regexp.MustCompile(
+		`^/api/v3/dynamicgroup/export/schedule/[0-9]+/[^\s/]+/?$`)
+	updateDynamicGroupExportScheduleRegexp = regexp.MustCompile(
+		`^/api/v3/dynamicgroup/export/schedule/[0-9]+/[^\s/]+/[0-9]+/?$`)
+)
+
+func (ps *parseStream) dynamicGroupExport() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// export dynamic group data, download or delete the exported file needs the same execute permission.
+	if ps.hitRegexp(exportDynamicGroupRegexp, http.MethodPost) ||
+		ps.hitRegexp(findDynamicGroupExportChunkRegexp, http.MethodGet) ||
+		ps.hitRegexp(deleteDynamicGroupExportFileRegexp, http.MethodDelete) {
+
+		bizIdx, groupIdx := 4, 5
+		if ps.RequestCtx.Elements[4] == "file" {
+			bizIdx, groupIdx = 5, 6
+		}
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[bizIdx], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("export dynamic group failed, err: %v", err)
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.DynamicGrouping,
+					Action: meta.Execute,
+					Name:   ps.RequestCtx.Elements[groupIdx],
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(searchDynamicGroupExportRegexp, http.MethodPost) {
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("search dynamic group export files or schedules failed, err: %v", err)
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.DynamicGrouping,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	// export schedule is a part of the dynamic group, so managing it needs the update permission of the group.
+	if ps.hitRegexp(createDynamicGroupExportScheduleRegexp, http.MethodPost) ||
+		ps.hitRegexp(updateDynamicGroupExportScheduleRegexp, http.MethodPut) ||
+		ps.hitRegexp(updateDynamicGroupExportScheduleRegexp, http.MethodDelete) {
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[5], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("manage dynamic group export schedule failed, err: %v", err)
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:         meta.DynamicGrouping,
+					Action:       meta.Update,
+					InstanceIDEx: ps.RequestCtx.Elements[6],
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
 var (
 	saveUserCustomPattern         = `/api/v3/usercustom`
 	searchUserCustomPattern       = `/api/v3/usercustom/user/search`
@@ -369,6 +464,11 @@ const (
 
 	findHostsServiceTemplatesPattern = "/api/v3/findmany/hosts/service_template"
 
+	// find collected host attribute history and drift
+	findHostAttrHistoryPattern = "/api/v3/findmany/hosts/attr_history"
+	findHostAttrValuePattern   = "/api/v3/findmany/hosts/attr_history/value"
+	findHostAttrDriftPattern   = "/api/v3/findmany/hosts/attr_drift"
+
 	// 特殊接口，给蓝鲸业务使用
 	hostInstallPattern = "/api/v3/host/install/bk"
 
@@ -434,6 +534,23 @@ func (ps *parseStream) host() *parseStream {
 		return ps
 	}
 
+	// find collected host attribute history and drift
+	if ps.hitPattern(findHostAttrHistoryPattern, http.MethodPost) ||
+		ps.hitPattern(findHostAttrValuePattern, http.MethodPost) ||
+		ps.hitPattern(findHostAttrDriftPattern, http.MethodPost) {
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.FindMany,
+				},
+			},
+		}
+
+		return ps
+	}
+
 	if ps.hitRegexp(findHostsBySetTemplatesRegex, http.MethodPost) {
 		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
 		if err != nil {
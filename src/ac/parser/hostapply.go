@@ -168,6 +168,51 @@ var HostApplyAuthConfigs = []AuthConfig{
 		BizIDGetter:    DefaultBizIDGetter,
 		ResourceType:   meta.HostApply,
 		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "PreviewHostApplyRuleRegex",
+		Description:    "预览主机属性自动应用规则变更对主机的影响",
+		Regex:          regexp.MustCompile(`^/api/v3/findmany/host_apply_plan/bk_biz_id/([0-9]+)/preview/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "UpdateHostApplyReconcileSettingRegex",
+		Description:    "更新业务的主机属性自动应用巡检配置",
+		Regex:          regexp.MustCompile(`^/api/v3/update/host_apply_plan/bk_biz_id/([0-9]+)/reconcile_setting/?$`),
+		HTTPMethod:     http.MethodPut,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "GetHostApplyReconcileSettingRegex",
+		Description:    "查询业务的主机属性自动应用巡检配置",
+		Regex:          regexp.MustCompile(`^/api/v3/find/host_apply_plan/bk_biz_id/([0-9]+)/reconcile_setting/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "ListHostApplyConflictRegex",
+		Description:    "查询巡检发现的与主机属性自动应用规则不一致的主机",
+		Regex:          regexp.MustCompile(`^/api/v3/findmany/host_apply_plan/bk_biz_id/([0-9]+)/conflict/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "RunHostApplyReconcileRegex",
+		Description:    "立即执行业务的主机属性自动应用巡检",
+		Regex:          regexp.MustCompile(`^/api/v3/updatemany/host_apply_plan/bk_biz_id/([0-9]+)/reconcile/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.Update,
 	},
 }
 
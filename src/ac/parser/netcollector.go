@@ -24,6 +24,7 @@ const (
 	findNetCollectorsPattern  = "/api/v3/collector/netcollect/collector/action/search"
 	updateNetCollectorPattern = "/api/v3/collector/netcollect/collector/action/update"
 	startNetCollectorPattern  = "/api/v3/collector/netcollect/collector/action/discover"
+	snmpDiscoverPattern       = "/api/v3/collector/netcollect/collector/action/snmp_discover"
 )
 
 func (ps *parseStream) netCollector() *parseStream {
@@ -73,6 +74,20 @@ func (ps *parseStream) netCollector() *parseStream {
 		return ps
 	}
 
+	// discover the network devices in the scan range of the net collectors by snmp.
+	if ps.hitPattern(snmpDiscoverPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.NetDataCollector,
+					Name:   meta.NetCollector,
+					Action: meta.UpdateMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	return ps
 }
 
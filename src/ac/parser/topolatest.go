@@ -42,6 +42,8 @@ func (ps *parseStream) topologyLatest() *parseStream {
 	}
 
 	ps.objectUniqueLatest().
+		objectValidationRuleLatest().
+		objectLifecycleLatest().
 		associationTypeLatest().
 		objectAssociationLatest().
 		objectInstanceAssociationLatest().
@@ -53,7 +55,8 @@ func (ps *parseStream) topologyLatest() *parseStream {
 		mainlineLatest().
 		setTemplate().
 		modelQuote().
-		fieldTemplate()
+		fieldTemplate().
+		encryptedAttr()
 
 	return ps
 }
@@ -195,6 +198,155 @@ func (ps *parseStream) objectUniqueLatest() *parseStream {
 	return ps
 }
 
+var (
+	createObjectValidationRuleRegexp = regexp.MustCompile(`^/api/v3/create/objectvalidationrule/object/[^\s/]+/?$`)
+	updateObjectValidationRuleRegexp = regexp.MustCompile(
+		`^/api/v3/update/objectvalidationrule/object/[^\s/]+/rule/[0-9]+/?$`)
+	deleteObjectValidationRuleRegexp = regexp.MustCompile(
+		`^/api/v3/delete/objectvalidationrule/object/[^\s/]+/rule/[0-9]+/?$`)
+	findObjectValidationRuleRegexp = regexp.MustCompile(`^/api/v3/find/objectvalidationrule/object/[^\s/]+/?$`)
+)
+
+// objectValidationRuleLatest the validation rules are a part of the model's definition, so changing them requires
+// the model's edit permission.
+func (ps *parseStream) objectValidationRuleLatest() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	if ps.hitRegexp(createObjectValidationRuleRegexp, http.MethodPost) ||
+		ps.hitRegexp(updateObjectValidationRuleRegexp, http.MethodPut) ||
+		ps.hitRegexp(deleteObjectValidationRuleRegexp, http.MethodPost) {
+
+		if len(ps.RequestCtx.Elements) < 6 {
+			ps.err = errors.New("operate object validation rule, but got invalid url")
+			return ps
+		}
+
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: ps.RequestCtx.Elements[5]})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:       meta.Model,
+					Action:     meta.Update,
+					InstanceID: model.ID,
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(findObjectValidationRuleRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.Model,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+var (
+	updateObjectLifecycleRegexp = regexp.MustCompile(`^/api/v3/update/objectlifecycle/object/[^\s/]+/?$`)
+	deleteObjectLifecycleRegexp = regexp.MustCompile(`^/api/v3/delete/objectlifecycle/object/[^\s/]+/?$`)
+	findObjectLifecycleRegexp   = regexp.MustCompile(`^/api/v3/find/objectlifecycle/object/[^\s/]+/?$`)
+	transitInstStateRegexp      = regexp.MustCompile(`^/api/v3/transition/instance/object/[^\s/]+/inst/[0-9]+/?$`)
+)
+
+// objectLifecycleLatest the lifecycle is a part of the model's definition, so changing it requires the model's edit
+// permission, while transiting an instance's state is an update of the instance.
+func (ps *parseStream) objectLifecycleLatest() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	if ps.hitRegexp(updateObjectLifecycleRegexp, http.MethodPut) ||
+		ps.hitRegexp(deleteObjectLifecycleRegexp, http.MethodPost) {
+
+		if len(ps.RequestCtx.Elements) < 6 {
+			ps.err = errors.New("operate object lifecycle, but got invalid url")
+			return ps
+		}
+
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: ps.RequestCtx.Elements[5]})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:       meta.Model,
+					Action:     meta.Update,
+					InstanceID: model.ID,
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(findObjectLifecycleRegexp, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.Model,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(transitInstStateRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 8 {
+			ps.err = errors.New("transit instance state, but got invalid url")
+			return ps
+		}
+
+		instID, err := strconv.ParseInt(ps.RequestCtx.Elements[7], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("transit instance state, but got invalid instance id %s", ps.RequestCtx.Elements[7])
+			return ps
+		}
+
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: ps.RequestCtx.Elements[5]})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		instanceType, err := ps.getInstanceTypeByObject(model.ObjectID, model.ID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:       instanceType,
+					Action:     meta.Update,
+					InstanceID: instID,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
 const (
 	findManyAssociationKindLatestPattern = "/api/v3/find/associationtype"
 	createAssociationKindLatestPattern   = "/api/v3/create/associationtype"
@@ -474,6 +626,7 @@ func (ps *parseStream) getModelAsstUpdateResAttr(objID, asstObjID interface{}) (
 const (
 	findObjectInstanceAssociationLatestPattern        = "/api/v3/find/instassociation"
 	findObjectInstanceAssociationRelatedLatestPattern = "/api/v3/find/instassociation/related"
+	findInstAssociationGraphLatestPattern             = "/api/v3/find/instassociation/graph"
 	createObjectInstanceAssociationLatestPattern      = "/api/v3/create/instassociation"
 	createObjectManyInstanceAssociationLatestPattern  = "/api/v3/createmany/instassociation"
 )
@@ -545,6 +698,18 @@ func (ps *parseStream) objectInstanceAssociationLatest() *parseStream {
 		return ps
 	}
 
+	// find instance association graph operation, authorize the traversed objects in topo server.
+	if ps.hitPattern(findInstAssociationGraphLatestPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
 	// find instance's association related info operation.
 	if ps.hitPattern(findObjectInstanceAssociationRelatedLatestPattern, http.MethodPost) {
 		ps.Attribute.Resources = []meta.ResourceAttribute{
@@ -1329,10 +1329,12 @@ func (ps *parseStream) objectSet() *parseStream {
 }
 
 var (
-	searchAuditDict   = `/api/v3/find/audit_dict`
-	searchAuditList   = `/api/v3/findmany/audit_list`
-	searchAuditDetail = `/api/v3/find/audit`
-	searchInstAudit   = `/api/v3/find/inst_audit`
+	searchAuditDict    = `/api/v3/find/audit_dict`
+	searchAuditList    = `/api/v3/findmany/audit_list`
+	searchAuditDetail  = `/api/v3/find/audit`
+	searchInstAudit    = `/api/v3/find/inst_audit`
+	searchInstSnapshot = `/api/v3/find/audit/inst_snapshot`
+	diffInstSnapshot   = `/api/v3/find/audit/inst_snapshot/diff`
 )
 
 // NOCC:golint/fnsize(设计如此)
@@ -1377,6 +1379,19 @@ func (ps *parseStream) audit() *parseStream {
 		return ps
 	}
 
+	// instance snapshot is reconstructed from audit logs, so it uses the same permission as audit log list
+	if ps.hitPattern(searchInstSnapshot, http.MethodPost) || ps.hitPattern(diffInstSnapshot, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.AuditLog,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	if ps.hitPattern(searchInstAudit, http.MethodPost) {
 		query := new(metadata.InstAuditQueryInput)
 		body, err := ps.RequestCtx.getRequestBody()
@@ -808,6 +808,57 @@ func (a *apiServer) GroupRelResByIDs(ctx context.Context, h http.Header, kind me
 	return resp.Data, nil
 }
 
+// SearchDynamicGroupExportFile search dynamic group export files in the business.
+func (a *apiServer) SearchDynamicGroupExportFile(ctx context.Context, h http.Header, bizID int64,
+	opt *metadata.SearchDynamicGroupExportOption) (*metadata.DynamicGroupExportFileList, ccErr.CCErrorCoder) {
+
+	resp := new(metadata.DynamicGroupExportFileListResp)
+	subPath := "/dynamicgroup/export/search/file/%d"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath, bizID).
+		WithHeaders(h).
+		Do().
+		IntoCmdbResp(resp)
+
+	if err != nil {
+		return nil, ccErr.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// GetDynamicGroupExportFileChunk get a content chunk of the dynamic group export file.
+func (a *apiServer) GetDynamicGroupExportFileChunk(ctx context.Context, h http.Header, bizID int64,
+	groupID string, fileID, index int64) (*metadata.DynamicGroupExportFileChunk, ccErr.CCErrorCoder) {
+
+	resp := new(metadata.DynamicGroupExportChunkResp)
+	subPath := "/dynamicgroup/export/file/%d/%s/%d/chunk/%d"
+
+	err := a.client.Get().
+		WithContext(ctx).
+		SubResourcef(subPath, bizID, groupID, fileID, index).
+		WithHeaders(h).
+		Do().
+		IntoCmdbResp(resp)
+
+	if err != nil {
+		return nil, ccErr.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
 // HealthCheck check if api-server is healthy.
 func (a *apiServer) HealthCheck() (bool, error) {
 	resp := new(metric.HealthResponse)
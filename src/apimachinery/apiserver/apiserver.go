@@ -130,6 +130,11 @@ type ApiServerClientInterface interface {
 	GroupRelResByIDs(ctx context.Context, h http.Header, kind metadata.GroupByResKind,
 		opt *metadata.GroupRelResByIDsOption) (map[int64][]interface{}, errors.CCErrorCoder)
 
+	SearchDynamicGroupExportFile(ctx context.Context, h http.Header, bizID int64,
+		opt *metadata.SearchDynamicGroupExportOption) (*metadata.DynamicGroupExportFileList, errors.CCErrorCoder)
+	GetDynamicGroupExportFileChunk(ctx context.Context, h http.Header, bizID int64, groupID string, fileID,
+		index int64) (*metadata.DynamicGroupExportFileChunk, errors.CCErrorCoder)
+
 	HealthCheck() (bool, error)
 	SearchProject(ctx context.Context, h http.Header, params *metadata.SearchProjectOption) (resp *metadata.InstResult,
 		err error)
@@ -74,3 +74,29 @@ func (inst *auditlog) SearchAuditLog(ctx context.Context, h http.Header, param m
 
 	return resp.Data, nil
 }
+
+// SearchInstSnapshot api of reconstructing an instance at a point in time from its audit logs
+func (inst *auditlog) SearchInstSnapshot(ctx context.Context, h http.Header, opt *metadata.InstSnapshotOption) (
+	*metadata.InstSnapshot, errors.CCErrorCoder) {
+
+	resp := new(metadata.InstSnapshotResult)
+	subPath := "/read/auditlog/inst_snapshot"
+
+	err := inst.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -31,6 +31,8 @@ type AuditClientInterface interface {
 	SaveAuditLog(ctx context.Context, h http.Header, logs ...metadata.AuditLog) errors.CCErrorCoder
 	SearchAuditLog(ctx context.Context, h http.Header, param metadata.QueryCondition) (*metadata.AuditQueryResult,
 		errors.CCErrorCoder)
+	SearchInstSnapshot(ctx context.Context, h http.Header, opt *metadata.InstSnapshotOption) (
+		*metadata.InstSnapshot, errors.CCErrorCoder)
 }
 
 // NewAuditClientInterface TODO
@@ -0,0 +1,52 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package authrole defines the auth role client of core service
+package authrole
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// Interface defines auth role apis.
+type Interface interface {
+	CreateRole(ctx context.Context, h http.Header, opt *metadata.AuthRoleSpec) (int64, errors.CCErrorCoder)
+	UpdateRole(ctx context.Context, h http.Header, id int64, opt *metadata.AuthRoleSpec) errors.CCErrorCoder
+	DeleteRole(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	ListRole(ctx context.Context, h http.Header, opt *metadata.SearchAuthRoleOption) (*metadata.AuthRoleList,
+		errors.CCErrorCoder)
+	CreateRoleBinding(ctx context.Context, h http.Header, opt *metadata.CreateAuthRoleBindingOption) (int64,
+		errors.CCErrorCoder)
+	DeleteRoleBinding(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	ListRoleBinding(ctx context.Context, h http.Header, opt *metadata.SearchAuthRoleOption) (
+		*metadata.AuthRoleBindingList, errors.CCErrorCoder)
+	ListUserPolicy(ctx context.Context, h http.Header, opt *metadata.ListUserAuthPolicyOption) (
+		[]metadata.AuthPolicy, errors.CCErrorCoder)
+}
+
+// New auth role api client.
+func New(client rest.ClientInterface) Interface {
+	return &authRole{client: client}
+}
+
+type authRole struct {
+	client rest.ClientInterface
+}
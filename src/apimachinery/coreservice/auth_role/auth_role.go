@@ -0,0 +1,219 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package authrole
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// CreateRole create auth role
+func (a *authRole) CreateRole(ctx context.Context, h http.Header, opt *metadata.AuthRoleSpec) (int64,
+	errors.CCErrorCoder) {
+
+	resp := new(metadata.CreateResult)
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/create/auth/role").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return 0, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return 0, err
+	}
+
+	return resp.Data.ID, nil
+}
+
+// UpdateRole update auth role
+func (a *authRole) UpdateRole(ctx context.Context, h http.Header, id int64,
+	opt *metadata.AuthRoleSpec) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := a.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/auth/role/%d", id).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRole delete auth role together with its bindings
+func (a *authRole) DeleteRole(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	resp := new(metadata.BaseResp)
+
+	err := a.client.Delete().
+		WithContext(ctx).
+		SubResourcef("/delete/auth/role/%d", id).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListRole list auth roles
+func (a *authRole) ListRole(ctx context.Context, h http.Header, opt *metadata.SearchAuthRoleOption) (
+	*metadata.AuthRoleList, errors.CCErrorCoder) {
+
+	resp := new(metadata.AuthRoleListResp)
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/auth/role").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// CreateRoleBinding bind auth role to users
+func (a *authRole) CreateRoleBinding(ctx context.Context, h http.Header,
+	opt *metadata.CreateAuthRoleBindingOption) (int64, errors.CCErrorCoder) {
+
+	resp := new(metadata.CreateResult)
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/create/auth/role_binding").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return 0, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return 0, err
+	}
+
+	return resp.Data.ID, nil
+}
+
+// DeleteRoleBinding delete auth role binding
+func (a *authRole) DeleteRoleBinding(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	resp := new(metadata.BaseResp)
+
+	err := a.client.Delete().
+		WithContext(ctx).
+		SubResourcef("/delete/auth/role_binding/%d", id).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListRoleBinding list auth role bindings
+func (a *authRole) ListRoleBinding(ctx context.Context, h http.Header, opt *metadata.SearchAuthRoleOption) (
+	*metadata.AuthRoleBindingList, errors.CCErrorCoder) {
+
+	resp := new(metadata.AuthRoleBindingListResp)
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/auth/role_binding").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// ListUserPolicy list the policies of all the roles that are bound to the user
+func (a *authRole) ListUserPolicy(ctx context.Context, h http.Header, opt *metadata.ListUserAuthPolicyOption) (
+	[]metadata.AuthPolicy, errors.CCErrorCoder) {
+
+	resp := new(metadata.ListUserAuthPolicyResp)
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/auth/user_policy").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -23,16 +23,21 @@ import (
 	"configcenter/src/apimachinery/coreservice/association"
 	"configcenter/src/apimachinery/coreservice/auditlog"
 	"configcenter/src/apimachinery/coreservice/auth"
+	authrole "configcenter/src/apimachinery/coreservice/auth_role"
 	"configcenter/src/apimachinery/coreservice/cloud"
 	"configcenter/src/apimachinery/coreservice/common"
 	"configcenter/src/apimachinery/coreservice/count"
+	dgexport "configcenter/src/apimachinery/coreservice/dynamic_group_export"
 	fieldtmpl "configcenter/src/apimachinery/coreservice/field_template"
 	"configcenter/src/apimachinery/coreservice/host"
+	hostapplyreconcile "configcenter/src/apimachinery/coreservice/host_apply_reconcile"
+	hostattrhistory "configcenter/src/apimachinery/coreservice/host_attr_history"
 	"configcenter/src/apimachinery/coreservice/hostapplyrule"
 	"configcenter/src/apimachinery/coreservice/id_rule"
 	"configcenter/src/apimachinery/coreservice/instance"
 	"configcenter/src/apimachinery/coreservice/kube"
 	"configcenter/src/apimachinery/coreservice/label"
+	"configcenter/src/apimachinery/coreservice/lifecycle"
 	"configcenter/src/apimachinery/coreservice/mainline"
 	"configcenter/src/apimachinery/coreservice/model"
 	modelquote "configcenter/src/apimachinery/coreservice/model_quote"
@@ -43,6 +48,7 @@ import (
 	"configcenter/src/apimachinery/coreservice/synchronize"
 	ccSystem "configcenter/src/apimachinery/coreservice/system"
 	"configcenter/src/apimachinery/coreservice/topographics"
+	validationrule "configcenter/src/apimachinery/coreservice/validation_rule"
 	"configcenter/src/apimachinery/rest"
 	"configcenter/src/apimachinery/transaction"
 	"configcenter/src/apimachinery/util"
@@ -74,6 +80,12 @@ type CoreServiceClientInterface interface {
 	ModelQuote() modelquote.Interface
 	FieldTemplate() fieldtmpl.Interface
 	IDRule() idrule.Interface
+	ValidationRule() validationrule.Interface
+	Lifecycle() lifecycle.Interface
+	DynamicGroupExport() dgexport.Interface
+	AuthRole() authrole.Interface
+	HostAttrHistory() hostattrhistory.Interface
+	HostApplyReconcile() hostapplyreconcile.Interface
 }
 
 // NewCoreServiceClient TODO
@@ -208,3 +220,33 @@ func (c *coreService) FieldTemplate() fieldtmpl.Interface {
 func (c *coreService) IDRule() idrule.Interface {
 	return idrule.New(c.restCli)
 }
+
+// ValidationRule return the object validation rule client
+func (c *coreService) ValidationRule() validationrule.Interface {
+	return validationrule.New(c.restCli)
+}
+
+// Lifecycle return the object lifecycle client
+func (c *coreService) Lifecycle() lifecycle.Interface {
+	return lifecycle.New(c.restCli)
+}
+
+// DynamicGroupExport return the dynamic group export client
+func (c *coreService) DynamicGroupExport() dgexport.Interface {
+	return dgexport.New(c.restCli)
+}
+
+// AuthRole return the auth role client
+func (c *coreService) AuthRole() authrole.Interface {
+	return authrole.New(c.restCli)
+}
+
+// HostAttrHistory return the host attribute history client
+func (c *coreService) HostAttrHistory() hostattrhistory.Interface {
+	return hostattrhistory.New(c.restCli)
+}
+
+// HostApplyReconcile return the host apply reconcile client
+func (c *coreService) HostApplyReconcile() hostapplyreconcile.Interface {
+	return hostapplyreconcile.New(c.restCli)
+}
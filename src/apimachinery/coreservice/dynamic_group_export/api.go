@@ -0,0 +1,57 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package dynamicgroupexport defines the dynamic group export client of core service
+package dynamicgroupexport
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// Interface defines dynamic group export schedule and file apis.
+type Interface interface {
+	CreateExportSchedule(ctx context.Context, h http.Header, schedule *metadata.DynamicGroupExportSchedule) (
+		*metadata.RspID, errors.CCErrorCoder)
+	UpdateExportSchedule(ctx context.Context, h http.Header, opt *metadata.UpdateOption) errors.CCErrorCoder
+	DeleteExportSchedule(ctx context.Context, h http.Header, opt *metadata.DeleteOption) errors.CCErrorCoder
+	ListExportSchedule(ctx context.Context, h http.Header, opt *metadata.QueryCondition) (
+		*metadata.DynamicGroupExportScheduleList, errors.CCErrorCoder)
+
+	CreateExportFile(ctx context.Context, h http.Header, file *metadata.DynamicGroupExportFile) (*metadata.RspID,
+		errors.CCErrorCoder)
+	UpdateExportFile(ctx context.Context, h http.Header, opt *metadata.UpdateOption) errors.CCErrorCoder
+	DeleteExportFile(ctx context.Context, h http.Header, opt *metadata.DeleteOption) errors.CCErrorCoder
+	ListExportFile(ctx context.Context, h http.Header, opt *metadata.QueryCondition) (
+		*metadata.DynamicGroupExportFileList, errors.CCErrorCoder)
+	CreateExportChunk(ctx context.Context, h http.Header,
+		chunk *metadata.DynamicGroupExportFileChunk) errors.CCErrorCoder
+	FindExportChunk(ctx context.Context, h http.Header, opt *metadata.FindDynamicGroupExportChunkOption) (
+		*metadata.DynamicGroupExportFileChunk, errors.CCErrorCoder)
+}
+
+// New dynamic group export api client.
+func New(client rest.ClientInterface) Interface {
+	return &dynamicGroupExport{client: client}
+}
+
+type dynamicGroupExport struct {
+	client rest.ClientInterface
+}
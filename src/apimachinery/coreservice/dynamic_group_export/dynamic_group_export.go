@@ -0,0 +1,275 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package dynamicgroupexport
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// CreateExportSchedule create dynamic group export schedule
+func (d *dynamicGroupExport) CreateExportSchedule(ctx context.Context, h http.Header,
+	schedule *metadata.DynamicGroupExportSchedule) (*metadata.RspID, errors.CCErrorCoder) {
+
+	resp := new(metadata.CreateResult)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(schedule).
+		SubResourcef("/create/dynamicgroup/export_schedule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// UpdateExportSchedule update dynamic group export schedules
+func (d *dynamicGroupExport) UpdateExportSchedule(ctx context.Context, h http.Header,
+	opt *metadata.UpdateOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := d.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/dynamicgroup/export_schedule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExportSchedule delete dynamic group export schedules
+func (d *dynamicGroupExport) DeleteExportSchedule(ctx context.Context, h http.Header,
+	opt *metadata.DeleteOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := d.client.Delete().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/delete/dynamicgroup/export_schedule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListExportSchedule list dynamic group export schedules
+func (d *dynamicGroupExport) ListExportSchedule(ctx context.Context, h http.Header,
+	opt *metadata.QueryCondition) (*metadata.DynamicGroupExportScheduleList, errors.CCErrorCoder) {
+
+	resp := new(metadata.DynamicGroupExportScheduleListResp)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/dynamicgroup/export_schedule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// CreateExportFile create dynamic group export file record
+func (d *dynamicGroupExport) CreateExportFile(ctx context.Context, h http.Header,
+	file *metadata.DynamicGroupExportFile) (*metadata.RspID, errors.CCErrorCoder) {
+
+	resp := new(metadata.CreateResult)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(file).
+		SubResourcef("/create/dynamicgroup/export_file").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// UpdateExportFile update dynamic group export file records
+func (d *dynamicGroupExport) UpdateExportFile(ctx context.Context, h http.Header,
+	opt *metadata.UpdateOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := d.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/dynamicgroup/export_file").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExportFile delete dynamic group export files with their content
+func (d *dynamicGroupExport) DeleteExportFile(ctx context.Context, h http.Header,
+	opt *metadata.DeleteOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := d.client.Delete().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/delete/dynamicgroup/export_file").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListExportFile list dynamic group export file records
+func (d *dynamicGroupExport) ListExportFile(ctx context.Context, h http.Header,
+	opt *metadata.QueryCondition) (*metadata.DynamicGroupExportFileList, errors.CCErrorCoder) {
+
+	resp := new(metadata.DynamicGroupExportFileListResp)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/dynamicgroup/export_file").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// CreateExportChunk create a content chunk of the dynamic group export file
+func (d *dynamicGroupExport) CreateExportChunk(ctx context.Context, h http.Header,
+	chunk *metadata.DynamicGroupExportFileChunk) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(chunk).
+		SubResourcef("/create/dynamicgroup/export_file/chunk").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindExportChunk find a content chunk of the dynamic group export file
+func (d *dynamicGroupExport) FindExportChunk(ctx context.Context, h http.Header,
+	opt *metadata.FindDynamicGroupExportChunkOption) (*metadata.DynamicGroupExportFileChunk, errors.CCErrorCoder) {
+
+	resp := new(metadata.DynamicGroupExportChunkResp)
+
+	err := d.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/dynamicgroup/export_file/chunk").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -60,6 +60,12 @@ type Interface interface {
 		opt *metadata.ListTmplSimpleByUniqueOption) (*metadata.ListTmplSimpleResult, errors.CCErrorCoder)
 	ListFieldTmplSimplyByAttrTemplateID(ctx context.Context, h http.Header,
 		opt *metadata.ListTmplSimpleByAttrOption) (*metadata.ListTmplSimpleResult, errors.CCErrorCoder)
+	CreateFieldTemplateVersion(ctx context.Context, h http.Header, templateID int64) (*metadata.FieldTemplateVersion,
+		errors.CCErrorCoder)
+	ListFieldTemplateVersion(ctx context.Context, h http.Header, opt *metadata.CommonQueryOption) (
+		*metadata.FieldTemplateVersionInfo, errors.CCErrorCoder)
+	UpdateObjFieldTmplRelVersion(ctx context.Context, h http.Header,
+		opt *metadata.UpgradeObjFieldTmplVersionOption) errors.CCErrorCoder
 }
 
 // New field template api client.
@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fieldtmpl
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// CreateFieldTemplateVersion create a version snapshot of the current field template attributes and uniques
+func (t template) CreateFieldTemplateVersion(ctx context.Context, h http.Header, templateID int64) (
+	*metadata.FieldTemplateVersion, errors.CCErrorCoder) {
+
+	resp := new(metadata.FieldTemplateVersionResp)
+
+	err := t.client.Post().
+		WithContext(ctx).
+		SubResourcef("/create/field_template/%d/version", templateID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// ListFieldTemplateVersion list field template versions
+func (t template) ListFieldTemplateVersion(ctx context.Context, h http.Header, opt *metadata.CommonQueryOption) (
+	*metadata.FieldTemplateVersionInfo, errors.CCErrorCoder) {
+
+	resp := new(metadata.ListFieldTemplateVersionResp)
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/field_template/version").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// UpdateObjFieldTmplRelVersion pin the objects bound to the field template to the specified version
+func (t template) UpdateObjFieldTmplRelVersion(ctx context.Context, h http.Header,
+	opt *metadata.UpgradeObjFieldTmplVersionOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := t.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/field_template/object/version").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
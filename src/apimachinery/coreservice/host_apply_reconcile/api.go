@@ -0,0 +1,48 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package hostapplyreconcile defines the host apply reconcile client of core service
+package hostapplyreconcile
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// Interface defines the host apply reconcile setting and conflict apis.
+type Interface interface {
+	UpdateReconcileSetting(ctx context.Context, h http.Header,
+		setting *metadata.HostApplyReconcileSetting) errors.CCErrorCoder
+	ListReconcileSetting(ctx context.Context, h http.Header, opt *metadata.ListHostApplyReconcileSettingOption) (
+		[]metadata.HostApplyReconcileSetting, errors.CCErrorCoder)
+	ReplaceConflict(ctx context.Context, h http.Header,
+		opt *metadata.ReplaceHostApplyConflictOption) errors.CCErrorCoder
+	ListConflict(ctx context.Context, h http.Header, opt *metadata.ListHostApplyConflictOption) (
+		*metadata.HostApplyConflictResult, errors.CCErrorCoder)
+}
+
+// New host apply reconcile api client.
+func New(client rest.ClientInterface) Interface {
+	return &hostApplyReconcile{client: client}
+}
+
+type hostApplyReconcile struct {
+	client rest.ClientInterface
+}
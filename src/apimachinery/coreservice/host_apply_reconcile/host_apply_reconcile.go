@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package hostapplyreconcile
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// UpdateReconcileSetting create or update the host apply reconcile setting of a business
+func (h *hostApplyReconcile) UpdateReconcileSetting(ctx context.Context, header http.Header,
+	setting *metadata.HostApplyReconcileSetting) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := h.client.Put().
+		WithContext(ctx).
+		Body(setting).
+		SubResourcef("/update/host_apply_reconcile/setting").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	return resp.CCError()
+}
+
+// ListReconcileSetting list the host apply reconcile settings
+func (h *hostApplyReconcile) ListReconcileSetting(ctx context.Context, header http.Header,
+	opt *metadata.ListHostApplyReconcileSettingOption) ([]metadata.HostApplyReconcileSetting, errors.CCErrorCoder) {
+
+	resp := new(metadata.HostApplyReconcileSettingResp)
+
+	err := h.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/host_apply_reconcile/setting").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// ReplaceConflict replace all the host apply conflicts of a business
+func (h *hostApplyReconcile) ReplaceConflict(ctx context.Context, header http.Header,
+	opt *metadata.ReplaceHostApplyConflictOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := h.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/updatemany/host_apply_reconcile/conflict").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	return resp.CCError()
+}
+
+// ListConflict list the host apply conflicts of a business
+func (h *hostApplyReconcile) ListConflict(ctx context.Context, header http.Header,
+	opt *metadata.ListHostApplyConflictOption) (*metadata.HostApplyConflictResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.HostApplyConflictResp)
+
+	err := h.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/host_apply_reconcile/conflict").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+// Package hostattrhistory defines the host attribute history client of core service
+package hostattrhistory
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// Interface defines the collected host attribute history and drift apis.
+type Interface interface {
+	FindHostAttrHistory(ctx context.Context, h http.Header, opt *metadata.FindHostAttrHistoryOption) (
+		*metadata.HostAttrHistoryResult, errors.CCErrorCoder)
+	FindHostAttrValue(ctx context.Context, h http.Header, opt *metadata.FindHostAttrValueOption) (
+		[]metadata.HostAttrValue, errors.CCErrorCoder)
+	FindHostAttrDrift(ctx context.Context, h http.Header, opt *metadata.FindHostAttrDriftOption) (
+		*metadata.HostAttrDriftResult, errors.CCErrorCoder)
+}
+
+// New host attribute history api client.
+func New(client rest.ClientInterface) Interface {
+	return &hostAttrHistory{client: client}
+}
+
+type hostAttrHistory struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostattrhistory
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// FindHostAttrHistory find the collected host attribute history periods
+func (h *hostAttrHistory) FindHostAttrHistory(ctx context.Context, header http.Header,
+	opt *metadata.FindHostAttrHistoryOption) (*metadata.HostAttrHistoryResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.HostAttrHistoryResp)
+
+	err := h.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/host/attr_history").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// FindHostAttrValue find the first seen and last seen time of each collected value of the host attributes
+func (h *hostAttrHistory) FindHostAttrValue(ctx context.Context, header http.Header,
+	opt *metadata.FindHostAttrValueOption) ([]metadata.HostAttrValue, errors.CCErrorCoder) {
+
+	resp := new(metadata.HostAttrValueResp)
+
+	err := h.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/host/attr_history/value").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// FindHostAttrDrift find the host attributes whose collected value disagrees with the maintained value
+func (h *hostAttrHistory) FindHostAttrDrift(ctx context.Context, header http.Header,
+	opt *metadata.FindHostAttrDriftOption) (*metadata.HostAttrDriftResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.HostAttrDriftResp)
+
+	err := h.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/host/attr_drift").
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -290,3 +290,29 @@ func (inst *instance) GetInstanceObjectMapping(ctx context.Context, header http.
 
 	return resp.Data, nil
 }
+
+// DecryptInstAttr decrypt the encrypted attribute value of an instance
+func (inst *instance) DecryptInstAttr(ctx context.Context, h http.Header, objID string,
+	opt *metadata.DecryptInstAttrOption) (*metadata.DecryptInstAttrResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.DecryptInstAttrResp)
+	subPath := "/decrypt/model/%s/instance/attribute"
+
+	err := inst.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath, objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -54,6 +54,9 @@ type InstanceClientInterface interface {
 		*metadata.CountResponseContent, error)
 	GetInstanceObjectMapping(ctx context.Context, h http.Header, ids []int64) ([]metadata.ObjectMapping,
 		errors.CCErrorCoder)
+	// DecryptInstAttr decrypt the encrypted attribute value of an instance
+	DecryptInstAttr(ctx context.Context, h http.Header, objID string, opt *metadata.DecryptInstAttrOption) (
+		*metadata.DecryptInstAttrResult, errors.CCErrorCoder)
 }
 
 // NewInstanceClientInterface TODO
@@ -326,6 +326,111 @@ func (k *kube) ListWorkload(ctx context.Context, header http.Header, input *meta
 	return &result.Data, nil
 }
 
+// CreateNsResource create namespace resource
+func (k *kube) CreateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	data []types.NsResourceInterface) (*metadata.RspIDs, errors.CCErrorCoder) {
+
+	result := new(types.NsResCreateResp)
+
+	err := k.client.Post().
+		WithContext(ctx).
+		Body(data).
+		SubResourcef("/createmany/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	return &result.Data, nil
+}
+
+// UpdateNsResource update namespace resource
+func (k *kube) UpdateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResUpdateByIDsOption) errors.CCErrorCoder {
+
+	result := new(metadata.BaseResp)
+
+	err := k.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/updatemany/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return ccErr
+	}
+
+	return nil
+}
+
+// DeleteNsResource delete namespace resource
+func (k *kube) DeleteNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResDeleteByIDsOption) errors.CCErrorCoder {
+
+	result := new(metadata.BaseResp)
+
+	err := k.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return ccErr
+	}
+
+	return nil
+}
+
+// ListNsResource list namespace resource
+func (k *kube) ListNsResource(ctx context.Context, header http.Header, input *metadata.QueryCondition,
+	kind types.NsResourceType) (*types.NsResDataResp, errors.CCErrorCoder) {
+
+	result := types.NsResInstResp{
+		Data: types.NsResDataResp{
+			Kind: kind,
+			Info: make([]types.NsResourceInterface, 0),
+		},
+	}
+
+	err := k.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef("/findmany/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(&result)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	return &result.Data, nil
+}
+
 // BatchCreateNode batch create nodes
 func (k *kube) BatchCreateNode(ctx context.Context, header http.Header, data []types.OneNodeCreateOption) (
 	*types.CreateNodesResult, errors.CCErrorCoder) {
@@ -59,6 +59,19 @@ type KubeClientInterface interface {
 	ListWorkload(ctx context.Context, header http.Header, input *metadata.QueryCondition, kind types.WorkloadType) (
 		*types.WlDataResp, errors.CCErrorCoder)
 
+	// CreateNsResource create namespace resource
+	CreateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		data []types.NsResourceInterface) (*metadata.RspIDs, errors.CCErrorCoder)
+	// UpdateNsResource update namespace resource
+	UpdateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResUpdateByIDsOption) errors.CCErrorCoder
+	// DeleteNsResource delete namespace resource
+	DeleteNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResDeleteByIDsOption) errors.CCErrorCoder
+	// ListNsResource list namespace resource
+	ListNsResource(ctx context.Context, header http.Header, input *metadata.QueryCondition,
+		kind types.NsResourceType) (*types.NsResDataResp, errors.CCErrorCoder)
+
 	BatchCreateNode(ctx context.Context, header http.Header, data []types.OneNodeCreateOption) (
 		*types.CreateNodesResult, errors.CCErrorCoder)
 	SearchNode(ctx context.Context, header http.Header, input *metadata.QueryCondition) (*types.SearchNodeRsp,
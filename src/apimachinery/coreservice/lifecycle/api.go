@@ -0,0 +1,44 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package lifecycle defines the object lifecycle client of core service
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// Interface defines object lifecycle apis.
+type Interface interface {
+	SaveLifecycle(ctx context.Context, h http.Header, opt *metadata.SaveLifecycleOption) errors.CCErrorCoder
+	DeleteLifecycle(ctx context.Context, h http.Header, objID string) errors.CCErrorCoder
+	FindLifecycle(ctx context.Context, h http.Header, opt *metadata.FindLifecycleOption) (*metadata.ObjectLifecycle,
+		errors.CCErrorCoder)
+}
+
+// New object lifecycle api client.
+func New(client rest.ClientInterface) Interface {
+	return &lifecycle{client: client}
+}
+
+type lifecycle struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// SaveLifecycle create or update object lifecycle
+func (l *lifecycle) SaveLifecycle(ctx context.Context, h http.Header,
+	opt *metadata.SaveLifecycleOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := l.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/model/lifecycle").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteLifecycle delete object lifecycle
+func (l *lifecycle) DeleteLifecycle(ctx context.Context, h http.Header, objID string) errors.CCErrorCoder {
+	resp := new(metadata.BaseResp)
+
+	err := l.client.Delete().
+		WithContext(ctx).
+		SubResourcef("/delete/model/%s/lifecycle", objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindLifecycle find object lifecycle, returns nil if the object has no lifecycle
+func (l *lifecycle) FindLifecycle(ctx context.Context, h http.Header,
+	opt *metadata.FindLifecycleOption) (*metadata.ObjectLifecycle, errors.CCErrorCoder) {
+
+	resp := new(metadata.FindLifecycleResp)
+
+	err := l.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/model/lifecycle").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
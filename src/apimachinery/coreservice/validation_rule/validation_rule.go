@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package validationrule
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// CreateValidationRule create object validation rule
+func (v *validationRule) CreateValidationRule(ctx context.Context, h http.Header,
+	opt *metadata.CreateValidationRuleOption) (*metadata.RspID, errors.CCErrorCoder) {
+
+	resp := new(metadata.CreateResult)
+
+	err := v.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/create/model/validation_rule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// UpdateValidationRule update object validation rule
+func (v *validationRule) UpdateValidationRule(ctx context.Context, h http.Header,
+	opt *metadata.UpdateValidationRuleOption) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := v.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/update/model/validation_rule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteValidationRule delete object validation rule
+func (v *validationRule) DeleteValidationRule(ctx context.Context, h http.Header, objID string,
+	id int64) errors.CCErrorCoder {
+
+	resp := new(metadata.BaseResp)
+
+	err := v.client.Delete().
+		WithContext(ctx).
+		SubResourcef("/delete/model/%s/validation_rule/%d", objID, id).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListValidationRule list object validation rules
+func (v *validationRule) ListValidationRule(ctx context.Context, h http.Header,
+	opt *metadata.ListValidationRuleOption) ([]metadata.ObjectValidationRule, errors.CCErrorCoder) {
+
+	resp := new(metadata.ListValidationRuleResp)
+
+	err := v.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/model/validation_rule").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -63,7 +63,12 @@ type Interface interface {
 
 // NewServiceDiscovery new a simple discovery module which can be used to get alive server address
 func NewServiceDiscovery(client *zk.ZkClient, env string) (DiscoveryInterface, error) {
-	disc := registerdiscover.NewRegDiscoverEx(client)
+	return NewServiceDiscoveryWithRegDiscover(registerdiscover.NewRegDiscoverEx(client), env)
+}
+
+// NewServiceDiscoveryWithRegDiscover new a simple discovery module with the specified register and discover backend
+func NewServiceDiscoveryWithRegDiscover(disc *registerdiscover.RegDiscover, env string) (DiscoveryInterface,
+	error) {
 
 	d := &discover{
 		servers: make(map[string]*server),
@@ -90,6 +90,16 @@ type FieldTemplateInterface interface {
 		option metadata.ListTmplSimpleByAttrOption) (*metadata.ListFieldTemplateSimpleResp, errors.CCErrorCoder)
 	ListFieldTemplateModelStatus(ctx context.Context, header http.Header,
 		option metadata.ListFieldTmplModelStatusOption) (*metadata.ListFieldTmplTaskSyncResultResp, errors.CCErrorCoder)
+
+	// field template version
+	ListFieldTemplateVersion(ctx context.Context, header http.Header, option metadata.ListFieldTmplVersionOption) (
+		*metadata.ListFieldTemplateVersionResp, errors.CCErrorCoder)
+	CompareFieldTemplateVersion(ctx context.Context, header http.Header,
+		option metadata.CompareFieldTmplVersionOption) (*metadata.CompareFieldTmplVersionResp, errors.CCErrorCoder)
+	UpgradeObjFieldTemplateVersion(ctx context.Context, header http.Header,
+		option metadata.UpgradeObjFieldTmplVersionOption) (*metadata.Response, errors.CCErrorCoder)
+	RollbackFieldTemplate(ctx context.Context, header http.Header, option metadata.RollbackFieldTmplOption) (
+		*metadata.FieldTemplateVersionResp, errors.CCErrorCoder)
 }
 
 // NewFieldTemplateInterface TODO
@@ -623,3 +623,103 @@ func (ft FieldTemplate) ListFieldTemplateModelStatus(ctx context.Context, header
 
 	return ret, nil
 }
+
+// ListFieldTemplateVersion list field template versions
+func (ft FieldTemplate) ListFieldTemplateVersion(ctx context.Context, header http.Header,
+	option metadata.ListFieldTmplVersionOption) (*metadata.ListFieldTemplateVersionResp, errors.CCErrorCoder) {
+
+	ret := new(metadata.ListFieldTemplateVersionResp)
+	subPath := "/findmany/field_template/version"
+
+	err := ft.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CompareFieldTemplateVersion compare two versions of field template
+func (ft FieldTemplate) CompareFieldTemplateVersion(ctx context.Context, header http.Header,
+	option metadata.CompareFieldTmplVersionOption) (*metadata.CompareFieldTmplVersionResp, errors.CCErrorCoder) {
+
+	ret := new(metadata.CompareFieldTmplVersionResp)
+	subPath := "/find/field_template/version/difference"
+
+	err := ft.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// UpgradeObjFieldTemplateVersion upgrade objects to the specified field template version
+func (ft FieldTemplate) UpgradeObjFieldTemplateVersion(ctx context.Context, header http.Header,
+	option metadata.UpgradeObjFieldTmplVersionOption) (*metadata.Response, errors.CCErrorCoder) {
+
+	ret := new(metadata.Response)
+	subPath := "/update/field_template/object/version"
+
+	err := ft.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// RollbackFieldTemplate rollback field template to the specified version
+func (ft FieldTemplate) RollbackFieldTemplate(ctx context.Context, header http.Header,
+	option metadata.RollbackFieldTmplOption) (*metadata.FieldTemplateVersionResp, errors.CCErrorCoder) {
+
+	ret := new(metadata.FieldTemplateVersionResp)
+	subPath := "/update/field_template/rollback"
+
+	err := ft.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package manifest defines the topology manifest api machinery.
+package manifest
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// ManifestInterface topology manifest interface
+type ManifestInterface interface {
+	ExportManifest(ctx context.Context, h http.Header, opt *metadata.ExportManifestOption) (*metadata.Manifest,
+		errors.CCErrorCoder)
+	PlanManifest(ctx context.Context, h http.Header, opt *metadata.PlanManifestOption) (*metadata.ManifestPlan,
+		errors.CCErrorCoder)
+	ApplyManifest(ctx context.Context, h http.Header, opt *metadata.ApplyManifestOption) (*metadata.ManifestPlan,
+		errors.CCErrorCoder)
+}
+
+// NewManifestInterface new topology manifest interface
+func NewManifestInterface(client rest.ClientInterface) ManifestInterface {
+	return &manifest{client: client}
+}
+
+type manifest struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,100 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// ExportManifest export the live models and business topology to a manifest
+func (m *manifest) ExportManifest(ctx context.Context, h http.Header, opt *metadata.ExportManifestOption) (
+	*metadata.Manifest, errors.CCErrorCoder) {
+
+	ret := new(metadata.ExportManifestResp)
+	subPath := "/find/topo/manifest"
+
+	err := m.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret.Data, nil
+}
+
+// PlanManifest compare the manifest with the live system
+func (m *manifest) PlanManifest(ctx context.Context, h http.Header, opt *metadata.PlanManifestOption) (
+	*metadata.ManifestPlan, errors.CCErrorCoder) {
+
+	ret := new(metadata.ManifestPlanResp)
+	subPath := "/find/topo/manifest/plan"
+
+	err := m.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret.Data, nil
+}
+
+// ApplyManifest apply the reviewed plan of the manifest to the live system
+func (m *manifest) ApplyManifest(ctx context.Context, h http.Header, opt *metadata.ApplyManifestOption) (
+	*metadata.ManifestPlan, errors.CCErrorCoder) {
+
+	ret := new(metadata.ManifestPlanResp)
+	subPath := "/update/topo/manifest/apply"
+
+	err := m.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if err := ret.CCError(); err != nil {
+		return nil, err
+	}
+
+	return ret.Data, nil
+}
@@ -25,6 +25,7 @@ import (
 	"configcenter/src/apimachinery/toposerver/fieldtemplate"
 	"configcenter/src/apimachinery/toposerver/inst"
 	"configcenter/src/apimachinery/toposerver/kube"
+	"configcenter/src/apimachinery/toposerver/manifest"
 	"configcenter/src/apimachinery/toposerver/object"
 	"configcenter/src/apimachinery/toposerver/resourcedir"
 	"configcenter/src/apimachinery/toposerver/settemplate"
@@ -40,6 +41,7 @@ type TopoServerClientInterface interface {
 	ResourceDirectory() resourcedir.ResourceDirectoryInterface
 	Kube() kube.KubeOperationInterface
 	FieldTemplate() fieldtemplate.FieldTemplateInterface
+	Manifest() manifest.ManifestInterface
 }
 
 // NewTopoServerClient TODO
@@ -88,3 +90,8 @@ func (t *topoServer) ResourceDirectory() resourcedir.ResourceDirectoryInterface
 func (t *topoServer) FieldTemplate() fieldtemplate.FieldTemplateInterface {
 	return fieldtemplate.NewFieldTemplateInterface(t.restCli)
 }
+
+// Manifest topology manifest related interface initialization.
+func (t *topoServer) Manifest() manifest.ManifestInterface {
+	return manifest.NewManifestInterface(t.restCli)
+}
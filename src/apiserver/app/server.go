@@ -47,6 +47,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: apiSvr.onApiServerConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo:      svrInfo,
 	}
@@ -65,7 +66,18 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return fmt.Errorf("connect redis server failed, err: %s", err.Error())
 	}
 
-	limiter := service.NewLimiter(engine.ServiceManageClient().Client())
+	var limiterRuleStore service.LimiterRuleStore
+	if etcdClient := engine.EtcdClient(); etcdClient != nil {
+		limiterRuleStore = etcdClient.Client()
+	} else {
+		limiterRuleStore = engine.ServiceManageClient().Client()
+	}
+	limiter := service.NewLimiter(limiterRuleStore)
+	gateConf, err := service.ParsePriorityGateConfig("apiServer.limiter")
+	if err != nil {
+		return fmt.Errorf("parse api limiter priority gate config failed, err: %v", err)
+	}
+	limiter.SetPriorityGate(gateConf)
 	err = limiter.SyncLimiterRules()
 	if err != nil {
 		blog.Infof("SyncLimiterRules failed, err: %v", err)
@@ -20,7 +20,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"configcenter/src/ac/parser"
 	"configcenter/src/apimachinery/discovery"
@@ -68,6 +70,8 @@ const (
 // URLFilterChan url filter chan
 func (s *service) URLFilterChan(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	rid := httpheader.GetRid(req.Request.Header)
+	// lifecycle transition flag can only be set by topo server, do not accept it from the client
+	req.Request.Header.Del(httpheader.LifecycleTransitionHeader)
 
 	var kind RequestType
 	var err error
@@ -358,68 +362,140 @@ end
 return cnt
 `
 
+// KEYS[1] is the redis key of the token bucket
+// ARGV[1] is the rate of tokens per second, ARGV[2] is the burst, ARGV[3] is the current time in milliseconds
+// returns whether the request is allowed(1 or 0) and the remaining tokens
+const takeTokenScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil
+then
+	tokens = burst
+	ts = now
+end
+
+local elapsed = now - ts
+if elapsed < 0
+then
+	elapsed = 0
+end
+tokens = math.min(burst, tokens + elapsed * rate / 1000)
+
+local allowed = 0
+if tokens >= 1
+then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+
+return {allowed, math.floor(tokens)}
+`
+
+const (
+	// rateLimitRuleHeader is the response header of the most restrictive matched limiter rule
+	rateLimitRuleHeader = "X-RateLimit-Rule"
+	// rateLimitLimitHeader is the response header of the quota of the most restrictive matched limiter rule
+	rateLimitLimitHeader = "X-RateLimit-Limit"
+	// rateLimitRemainingHeader is the response header of the remaining quota of the most restrictive matched rule
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+
+	limiterRuleLabel     = "rule"
+	limiterResultLabel   = "result"
+	limiterPriorityLabel = "priority"
+)
+
+// limiterQuota is the quota of a matched limiter rule
+type limiterQuota struct {
+	rule      string
+	limit     int64
+	remaining int64
+}
+
 // LimiterFilter limit on a api request according to limiter rules
 func (s *service) LimiterFilter() func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
 	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
 		rid := httpheader.GetRid(req.Request.Header)
-		if s.limiter.LenOfRules() == 0 {
+		if s.limiter.LenOfRules() == 0 && !s.limiter.gate.enabled() {
 			fchain.ProcessFilter(req, resp)
 			return
 		}
 
-		rule := s.limiter.GetMatchedRule(req)
-		if rule == nil {
-			fchain.ProcessFilter(req, resp)
-			return
+		rules := s.limiter.GetMatchedRules(req)
+		var quota *limiterQuota
+		setQuota := func(q *limiterQuota) {
+			if quota == nil || q.remaining < quota.remaining {
+				quota = q
+			}
 		}
 
-		if rule.DenyAll {
-			blog.Errorf("too many requests, matched rule is %#v, rid: %s", *rule, rid)
+		releases := make([]func(), 0)
+		defer func() {
+			for _, release := range releases {
+				release()
+			}
+		}()
+
+		// deny all rule or the count rule with the minimum limit
+		if rule := s.limiter.GetMatchedRule(req); rule != nil {
+			q, ok := s.checkCountRule(rule, rid)
+			if !ok {
+				s.rejectByLimiter(req, resp, rule.RuleName, "too many requests")
+				return
+			}
+			if q != nil {
+				setQuota(q)
+			}
+		}
 
-			s.errorLimiterTotal.With(prometheus.Labels{
-				metrics.LabelAppCode: httpheader.GetAppCode(req.Request.Header),
-				metrics.LabelHandler: req.Request.RequestURI,
-			}).Inc()
+		caller := httpheader.GetAppCode(req.Request.Header) + "/" + httpheader.GetUser(req.Request.Header)
+		for _, rule := range rules {
+			if rule.IsTokenBucketRule() {
+				q, ok := s.checkTokenBucketRule(rule, rid)
+				if !ok {
+					s.rejectByLimiter(req, resp, rule.RuleName, "too many requests")
+					return
+				}
+				if q != nil {
+					setQuota(q)
+				}
+			}
 
-			rsp := metadata.BaseResp{
-				Code:   common.CCErrTooManyRequestErr,
-				ErrMsg: "too many requests",
-				Result: false,
+			if !rule.DenyAll && rule.MaxInFlight > 0 {
+				release, remaining, ok := s.limiter.acquireInFlight(rule, caller)
+				if !ok {
+					s.rejectByLimiter(req, resp, rule.RuleName, "too many concurrent requests")
+					return
+				}
+				releases = append(releases, release)
+				setQuota(&limiterQuota{rule: rule.RuleName, limit: rule.MaxInFlight, remaining: remaining})
+				s.limiterRuleTotal.With(prometheus.Labels{limiterRuleLabel: rule.RuleName,
+					limiterResultLabel: "allowed"}).Inc()
 			}
-			resp.WriteAsJson(rsp)
-			return
 		}
 
-		key := common.ApiCacheLimiterRulePrefix + rule.RuleName
-		result, err := s.cache.Eval(context.Background(), setRequestCntTTLScript, []string{key}, rule.TTL).Result()
-		if err != nil {
-			blog.Errorf("redis Eval failed, key:%s, rule:%#v, err: %v, rid: %s", key, *rule, err, rid)
-			fchain.ProcessFilter(req, resp)
-			return
+		if quota != nil {
+			resp.AddHeader(rateLimitRuleHeader, quota.rule)
+			resp.AddHeader(rateLimitLimitHeader, strconv.FormatInt(quota.limit, 10))
+			resp.AddHeader(rateLimitRemainingHeader, strconv.FormatInt(quota.remaining, 10))
 		}
-		cnt, ok := result.(int64)
-		if !ok {
-			blog.Errorf("execute setRequestCntTTLScript failed, key:%s, rule:%#v, err: %v, rid: %s",
-				key, *rule, result, rid)
-			fchain.ProcessFilter(req, resp)
-			return
-		}
-
-		if cnt > rule.Limit {
-			blog.Errorf("too many requests, matched rule is %#v, rid: %s", *rule, rid)
 
-			s.errorLimiterTotal.With(prometheus.Labels{
-				metrics.LabelAppCode: httpheader.GetAppCode(req.Request.Header),
-				metrics.LabelHandler: req.Request.RequestURI,
-			}).Inc()
-
-			rsp := metadata.BaseResp{
-				Code:   common.CCErrTooManyRequestErr,
-				ErrMsg: "too many requests",
-				Result: false,
+		if s.limiter.gate.enabled() {
+			priority := highestPriority(rules)
+			release, err := s.limiter.gate.acquire(req.Request.Context(), priority)
+			if err != nil {
+				s.limiterShedTotal.With(prometheus.Labels{limiterPriorityLabel: priority}).Inc()
+				s.rejectByLimiter(req, resp, "priority_"+priority, err.Error())
+				return
 			}
-			resp.WriteAsJson(rsp)
-			return
+			releases = append(releases, release)
 		}
 
 		fchain.ProcessFilter(req, resp)
@@ -427,6 +503,86 @@ func (s *service) LimiterFilter() func(req *restful.Request, resp *restful.Respo
 	}
 }
 
+// checkCountRule check the deny all rule or the count rule, returns the quota of count rule and if it's allowed,
+// the request is allowed when redis fails
+func (s *service) checkCountRule(rule *metadata.LimiterRule, rid string) (*limiterQuota, bool) {
+	if rule.DenyAll {
+		return nil, false
+	}
+
+	key := common.ApiCacheLimiterRulePrefix + rule.RuleName
+	result, err := s.cache.Eval(context.Background(), setRequestCntTTLScript, []string{key}, rule.TTL).Result()
+	if err != nil {
+		blog.Errorf("redis Eval failed, key:%s, rule:%#v, err: %v, rid: %s", key, *rule, err, rid)
+		return nil, true
+	}
+	cnt, ok := result.(int64)
+	if !ok {
+		blog.Errorf("execute setRequestCntTTLScript failed, key:%s, rule:%#v, err: %v, rid: %s",
+			key, *rule, result, rid)
+		return nil, true
+	}
+
+	if cnt > rule.Limit {
+		return nil, false
+	}
+
+	s.limiterRuleTotal.With(prometheus.Labels{limiterRuleLabel: rule.RuleName,
+		limiterResultLabel: "allowed"}).Inc()
+	return &limiterQuota{rule: rule.RuleName, limit: rule.Limit, remaining: rule.Limit - cnt}, true
+}
+
+// checkTokenBucketRule take a token from the token bucket of the rule, returns the quota and if it's allowed,
+// the request is allowed when redis fails
+func (s *service) checkTokenBucketRule(rule *metadata.LimiterRule, rid string) (*limiterQuota, bool) {
+	key := common.ApiCacheLimiterRulePrefix + rule.RuleName + ":token_bucket"
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := s.cache.Eval(context.Background(), takeTokenScript, []string{key}, rule.Rate, rule.GetBurst(),
+		now).Result()
+	if err != nil {
+		blog.Errorf("redis Eval failed, key: %s, rule: %#v, err: %v, rid: %s", key, *rule, err, rid)
+		return nil, true
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		blog.Errorf("execute takeTokenScript failed, key: %s, rule: %#v, result: %v, rid: %s", key, *rule, result,
+			rid)
+		return nil, true
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+
+	if allowed != 1 {
+		return nil, false
+	}
+
+	s.limiterRuleTotal.With(prometheus.Labels{limiterRuleLabel: rule.RuleName,
+		limiterResultLabel: "allowed"}).Inc()
+	return &limiterQuota{rule: rule.RuleName, limit: rule.GetBurst(), remaining: remaining}, true
+}
+
+// rejectByLimiter reject the request which is limited by the rule
+func (s *service) rejectByLimiter(req *restful.Request, resp *restful.Response, ruleName, reason string) {
+	blog.Errorf("%s, matched rule is %s, rid: %s", reason, ruleName, httpheader.GetRid(req.Request.Header))
+
+	s.errorLimiterTotal.With(prometheus.Labels{
+		metrics.LabelAppCode: httpheader.GetAppCode(req.Request.Header),
+		metrics.LabelHandler: req.Request.RequestURI,
+	}).Inc()
+	s.limiterRuleTotal.With(prometheus.Labels{limiterRuleLabel: ruleName,
+		limiterResultLabel: "rejected"}).Inc()
+
+	resp.AddHeader(rateLimitRuleHeader, ruleName)
+	resp.AddHeader(rateLimitRemainingHeader, "0")
+	rsp := metadata.BaseResp{
+		Code:   common.CCErrTooManyRequestErr,
+		ErrMsg: reason,
+		Result: false,
+	}
+	resp.WriteAsJson(rsp)
+}
+
 // JwtFilter the filter that handles the source of the jwt request
 func (s *service) JwtFilter() func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
 	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,23 +35,41 @@ import (
 	"github.com/emicklei/go-restful/v3"
 )
 
+// LimiterRuleStore is the store of limiter rules, which is the zookeeper or etcd client of the regdiscv
+type LimiterRuleStore interface {
+	// GetChildren get the rule names of the path
+	GetChildren(path string) ([]string, error)
+	// Get the rule data of the path
+	Get(path string) (string, error)
+}
+
 // Limiter TODO
 type Limiter struct {
-	zkCli        *zkclient.ZkClient
+	store        LimiterRuleStore
 	rules        map[string]*metadata.LimiterRule
 	lock         sync.RWMutex
 	syncDuration time.Duration
+	// inFlight is the in-flight request count of each rule and caller
+	inFlight     map[string]int64
+	inFlightLock sync.Mutex
+	gate         *priorityGate
 }
 
 // NewLimiter TODO
-func NewLimiter(zkCli *zkclient.ZkClient) *Limiter {
+func NewLimiter(store LimiterRuleStore) *Limiter {
 	return &Limiter{
-		zkCli:        zkCli,
+		store:        store,
 		syncDuration: 5 * time.Second,
+		inFlight:     make(map[string]int64),
 	}
 }
 
-// SyncLimiterRules sync the api limiter rules from zk
+// SetPriorityGate set the priority gate which limits the total in-flight requests of apiserver
+func (l *Limiter) SetPriorityGate(conf PriorityGateConfig) {
+	l.gate = newPriorityGate(conf)
+}
+
+// SyncLimiterRules sync the api limiter rules from regdiscv
 func (l *Limiter) SyncLimiterRules() error {
 	blog.Info("begin SyncLimiterRules")
 	path := types.CC_SERVLIMITER_BASEPATH
@@ -68,7 +87,7 @@ func (l *Limiter) SyncLimiterRules() error {
 
 func (l *Limiter) syncLimiterRules(path string) error {
 	blog.V(5).Infof("syncing limiter rules for path:%s", path)
-	children, err := l.zkCli.GetChildren(path)
+	children, err := l.store.GetChildren(path)
 	if err != nil {
 		if err == zkclient.ErrNoNode {
 			// if no rules, set rules to be empty
@@ -81,7 +100,7 @@ func (l *Limiter) syncLimiterRules(path string) error {
 
 	rules := make(map[string]*metadata.LimiterRule)
 	for _, child := range children {
-		data, err := l.zkCli.Get(path + "/" + child)
+		data, err := l.store.Get(path + "/" + child)
 		if err != nil {
 			blog.Errorf("fail to Get for path:%s, err:%s", path, err.Error())
 			continue
@@ -138,65 +157,125 @@ func (l *Limiter) LenOfRules() int {
 	return len(l.rules)
 }
 
-// GetMatchedRule get the matched limiter rule according request
+// GetMatchedRule get the matched limiter rule according request, deny all rule is returned first,
+// otherwise the count rule with the minimum limit is returned
 func (l *Limiter) GetMatchedRule(req *restful.Request) *metadata.LimiterRule {
-	header := req.Request.Header
 	var matchedRule *metadata.LimiterRule
 	var min int64 = 999999
-	rules := l.GetRules()
-	for _, r := range rules {
-		if r.AppCode == "" && r.User == "" && r.IP == "" && r.Url == "" && r.Method == "" {
-			blog.Errorf("wrong rule format, one of appcode, user, ip, url, method must be set, r:%#v", *r)
-			continue
+	for _, r := range l.GetMatchedRules(req) {
+		if r.DenyAll == true {
+			return r
 		}
-		if r.AppCode != "" {
-			if r.AppCode != httpheader.GetAppCode(header) {
-				continue
-			}
+		if r.IsCountRule() && r.Limit < min {
+			min = r.Limit
+			matchedRule = r
 		}
-		if r.User != "" {
-			if r.User != httpheader.GetUser(header) {
-				continue
-			}
+	}
+	return matchedRule
+}
+
+// GetMatchedRules get all the matched limiter rules according request, sorted by rule name
+func (l *Limiter) GetMatchedRules(req *restful.Request) []*metadata.LimiterRule {
+	matchedRules := make([]*metadata.LimiterRule, 0)
+	for _, r := range l.GetRules() {
+		if isRuleMatched(r, req) {
+			matchedRules = append(matchedRules, r)
 		}
-		if r.IP != "" {
-			hit := false
-			ips := strings.Split(r.IP, ",")
-			for _, ip := range ips {
-				if strings.TrimSpace(ip) == strings.TrimSpace(httpheader.GetReqRealIP(header)) {
-					hit = true
-					break
-				}
-			}
-			if hit == false {
-				continue
-			}
+	}
+	sort.Slice(matchedRules, func(i, j int) bool {
+		return matchedRules[i].RuleName < matchedRules[j].RuleName
+	})
+	return matchedRules
+}
+
+func isRuleMatched(r *metadata.LimiterRule, req *restful.Request) bool {
+	header := req.Request.Header
+	if r.AppCode == "" && r.User == "" && r.IP == "" && r.Url == "" && r.Method == "" {
+		blog.Errorf("wrong rule format, one of appcode, user, ip, url, method must be set, r:%#v", *r)
+		return false
+	}
+	if r.AppCode != "" {
+		if r.AppCode != httpheader.GetAppCode(header) {
+			return false
 		}
-		if r.Method != "" {
-			if util.Normalize(r.Method) != util.Normalize(req.Request.Method) {
-				continue
-			}
+	}
+	if r.User != "" {
+		if r.User != httpheader.GetUser(header) {
+			return false
 		}
-		if r.Url != "" {
-			match, err := regexp.MatchString(r.Url, req.Request.RequestURI)
-			if err != nil {
-				blog.Errorf("MatchString failed, r.Url:%s, reqURI:%s, err:%s", r.Url, req.Request.RequestURI,
-					err.Error())
-				continue
-			}
-			if !match {
-				continue
+	}
+	if r.IP != "" {
+		hit := false
+		ips := strings.Split(r.IP, ",")
+		for _, ip := range ips {
+			if strings.TrimSpace(ip) == strings.TrimSpace(httpheader.GetReqRealIP(header)) {
+				hit = true
+				break
 			}
 		}
+		if hit == false {
+			return false
+		}
+	}
+	if r.Method != "" {
+		if util.Normalize(r.Method) != util.Normalize(req.Request.Method) {
+			return false
+		}
+	}
+	if r.Url != "" {
+		match, err := regexp.MatchString(r.Url, req.Request.RequestURI)
+		if err != nil {
+			blog.Errorf("MatchString failed, r.Url:%s, reqURI:%s, err:%s", r.Url, req.Request.RequestURI,
+				err.Error())
+			return false
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
 
-		if r.DenyAll == true {
-			matchedRule = r
-			break
+// acquireInFlight acquire an in-flight slot of the concurrency rule for the caller,
+// returns the function to release the slot and the remaining slots, or false if there is no slot
+func (l *Limiter) acquireInFlight(rule *metadata.LimiterRule, caller string) (func(), int64, bool) {
+	key := rule.RuleName + "/" + caller
+
+	l.inFlightLock.Lock()
+	defer l.inFlightLock.Unlock()
+	if l.inFlight[key] >= rule.MaxInFlight {
+		return nil, 0, false
+	}
+	l.inFlight[key]++
+	remaining := rule.MaxInFlight - l.inFlight[key]
+
+	once := sync.Once{}
+	release := func() {
+		once.Do(func() {
+			l.inFlightLock.Lock()
+			defer l.inFlightLock.Unlock()
+			l.inFlight[key]--
+			if l.inFlight[key] <= 0 {
+				delete(l.inFlight, key)
+			}
+		})
+	}
+	return release, remaining, true
+}
+
+// highestPriority get the highest priority class of the matched rules
+func highestPriority(rules []*metadata.LimiterRule) string {
+	priority := ""
+	for _, r := range rules {
+		if r.Priority == "" {
+			continue
 		}
-		if r.Limit < min {
-			min = r.Limit
-			matchedRule = r
+		if priority == "" || priorityLevel(r.Priority) < priorityLevel(priority) {
+			priority = r.Priority
 		}
 	}
-	return matchedRule
+	if priority == "" {
+		return metadata.LimiterPriorityNormal
+	}
+	return priority
 }
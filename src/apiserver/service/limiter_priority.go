@@ -0,0 +1,250 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	defaultPriorityGateMaxQueueLength = 1000
+	defaultPriorityGateQueueTimeout   = 5 * time.Second
+)
+
+var (
+	// errRequestShed the queued request is shed by the higher priority requests because the queue is full
+	errRequestShed = errors.New("apiserver is overloaded, request is shed")
+	// errQueueTimeout the queued request waits for too long
+	errQueueTimeout = errors.New("apiserver is overloaded, request waits in queue timeout")
+)
+
+// PriorityGateConfig is the config of the priority gate which limits the total in-flight requests of apiserver
+type PriorityGateConfig struct {
+	// MaxInFlight is the max in-flight requests of one apiserver, the priority gate is disabled if it is 0
+	MaxInFlight int
+	// MaxQueueLength is the max requests waiting in queue for in-flight slots
+	MaxQueueLength int
+	// QueueTimeout is the max waiting time of the queued requests
+	QueueTimeout time.Duration
+}
+
+// ParsePriorityGateConfig parse the priority gate config from the config center
+func ParsePriorityGateConfig(prefix string) (PriorityGateConfig, error) {
+	conf := PriorityGateConfig{
+		MaxQueueLength: defaultPriorityGateMaxQueueLength,
+		QueueTimeout:   defaultPriorityGateQueueTimeout,
+	}
+
+	var err error
+	if cc.IsExist(prefix + ".maxInFlight") {
+		if conf.MaxInFlight, err = cc.Int(prefix + ".maxInFlight"); err != nil {
+			return conf, err
+		}
+	}
+
+	if cc.IsExist(prefix + ".maxQueueLength") {
+		if conf.MaxQueueLength, err = cc.Int(prefix + ".maxQueueLength"); err != nil {
+			return conf, err
+		}
+	}
+
+	if cc.IsExist(prefix + ".queueTimeoutSeconds") {
+		timeout, err := cc.Int(prefix + ".queueTimeoutSeconds")
+		if err != nil {
+			return conf, err
+		}
+		conf.QueueTimeout = time.Duration(timeout) * time.Second
+	}
+
+	if conf.MaxInFlight < 0 || conf.MaxQueueLength < 0 || conf.QueueTimeout < 0 {
+		return conf, errors.New("maxInFlight, maxQueueLength and queueTimeoutSeconds can not be negative")
+	}
+
+	return conf, nil
+}
+
+type priorityWaiter struct {
+	level int
+	// ready is closed when the waiter is admitted or shed
+	ready chan struct{}
+	err   error
+}
+
+// priorityGate limits the total in-flight requests, when it is full, the requests wait in the queue of their
+// priority class, and the higher priority requests are admitted first. when the queue is full, the lower priority
+// requests are shed first, so that the interactive requests are still served when the backends are slow.
+type priorityGate struct {
+	lock     sync.Mutex
+	conf     PriorityGateConfig
+	total    int
+	inFlight []int
+	queues   [][]*priorityWaiter
+	queued   int
+}
+
+func newPriorityGate(conf PriorityGateConfig) *priorityGate {
+	return &priorityGate{
+		conf:     conf,
+		inFlight: make([]int, len(metadata.LimiterPriorities)),
+		queues:   make([][]*priorityWaiter, len(metadata.LimiterPriorities)),
+	}
+}
+
+// priorityLevel get the level of the priority class, the lower level has the higher priority
+func priorityLevel(priority string) int {
+	for idx, p := range metadata.LimiterPriorities {
+		if p == priority {
+			return idx
+		}
+	}
+	return priorityLevel(metadata.LimiterPriorityNormal)
+}
+
+func (g *priorityGate) enabled() bool {
+	return g != nil && g.conf.MaxInFlight > 0
+}
+
+// acquire an in-flight slot for the request of the priority, returns the function to release the slot
+func (g *priorityGate) acquire(ctx context.Context, priority string) (func(), error) {
+	level := priorityLevel(priority)
+
+	g.lock.Lock()
+	if g.total < g.conf.MaxInFlight && g.queued == 0 {
+		g.admit(level)
+		g.lock.Unlock()
+		return g.releaseFunc(level), nil
+	}
+
+	if g.queued >= g.conf.MaxQueueLength {
+		victim := g.lowestWaiter(level)
+		if victim == nil {
+			g.lock.Unlock()
+			return nil, errRequestShed
+		}
+		g.remove(victim)
+		victim.err = errRequestShed
+		close(victim.ready)
+	}
+
+	waiter := &priorityWaiter{level: level, ready: make(chan struct{})}
+	g.queues[level] = append(g.queues[level], waiter)
+	g.queued++
+	g.lock.Unlock()
+
+	timer := time.NewTimer(g.conf.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-waiter.ready:
+	case <-timer.C:
+		err = errQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		g.lock.Lock()
+		select {
+		case <-waiter.ready:
+			// the waiter is admitted or shed at the same time
+		default:
+			g.remove(waiter)
+			g.lock.Unlock()
+			return nil, err
+		}
+		g.lock.Unlock()
+	}
+
+	if waiter.err != nil {
+		return nil, waiter.err
+	}
+	return g.releaseFunc(level), nil
+}
+
+// admit the request of the level, the lock must be held
+func (g *priorityGate) admit(level int) {
+	g.total++
+	g.inFlight[level]++
+}
+
+func (g *priorityGate) releaseFunc(level int) func() {
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			g.lock.Lock()
+			defer g.lock.Unlock()
+
+			g.total--
+			g.inFlight[level]--
+			g.dispatch()
+		})
+	}
+}
+
+// dispatch admit the queued requests with the highest priority first, the lock must be held
+func (g *priorityGate) dispatch() {
+	for g.total < g.conf.MaxInFlight && g.queued > 0 {
+		for level, queue := range g.queues {
+			if len(queue) == 0 {
+				continue
+			}
+			waiter := queue[0]
+			g.queues[level] = queue[1:]
+			g.queued--
+			g.admit(level)
+			close(waiter.ready)
+			break
+		}
+	}
+}
+
+// lowestWaiter get the newest waiter with the lowest priority which is lower than the level, the lock must be held
+func (g *priorityGate) lowestWaiter(level int) *priorityWaiter {
+	for l := len(g.queues) - 1; l > level; l-- {
+		if len(g.queues[l]) > 0 {
+			return g.queues[l][len(g.queues[l])-1]
+		}
+	}
+	return nil
+}
+
+// remove the waiter from its queue, the lock must be held
+func (g *priorityGate) remove(waiter *priorityWaiter) {
+	queue := g.queues[waiter.level]
+	for idx, w := range queue {
+		if w == waiter {
+			g.queues[waiter.level] = append(queue[:idx:idx], queue[idx+1:]...)
+			g.queued--
+			return
+		}
+	}
+}
+
+// stats get the in-flight and queued request count of the priority
+func (g *priorityGate) stats(priority string) (int, int) {
+	level := priorityLevel(priority)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.inFlight[level], len(g.queues[level])
+}
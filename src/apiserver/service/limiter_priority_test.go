@@ -0,0 +1,114 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestPriorityGateDispatchOrder(t *testing.T) {
+	gate := newPriorityGate(PriorityGateConfig{MaxInFlight: 1, MaxQueueLength: 10, QueueTimeout: time.Second})
+
+	release, err := gate.acquire(context.Background(), metadata.LimiterPriorityNormal)
+	if err != nil {
+		t.Fatalf("acquire the first slot failed, err: %v", err)
+	}
+
+	order := make(chan string, 2)
+	wait := func(priority string) {
+		rel, err := gate.acquire(context.Background(), priority)
+		if err != nil {
+			t.Errorf("acquire %s failed, err: %v", priority, err)
+			return
+		}
+		order <- priority
+		rel()
+	}
+
+	go wait(metadata.LimiterPriorityLow)
+	waitQueued(t, gate, metadata.LimiterPriorityLow, 1)
+	go wait(metadata.LimiterPriorityHigh)
+	waitQueued(t, gate, metadata.LimiterPriorityHigh, 1)
+
+	release()
+	if first := <-order; first != metadata.LimiterPriorityHigh {
+		t.Fatalf("expect high priority request to be admitted first, got %s", first)
+	}
+	if second := <-order; second != metadata.LimiterPriorityLow {
+		t.Fatalf("expect low priority request to be admitted second, got %s", second)
+	}
+}
+
+func TestPriorityGateShed(t *testing.T) {
+	gate := newPriorityGate(PriorityGateConfig{MaxInFlight: 1, MaxQueueLength: 1, QueueTimeout: time.Second})
+
+	release, err := gate.acquire(context.Background(), metadata.LimiterPriorityNormal)
+	if err != nil {
+		t.Fatalf("acquire the first slot failed, err: %v", err)
+	}
+	defer release()
+
+	lowErr := make(chan error, 1)
+	go func() {
+		_, err := gate.acquire(context.Background(), metadata.LimiterPriorityLow)
+		lowErr <- err
+	}()
+	waitQueued(t, gate, metadata.LimiterPriorityLow, 1)
+
+	// the queue is full, the low priority request is shed for the high priority one
+	go func() {
+		_, _ = gate.acquire(context.Background(), metadata.LimiterPriorityHigh)
+	}()
+	if err := <-lowErr; err != errRequestShed {
+		t.Fatalf("expect low priority request to be shed, got %v", err)
+	}
+
+	// no lower priority request can be shed, the new low priority request is rejected
+	if _, err := gate.acquire(context.Background(), metadata.LimiterPriorityLow); err != errRequestShed {
+		t.Fatalf("expect new low priority request to be shed, got %v", err)
+	}
+}
+
+func TestPriorityGateQueueTimeout(t *testing.T) {
+	gate := newPriorityGate(PriorityGateConfig{MaxInFlight: 1, MaxQueueLength: 1, QueueTimeout: 10 * time.Millisecond})
+
+	release, err := gate.acquire(context.Background(), metadata.LimiterPriorityNormal)
+	if err != nil {
+		t.Fatalf("acquire the first slot failed, err: %v", err)
+	}
+	defer release()
+
+	if _, err := gate.acquire(context.Background(), metadata.LimiterPriorityHigh); err != errQueueTimeout {
+		t.Fatalf("expect queue timeout, got %v", err)
+	}
+	if _, queued := gate.stats(metadata.LimiterPriorityHigh); queued != 0 {
+		t.Fatalf("expect timeout request to be removed from queue, got %d queued", queued)
+	}
+}
+
+func waitQueued(t *testing.T, gate *priorityGate, priority string, expect int) {
+	for i := 0; i < 100; i++ {
+		if _, queued := gate.stats(priority); queued == expect {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%s priority request is not queued", priority)
+}
@@ -19,7 +19,6 @@ package service
 
 import (
 	"configcenter/src/ac"
-	"configcenter/src/ac/iam"
 	"configcenter/src/apimachinery"
 	"configcenter/src/apimachinery/discovery"
 	"configcenter/src/common/auth"
@@ -62,6 +61,10 @@ type service struct {
 	// errorRequestTotal is the total number of request with error response
 	errorRequestTotal *prometheus.CounterVec
 	errorLimiterTotal *prometheus.CounterVec
+	// limiterRuleTotal is the total number of request allowed or rejected by each limiter rule
+	limiterRuleTotal *prometheus.CounterVec
+	// limiterShedTotal is the total number of request shed by the priority gate of each priority class
+	limiterShedTotal *prometheus.CounterVec
 }
 
 // SetConfig set config
@@ -73,7 +76,7 @@ func (s *service) SetConfig(engine *backbone.Engine, httpClient HTTPClient, disc
 	s.clientSet = clientSet
 	s.cache = cache
 	s.limiter = limiter
-	s.authorizer = iam.NewAuthorizer(clientSet)
+	s.authorizer = ac.NewAuthorizer(clientSet)
 }
 
 // WebServices TODO
@@ -206,4 +209,52 @@ func (s *service) metricsRegister() {
 		[]string{metrics.LabelHandler, metrics.LabelAppCode},
 	)
 	s.engine.Metric().Registry().MustRegister(s.errorLimiterTotal)
+
+	s.limiterRuleTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cmdb_api_limiter_rule_total",
+			Help: "total number of request allowed or rejected by each api limiter rule.",
+		},
+		[]string{limiterRuleLabel, limiterResultLabel},
+	)
+	s.engine.Metric().Registry().MustRegister(s.limiterRuleTotal)
+
+	s.limiterShedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cmdb_api_limiter_shed_total",
+			Help: "total number of request shed by the api limiter priority gate.",
+		},
+		[]string{limiterPriorityLabel},
+	)
+	s.engine.Metric().Registry().MustRegister(s.limiterShedTotal)
+
+	if !s.limiter.gate.enabled() {
+		return
+	}
+
+	for _, priority := range metadata.LimiterPriorities {
+		priority := priority
+		s.engine.Metric().Registry().MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name:        "cmdb_api_limiter_in_flight",
+				Help:        "current in-flight request of the api limiter priority gate.",
+				ConstLabels: prometheus.Labels{limiterPriorityLabel: priority},
+			},
+			func() float64 {
+				inFlight, _ := s.limiter.gate.stats(priority)
+				return float64(inFlight)
+			},
+		))
+		s.engine.Metric().Registry().MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name:        "cmdb_api_limiter_queue_length",
+				Help:        "current queued request of the api limiter priority gate.",
+				ConstLabels: prometheus.Labels{limiterPriorityLabel: priority},
+			},
+			func() float64 {
+				_, queued := s.limiter.gate.stats(priority)
+				return float64(queued)
+			},
+		))
+	}
 }
@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+func TestTopoURLFilterChain(t *testing.T) {
+	paths := []string{
+		"/create/objectvalidationrule/object/bk_switch",
+		"/update/objectvalidationrule/object/bk_switch/rule/1",
+		"/delete/objectvalidationrule/object/bk_switch/rule/1",
+		"/find/objectvalidationrule/object/bk_switch",
+		"/update/objectlifecycle/object/bk_switch",
+		"/delete/objectlifecycle/object/bk_switch",
+		"/find/objectlifecycle/object/bk_switch",
+		"/transition/instance/object/bk_switch/inst/1",
+	}
+
+	for _, path := range paths {
+		uri, expected := rootPath+path, "/topo/v3"+path
+		httpReq, err := http.NewRequest(http.MethodPost, uri, nil)
+		if err != nil {
+			t.Fatalf("new request %s failed, err: %v", uri, err)
+		}
+		httpReq.RequestURI = uri
+		req := restful.NewRequest(httpReq)
+
+		kind, err := URLPath(uri).FilterChain(req)
+		if err != nil || kind != TopoType {
+			t.Errorf("%s should be routed to topo server, got %s, err: %v", uri, kind, err)
+			continue
+		}
+		if req.Request.URL.Path != expected {
+			t.Errorf("%s should be revised to %s, got %s", uri, expected, req.Request.URL.Path)
+		}
+	}
+}
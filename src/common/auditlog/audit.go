@@ -23,6 +23,7 @@ import (
 	"configcenter/src/apimachinery/coreservice"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
@@ -151,3 +152,53 @@ func (a *audit) getDefaultAppID(kit *rest.Kit) (int64, error) {
 	blog.Errorf("no such default business when supplier account is %s, rid: %s", kit.SupplierAccount, kit.Rid)
 	return 0, fmt.Errorf("no such default business when supplier account is %s", kit.SupplierAccount)
 }
+
+// getEncryptedAttrIDs get the property ids of the encrypted attributes of the object
+func (a *audit) getEncryptedAttrIDs(kit *rest.Kit, objID string) ([]string, error) {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKObjIDField:                objID,
+			metadata.AttributeFieldIsEncrypted: true,
+		},
+		Fields:         []string{common.BKPropertyIDField},
+		DisableCounter: true,
+	}
+
+	resp, err := a.clientSet.Model().ReadModelAttr(kit.Ctx, kit.Header, objID, cond)
+	if err != nil {
+		blog.Errorf("get %s encrypted attributes failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+
+	propertyIDs := make([]string, len(resp.Info))
+	for idx, attr := range resp.Info {
+		propertyIDs[idx] = attr.PropertyID
+	}
+	return propertyIDs, nil
+}
+
+// maskEncryptedContent replace the encrypted attribute values in audit log details with mask,
+// the data is cloned before masking so that the caller's data is not changed
+func maskEncryptedContent(details *metadata.BasicContent, propertyIDs []string) {
+	if details == nil || len(propertyIDs) == 0 {
+		return
+	}
+
+	details.PreData = maskEncryptedData(details.PreData, propertyIDs)
+	details.CurData = maskEncryptedData(details.CurData, propertyIDs)
+	details.UpdateFields = maskEncryptedData(details.UpdateFields, propertyIDs)
+}
+
+func maskEncryptedData(data map[string]interface{}, propertyIDs []string) map[string]interface{} {
+	if len(data) == 0 {
+		return data
+	}
+
+	masked := mapstr.MapStr(data).Clone()
+	for _, propertyID := range propertyIDs {
+		if val, exists := masked[propertyID]; exists && val != nil && val != "" {
+			masked[propertyID] = cryptor.AttrValueMask
+		}
+	}
+	return masked
+}
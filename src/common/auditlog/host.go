@@ -56,6 +56,11 @@ func (h *hostAuditLog) generateAuditLog(parameter *generateAuditCommonParameter,
 		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "host audit log data")
 	}
 
+	encryptedAttrIDs, err := h.getEncryptedAttrIDs(kit, common.BKInnerObjIDHost)
+	if err != nil {
+		return nil, err
+	}
+
 	auditLogs := make([]metadata.AuditLog, len(data))
 	hostIDs := make([]int64, len(data))
 	for index, host := range data {
@@ -66,6 +71,9 @@ func (h *hostAuditLog) generateAuditLog(parameter *generateAuditCommonParameter,
 		}
 		hostIDs[index] = hostID
 
+		details := parameter.NewBasicContent(host)
+		maskEncryptedContent(details, encryptedAttrIDs)
+
 		auditLog := metadata.AuditLog{
 			AuditType:          metadata.HostType,
 			ResourceType:       metadata.HostRes,
@@ -77,7 +85,7 @@ func (h *hostAuditLog) generateAuditLog(parameter *generateAuditCommonParameter,
 			OperateFrom:        parameter.operateFrom,
 			OperationDetail: &metadata.InstanceOpDetail{
 				BasicOpDetail: metadata.BasicOpDetail{
-					Details: parameter.NewBasicContent(host),
+					Details: details,
 				},
 				ModelID: common.BKInnerObjIDHost,
 			},
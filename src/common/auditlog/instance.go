@@ -61,6 +61,11 @@ func (i *instanceAuditLog) generateAuditLog(parameter *generateAuditCommonParame
 		return nil, err
 	}
 
+	encryptedAttrIDs, err := i.getEncryptedAttrIDs(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
 	for index, inst := range data {
 		id, err := util.GetInt64ByInterface(inst[metadata.GetInstIDFieldByObjID(objID)])
 		if err != nil {
@@ -102,12 +107,19 @@ func (i *instanceAuditLog) generateAuditLog(parameter *generateAuditCommonParame
 				}
 			}
 
+			details = &metadata.BasicContent{
+				PreData:      inst,
+				UpdateFields: updateFields,
+			}
+		case metadata.AuditTransition:
 			details = &metadata.BasicContent{
 				PreData:      inst,
 				UpdateFields: updateFields,
 			}
 		}
 
+		maskEncryptedContent(details, encryptedAttrIDs)
+
 		auditLog := metadata.AuditLog{
 			AuditType:    metadata.GetAuditTypeByObjID(objID, isMainline),
 			ResourceType: metadata.GetResourceTypeByObjID(objID, isMainline),
@@ -120,7 +132,8 @@ func (i *instanceAuditLog) generateAuditLog(parameter *generateAuditCommonParame
 				BasicOpDetail: metadata.BasicOpDetail{
 					Details: details,
 				},
-				ModelID: objID,
+				ModelID:    objID,
+				Transition: parameter.transition,
 			},
 		}
 		auditLogs[index] = auditLog
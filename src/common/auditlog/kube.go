@@ -135,6 +135,35 @@ func (c *kubeAuditLog) GenerateWorkloadAuditLog(param *generateAuditCommonParame
 	return auditLogs, nil
 }
 
+// kubeNsResourceData kube namespace resource audit data struct, including resource type and its actual data
+type kubeNsResourceData struct {
+	Kind types.NsResourceType      `json:"kind" bson:"kind"`
+	Data types.NsResourceInterface `json:"data" bson:"data"`
+}
+
+// GenerateNsResourceAuditLog generate audit log of kube namespace resource.
+func (c *kubeAuditLog) GenerateNsResourceAuditLog(param *generateAuditCommonParameter,
+	data []types.NsResourceInterface, kind types.NsResourceType) ([]metadata.AuditLog, errors.CCErrorCoder) {
+
+	auditLogs := make([]metadata.AuditLog, len(data))
+
+	for index, d := range data {
+		res := &kubeNsResourceData{
+			Kind: kind,
+			Data: d,
+		}
+
+		base := d.GetNsResourceBase()
+		auditLog, err := c.generateAuditLog(param, metadata.KubeNsResource, base.ID, base.BizID, &base.Name, res)
+		if err != nil {
+			return nil, err
+		}
+		auditLogs[index] = auditLog
+	}
+
+	return auditLogs, nil
+}
+
 func (c *kubeAuditLog) generateAuditLog(param *generateAuditCommonParameter, typ metadata.ResourceType,
 	id, bizID int64, name *string, data interface{}) (metadata.AuditLog, errors.CCErrorCoder) {
 
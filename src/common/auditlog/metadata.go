@@ -27,6 +27,7 @@ type generateAuditCommonParameter struct {
 	action       metadata.ActionType
 	operateFrom  metadata.OperateFromType
 	updateFields map[string]interface{}
+	transition   *metadata.LifecycleTransitionDetail
 }
 
 // NewGenerateAuditCommonParameter TODO
@@ -49,6 +50,14 @@ func (a *generateAuditCommonParameter) WithUpdateFields(updateFields map[string]
 	return a
 }
 
+// WithTransition set the lifecycle state transition detail for the transition operation
+func (a *generateAuditCommonParameter) WithTransition(
+	transition *metadata.LifecycleTransitionDetail) *generateAuditCommonParameter {
+
+	a.transition = transition
+	return a
+}
+
 // NewBasicContent get basicContent by data and self.
 func (a *generateAuditCommonParameter) NewBasicContent(data map[string]interface{}) *metadata.BasicContent {
 	var basicDetail *metadata.BasicContent
@@ -61,7 +70,7 @@ func (a *generateAuditCommonParameter) NewBasicContent(data map[string]interface
 		basicDetail = &metadata.BasicContent{
 			PreData: data,
 		}
-	case metadata.AuditUpdate:
+	case metadata.AuditUpdate, metadata.AuditTransition:
 		basicDetail = &metadata.BasicContent{
 			PreData:      data,
 			UpdateFields: a.updateFields,
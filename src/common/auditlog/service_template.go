@@ -0,0 +1,55 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package auditlog
+
+import (
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+type serviceTemplateAuditLog struct {
+	audit
+}
+
+// GenerateAuditLog generate audit of service template, data is the created service template for create action,
+// and is the service template before the update for update action.
+func (h *serviceTemplateAuditLog) GenerateAuditLog(parameter *generateAuditCommonParameter,
+	data *metadata.ServiceTemplate) *metadata.AuditLog {
+
+	return &metadata.AuditLog{
+		AuditType:    metadata.BusinessResourceType,
+		ResourceType: metadata.ServiceTemplateRes,
+		Action:       parameter.action,
+		BusinessID:   data.BizID,
+		ResourceID:   data.ID,
+		ResourceName: data.Name,
+		OperateFrom:  parameter.operateFrom,
+		OperationDetail: &metadata.BasicOpDetail{
+			Details: parameter.NewBasicContent(mapstr.NewFromStruct(data, "field")),
+		},
+	}
+}
+
+// NewServiceTemplateAuditLog new service template audit log handler
+func NewServiceTemplateAuditLog(clientSet coreservice.CoreServiceClientInterface) *serviceTemplateAuditLog {
+	return &serviceTemplateAuditLog{
+		audit: audit{
+			clientSet: clientSet,
+		},
+	}
+}
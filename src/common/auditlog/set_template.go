@@ -0,0 +1,61 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package auditlog
+
+import (
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// setTemplateSvcTmplIDsField is the field of the service template ids of set template in the audit log
+const setTemplateSvcTmplIDsField = "service_template_ids"
+
+type setTemplateAuditLog struct {
+	audit
+}
+
+// GenerateAuditLog generate audit of set template with its service template ids, data is the created set template
+// for create action, and is the set template before the update for update action.
+func (h *setTemplateAuditLog) GenerateAuditLog(parameter *generateAuditCommonParameter, data *metadata.SetTemplate,
+	svcTmplIDs []int64) *metadata.AuditLog {
+
+	details := mapstr.NewFromStruct(data, "field")
+	details[setTemplateSvcTmplIDsField] = svcTmplIDs
+
+	return &metadata.AuditLog{
+		AuditType:    metadata.BusinessResourceType,
+		ResourceType: metadata.SetTemplateRes,
+		Action:       parameter.action,
+		BusinessID:   data.BizID,
+		ResourceID:   data.ID,
+		ResourceName: data.Name,
+		OperateFrom:  parameter.operateFrom,
+		OperationDetail: &metadata.BasicOpDetail{
+			Details: parameter.NewBasicContent(details),
+		},
+	}
+}
+
+// NewSetTemplateAuditLog new set template audit log handler
+func NewSetTemplateAuditLog(clientSet coreservice.CoreServiceClientInterface) *setTemplateAuditLog {
+	return &setTemplateAuditLog{
+		audit: audit{
+			clientSet: clientSet,
+		},
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package auditlog
+
+import (
+	"reflect"
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// snapshotIgnoredFields are the fields that are changed by every update, they are not compared in snapshot diff
+var snapshotIgnoredFields = map[string]struct{}{
+	common.MongoMetaID:   {},
+	common.LastTimeField: {},
+}
+
+// ReplayInstAuditLogs applies the audit logs of an instance to its snapshot in order of occurrence.
+// the audit logs must be sorted by id in ascending order, and must be related to the snapshot's instance.
+func ReplayInstAuditLogs(snapshot *metadata.InstSnapshot, logs ...metadata.AuditLog) {
+	for _, log := range logs {
+		switch detail := log.OperationDetail.(type) {
+		case *metadata.InstanceOpDetail:
+			if detail.Details == nil {
+				continue
+			}
+			replayInstDetail(snapshot, log.Action, detail.Details)
+		case *metadata.HostTransferOpDetail:
+			topo := detail.CurData
+			snapshot.Topo = &topo
+		case *metadata.InstanceAssociationOpDetail:
+			replayInstAsstDetail(snapshot, log, detail)
+		default:
+			continue
+		}
+
+		snapshot.LastAuditID = log.ID
+		opTime := log.OperationTime
+		snapshot.LastOperationTime = &opTime
+	}
+}
+
+func replayInstDetail(snapshot *metadata.InstSnapshot, action metadata.ActionType, details *metadata.BasicContent) {
+	switch action {
+	case metadata.AuditCreate:
+		snapshot.Exists = true
+		snapshot.Data = mapstr.MapStr(details.CurData).Clone()
+	case metadata.AuditDelete:
+		snapshot.Exists = false
+		snapshot.Data = nil
+	default:
+		// update like actions records the previous data and the update fields, previous data is used as the
+		// baseline so that the snapshot is still correct if some of the earlier audit logs are missing
+		if details.PreData != nil {
+			snapshot.Data = mapstr.MapStr(details.PreData).Clone()
+		}
+		if snapshot.Data == nil {
+			snapshot.Data = make(mapstr.MapStr)
+		}
+		snapshot.Data.Merge(details.UpdateFields)
+		snapshot.Exists = true
+	}
+}
+
+func replayInstAsstDetail(snapshot *metadata.InstSnapshot, log metadata.AuditLog,
+	detail *metadata.InstanceAssociationOpDetail) {
+
+	// instance association audit log uses the source instance id as its resource id
+	srcInstID, err := util.GetInt64ByInterface(log.ResourceID)
+	if err != nil {
+		return
+	}
+
+	asst := metadata.InstSnapshotAsst{
+		ObjectAsstID: detail.AssociationID,
+		AsstKindID:   detail.AssociationKind,
+		SrcObjID:     detail.SourceModelID,
+		SrcInstID:    srcInstID,
+		DestObjID:    detail.TargetModelID,
+		DestInstID:   detail.TargetInstanceID,
+	}
+
+	idx := -1
+	for i, exist := range snapshot.Associations {
+		if isSameSnapshotAsst(exist, asst) {
+			idx = i
+			break
+		}
+	}
+
+	switch log.Action {
+	case metadata.AuditCreate:
+		if idx < 0 {
+			snapshot.Associations = append(snapshot.Associations, asst)
+		}
+	case metadata.AuditDelete:
+		if idx >= 0 {
+			snapshot.Associations = append(snapshot.Associations[:idx], snapshot.Associations[idx+1:]...)
+		}
+	}
+}
+
+func isSameSnapshotAsst(a, b metadata.InstSnapshotAsst) bool {
+	return a.ObjectAsstID == b.ObjectAsstID && a.SrcObjID == b.SrcObjID && a.SrcInstID == b.SrcInstID &&
+		a.DestObjID == b.DestObjID && a.DestInstID == b.DestInstID
+}
+
+// DiffInstSnapshot compares the snapshots of an instance at two points in time
+func DiffInstSnapshot(start, end *metadata.InstSnapshot) *metadata.InstSnapshotDiff {
+	diff := &metadata.InstSnapshotDiff{
+		Start:               start,
+		End:                 end,
+		Changes:             make([]metadata.InstAttrChange, 0),
+		AddedAssociations:   make([]metadata.InstSnapshotAsst, 0),
+		RemovedAssociations: make([]metadata.InstSnapshotAsst, 0),
+	}
+
+	fields := make([]string, 0)
+	fieldMap := make(map[string]struct{})
+	for _, data := range []mapstr.MapStr{start.Data, end.Data} {
+		for field := range data {
+			if _, exists := fieldMap[field]; exists {
+				continue
+			}
+			fieldMap[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if _, ignored := snapshotIgnoredFields[field]; ignored {
+			continue
+		}
+
+		before, after := start.Data[field], end.Data[field]
+		if isSnapshotValueEqual(before, after) {
+			continue
+		}
+		diff.Changes = append(diff.Changes, metadata.InstAttrChange{PropertyID: field, Before: before, After: after})
+	}
+
+	diff.TopoChanged = !reflect.DeepEqual(start.Topo, end.Topo)
+
+	for _, asst := range end.Associations {
+		if !containsSnapshotAsst(start.Associations, asst) {
+			diff.AddedAssociations = append(diff.AddedAssociations, asst)
+		}
+	}
+
+	for _, asst := range start.Associations {
+		if !containsSnapshotAsst(end.Associations, asst) {
+			diff.RemovedAssociations = append(diff.RemovedAssociations, asst)
+		}
+	}
+
+	return diff
+}
+
+func containsSnapshotAsst(assts []metadata.InstSnapshotAsst, asst metadata.InstSnapshotAsst) bool {
+	for _, exist := range assts {
+		if isSameSnapshotAsst(exist, asst) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSnapshotValueEqual compares two attribute values, numeric values are compared by value regardless of their
+// types, because the same value may be decoded as different numeric types from different audit logs
+func isSnapshotValueEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+
+	if !util.IsNumeric(a) || !util.IsNumeric(b) {
+		return false
+	}
+
+	aVal, err := util.GetFloat64ByInterface(a)
+	if err != nil {
+		return false
+	}
+
+	bVal, err := util.GetFloat64ByInterface(b)
+	if err != nil {
+		return false
+	}
+
+	return aVal == bVal
+}
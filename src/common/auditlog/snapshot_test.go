@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package auditlog
+
+import (
+	"testing"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func instAuditLog(id int64, action metadata.ActionType, content *metadata.BasicContent) metadata.AuditLog {
+	return metadata.AuditLog{
+		ID:           id,
+		ResourceType: metadata.HostRes,
+		Action:       action,
+		ResourceID:   int64(1),
+		OperationDetail: &metadata.InstanceOpDetail{
+			BasicOpDetail: metadata.BasicOpDetail{Details: content},
+			ModelID:       "host",
+		},
+	}
+}
+
+func TestReplayInstAuditLogs(t *testing.T) {
+	logs := []metadata.AuditLog{
+		instAuditLog(1, metadata.AuditCreate, &metadata.BasicContent{
+			CurData: map[string]interface{}{"bk_host_id": 1, "bk_host_name": "a", "bk_cpu": 4},
+		}),
+		instAuditLog(2, metadata.AuditUpdate, &metadata.BasicContent{
+			PreData:      map[string]interface{}{"bk_host_id": 1, "bk_host_name": "a", "bk_cpu": int64(4)},
+			UpdateFields: map[string]interface{}{"bk_host_name": "b"},
+		}),
+		{
+			ID:           3,
+			ResourceType: metadata.HostRes,
+			Action:       metadata.AuditTransferHostModule,
+			ResourceID:   int64(1),
+			OperationDetail: &metadata.HostTransferOpDetail{
+				PreData: metadata.HostBizTopo{BizID: 1},
+				CurData: metadata.HostBizTopo{BizID: 2},
+			},
+		},
+		{
+			ID:           4,
+			ResourceType: metadata.InstanceAssociationRes,
+			Action:       metadata.AuditCreate,
+			ResourceID:   int64(1),
+			OperationDetail: &metadata.InstanceAssociationOpDetail{
+				AssociationOpDetail: metadata.AssociationOpDetail{AssociationID: "host_run_app"},
+				SourceModelID:       "host",
+				TargetModelID:       "app",
+				TargetInstanceID:    10,
+			},
+		},
+	}
+
+	start := &metadata.InstSnapshot{ObjID: "host", InstID: 1}
+	ReplayInstAuditLogs(start, logs[:1]...)
+	require.True(t, start.Exists)
+	require.Equal(t, "a", start.Data["bk_host_name"])
+	require.Equal(t, int64(1), start.LastAuditID)
+
+	end := &metadata.InstSnapshot{ObjID: "host", InstID: 1}
+	ReplayInstAuditLogs(end, logs...)
+	require.True(t, end.Exists)
+	require.Equal(t, "b", end.Data["bk_host_name"])
+	require.Equal(t, int64(2), end.Topo.BizID)
+	require.Len(t, end.Associations, 1)
+	require.Equal(t, int64(4), end.LastAuditID)
+
+	diff := DiffInstSnapshot(start, end)
+	require.Equal(t, []metadata.InstAttrChange{{PropertyID: "bk_host_name", Before: "a", After: "b"}}, diff.Changes)
+	require.True(t, diff.TopoChanged)
+	require.Len(t, diff.AddedAssociations, 1)
+	require.Len(t, diff.RemovedAssociations, 0)
+
+	deleted := &metadata.InstSnapshot{ObjID: "host", InstID: 1}
+	ReplayInstAuditLogs(deleted, append(logs, instAuditLog(5, metadata.AuditDelete, &metadata.BasicContent{
+		PreData: map[string]interface{}{"bk_host_id": 1, "bk_host_name": "b"},
+	}))...)
+	require.False(t, deleted.Exists)
+	require.Nil(t, deleted.Data)
+}
@@ -29,12 +29,14 @@ import (
 	"configcenter/src/apimachinery/util"
 	"configcenter/src/common"
 	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/backbone/service_mange/etcd"
 	"configcenter/src/common/backbone/service_mange/zk"
 	"configcenter/src/common/blog"
 	crd "configcenter/src/common/confregdiscover"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/language"
 	"configcenter/src/common/metrics"
+	"configcenter/src/common/registerdiscover"
 	"configcenter/src/common/ssl"
 	"configcenter/src/common/types"
 	"configcenter/src/storage/dal/mongo"
@@ -56,6 +58,8 @@ type BackboneParameter struct {
 	ExtraUpdate  cc.ProcHandlerFunc
 	// config path
 	ConfigPath string
+	// ConfigDir is the directory of the config files, if set, configurations are read from it instead of regdiscv
+	ConfigDir string
 	// http server parameter
 	SrvInfo *types.ServerInfo
 	SrvRegdiscv
@@ -84,6 +88,30 @@ func newSvcManagerClient(ctx context.Context, svcManagerAddr string,
 	return nil, err
 }
 
+func newEtcdSvcManagerClient(ctx context.Context, svcManagerAddr string,
+	tlsConfig *ssl.TLSClientConfig) (*etcd.EtcdClient, error) {
+	var err error
+	for retry := 0; retry < maxRetry; retry++ {
+		client := etcd.NewEtcdClient(svcManagerAddr, 40*time.Second, tlsConfig)
+		if err = client.Start(); err != nil {
+			blog.Errorf("connect regdiscv [%s] failed: %v", svcManagerAddr, err)
+			time.Sleep(time.Second * 2)
+			continue
+		}
+
+		if err = client.Ping(); err != nil {
+			client.Stop()
+			blog.Errorf("connect regdiscv [%s] failed: %v", svcManagerAddr, err)
+			time.Sleep(time.Second * 2)
+			continue
+		}
+
+		return client, nil
+	}
+
+	return nil, err
+}
+
 func newConfig(ctx context.Context, srvInfo *types.ServerInfo, discovery discovery.DiscoveryInterface,
 	apiMachineryConfig *util.APIMachineryConfig) (*Config, error) {
 
@@ -167,29 +195,29 @@ func NewBackbone(ctx context.Context, input *BackboneParameter) (*Engine, error)
 	}
 
 	if !input.Disable {
-		client, err := newSvcManagerClient(ctx, input.Regdiscv, input.TLSConfig)
-		if err != nil {
+		engine.Regdiscv = input.Regdiscv
+		engine.TLSConfig = input.TLSConfig
+		if err = engine.connectSvcManager(ctx); err != nil {
 			return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", input.Regdiscv, err)
 		}
-		serviceDiscovery, err := discovery.NewServiceDiscovery(client, input.SrvInfo.Environment)
+		serviceDiscovery, err := discovery.NewServiceDiscoveryWithRegDiscover(engine.newRegDiscover(),
+			input.SrvInfo.Environment)
 		if err != nil {
 			return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", input.Regdiscv, err)
 		}
-		disc, err := NewServiceRegister(client)
+		disc, err := NewServiceRegister(engine.newRegDiscover())
 		if err != nil {
 			return nil, fmt.Errorf("new service discover failed, err:%v", err)
 		}
 
-		engine.client = client
 		engine.discovery = serviceDiscovery
 		engine.ServiceManageInterface = serviceDiscovery
 		engine.SvcDisc = disc
 
 		// add default configcenter
-		zkdisc := crd.NewZkRegDiscover(client)
 		configCenter := &cc.ConfigCenter{
 			Type:               common.BKDefaultConfigCenter,
-			ConfigCenterDetail: zkdisc,
+			ConfigCenterDetail: engine.confRegDiscv,
 		}
 		cc.AddConfigCenter(configCenter)
 
@@ -210,9 +238,25 @@ func NewBackbone(ctx context.Context, input *BackboneParameter) (*Engine, error)
 		}
 		engine.CoreAPI = machinery
 
-		if err = handleNotice(ctx, client.Client(), input.SrvInfo.Instance()); err != nil {
-			return nil, fmt.Errorf("handle notice failed, err: %v", err)
+		if engine.client != nil {
+			if err = handleNotice(ctx, engine.client.Client(), input.SrvInfo.Instance()); err != nil {
+				return nil, fmt.Errorf("handle notice failed, err: %v", err)
+			}
+		} else {
+			blog.Warnf("log notice is only supported by zookeeper regdiscv, skip it")
+		}
+	}
+
+	if input.ConfigDir != "" {
+		fileSource, err := cc.NewFileConfigSource(ctx, input.ConfigDir)
+		if err != nil {
+			return nil, fmt.Errorf("new file config source failed, err: %v", err)
 		}
+		cc.AddConfigCenter(&cc.ConfigCenter{
+			Type:               common.BKFileConfigCenter,
+			ConfigCenterDetail: fileSource,
+		})
+		cc.SetConfigCenterType(common.BKFileConfigCenter)
 	}
 
 	// get the real configuration center.
@@ -285,6 +329,8 @@ func New() (*Engine, error) {
 // SrvRegdiscv service registration discovery
 type SrvRegdiscv struct {
 	client                 *zk.ZkClient
+	etcdClient             *etcd.EtcdClient
+	confRegDiscv           crd.ConfRegDiscvIf
 	ServiceManageInterface discovery.ServiceManageInterface
 	SvcDisc                ServiceRegisterInterface
 	discovery              discovery.DiscoveryInterface
@@ -303,11 +349,53 @@ func (s *SrvRegdiscv) Discovery() discovery.DiscoveryInterface {
 	return s.discovery
 }
 
-// ServiceManageClient return service manage client
+// ServiceManageClient return service manage client, it is nil if the regdiscv is not zookeeper
 func (s *SrvRegdiscv) ServiceManageClient() *zk.ZkClient {
 	return s.client
 }
 
+// EtcdClient return etcd service manage client, it is nil if the regdiscv is not etcd
+func (s *SrvRegdiscv) EtcdClient() *etcd.EtcdClient {
+	return s.etcdClient
+}
+
+// ConfRegDiscover return the config register and discover of the regdiscv
+func (s *SrvRegdiscv) ConfRegDiscover() crd.ConfRegDiscvIf {
+	return s.confRegDiscv
+}
+
+// connectSvcManager connect the service manager by the scheme of regdiscv address, zookeeper is used by default
+func (s *SrvRegdiscv) connectSvcManager(ctx context.Context) error {
+	regDiscvType, hosts, err := registerdiscover.ParseRegDiscvAddress(s.Regdiscv)
+	if err != nil {
+		return err
+	}
+
+	switch regDiscvType {
+	case registerdiscover.RegDiscvTypeEtcd:
+		s.etcdClient, err = newEtcdSvcManagerClient(ctx, hosts, s.TLSConfig)
+		if err != nil {
+			return err
+		}
+		s.confRegDiscv = crd.NewEtcdRegDiscover(s.etcdClient)
+	default:
+		s.client, err = newSvcManagerClient(ctx, hosts, s.TLSConfig)
+		if err != nil {
+			return err
+		}
+		s.confRegDiscv = crd.NewZkRegDiscover(s.client)
+	}
+	return nil
+}
+
+// newRegDiscover create a new register and discover on the connected service manager
+func (s *SrvRegdiscv) newRegDiscover() *registerdiscover.RegDiscover {
+	if s.etcdClient != nil {
+		return registerdiscover.NewRegDiscoverWithEtcd(s.etcdClient)
+	}
+	return registerdiscover.NewRegDiscoverEx(s.client)
+}
+
 // Engine TODO
 type Engine struct {
 	CoreAPI            apimachinery.ClientSetInterface
@@ -0,0 +1,276 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package configcenter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"configcenter/src/common/blog"
+	crd "configcenter/src/common/confregdiscover"
+	ccerr "configcenter/src/common/errors"
+	"configcenter/src/common/language"
+	"configcenter/src/common/types"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// fileConfigEnvPrefix is the prefix of the environment variables which override the file configurations,
+	// the levels of the key are separated by fileConfigEnvSeparator, and the first level is the file name.
+	// e.g. BKCMDB_MONGODB__MONGODB__HOST overrides the mongodb.host of mongodb.yaml
+	fileConfigEnvPrefix    = "BKCMDB_"
+	fileConfigEnvSeparator = "__"
+
+	// fileConfigErrorDir is the sub directory of config dir which stores the error resources
+	fileConfigErrorDir = "errors"
+	// fileConfigLanguageDir is the sub directory of config dir which stores the language resources
+	fileConfigLanguageDir = "language"
+)
+
+// FileConfigSource is the config center which reads common.yaml, extra.yaml, mongodb.yaml and redis.yaml
+// from a local directory, e.g. the mounted directory in container, and the error and language resources
+// from its errors and language sub directories. the file configurations can be overridden by environment
+// variables, and the changes of the files are watched so that they can be hot reloaded.
+type FileConfigSource struct {
+	ctx     context.Context
+	confDir string
+}
+
+// NewFileConfigSource create a config center which reads configurations from the confDir
+func NewFileConfigSource(ctx context.Context, confDir string) (*FileConfigSource, error) {
+	if err := checkDir(confDir); err != nil {
+		return nil, err
+	}
+
+	return &FileConfigSource{
+		ctx:     ctx,
+		confDir: confDir,
+	}, nil
+}
+
+// Ping check if the config directory is available
+func (f *FileConfigSource) Ping() error {
+	return checkDir(f.confDir)
+}
+
+// Write is not supported, the configurations can only be changed by files
+func (f *FileConfigSource) Write(key string, data []byte) error {
+	return errors.New("file config source is read only")
+}
+
+// Read the configuration data of the key
+func (f *FileConfigSource) Read(key string) (string, error) {
+	data, err := f.read(key)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (f *FileConfigSource) read(key string) ([]byte, error) {
+	switch key {
+	case types.CC_SERVERROR_BASEPATH:
+		errCode, err := ccerr.LoadErrorResourceFromDir(filepath.Join(f.confDir, fileConfigErrorDir))
+		if err != nil {
+			return nil, fmt.Errorf("load error resource failed, err: %v", err)
+		}
+		return json.Marshal(errCode)
+
+	case types.CC_SERVLANG_BASEPATH:
+		languagePack, err := language.LoadLanguageResourceFromDir(filepath.Join(f.confDir, fileConfigLanguageDir))
+		if err != nil {
+			return nil, fmt.Errorf("load language resource failed, err: %v", err)
+		}
+		return json.Marshal(languagePack)
+	}
+
+	name := strings.TrimPrefix(key, types.CC_SERVCONF_BASEPATH+"/")
+	if name == key || name == "" {
+		return nil, fmt.Errorf("config key %s is not supported by file config source", key)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(f.confDir, name+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	return applyEnvOverrides(name, data, os.Environ())
+}
+
+// watchDirs returns the directories to be watched for the changes of the key
+func (f *FileConfigSource) watchDirs(key string) []string {
+	var root string
+	switch key {
+	case types.CC_SERVERROR_BASEPATH:
+		root = filepath.Join(f.confDir, fileConfigErrorDir)
+	case types.CC_SERVLANG_BASEPATH:
+		root = filepath.Join(f.confDir, fileConfigLanguageDir)
+	default:
+		// watch the directory instead of the file, so that the file replaced by editors or by the
+		// symbolic link switch of the mounted kubernetes config map can also be watched
+		return []string{f.confDir}
+	}
+
+	// the resources are stored in the sub directory of each language
+	dirs := make([]string, 0)
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// Discover the changes of the key's configuration files
+func (f *FileConfigSource) Discover(key string) (<-chan *crd.DiscoverEvent, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("new file watcher failed, err: %v", err)
+	}
+
+	for _, dir := range f.watchDirs(key) {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("watch directory %s failed, err: %v", dir, err)
+		}
+	}
+
+	env := make(chan *crd.DiscoverEvent, 1)
+	go f.loopDiscover(watcher, key, env)
+	return env, nil
+}
+
+func (f *FileConfigSource) loopDiscover(watcher *fsnotify.Watcher, key string, env chan *crd.DiscoverEvent) {
+	defer watcher.Close()
+
+	var previous []byte
+	notify := func() {
+		data, err := f.read(key)
+		if err != nil {
+			blog.Errorf("read config of key(%s) from file failed, err: %v", key, err)
+			env <- &crd.DiscoverEvent{Key: key, Err: err}
+			return
+		}
+
+		if previous != nil && reflect.DeepEqual(previous, data) {
+			return
+		}
+		previous = data
+		env <- &crd.DiscoverEvent{Key: key, Data: data}
+	}
+
+	notify()
+	for {
+		select {
+		case <-f.ctx.Done():
+			blog.Infof("discover config key(%s) from file done", key)
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			blog.V(4).Infof("config file of key(%s) changed, event: %s", key, event.String())
+			notify()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			blog.Errorf("watch config file of key(%s) failed, err: %v", key, err)
+		}
+	}
+}
+
+// applyEnvOverrides override the yaml configuration data of the named file by the environment variables,
+// the value of environment variable is parsed as yaml, so that the int, bool and list values are kept.
+func applyEnvOverrides(name string, data []byte, environ []string) ([]byte, error) {
+	prefix := fileConfigEnvPrefix + strings.ToUpper(name) + fileConfigEnvSeparator
+	overrides := make(map[string]string)
+	for _, kv := range environ {
+		idx := strings.Index(kv, "=")
+		if idx < 0 || !strings.HasPrefix(kv[:idx], prefix) {
+			continue
+		}
+		overrides[kv[len(prefix):idx]] = kv[idx+1:]
+	}
+
+	if len(overrides) == 0 {
+		return data, nil
+	}
+
+	conf := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("unmarshal %s config failed, err: %v", name, err)
+	}
+
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		var value interface{} = overrides[path]
+		if overrides[path] != "" {
+			if err := yaml.Unmarshal([]byte(overrides[path]), &value); err != nil {
+				value = overrides[path]
+			}
+		}
+		setConfigValue(conf, strings.Split(path, fileConfigEnvSeparator), value)
+		blog.Infof("%s config %s is overridden by environment variable", name, path)
+	}
+
+	return yaml.Marshal(conf)
+}
+
+// setConfigValue set the value of the key path, the key is matched case-insensitively like viper
+func setConfigValue(conf map[interface{}]interface{}, keys []string, value interface{}) {
+	for idx, key := range keys {
+		var matched interface{} = strings.ToLower(key)
+		for existKey := range conf {
+			if strings.EqualFold(fmt.Sprint(existKey), key) {
+				matched = existKey
+				break
+			}
+		}
+
+		if idx == len(keys)-1 {
+			conf[matched] = value
+			return
+		}
+
+		child, ok := conf[matched].(map[interface{}]interface{})
+		if !ok {
+			child = make(map[interface{}]interface{})
+			conf[matched] = child
+		}
+		conf = child
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package configcenter
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	data := []byte("mongodb:\n  host: 127.0.0.1\n  port: 27017\n  maxOpenConns: 3000\n")
+	environ := []string{
+		"BKCMDB_MONGODB__MONGODB__HOST=mongo.svc",
+		"BKCMDB_MONGODB__MONGODB__MAXOPENCONNS=100",
+		"BKCMDB_MONGODB__MONGODB__TLS__ENABLE=true",
+		"BKCMDB_REDIS__REDIS__HOST=redis.svc",
+		"PATH=/usr/bin",
+	}
+
+	result, err := applyEnvOverrides("mongodb", data, environ)
+	if err != nil {
+		t.Fatalf("apply env overrides failed, err: %v", err)
+	}
+
+	v, err := newViperParser(result)
+	if err != nil {
+		t.Fatalf("parse overridden config failed, err: %v", err)
+	}
+
+	if host := v.getString("mongodb.host"); host != "mongo.svc" {
+		t.Errorf("mongodb.host should be overridden to mongo.svc, but got %s", host)
+	}
+	if conns := v.getInt("mongodb.maxOpenConns"); conns != 100 {
+		t.Errorf("mongodb.maxOpenConns should be overridden to 100, but got %d", conns)
+	}
+	if !v.isConfigBoolType("mongodb.tls.enable") || !v.getBool("mongodb.tls.enable") {
+		t.Errorf("mongodb.tls.enable should be added as bool true")
+	}
+	if port := v.getInt("mongodb.port"); port != 27017 {
+		t.Errorf("mongodb.port should be kept as 27017, but got %d", port)
+	}
+
+	conf := make(map[string]interface{})
+	if err := yaml.Unmarshal(result, &conf); err != nil {
+		t.Fatalf("unmarshal overridden config failed, err: %v", err)
+	}
+	if _, exists := conf["redis"]; exists {
+		t.Errorf("redis config should not be overridden into mongodb config")
+	}
+
+	unchanged, err := applyEnvOverrides("common", data, environ)
+	if err != nil || string(unchanged) != string(data) {
+		t.Errorf("config without overrides should be kept, err: %v", err)
+	}
+}
@@ -322,6 +322,7 @@ func Mongo(prefix string) (mongo.Config, error) {
 	}
 
 	c := mongo.Config{
+		Engine:    parser.getString(prefix + ".engine"),
 		Address:   parser.getString(prefix + ".host"),
 		Port:      parser.getString(prefix + ".port"),
 		User:      parser.getString(prefix + ".usr"),
@@ -452,6 +453,35 @@ func Crypto(prefix string) (*cryptor.Config, error) {
 	return conf, nil
 }
 
+// AttrCrypto return model attribute crypto configuration information according to the prefix,
+// returns nil if model attribute crypto is not configured.
+func AttrCrypto(prefix string) (*cryptor.AttrCryptoConfig, error) {
+	var parser *viperParser
+	for sleepCnt := 0; sleepCnt < common.APPConfigWaitTime; sleepCnt++ {
+		parser = getCommonParser()
+		if parser != nil {
+			break
+		}
+		blog.Warn("the configuration of common is not ready yet")
+		time.Sleep(time.Duration(1) * time.Second)
+	}
+
+	if parser == nil {
+		return nil, errors.New("get common parser failed")
+	}
+
+	if !parser.isSet(prefix) || parser.getString(prefix+".activeKey") == "" {
+		return nil, nil
+	}
+
+	conf := new(cryptor.AttrCryptoConfig)
+	if err := parser.unmarshalKey(prefix, conf); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
 // String return the string value of the configuration information according to the key.
 func String(key string) (string, error) {
 	confLock.RLock()
@@ -20,7 +20,6 @@ import (
 	"encoding/json"
 	"errors"
 
-	"configcenter/src/common/backbone/service_mange/zk"
 	"configcenter/src/common/registerdiscover"
 	"configcenter/src/common/types"
 )
@@ -38,9 +37,9 @@ type ServiceRegisterInterface interface {
 }
 
 // NewServiceRegister TODO
-func NewServiceRegister(client *zk.ZkClient) (ServiceRegisterInterface, error) {
+func NewServiceRegister(client *registerdiscover.RegDiscover) (ServiceRegisterInterface, error) {
 	s := new(serviceRegister)
-	s.client = registerdiscover.NewRegDiscoverEx(client)
+	s.client = client
 	return s, nil
 }
 
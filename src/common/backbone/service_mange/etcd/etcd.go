@@ -0,0 +1,88 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package etcd TODO
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"configcenter/src/common/etcdclient"
+	"configcenter/src/common/ssl"
+)
+
+// EtcdClient do service register and discover by etcd
+type EtcdClient struct {
+	etcdCli   *etcdclient.EtcdClient
+	address   string
+	tlsConfig *ssl.TLSClientConfig
+	cancel    context.CancelFunc
+	rootCxt   context.Context
+	// leaseTTL is the ttl of the lease which the registered node is bound with, like the zookeeper session timeout
+	leaseTTL time.Duration
+}
+
+// NewEtcdClient create a object of EtcdClient
+func NewEtcdClient(etcdAddress string, leaseTTL time.Duration, tlsConfig *ssl.TLSClientConfig) *EtcdClient {
+	return &EtcdClient{
+		address:   etcdAddress,
+		tlsConfig: tlsConfig,
+		leaseTTL:  leaseTTL,
+	}
+}
+
+// Ping to ping server
+func (e *EtcdClient) Ping() error {
+	return e.etcdCli.Ping()
+}
+
+// Start used to run register and discover server
+func (e *EtcdClient) Start() error {
+	client, err := etcdclient.NewEtcdClient(strings.Split(e.address, ","), e.tlsConfig)
+	if err != nil {
+		return fmt.Errorf("fail to connect etcd, err: %v", err)
+	}
+	e.etcdCli = client
+
+	// create root context
+	e.rootCxt, e.cancel = context.WithCancel(context.Background())
+
+	return nil
+}
+
+// Stop used to stop register and discover server
+func (e *EtcdClient) Stop() error {
+	e.cancel()
+	return nil
+}
+
+// Client return etcd client
+func (e *EtcdClient) Client() *etcdclient.EtcdClient {
+	return e.etcdCli
+}
+
+// LeaseTTL the ttl of the register lease
+func (e *EtcdClient) LeaseTTL() time.Duration {
+	return e.leaseTTL
+}
+
+// WithCancel context with cancel
+func (e *EtcdClient) WithCancel() (context.Context, context.CancelFunc) {
+	return context.WithCancel(e.rootCxt)
+}
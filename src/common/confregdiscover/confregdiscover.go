@@ -18,6 +18,7 @@
 package confregdiscover
 
 import (
+	"configcenter/src/common/backbone/service_mange/etcd"
 	"configcenter/src/common/backbone/service_mange/zk"
 )
 
@@ -55,6 +56,13 @@ func NewConfRegDiscoverWithTimeOut(client *zk.ZkClient) *ConfRegDiscover {
 	return confRD
 }
 
+// NewConfRegDiscoverWithEtcd used to create a object of ConfRegDiscover which is based on etcd
+func NewConfRegDiscoverWithEtcd(client *etcd.EtcdClient) *ConfRegDiscover {
+	return &ConfRegDiscover{
+		confRD: ConfRegDiscvIf(NewEtcdRegDiscover(client)),
+	}
+}
+
 // Ping to ping server
 func (crd *ConfRegDiscover) Ping() error {
 	return crd.confRD.Ping()
@@ -0,0 +1,120 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package confregdiscover
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common/backbone/service_mange/etcd"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/etcdclient"
+)
+
+// EtcdRegDiscover config register and discover by etcd
+type EtcdRegDiscover struct {
+	etcdCli *etcdclient.EtcdClient
+	cancel  context.CancelFunc
+	rootCtx context.Context
+}
+
+// NewEtcdRegDiscover create a object of EtcdRegDiscover
+func NewEtcdRegDiscover(client *etcd.EtcdClient) *EtcdRegDiscover {
+	ctx, ctxCancel := client.WithCancel()
+	return &EtcdRegDiscover{
+		etcdCli: client.Client(),
+		rootCtx: ctx,
+		cancel:  ctxCancel,
+	}
+}
+
+// Ping to ping server
+func (e *EtcdRegDiscover) Ping() error {
+	return e.etcdCli.Ping()
+}
+
+// Write to save config data into etcd
+func (e *EtcdRegDiscover) Write(key string, data []byte) error {
+	return e.etcdCli.Put(key, string(data))
+}
+
+// Read the config data from etcd
+func (e *EtcdRegDiscover) Read(key string) (string, error) {
+	return e.etcdCli.Get(key)
+}
+
+// Discover the config data change of the key
+func (e *EtcdRegDiscover) Discover(key string) (<-chan *DiscoverEvent, error) {
+	env := make(chan *DiscoverEvent, 1)
+
+	go e.loopDiscover(e.rootCtx, key, env)
+
+	return env, nil
+}
+
+func (e *EtcdRegDiscover) loopDiscover(discvCtx context.Context, key string, env chan *DiscoverEvent) {
+	for {
+		discvEnv := &DiscoverEvent{
+			Err: nil,
+			Key: key,
+		}
+
+		result, err := e.etcdCli.Range(key, false)
+		if err != nil {
+			blog.Errorf("fail to get config of key(%s), err: %v", key, err)
+			discvEnv.Err = err
+			env <- discvEnv
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		if len(result.Kvs) == 0 {
+			blog.Warnf("config key(%s) is not exist, will watch after 5s", key)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		discvEnv.Data = result.Kvs[0].Value
+
+		// write into discoverEvent channel
+		env <- discvEnv
+
+		watchCtx, watchCancel := context.WithCancel(discvCtx)
+		watchCh, err := e.etcdCli.Watch(watchCtx, key, false, result.Header.Revision+1)
+		if err != nil {
+			watchCancel()
+			blog.Errorf("fail to watch config of key(%s), will retry after 5s, err: %v", key, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		select {
+		case <-discvCtx.Done():
+			watchCancel()
+			blog.Infof("discover config key(%s) done", key)
+			return
+		case resp, ok := <-watchCh:
+			watchCancel()
+			if !ok || resp.Err != nil {
+				blog.Errorf("watch config of key(%s) is broken, will retry after 1s", key)
+				time.Sleep(time.Second)
+				continue
+			}
+			blog.Infof("watch found the content of key(%s) changed", key)
+		}
+	}
+}
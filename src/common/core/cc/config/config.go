@@ -38,6 +38,7 @@ type CCAPIConfig struct {
 	RegDiscoverCertPassword string
 	RegisterIP              string
 	ExConfig                string
+	ConfigDir               string
 	Environment             string
 	Qps                     int64
 	Burst                   int64
@@ -137,13 +138,17 @@ func getPortFunc(addrPort string) (uint, error) {
 // AddFlags add common flags for cc api config
 func (conf *CCAPIConfig) AddFlags(fs *pflag.FlagSet, defaultAddrPort string) {
 	fs.StringVar(&conf.AddrPort, "addrport", defaultAddrPort, "The ip address and port for the serve on")
-	fs.StringVar(&conf.RegDiscover, "regdiscv", "", "hosts of register and discover server. e.g: 127.0.0.1:2181")
+	fs.StringVar(&conf.RegDiscover, "regdiscv", "",
+		"hosts of register and discover server. e.g: 127.0.0.1:2181 for zookeeper, etcd://127.0.0.1:2379 for etcd")
 	fs.StringVar(&conf.RegDiscoverCaFile, "regdiscv-cafile", "", "register and discover server ca file path")
 	fs.StringVar(&conf.RegDiscoverCertFile, "regdiscv-certfile", "", "register and discover server cert file")
 	fs.StringVar(&conf.RegDiscoverCertPassword, "regdiscv-certpassword", "", "register and discover server cert password")
 	fs.StringVar(&conf.RegDiscoverKeyFile, "regdiscv-keyfile", "", "register and discover server key file")
 	fs.BoolVar(&conf.RegDiscoverSkipVerify, "regdiscv-skipverify", true, "register and discover server skip ca verify")
 	fs.StringVar(&conf.ExConfig, "config", "", "The config path. e.g conf/api.conf")
+	fs.StringVar(&conf.ConfigDir, "config-dir", "", "the directory of common.yaml, extra.yaml, mongodb.yaml, "+
+		"redis.yaml and the errors, language resources, if set, the configurations are read from it instead of "+
+		"the regdiscv")
 	fs.StringVar(&conf.RegisterIP, "register-ip", "", "the ip address registered on zookeeper, it can be domain")
 	fs.StringVar(&conf.Environment, "env", "", "the environment of the server, used for service discovery")
 }
@@ -0,0 +1,209 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cryptor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// attrCipherPrefix is the prefix of the encrypted attribute value, the key version is followed by it, so that the
+	// value can be decrypted by the key it is encrypted with after the active key is rotated
+	attrCipherPrefix = "[Attr:::"
+	// attrCipherSuffix is the suffix of the encrypted attribute value's key version
+	attrCipherSuffix = "]"
+
+	// AttrValueMask is the masked representation of the encrypted attribute value
+	AttrValueMask = "******"
+)
+
+// attrCipherJsonRegexp matches the encrypted attribute value in json string
+var attrCipherJsonRegexp = regexp.MustCompile(`"\[Attr:::[^\]"\\]+\][^"\\]*"`)
+
+// AttrKeyConfig defines one version of the model attribute crypto key
+type AttrKeyConfig struct {
+	Version   string      `mapstructure:"version"`
+	Algorithm Algorithm   `mapstructure:"algorithm"`
+	Sm4       *Sm4Conf    `mapstructure:"sm4"`
+	AesGcm    *AesGcmConf `mapstructure:"aesGcm"`
+}
+
+// AttrCryptoConfig defines model attribute crypto configuration
+type AttrCryptoConfig struct {
+	// ActiveKey is the version of the key that is used to encrypt new attribute values
+	ActiveKey string `mapstructure:"activeKey"`
+	// Keys are all versions of the keys, old keys are used to decrypt the values that are not re-encrypted yet
+	Keys []AttrKeyConfig `mapstructure:"keys"`
+}
+
+// Validate AttrCryptoConfig
+func (c *AttrCryptoConfig) Validate() error {
+	if c.ActiveKey == "" {
+		return errors.New("active key is not set")
+	}
+
+	if len(c.Keys) == 0 {
+		return errors.New("keys are not set")
+	}
+
+	activeKeyExists := false
+	versions := make(map[string]struct{})
+	for _, key := range c.Keys {
+		if key.Version == "" || strings.ContainsAny(key.Version, attrCipherSuffix+"\"\\") {
+			return fmt.Errorf("key version %s is invalid", key.Version)
+		}
+
+		if _, exists := versions[key.Version]; exists {
+			return fmt.Errorf("key version %s is duplicated", key.Version)
+		}
+		versions[key.Version] = struct{}{}
+
+		if key.Version == c.ActiveKey {
+			activeKeyExists = true
+		}
+	}
+
+	if !activeKeyExists {
+		return fmt.Errorf("active key %s is not in keys", c.ActiveKey)
+	}
+
+	return nil
+}
+
+// AttrCryptor encrypts and decrypts model attribute values with versioned keys
+type AttrCryptor struct {
+	activeKey string
+	cryptors  map[string]Cryptor
+}
+
+// NewAttrCryptor new model attribute cryptor by config
+func NewAttrCryptor(conf *AttrCryptoConfig) (*AttrCryptor, error) {
+	if conf == nil {
+		return nil, errors.New("attribute crypto config is nil")
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("validate attribute crypto config failed, err: %v", err)
+	}
+
+	cryptors := make(map[string]Cryptor)
+	for _, key := range conf.Keys {
+		algorithm := key.Algorithm
+		if algorithm == "" {
+			algorithm = AesGcm
+		}
+
+		cryptor, err := NewCrypto(&Config{Enabled: true, Algorithm: algorithm, Sm4: key.Sm4, AesGcm: key.AesGcm})
+		if err != nil {
+			return nil, fmt.Errorf("new crypto for key %s failed, err: %v", key.Version, err)
+		}
+		cryptors[key.Version] = cryptor
+	}
+
+	return &AttrCryptor{activeKey: conf.ActiveKey, cryptors: cryptors}, nil
+}
+
+// Encrypt encrypts attribute value with the active key, value with the encrypted prefix is rejected, because it can
+// not be told apart from the ciphertext and would be stored as is without being encrypted
+func (a *AttrCryptor) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	if IsEncryptedAttrValue(plaintext) {
+		return "", errors.New("attribute value with the encrypted prefix can not be encrypted")
+	}
+
+	ciphertext, err := a.cryptors[a.activeKey].Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	return attrCipherPrefix + a.activeKey + attrCipherSuffix + ciphertext, nil
+}
+
+// Decrypt decrypts attribute value with the key it is encrypted with, value that is not encrypted is returned directly
+func (a *AttrCryptor) Decrypt(value string) (string, error) {
+	version, ciphertext, encrypted := parseAttrCipher(value)
+	if !encrypted {
+		return value, nil
+	}
+
+	cryptor, exists := a.cryptors[version]
+	if !exists {
+		return "", fmt.Errorf("attribute crypto key %s is not configured", version)
+	}
+
+	return cryptor.Decrypt(ciphertext)
+}
+
+// NeedReEncrypt checks if attribute value is not encrypted or is not encrypted with the active key
+func (a *AttrCryptor) NeedReEncrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	version, _, encrypted := parseAttrCipher(value)
+	return !encrypted || version != a.activeKey
+}
+
+// IsEncryptedAttrValue checks if attribute value is encrypted
+func IsEncryptedAttrValue(value string) bool {
+	_, _, encrypted := parseAttrCipher(value)
+	return encrypted
+}
+
+// parseAttrCipher parse encrypted attribute value into its key version and ciphertext
+func parseAttrCipher(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, attrCipherPrefix) {
+		return "", "", false
+	}
+
+	value = strings.TrimPrefix(value, attrCipherPrefix)
+	index := strings.Index(value, attrCipherSuffix)
+	if index <= 0 {
+		return "", "", false
+	}
+
+	return value[:index], value[index+len(attrCipherSuffix):], true
+}
+
+// MaskAttrValues replaces the encrypted attribute values in data with the masked representation
+func MaskAttrValues(data map[string]interface{}) {
+	for key, value := range data {
+		switch val := value.(type) {
+		case string:
+			if IsEncryptedAttrValue(val) {
+				data[key] = AttrValueMask
+			}
+		case map[string]interface{}:
+			MaskAttrValues(val)
+		}
+	}
+}
+
+// MaskAttrJson replaces the encrypted attribute values in json string with the masked representation
+func MaskAttrJson(data string) string {
+	if !strings.Contains(data, attrCipherPrefix) {
+		return data
+	}
+
+	return attrCipherJsonRegexp.ReplaceAllString(data, `"`+AttrValueMask+`"`)
+}
@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cryptor
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestAttrCryptor(t *testing.T, activeKey string) *AttrCryptor {
+	conf := &AttrCryptoConfig{
+		ActiveKey: activeKey,
+		Keys: []AttrKeyConfig{
+			{Version: "v1", AesGcm: &AesGcmConf{Key: "1234567812345678"}},
+			{Version: "v2", AesGcm: &AesGcmConf{Key: "8765432187654321"}},
+		},
+	}
+
+	cryptor, err := NewAttrCryptor(conf)
+	if err != nil {
+		t.Fatalf("new attribute cryptor failed, err: %v", err)
+	}
+	return cryptor
+}
+
+func TestAttrCryptorRotate(t *testing.T) {
+	v1Cryptor := newTestAttrCryptor(t, "v1")
+	v2Cryptor := newTestAttrCryptor(t, "v2")
+
+	plaintext := "bmc-password"
+	v1Cipher, err := v1Cryptor.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(v1Cipher, attrCipherPrefix+"v1]") || !IsEncryptedAttrValue(v1Cipher) {
+		t.Fatalf("ciphertext %s has no key version prefix", v1Cipher)
+	}
+
+	// value with the encrypted prefix is rejected instead of being stored as is
+	if cipher, err := v1Cryptor.Encrypt(v1Cipher); err == nil {
+		t.Fatalf("value with the encrypted prefix should be rejected, but got %s", cipher)
+	}
+
+	if v1Cryptor.NeedReEncrypt(v1Cipher) || !v2Cryptor.NeedReEncrypt(v1Cipher) || !v2Cryptor.NeedReEncrypt(plaintext) {
+		t.Fatalf("check need re-encrypt failed")
+	}
+
+	// the rotated cryptor can still decrypt values encrypted with the old key
+	result, err := v2Cryptor.Decrypt(v1Cipher)
+	if err != nil || result != plaintext {
+		t.Fatalf("decrypt old key value failed, result: %s, err: %v", result, err)
+	}
+
+	v2Cipher, err := v2Cryptor.Encrypt(result)
+	if err != nil || v2Cryptor.NeedReEncrypt(v2Cipher) {
+		t.Fatalf("re-encrypt value failed, ciphertext: %s, err: %v", v2Cipher, err)
+	}
+
+	// plaintext that is not encrypted yet is returned directly
+	if result, _ = v2Cryptor.Decrypt(plaintext); result != plaintext {
+		t.Fatalf("decrypt plaintext failed, result: %s", result)
+	}
+
+	if _, err = NewAttrCryptor(&AttrCryptoConfig{ActiveKey: "v3", Keys: []AttrKeyConfig{{Version: "v1"}}}); err == nil {
+		t.Fatalf("active key that is not configured should be invalid")
+	}
+}
+
+func TestMaskAttr(t *testing.T) {
+	cryptor := newTestAttrCryptor(t, "v1")
+	cipher, err := cryptor.Encrypt("license-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{"license": cipher, "name": "server"}
+	MaskAttrValues(data)
+	if data["license"] != AttrValueMask || data["name"] != "server" {
+		t.Fatalf("mask attribute values failed, data: %v", data)
+	}
+
+	json := `{"license":"` + cipher + `","name":"server"}`
+	expected := `{"license":"` + AttrValueMask + `","name":"server"}`
+	if masked := MaskAttrJson(json); masked != expected {
+		t.Fatalf("mask attribute json failed, result: %s", masked)
+	}
+}
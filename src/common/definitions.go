@@ -994,7 +994,7 @@ const (
 
 var FieldTypes = []string{FieldTypeSingleChar, FieldTypeLongChar, FieldTypeInt, FieldTypeFloat, FieldTypeEnum,
 	FieldTypeEnumMulti, FieldTypeDate, FieldTypeTime, FieldTypeUser, FieldTypeOrganization, FieldTypeTimeZone,
-	FieldTypeBool, FieldTypeList, FieldTypeTable, FieldTypeInnerTable, FieldTypeEnumQuote}
+	FieldTypeBool, FieldTypeList, FieldTypeTable, FieldTypeInnerTable, FieldTypeEnumQuote, FieldTypeComputed}
 
 const (
 	// FieldTypeSingleChar the single char filed type
@@ -1054,6 +1054,9 @@ const (
 	// FieldTypeIDRule the id rule field type
 	FieldTypeIDRule string = "id_rule"
 
+	// FieldTypeComputed the computed field type, its value is calculated and saved by the system
+	FieldTypeComputed string = "computed"
+
 	// FieldTypeSingleLenChar the single char length limit
 	FieldTypeSingleLenChar int = 256
 
@@ -1404,6 +1407,10 @@ const (
 	BKOpenSourceLoginPluginVersion = "opensource"
 	// BKSkipLoginPluginVersion TODO
 	BKSkipLoginPluginVersion = "skip-login"
+	// BKOIDCLoginPluginVersion login with the OpenID Connect authorization code flow
+	BKOIDCLoginPluginVersion = "oidc"
+	// BKLDAPLoginPluginVersion login with the user and password bound to the LDAP directory
+	BKLDAPLoginPluginVersion = "ldap"
 
 	// BKNoopMonitorPlugin TODO
 	// monitor plugin type
@@ -1641,6 +1648,8 @@ const (
 	SyncServiceTemplateHostApplyTaskFlag = "service_template_host_apply_sync"
 	// SyncInstIDRuleTaskFlag  instance id rule async task flag.
 	SyncInstIDRuleTaskFlag = "inst_id_rule_sync"
+	// DynamicGroupExportTaskFlag dynamic group export async task flag.
+	DynamicGroupExportTaskFlag = "dynamic_group_export"
 
 	// BKHostState TODO
 	BKHostState = "bk_state"
@@ -1672,6 +1681,7 @@ const (
 	BKStatusDetail               = "bk_status_detail"
 	BKLastEditor                 = "bk_last_editor"
 	BKSecretID                   = "bk_secret_id"
+	BKCloudEndpoint              = "bk_endpoint"
 	BKVpcID                      = "bk_vpc_id"
 	BKVpcName                    = "bk_vpc_name"
 	BKRegion                     = "bk_region"
@@ -1706,6 +1716,8 @@ const (
 // configcenter
 const (
 	BKDefaultConfigCenter = "zookeeper"
+	// BKFileConfigCenter the config center which reads the configurations from local files
+	BKFileConfigCenter = "file"
 )
 
 const (
@@ -1735,6 +1747,9 @@ const (
 const (
 	// BKTemplateID template id field
 	BKTemplateID = "bk_template_id"
+
+	// BKVersion field template version field
+	BKVersion = "version"
 )
 
 // ProcessPropertyName process property name
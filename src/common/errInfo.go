@@ -243,6 +243,36 @@ const (
 	// 该状态码只提供给支持可多选字段校验报错时使用，目前用户类型，枚举多选，枚举引用，组织类型校验可多选报错时可以使用
 	CCErrCommParamsNeedSingleChoice = 1199092
 
+	// CCErrCommValidationRuleFailed field %s does not satisfy the model validation rule %s
+	CCErrCommValidationRuleFailed = 1199093
+
+	// CCErrCommLifecycleTransitionNotAllowed the transition from state %s to state %s is not allowed
+	CCErrCommLifecycleTransitionNotAllowed = 1199094
+
+	// CCErrCommLifecycleTransitionNoPermission user %s can not transit the state from %s to %s
+	CCErrCommLifecycleTransitionNoPermission = 1199095
+
+	// CCErrCommLifecycleStateFieldRequired field %s must be set when the state is %s
+	CCErrCommLifecycleStateFieldRequired = 1199096
+
+	// CCErrCommManifestPlanChanged the live system has changed since the manifest was planned, please plan again
+	CCErrCommManifestPlanChanged = 1199097
+
+	// CCErrCommManifestHasConflicts the manifest has %d conflicts with the live system
+	CCErrCommManifestHasConflicts = 1199098
+
+	// CCErrCommAttrCryptoNotConfigured model attribute encryption is not configured
+	CCErrCommAttrCryptoNotConfigured = 1199099
+
+	// CCErrCommAttrCryptoFailed encrypt or decrypt the value of attribute %s failed
+	CCErrCommAttrCryptoFailed = 1199100
+
+	// CCErrCommValidationRuleInvalid the model validation rule %s is malformed, err: %s
+	CCErrCommValidationRuleInvalid = 1199101
+
+	// CCErrCommLifecycleTransitionRequired state field %s can only be changed by the state transition with a reason
+	CCErrCommLifecycleTransitionRequired = 1199102
+
 	// too many requests
 	CCErrTooManyRequestErr = 1199997
 
@@ -778,6 +808,8 @@ const (
 	CCErrWebDisableNotification         = 1111025
 	CCErrWebGetAnnFail                  = 1111026
 	CCErrInvalidFileTypeFail            = 1111027
+	CCErrWebOIDCLoginFailed             = 1111028
+	CCErrWebLoginGroupNotAllowed        = 1111029
 
 	// datacollection 1112xxx
 	CCErrCollectNetDeviceCreateFail            = 1112000
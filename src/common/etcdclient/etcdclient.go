@@ -0,0 +1,446 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package etcdclient is a lightweight etcd v3 client based on the etcd grpc-gateway json api,
+// it only covers the kv, lease and watch functions which are used by register and discover.
+package etcdclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"configcenter/src/common/ssl"
+)
+
+var (
+	// ErrKeyNotFound the key does not exist in etcd
+	ErrKeyNotFound = errors.New("etcd key not found")
+	// ErrLeaseNotFound the lease does not exist or has been expired
+	ErrLeaseNotFound = errors.New("etcd lease not found")
+	// ErrNoEndpoint no etcd endpoint is configured
+	ErrNoEndpoint = errors.New("etcd endpoint is not set")
+)
+
+const (
+	// defaultRequestTimeout is the timeout of the unary requests, watch requests are not limited
+	defaultRequestTimeout = 10 * time.Second
+	// EventTypePut the put event type of watch
+	EventTypePut = "PUT"
+	// EventTypeDelete the delete event type of watch
+	EventTypeDelete = "DELETE"
+)
+
+// EtcdClient etcd v3 client
+type EtcdClient struct {
+	endpoints []string
+	// unaryCli is used by the requests with timeout
+	unaryCli *http.Client
+	// streamCli is used by the long-lived watch requests
+	streamCli *http.Client
+	next      uint32
+}
+
+// NewEtcdClient create an etcd client, hosts are the etcd endpoints like 127.0.0.1:2379
+func NewEtcdClient(hosts []string, tlsConf *ssl.TLSClientConfig) (*EtcdClient, error) {
+	tlsConfig, enableTLS, err := ssl.NewTLSConfigFromConf(tlsConf)
+	if err != nil {
+		return nil, fmt.Errorf("parse etcd tls config failed, err: %v", err)
+	}
+
+	scheme := "http"
+	if enableTLS {
+		scheme = "https"
+	}
+
+	endpoints := make([]string, 0)
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+			host = scheme + "://" + host
+		}
+		endpoints = append(endpoints, strings.TrimSuffix(host, "/"))
+	}
+
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &EtcdClient{
+		endpoints: endpoints,
+		unaryCli:  &http.Client{Transport: transport, Timeout: defaultRequestTimeout},
+		streamCli: &http.Client{Transport: transport},
+	}, nil
+}
+
+// KeyValue is the key value pair stored in etcd
+type KeyValue struct {
+	Key            []byte `json:"key"`
+	Value          []byte `json:"value"`
+	CreateRevision int64  `json:"create_revision,string"`
+	ModRevision    int64  `json:"mod_revision,string"`
+	Version        int64  `json:"version,string"`
+	Lease          int64  `json:"lease,string"`
+}
+
+// ResponseHeader is the header of etcd response
+type ResponseHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// RangeResult is the result of range request
+type RangeResult struct {
+	Header ResponseHeader `json:"header"`
+	Kvs    []*KeyValue    `json:"kvs"`
+}
+
+// Event is the watched change event of a key
+type Event struct {
+	// Type is PUT or DELETE
+	Type string    `json:"type"`
+	Kv   *KeyValue `json:"kv"`
+}
+
+// WatchResponse is the watched events, if Err is set, the watch is broken and the channel will be closed
+type WatchResponse struct {
+	Header ResponseHeader `json:"header"`
+	Events []*Event       `json:"events"`
+	Err    error          `json:"-"`
+}
+
+type gatewayError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+// Ping to ping etcd server
+func (c *EtcdClient) Ping() error {
+	return c.do(context.Background(), "/v3/maintenance/status", struct{}{}, nil)
+}
+
+// Range get the key value of the key, or all the key values with the key prefix if prefix is true,
+// the result is sorted by create revision, the earlier created key is in the front
+func (c *EtcdClient) Range(key string, prefix bool) (*RangeResult, error) {
+	req := map[string]interface{}{
+		"key":         []byte(key),
+		"sort_order":  "ASCEND",
+		"sort_target": "CREATE",
+	}
+	if prefix {
+		req["range_end"] = prefixRangeEnd(key)
+	}
+
+	result := new(RangeResult)
+	if err := c.do(context.Background(), "/v3/kv/range", req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Get the value of the key
+func (c *EtcdClient) Get(key string) (string, error) {
+	result, err := c.Range(key, false)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Kvs) == 0 {
+		return "", ErrKeyNotFound
+	}
+	return string(result.Kvs[0].Value), nil
+}
+
+// GetChildren get the names of the direct children of the path, like the children of zookeeper node
+func (c *EtcdClient) GetChildren(path string) ([]string, error) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	result, err := c.Range(prefix, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChildrenOf(prefix, result.Kvs), nil
+}
+
+// ChildrenOf get the names of the direct children of the prefix from the key values, the order is kept
+func ChildrenOf(prefix string, kvs []*KeyValue) []string {
+	children := make([]string, 0)
+	exists := make(map[string]struct{})
+	for _, kv := range kvs {
+		child := strings.TrimPrefix(string(kv.Key), prefix)
+		if idx := strings.Index(child, "/"); idx >= 0 {
+			child = child[:idx]
+		}
+		if _, ok := exists[child]; ok || child == "" {
+			continue
+		}
+		exists[child] = struct{}{}
+		children = append(children, child)
+	}
+	return children
+}
+
+// Put set the value of the key
+func (c *EtcdClient) Put(key, value string) error {
+	return c.PutWithLease(key, value, 0)
+}
+
+// PutWithLease set the value of the key bound with the lease, the key is deleted when the lease is expired
+func (c *EtcdClient) PutWithLease(key, value string, leaseID int64) error {
+	req := map[string]interface{}{
+		"key":   []byte(key),
+		"value": []byte(value),
+	}
+	if leaseID != 0 {
+		req["lease"] = fmt.Sprintf("%d", leaseID)
+	}
+	return c.do(context.Background(), "/v3/kv/put", req, nil)
+}
+
+// Delete the key, or all the keys with the key prefix if prefix is true
+func (c *EtcdClient) Delete(key string, prefix bool) error {
+	req := map[string]interface{}{
+		"key": []byte(key),
+	}
+	if prefix {
+		req["range_end"] = prefixRangeEnd(key)
+	}
+	return c.do(context.Background(), "/v3/kv/deleterange", req, nil)
+}
+
+// Grant create a lease with ttl seconds
+func (c *EtcdClient) Grant(ttl int64) (int64, error) {
+	result := new(struct {
+		ID  int64 `json:"ID,string"`
+		TTL int64 `json:"TTL,string"`
+	})
+	req := map[string]interface{}{"TTL": fmt.Sprintf("%d", ttl)}
+	if err := c.do(context.Background(), "/v3/lease/grant", req, result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+// KeepAliveOnce renew the lease once, returns the remaining ttl of the lease
+func (c *EtcdClient) KeepAliveOnce(leaseID int64) (int64, error) {
+	result := new(struct {
+		Result struct {
+			TTL int64 `json:"TTL,string"`
+		} `json:"result"`
+	})
+	req := map[string]interface{}{"ID": fmt.Sprintf("%d", leaseID)}
+	if err := c.do(context.Background(), "/v3/lease/keepalive", req, result); err != nil {
+		return 0, err
+	}
+	if result.Result.TTL <= 0 {
+		return 0, ErrLeaseNotFound
+	}
+	return result.Result.TTL, nil
+}
+
+// Revoke the lease, all the keys bound with it are deleted
+func (c *EtcdClient) Revoke(leaseID int64) error {
+	req := map[string]interface{}{"ID": fmt.Sprintf("%d", leaseID)}
+	return c.do(context.Background(), "/v3/lease/revoke", req, nil)
+}
+
+// Watch the changes of the key, or all the keys with the key prefix if prefix is true, starts from startRev
+// if it is not zero. the returned channel is closed when the context is done or the watch is broken.
+func (c *EtcdClient) Watch(ctx context.Context, key string, prefix bool, startRev int64) (<-chan *WatchResponse,
+	error) {
+
+	createReq := map[string]interface{}{
+		"key": []byte(key),
+	}
+	if prefix {
+		createReq["range_end"] = prefixRangeEnd(key)
+	}
+	if startRev > 0 {
+		createReq["start_revision"] = fmt.Sprintf("%d", startRev)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"create_request": createReq})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.request(ctx, c.streamCli, "/v3/watch", body)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *WatchResponse, 1)
+	go c.loopWatch(ctx, resp.Body, ch)
+	return ch, nil
+}
+
+type watchMessage struct {
+	Result *struct {
+		WatchResponse
+		Canceled     bool   `json:"canceled"`
+		CancelReason string `json:"cancel_reason"`
+	} `json:"result"`
+	Error *gatewayError `json:"error"`
+}
+
+func (c *EtcdClient) loopWatch(ctx context.Context, body io.ReadCloser, ch chan *WatchResponse) {
+	defer close(ch)
+	defer body.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(body))
+	for {
+		msg := new(watchMessage)
+		if err := decoder.Decode(msg); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if err == io.EOF {
+				err = errors.New("etcd watch stream is closed")
+			}
+			ch <- &WatchResponse{Err: err}
+			return
+		}
+
+		if msg.Error != nil {
+			ch <- &WatchResponse{Err: fmt.Errorf("etcd watch failed, err: %s", msg.Error.Message)}
+			return
+		}
+
+		if msg.Result == nil {
+			continue
+		}
+
+		if msg.Result.Canceled {
+			ch <- &WatchResponse{Err: fmt.Errorf("etcd watch is canceled, reason: %s", msg.Result.CancelReason)}
+			return
+		}
+
+		if len(msg.Result.Events) == 0 {
+			// the created response or progress notify
+			continue
+		}
+
+		for _, event := range msg.Result.Events {
+			if event.Type == "" {
+				// put is the default value of event type, so it is omitted in the json
+				event.Type = EventTypePut
+			}
+		}
+
+		select {
+		case ch <- &msg.Result.WatchResponse:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// do send the unary request to etcd and decode the response into result if it's not nil
+func (c *EtcdClient) do(ctx context.Context, path string, req interface{}, result interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.request(ctx, c.unaryCli, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decode etcd response of %s failed, err: %v", path, err)
+	}
+	return nil
+}
+
+// request send the request to the etcd endpoints in turn until one of them responds
+func (c *EtcdClient) request(ctx context.Context, cli *http.Client, path string, body []byte) (*http.Response,
+	error) {
+
+	start := int(atomic.AddUint32(&c.next, 1))
+	var lastErr error
+	for i := 0; i < len(c.endpoints); i++ {
+		endpoint := c.endpoints[(start+i)%len(c.endpoints)]
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := cli.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			data, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			gwErr := new(gatewayError)
+			if json.Unmarshal(data, gwErr) == nil && gwErr.Message != "" {
+				if strings.Contains(gwErr.Message, "requested lease not found") {
+					return nil, ErrLeaseNotFound
+				}
+				return nil, fmt.Errorf("request etcd %s failed, err: %s", path, gwErr.Message)
+			}
+			return nil, fmt.Errorf("request etcd %s failed, status: %s, body: %s", path, resp.Status, data)
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("request etcd %s failed, err: %v", path, lastErr)
+}
+
+// prefixRangeEnd get the range end of the keys with the prefix, which is the prefix with the last byte plus one
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, means all the keys after the prefix
+	return []byte{0}
+}
@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package etcdclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func newTestGateway(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		req := make(map[string]string)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode range request failed, err: %v", err)
+		}
+		if req["key"] != b64("/cc/services/host/") || req["range_end"] != b64("/cc/services/host0") {
+			fmt.Fprint(w, `{"header":{"revision":"10"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"header":{"revision":"10"},"kvs":[{"key":"%s","value":"%s","create_revision":"3"},`+
+			`{"key":"%s","value":"%s","create_revision":"5"}]}`, b64("/cc/services/host/b_01"), b64("b"),
+			b64("/cc/services/host/a_02/sub"), b64("a"))
+	})
+	mux.HandleFunc("/v3/lease/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"ID":"1","TTL":"0"}}`)
+	})
+	mux.HandleFunc("/v3/watch", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"header":{"revision":"10"},"created":true}}`+"\n")
+		fmt.Fprintf(w, `{"result":{"header":{"revision":"11"},"events":[{"kv":{"key":"%s"}},`+
+			`{"type":"DELETE","kv":{"key":"%s"}}]}}`+"\n", b64("/a"), b64("/b"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestEtcdClient(t *testing.T) {
+	server := newTestGateway(t)
+	defer server.Close()
+
+	client, err := NewEtcdClient([]string{server.URL}, nil)
+	if err != nil {
+		t.Fatalf("new etcd client failed, err: %v", err)
+	}
+
+	children, err := client.GetChildren("/cc/services/host")
+	if err != nil {
+		t.Fatalf("get children failed, err: %v", err)
+	}
+	if !reflect.DeepEqual(children, []string{"b_01", "a_02"}) {
+		t.Errorf("children %v is not sorted by create revision", children)
+	}
+
+	if _, err := client.Get("/not/exist"); err != ErrKeyNotFound {
+		t.Errorf("get not exist key should return ErrKeyNotFound, but got %v", err)
+	}
+
+	if _, err := client.KeepAliveOnce(1); err != ErrLeaseNotFound {
+		t.Errorf("keep alive expired lease should return ErrLeaseNotFound, but got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := client.Watch(ctx, "/", true, 0)
+	if err != nil {
+		t.Fatalf("watch failed, err: %v", err)
+	}
+	resp := <-ch
+	if resp.Err != nil || resp.Header.Revision != 11 || len(resp.Events) != 2 {
+		t.Fatalf("watch response %+v is invalid", resp)
+	}
+	if resp.Events[0].Type != EventTypePut || resp.Events[1].Type != EventTypeDelete {
+		t.Errorf("watch event types %s, %s are invalid", resp.Events[0].Type, resp.Events[1].Type)
+	}
+
+	// the stream is closed by the server, so the watch is broken
+	if resp := <-ch; resp == nil || resp.Err == nil {
+		t.Errorf("watch should be broken after the stream is closed")
+	}
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	cases := map[string][]byte{
+		"/cc/":       []byte("/cc0"),
+		"a\xff":      []byte("b"),
+		"\xff\xff":   {0},
+		"/cc/host/1": []byte("/cc/host/2"),
+	}
+	for prefix, expect := range cases {
+		if end := prefixRangeEnd(prefix); !reflect.DeepEqual(end, expect) {
+			t.Errorf("range end of %q should be %q, but got %q", prefix, expect, end)
+		}
+	}
+}
@@ -212,3 +212,13 @@ func IsInnerReq(header http.Header) bool {
 func SetIsInnerReqHeader(header http.Header) {
 	header.Set(IsInnerReqHeader, "true")
 }
+
+// IsLifecycleTransition check if the request is a lifecycle state transition
+func IsLifecycleTransition(header http.Header) bool {
+	return header.Get(LifecycleTransitionHeader) == "true"
+}
+
+// SetLifecycleTransition set the request is a lifecycle state transition flag to http header
+func SetLifecycleTransition(header http.Header) {
+	header.Set(LifecycleTransitionHeader, "true")
+}
@@ -75,4 +75,8 @@ const (
 
 	// IsInnerReqHeader is the http header key that represents if request is an inner request
 	IsInnerReqHeader = "X-Bkcmdb-Is-Inner-Request"
+
+	// LifecycleTransitionHeader is the http header key that represents if the instance update request is a lifecycle
+	// state transition, it is only set by topo server, and is removed from the requests that pass through api server
+	LifecycleTransitionHeader = "X-Bkcmdb-Lifecycle-Transition"
 )
@@ -31,6 +31,7 @@ func init() {
 	registerIndexes(kubetypes.BKTableNameBasePod, commPodIndexes)
 	registerIndexes(kubetypes.BKTableNameBaseContainer, commContainerIndexes)
 	registerIndexes(kubetypes.BKTableNameNsSharedClusterRel, nsSharedClusterRelIndexes)
+	registerIndexes(kubetypes.BKTableNameClusterCredential, clusterCredentialIndexes)
 
 	workLoadTables := []string{
 		kubetypes.BKTableNameBaseDeployment, kubetypes.BKTableNameBaseDaemonSet,
@@ -41,6 +42,10 @@ func init() {
 	for _, table := range workLoadTables {
 		registerIndexes(table, commWorkLoadIndexes)
 	}
+
+	for _, table := range kubetypes.GetNsResourceTables() {
+		registerIndexes(table, commWorkLoadIndexes)
+	}
 }
 
 var commWorkLoadIndexes = []types.Index{
@@ -402,3 +407,29 @@ var nsSharedClusterRelIndexes = []types.Index{
 		Background: true,
 	},
 }
+
+var clusterCredentialIndexes = []types.Index{
+	{
+		Name: common.CCLogicUniqueIdxNamePrefix + common.BKFieldID,
+		Keys: bson.D{
+			{common.BKFieldID, 1},
+		},
+		Background: true,
+		Unique:     true,
+	},
+	{
+		Name: common.CCLogicUniqueIdxNamePrefix + "bk_cluster_id",
+		Keys: bson.D{
+			{kubetypes.BKClusterIDFiled, 1},
+		},
+		Background: true,
+		Unique:     true,
+	},
+	{
+		Name: common.CCLogicIndexNamePrefix + "bk_biz_id",
+		Keys: bson.D{
+			{kubetypes.BKBizIDField, 1},
+		},
+		Background: true,
+	},
+}
@@ -18,11 +18,26 @@ package metadata
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 
 	"configcenter/src/common/util"
 )
 
+const (
+	// LimiterPriorityHigh is the priority class of the requests which are served first when apiserver is overloaded,
+	// like the interactive requests from web server
+	LimiterPriorityHigh = "high"
+	// LimiterPriorityNormal is the default priority class of the requests
+	LimiterPriorityNormal = "normal"
+	// LimiterPriorityLow is the priority class of the requests which are shed first when apiserver is overloaded,
+	// like the batch jobs from esb
+	LimiterPriorityLow = "low"
+)
+
+// LimiterPriorities is the priority classes of the api limiter, the former one has higher priority
+var LimiterPriorities = []string{LimiterPriorityHigh, LimiterPriorityNormal, LimiterPriorityLow}
+
 // LimiterRule is a rule for api limiter
 type LimiterRule struct {
 	RuleName string `json:"rulename"`
@@ -34,6 +49,44 @@ type LimiterRule struct {
 	Limit    int64  `json:"limit"`
 	TTL      int64  `json:"ttl"`
 	DenyAll  bool   `json:"denyall"`
+	// Rate is the tokens generated per second of the token bucket, token bucket limiting is enabled if it is set
+	Rate float64 `json:"rate"`
+	// Burst is the capacity of the token bucket, defaults to the ceiling of rate
+	Burst int64 `json:"burst"`
+	// MaxInFlight is the max concurrent requests of each caller, which is identified by app code and user,
+	// the concurrency is counted in each apiserver instance
+	MaxInFlight int64 `json:"maxinflight"`
+	// Priority is the priority class of the matched requests, which decides the order of the queued requests
+	// and the requests to be shed when apiserver is overloaded
+	Priority string `json:"priority"`
+}
+
+// IsCountRule check if the rule limits the request count in a fixed ttl window
+func (r LimiterRule) IsCountRule() bool {
+	return !r.DenyAll && r.Limit > 0 && r.TTL > 0
+}
+
+// IsTokenBucketRule check if the rule limits the request rate by token bucket
+func (r LimiterRule) IsTokenBucketRule() bool {
+	return !r.DenyAll && r.Rate > 0
+}
+
+// GetBurst get the capacity of the token bucket
+func (r LimiterRule) GetBurst() int64 {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return int64(math.Ceil(r.Rate))
+}
+
+// IsValidLimiterPriority check if the priority is a valid priority class
+func IsValidLimiterPriority(priority string) bool {
+	for _, p := range LimiterPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
 }
 
 // Verify to check the fields of LimiterRule
@@ -54,10 +107,27 @@ func (r LimiterRule) Verify() error {
 			return fmt.Errorf("url is not a valid regular expression，%s", err.Error())
 		}
 	}
-	if !r.DenyAll {
-		if r.Limit <= 0 || r.TTL <= 0 {
-			return fmt.Errorf("both limit and ttl must be set and bigger than 0 when denyall is false")
-		}
+	if r.DenyAll {
+		return nil
+	}
+
+	if r.Limit < 0 || r.TTL < 0 || (r.Limit > 0) != (r.TTL > 0) {
+		return fmt.Errorf("both limit and ttl must be set and bigger than 0 when limiting the request count")
+	}
+	if r.Rate < 0 || r.Burst < 0 {
+		return fmt.Errorf("rate and burst can not be negative")
+	}
+	if r.Burst > 0 && r.Rate == 0 {
+		return fmt.Errorf("rate must be set when burst is set")
+	}
+	if r.MaxInFlight < 0 {
+		return fmt.Errorf("maxinflight can not be negative")
+	}
+	if r.Priority != "" && !IsValidLimiterPriority(r.Priority) {
+		return fmt.Errorf("priority must be one of %v", LimiterPriorities)
+	}
+	if !r.IsCountRule() && !r.IsTokenBucketRule() && r.MaxInFlight == 0 && r.Priority == "" {
+		return fmt.Errorf("one of limit and ttl, rate, maxinflight, priority must be set when denyall is false")
 	}
 	return nil
 }
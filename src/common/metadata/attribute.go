@@ -89,6 +89,8 @@ const (
 	AttributeFieldDefault = "default"
 	// AttributeFieldIsMultiple the is multiple name field
 	AttributeFieldIsMultiple = "ismultiple"
+	// AttributeFieldIsEncrypted the is encrypted name field
+	AttributeFieldIsEncrypted = "isencrypted"
 )
 
 const (
@@ -125,6 +127,7 @@ type Attribute struct {
 	Option            interface{} `field:"option" json:"option" bson:"option" mapstructure:"option"`
 	Default           interface{} `field:"default" json:"default,omitempty" bson:"default" mapstructure:"default"`
 	IsMultiple        *bool       `field:"ismultiple" json:"ismultiple,omitempty" bson:"ismultiple" mapstructure:"ismultiple"`
+	IsEncrypted       bool        `field:"isencrypted" json:"isencrypted" bson:"isencrypted" mapstructure:"isencrypted"`
 	Description       string      `field:"description" json:"description" bson:"description" mapstructure:"description"`
 	TemplateID        int64       `field:"bk_template_id" json:"bk_template_id" bson:"bk_template_id" mapstructure:"bk_template_id"`
 	Creator           string      `field:"creator" json:"creator" bson:"creator" mapstructure:"creator"`
@@ -192,6 +195,7 @@ func (attribute *Attribute) Validate(ctx context.Context, data interface{}, key
 		common.FieldTypeOrganization: attribute.validOrganization,
 		common.FieldTypeInnerTable:   attribute.validInnerTable,
 		common.FieldTypeIDRule:       attribute.validIDRule,
+		common.FieldTypeComputed:     attribute.validComputed,
 	}
 
 	rawError := errors.RawErrorInfo{}
@@ -1117,6 +1121,20 @@ func (attribute *Attribute) validIDRule(ctx context.Context, val interface{}, ke
 	return errors.RawErrorInfo{}
 }
 
+// validComputed valid computed value, which is calculated by the system and can only be a number or null
+func (attribute *Attribute) validComputed(ctx context.Context, val interface{}, key string) errors.RawErrorInfo {
+	if val == nil {
+		return errors.RawErrorInfo{}
+	}
+
+	if _, err := util.GetFloat64ByInterface(val); err != nil {
+		blog.Errorf("params %s:%#v not numeric, rid: %s", key, val, util.ExtractRequestIDFromContext(ctx))
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{key}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
 // ValidIDRuleVal validate id rule value
 func ValidIDRuleVal(ctx context.Context, inst mapstr.MapStr, field Attribute, attrMap map[string]Attribute) error {
 	rid := util.ExtractRequestIDFromContext(ctx)
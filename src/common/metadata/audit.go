@@ -457,6 +457,8 @@ type InstanceOpDetail struct {
 	BasicOpDetail `bson:",inline"`
 	// BkObjID the object ID of the instance's model
 	ModelID string `json:"bk_obj_id" bson:"bk_obj_id"`
+	// Transition the lifecycle state transition detail, only set for the transition operation
+	Transition *LifecycleTransitionDetail `json:"transition,omitempty" bson:"transition,omitempty"`
 }
 
 // WithName TODO
@@ -756,6 +758,8 @@ const (
 	KubeWorkload ResourceType = "kube_workload"
 	// KubePod kube pod audit resource type
 	KubePod ResourceType = "kube_pod"
+	// KubeNsResource kube namespace resource audit resource type, including service, ingress, configMap and pvc
+	KubeNsResource ResourceType = "kube_ns_resource"
 
 	// QuotedInst is quoted instance related audit resource type
 	QuotedInst ResourceType = "quoted_inst"
@@ -787,6 +791,8 @@ const (
 	FromSynchronizer OperateFromType = "synchronizer"
 	// FromCloudSync means this audit is created by cloud sync.
 	FromCloudSync OperateFromType = "cloud_sync"
+	// FromHostApplyReconcile means this audit is created by the host apply reconciler correcting drifted hosts.
+	FromHostApplyReconcile OperateFromType = "host_apply_reconcile"
 )
 
 // ActionType defines all the user's operation type
@@ -823,6 +829,8 @@ const (
 	// AuditResume TODO
 	// resume using an object
 	AuditResume ActionType = "resume"
+	// AuditTransition transit an instance's lifecycle state
+	AuditTransition ActionType = "transition"
 )
 
 // GetAuditTypeByObjID TODO
@@ -958,6 +966,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditAssignHost],
 			actionInfoMap[AuditUnassignHost],
 			actionInfoMap[AuditTransferHostModule],
+			actionInfoMap[AuditTransition],
 		},
 	},
 	{
@@ -1004,6 +1013,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditCreate],
 			actionInfoMap[AuditUpdate],
 			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditTransition],
 		},
 	},
 	{
@@ -1138,6 +1148,7 @@ var actionInfoMap = map[ActionType]actionTypeInfo{
 	AuditRecover:            {ID: AuditRecover, Name: "恢复"},
 	AuditPause:              {ID: AuditPause, Name: "停用"},
 	AuditResume:             {ID: AuditResume, Name: "启用"},
+	AuditTransition:         {ID: AuditTransition, Name: "状态流转"},
 }
 
 type resourceTypeInfo struct {
@@ -1221,6 +1232,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditAssignHost],
 			actionInfoEnMap[AuditUnassignHost],
 			actionInfoEnMap[AuditTransferHostModule],
+			actionInfoEnMap[AuditTransition],
 		},
 	},
 	{
@@ -1267,6 +1279,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditCreate],
 			actionInfoEnMap[AuditUpdate],
 			actionInfoEnMap[AuditDelete],
+			actionInfoEnMap[AuditTransition],
 		},
 	},
 	{
@@ -1400,4 +1413,5 @@ var actionInfoEnMap = map[ActionType]actionTypeInfo{
 	AuditRecover:            {ID: AuditRecover, Name: "Recover"},
 	AuditPause:              {ID: AuditPause, Name: "Pause"},
 	AuditResume:             {ID: AuditResume, Name: "Resume"},
+	AuditTransition:         {ID: AuditTransition, Name: "Transit state"},
 }
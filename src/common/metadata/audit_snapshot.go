@@ -0,0 +1,162 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+
+	"github.com/coccyx/timeparser"
+)
+
+// InstSnapshotOption is the option to reconstruct an instance at a point in time from its audit logs
+type InstSnapshotOption struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	// Time is the point in time to reconstruct the instance at, uses the same format as audit operation time
+	Time string `json:"time"`
+}
+
+// Validate validates the instance snapshot option
+func (o *InstSnapshotOption) Validate() errors.RawErrorInfo {
+	if len(o.ObjID) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if o.InstID <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	if _, err := ParseSnapshotTime(o.Time); err != nil {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"time"}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// InstSnapshotDiffOption is the option to compare an instance between two points in time
+type InstSnapshotDiffOption struct {
+	ObjID     string `json:"bk_obj_id"`
+	InstID    int64  `json:"bk_inst_id"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// Validate validates the instance snapshot diff option
+func (o *InstSnapshotDiffOption) Validate() errors.RawErrorInfo {
+	if len(o.ObjID) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if o.InstID <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	start, err := ParseSnapshotTime(o.StartTime)
+	if err != nil {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"start_time"}}
+	}
+
+	end, err := ParseSnapshotTime(o.EndTime)
+	if err != nil {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"end_time"}}
+	}
+
+	if end.Before(start) {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"end_time"}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// StartOption returns the snapshot option of the start time
+func (o *InstSnapshotDiffOption) StartOption() *InstSnapshotOption {
+	return &InstSnapshotOption{ObjID: o.ObjID, InstID: o.InstID, Time: o.StartTime}
+}
+
+// EndOption returns the snapshot option of the end time
+func (o *InstSnapshotDiffOption) EndOption() *InstSnapshotOption {
+	return &InstSnapshotOption{ObjID: o.ObjID, InstID: o.InstID, Time: o.EndTime}
+}
+
+// ParseSnapshotTime parse the snapshot time in local time zone, same as the audit log operation time condition
+func ParseSnapshotTime(t string) (time.Time, error) {
+	parsed, err := timeparser.TimeParserInLocation(t, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.Local(), nil
+}
+
+// InstSnapshot is an instance reconstructed at a point in time by replaying its audit logs
+type InstSnapshot struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	Time   string `json:"time"`
+	// Exists represents whether the instance exists at the point in time
+	Exists bool `json:"exists"`
+	// Data is the attributes of the instance at the point in time
+	Data mapstr.MapStr `json:"data"`
+	// Topo is the business topology of the host at the point in time, only set for host
+	Topo *HostBizTopo `json:"topo,omitempty"`
+	// Associations are the instance associations of the instance at the point in time
+	Associations []InstSnapshotAsst `json:"associations"`
+	// LastAuditID is the id of the last audit log applied to this snapshot, 0 if no audit log is applied
+	LastAuditID int64 `json:"last_audit_id"`
+	// LastOperationTime is the operation time of the last audit log applied to this snapshot
+	LastOperationTime *Time `json:"last_operation_time,omitempty"`
+}
+
+// InstSnapshotAsst is an instance association in the instance snapshot
+type InstSnapshotAsst struct {
+	ObjectAsstID string `json:"bk_obj_asst_id"`
+	AsstKindID   string `json:"bk_asst_id"`
+	SrcObjID     string `json:"bk_obj_id"`
+	SrcInstID    int64  `json:"bk_inst_id"`
+	DestObjID    string `json:"bk_asst_obj_id"`
+	DestInstID   int64  `json:"bk_asst_inst_id"`
+}
+
+// InstSnapshotResult is the response of the instance snapshot search
+type InstSnapshotResult struct {
+	BaseResp `json:",inline"`
+	Data     *InstSnapshot `json:"data"`
+}
+
+// InstSnapshotDiff is the difference of an instance between two points in time
+type InstSnapshotDiff struct {
+	Start *InstSnapshot `json:"start"`
+	End   *InstSnapshot `json:"end"`
+	// Changes are the attributes whose value is changed between the start and the end
+	Changes []InstAttrChange `json:"changes"`
+	// TopoChanged represents whether the host's business topology is changed, only for host
+	TopoChanged bool `json:"topo_changed"`
+	// AddedAssociations are the associations that only exist at the end time
+	AddedAssociations []InstSnapshotAsst `json:"added_associations"`
+	// RemovedAssociations are the associations that only exist at the start time
+	RemovedAssociations []InstSnapshotAsst `json:"removed_associations"`
+}
+
+// InstAttrChange is the change of an attribute between two instance snapshots
+type InstAttrChange struct {
+	PropertyID string      `json:"bk_property_id"`
+	Before     interface{} `json:"before"`
+	After      interface{} `json:"after"`
+}
@@ -0,0 +1,213 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"unicode/utf8"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+const (
+	authRoleNameMaxLen        = 128
+	authRoleDescMaxLen        = 512
+	authRolePolicyMaxCount    = 500
+	authPolicyResourceMaxCnt  = 200
+	authRoleBindingUserMaxCnt = 500
+
+	// AuthRoleIDField is the role id field of the role binding
+	AuthRoleIDField = "role_id"
+	// AuthRoleBindingUsersField is the users field of the role binding
+	AuthRoleBindingUsersField = "users"
+)
+
+// AuthRole is a role of the built-in local authorizer, it is a set of policies that can be bound to users
+type AuthRole struct {
+	ID          int64        `json:"id" bson:"id"`
+	Name        string       `json:"name" bson:"name"`
+	Description string       `json:"description" bson:"description"`
+	Policies    []AuthPolicy `json:"policies" bson:"policies"`
+	OwnerID     string       `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator     string       `json:"creator" bson:"creator"`
+	Modifier    string       `json:"modifier" bson:"modifier"`
+	CreateTime  Time         `json:"create_time" bson:"create_time"`
+	LastTime    Time         `json:"last_time" bson:"last_time"`
+}
+
+// AuthPolicy grants an iam action on the resources
+type AuthPolicy struct {
+	// Action is the iam action id, e.g. edit_biz_host
+	Action string `json:"action" bson:"action"`
+	// Resources is the resources that the policy is scoped to, the policy applies to all resources if it is not set.
+	// A resource also covers all the resources under it, e.g. a business resource covers its sets and modules.
+	Resources []AuthPolicyResource `json:"resources,omitempty" bson:"resources,omitempty"`
+}
+
+// AuthPolicyResource is an iam resource instance that the policy is scoped to
+type AuthPolicyResource struct {
+	// Type is the iam resource type id, e.g. biz
+	Type string `json:"type" bson:"type"`
+	ID   string `json:"id" bson:"id"`
+}
+
+// AuthRoleSpec is the user defined content of a role
+type AuthRoleSpec struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Policies    []AuthPolicy `json:"policies"`
+}
+
+// Validate auth role spec
+func (a *AuthRoleSpec) Validate() ccErr.RawErrorInfo {
+	if len(a.Name) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"name"}}
+	}
+
+	if utf8.RuneCountInString(a.Name) > authRoleNameMaxLen {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommValExceedMaxFailed,
+			Args: []interface{}{"name", authRoleNameMaxLen}}
+	}
+
+	if utf8.RuneCountInString(a.Description) > authRoleDescMaxLen {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommValExceedMaxFailed,
+			Args: []interface{}{"description", authRoleDescMaxLen}}
+	}
+
+	if len(a.Policies) > authRolePolicyMaxCount {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"policies", authRolePolicyMaxCount}}
+	}
+
+	for _, policy := range a.Policies {
+		if len(policy.Action) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"policies.action"}}
+		}
+
+		if len(policy.Resources) > authPolicyResourceMaxCnt {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+				Args: []interface{}{"policies.resources", authPolicyResourceMaxCnt}}
+		}
+
+		for _, resource := range policy.Resources {
+			if len(resource.Type) == 0 || len(resource.ID) == 0 {
+				return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+					Args: []interface{}{"policies.resources"}}
+			}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// AuthRoleBinding binds a role to users
+type AuthRoleBinding struct {
+	ID         int64    `json:"id" bson:"id"`
+	RoleID     int64    `json:"role_id" bson:"role_id"`
+	Users      []string `json:"users" bson:"users"`
+	OwnerID    string   `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator    string   `json:"creator" bson:"creator"`
+	CreateTime Time     `json:"create_time" bson:"create_time"`
+	LastTime   Time     `json:"last_time" bson:"last_time"`
+}
+
+// CreateAuthRoleBindingOption create auth role binding option
+type CreateAuthRoleBindingOption struct {
+	RoleID int64    `json:"role_id"`
+	Users  []string `json:"users"`
+}
+
+// Validate create auth role binding option
+func (c *CreateAuthRoleBindingOption) Validate() ccErr.RawErrorInfo {
+	if c.RoleID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{AuthRoleIDField}}
+	}
+
+	if len(c.Users) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+			Args: []interface{}{AuthRoleBindingUsersField}}
+	}
+
+	if len(c.Users) > authRoleBindingUserMaxCnt {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{AuthRoleBindingUsersField, authRoleBindingUserMaxCnt}}
+	}
+
+	for _, user := range c.Users {
+		if len(user) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+				Args: []interface{}{AuthRoleBindingUsersField}}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// SearchAuthRoleOption search auth roles or role bindings option
+type SearchAuthRoleOption struct {
+	Condition map[string]interface{} `json:"condition"`
+	Page      BasePage               `json:"page"`
+}
+
+// Validate search auth roles or role bindings option
+func (s *SearchAuthRoleOption) Validate() ccErr.RawErrorInfo {
+	return s.Page.ValidateWithEnableCount(false, common.BKMaxLimitSize)
+}
+
+// AuthRoleList is the auth role list
+type AuthRoleList struct {
+	Count int64      `json:"count"`
+	Info  []AuthRole `json:"info"`
+}
+
+// AuthRoleListResp is the auth role list response
+type AuthRoleListResp struct {
+	BaseResp `json:",inline"`
+	Data     AuthRoleList `json:"data"`
+}
+
+// AuthRoleBindingList is the auth role binding list
+type AuthRoleBindingList struct {
+	Count int64             `json:"count"`
+	Info  []AuthRoleBinding `json:"info"`
+}
+
+// AuthRoleBindingListResp is the auth role binding list response
+type AuthRoleBindingListResp struct {
+	BaseResp `json:",inline"`
+	Data     AuthRoleBindingList `json:"data"`
+}
+
+// ListUserAuthPolicyOption list the policies of the roles bound to the user option
+type ListUserAuthPolicyOption struct {
+	User string `json:"user"`
+}
+
+// Validate list user auth policy option
+func (l *ListUserAuthPolicyOption) Validate() ccErr.RawErrorInfo {
+	if len(l.User) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"user"}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// ListUserAuthPolicyResp list user auth policy response
+type ListUserAuthPolicyResp struct {
+	BaseResp `json:",inline"`
+	Data     []AuthPolicy `json:"data"`
+}
@@ -32,6 +32,7 @@ type CloudAccount struct {
 	AccountID   int64     `json:"bk_account_id" bson:"bk_account_id"`
 	SecretID    string    `json:"bk_secret_id" bson:"bk_secret_id"`
 	SecretKey   string    `json:"bk_secret_key" bson:"bk_secret_key"`
+	Endpoint    string    `json:"bk_endpoint" bson:"bk_endpoint"`
 	Description string    `json:"bk_description" bson:"bk_description"`
 	OwnerID     string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
 	Creator     string    `json:"bk_creator" bson:"bk_creator"`
@@ -76,6 +77,14 @@ func (c *CloudAccount) Validate() (rawError errors.RawErrorInfo) {
 		}
 	}
 
+	// 私有云没有统一的接入地址，需要指定认证服务的地址
+	if c.CloudVendor == OpenStack && c.Endpoint == "" {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKCloudEndpoint},
+		}
+	}
+
 	return errors.RawErrorInfo{}
 }
 
@@ -91,11 +100,14 @@ type CloudAccountWithExtraInfo struct {
 const (
 	AWS          string = "1"
 	TencentCloud string = "2"
+	Aliyun       string = "9"
+	HuaweiCloud  string = "15"
+	OpenStack    string = "19"
 )
 
 // SupportedCloudVendors 支持的云厂商
 // 实现了相应的云厂商插件
-var SupportedCloudVendors = []string{AWS, TencentCloud}
+var SupportedCloudVendors = []string{AWS, TencentCloud, Aliyun, HuaweiCloud, OpenStack}
 
 // 云同步任务同步状态
 const (
@@ -110,6 +122,8 @@ type CloudAccountConf struct {
 	VendorName string `json:"bk_cloud_vendor" bson:"bk_cloud_vendor"`
 	SecretID   string `json:"bk_secret_id" bson:"bk_secret_id"`
 	SecretKey  string `json:"bk_secret_key" bson:"bk_secret_key"`
+	// Endpoint 云厂商接入地址，仅私有云(如OpenStack)需要
+	Endpoint string `json:"bk_endpoint" bson:"bk_endpoint"`
 }
 
 // SearchCloudOption TODO
@@ -187,6 +201,7 @@ type CloudAccountVerify struct {
 	SecretID    string `json:"bk_secret_id"`
 	SecretKey   string `json:"bk_secret_key"`
 	CloudVendor string `json:"bk_cloud_vendor"`
+	Endpoint    string `json:"bk_endpoint"`
 }
 
 // SearchAccountValidityOption TODO
@@ -0,0 +1,132 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+// ComputedSource is the source that the computed attribute value is calculated from
+type ComputedSource string
+
+const (
+	// ComputedSourceExpression the value is an expression over other fields of the same instance
+	ComputedSourceExpression ComputedSource = "expression"
+	// ComputedSourceHostRelation the value is aggregated over the hosts in the biz, set or module instance
+	ComputedSourceHostRelation ComputedSource = "host_relation"
+	// ComputedSourceAssociation the value is aggregated over the instances associated by an association
+	ComputedSourceAssociation ComputedSource = "association"
+)
+
+// AggregateFunc is the aggregate function of the computed attribute
+type AggregateFunc string
+
+const (
+	// AggregateCount count the aggregated instances
+	AggregateCount AggregateFunc = "count"
+	// AggregateSum sum the field values of the aggregated instances
+	AggregateSum AggregateFunc = "sum"
+	// AggregateAvg average the field values of the aggregated instances
+	AggregateAvg AggregateFunc = "avg"
+	// AggregateMin the min field value of the aggregated instances
+	AggregateMin AggregateFunc = "min"
+	// AggregateMax the max field value of the aggregated instances
+	AggregateMax AggregateFunc = "max"
+)
+
+// ComputedOption is the option of the computed attribute, the computed value is always a number.
+type ComputedOption struct {
+	Source ComputedSource `json:"source"`
+	// Expression is the expression over other fields of the same instance, used by expression source
+	Expression string `json:"expression,omitempty"`
+	// ObjAsstID is the association whose associated instances are aggregated, used by association source
+	ObjAsstID string `json:"bk_obj_asst_id,omitempty"`
+	// Func is the aggregate function, used by host_relation and association source
+	Func AggregateFunc `json:"func,omitempty"`
+	// Field is the aggregated field of the host or the associated instance, not needed by count function
+	Field string `json:"field,omitempty"`
+}
+
+// IsAggregation returns if the computed value is aggregated over other instances
+func (c *ComputedOption) IsAggregation() bool {
+	return c.Source == ComputedSourceHostRelation || c.Source == ComputedSourceAssociation
+}
+
+// Validate validate the computed option fields, the referenced fields are validated by the caller
+func (c *ComputedOption) Validate() error {
+	switch c.Source {
+	case ComputedSourceExpression:
+		if c.Expression == "" {
+			return fmt.Errorf("expression is not set")
+		}
+		return nil
+	case ComputedSourceHostRelation:
+	case ComputedSourceAssociation:
+		if c.ObjAsstID == "" {
+			return fmt.Errorf("%s is not set", common.AssociationObjAsstIDField)
+		}
+	default:
+		return fmt.Errorf("source %s is invalid", c.Source)
+	}
+
+	switch c.Func {
+	case AggregateCount:
+		return nil
+	case AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+		if c.Field == "" {
+			return fmt.Errorf("field is not set for %s function", c.Func)
+		}
+		return nil
+	default:
+		return fmt.Errorf("func %s is invalid", c.Func)
+	}
+}
+
+// ParseComputedOption parse computed attribute option
+func ParseComputedOption(val interface{}) (*ComputedOption, error) {
+	if val == nil || val == "" {
+		return nil, fmt.Errorf("option val is invalid")
+	}
+
+	var raw []byte
+	var err error
+	switch option := val.(type) {
+	case ComputedOption:
+		return &option, nil
+	case *ComputedOption:
+		return option, nil
+	case string:
+		raw = []byte(option)
+	case map[string]interface{}, mapstr.MapStr:
+		raw, err = json.Marshal(option)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknow val type: %T for computed option", val)
+	}
+
+	res := new(ComputedOption)
+	if err = json.Unmarshal(raw, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
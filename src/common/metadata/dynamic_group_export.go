@@ -0,0 +1,246 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+
+	"github.com/robfig/cron"
+)
+
+const (
+	dynamicGroupExportFieldMaxCount = 200
+	// DynamicGroupExportLastRunTimeField is the last run time field of dynamic group export schedule
+	DynamicGroupExportLastRunTimeField = "last_run_time"
+	// DynamicGroupExportChunkSize is the max size of an export file chunk that is stored in one document
+	DynamicGroupExportChunkSize = 1 << 20
+)
+
+// DynamicGroupExportFormat is the file format of the dynamic group export
+type DynamicGroupExportFormat string
+
+const (
+	// DynamicGroupExportCSV export dynamic group as csv file
+	DynamicGroupExportCSV DynamicGroupExportFormat = "csv"
+	// DynamicGroupExportJSONLines export dynamic group as json lines file, one json object per line
+	DynamicGroupExportJSONLines DynamicGroupExportFormat = "jsonl"
+	// DynamicGroupExportXLSX export dynamic group as excel file
+	DynamicGroupExportXLSX DynamicGroupExportFormat = "xlsx"
+)
+
+// Validate dynamic group export format
+func (f DynamicGroupExportFormat) Validate() bool {
+	switch f {
+	case DynamicGroupExportCSV, DynamicGroupExportJSONLines, DynamicGroupExportXLSX:
+		return true
+	}
+	return false
+}
+
+// DynamicGroupExportFileStatus is the generation status of the dynamic group export file
+type DynamicGroupExportFileStatus string
+
+const (
+	// DynamicGroupExportWaiting the export task is waiting to be executed
+	DynamicGroupExportWaiting DynamicGroupExportFileStatus = "waiting"
+	// DynamicGroupExportExecuting the export file is being generated
+	DynamicGroupExportExecuting DynamicGroupExportFileStatus = "executing"
+	// DynamicGroupExportFinished the export file is generated and can be downloaded
+	DynamicGroupExportFinished DynamicGroupExportFileStatus = "finished"
+	// DynamicGroupExportFailed the export file generation failed
+	DynamicGroupExportFailed DynamicGroupExportFileStatus = "failed"
+)
+
+// DynamicGroupExportSpec is the export setting shared by the on-demand exports and the scheduled exports
+type DynamicGroupExportSpec struct {
+	Format DynamicGroupExportFormat `json:"format" bson:"format"`
+	// Fields is the exported fields of the dynamic group's object
+	Fields []string `json:"fields" bson:"fields"`
+}
+
+// Validate dynamic group export spec
+func (d *DynamicGroupExportSpec) Validate() ccErr.RawErrorInfo {
+	if !d.Format.Validate() {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{"format"}}
+	}
+
+	if len(d.Fields) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"fields"}}
+	}
+
+	if len(d.Fields) > dynamicGroupExportFieldMaxCount {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"fields", dynamicGroupExportFieldMaxCount}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// DynamicGroupExportSchedule is the cron schedule that exports the dynamic group periodically
+type DynamicGroupExportSchedule struct {
+	ID                     int64  `json:"id" bson:"id"`
+	AppID                  int64  `json:"bk_biz_id" bson:"bk_biz_id"`
+	GroupID                string `json:"group_id" bson:"group_id"`
+	DynamicGroupExportSpec `json:",inline" bson:",inline"`
+	// Cron is the standard cron spec of the schedule, like "0 2 * * *"
+	Cron   string `json:"cron" bson:"cron"`
+	Enable bool   `json:"enable" bson:"enable"`
+	// LastRunTime is the last time that the schedule triggered an export
+	LastRunTime *time.Time `json:"last_run_time,omitempty" bson:"last_run_time,omitempty"`
+	OwnerID     string     `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator     string     `json:"creator" bson:"creator"`
+	Modifier    string     `json:"modifier" bson:"modifier"`
+	CreateTime  time.Time  `json:"create_time" bson:"create_time"`
+	LastTime    time.Time  `json:"last_time" bson:"last_time"`
+}
+
+// NextRunTime returns the next time that the schedule should trigger an export
+func (d *DynamicGroupExportSchedule) NextRunTime() (time.Time, error) {
+	schedule, err := cron.ParseStandard(d.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if d.LastRunTime != nil {
+		return schedule.Next(*d.LastRunTime), nil
+	}
+	return schedule.Next(d.CreateTime), nil
+}
+
+// DynamicGroupExportScheduleSpec is the user defined content of a dynamic group export schedule
+type DynamicGroupExportScheduleSpec struct {
+	DynamicGroupExportSpec `json:",inline"`
+	Cron                   string `json:"cron"`
+	Enable                 bool   `json:"enable"`
+}
+
+// Validate dynamic group export schedule spec
+func (d *DynamicGroupExportScheduleSpec) Validate() ccErr.RawErrorInfo {
+	if rawErr := d.DynamicGroupExportSpec.Validate(); rawErr.ErrCode != 0 {
+		return rawErr
+	}
+
+	if len(d.Cron) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"cron"}}
+	}
+
+	if _, err := cron.ParseStandard(d.Cron); err != nil {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{"cron"}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// DynamicGroupExportFile is a generated export file of the dynamic group, its content is stored in chunks
+type DynamicGroupExportFile struct {
+	ID      int64  `json:"id" bson:"id"`
+	AppID   int64  `json:"bk_biz_id" bson:"bk_biz_id"`
+	GroupID string `json:"group_id" bson:"group_id"`
+	// ScheduleID is the id of the schedule that triggered the export, it is 0 for the on-demand export
+	ScheduleID             int64 `json:"schedule_id" bson:"schedule_id"`
+	DynamicGroupExportSpec `json:",inline" bson:",inline"`
+	Status                 DynamicGroupExportFileStatus `json:"status" bson:"status"`
+	TaskID                 string                       `json:"task_id" bson:"task_id"`
+	FileName               string                       `json:"file_name" bson:"file_name"`
+	// Size is the byte size of the file
+	Size int64 `json:"size" bson:"size"`
+	// Count is the exported row count of the file
+	Count      int64     `json:"count" bson:"count"`
+	ChunkCount int64     `json:"chunk_count" bson:"chunk_count"`
+	ErrMsg     string    `json:"err_msg,omitempty" bson:"err_msg,omitempty"`
+	OwnerID    string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator    string    `json:"creator" bson:"creator"`
+	CreateTime time.Time `json:"create_time" bson:"create_time"`
+	LastTime   time.Time `json:"last_time" bson:"last_time"`
+}
+
+// DynamicGroupExportFileChunk is a chunk of the export file content
+type DynamicGroupExportFileChunk struct {
+	FileID  int64  `json:"file_id" bson:"file_id"`
+	Index   int64  `json:"index" bson:"index"`
+	Data    []byte `json:"data" bson:"data"`
+	OwnerID string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// FindDynamicGroupExportChunkOption find dynamic group export file chunk option
+type FindDynamicGroupExportChunkOption struct {
+	FileID int64 `json:"file_id"`
+	Index  int64 `json:"index"`
+}
+
+// DynamicGroupExportTaskOption is the data of the dynamic group export task
+type DynamicGroupExportTaskOption struct {
+	AppID  int64 `json:"bk_biz_id"`
+	FileID int64 `json:"file_id"`
+}
+
+// DynamicGroupExportResult is the result of creating a dynamic group export
+type DynamicGroupExportResult struct {
+	FileID int64  `json:"file_id"`
+	TaskID string `json:"task_id"`
+}
+
+// SearchDynamicGroupExportOption search dynamic group export files or schedules option
+type SearchDynamicGroupExportOption struct {
+	GroupID string   `json:"group_id"`
+	IDs     []int64  `json:"ids"`
+	Page    BasePage `json:"page"`
+}
+
+// Validate search dynamic group export files or schedules option
+func (s *SearchDynamicGroupExportOption) Validate() ccErr.RawErrorInfo {
+	return s.Page.ValidateWithEnableCount(false, common.BKMaxLimitSize)
+}
+
+// DynamicGroupExportScheduleList is the dynamic group export schedule list
+type DynamicGroupExportScheduleList struct {
+	Count int64                        `json:"count"`
+	Info  []DynamicGroupExportSchedule `json:"info"`
+}
+
+// DynamicGroupExportScheduleListResp is the dynamic group export schedule list response
+type DynamicGroupExportScheduleListResp struct {
+	BaseResp `json:",inline"`
+	Data     DynamicGroupExportScheduleList `json:"data"`
+}
+
+// DynamicGroupExportFileList is the dynamic group export file list
+type DynamicGroupExportFileList struct {
+	Count int64                    `json:"count"`
+	Info  []DynamicGroupExportFile `json:"info"`
+}
+
+// DynamicGroupExportFileListResp is the dynamic group export file list response
+type DynamicGroupExportFileListResp struct {
+	BaseResp `json:",inline"`
+	Data     DynamicGroupExportFileList `json:"data"`
+}
+
+// DynamicGroupExportChunkResp is the dynamic group export file chunk response
+type DynamicGroupExportChunkResp struct {
+	BaseResp `json:",inline"`
+	Data     *DynamicGroupExportFileChunk `json:"data"`
+}
+
+// DynamicGroupExportResultResp is the response of creating a dynamic group export
+type DynamicGroupExportResultResp struct {
+	BaseResp `json:",inline"`
+	Data     DynamicGroupExportResult `json:"data"`
+}
@@ -229,7 +229,8 @@ func getAttributeType(attributeType string) (string, error) {
 	case common.FieldTypeSingleChar, common.FieldTypeLongChar, common.FieldTypeEnum, common.FieldTypeEnumMulti,
 		common.FieldTypeTimeZone, common.FieldTypeUser, common.FieldTypeList:
 		return stringType, nil
-	case common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeOrganization, common.FieldTypeEnumQuote:
+	case common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeOrganization, common.FieldTypeEnumQuote,
+		common.FieldTypeComputed:
 		return numericType, nil
 	case common.FieldTypeBool:
 		return boolType, nil
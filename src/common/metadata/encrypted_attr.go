@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+// DecryptInstAttrOption decrypt the encrypted attribute value of an instance option
+type DecryptInstAttrOption struct {
+	InstID     int64  `json:"bk_inst_id"`
+	PropertyID string `json:"bk_property_id"`
+}
+
+// Validate decrypt instance attribute option
+func (o *DecryptInstAttrOption) Validate() ccErr.RawErrorInfo {
+	if o.InstID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	if o.PropertyID == "" {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+			Args: []interface{}{common.BKPropertyIDField}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// DecryptInstAttrResult the decrypted attribute value of an instance
+type DecryptInstAttrResult struct {
+	PropertyID string `json:"bk_property_id"`
+	Value      string `json:"value"`
+}
+
+// DecryptInstAttrResp decrypt instance attribute response
+type DecryptInstAttrResp struct {
+	BaseResp `json:",inline"`
+	Data     *DecryptInstAttrResult `json:"data"`
+}
@@ -36,6 +36,8 @@ type FieldTemplate struct {
 	Modifier    string `json:"modifier" bson:"modifier"`
 	CreateTime  *Time  `json:"create_time" bson:"create_time"`
 	LastTime    *Time  `json:"last_time" bson:"last_time"`
+	// Version the latest version of the field template, it is maintained by the version snapshot creation
+	Version int64 `json:"version" bson:"version"`
 }
 
 // FieldTemplateResp find field template response
@@ -308,6 +310,9 @@ type ObjFieldTemplateRelation struct {
 	ObjectID   int64  `json:"object_id" bson:"object_id"`
 	TemplateID int64  `json:"bk_template_id" bson:"bk_template_id"`
 	OwnerID    string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	// Version the field template version that the object is pinned to, 0 means the object follows the
+	// current field template content, which is compatible with relations created before versioning
+	Version int64 `json:"version" bson:"version"`
 }
 
 // FieldTemplateInfo field template info for list apis
@@ -620,6 +625,8 @@ type CompareFieldTmplAttrOption struct {
 	Attrs      []FieldTemplateAttr `json:"attributes"`
 	// IsPartial partial comparison of template and model attre flag.
 	IsPartial bool `json:"is_partial"`
+	// Version compare the attributes of this field template version instead of Attrs if it is set
+	Version int64 `json:"version,omitempty"`
 }
 
 // Validate compare field template attribute with object option
@@ -638,7 +645,11 @@ func (l *CompareFieldTmplAttrOption) Validate() ccErr.RawErrorInfo {
 		}
 	}
 
-	if len(l.Attrs) == 0 {
+	if l.Version < 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKVersion}}
+	}
+
+	if l.Version == 0 && len(l.Attrs) == 0 {
 		return ccErr.RawErrorInfo{
 			ErrCode: common.CCErrCommParamsNeedSet,
 			Args:    []interface{}{"attributes"},
@@ -706,6 +717,8 @@ type CompareFieldTmplUniqueOption struct {
 	Uniques    []FieldTmplUniqueForUpdate `json:"uniques"`
 	// IsPartial partial comparison of template and model unique flag.
 	IsPartial bool `json:"is_partial"`
+	// Version compare the uniques of this field template version instead of Uniques if it is set
+	Version int64 `json:"version,omitempty"`
 }
 
 // FieldTmplUniqueForUpdate field template unique for field template update scenario.
@@ -732,6 +745,10 @@ func (l *CompareFieldTmplUniqueOption) Validate() ccErr.RawErrorInfo {
 		}
 	}
 
+	if l.Version < 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKVersion}}
+	}
+
 	return ccErr.RawErrorInfo{}
 }
 
@@ -0,0 +1,376 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"sort"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+// FieldTemplateVersion immutable snapshot of the field template attributes and uniques, a new version is
+// generated every time the field template attributes or uniques are changed
+type FieldTemplateVersion struct {
+	ID         int64                 `json:"id" bson:"id"`
+	TemplateID int64                 `json:"bk_template_id" bson:"bk_template_id"`
+	Version    int64                 `json:"version" bson:"version"`
+	Attributes []FieldTemplateAttr   `json:"attributes" bson:"attributes"`
+	Uniques    []FieldTemplateUnique `json:"uniques" bson:"uniques"`
+	OwnerID    string                `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator    string                `json:"creator" bson:"creator"`
+	CreateTime *Time                 `json:"create_time" bson:"create_time"`
+}
+
+// UniqueOptions convert the uniques of the version whose keys are attribute ids to uniques whose keys are
+// property ids, so that they can be compared with the object uniques
+func (v *FieldTemplateVersion) UniqueOptions() ([]FieldTmplUniqueForUpdate, ccErr.RawErrorInfo) {
+	idToPropertyIDMap := make(map[int64]string)
+	for _, attr := range v.Attributes {
+		idToPropertyIDMap[attr.ID] = attr.PropertyID
+	}
+
+	uniques := make([]FieldTmplUniqueForUpdate, len(v.Uniques))
+	for idx, unique := range v.Uniques {
+		uniqueOpt, err := unique.Convert(idToPropertyIDMap)
+		if err.ErrCode != 0 {
+			return nil, err
+		}
+
+		uniques[idx] = FieldTmplUniqueForUpdate{ID: unique.ID, Keys: uniqueOpt.Keys}
+	}
+
+	return uniques, ccErr.RawErrorInfo{}
+}
+
+// FieldTemplateVersionInfo field template version info for list apis
+type FieldTemplateVersionInfo struct {
+	Count uint64                 `json:"count"`
+	Info  []FieldTemplateVersion `json:"info"`
+}
+
+// ListFieldTemplateVersionResp list field template version response
+type ListFieldTemplateVersionResp struct {
+	BaseResp `json:",inline"`
+	Data     FieldTemplateVersionInfo `json:"data"`
+}
+
+// FieldTemplateVersionResp field template version response
+type FieldTemplateVersionResp struct {
+	BaseResp `json:",inline"`
+	Data     FieldTemplateVersion `json:"data"`
+}
+
+// ListFieldTmplVersionOption list field template version option
+type ListFieldTmplVersionOption struct {
+	TemplateID        int64 `json:"bk_template_id"`
+	CommonQueryOption `json:",inline"`
+}
+
+// Validate list field template version option
+func (l *ListFieldTmplVersionOption) Validate() ccErr.RawErrorInfo {
+	if l.TemplateID == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKTemplateID}}
+	}
+
+	if rawErr := l.CommonQueryOption.Validate(); rawErr.ErrCode != 0 {
+		return rawErr
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// CompareFieldTmplVersionOption compare two versions of field template option
+type CompareFieldTmplVersionOption struct {
+	TemplateID  int64 `json:"bk_template_id"`
+	FromVersion int64 `json:"from_version"`
+	ToVersion   int64 `json:"to_version"`
+}
+
+// Validate compare two versions of field template option
+func (c *CompareFieldTmplVersionOption) Validate() ccErr.RawErrorInfo {
+	if c.TemplateID == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKTemplateID}}
+	}
+
+	if c.FromVersion <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"from_version"}}
+	}
+
+	if c.ToVersion <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"to_version"}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// CompareFieldTmplVersionRes compare two versions of field template result, attributes are matched by property id
+// because attribute with the same property id can not be recreated, uniques are matched by id
+type CompareFieldTmplVersionRes struct {
+	FromVersion int64                      `json:"from_version"`
+	ToVersion   int64                      `json:"to_version"`
+	Attributes  FieldTmplVersionAttrDiff   `json:"attributes"`
+	Uniques     FieldTmplVersionUniqueDiff `json:"uniques"`
+}
+
+// CompareFieldTmplVersionResp compare two versions of field template response
+type CompareFieldTmplVersionResp struct {
+	BaseResp `json:",inline"`
+	Data     CompareFieldTmplVersionRes `json:"data"`
+}
+
+// FieldTmplVersionAttrDiff field template attribute difference between two versions
+type FieldTmplVersionAttrDiff struct {
+	Create []FieldTemplateAttr          `json:"create"`
+	Update []FieldTmplVersionAttrChange `json:"update"`
+	Delete []FieldTemplateAttr          `json:"delete"`
+}
+
+// FieldTmplVersionAttrChange field template attribute that is changed between two versions
+type FieldTmplVersionAttrChange struct {
+	PropertyID string            `json:"bk_property_id"`
+	Before     FieldTemplateAttr `json:"before"`
+	After      FieldTemplateAttr `json:"after"`
+	// ChangedFields the changed field names of the attribute
+	ChangedFields []string `json:"changed_fields"`
+}
+
+// FieldTmplVersionUniqueDiff field template unique difference between two versions, unique keys are property ids
+type FieldTmplVersionUniqueDiff struct {
+	Create []FieldTmplUniqueForUpdate     `json:"create"`
+	Update []FieldTmplVersionUniqueChange `json:"update"`
+	Delete []FieldTmplUniqueForUpdate     `json:"delete"`
+}
+
+// FieldTmplVersionUniqueChange field template unique whose keys are changed between two versions
+type FieldTmplVersionUniqueChange struct {
+	ID     int64    `json:"id"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+}
+
+// DiffFieldTemplateVersion get the difference from one version of field template to another
+func DiffFieldTemplateVersion(from, to *FieldTemplateVersion) (*CompareFieldTmplVersionRes, ccErr.RawErrorInfo) {
+	res := &CompareFieldTmplVersionRes{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Attributes: FieldTmplVersionAttrDiff{
+			Create: make([]FieldTemplateAttr, 0),
+			Update: make([]FieldTmplVersionAttrChange, 0),
+			Delete: make([]FieldTemplateAttr, 0),
+		},
+		Uniques: FieldTmplVersionUniqueDiff{
+			Create: make([]FieldTmplUniqueForUpdate, 0),
+			Update: make([]FieldTmplVersionUniqueChange, 0),
+			Delete: make([]FieldTmplUniqueForUpdate, 0),
+		},
+	}
+
+	fromAttrMap := make(map[string]FieldTemplateAttr)
+	for _, attr := range from.Attributes {
+		fromAttrMap[attr.PropertyID] = attr
+	}
+
+	for _, attr := range to.Attributes {
+		fromAttr, exists := fromAttrMap[attr.PropertyID]
+		if !exists {
+			res.Attributes.Create = append(res.Attributes.Create, attr)
+			continue
+		}
+		delete(fromAttrMap, attr.PropertyID)
+
+		changedFields := diffFieldTmplAttr(&fromAttr, &attr)
+		if len(changedFields) == 0 {
+			continue
+		}
+
+		res.Attributes.Update = append(res.Attributes.Update, FieldTmplVersionAttrChange{
+			PropertyID:    attr.PropertyID,
+			Before:        fromAttr,
+			After:         attr,
+			ChangedFields: changedFields,
+		})
+	}
+
+	// keep the original order of the deleted attributes
+	for _, attr := range from.Attributes {
+		if _, exists := fromAttrMap[attr.PropertyID]; exists {
+			res.Attributes.Delete = append(res.Attributes.Delete, attr)
+		}
+	}
+
+	fromUniques, err := from.UniqueOptions()
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+
+	toUniques, err := to.UniqueOptions()
+	if err.ErrCode != 0 {
+		return nil, err
+	}
+
+	fromUniqueMap := make(map[int64]FieldTmplUniqueForUpdate)
+	for _, unique := range fromUniques {
+		fromUniqueMap[unique.ID] = unique
+	}
+
+	for _, unique := range toUniques {
+		fromUnique, exists := fromUniqueMap[unique.ID]
+		if !exists {
+			res.Uniques.Create = append(res.Uniques.Create, unique)
+			continue
+		}
+		delete(fromUniqueMap, unique.ID)
+
+		if isSameUniqueKeys(fromUnique.Keys, unique.Keys) {
+			continue
+		}
+
+		res.Uniques.Update = append(res.Uniques.Update, FieldTmplVersionUniqueChange{
+			ID:     unique.ID,
+			Before: fromUnique.Keys,
+			After:  unique.Keys,
+		})
+	}
+
+	for _, unique := range fromUniques {
+		if _, exists := fromUniqueMap[unique.ID]; exists {
+			res.Uniques.Delete = append(res.Uniques.Delete, unique)
+		}
+	}
+
+	return res, ccErr.RawErrorInfo{}
+}
+
+// diffFieldTmplAttr returns the changed field names between two field template attributes with the same property id,
+// ids, index and operator info are not compared since they are not the definition of the attribute
+func diffFieldTmplAttr(from, to *FieldTemplateAttr) []string {
+	changedFields := make([]string, 0)
+	if from.PropertyName != to.PropertyName {
+		changedFields = append(changedFields, common.BKPropertyNameField)
+	}
+
+	if from.PropertyType != to.PropertyType {
+		changedFields = append(changedFields, common.BKPropertyTypeField)
+	}
+
+	if from.Unit != to.Unit {
+		changedFields = append(changedFields, AttributeFieldUnit)
+	}
+
+	if from.Placeholder != to.Placeholder {
+		changedFields = append(changedFields, AttributeFieldPlaceHolder)
+	}
+
+	if from.Editable != to.Editable {
+		changedFields = append(changedFields, "editable")
+	}
+
+	if from.Required != to.Required {
+		changedFields = append(changedFields, common.BKIsRequiredField)
+	}
+
+	if !reflect.DeepEqual(from.Option, to.Option) {
+		changedFields = append(changedFields, common.BKOptionField)
+	}
+
+	if !reflect.DeepEqual(from.Default, to.Default) {
+		changedFields = append(changedFields, AttributeFieldDefault)
+	}
+
+	if from.IsMultiple != to.IsMultiple {
+		changedFields = append(changedFields, common.BKIsMultipleField)
+	}
+
+	return changedFields
+}
+
+// isSameUniqueKeys check if two unique keys contain the same property ids regardless of their order
+func isSameUniqueKeys(keys1, keys2 []string) bool {
+	if len(keys1) != len(keys2) {
+		return false
+	}
+
+	sorted1 := append([]string{}, keys1...)
+	sorted2 := append([]string{}, keys2...)
+	sort.Strings(sorted1)
+	sort.Strings(sorted2)
+
+	for idx := range sorted1 {
+		if sorted1[idx] != sorted2[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UpgradeObjFieldTmplVersionOption pin objects bound to the field template to the specified version option
+type UpgradeObjFieldTmplVersionOption struct {
+	TemplateID int64   `json:"bk_template_id"`
+	ObjectIDs  []int64 `json:"object_ids"`
+	Version    int64   `json:"version"`
+}
+
+// Validate pin objects bound to the field template to the specified version option
+func (u *UpgradeObjFieldTmplVersionOption) Validate() ccErr.RawErrorInfo {
+	if u.TemplateID == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKTemplateID}}
+	}
+
+	if len(u.ObjectIDs) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"object_ids"}}
+	}
+
+	if len(u.ObjectIDs) > common.BKMaxLimitSize {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"object_ids", common.BKMaxLimitSize}}
+	}
+
+	for _, objID := range u.ObjectIDs {
+		if objID == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+				Args: []interface{}{common.ObjectIDField}}
+		}
+	}
+
+	if u.Version <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKVersion}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// RollbackFieldTmplOption rollback field template attributes and uniques to the specified version option
+type RollbackFieldTmplOption struct {
+	ID      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
+
+// Validate rollback field template option
+func (r *RollbackFieldTmplOption) Validate() ccErr.RawErrorInfo {
+	if r.ID == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKFieldID}}
+	}
+
+	if r.Version <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKVersion}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
@@ -50,6 +50,16 @@ const (
 	IndexNameObjectInstance = IndexNamePrefix + "object_instance"
 )
 
+// fulltext search engine types.
+const (
+	// FullTextEngineElastic fulltext search with elasticsearch, the documents are synchronized by monstache.
+	FullTextEngineElastic = "elasticsearch"
+
+	// FullTextEngineLocal fulltext search with the embedded inverted index of topo server, the documents are
+	// synchronized by the mongodb change stream.
+	FullTextEngineLocal = "local"
+)
+
 // elastic document data kind.
 const (
 	// DataKindModel data kind model.
@@ -0,0 +1,189 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+const (
+	// HostApplyReconcileEnabledField is the field that marks the business is opted in to host apply reconciliation
+	HostApplyReconcileEnabledField = "enabled"
+	// HostApplyReconcileAutoCorrectField is the field that marks the drifted hosts are corrected automatically
+	HostApplyReconcileAutoCorrectField = "auto_correct"
+	// HostApplyConflictDetectTimeField is the time field that the host apply conflict is detected
+	HostApplyConflictDetectTimeField = "detect_time"
+)
+
+// HostApplyReconcileSetting is the per-business opt-in setting of the host apply reconciler, the reconciler only
+// checks the hosts of the businesses that are enabled.
+type HostApplyReconcileSetting struct {
+	BizID int64 `json:"bk_biz_id" bson:"bk_biz_id"`
+	// Enabled marks the reconciler detects the hosts drifted away from their host apply rules in this business
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// AutoCorrect marks the reconciler updates the drifted hosts to the expected values, the fields whose rules
+	// disagree with each other are only recorded as conflicts
+	AutoCorrect bool      `json:"auto_correct" bson:"auto_correct"`
+	Modifier    string    `json:"modifier" bson:"modifier"`
+	LastTime    time.Time `json:"last_time" bson:"last_time"`
+	OwnerID     string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// HostApplyConflict is a host whose attributes drifted away from the host apply rules of its modules, it is
+// recorded by the host apply reconciler and replaced by each run.
+type HostApplyConflict struct {
+	BizID     int64                    `json:"bk_biz_id" bson:"bk_biz_id"`
+	HostID    int64                    `json:"bk_host_id" bson:"bk_host_id"`
+	ModuleIDs []int64                  `json:"bk_module_ids" bson:"bk_module_ids"`
+	Fields    []HostApplyConflictDrift `json:"fields" bson:"fields"`
+	// ErrMsg is the reason that the host is not corrected when auto correct is enabled
+	ErrMsg     string    `json:"err_msg,omitempty" bson:"err_msg,omitempty"`
+	DetectTime time.Time `json:"detect_time" bson:"detect_time"`
+	OwnerID    string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// HostApplyConflictDrift is a host attribute whose value differs from the host apply rules
+type HostApplyConflictDrift struct {
+	AttributeID int64  `json:"bk_attribute_id" bson:"bk_attribute_id"`
+	PropertyID  string `json:"bk_property_id" bson:"bk_property_id"`
+	// PropertyValue is the current value of the host attribute
+	PropertyValue interface{} `json:"bk_property_value" bson:"bk_property_value"`
+	// ExpectValue is the value of the rule, it is empty if the rules of the host's modules disagree
+	ExpectValue interface{} `json:"expect_value" bson:"expect_value"`
+	// Ambiguous marks the rules of the host's modules disagree with each other, so it can not be corrected
+	Ambiguous bool `json:"ambiguous" bson:"ambiguous"`
+}
+
+// UpdateHostApplyReconcileSettingOption update the host apply reconcile setting of a business option
+type UpdateHostApplyReconcileSettingOption struct {
+	Enabled     bool `json:"enabled"`
+	AutoCorrect bool `json:"auto_correct"`
+}
+
+// Validate update host apply reconcile setting option
+func (o *UpdateHostApplyReconcileSettingOption) Validate() ccErr.RawErrorInfo {
+	if o.AutoCorrect && !o.Enabled {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid,
+			Args: []interface{}{HostApplyReconcileAutoCorrectField}}
+	}
+	return ccErr.RawErrorInfo{}
+}
+
+// ListHostApplyReconcileSettingOption list host apply reconcile settings option
+type ListHostApplyReconcileSettingOption struct {
+	// BizIDs is optional, the settings of all businesses are returned if it is not set
+	BizIDs []int64 `json:"bk_biz_ids"`
+	// OnlyEnabled marks only the settings of the businesses opted in are returned
+	OnlyEnabled bool `json:"only_enabled"`
+}
+
+// ListHostApplyConflictOption list the host apply conflicts of a business option
+type ListHostApplyConflictOption struct {
+	BizID int64 `json:"bk_biz_id"`
+	// HostIDs is optional, all the conflicts of the business are returned if it is not set
+	HostIDs []int64  `json:"bk_host_ids"`
+	Page    BasePage `json:"page"`
+}
+
+// Validate list host apply conflict option
+func (o *ListHostApplyConflictOption) Validate() ccErr.RawErrorInfo {
+	if o.BizID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKAppIDField}}
+	}
+
+	return o.Page.ValidateWithEnableCount(false)
+}
+
+// ReplaceHostApplyConflictOption replace all the host apply conflicts of a business option
+type ReplaceHostApplyConflictOption struct {
+	BizID     int64               `json:"bk_biz_id"`
+	Conflicts []HostApplyConflict `json:"conflicts"`
+}
+
+// Validate replace host apply conflict option
+func (o *ReplaceHostApplyConflictOption) Validate() ccErr.RawErrorInfo {
+	if o.BizID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKAppIDField}}
+	}
+
+	for _, conflict := range o.Conflicts {
+		if conflict.BizID != o.BizID || conflict.HostID <= 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"conflicts"}}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// PreviewHostApplyRuleOption preview the effect of a host apply rule change on all the affected hosts option,
+// the rules can be of modules or service templates, and the change is not saved.
+type PreviewHostApplyRuleOption struct {
+	BizID           int64                       `json:"bk_biz_id"`
+	AdditionalRules []CreateHostApplyRuleOption `json:"additional_rules"`
+	RemoveRuleIDs   []int64                     `json:"remove_rule_ids"`
+}
+
+// Validate preview host apply rule option
+func (o *PreviewHostApplyRuleOption) Validate() ccErr.RawErrorInfo {
+	if rawErr := hostApplyBaseValidate(o.BizID, o.AdditionalRules, o.RemoveRuleIDs); rawErr.ErrCode != 0 {
+		return rawErr
+	}
+
+	for _, rule := range o.AdditionalRules {
+		if (rule.ModuleID == 0) == (rule.ServiceTemplateID == 0) {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{"bk_module_id or service_template_id"}}
+		}
+
+		if rule.AttributeID <= 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"bk_attribute_id"}}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// HostApplyReconcileResult is the result of one reconciliation of a business
+type HostApplyReconcileResult struct {
+	// HostCount is the count of the hosts checked
+	HostCount int `json:"host_count"`
+	// ConflictCount is the count of the hosts that still drift away from the rules after reconciliation
+	ConflictCount int `json:"conflict_count"`
+	// CorrectedCount is the count of the hosts that are corrected automatically
+	CorrectedCount int `json:"corrected_count"`
+}
+
+// HostApplyReconcileSettingResp host apply reconcile setting response
+type HostApplyReconcileSettingResp struct {
+	BaseResp `json:",inline"`
+	Data     []HostApplyReconcileSetting `json:"data"`
+}
+
+// HostApplyConflictResult host apply conflict query result
+type HostApplyConflictResult struct {
+	Count int64               `json:"count"`
+	Info  []HostApplyConflict `json:"info"`
+}
+
+// HostApplyConflictResp host apply conflict query response
+type HostApplyConflictResp struct {
+	BaseResp `json:",inline"`
+	Data     *HostApplyConflictResult `json:"data"`
+}
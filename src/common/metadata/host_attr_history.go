@@ -0,0 +1,170 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+const (
+	// hostAttrHistoryMaxHostCount is the max host count of one host attribute history or drift query
+	hostAttrHistoryMaxHostCount = 500
+
+	// HostAttrFieldField is the host attribute field name field of the host attribute history and drift
+	HostAttrFieldField = "field"
+	// HostAttrValueField is the collected value field of the host attribute history
+	HostAttrValueField = "value"
+	// HostAttrFirstSeenField is the first seen time field of the host attribute history
+	HostAttrFirstSeenField = "first_seen"
+	// HostAttrLastSeenField is the last seen time field of the host attribute history
+	HostAttrLastSeenField = "last_seen"
+	// HostAttrCollectedValueField is the collected value field of the host attribute drift
+	HostAttrCollectedValueField = "collected_value"
+	// HostAttrHostValueField is the host's current value field of the host attribute drift
+	HostAttrHostValueField = "host_value"
+)
+
+// HostAttrHistory is a period that the host attribute is collected with the same value, a new period is recorded
+// when the collected value changes, so the periods of a host attribute make up its time series.
+type HostAttrHistory struct {
+	ID        int64     `json:"id" bson:"id"`
+	HostID    int64     `json:"bk_host_id" bson:"bk_host_id"`
+	Field     string    `json:"field" bson:"field"`
+	Value     string    `json:"value" bson:"value"`
+	FirstSeen time.Time `json:"first_seen" bson:"first_seen"`
+	LastSeen  time.Time `json:"last_seen" bson:"last_seen"`
+	OwnerID   string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// HostAttrValue is the first seen and last seen time of a collected value of the host attribute
+type HostAttrValue struct {
+	HostID    int64     `json:"bk_host_id" bson:"bk_host_id"`
+	Field     string    `json:"field" bson:"field"`
+	Value     string    `json:"value" bson:"value"`
+	FirstSeen time.Time `json:"first_seen" bson:"first_seen"`
+	LastSeen  time.Time `json:"last_seen" bson:"last_seen"`
+	// Periods is the count of the periods that the value is collected
+	Periods int64 `json:"periods" bson:"periods"`
+}
+
+// HostAttrDrift is the host attribute whose collected value disagrees with the manually maintained value, which is
+// configured not to be overwritten by the collected data.
+type HostAttrDrift struct {
+	HostID         int64     `json:"bk_host_id" bson:"bk_host_id"`
+	Field          string    `json:"field" bson:"field"`
+	CollectedValue string    `json:"collected_value" bson:"collected_value"`
+	HostValue      string    `json:"host_value" bson:"host_value"`
+	OwnerID        string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime     time.Time `json:"create_time" bson:"create_time"`
+	LastTime       time.Time `json:"last_time" bson:"last_time"`
+}
+
+// FindHostAttrHistoryOption find host attribute history option
+type FindHostAttrHistoryOption struct {
+	HostIDs []int64  `json:"bk_host_ids"`
+	Fields  []string `json:"fields"`
+	// StartTime and EndTime are unix timestamps, the periods overlapped with the time range are returned
+	StartTime int64    `json:"start_time"`
+	EndTime   int64    `json:"end_time"`
+	Page      BasePage `json:"page"`
+}
+
+// Validate find host attribute history option
+func (o *FindHostAttrHistoryOption) Validate() ccErr.RawErrorInfo {
+	if rawErr := validateHostAttrHostIDs(o.HostIDs, true); rawErr.ErrCode != 0 {
+		return rawErr
+	}
+
+	if o.StartTime < 0 || o.EndTime < 0 || (o.EndTime != 0 && o.StartTime > o.EndTime) {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{"start_time"}}
+	}
+
+	return o.Page.ValidateWithEnableCount(false)
+}
+
+// FindHostAttrValueOption find the first seen and last seen time of the host attribute values option
+type FindHostAttrValueOption struct {
+	HostIDs []int64  `json:"bk_host_ids"`
+	Fields  []string `json:"fields"`
+}
+
+// Validate find host attribute value option
+func (o *FindHostAttrValueOption) Validate() ccErr.RawErrorInfo {
+	return validateHostAttrHostIDs(o.HostIDs, true)
+}
+
+// FindHostAttrDriftOption find host attribute drift option
+type FindHostAttrDriftOption struct {
+	// HostIDs is optional, all drifted hosts are returned if it is not set
+	HostIDs []int64  `json:"bk_host_ids"`
+	Fields  []string `json:"fields"`
+	Page    BasePage `json:"page"`
+}
+
+// Validate find host attribute drift option
+func (o *FindHostAttrDriftOption) Validate() ccErr.RawErrorInfo {
+	if rawErr := validateHostAttrHostIDs(o.HostIDs, false); rawErr.ErrCode != 0 {
+		return rawErr
+	}
+
+	return o.Page.ValidateWithEnableCount(false)
+}
+
+func validateHostAttrHostIDs(hostIDs []int64, required bool) ccErr.RawErrorInfo {
+	if required && len(hostIDs) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"bk_host_ids"}}
+	}
+
+	if len(hostIDs) > hostAttrHistoryMaxHostCount {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"bk_host_ids", hostAttrHistoryMaxHostCount}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// HostAttrHistoryResult host attribute history query result
+type HostAttrHistoryResult struct {
+	Count int64             `json:"count"`
+	Info  []HostAttrHistory `json:"info"`
+}
+
+// HostAttrHistoryResp host attribute history query response
+type HostAttrHistoryResp struct {
+	BaseResp `json:",inline"`
+	Data     *HostAttrHistoryResult `json:"data"`
+}
+
+// HostAttrValueResp host attribute value query response
+type HostAttrValueResp struct {
+	BaseResp `json:",inline"`
+	Data     []HostAttrValue `json:"data"`
+}
+
+// HostAttrDriftResult host attribute drift query result
+type HostAttrDriftResult struct {
+	Count int64           `json:"count"`
+	Info  []HostAttrDrift `json:"info"`
+}
+
+// HostAttrDriftResp host attribute drift query response
+type HostAttrDriftResp struct {
+	BaseResp `json:",inline"`
+	Data     *HostAttrDriftResult `json:"data"`
+}
@@ -0,0 +1,146 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"fmt"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+const (
+	// InstAsstGraphMaxDepth is the max hops that the instance association graph can be traversed
+	InstAsstGraphMaxDepth = 5
+	// InstAsstGraphMaxNodes is the max node count of the instance association graph
+	InstAsstGraphMaxNodes = 1000
+	// InstAsstGraphMaxEdges is the max edge count of the instance association graph
+	InstAsstGraphMaxEdges = 3000
+)
+
+// InstAsstDirection is the direction of the associations to traverse, source instance's association points to
+// the target instance
+type InstAsstDirection string
+
+const (
+	// InstAsstDirectionOut traverse the associations that the instance is the source of
+	InstAsstDirectionOut InstAsstDirection = "out"
+	// InstAsstDirectionIn traverse the associations that the instance is the target of
+	InstAsstDirectionIn InstAsstDirection = "in"
+	// InstAsstDirectionBoth traverse the associations in both directions
+	InstAsstDirectionBoth InstAsstDirection = "both"
+)
+
+// InstAsstGraphOption search instance association graph option
+type InstAsstGraphOption struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	// Depth is the max hops from the start instance, defaults to 1
+	Depth     int               `json:"depth"`
+	Direction InstAsstDirection `json:"direction"`
+	// AsstKindIDs is the association kinds to traverse, all kinds are traversed if it is not set
+	AsstKindIDs []string `json:"bk_asst_ids"`
+	// ObjIDs is the objects whose instances can be traversed to, all objects can be traversed to if it is not set
+	ObjIDs []string `json:"bk_obj_ids"`
+	// WithMainline specifies whether to traverse the mainline topology and host relations, mainline relations
+	// point from the child to the parent, e.g. from the host to its modules, from the module to its set
+	WithMainline bool `json:"with_mainline"`
+	// MaxNodes and MaxEdges limit the graph size, defaults to and can not exceed the server side limits
+	MaxNodes int `json:"max_nodes"`
+	MaxEdges int `json:"max_edges"`
+}
+
+// Validate search instance association graph option, set the default values if they are not set
+func (o *InstAsstGraphOption) Validate() ccErr.RawErrorInfo {
+	if len(o.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if o.InstID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	if o.Depth == 0 {
+		o.Depth = 1
+	}
+	if o.Depth < 0 || o.Depth > InstAsstGraphMaxDepth {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{"depth"}}
+	}
+
+	switch o.Direction {
+	case "":
+		o.Direction = InstAsstDirectionBoth
+	case InstAsstDirectionOut, InstAsstDirectionIn, InstAsstDirectionBoth:
+	default:
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsIsInvalid, Args: []interface{}{"direction"}}
+	}
+
+	if o.MaxNodes == 0 {
+		o.MaxNodes = InstAsstGraphMaxNodes
+	}
+	if o.MaxNodes < 0 || o.MaxNodes > InstAsstGraphMaxNodes {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"max_nodes", InstAsstGraphMaxNodes}}
+	}
+
+	if o.MaxEdges == 0 {
+		o.MaxEdges = InstAsstGraphMaxEdges
+	}
+	if o.MaxEdges < 0 || o.MaxEdges > InstAsstGraphMaxEdges {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"max_edges", InstAsstGraphMaxEdges}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// InstAsstGraphNodeID generate the node id of the instance in the instance association graph
+func InstAsstGraphNodeID(objID string, instID int64) string {
+	return fmt.Sprintf("%s:%d", objID, instID)
+}
+
+// InstAsstGraph is the instance association graph traversed from the start instance
+type InstAsstGraph struct {
+	Nodes []InstAsstGraphNode `json:"nodes"`
+	Edges []InstAsstGraphEdge `json:"edges"`
+	// Cycles is the cycles found in the graph, each cycle is the node ids in order
+	Cycles [][]string `json:"cycles"`
+	// Truncated is set when the node or edge limit is reached, the graph is not complete
+	Truncated bool `json:"truncated"`
+}
+
+// InstAsstGraphNode is an instance in the instance association graph
+type InstAsstGraphNode struct {
+	ID       string `json:"id"`
+	ObjID    string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+	// Depth is the hops from the start instance
+	Depth int `json:"depth"`
+	// Path is the node ids of the shortest path from the start instance to this one
+	Path []string `json:"path"`
+}
+
+// InstAsstGraphEdge is an association in the instance association graph
+type InstAsstGraphEdge struct {
+	// AsstID is the instance association id, it is 0 for mainline relations
+	AsstID     int64  `json:"id"`
+	ObjAsstID  string `json:"bk_obj_asst_id"`
+	AsstKindID string `json:"bk_asst_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
@@ -0,0 +1,228 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/common/util"
+)
+
+const (
+	lifecycleStateMaxCount      = 50
+	lifecycleTransitionMaxCount = 200
+	lifecycleReasonMaxLen       = 512
+)
+
+// ObjectLifecycle is the lifecycle state machine of an object's instances
+type ObjectLifecycle struct {
+	ID            int64  `json:"id" bson:"id"`
+	ObjID         string `json:"bk_obj_id" bson:"bk_obj_id"`
+	LifecycleSpec `json:",inline" bson:",inline"`
+	OwnerID       string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator       string `json:"creator" bson:"creator"`
+	Modifier      string `json:"modifier" bson:"modifier"`
+	CreateTime    Time   `json:"create_time" bson:"create_time"`
+	LastTime      Time   `json:"last_time" bson:"last_time"`
+}
+
+// LifecycleSpec is the user defined content of a lifecycle state machine
+type LifecycleSpec struct {
+	// PropertyID is the enum attribute that stores the instance's state, its enum option ids are the state ids
+	PropertyID string `json:"bk_property_id" bson:"bk_property_id"`
+	// InitialState is the state of the newly created instances that do not specify one
+	InitialState string                `json:"initial_state" bson:"initial_state"`
+	States       []LifecycleState      `json:"states" bson:"states"`
+	Transitions  []LifecycleTransition `json:"transitions" bson:"transitions"`
+}
+
+// LifecycleState is a state of the lifecycle
+type LifecycleState struct {
+	ID string `json:"id" bson:"id"`
+	// RequiredFields is the fields that must be set when the instance is in this state
+	RequiredFields []string `json:"required_fields,omitempty" bson:"required_fields,omitempty"`
+}
+
+// LifecycleTransition is an allowed transition between two states
+type LifecycleTransition struct {
+	From string `json:"from" bson:"from"`
+	To   string `json:"to" bson:"to"`
+	// Operators is the users who may perform the transition, anyone with the instance's update permission
+	// can perform it if it is not set
+	Operators []string `json:"operators,omitempty" bson:"operators,omitempty"`
+}
+
+// Validate lifecycle spec
+func (l *LifecycleSpec) Validate() ccErr.RawErrorInfo {
+	if len(l.PropertyID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKPropertyIDField}}
+	}
+
+	if len(l.States) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"states"}}
+	}
+
+	if len(l.States) > lifecycleStateMaxCount {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"states", lifecycleStateMaxCount}}
+	}
+
+	if len(l.Transitions) > lifecycleTransitionMaxCount {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"transitions", lifecycleTransitionMaxCount}}
+	}
+
+	states := make(map[string]struct{})
+	for _, state := range l.States {
+		if len(state.ID) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"states.id"}}
+		}
+		if _, exists := states[state.ID]; exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem, Args: []interface{}{state.ID}}
+		}
+		states[state.ID] = struct{}{}
+	}
+
+	if _, exists := states[l.InitialState]; len(l.InitialState) != 0 && !exists {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"initial_state"}}
+	}
+
+	transitions := make(map[string]struct{})
+	for _, transition := range l.Transitions {
+		_, fromExists := states[transition.From]
+		_, toExists := states[transition.To]
+		if !fromExists || !toExists || transition.From == transition.To {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{fmt.Sprintf("transitions(%s->%s)", transition.From, transition.To)}}
+		}
+
+		key := transition.From + "->" + transition.To
+		if _, exists := transitions[key]; exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem, Args: []interface{}{key}}
+		}
+		transitions[key] = struct{}{}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// Fields returns all the instance fields that the lifecycle refers to
+func (l *LifecycleSpec) Fields() []string {
+	fields := []string{l.PropertyID}
+	for _, state := range l.States {
+		fields = append(fields, state.RequiredFields...)
+	}
+	return util.StrArrayUnique(fields)
+}
+
+// GetState returns the state by id, returns nil if it does not exist
+func (l *LifecycleSpec) GetState(id string) *LifecycleState {
+	for idx := range l.States {
+		if l.States[idx].ID == id {
+			return &l.States[idx]
+		}
+	}
+	return nil
+}
+
+// GetTransition returns the transition between the two states, returns nil if it is not allowed
+func (l *LifecycleSpec) GetTransition(from, to string) *LifecycleTransition {
+	for idx := range l.Transitions {
+		if l.Transitions[idx].From == from && l.Transitions[idx].To == to {
+			return &l.Transitions[idx]
+		}
+	}
+	return nil
+}
+
+// CanOperate checks if the user may perform the transition, all users can perform it if no operator is specified
+func (t *LifecycleTransition) CanOperate(user string) bool {
+	if len(t.Operators) == 0 {
+		return true
+	}
+	return util.InStrArr(t.Operators, user)
+}
+
+// SaveLifecycleOption create or update object lifecycle option, an object can only have one lifecycle
+type SaveLifecycleOption struct {
+	ObjID         string `json:"bk_obj_id"`
+	LifecycleSpec `json:",inline"`
+}
+
+// Validate save object lifecycle option
+func (s *SaveLifecycleOption) Validate() ccErr.RawErrorInfo {
+	if len(s.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	return s.LifecycleSpec.Validate()
+}
+
+// FindLifecycleOption find object lifecycle option
+type FindLifecycleOption struct {
+	ObjID string `json:"bk_obj_id"`
+}
+
+// Validate find object lifecycle option
+func (f *FindLifecycleOption) Validate() ccErr.RawErrorInfo {
+	if len(f.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// FindLifecycleResp find object lifecycle response, data is nil if the object has no lifecycle
+type FindLifecycleResp struct {
+	BaseResp `json:",inline"`
+	Data     *ObjectLifecycle `json:"data"`
+}
+
+// TransitionInstOption transit instance state option
+type TransitionInstOption struct {
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// Validate transit instance state option
+func (t *TransitionInstOption) Validate() ccErr.RawErrorInfo {
+	if len(t.To) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"to"}}
+	}
+
+	if len(t.Reason) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"reason"}}
+	}
+
+	if utf8.RuneCountInString(t.Reason) > lifecycleReasonMaxLen {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommValExceedMaxFailed,
+			Args: []interface{}{"reason", lifecycleReasonMaxLen}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// LifecycleTransitionDetail is the state transition detail of an instance's audit log
+type LifecycleTransitionDetail struct {
+	PropertyID string `json:"bk_property_id" bson:"bk_property_id"`
+	From       string `json:"from" bson:"from"`
+	To         string `json:"to" bson:"to"`
+	Reason     string `json:"reason" bson:"reason"`
+}
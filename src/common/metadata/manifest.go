@@ -0,0 +1,457 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+// ManifestVersion is the current version of the topology manifest format
+const ManifestVersion = "v1"
+
+// Manifest is the declarative description of the models and the business topology, the resources are identified
+// by their names or string ids instead of the auto increment ids, so that a manifest exported from one system can
+// be planned and applied to another one. A manifest only creates or updates the resources, the live resources that
+// are not in the manifest are left untouched.
+type Manifest struct {
+	Version          string                    `json:"version"`
+	Classifications  []ManifestClassification  `json:"classifications,omitempty"`
+	Models           []ManifestModel           `json:"models,omitempty"`
+	Associations     []ManifestAssociation     `json:"associations,omitempty"`
+	FieldTemplates   []ManifestFieldTemplate   `json:"field_templates,omitempty"`
+	ServiceTemplates []ManifestServiceTemplate `json:"service_templates,omitempty"`
+	SetTemplates     []ManifestSetTemplate     `json:"set_templates,omitempty"`
+	Businesses       []ManifestBusiness        `json:"businesses,omitempty"`
+}
+
+// ManifestClassification is the model classification in the manifest
+type ManifestClassification struct {
+	ID   string `json:"bk_classification_id"`
+	Name string `json:"bk_classification_name"`
+	Icon string `json:"bk_classification_icon,omitempty"`
+}
+
+// ManifestModel is the model with its attribute groups, attributes and unique rules in the manifest, for the
+// preset models only the groups, attributes and unique rules are managed.
+type ManifestModel struct {
+	ObjectID       string              `json:"bk_obj_id"`
+	Name           string              `json:"bk_obj_name"`
+	Classification string              `json:"bk_classification_id"`
+	Icon           string              `json:"bk_obj_icon,omitempty"`
+	Groups         []ManifestGroup     `json:"groups,omitempty"`
+	Attributes     []ManifestAttribute `json:"attributes,omitempty"`
+	// Uniques is the unique rules of the model, each one is the property ids of the rule
+	Uniques [][]string `json:"uniques,omitempty"`
+}
+
+// ManifestGroup is the attribute group in the manifest
+type ManifestGroup struct {
+	ID         string `json:"bk_group_id"`
+	Name       string `json:"bk_group_name"`
+	Index      int64  `json:"bk_group_index"`
+	IsCollapse bool   `json:"is_collapse,omitempty"`
+}
+
+// ManifestAttribute is the model or field template attribute in the manifest
+type ManifestAttribute struct {
+	PropertyID    string      `json:"bk_property_id"`
+	PropertyName  string      `json:"bk_property_name"`
+	PropertyType  string      `json:"bk_property_type"`
+	PropertyGroup string      `json:"bk_property_group,omitempty"`
+	PropertyIndex int64       `json:"bk_property_index,omitempty"`
+	Unit          string      `json:"unit,omitempty"`
+	Placeholder   string      `json:"placeholder,omitempty"`
+	IsEditable    bool        `json:"editable"`
+	IsRequired    bool        `json:"isrequired"`
+	IsMultiple    bool        `json:"ismultiple,omitempty"`
+	Option        interface{} `json:"option,omitempty"`
+	Default       interface{} `json:"default,omitempty"`
+	Description   string      `json:"description,omitempty"`
+}
+
+// ManifestAssociation is the model association in the manifest, the mainline associations are not included
+type ManifestAssociation struct {
+	AssociationName string                    `json:"bk_obj_asst_id"`
+	AliasName       string                    `json:"bk_obj_asst_name,omitempty"`
+	ObjectID        string                    `json:"bk_obj_id"`
+	AsstObjID       string                    `json:"bk_asst_obj_id"`
+	AsstKindID      string                    `json:"bk_asst_id"`
+	Mapping         AssociationMapping        `json:"mapping"`
+	OnDelete        AssociationOnDeleteAction `json:"on_delete,omitempty"`
+}
+
+// ManifestFieldTemplate is the field template in the manifest
+type ManifestFieldTemplate struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Attributes  []ManifestAttribute `json:"attributes"`
+	Uniques     [][]string          `json:"uniques,omitempty"`
+}
+
+// ManifestServiceTemplate is the service template of a business in the manifest
+type ManifestServiceTemplate struct {
+	BizName string `json:"bk_biz_name"`
+	Name    string `json:"name"`
+	// Category is the path of the service category, like "parent/child"
+	Category string `json:"service_category"`
+}
+
+// ManifestSetTemplate is the set template of a business in the manifest
+type ManifestSetTemplate struct {
+	BizName          string   `json:"bk_biz_name"`
+	Name             string   `json:"name"`
+	ServiceTemplates []string `json:"service_templates,omitempty"`
+}
+
+// ManifestBusiness is the business with its sets and modules in the manifest
+type ManifestBusiness struct {
+	Name string `json:"bk_biz_name"`
+	// Attributes is the other attributes of the business, like bk_biz_maintainer
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Sets       []ManifestSet          `json:"sets,omitempty"`
+}
+
+// ManifestSet is the set in the manifest, the modules of the set created by set template are generated from the
+// template and should not be specified
+type ManifestSet struct {
+	Name        string           `json:"bk_set_name"`
+	SetTemplate string           `json:"set_template,omitempty"`
+	Modules     []ManifestModule `json:"modules,omitempty"`
+}
+
+// ManifestModule is the module in the manifest
+type ManifestModule struct {
+	Name            string `json:"bk_module_name"`
+	ServiceTemplate string `json:"service_template,omitempty"`
+}
+
+// ManifestKey generates the key of the resource in the manifest by its identifying names
+func ManifestKey(names ...string) string {
+	return strings.Join(names, "/")
+}
+
+// ManifestUniqueKey generates the key of the unique rule by its property ids
+func ManifestUniqueKey(keys []string) string {
+	return strings.Join(keys, "+")
+}
+
+// Validate validates the manifest, the resources must have their identifying fields and must not be duplicated
+func (m *Manifest) Validate() ccErr.RawErrorInfo {
+	if m.Version != ManifestVersion {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"version"}}
+	}
+
+	keys := make(map[string]struct{})
+	checkKey := func(field string, kind ManifestKind, names ...string) ccErr.RawErrorInfo {
+		if err := requireManifestFields(field, names...); err.ErrCode != 0 {
+			return err
+		}
+
+		key := string(kind) + ":" + ManifestKey(names...)
+		if _, exists := keys[key]; exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem, Args: []interface{}{key}}
+		}
+		keys[key] = struct{}{}
+		return ccErr.RawErrorInfo{}
+	}
+
+	for _, cls := range m.Classifications {
+		if err := checkKey("classifications", ManifestKindClassification, cls.ID); err.ErrCode != 0 {
+			return err
+		}
+		if err := requireManifestFields("classifications", cls.Name); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	for _, model := range m.Models {
+		if err := m.validateModel(&model, checkKey); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	for _, asst := range m.Associations {
+		if err := checkKey("associations", ManifestKindAssociation, asst.AssociationName); err.ErrCode != 0 {
+			return err
+		}
+		err := requireManifestFields("associations", asst.ObjectID, asst.AsstObjID, asst.AsstKindID,
+			string(asst.Mapping))
+		if err.ErrCode != 0 {
+			return err
+		}
+		if asst.AsstKindID == common.AssociationKindMainline {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{"associations." + asst.AssociationName}}
+		}
+	}
+
+	for _, template := range m.FieldTemplates {
+		if err := checkKey("field_templates", ManifestKindFieldTemplate, template.Name); err.ErrCode != 0 {
+			return err
+		}
+		prefix := ManifestKey("field_templates", template.Name)
+		if err := validateManifestAttrs(prefix, template.Attributes, template.Uniques); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	for _, template := range m.ServiceTemplates {
+		err := checkKey("service_templates", ManifestKindServiceTemplate, template.BizName, template.Name)
+		if err.ErrCode != 0 {
+			return err
+		}
+		if err := requireManifestFields("service_templates", template.Category); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	for _, template := range m.SetTemplates {
+		err := checkKey("set_templates", ManifestKindSetTemplate, template.BizName, template.Name)
+		if err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	for _, biz := range m.Businesses {
+		if err := m.validateBusiness(&biz, checkKey); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+func (m *Manifest) validateModel(model *ManifestModel,
+	checkKey func(string, ManifestKind, ...string) ccErr.RawErrorInfo) ccErr.RawErrorInfo {
+
+	if err := checkKey("models", ManifestKindModel, model.ObjectID); err.ErrCode != 0 {
+		return err
+	}
+	if err := requireManifestFields("models", model.Name); err.ErrCode != 0 {
+		return err
+	}
+	if len(model.Classification) == 0 && !common.IsInnerModel(model.ObjectID) {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+			Args: []interface{}{ManifestKey("models", model.ObjectID, common.BKClassificationIDField)}}
+	}
+
+	for _, group := range model.Groups {
+		if err := checkKey("models.groups", ManifestKindAttributeGroup, model.ObjectID, group.ID); err.ErrCode != 0 {
+			return err
+		}
+		if err := requireManifestFields("models.groups", group.Name); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	return validateManifestAttrs(ManifestKey("models", model.ObjectID), model.Attributes, model.Uniques)
+}
+
+func requireManifestFields(field string, values ...string) ccErr.RawErrorInfo {
+	for _, value := range values {
+		if len(value) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{field}}
+		}
+	}
+	return ccErr.RawErrorInfo{}
+}
+
+func validateManifestAttrs(prefix string, attrs []ManifestAttribute, uniques [][]string) ccErr.RawErrorInfo {
+	propertyIDs := make(map[string]struct{})
+	for _, attr := range attrs {
+		if len(attr.PropertyID) == 0 || len(attr.PropertyName) == 0 || len(attr.PropertyType) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+				Args: []interface{}{prefix + ".attributes"}}
+		}
+		if _, exists := propertyIDs[attr.PropertyID]; exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem,
+				Args: []interface{}{ManifestKey(prefix, attr.PropertyID)}}
+		}
+		propertyIDs[attr.PropertyID] = struct{}{}
+	}
+
+	uniqueKeys := make(map[string]struct{})
+	for _, keys := range uniques {
+		if len(keys) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{prefix + ".uniques"}}
+		}
+		key := ManifestUniqueKey(keys)
+		if _, exists := uniqueKeys[key]; exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem,
+				Args: []interface{}{ManifestKey(prefix, key)}}
+		}
+		uniqueKeys[key] = struct{}{}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+func (m *Manifest) validateBusiness(biz *ManifestBusiness,
+	checkKey func(string, ManifestKind, ...string) ccErr.RawErrorInfo) ccErr.RawErrorInfo {
+
+	if err := checkKey("businesses", ManifestKindBusiness, biz.Name); err.ErrCode != 0 {
+		return err
+	}
+
+	for _, set := range biz.Sets {
+		if err := checkKey("businesses.sets", ManifestKindSet, biz.Name, set.Name); err.ErrCode != 0 {
+			return err
+		}
+		if len(set.SetTemplate) != 0 && len(set.Modules) != 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{fmt.Sprintf("businesses.sets(%s).modules", ManifestKey(biz.Name, set.Name))}}
+		}
+
+		for _, module := range set.Modules {
+			err := checkKey("businesses.sets.modules", ManifestKindModule, biz.Name, set.Name, module.Name)
+			if err.ErrCode != 0 {
+				return err
+			}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// ManifestKind is the kind of the resource in the manifest plan
+type ManifestKind string
+
+const (
+	// ManifestKindClassification is the model classification
+	ManifestKindClassification ManifestKind = "classification"
+	// ManifestKindModel is the model
+	ManifestKindModel ManifestKind = "model"
+	// ManifestKindAttributeGroup is the attribute group of the model
+	ManifestKindAttributeGroup ManifestKind = "attribute_group"
+	// ManifestKindAttribute is the attribute of the model
+	ManifestKindAttribute ManifestKind = "attribute"
+	// ManifestKindUnique is the unique rule of the model
+	ManifestKindUnique ManifestKind = "unique"
+	// ManifestKindAssociation is the model association
+	ManifestKindAssociation ManifestKind = "association"
+	// ManifestKindFieldTemplate is the field template
+	ManifestKindFieldTemplate ManifestKind = "field_template"
+	// ManifestKindFieldTemplateAttr is the attribute of the field template
+	ManifestKindFieldTemplateAttr ManifestKind = "field_template_attribute"
+	// ManifestKindFieldTemplateUnique is the unique rule of the field template
+	ManifestKindFieldTemplateUnique ManifestKind = "field_template_unique"
+	// ManifestKindServiceTemplate is the service template
+	ManifestKindServiceTemplate ManifestKind = "service_template"
+	// ManifestKindSetTemplate is the set template
+	ManifestKindSetTemplate ManifestKind = "set_template"
+	// ManifestKindBusiness is the business
+	ManifestKindBusiness ManifestKind = "business"
+	// ManifestKindSet is the set
+	ManifestKindSet ManifestKind = "set"
+	// ManifestKindModule is the module
+	ManifestKindModule ManifestKind = "module"
+)
+
+// ManifestAction is the action of the change in the manifest plan
+type ManifestAction string
+
+const (
+	// ManifestActionCreate creates the resource that does not exist in the live system
+	ManifestActionCreate ManifestAction = "create"
+	// ManifestActionUpdate updates the fields of the live resource that differ from the manifest
+	ManifestActionUpdate ManifestAction = "update"
+)
+
+// ManifestPlan is the structured diff between the manifest and the live system
+type ManifestPlan struct {
+	Changes []ManifestChange `json:"changes"`
+	// Conflicts is the differences that can not be applied, like changing the type of an attribute, the manifest
+	// can not be applied until the conflicts are resolved
+	Conflicts []ManifestConflict `json:"conflicts"`
+	// Digest is the digest of the changes, apply with it to make sure the live system has not changed since planning
+	Digest string `json:"digest"`
+}
+
+// ManifestChange is a change to apply to the live system
+type ManifestChange struct {
+	Kind   ManifestKind        `json:"kind"`
+	Action ManifestAction      `json:"action"`
+	Key    string              `json:"key"`
+	Fields []ManifestFieldDiff `json:"fields,omitempty"`
+}
+
+// ManifestFieldDiff is the difference of a field between the live resource and the manifest
+type ManifestFieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ManifestConflict is a difference that can not be applied
+type ManifestConflict struct {
+	Kind    ManifestKind `json:"kind"`
+	Key     string       `json:"key"`
+	Message string       `json:"message"`
+}
+
+// ExportManifestOption is the option to export the live system to a manifest
+type ExportManifestOption struct {
+	// ObjectIDs is the models to export, all the models are exported if it is empty
+	ObjectIDs []string `json:"bk_obj_ids"`
+	// BizNames is the businesses whose service templates, set templates and topology are exported
+	BizNames []string `json:"bk_biz_names"`
+}
+
+// PlanManifestOption is the option to plan the manifest against the live system
+type PlanManifestOption struct {
+	Manifest Manifest `json:"manifest"`
+}
+
+// ApplyManifestOption is the option to apply the manifest to the live system
+type ApplyManifestOption struct {
+	Manifest Manifest `json:"manifest"`
+	// Digest is the digest of the plan that the user has reviewed, the apply fails if the plan has changed
+	Digest string `json:"digest"`
+}
+
+// ExportManifestResp is the response of exporting a manifest
+type ExportManifestResp struct {
+	BaseResp `json:",inline"`
+	Data     *Manifest `json:"data"`
+}
+
+// ManifestPlanResp is the response of planning or applying a manifest
+type ManifestPlanResp struct {
+	BaseResp `json:",inline"`
+	Data     *ManifestPlan `json:"data"`
+}
+
+// Validate the plan manifest option
+func (o *PlanManifestOption) Validate() ccErr.RawErrorInfo {
+	return o.Manifest.Validate()
+}
+
+// Validate the apply manifest option
+func (o *ApplyManifestOption) Validate() ccErr.RawErrorInfo {
+	if o.Digest == "" {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"digest"},
+		}
+	}
+
+	return o.Manifest.Validate()
+}
@@ -31,6 +31,9 @@ type NetcollectDevice struct {
 	OwnerID     string     `json:"-" bson:"bk_supplier_account,omitempty"`
 	CreateTime  *time.Time `field:"create_time,omitempty" json:"create_time,omitempty" bson:"create_time,omitempty"`
 	LastTime    *time.Time `field:"last_time" json:"last_time,omitempty" bson:"last_time,omitempty"`
+	// SysObjectID is the snmp sysObjectID of the device model, the snmp discovered device is matched to the device
+	// model whose SysObjectID is the longest prefix of its sysObjectID
+	SysObjectID string `json:"sys_object_id,omitempty" bson:"sys_object_id,omitempty"`
 }
 
 // NetcollectProperty TODO
@@ -77,6 +80,8 @@ type Netcollector struct {
 	LatestVersion string             `json:"latest_ersion" bson:"latest_ersion"`
 	ReportTotal   int64              `json:"report_total" bson:"report_total"`
 	Config        NetcollectConfig   `json:"config" bson:"config"`
+	// LastDiscoverTime is the last time that the scan range of the collector is discovered with snmp
+	LastDiscoverTime *time.Time `json:"last_discover_time,omitempty" bson:"last_discover_time,omitempty"`
 }
 
 // ParamNetcollectDiscover TODO
@@ -109,6 +114,58 @@ type NetcollectConfig struct {
 	ScanRange []string `json:"scan_range" bson:"scan_range"`
 	Period    string   `json:"period" bson:"period"`
 	Community string   `json:"community" bson:"community"`
+	// SNMP is the snmp config used to actively discover the devices in the scan range
+	SNMP *NetcollectSNMPConfig `json:"snmp,omitempty" bson:"snmp,omitempty"`
+}
+
+// NetcollectSNMPConfig is the snmp config of the active discovery, the community of NetcollectConfig is used for
+// snmp v2c.
+type NetcollectSNMPConfig struct {
+	// Version is the snmp version, v2c or v3
+	Version string `json:"version" bson:"version"`
+	Port    int    `json:"port,omitempty" bson:"port,omitempty"`
+	// TimeoutSeconds is the timeout of each snmp request
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" bson:"timeout_seconds,omitempty"`
+	Retries        int `json:"retries,omitempty" bson:"retries,omitempty"`
+	// DiscoverPeriod is the period to discover the scan range automatically, like 24h, empty means only discover
+	// when it is triggered manually
+	DiscoverPeriod string `json:"discover_period,omitempty" bson:"discover_period,omitempty"`
+
+	// the user based security model params of snmp v3
+	UserName      string `json:"user_name,omitempty" bson:"user_name,omitempty"`
+	SecurityLevel string `json:"security_level,omitempty" bson:"security_level,omitempty"`
+	AuthProtocol  string `json:"auth_protocol,omitempty" bson:"auth_protocol,omitempty"`
+	AuthPassword  string `json:"auth_password,omitempty" bson:"auth_password,omitempty"`
+	PrivProtocol  string `json:"priv_protocol,omitempty" bson:"priv_protocol,omitempty"`
+	PrivPassword  string `json:"priv_password,omitempty" bson:"priv_password,omitempty"`
+}
+
+// ParamNetcollectSNMPDiscover is the param to discover the scan range of the netcollectors with snmp, all the
+// collectors with snmp config in the cloud area are discovered when the inner ip is not set
+type ParamNetcollectSNMPDiscover struct {
+	CloudID int64  `json:"bk_cloud_id"`
+	InnerIP string `json:"bk_host_innerip"`
+}
+
+// RspNetcollectSNMPDiscover is the result of the snmp discovery, the discovered devices and associations are saved
+// as netcollect reports to be confirmed
+type RspNetcollectSNMPDiscover struct {
+	Scanned      int `json:"scanned"`
+	Responded    int `json:"responded"`
+	Reports      int `json:"reports"`
+	Associations int `json:"associations"`
+	// Unmatched is the responded devices whose sysObjectID matches no device model
+	Unmatched []NetcollectUnmatchedDevice `json:"unmatched"`
+	Errors    []string                    `json:"errors"`
+}
+
+// NetcollectUnmatchedDevice is the snmp discovered device that matches no device model
+type NetcollectUnmatchedDevice struct {
+	CloudID     int64  `json:"bk_cloud_id"`
+	IP          string `json:"ip"`
+	SysObjectID string `json:"sys_object_id"`
+	SysName     string `json:"sys_name"`
+	SysDescr    string `json:"sys_descr"`
 }
 
 // ParamSearchNetcollectReport TODO
@@ -0,0 +1,227 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+)
+
+const (
+	validationRuleNameMaxLen    = 128
+	validationRuleMessageMaxLen = 256
+	// ValidationRuleMaxCount is the maximum number of validation rules that an object can have
+	ValidationRuleMaxCount = 50
+)
+
+// comparableOperators is the operators that can be used to compare two fields of an instance
+var comparableOperators = map[filter.OpFactory]struct{}{
+	filter.Equal.Factory():                  {},
+	filter.NotEqual.Factory():               {},
+	filter.Less.Factory():                   {},
+	filter.LessOrEqual.Factory():            {},
+	filter.Greater.Factory():                {},
+	filter.GreaterOrEqual.Factory():         {},
+	filter.DatetimeLess.Factory():           {},
+	filter.DatetimeLessOrEqual.Factory():    {},
+	filter.DatetimeGreater.Factory():        {},
+	filter.DatetimeGreaterOrEqual.Factory(): {},
+}
+
+// ObjectValidationRule is a cross-field validation rule of an object's instances
+type ObjectValidationRule struct {
+	ID                 int64  `json:"id" bson:"id"`
+	ObjID              string `json:"bk_obj_id" bson:"bk_obj_id"`
+	ValidationRuleSpec `json:",inline" bson:",inline"`
+	OwnerID            string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator            string `json:"creator" bson:"creator"`
+	Modifier           string `json:"modifier" bson:"modifier"`
+	CreateTime         Time   `json:"create_time" bson:"create_time"`
+	LastTime           Time   `json:"last_time" bson:"last_time"`
+}
+
+// ValidationRuleSpec is the user defined content of a validation rule, an instance that matches the condition
+// must set all the required fields, match the assertion and satisfy all the field comparisons.
+// e.g. "if bk_os_type=Linux then bk_os_version is required" can be defined as:
+// {"condition": {"field": "bk_os_type", "operator": "equal", "value": "1"}, "required": ["bk_os_version"]}
+type ValidationRuleSpec struct {
+	Name string `json:"name" bson:"name"`
+	// Condition defines the instances that the rule applies to, the rule applies to all instances if it is not set
+	Condition *filter.Expression `json:"condition,omitempty" bson:"condition,omitempty"`
+	// Required is the fields that must be set
+	Required []string `json:"required,omitempty" bson:"required,omitempty"`
+	// Assertion is the expression that the instance must match, e.g. a field's value must be in a sub-set of its enum
+	Assertion *filter.Expression `json:"assertion,omitempty" bson:"assertion,omitempty"`
+	// Comparisons is the comparisons between two fields, e.g. end_date must be greater than start_date
+	Comparisons []FieldComparison `json:"comparisons,omitempty" bson:"comparisons,omitempty"`
+	// Message is the description of the rule that is returned when the rule is violated, use name if it is not set
+	Message string `json:"message" bson:"message"`
+}
+
+// FieldComparison defines that the field's value compared with the target field's value must match the operator
+type FieldComparison struct {
+	Field       string           `json:"field" bson:"field"`
+	Operator    filter.OpFactory `json:"operator" bson:"operator"`
+	TargetField string           `json:"target_field" bson:"target_field"`
+}
+
+// Validate validation rule spec
+func (v *ValidationRuleSpec) Validate() ccErr.RawErrorInfo {
+	if len(v.Name) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"name"}}
+	}
+
+	if utf8.RuneCountInString(v.Name) > validationRuleNameMaxLen {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommValExceedMaxFailed,
+			Args: []interface{}{"name", validationRuleNameMaxLen}}
+	}
+
+	if utf8.RuneCountInString(v.Message) > validationRuleMessageMaxLen {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommValExceedMaxFailed,
+			Args: []interface{}{"message", validationRuleMessageMaxLen}}
+	}
+
+	if len(v.Required) == 0 && v.Assertion == nil && len(v.Comparisons) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+			Args: []interface{}{"required/assertion/comparisons"}}
+	}
+
+	opt := filter.NewDefaultExprOpt(nil)
+	opt.IgnoreRuleFields = true
+	if v.Condition != nil {
+		if err := v.Condition.Validate(opt); err != nil {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{fmt.Sprintf("condition, err: %v", err)}}
+		}
+	}
+
+	if v.Assertion != nil {
+		if err := v.Assertion.Validate(opt); err != nil {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{fmt.Sprintf("assertion, err: %v", err)}}
+		}
+	}
+
+	for _, field := range v.Required {
+		if len(field) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"required"}}
+		}
+	}
+
+	for _, comparison := range v.Comparisons {
+		if len(comparison.Field) == 0 || len(comparison.TargetField) == 0 {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet,
+				Args: []interface{}{"comparisons.field/target_field"}}
+		}
+
+		if _, exists := comparableOperators[comparison.Operator]; !exists {
+			return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{"comparisons.operator"}}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// Fields returns all the instance fields that the rule refers to
+func (v *ValidationRuleSpec) Fields() []string {
+	fields := make([]string, 0)
+	if v.Condition != nil {
+		fields = append(fields, v.Condition.RuleFields()...)
+	}
+	fields = append(fields, v.Required...)
+	if v.Assertion != nil {
+		fields = append(fields, v.Assertion.RuleFields()...)
+	}
+	for _, comparison := range v.Comparisons {
+		fields = append(fields, comparison.Field, comparison.TargetField)
+	}
+	return fields
+}
+
+// GetMessage returns the description of the rule that is returned when the rule is violated
+func (v *ValidationRuleSpec) GetMessage() string {
+	if len(v.Message) != 0 {
+		return v.Message
+	}
+	return v.Name
+}
+
+// CreateValidationRuleOption create object validation rule option
+type CreateValidationRuleOption struct {
+	ObjID              string `json:"bk_obj_id"`
+	ValidationRuleSpec `json:",inline"`
+}
+
+// Validate create object validation rule option
+func (c *CreateValidationRuleOption) Validate() ccErr.RawErrorInfo {
+	if len(c.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	return c.ValidationRuleSpec.Validate()
+}
+
+// UpdateValidationRuleOption update object validation rule option, the rule content is replaced as a whole
+type UpdateValidationRuleOption struct {
+	ObjID              string `json:"bk_obj_id"`
+	ID                 int64  `json:"id"`
+	ValidationRuleSpec `json:",inline"`
+}
+
+// Validate update object validation rule option
+func (u *UpdateValidationRuleOption) Validate() ccErr.RawErrorInfo {
+	if len(u.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if u.ID <= 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKFieldID}}
+	}
+
+	return u.ValidationRuleSpec.Validate()
+}
+
+// ListValidationRuleOption list object validation rules option
+type ListValidationRuleOption struct {
+	ObjID string  `json:"bk_obj_id"`
+	IDs   []int64 `json:"ids"`
+}
+
+// Validate list object validation rules option
+func (l *ListValidationRuleOption) Validate() ccErr.RawErrorInfo {
+	if len(l.ObjID) == 0 {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if len(l.IDs) > common.BKMaxPageSize {
+		return ccErr.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"ids", common.BKMaxPageSize}}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// ListValidationRuleResp list object validation rules response
+type ListValidationRuleResp struct {
+	BaseResp `json:",inline"`
+	Data     []ObjectValidationRule `json:"data"`
+}
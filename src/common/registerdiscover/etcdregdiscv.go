@@ -0,0 +1,233 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package registerdiscover
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common/backbone/service_mange/etcd"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/etcdclient"
+)
+
+// minLeaseTTL is the minimum ttl seconds of the register lease
+const minLeaseTTL = 5
+
+// EtcdRegDiscv do register and discover by etcd, the registered node is bound with a lease which is kept alive
+// by the register process, so that the node is deleted when the process is down. the registered nodes are sorted
+// by their create revision, so the first node is the master, the same as the sequential node of zookeeper.
+type EtcdRegDiscv struct {
+	etcdCli      *etcdclient.EtcdClient
+	cancel       context.CancelFunc
+	rootCxt      context.Context
+	leaseTTL     int64
+	leaseID      int64
+	registerPath string
+	sync.Mutex
+}
+
+// NewEtcdRegDiscv create a object of EtcdRegDiscv
+func NewEtcdRegDiscv(client *etcd.EtcdClient) *EtcdRegDiscv {
+	ctx, ctxCancel := client.WithCancel()
+	leaseTTL := int64(client.LeaseTTL() / time.Second)
+	if leaseTTL < minLeaseTTL {
+		leaseTTL = minLeaseTTL
+	}
+
+	return &EtcdRegDiscv{
+		etcdCli:  client.Client(),
+		cancel:   ctxCancel,
+		rootCxt:  ctx,
+		leaseTTL: leaseTTL,
+	}
+}
+
+// RegisterAndWatch create a node bound with lease for the service and keep the lease alive,
+// if the lease is expired, register again
+func (e *EtcdRegDiscv) RegisterAndWatch(path string, data []byte) error {
+	blog.Infof("register server and watch it. path(%s), data(%s)", path, string(data))
+	go func() {
+		interval := time.Duration(e.leaseTTL) * time.Second / 3
+		for {
+			if err := e.registerOrKeepAlive(path, data); err != nil {
+				blog.Errorf("fail to register server node(%s) into etcd, err: %v", path, err)
+			}
+
+			select {
+			case <-e.rootCxt.Done():
+				blog.Infof("watch register node(%s) done, now exist service register.", path)
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	blog.Infof("finish register server node(%s) and watch it", path)
+	return nil
+}
+
+func (e *EtcdRegDiscv) registerOrKeepAlive(path string, data []byte) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.registerPath != "" {
+		_, err := e.etcdCli.KeepAliveOnce(e.leaseID)
+		if err == nil {
+			return nil
+		}
+
+		if err != etcdclient.ErrLeaseNotFound {
+			return fmt.Errorf("keep alive lease %d failed, err: %v", e.leaseID, err)
+		}
+
+		// the lease is expired and the node is deleted, register a new one
+		blog.Errorf("lease of node %s is expired, try to register a new one", e.registerPath)
+		e.registerPath = ""
+		e.leaseID = 0
+	}
+
+	leaseID, err := e.etcdCli.Grant(e.leaseTTL)
+	if err != nil {
+		return fmt.Errorf("grant lease failed, err: %v", err)
+	}
+
+	// use lease id as the suffix to make sure the node is unique, like the sequential node of zookeeper
+	registerPath := fmt.Sprintf("%s_%016x", path, leaseID)
+	if err := e.etcdCli.PutWithLease(registerPath, string(data), leaseID); err != nil {
+		_ = e.etcdCli.Revoke(leaseID)
+		return fmt.Errorf("put node %s failed, err: %v", registerPath, err)
+	}
+
+	e.leaseID = leaseID
+	e.registerPath = registerPath
+	blog.Infof("register server node(%s) with lease %d success", registerPath, leaseID)
+	return nil
+}
+
+// GetServNodes get server nodes by path
+func (e *EtcdRegDiscv) GetServNodes(path string) ([]string, error) {
+	return e.etcdCli.GetChildren(path)
+}
+
+// Ping to ping server
+func (e *EtcdRegDiscv) Ping() error {
+	return e.etcdCli.Ping()
+}
+
+// Discover watch the children of the path
+func (e *EtcdRegDiscv) Discover(path string) (<-chan *DiscoverEvent, error) {
+	blog.Infof("begin to discover by watch children of path(%s)", path)
+
+	env := make(chan *DiscoverEvent, 1)
+	go e.loopDiscover(e.rootCxt, path, env)
+	return env, nil
+}
+
+func (e *EtcdRegDiscv) loopDiscover(discvCtx context.Context, path string, env chan *DiscoverEvent) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for {
+		result, err := e.etcdCli.Range(prefix, true)
+		if err != nil {
+			blog.Errorf("fail to get children of path(%s), will retry after 5s, err: %v", path, err)
+			if e.sleep(discvCtx, 5*time.Second) {
+				return
+			}
+			continue
+		}
+
+		discvEnv := &DiscoverEvent{
+			Key:   path,
+			Nodes: etcdclient.ChildrenOf(prefix, result.Kvs),
+		}
+		// the key values are sorted by create revision, so the first one is the master
+		for _, kv := range result.Kvs {
+			discvEnv.Server = append(discvEnv.Server, string(kv.Value))
+		}
+
+		select {
+		case env <- discvEnv:
+		case <-discvCtx.Done():
+			blog.Infof("discover path(%s) done", path)
+			return
+		}
+
+		// watch the changes after the revision of the range result, so that no change is missed
+		watchCtx, watchCancel := context.WithCancel(discvCtx)
+		watchCh, err := e.etcdCli.Watch(watchCtx, prefix, true, result.Header.Revision+1)
+		if err != nil {
+			watchCancel()
+			blog.Errorf("fail to watch children of path(%s), will retry after 5s, err: %v", path, err)
+			if e.sleep(discvCtx, 5*time.Second) {
+				return
+			}
+			continue
+		}
+
+		resp, ok := <-watchCh
+		watchCancel()
+		if discvCtx.Err() != nil {
+			blog.Infof("discover path(%s) done", path)
+			return
+		}
+
+		if !ok || resp.Err != nil {
+			blog.Errorf("watch children of path(%s) is broken, will retry after 1s, resp: %v", path, resp)
+			if e.sleep(discvCtx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		blog.V(4).Infof("watch found the children of path(%s) change, events: %d", path, len(resp.Events))
+	}
+}
+
+// sleep wait for the duration, returns true if the context is done
+func (e *EtcdRegDiscv) sleep(ctx context.Context, duration time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(duration):
+		return false
+	}
+}
+
+// Cancel to stop server register and discover
+func (e *EtcdRegDiscv) Cancel() {
+	e.cancel()
+}
+
+// ClearRegisterPath to revoke the register lease, the register node is deleted with it
+func (e *EtcdRegDiscv) ClearRegisterPath() error {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.leaseID == 0 {
+		return nil
+	}
+
+	if err := e.etcdCli.Revoke(e.leaseID); err != nil && err != etcdclient.ErrLeaseNotFound {
+		return err
+	}
+	e.leaseID = 0
+	e.registerPath = ""
+	return nil
+}
@@ -18,11 +18,42 @@
 package registerdiscover
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"configcenter/src/common/backbone/service_mange/etcd"
 	"configcenter/src/common/backbone/service_mange/zk"
 )
 
+const (
+	// RegDiscvTypeZk register and discover by zookeeper, it's the default type
+	RegDiscvTypeZk = "zk"
+	// RegDiscvTypeEtcd register and discover by etcd
+	RegDiscvTypeEtcd = "etcd"
+)
+
+// ParseRegDiscvAddress parse the regdiscv address into the register and discover type and its hosts,
+// e.g. etcd://127.0.0.1:2379,127.0.0.2:2379, the address without scheme is treated as zookeeper hosts.
+func ParseRegDiscvAddress(address string) (string, string, error) {
+	idx := strings.Index(address, "://")
+	if idx < 0 {
+		return RegDiscvTypeZk, address, nil
+	}
+
+	scheme, hosts := address[:idx], address[idx+len("://"):]
+	if hosts == "" {
+		return "", "", fmt.Errorf("regdiscv address %s has no host", address)
+	}
+
+	switch scheme {
+	case RegDiscvTypeZk, RegDiscvTypeEtcd:
+		return scheme, hosts, nil
+	default:
+		return "", "", fmt.Errorf("regdiscv address %s has unsupported scheme %s", address, scheme)
+	}
+}
+
 // DiscoverEvent if servers chenged, will create a discover event
 type DiscoverEvent struct { //
 	Err    error
@@ -58,6 +89,13 @@ func NewRegDiscoverEx(client *zk.ZkClient) *RegDiscover {
 	return regDiscv
 }
 
+// NewRegDiscoverWithEtcd used to create a object of RegDiscover which is based on etcd
+func NewRegDiscoverWithEtcd(client *etcd.EtcdClient) *RegDiscover {
+	return &RegDiscover{
+		rdServer: RegDiscvServer(NewEtcdRegDiscv(client)),
+	}
+}
+
 // RegisterAndWatchService register service info into register-discover platform
 // and then watch the service info, if not exist, then register again
 // key is the index of registered service
@@ -38,6 +38,39 @@ const (
 	// BKTableNameObjAttDes the table name of the object attribute
 	BKTableNameObjAttDes = "cc_ObjAttDes"
 
+	// BKTableNameObjValidationRule the table name of the object cross-field validation rule
+	BKTableNameObjValidationRule = "cc_ObjectValidationRule"
+
+	// BKTableNameObjLifecycle the table name of the object instance lifecycle state machine
+	BKTableNameObjLifecycle = "cc_ObjectLifecycle"
+
+	// BKTableNameDynamicGroupExportSchedule the table name of the dynamic group export schedule
+	BKTableNameDynamicGroupExportSchedule = "cc_DynamicGroupExportSchedule"
+
+	// BKTableNameDynamicGroupExportFile the table name of the dynamic group export file
+	BKTableNameDynamicGroupExportFile = "cc_DynamicGroupExportFile"
+
+	// BKTableNameDynamicGroupExportChunk the table name of the dynamic group export file content chunk
+	BKTableNameDynamicGroupExportChunk = "cc_DynamicGroupExportChunk"
+
+	// BKTableNameAuthRole the table name of the local authorizer's role
+	BKTableNameAuthRole = "cc_AuthRole"
+
+	// BKTableNameAuthRoleBinding the table name of the local authorizer's role binding
+	BKTableNameAuthRoleBinding = "cc_AuthRoleBinding"
+
+	// BKTableNameHostAttrHistory the table name of the collected host attribute history
+	BKTableNameHostAttrHistory = "cc_HostAttrHistory"
+
+	// BKTableNameHostAttrDrift the table name of the host attribute drift between collected and maintained value
+	BKTableNameHostAttrDrift = "cc_HostAttrDrift"
+
+	// BKTableNameHostApplyReconcileSetting the table name of the per-business host apply reconcile setting
+	BKTableNameHostApplyReconcileSetting = "cc_HostApplyReconcileSetting"
+
+	// BKTableNameHostApplyConflict the table name of the hosts drifted away from their host apply rules
+	BKTableNameHostApplyConflict = "cc_HostApplyConflict"
+
 	// BKTableNameObjClassification the table name of the object classification
 	BKTableNameObjClassification = "cc_ObjClassification"
 
@@ -127,6 +160,9 @@ const (
 
 	// BKTableNameObjFieldTemplateRelation  object and field template relationship table
 	BKTableNameObjFieldTemplateRelation = "cc_ObjFieldTemplateRelation"
+
+	// BKTableNameFieldTemplateVersion  field template immutable version snapshot table
+	BKTableNameFieldTemplateVersion = "cc_FieldTemplateVersion"
 )
 
 // AllTables is all table names, not include the sharding tables which is created dynamically,
@@ -151,6 +187,15 @@ var AllTables = []string{
 	BKTableNameAuditLog,
 	BKTableNameUserAPI,
 	BKTableNameDynamicGroup,
+	BKTableNameDynamicGroupExportSchedule,
+	BKTableNameDynamicGroupExportFile,
+	BKTableNameDynamicGroupExportChunk,
+	BKTableNameHostAttrHistory,
+	BKTableNameHostAttrDrift,
+	BKTableNameHostApplyReconcileSetting,
+	BKTableNameHostApplyConflict,
+	BKTableNameAuthRole,
+	BKTableNameAuthRoleBinding,
 	BKTableNameUserCustom,
 	BKTableNameObjAsst,
 	BKTableNameTopoGraphics,
@@ -163,6 +208,8 @@ var AllTables = []string{
 	BKTableNameIDgenerator,
 	BKTableNameHostLock,
 	BKTableNameObjUnique,
+	BKTableNameObjValidationRule,
+	BKTableNameObjLifecycle,
 	BKTableNameAsstDes,
 	BKTableNameServiceCategory,
 	BKTableNameServiceTemplate,
@@ -43,22 +43,26 @@ var delArchiveCollMap = map[string]string{
 
 	common.BKTableNameServiceInstance: common.BKTableNameDelArchive,
 
-	kubetypes.BKTableNameBaseCluster:        common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseNode:           common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseNamespace:      common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseWorkload:       common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseDeployment:     common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseStatefulSet:    common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseDaemonSet:      common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameGameDeployment:     common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameGameStatefulSet:    common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseCronJob:        common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseJob:            common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBasePodWorkload:    common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseCustom:         common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBasePod:            common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameBaseContainer:      common.BKTableNameKubeDelArchive,
-	kubetypes.BKTableNameNsSharedClusterRel: common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseCluster:               common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseNode:                  common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseNamespace:             common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseWorkload:              common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseDeployment:            common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseStatefulSet:           common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseDaemonSet:             common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameGameDeployment:            common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameGameStatefulSet:           common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseCronJob:               common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseJob:                   common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBasePodWorkload:           common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseCustom:                common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBasePod:                   common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseContainer:             common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameNsSharedClusterRel:        common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseService:               common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseIngress:               common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBaseConfigMap:             common.BKTableNameKubeDelArchive,
+	kubetypes.BKTableNameBasePersistentVolumeClaim: common.BKTableNameKubeDelArchive,
 }
 
 // GetDelArchiveTable get delete archive table
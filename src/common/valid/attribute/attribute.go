@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"unicode/utf8"
 
+	"configcenter/pkg/formula"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
@@ -56,6 +57,13 @@ func ValidPropertyOption(kit *rest.Kit, propertyType string, option interface{},
 			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "option")
 		}
 		return ValidIDRuleOption(kit, option, attrTypeMap)
+	case common.FieldTypeComputed:
+		attrTypeMap, ok := extraOpt.(map[string]string)
+		if !ok {
+			blog.Errorf("extra opt(%+v) type %T is invalid, rid: %s", extraOpt, extraOpt, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "option")
+		}
+		return ValidComputedOption(kit, option, attrTypeMap)
 	}
 
 	return nil
@@ -367,3 +375,44 @@ func ValidIDRuleOption(kit *rest.Kit, val interface{}, attrTypeMap map[string]st
 
 	return nil
 }
+
+// ValidComputedOption validate computed field type's option, attrTypeMap is the property id to type map of the
+// object's attributes. the fields of the aggregated instances are validated by the caller.
+func ValidComputedOption(kit *rest.Kit, val interface{}, attrTypeMap map[string]string) error {
+	option, err := metadata.ParseComputedOption(val)
+	if err != nil {
+		blog.Errorf("parse computed option %+v failed, err: %v, rid: %s", val, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "option")
+	}
+
+	if err = option.Validate(); err != nil {
+		blog.Errorf("computed option %+v is invalid, err: %v, rid: %s", option, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
+	if option.Source != metadata.ComputedSourceExpression {
+		return nil
+	}
+
+	f, err := formula.Parse(option.Expression)
+	if err != nil {
+		blog.Errorf("parse computed expression %s failed, err: %v, rid: %s", option.Expression, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
+	// expression can only reference the numeric fields that are written by user, so that the computed fields
+	// do not depend on each other and can be calculated in any order
+	for _, field := range f.Fields() {
+		attrType, exists := attrTypeMap[field]
+		if !exists {
+			blog.Errorf("expression field %s is invalid, attribute not exists, rid: %s", field, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "option")
+		}
+		if attrType != common.FieldTypeInt && attrType != common.FieldTypeFloat {
+			blog.Errorf("expression field %s type %s is not numeric, rid: %s", field, attrType, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "option")
+		}
+	}
+
+	return nil
+}
@@ -106,7 +106,7 @@ func TestValidPropertyOption(t *testing.T) {
 			Type:      "text",
 			IsDefault: true,
 		}}, true, []string{"c", "b"}}, false},
-		{"int", args{common.FieldTypeInt, metadata.PrevIntOption{
+		{"int", args{common.FieldTypeInt, metadata.IntOption{
 			Min: 1,
 			Max: 100,
 		}, false, 1}, false},
@@ -129,7 +129,7 @@ func TestValidPropertyOption(t *testing.T) {
 			Type:      "aaa",
 			IsDefault: false,
 		}}, false, nil}, true},
-		{"int", args{common.FieldTypeInt, metadata.PrevIntOption{
+		{"int", args{common.FieldTypeInt, metadata.IntOption{
 			Min: 101,
 			Max: 100,
 		}, false, 100}, true},
@@ -146,8 +146,11 @@ func TestValidPropertyOption(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidPropertyOption(kit, tt.args.propertyType, tt.args.option, tt.args.isMultiple,
-				tt.args.defaultVal)
+			extraOpt := tt.args.defaultVal
+			if tt.args.propertyType == common.FieldTypeEnum || tt.args.propertyType == common.FieldTypeEnumMulti {
+				extraOpt = &tt.args.isMultiple
+			}
+			err := ValidPropertyOption(kit, tt.args.propertyType, tt.args.option, extraOpt)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidPropertyOption() error = %v, wantErr %v", err, tt.wantErr)
 			}
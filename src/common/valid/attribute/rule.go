@@ -0,0 +1,175 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package attrvalid
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// ValidValidationRule validate that all the fields of the validation rule exist in the object's attributes,
+// attrTypeMap is the property id to type map of the object's attributes.
+func ValidValidationRule(kit *rest.Kit, rule *metadata.ValidationRuleSpec, attrTypeMap map[string]string) error {
+	for _, field := range rule.Fields() {
+		if _, exists := attrTypeMap[field]; !exists {
+			blog.Errorf("validation rule %s field %s is invalid, attribute not exists, rid: %s", rule.Name, field,
+				kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, field)
+		}
+	}
+
+	return nil
+}
+
+// CheckValidationRules check if the instance data satisfies all the validation rules of its object,
+// returns the error of every field that violates a rule, or the error of the first malformed rule.
+func CheckValidationRules(kit *rest.Kit, data mapstr.MapStr, rules []metadata.ObjectValidationRule) error {
+	violations := make([]errors.CCErrorCoder, 0)
+	for _, rule := range rules {
+		fields, err := checkValidationRule(data, &rule.ValidationRuleSpec)
+		if err != nil {
+			blog.Errorf("validation rule %d(%s) is malformed, err: %v, data: %+v, rid: %s", rule.ID, rule.Name, err,
+				data, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommValidationRuleInvalid, rule.Name, err.Error())
+		}
+
+		for _, field := range fields {
+			blog.Errorf("instance field %s violates validation rule %d(%s), data: %+v, rid: %s", field, rule.ID,
+				rule.Name, data, kit.Rid)
+			violations = append(violations, kit.CCError.CCErrorf(common.CCErrCommValidationRuleFailed, field,
+				rule.GetMessage()))
+		}
+	}
+
+	switch len(violations) {
+	case 0:
+		return nil
+	case 1:
+		return violations[0]
+	}
+
+	msgs := make([]string, len(violations))
+	for idx, violation := range violations {
+		msgs[idx] = violation.Error()
+	}
+	return errors.NewCCError(common.CCErrCommValidationRuleFailed, strings.Join(msgs, "; "))
+}
+
+// checkValidationRule check if the instance data satisfies the validation rule, returns all the violated fields.
+// the error is returned when the rule can not be matched with the data, which means that the rule is malformed,
+// e.g. its value type does not match the field type.
+func checkValidationRule(data mapstr.MapStr, rule *metadata.ValidationRuleSpec) ([]string, error) {
+	matchData := filter.MapStr(data)
+
+	if rule.Condition != nil {
+		matched, err := rule.Condition.Match(matchData)
+		if err != nil {
+			return nil, fmt.Errorf("match condition failed, err: %v", err)
+		}
+		if !matched {
+			return nil, nil
+		}
+	}
+
+	violated := make([]string, 0)
+	for _, field := range rule.Required {
+		if IsEmptyValue(data[field]) {
+			violated = append(violated, field)
+		}
+	}
+
+	if rule.Assertion != nil {
+		matched, err := rule.Assertion.Match(matchData)
+		if err != nil {
+			return nil, fmt.Errorf("match assertion failed, err: %v", err)
+		}
+		if !matched {
+			violated = append(violated, strings.Join(rule.Assertion.RuleFields(), ","))
+		}
+	}
+
+	for _, comparison := range rule.Comparisons {
+		value, target := data[comparison.Field], data[comparison.TargetField]
+		// fields that are not set are checked by the required fields, so comparisons only applies to set fields
+		if IsEmptyValue(value) || IsEmptyValue(target) {
+			continue
+		}
+
+		if isDatetimeOperator(comparison.Operator) {
+			value, target = parseDateValue(value), parseDateValue(target)
+		}
+
+		matched, err := comparison.Operator.Operator().Match(value, target)
+		if err != nil {
+			return nil, fmt.Errorf("compare field %s with %s failed, err: %v", comparison.Field,
+				comparison.TargetField, err)
+		}
+		if !matched {
+			violated = append(violated, comparison.Field)
+		}
+	}
+
+	return violated, nil
+}
+
+func isDatetimeOperator(op filter.OpFactory) bool {
+	switch op {
+	case filter.DatetimeLess.Factory(), filter.DatetimeLessOrEqual.Factory(), filter.DatetimeGreater.Factory(),
+		filter.DatetimeGreaterOrEqual.Factory():
+		return true
+	}
+	return false
+}
+
+// parseDateValue parse the value of date type field to time, because datetime operators only support time values
+func parseDateValue(val interface{}) interface{} {
+	strVal, ok := val.(string)
+	if !ok {
+		return val
+	}
+
+	date, err := time.ParseInLocation(common.TimeDayTransferModel, strVal, time.Local)
+	if err != nil {
+		return val
+	}
+	return date
+}
+
+// IsEmptyValue checks if the instance field value is not set
+func IsEmptyValue(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+
+	switch value := reflect.ValueOf(val); value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package attrvalid
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func TestCheckValidationRules(t *testing.T) {
+	kit := rest.NewKitFromHeader(http.Header{}, errif{})
+	rules := []metadata.ObjectValidationRule{
+		{
+			ID: 1,
+			ValidationRuleSpec: metadata.ValidationRuleSpec{
+				Name: "linux os version",
+				Condition: &filter.Expression{RuleFactory: &filter.AtomRule{Field: "bk_os_type",
+					Operator: filter.Equal.Factory(), Value: "1"}},
+				Required: []string{"bk_os_version"},
+			},
+		},
+		{
+			ID: 2,
+			ValidationRuleSpec: metadata.ValidationRuleSpec{
+				Name: "date range",
+				Comparisons: []metadata.FieldComparison{{Field: "end_date",
+					Operator: filter.DatetimeGreater.Factory(), TargetField: "start_date"}},
+			},
+		},
+		{
+			ID: 3,
+			ValidationRuleSpec: metadata.ValidationRuleSpec{
+				Name: "level subset",
+				Condition: &filter.Expression{RuleFactory: &filter.AtomRule{Field: "env",
+					Operator: filter.Equal.Factory(), Value: "prod"}},
+				Assertion: &filter.Expression{RuleFactory: &filter.AtomRule{Field: "level",
+					Operator: filter.In.Factory(), Value: []string{"high", "medium"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		data       mapstr.MapStr
+		wantFields []string
+	}{
+		{
+			name: "all rules satisfied",
+			data: mapstr.MapStr{"bk_os_type": "1", "bk_os_version": "7.9", "start_date": "2024-01-01",
+				"end_date": "2024-02-01", "env": "prod", "level": "high"},
+		},
+		{
+			name: "condition not matched",
+			data: mapstr.MapStr{"bk_os_type": "2", "env": "test", "level": "low"},
+		},
+		{
+			name:       "required field not set",
+			data:       mapstr.MapStr{"bk_os_type": "1", "bk_os_version": ""},
+			wantFields: []string{"bk_os_version"},
+		},
+		{
+			name:       "comparison not satisfied",
+			data:       mapstr.MapStr{"start_date": "2024-02-01", "end_date": "2024-01-01"},
+			wantFields: []string{"end_date"},
+		},
+		{
+			name: "comparison field not set",
+			data: mapstr.MapStr{"start_date": "2024-02-01"},
+		},
+		{
+			name:       "assertion not matched",
+			data:       mapstr.MapStr{"env": "prod", "level": "low"},
+			wantFields: []string{"level"},
+		},
+		{
+			name: "multiple rules violated",
+			data: mapstr.MapStr{"bk_os_type": "1", "start_date": "2024-02-01", "end_date": "2024-01-01",
+				"env": "prod", "level": "low"},
+			wantFields: []string{"bk_os_version", "end_date", "level"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckValidationRules(kit, tt.data, rules)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("CheckValidationRules() error = %v, want no error", err)
+				}
+				return
+			}
+
+			ccErr, ok := err.(errors.CCErrorCoder)
+			if !ok || ccErr.GetCode() != common.CCErrCommValidationRuleFailed {
+				t.Fatalf("CheckValidationRules() error = %v, want validation rule failed error", err)
+			}
+			for _, field := range tt.wantFields {
+				if !strings.Contains(ccErr.Error(), "["+field+" ") {
+					t.Errorf("CheckValidationRules() error = %v, want violated field %s", err, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMalformedValidationRules(t *testing.T) {
+	kit := rest.NewKitFromHeader(http.Header{}, errif{})
+	tests := []struct {
+		name string
+		rule metadata.ValidationRuleSpec
+	}{
+		{
+			name: "condition value type not match",
+			rule: metadata.ValidationRuleSpec{
+				Name: "condition",
+				Condition: &filter.Expression{RuleFactory: &filter.AtomRule{Field: "bk_os_type",
+					Operator: filter.Equal.Factory(), Value: true}},
+				Required: []string{"bk_os_version"},
+			},
+		},
+		{
+			name: "assertion value is not array",
+			rule: metadata.ValidationRuleSpec{
+				Name: "assertion",
+				Assertion: &filter.Expression{RuleFactory: &filter.AtomRule{Field: "level",
+					Operator: filter.In.Factory(), Value: "high"}},
+			},
+		},
+		{
+			name: "comparison operator not applicable",
+			rule: metadata.ValidationRuleSpec{
+				Name: "comparison",
+				Comparisons: []metadata.FieldComparison{{Field: "level", Operator: filter.In.Factory(),
+					TargetField: "bk_os_type"}},
+			},
+		},
+	}
+
+	data := mapstr.MapStr{"bk_os_type": "1", "bk_os_version": "7.9", "level": "high"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []metadata.ObjectValidationRule{{ID: 1, ValidationRuleSpec: tt.rule}}
+			err := CheckValidationRules(kit, data, rules)
+			ccErr, ok := err.(errors.CCErrorCoder)
+			if !ok || ccErr.GetCode() != common.CCErrCommValidationRuleInvalid {
+				t.Errorf("CheckValidationRules() error = %v, want malformed validation rule error", err)
+			}
+		})
+	}
+}
@@ -62,27 +62,31 @@ func init() {
 
 var (
 	cursorTypeIntMap = map[CursorType]int{
-		NoEvent:                 1,
-		Host:                    2,
-		ModuleHostRelation:      3,
-		Biz:                     4,
-		Set:                     5,
-		Module:                  6,
-		ObjectBase:              8,
-		Process:                 9,
-		ProcessInstanceRelation: 10,
-		HostIdentifier:          11,
-		MainlineInstance:        12,
-		InstAsst:                13,
-		BizSet:                  14,
-		BizSetRelation:          15,
-		Plat:                    16,
-		KubeCluster:             17,
-		KubeNode:                18,
-		KubeNamespace:           19,
-		KubeWorkload:            20,
-		KubePod:                 21,
-		Project:                 22,
+		NoEvent:                   1,
+		Host:                      2,
+		ModuleHostRelation:        3,
+		Biz:                       4,
+		Set:                       5,
+		Module:                    6,
+		ObjectBase:                8,
+		Process:                   9,
+		ProcessInstanceRelation:   10,
+		HostIdentifier:            11,
+		MainlineInstance:          12,
+		InstAsst:                  13,
+		BizSet:                    14,
+		BizSetRelation:            15,
+		Plat:                      16,
+		KubeCluster:               17,
+		KubeNode:                  18,
+		KubeNamespace:             19,
+		KubeWorkload:              20,
+		KubePod:                   21,
+		Project:                   22,
+		KubeService:               23,
+		KubeIngress:               24,
+		KubeConfigMap:             25,
+		KubePersistentVolumeClaim: 26,
 	}
 
 	intCursorTypeMap = make(map[int]CursorType)
@@ -139,6 +143,14 @@ const (
 	KubeWorkload CursorType = "kube_workload"
 	// KubePod cursor type, its event detail is pod info with containers in it
 	KubePod CursorType = "kube_pod"
+	// KubeService kube service cursor type
+	KubeService CursorType = "kube_service"
+	// KubeIngress kube ingress cursor type
+	KubeIngress CursorType = "kube_ingress"
+	// KubeConfigMap kube configMap cursor type
+	KubeConfigMap CursorType = "kube_config_map"
+	// KubePersistentVolumeClaim kube persistentVolumeClaim cursor type
+	KubePersistentVolumeClaim CursorType = "kube_persistent_volume_claim"
 )
 
 // ToInt TODO
@@ -166,7 +178,7 @@ func (ct *CursorType) ParseInt(typ int) {
 func ListCursorTypes() []CursorType {
 	return []CursorType{Host, ModuleHostRelation, Biz, Set, Module, ObjectBase, Process, ProcessInstanceRelation,
 		HostIdentifier, MainlineInstance, InstAsst, BizSet, BizSetRelation, Plat, KubeCluster, KubeNode, KubeNamespace,
-		KubeWorkload, KubePod, Project}
+		KubeWorkload, KubePod, Project, KubeService, KubeIngress, KubeConfigMap, KubePersistentVolumeClaim}
 }
 
 // Cursor is a self-defined token which is corresponding to the mongodb's resume token.
@@ -331,24 +343,28 @@ func (c *Cursor) Decode(cur string) error {
 }
 
 var collEventCursorTypeMap = map[string]CursorType{
-	common.BKTableNameBaseHost:                Host,
-	common.BKTableNameModuleHostConfig:        ModuleHostRelation,
-	common.BKTableNameBaseApp:                 Biz,
-	common.BKTableNameBaseSet:                 Set,
-	common.BKTableNameBaseModule:              Module,
-	common.BKTableNameBaseInst:                ObjectBase,
-	common.BKTableNameMainlineInstance:        MainlineInstance,
-	common.BKTableNameBaseProcess:             Process,
-	common.BKTableNameProcessInstanceRelation: ProcessInstanceRelation,
-	common.BKTableNameInstAsst:                InstAsst,
-	common.BKTableNameBaseBizSet:              BizSet,
-	common.BKTableNameBasePlat:                Plat,
-	kubetypes.BKTableNameBaseCluster:          KubeCluster,
-	kubetypes.BKTableNameBaseNode:             KubeNode,
-	kubetypes.BKTableNameBaseNamespace:        KubeNamespace,
-	kubetypes.BKTableNameBaseWorkload:         KubeWorkload,
-	kubetypes.BKTableNameBasePod:              KubePod,
-	common.BKTableNameBaseProject:             Project,
+	common.BKTableNameBaseHost:                     Host,
+	common.BKTableNameModuleHostConfig:             ModuleHostRelation,
+	common.BKTableNameBaseApp:                      Biz,
+	common.BKTableNameBaseSet:                      Set,
+	common.BKTableNameBaseModule:                   Module,
+	common.BKTableNameBaseInst:                     ObjectBase,
+	common.BKTableNameMainlineInstance:             MainlineInstance,
+	common.BKTableNameBaseProcess:                  Process,
+	common.BKTableNameProcessInstanceRelation:      ProcessInstanceRelation,
+	common.BKTableNameInstAsst:                     InstAsst,
+	common.BKTableNameBaseBizSet:                   BizSet,
+	common.BKTableNameBasePlat:                     Plat,
+	kubetypes.BKTableNameBaseCluster:               KubeCluster,
+	kubetypes.BKTableNameBaseNode:                  KubeNode,
+	kubetypes.BKTableNameBaseNamespace:             KubeNamespace,
+	kubetypes.BKTableNameBaseWorkload:              KubeWorkload,
+	kubetypes.BKTableNameBasePod:                   KubePod,
+	common.BKTableNameBaseProject:                  Project,
+	kubetypes.BKTableNameBaseService:               KubeService,
+	kubetypes.BKTableNameBaseIngress:               KubeIngress,
+	kubetypes.BKTableNameBaseConfigMap:             KubeConfigMap,
+	kubetypes.BKTableNameBasePersistentVolumeClaim: KubePersistentVolumeClaim,
 }
 
 // GetEventCursor get event cursor.
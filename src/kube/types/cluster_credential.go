@@ -0,0 +1,199 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/table"
+)
+
+// cluster credential field names
+const (
+	// KubeConfigField cluster credential kubeconfig field
+	KubeConfigField = "kubeconfig"
+
+	// KubeContextField cluster credential kubeconfig context field
+	KubeContextField = "context"
+
+	// NsBizLabelField cluster credential namespace business label key field
+	NsBizLabelField = "ns_biz_label"
+
+	// EnabledField cluster credential enabled field
+	EnabledField = "enabled"
+
+	// SyncStatusField cluster credential sync status field
+	SyncStatusField = "sync_status"
+)
+
+const (
+	// CredentialSearchLimit the max limit of searching cluster credentials
+	CredentialSearchLimit = 200
+
+	// CredentialDeleteLimit the max limit of deleting cluster credentials
+	CredentialDeleteLimit = 100
+)
+
+// CollectState the collecting state of a registered cluster
+type CollectState string
+
+const (
+	// CollectStateSyncing the cluster is listing resources and has not been reconciled yet
+	CollectStateSyncing CollectState = "syncing"
+
+	// CollectStateSuccess the latest reconciliation of the cluster succeeded
+	CollectStateSuccess CollectState = "success"
+
+	// CollectStateFailed the latest reconciliation of the cluster failed
+	CollectStateFailed CollectState = "failed"
+)
+
+// ClusterCredential the credential of a cluster registered to be collected by datacollection
+type ClusterCredential struct {
+	ID        int64 `json:"id" bson:"id"`
+	BizID     int64 `json:"bk_biz_id" bson:"bk_biz_id"`
+	ClusterID int64 `json:"bk_cluster_id" bson:"bk_cluster_id"`
+	// KubeConfig the kubeconfig used to access the cluster, it is encrypted when crypto is enabled,
+	// and is never returned by the search api
+	KubeConfig string `json:"kubeconfig,omitempty" bson:"kubeconfig"`
+	// Context the kubeconfig context to use, current context is used if it is not set
+	Context string `json:"context" bson:"context"`
+	// NsBizLabel the label key of namespace whose value is the business id the namespace belongs to,
+	// namespaces without this label belong to the cluster's business
+	NsBizLabel      string         `json:"ns_biz_label" bson:"ns_biz_label"`
+	Enabled         bool           `json:"enabled" bson:"enabled"`
+	SyncStatus      *CollectStatus `json:"sync_status,omitempty" bson:"sync_status"`
+	SupplierAccount string         `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	table.Revision  `json:",inline" bson:",inline"`
+}
+
+// CollectStatus the collecting status of a registered cluster
+type CollectStatus struct {
+	State        CollectState `json:"state" bson:"state"`
+	LastSyncTime int64        `json:"last_sync_time" bson:"last_sync_time"`
+	Message      string       `json:"message" bson:"message"`
+	// Conflicts the namespaces whose business in cc differs from the one in the cluster, they are left unchanged
+	Conflicts []NsBizConflict `json:"conflicts" bson:"conflicts"`
+	// UnmatchedNodes the nodes that can not be matched to a host of the cluster's business by internal ip
+	UnmatchedNodes []string `json:"unmatched_nodes" bson:"unmatched_nodes"`
+}
+
+// NsBizConflict the namespace whose business in cc differs from the one derived from the cluster
+type NsBizConflict struct {
+	Namespace   string `json:"namespace" bson:"namespace"`
+	BizID       int64  `json:"bk_biz_id" bson:"bk_biz_id"`
+	ExpectBizID int64  `json:"expect_biz_id" bson:"expect_biz_id"`
+	Reason      string `json:"reason" bson:"reason"`
+}
+
+// RegisterCredentialOption register or update the credential of a cluster request
+type RegisterCredentialOption struct {
+	BizID      int64  `json:"bk_biz_id"`
+	ClusterID  int64  `json:"bk_cluster_id"`
+	KubeConfig string `json:"kubeconfig"`
+	Context    string `json:"context"`
+	NsBizLabel string `json:"ns_biz_label"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+// Validate validate RegisterCredentialOption
+func (r *RegisterCredentialOption) Validate() errors.RawErrorInfo {
+	if r.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if r.ClusterID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{BKClusterIDFiled},
+		}
+	}
+
+	if r.KubeConfig == "" {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{KubeConfigField},
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// SearchCredentialOption search cluster credentials request
+type SearchCredentialOption struct {
+	BizID      int64             `json:"bk_biz_id"`
+	ClusterIDs []int64           `json:"bk_cluster_ids"`
+	Page       metadata.BasePage `json:"page"`
+}
+
+// Validate validate SearchCredentialOption
+func (s *SearchCredentialOption) Validate() errors.RawErrorInfo {
+	if s.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if err := s.Page.ValidateWithEnableCount(false, CredentialSearchLimit); err.ErrCode != 0 {
+		return err
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// SearchCredentialResult search cluster credentials result
+type SearchCredentialResult struct {
+	Count int64               `json:"count"`
+	Info  []ClusterCredential `json:"info"`
+}
+
+// DeleteCredentialOption delete cluster credentials request
+type DeleteCredentialOption struct {
+	BizID      int64   `json:"bk_biz_id"`
+	ClusterIDs []int64 `json:"bk_cluster_ids"`
+}
+
+// Validate validate DeleteCredentialOption
+func (d *DeleteCredentialOption) Validate() errors.RawErrorInfo {
+	if d.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if len(d.ClusterIDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"bk_cluster_ids"},
+		}
+	}
+
+	if len(d.ClusterIDs) > CredentialDeleteLimit {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"bk_cluster_ids", CredentialDeleteLimit},
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
@@ -0,0 +1,220 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// IngressFields merge the fields of the Ingress and the details corresponding to the fields together.
+var IngressFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, IngressSpecFieldsDescriptor)
+
+// IngressSpecFieldsDescriptor Ingress spec's fields descriptors.
+var IngressSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: IngressClassNameField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: DefaultBackendField, Type: enumor.Object, IsRequired: false, IsEditable: true},
+	{Field: RulesField, Type: enumor.Object, IsRequired: false, IsEditable: true},
+	{Field: TLSField, Type: enumor.Object, IsRequired: false, IsEditable: true},
+}
+
+// PathType represents the type of path referred to by a HTTPIngressPath.
+type PathType string
+
+const (
+	// PathTypeExact matches the URL path exactly and with case sensitivity.
+	PathTypeExact PathType = "Exact"
+
+	// PathTypePrefix matches based on a URL path prefix split by '/'.
+	PathTypePrefix PathType = "Prefix"
+
+	// PathTypeImplementationSpecific matching is up to the IngressClass.
+	PathTypeImplementationSpecific PathType = "ImplementationSpecific"
+)
+
+// ServiceBackendPort is the service port being referenced, only one of name and number can be set.
+type ServiceBackendPort struct {
+	// Name is the name of the port on the Service.
+	Name string `json:"name,omitempty" bson:"name"`
+	// Number is the numerical port number (e.g. 80) on the Service.
+	Number int32 `json:"number,omitempty" bson:"number"`
+}
+
+// IngressServiceBackend references a Kubernetes Service as a Backend.
+type IngressServiceBackend struct {
+	// Name is the referenced service. The service must exist in the same namespace as the Ingress object.
+	Name string `json:"name" bson:"name"`
+	// Port of the referenced service.
+	Port ServiceBackendPort `json:"port" bson:"port"`
+}
+
+// IngressBackend describes all endpoints for a given service and port.
+type IngressBackend struct {
+	// Service references a Service as a Backend.
+	Service *IngressServiceBackend `json:"service,omitempty" bson:"service"`
+}
+
+// HTTPIngressPath associates a path with a backend. Incoming urls matching the path are forwarded to the backend.
+type HTTPIngressPath struct {
+	// Path is matched against the path of an incoming request.
+	Path string `json:"path,omitempty" bson:"path"`
+	// PathType determines the interpretation of the Path matching.
+	PathType *PathType `json:"path_type,omitempty" bson:"path_type"`
+	// Backend defines the referenced service endpoint to which the traffic will be forwarded to.
+	Backend IngressBackend `json:"backend" bson:"backend"`
+}
+
+// IngressRule represents the rules mapping the paths under a specified host to the related backend services.
+type IngressRule struct {
+	// Host is the fully qualified domain name of a network host, empty host matches all hosts.
+	Host string `json:"host,omitempty" bson:"host"`
+	// Paths is a collection of paths that map requests to backends.
+	Paths []HTTPIngressPath `json:"paths,omitempty" bson:"paths"`
+}
+
+// IngressTLS describes the transport layer security associated with an Ingress.
+type IngressTLS struct {
+	// Hosts are a list of hosts included in the TLS certificate.
+	Hosts []string `json:"hosts,omitempty" bson:"hosts"`
+	// SecretName is the name of the secret used to terminate TLS traffic on port 443.
+	SecretName string `json:"secret_name,omitempty" bson:"secret_name"`
+}
+
+// Ingress define the ingress struct.
+type Ingress struct {
+	NsResourceBase   `json:",inline" bson:",inline"`
+	Labels           *map[string]string `json:"labels,omitempty" bson:"labels"`
+	IngressClassName *string            `json:"ingress_class_name,omitempty" bson:"ingress_class_name"`
+	DefaultBackend   *IngressBackend    `json:"default_backend,omitempty" bson:"default_backend"`
+	Rules            *[]IngressRule     `json:"rules,omitempty" bson:"rules"`
+	TLS              *[]IngressTLS      `json:"tls,omitempty" bson:"tls"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (i *Ingress) GetNsResourceBase() NsResourceBase {
+	return i.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (i *Ingress) SetNsResourceBase(base NsResourceBase) {
+	i.NsResourceBase = base
+}
+
+// ValidateCreate validate create ingress
+func (i *Ingress) ValidateCreate() ccErr.RawErrorInfo {
+	if i == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := validateNsResCreate(*i, i.NsResourceBase, IngressFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return i.validateSpec()
+}
+
+// ValidateUpdate validate update ingress
+func (i *Ingress) ValidateUpdate() ccErr.RawErrorInfo {
+	if i == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := ValidateUpdate(*i, IngressFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return i.validateSpec()
+}
+
+func (i *Ingress) validateSpec() ccErr.RawErrorInfo {
+	if i.DefaultBackend != nil {
+		if err := ValidateIngressBackend(*i.DefaultBackend); err != nil {
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{DefaultBackendField},
+			}
+		}
+	}
+
+	if i.Rules != nil {
+		if err := ValidateIngressRules(*i.Rules); err != nil {
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{RulesField},
+			}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// BuildUpdateData build ingress update data
+func (i *Ingress) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if i == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResUpdateData(i, user)
+}
+
+// BackendServices returns the names of the services that the ingress routes traffic to, including the services
+// referenced by its default backend and by every rule path.
+func (i *Ingress) BackendServices() []string {
+	if i == nil {
+		return make([]string, 0)
+	}
+
+	names := make([]string, 0)
+	exists := make(map[string]struct{})
+	addBackend := func(backend IngressBackend) {
+		if backend.Service == nil || backend.Service.Name == "" {
+			return
+		}
+		if _, ok := exists[backend.Service.Name]; ok {
+			return
+		}
+		exists[backend.Service.Name] = struct{}{}
+		names = append(names, backend.Service.Name)
+	}
+
+	if i.DefaultBackend != nil {
+		addBackend(*i.DefaultBackend)
+	}
+
+	if i.Rules != nil {
+		for _, rule := range *i.Rules {
+			for _, path := range rule.Paths {
+				addBackend(path.Backend)
+			}
+		}
+	}
+
+	return names
+}
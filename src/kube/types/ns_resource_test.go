@@ -0,0 +1,89 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import "testing"
+
+func TestServiceSelectsPod(t *testing.T) {
+	selector := map[string]string{"app": "web"}
+	svc := &Service{Selector: &selector}
+
+	if !svc.SelectsPod(map[string]string{"app": "web", "tier": "frontend"}) {
+		t.Fatalf("service should select pod whose labels contain the selector")
+	}
+
+	if svc.SelectsPod(map[string]string{"app": "db"}) {
+		t.Fatalf("service should not select pod whose label value mismatches")
+	}
+
+	empty := &Service{}
+	if empty.SelectsPod(map[string]string{"app": "web"}) {
+		t.Fatalf("service without selector should select no pods")
+	}
+}
+
+func TestIngressBackendServices(t *testing.T) {
+	ingress := &Ingress{
+		DefaultBackend: &IngressBackend{Service: &IngressServiceBackend{Name: "web"}},
+		Rules: &[]IngressRule{{
+			Host: "example.com",
+			Paths: []HTTPIngressPath{
+				{Path: "/", Backend: IngressBackend{Service: &IngressServiceBackend{Name: "web"}}},
+				{Path: "/api", Backend: IngressBackend{Service: &IngressServiceBackend{Name: "api"}}},
+			},
+		}},
+	}
+
+	names := ingress.BackendServices()
+	if len(names) != 2 || names[0] != "web" || names[1] != "api" {
+		t.Fatalf("backend services should be deduplicated in order, got: %v", names)
+	}
+}
+
+func TestNsResourceMountedBy(t *testing.T) {
+	volumes := []Volume{
+		{Name: "conf", VolumeSource: VolumeSource{
+			ConfigMap: &ConfigMapVolumeSource{LocalObjectReference: LocalObjectReference{Name: "web-conf"}}}},
+		{Name: "data", VolumeSource: VolumeSource{
+			PersistentVolumeClaim: &PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}}},
+	}
+	pod := &Pod{Volumes: &volumes}
+
+	cm := &ConfigMap{NsResourceBase: NsResourceBase{Name: "web-conf"}}
+	if !cm.MountedBy(pod) {
+		t.Fatalf("configMap should be mounted by pod")
+	}
+
+	pvc := &PersistentVolumeClaim{NsResourceBase: NsResourceBase{Name: "other"}}
+	if pvc.MountedBy(pod) {
+		t.Fatalf("persistentVolumeClaim should not be mounted by pod")
+	}
+}
+
+func TestValidateServicePorts(t *testing.T) {
+	if err := ValidateServicePorts([]ServicePort{{Port: 80}}); err != nil {
+		t.Fatalf("single unnamed port should be valid, err: %v", err)
+	}
+
+	if err := ValidateServicePorts([]ServicePort{{Name: "http", Port: 80}, {Port: 443}}); err == nil {
+		t.Fatalf("multiple ports without names should be invalid")
+	}
+
+	if err := ValidateServicePorts([]ServicePort{{Name: "http", Port: 70000}}); err == nil {
+		t.Fatalf("port out of range should be invalid")
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// PersistentVolumeClaimFields merge the fields of the PersistentVolumeClaim and the details corresponding to the
+// fields together.
+var PersistentVolumeClaimFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, PersistentVolumeClaimSpecFieldsDescriptor)
+
+// PersistentVolumeClaimSpecFieldsDescriptor PersistentVolumeClaim spec's fields descriptors.
+var PersistentVolumeClaimSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: AccessModesField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+	{Field: StorageClassNameField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: VolumeNameField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: VolumeModeField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: StorageField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: PhaseField, Type: enumor.String, IsRequired: false, IsEditable: true},
+}
+
+// PersistentVolumeClaimPhase the phase of a persistentVolumeClaim
+type PersistentVolumeClaimPhase string
+
+const (
+	// ClaimPending used for PersistentVolumeClaims that are not yet bound
+	ClaimPending PersistentVolumeClaimPhase = "Pending"
+	// ClaimBound used for PersistentVolumeClaims that are bound
+	ClaimBound PersistentVolumeClaimPhase = "Bound"
+	// ClaimLost used for PersistentVolumeClaims that lost their underlying PersistentVolume
+	ClaimLost PersistentVolumeClaimPhase = "Lost"
+)
+
+// PersistentVolumeClaim define the persistentVolumeClaim struct.
+type PersistentVolumeClaim struct {
+	NsResourceBase   `json:",inline" bson:",inline"`
+	Labels           *map[string]string            `json:"labels,omitempty" bson:"labels"`
+	AccessModes      *[]PersistentVolumeAccessMode `json:"access_modes,omitempty" bson:"access_modes"`
+	StorageClassName *string                       `json:"storage_class_name,omitempty" bson:"storage_class_name"`
+	VolumeName       *string                       `json:"volume_name,omitempty" bson:"volume_name"`
+	VolumeMode       *PersistentVolumeMode         `json:"volume_mode,omitempty" bson:"volume_mode"`
+	// Storage the requested storage size, e.g. 10Gi
+	Storage *string                     `json:"storage,omitempty" bson:"storage"`
+	Phase   *PersistentVolumeClaimPhase `json:"phase,omitempty" bson:"phase"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (p *PersistentVolumeClaim) GetNsResourceBase() NsResourceBase {
+	return p.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (p *PersistentVolumeClaim) SetNsResourceBase(base NsResourceBase) {
+	p.NsResourceBase = base
+}
+
+// ValidateCreate validate create persistentVolumeClaim
+func (p *PersistentVolumeClaim) ValidateCreate() ccErr.RawErrorInfo {
+	if p == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := validateNsResCreate(*p, p.NsResourceBase, PersistentVolumeClaimFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return p.validateSpec()
+}
+
+// ValidateUpdate validate update persistentVolumeClaim
+func (p *PersistentVolumeClaim) ValidateUpdate() ccErr.RawErrorInfo {
+	if p == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := ValidateUpdate(*p, PersistentVolumeClaimFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return p.validateSpec()
+}
+
+func (p *PersistentVolumeClaim) validateSpec() ccErr.RawErrorInfo {
+	if p.AccessModes != nil {
+		if err := ValidateAccessModes(*p.AccessModes); err != nil {
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{AccessModesField},
+			}
+		}
+	}
+
+	if p.Phase != nil {
+		switch *p.Phase {
+		case ClaimPending, ClaimBound, ClaimLost:
+		default:
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{PhaseField},
+			}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// BuildUpdateData build persistentVolumeClaim update data
+func (p *PersistentVolumeClaim) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if p == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResUpdateData(p, user)
+}
+
+// MountedBy check if the persistentVolumeClaim is mounted by the pod as a volume. the pod must be in the same
+// namespace as the persistentVolumeClaim.
+func (p *PersistentVolumeClaim) MountedBy(pod *Pod) bool {
+	if p == nil || pod == nil || pod.Volumes == nil {
+		return false
+	}
+
+	for _, volume := range *pod.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == p.Name {
+			return true
+		}
+	}
+	return false
+}
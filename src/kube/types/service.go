@@ -0,0 +1,200 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// ServiceFields merge the fields of the Service and the details corresponding to the fields together.
+var ServiceFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, ServiceSpecFieldsDescriptor)
+
+// ServiceSpecFieldsDescriptor Service spec's fields descriptors.
+var ServiceSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: SelectorField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: TypeField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: ClusterIPField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: ClusterIPsField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+	{Field: ExternalIPsField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+	{Field: PortsField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+	{Field: ExternalNameField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: SessionAffinityField, Type: enumor.String, IsRequired: false, IsEditable: true},
+}
+
+// ServiceType string describes ingress methods for a service
+type ServiceType string
+
+const (
+	// ServiceTypeClusterIP means a service will only be accessible inside the cluster, via the cluster IP.
+	ServiceTypeClusterIP ServiceType = "ClusterIP"
+
+	// ServiceTypeNodePort means a service will be exposed on one port of every node, in addition to 'ClusterIP' type.
+	ServiceTypeNodePort ServiceType = "NodePort"
+
+	// ServiceTypeLoadBalancer means a service will be exposed via an external load balancer (if the cloud provider
+	// supports it), in addition to 'NodePort' type.
+	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+
+	// ServiceTypeExternalName means a service consists of only a reference to an external name that kubedns or
+	// equivalent will return as a CNAME record, with no exposing or proxying of any pods involved.
+	ServiceTypeExternalName ServiceType = "ExternalName"
+)
+
+const (
+	// ProtocolTCP is the TCP protocol.
+	ProtocolTCP Protocol = "TCP"
+	// ProtocolUDP is the UDP protocol.
+	ProtocolUDP Protocol = "UDP"
+	// ProtocolSCTP is the SCTP protocol.
+	ProtocolSCTP Protocol = "SCTP"
+)
+
+// ServicePort contains information on service's port.
+type ServicePort struct {
+	// Name of this port within the service, must be unique when the service has more than one port.
+	Name string `json:"name,omitempty" bson:"name"`
+	// Protocol the IP protocol for this port, supports "TCP", "UDP", and "SCTP", default is TCP.
+	Protocol Protocol `json:"protocol,omitempty" bson:"protocol"`
+	// Port the port that will be exposed by this service.
+	Port int32 `json:"port" bson:"port"`
+	// TargetPort number or name of the port to access on the pods targeted by the service.
+	TargetPort *IntOrString `json:"target_port,omitempty" bson:"target_port"`
+	// NodePort the port on each node on which this service is exposed when type is NodePort or LoadBalancer.
+	NodePort int32 `json:"node_port,omitempty" bson:"node_port"`
+}
+
+// Service define the service struct.
+type Service struct {
+	NsResourceBase  `json:",inline" bson:",inline"`
+	Labels          *map[string]string `json:"labels,omitempty" bson:"labels"`
+	Selector        *map[string]string `json:"selector,omitempty" bson:"selector"`
+	Type            *ServiceType       `json:"type,omitempty" bson:"type"`
+	ClusterIP       *string            `json:"cluster_ip,omitempty" bson:"cluster_ip"`
+	ClusterIPs      *[]string          `json:"cluster_ips,omitempty" bson:"cluster_ips"`
+	ExternalIPs     *[]string          `json:"external_ips,omitempty" bson:"external_ips"`
+	Ports           *[]ServicePort     `json:"ports,omitempty" bson:"ports"`
+	ExternalName    *string            `json:"external_name,omitempty" bson:"external_name"`
+	SessionAffinity *string            `json:"session_affinity,omitempty" bson:"session_affinity"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (s *Service) GetNsResourceBase() NsResourceBase {
+	return s.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (s *Service) SetNsResourceBase(base NsResourceBase) {
+	s.NsResourceBase = base
+}
+
+// ValidateCreate validate create service
+func (s *Service) ValidateCreate() ccErr.RawErrorInfo {
+	if s == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := validateNsResCreate(*s, s.NsResourceBase, ServiceFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return s.validateSpec()
+}
+
+// ValidateUpdate validate update service
+func (s *Service) ValidateUpdate() ccErr.RawErrorInfo {
+	if s == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if err := ValidateUpdate(*s, ServiceFields); err.ErrCode != 0 {
+		return err
+	}
+
+	return s.validateSpec()
+}
+
+func (s *Service) validateSpec() ccErr.RawErrorInfo {
+	if s.Type != nil {
+		switch *s.Type {
+		case ServiceTypeClusterIP, ServiceTypeNodePort, ServiceTypeLoadBalancer, ServiceTypeExternalName:
+		default:
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{TypeField},
+			}
+		}
+	}
+
+	if s.Selector != nil {
+		if err := ValidateSelectorLabels(*s.Selector); err != nil {
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{SelectorField},
+			}
+		}
+	}
+
+	if s.Ports != nil {
+		if err := ValidateServicePorts(*s.Ports); err != nil {
+			return ccErr.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{PortsField},
+			}
+		}
+	}
+
+	return ccErr.RawErrorInfo{}
+}
+
+// BuildUpdateData build service update data
+func (s *Service) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if s == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResUpdateData(s, user)
+}
+
+// SelectsPod check if the service routes traffic to the pod with the given labels. same as k8s, a service without
+// selector selects no pods, its endpoints are managed outside the cluster.
+func (s *Service) SelectsPod(labels map[string]string) bool {
+	if s == nil || s.Selector == nil || len(*s.Selector) == 0 {
+		return false
+	}
+
+	for key, val := range *s.Selector {
+		podVal, exists := labels[key]
+		if !exists || podVal != val {
+			return false
+		}
+	}
+	return true
+}
@@ -160,6 +160,114 @@ func (t WorkloadType) NewInst() (WorkloadInterface, error) {
 	}
 }
 
+// NsResourceType namespace scoped resource type enum, these resources are neither workloads nor pods, they describe
+// how pods are exposed, configured and which storage they mount.
+type NsResourceType string
+
+// Validate validate NsResourceType
+func (t NsResourceType) Validate() error {
+	switch t {
+	case KubeService, KubeIngress, KubeConfigMap, KubePersistentVolumeClaim:
+		return nil
+	default:
+		return fmt.Errorf("can not support this type of namespace resource, kind: %s", t)
+	}
+}
+
+// Table get the table name based on the namespace resource type
+func (t NsResourceType) Table() (string, error) {
+	switch t {
+	case KubeService:
+		return BKTableNameBaseService, nil
+
+	case KubeIngress:
+		return BKTableNameBaseIngress, nil
+
+	case KubeConfigMap:
+		return BKTableNameBaseConfigMap, nil
+
+	case KubePersistentVolumeClaim:
+		return BKTableNameBasePersistentVolumeClaim, nil
+
+	default:
+		return "", fmt.Errorf("can not find table name, kind: %s", t)
+	}
+}
+
+// Fields get the namespace resource type related table fields
+func (t NsResourceType) Fields() (*table.Fields, error) {
+	switch t {
+	case KubeService:
+		return ServiceFields, nil
+
+	case KubeIngress:
+		return IngressFields, nil
+
+	case KubeConfigMap:
+		return ConfigMapFields, nil
+
+	case KubePersistentVolumeClaim:
+		return PersistentVolumeClaimFields, nil
+
+	default:
+		return nil, fmt.Errorf("namespace resource type %s is not supported", t)
+	}
+}
+
+// NewInst new a namespace resource instance according to namespace resource type
+func (t NsResourceType) NewInst() (NsResourceInterface, error) {
+	switch t {
+	case KubeService:
+		return new(Service), nil
+
+	case KubeIngress:
+		return new(Ingress), nil
+
+	case KubeConfigMap:
+		return new(ConfigMap), nil
+
+	case KubePersistentVolumeClaim:
+		return new(PersistentVolumeClaim), nil
+
+	default:
+		return nil, fmt.Errorf("namespace resource type %s is not supported", t)
+	}
+}
+
+// SpecFieldsDescriptor get the spec fields descriptors according to namespace resource type
+func (t NsResourceType) SpecFieldsDescriptor() (table.FieldsDescriptors, error) {
+	switch t {
+	case KubeService:
+		return ServiceSpecFieldsDescriptor, nil
+
+	case KubeIngress:
+		return IngressSpecFieldsDescriptor, nil
+
+	case KubeConfigMap:
+		return ConfigMapSpecFieldsDescriptor, nil
+
+	case KubePersistentVolumeClaim:
+		return PersistentVolumeClaimSpecFieldsDescriptor, nil
+
+	default:
+		return nil, fmt.Errorf("namespace resource type %s is not supported", t)
+	}
+}
+
+const (
+	// KubeService k8s service type
+	KubeService NsResourceType = "service"
+
+	// KubeIngress k8s ingress type
+	KubeIngress NsResourceType = "ingress"
+
+	// KubeConfigMap k8s configMap type
+	KubeConfigMap NsResourceType = "configMap"
+
+	// KubePersistentVolumeClaim k8s persistentVolumeClaim type
+	KubePersistentVolumeClaim NsResourceType = "persistentVolumeClaim"
+)
+
 const (
 	// KubeDeployment k8s deployment type
 	KubeDeployment WorkloadType = "deployment"
@@ -227,6 +335,18 @@ const (
 	// BKTableNameBaseCustom the table name of the Custom Workload
 	BKTableNameBaseCustom = "cc_CustomBase"
 
+	// BKTableNameBaseService the table name of the Service
+	BKTableNameBaseService = "cc_ServiceBase"
+
+	// BKTableNameBaseIngress the table name of the Ingress
+	BKTableNameBaseIngress = "cc_IngressBase"
+
+	// BKTableNameBaseConfigMap the table name of the ConfigMap
+	BKTableNameBaseConfigMap = "cc_ConfigMapBase"
+
+	// BKTableNameBasePersistentVolumeClaim the table name of the PersistentVolumeClaim
+	BKTableNameBasePersistentVolumeClaim = "cc_PersistentVolumeClaimBase"
+
 	// BKTableNameBasePod the table name of the Pod
 	BKTableNameBasePod = "cc_PodBase"
 
@@ -235,6 +355,9 @@ const (
 
 	// BKTableNameNsSharedClusterRel the table name of shared cluster and biz relation by namespace dimension
 	BKTableNameNsSharedClusterRel = "cc_NsSharedClusterRelation"
+
+	// BKTableNameClusterCredential the table name of the credentials of clusters collected by datacollection
+	BKTableNameClusterCredential = "cc_ClusterCredential"
 )
 
 // common field names
@@ -401,6 +524,69 @@ const (
 	RollingUpdateStrategyField = "rolling_update_strategy"
 )
 
+// service field names
+const (
+	// ClusterIPField service cluster ip field
+	ClusterIPField = "cluster_ip"
+
+	// ClusterIPsField service cluster ips field
+	ClusterIPsField = "cluster_ips"
+
+	// ExternalIPsField service external ips field
+	ExternalIPsField = "external_ips"
+
+	// ExternalNameField service external name field
+	ExternalNameField = "external_name"
+
+	// SessionAffinityField service session affinity field
+	SessionAffinityField = "session_affinity"
+)
+
+// ingress field names
+const (
+	// IngressClassNameField ingress class name field
+	IngressClassNameField = "ingress_class_name"
+
+	// DefaultBackendField ingress default backend field
+	DefaultBackendField = "default_backend"
+
+	// RulesField ingress rules field
+	RulesField = "rules"
+
+	// TLSField ingress tls field
+	TLSField = "tls"
+)
+
+// configMap field names
+const (
+	// DataField configMap data field
+	DataField = "data"
+
+	// ImmutableField configMap immutable field
+	ImmutableField = "immutable"
+)
+
+// persistentVolumeClaim field names
+const (
+	// AccessModesField persistentVolumeClaim access modes field
+	AccessModesField = "access_modes"
+
+	// StorageClassNameField persistentVolumeClaim storage class name field
+	StorageClassNameField = "storage_class_name"
+
+	// VolumeNameField persistentVolumeClaim bound volume name field
+	VolumeNameField = "volume_name"
+
+	// VolumeModeField persistentVolumeClaim volume mode field
+	VolumeModeField = "volume_mode"
+
+	// StorageField persistentVolumeClaim requested storage field
+	StorageField = "storage"
+
+	// PhaseField persistentVolumeClaim phase field
+	PhaseField = "phase"
+)
+
 // pod field names
 const (
 	// PriorityField pod priority field
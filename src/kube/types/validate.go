@@ -387,3 +387,116 @@ func ValidateUpdate(data interface{}, field *table.Fields) ccErr.RawErrorInfo {
 
 	return ccErr.RawErrorInfo{}
 }
+
+const (
+	minPortNumber = 1
+	maxPortNumber = 65535
+)
+
+// ValidateSelectorLabels validate the label selector of a service, keys and values must be strings within the default
+// string length, and keys can not be empty.
+func ValidateSelectorLabels(selector map[string]string) error {
+	for key, val := range selector {
+		if key == "" {
+			return errors.New("selector key can not be empty")
+		}
+
+		if len(key) > defaultStringLength || len(val) > defaultStringLength {
+			return fmt.Errorf("selector %s length exceeds max length: %d", key, defaultStringLength)
+		}
+	}
+	return nil
+}
+
+// ValidateServicePorts validate the ports of a service:
+// 1、port must be in range [1, 65535], node port must be 0 or in range [1, 65535].
+// 2、protocol can only be TCP, UDP or SCTP, empty protocol is treated as TCP.
+// 3、port names must be set and be unique when there is more than one port.
+func ValidateServicePorts(ports []ServicePort) error {
+	names := make(map[string]struct{})
+	for _, port := range ports {
+		if port.Port < minPortNumber || port.Port > maxPortNumber {
+			return fmt.Errorf("port %d out of range [%d - %d]", port.Port, minPortNumber, maxPortNumber)
+		}
+
+		if port.NodePort != 0 && (port.NodePort < minPortNumber || port.NodePort > maxPortNumber) {
+			return fmt.Errorf("node port %d out of range [%d - %d]", port.NodePort, minPortNumber, maxPortNumber)
+		}
+
+		switch port.Protocol {
+		case "", ProtocolTCP, ProtocolUDP, ProtocolSCTP:
+		default:
+			return fmt.Errorf("port protocol %s is invalid", port.Protocol)
+		}
+
+		if len(ports) == 1 {
+			continue
+		}
+
+		if port.Name == "" {
+			return errors.New("port name must be set when service has more than one port")
+		}
+
+		if _, exists := names[port.Name]; exists {
+			return fmt.Errorf("port name %s is duplicated", port.Name)
+		}
+		names[port.Name] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateIngressBackend validate ingress backend, the backend service name must be set, and only one of the port
+// name and port number can be set.
+func ValidateIngressBackend(backend IngressBackend) error {
+	if backend.Service == nil {
+		return errors.New("backend service is not set")
+	}
+
+	if backend.Service.Name == "" {
+		return errors.New("backend service name is not set")
+	}
+
+	port := backend.Service.Port
+	if port.Name != "" && port.Number != 0 {
+		return errors.New("backend service port name and number can not be set at the same time")
+	}
+
+	if port.Number != 0 && (port.Number < minPortNumber || port.Number > maxPortNumber) {
+		return fmt.Errorf("backend service port %d out of range [%d - %d]", port.Number, minPortNumber,
+			maxPortNumber)
+	}
+
+	return nil
+}
+
+// ValidateIngressRules validate ingress rules, each path must have a valid backend and a valid path type.
+func ValidateIngressRules(rules []IngressRule) error {
+	for _, rule := range rules {
+		for _, path := range rule.Paths {
+			if path.PathType != nil {
+				switch *path.PathType {
+				case PathTypeExact, PathTypePrefix, PathTypeImplementationSpecific:
+				default:
+					return fmt.Errorf("path type %s is invalid", *path.PathType)
+				}
+			}
+
+			if err := ValidateIngressBackend(path.Backend); err != nil {
+				return fmt.Errorf("host %s path %s is invalid, err: %v", rule.Host, path.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAccessModes validate the access modes of a persistentVolumeClaim
+func ValidateAccessModes(modes []PersistentVolumeAccessMode) error {
+	for _, mode := range modes {
+		switch mode {
+		case ReadWriteOnce, ReadOnlyMany, ReadWriteMany, ReadWriteOncePod:
+		default:
+			return fmt.Errorf("access mode %s is invalid", mode)
+		}
+	}
+	return nil
+}
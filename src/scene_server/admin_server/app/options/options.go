@@ -21,6 +21,7 @@ import (
 	"configcenter/src/ac/iam"
 	"configcenter/src/common/auth"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/ssl"
 	"configcenter/src/storage/dal/kafka"
 	"configcenter/src/storage/dal/mongo"
@@ -71,6 +72,8 @@ type Config struct {
 	SyncIAMPeriodMinutes int
 	// 通过何种方式调用gse接口注册dataid
 	DataIdMigrateWay MigrateWay
+	// AttrCryptor is used to re-encrypt the encrypted model attributes' values, nil if it is not configured
+	AttrCryptor *cryptor.AttrCryptor
 }
 
 // MigrateWay 通过何种方式调用gse接口注册dataid
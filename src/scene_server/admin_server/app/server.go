@@ -21,11 +21,12 @@ import (
 	"fmt"
 	"time"
 
+	"configcenter/src/ac"
 	iamcli "configcenter/src/ac/iam"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/resource/esb"
 	"configcenter/src/common/types"
@@ -80,7 +81,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	process.Service.SetCache(cache)
 
 	var iamCli *iamcli.IAM
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		blog.Info("enable auth center access.")
 
 		iamCli, err = iamcli.NewIAM(process.Config.IAM, process.Core.Metric().Registry())
@@ -198,7 +199,7 @@ func parseSeverConfig(ctx context.Context, op *options.ServerOption) (*MigrateSe
 	process.Config.SnapRedis = snapRedisConf
 
 	process.Config.IAM, err = iamcli.ParseConfigFromKV("authServer", nil)
-	if err != nil && auth.EnableAuthorize() {
+	if err != nil && ac.EnableIAM() {
 		blog.Errorf("parse iam error: %v", err)
 		return nil, err
 	}
@@ -207,9 +208,22 @@ func parseSeverConfig(ctx context.Context, op *options.ServerOption) (*MigrateSe
 		return nil, err
 	}
 
+	attrCryptoConf, err := cc.AttrCrypto("attributeCrypto")
+	if err != nil {
+		return nil, fmt.Errorf("get attribute crypto config failed, err: %v", err)
+	}
+
+	if attrCryptoConf != nil {
+		process.Config.AttrCryptor, err = cryptor.NewAttrCryptor(attrCryptoConf)
+		if err != nil {
+			return nil, fmt.Errorf("new attribute cryptor failed, err: %v", err)
+		}
+	}
+
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: process.onMigrateConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv: backbone.SrvRegdiscv{
 			Regdiscv:  process.Config.Register.Address,
 			TLSConfig: &process.Config.Register.TLS,
@@ -222,7 +236,7 @@ func parseSeverConfig(ctx context.Context, op *options.ServerOption) (*MigrateSe
 	}
 
 	process.Core = engine
-	process.ConfigCenter = configures.NewConfCenter(ctx, engine.ServiceManageClient())
+	process.ConfigCenter = configures.NewConfCenter(ctx, engine.ConfRegDiscover())
 	return process, nil
 }
 
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
 	"configcenter/src/common/types"
 
 	"github.com/spf13/viper"
@@ -159,7 +160,14 @@ func (cc *ConfCenter) isEsConfigOK(v *viper.Viper, fileName string) error {
 			blog.Errorf("The configuration file is %s, the es.fullTextSearch should be on or off !", fileName)
 			return fmt.Errorf("The configuration file is %s, the es.fullTextSearch should be on or off !", fileName)
 		}
-		if fullTextSearch == "on" {
+		engine := v.GetString("es.engine")
+		if engine != "" && engine != metadata.FullTextEngineElastic && engine != metadata.FullTextEngineLocal {
+			blog.Errorf("The configuration file is %s, the es.engine should be elasticsearch or local !", fileName)
+			return fmt.Errorf("The configuration file is %s, the es.engine should be elasticsearch or local !",
+				fileName)
+		}
+		// the local engine does not need elasticsearch
+		if fullTextSearch == "on" && engine != metadata.FullTextEngineLocal {
 			if err := cc.isConfigEmpty("es.url", fileName, v); err != nil {
 				return err
 			}
@@ -21,9 +21,9 @@ import (
 	"net/http"
 	"time"
 
+	"configcenter/src/ac"
 	iamcli "configcenter/src/ac/iam"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/blog"
 	httpheader "configcenter/src/common/http/header"
 	headerutil "configcenter/src/common/http/header/util"
@@ -100,7 +100,7 @@ func newKit() *rest.Kit {
 
 // SyncIAM sync the system instances resource between CMDB and IAM
 func (s *syncor) SyncIAM(iamCli *iamcli.IAM, redisCli redis.Client, lgc *logics.Logics) {
-	if !auth.EnableAuthorize() {
+	if !ac.EnableIAM() {
 		return
 	}
 	time.Sleep(time.Minute)
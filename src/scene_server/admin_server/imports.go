@@ -120,4 +120,15 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202405141035"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202410100930"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202502101200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510201200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510211200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510221200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510231200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510241200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510251200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510261200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510271200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510281200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510291200"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.14.202510301200"
 )
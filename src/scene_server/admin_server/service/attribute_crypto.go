@@ -0,0 +1,190 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// reEncryptPageSize is the page size of the instances to re-encrypt in one batch
+const reEncryptPageSize = 200
+
+type reEncryptAttrReq struct {
+	// ObjID is the object whose encrypted attributes need to be re-encrypted, all objects if it is not set
+	ObjID string `json:"bk_obj_id"`
+}
+
+type reEncryptAttrRsp struct {
+	// Attributes is the number of encrypted attributes that are checked
+	Attributes int `json:"attributes"`
+	// Instances is the number of instances that are re-encrypted
+	Instances int `json:"instances"`
+}
+
+// ReEncryptAttribute re-encrypt the encrypted attributes' values with the active key. it is used to rotate the key,
+// and to encrypt the existing plaintext values after an attribute is changed to be encrypted.
+func (s *Service) ReEncryptAttribute(req *restful.Request, resp *restful.Response) {
+	rHeader := req.Request.Header
+	rid := httpheader.GetRid(rHeader)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(rHeader))
+
+	if s.Config.AttrCryptor == nil {
+		blog.Errorf("attribute crypto is not configured, rid: %s", rid)
+		errInfo := metadata.RespError{Msg: defErr.CCError(common.CCErrCommAttrCryptoNotConfigured)}
+		_ = resp.WriteError(http.StatusOK, &errInfo)
+		return
+	}
+
+	param := new(reEncryptAttrReq)
+	if err := json.NewDecoder(req.Request.Body).Decode(param); err != nil {
+		blog.Errorf("decode re-encrypt attribute body failed, err: %v, rid: %s", err, rid)
+		errInfo := metadata.RespError{Msg: defErr.CCError(common.CCErrCommJSONUnmarshalFailed)}
+		_ = resp.WriteError(http.StatusBadRequest, &errInfo)
+		return
+	}
+
+	attrCond := mapstr.MapStr{metadata.AttributeFieldIsEncrypted: true}
+	if param.ObjID != "" {
+		attrCond[common.BKObjIDField] = param.ObjID
+	}
+
+	attrs := make([]metadata.Attribute, 0)
+	err := s.db.Table(common.BKTableNameObjAttDes).Find(attrCond).
+		Fields(common.BKObjIDField, common.BKPropertyIDField, common.BkSupplierAccount).All(s.ctx, &attrs)
+	if err != nil {
+		blog.Errorf("get encrypted attributes failed, cond: %v, err: %v, rid: %s", attrCond, err, rid)
+		errInfo := metadata.RespError{Msg: defErr.CCError(common.CCErrCommDBSelectFailed)}
+		_ = resp.WriteError(http.StatusOK, &errInfo)
+		return
+	}
+
+	// group the encrypted attributes by the instance table they belong to
+	tableProperties := make(map[string][]string)
+	tableObjIDs := make(map[string]string)
+	for _, attr := range attrs {
+		table := common.GetInstTableName(attr.ObjectID, attr.OwnerID)
+		tableProperties[table] = append(tableProperties[table], attr.PropertyID)
+		tableObjIDs[table] = attr.ObjectID
+	}
+
+	response := &reEncryptAttrRsp{Attributes: len(attrs)}
+	for table, propertyIDs := range tableProperties {
+		cnt, err := s.reEncryptTableAttr(table, tableObjIDs[table], propertyIDs, rid)
+		response.Instances += cnt
+		if err != nil {
+			blog.Errorf("re-encrypt table %s attributes %v failed, err: %v, rid: %s", table, propertyIDs, err, rid)
+			errInfo := metadata.RespError{Msg: err}
+			_ = resp.WriteError(http.StatusOK, &errInfo)
+			return
+		}
+	}
+
+	blog.Infof("re-encrypt %d attributes of %d instances success, rid: %s", response.Attributes,
+		response.Instances, rid)
+	_ = resp.WriteEntity(metadata.NewSuccessResp(response))
+}
+
+// reEncryptTableAttr re-encrypt the encrypted attributes' values of the instances in the table page by page,
+// returns the number of instances that are re-encrypted
+func (s *Service) reEncryptTableAttr(table, objID string, propertyIDs []string, rid string) (int, error) {
+	idField := common.GetInstIDField(objID)
+
+	orCond := make([]mapstr.MapStr, len(propertyIDs))
+	for idx, propertyID := range propertyIDs {
+		orCond[idx] = mapstr.MapStr{propertyID: mapstr.MapStr{common.BKDBExists: true, common.BKDBNE: ""}}
+	}
+
+	fields := append([]string{idField}, propertyIDs...)
+	var lastID int64
+	total := 0
+	for {
+		cond := mapstr.MapStr{
+			idField:       mapstr.MapStr{common.BKDBGT: lastID},
+			common.BKDBOR: orCond,
+		}
+
+		insts := make([]mapstr.MapStr, 0)
+		err := s.db.Table(table).Find(cond).Fields(fields...).Sort(idField).Limit(reEncryptPageSize).
+			All(s.ctx, &insts)
+		if err != nil {
+			blog.Errorf("get instances from %s failed, cond: %v, err: %v, rid: %s", table, cond, err, rid)
+			return total, err
+		}
+
+		for _, inst := range insts {
+			instID, err := util.GetInt64ByInterface(inst[idField])
+			if err != nil {
+				blog.Errorf("parse instance id failed, inst: %v, err: %v, rid: %s", inst, err, rid)
+				return total, err
+			}
+			lastID = instID
+
+			updateData, err := s.reEncryptInstAttr(inst, propertyIDs)
+			if err != nil {
+				blog.Errorf("re-encrypt %s instance %d failed, err: %v, rid: %s", objID, instID, err, rid)
+				return total, err
+			}
+
+			if len(updateData) == 0 {
+				continue
+			}
+
+			if err = s.db.Table(table).Update(s.ctx, mapstr.MapStr{idField: instID}, updateData); err != nil {
+				blog.Errorf("update %s instance %d failed, err: %v, rid: %s", objID, instID, err, rid)
+				return total, err
+			}
+			total++
+		}
+
+		if len(insts) < reEncryptPageSize {
+			return total, nil
+		}
+	}
+}
+
+// reEncryptInstAttr generate the re-encrypted values of the instance's attributes that need to be re-encrypted
+func (s *Service) reEncryptInstAttr(inst mapstr.MapStr, propertyIDs []string) (mapstr.MapStr, error) {
+	updateData := make(mapstr.MapStr)
+	for _, propertyID := range propertyIDs {
+		value, ok := inst[propertyID].(string)
+		if !ok || !s.Config.AttrCryptor.NeedReEncrypt(value) {
+			continue
+		}
+
+		plaintext, err := s.Config.AttrCryptor.Decrypt(value)
+		if err != nil {
+			return nil, err
+		}
+
+		ciphertext, err := s.Config.AttrCryptor.Encrypt(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		updateData[propertyID] = ciphertext
+	}
+	return updateData, nil
+}
@@ -20,9 +20,9 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"configcenter/src/ac"
 	aciam "configcenter/src/ac/iam"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/blog"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/metadata"
@@ -33,7 +33,7 @@ import (
 
 // InitAuthCenter init auth resources on IAM
 func (s *Service) InitAuthCenter(req *restful.Request, resp *restful.Response) {
-	if !auth.EnableAuthorize() {
+	if !ac.EnableIAM() {
 		_ = resp.WriteEntity(metadata.NewSuccessResp(nil))
 		return
 	}
@@ -41,8 +41,8 @@ func (s *Service) InitAuthCenter(req *restful.Request, resp *restful.Response) {
 	rHeader := req.Request.Header
 	rid := httpheader.GetRid(rHeader)
 	defErr := s.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(rHeader))
-	if !auth.EnableAuthorize() {
-		blog.Warnf("received iam initialization request, but auth not enabled, rid: %s", rid)
+	if !ac.EnableIAM() {
+		blog.Warnf("received iam initialization request, but iam is not enabled, rid: %s", rid)
 		_ = resp.WriteEntity(metadata.NewSuccessResp(nil))
 		return
 	}
@@ -99,7 +99,7 @@ func (s *Service) InitAuthCenter(req *restful.Request, resp *restful.Response) {
 */
 // RegisterAuthAccount register auth account to iam
 func (s *Service) RegisterAuthAccount(req *restful.Request, resp *restful.Response) {
-	if !auth.EnableAuthorize() {
+	if !ac.EnableIAM() {
 		_ = resp.WriteEntity(metadata.NewSuccessResp(nil))
 		return
 	}
@@ -107,8 +107,8 @@ func (s *Service) RegisterAuthAccount(req *restful.Request, resp *restful.Respon
 	rHeader := req.Request.Header
 	rid := httpheader.GetRid(rHeader)
 	defErr := s.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(rHeader))
-	if !auth.EnableAuthorize() {
-		blog.Warnf("received iam register request, but auth not enabled, rid: %s", rid)
+	if !ac.EnableIAM() {
+		blog.Warnf("received iam register request, but iam is not enabled, rid: %s", rid)
 		_ = resp.WriteEntity(metadata.NewSuccessResp(nil))
 		return
 	}
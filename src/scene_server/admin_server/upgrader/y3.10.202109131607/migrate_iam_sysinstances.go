@@ -22,9 +22,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"configcenter/src/ac"
 	iamtype "configcenter/src/ac/iam"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/blog"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/mapstr"
@@ -39,7 +39,7 @@ import (
 // migrateIAMSysInstances migrate iam system instances
 func migrateIAMSysInstances(ctx context.Context, db dal.RDB, cache redis.Client, iam *iamtype.IAM,
 	conf *upgrader.Config) error {
-	if !auth.EnableAuthorize() {
+	if !ac.EnableIAM() {
 		return nil
 	}
 
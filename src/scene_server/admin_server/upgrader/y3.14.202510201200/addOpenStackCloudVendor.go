@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_14_202510201200
+
+import (
+	"context"
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+// attribute object attribute
+type attribute struct {
+	ID       int64        `json:"id" bson:"id"`
+	ObjectID string       `json:"bk_obj_id" bson:"bk_obj_id"`
+	Option   []enumOption `json:"option" bson:"option"`
+}
+
+// enumOption enum option
+type enumOption struct {
+	ID        string `json:"id" bson:"id"`
+	Name      string `json:"name" bson:"name"`
+	Type      string `json:"type" bson:"type"`
+	IsDefault bool   `json:"is_default" bson:"is_default"`
+}
+
+var openStackOption = enumOption{
+	ID:        metadata.OpenStack,
+	Name:      "OpenStack",
+	Type:      "text",
+	IsDefault: false,
+}
+
+// addOpenStackCloudVendor add openstack option to the cloud vendor enum of host and cloud area
+func addOpenStackCloudVendor(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	cond := map[string]interface{}{
+		common.BKObjIDField: map[string]interface{}{
+			common.BKDBIN: []string{common.BKInnerObjIDHost, common.BKInnerObjIDPlat},
+		},
+		common.BKPropertyIDField:   common.BKCloudVendor,
+		common.BKPropertyTypeField: common.FieldTypeEnum,
+	}
+
+	objAttrs := make([]attribute, 0)
+	err := db.Table(common.BKTableNameObjAttDes).Find(cond).All(ctx, &objAttrs)
+	if err != nil {
+		blog.Errorf("get cloud vendor field failed, err: %v", err)
+		return err
+	}
+	if len(objAttrs) != 2 {
+		blog.Errorf("get cloud vendor field failed, count cloud vendor field not equal 2, objAttrs: %v", objAttrs)
+		return errors.New("count cloud vendor field not equal 2")
+	}
+
+	for _, attr := range objAttrs {
+		exists := false
+		for _, option := range attr.Option {
+			if option.ID != openStackOption.ID {
+				continue
+			}
+			if option.Name != openStackOption.Name {
+				blog.Errorf("enum key %s already exists, enum: %v", option.ID, option)
+				return errors.New("enum key already exists")
+			}
+			exists = true
+			break
+		}
+		if exists {
+			continue
+		}
+
+		updateCond := map[string]interface{}{
+			common.BKObjIDField:        attr.ObjectID,
+			common.BKPropertyIDField:   common.BKCloudVendor,
+			common.BKPropertyTypeField: common.FieldTypeEnum,
+		}
+		updateData := map[string]interface{}{common.BKOptionField: append(attr.Option, openStackOption)}
+		if err := db.Table(common.BKTableNameObjAttDes).Update(ctx, updateCond, updateData); err != nil {
+			blog.Errorf("update cloud vendor attribute failed, err: %v, cond: %v, updateData: %v", err, updateCond,
+				updateData)
+			return err
+		}
+	}
+	return nil
+}
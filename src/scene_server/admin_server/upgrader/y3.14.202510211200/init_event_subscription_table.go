@@ -0,0 +1,120 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_14_202510211200
+
+import (
+	"context"
+
+	"configcenter/pkg/event/subscription"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var tableIndexes = map[string][]types.Index{
+	subscription.BKTableNameEventSubscription: {
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + subscription.IDField,
+			Keys:       bson.D{{subscription.IDField, 1}},
+			Background: true,
+			Unique:     true,
+		},
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + "name_bkSupplierAccount",
+			Keys:       bson.D{{subscription.NameField, 1}, {common.BkSupplierAccount, 1}},
+			Background: true,
+			Unique:     true,
+		},
+	},
+	subscription.BKTableNameEventDelivery: {
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + subscription.IDField,
+			Keys:       bson.D{{subscription.IDField, 1}},
+			Background: true,
+			Unique:     true,
+		},
+		{
+			Name:       common.CCLogicIndexNamePrefix + "subscriptionID_status",
+			Keys:       bson.D{{subscription.SubscriptionIDField, 1}, {subscription.StatusField, 1}},
+			Background: true,
+		},
+		{
+			Name:               common.CCLogicIndexNamePrefix + "createTime",
+			Keys:               bson.D{{common.CreateTimeField, 1}},
+			Background:         true,
+			ExpireAfterSeconds: 7 * 24 * 60 * 60,
+		},
+	},
+	subscription.BKTableNameEventDeadLetter: {
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + subscription.IDField,
+			Keys:       bson.D{{subscription.IDField, 1}},
+			Background: true,
+			Unique:     true,
+		},
+		{
+			Name:       common.CCLogicIndexNamePrefix + "subscriptionID",
+			Keys:       bson.D{{subscription.SubscriptionIDField, 1}},
+			Background: true,
+		},
+	},
+}
+
+func initEventSubscriptionTables(ctx context.Context, db dal.RDB) error {
+	for table, indexes := range tableIndexes {
+		exists, err := db.HasTable(ctx, table)
+		if err != nil {
+			blog.Errorf("check if table %s exists failed, err: %v", table, err)
+			return err
+		}
+
+		if !exists {
+			if err = db.CreateTable(ctx, table); err != nil && !db.IsDuplicatedError(err) {
+				blog.Errorf("create table %s failed, err: %v", table, err)
+				return err
+			}
+		}
+
+		existIndexes, err := db.Table(table).Indexes(ctx)
+		if err != nil {
+			blog.Errorf("get table %s index failed, err: %v", table, err)
+			return err
+		}
+
+		existIndexMap := make(map[string]struct{})
+		for _, index := range existIndexes {
+			existIndexMap[index.Name] = struct{}{}
+		}
+
+		for _, index := range indexes {
+			if _, exist := existIndexMap[index.Name]; exist {
+				continue
+			}
+
+			err = db.Table(table).CreateIndex(ctx, index)
+			if err != nil && !db.IsDuplicatedError(err) {
+				blog.Errorf("create table %s index %+v failed, err: %v", table, index, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
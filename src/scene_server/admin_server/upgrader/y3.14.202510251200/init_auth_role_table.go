@@ -0,0 +1,105 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_14_202510251200
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var tableIndexes = map[string][]types.Index{
+	common.BKTableNameAuthRole: {
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + "id",
+			Keys:       bson.D{{common.BKFieldID, 1}},
+			Background: true,
+			Unique:     true,
+		},
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + "name_bkSupplierAccount",
+			Keys:       bson.D{{common.BKFieldName, 1}, {common.BkSupplierAccount, 1}},
+			Background: true,
+			Unique:     true,
+		},
+	},
+	common.BKTableNameAuthRoleBinding: {
+		{
+			Name:       common.CCLogicUniqueIdxNamePrefix + "id",
+			Keys:       bson.D{{common.BKFieldID, 1}},
+			Background: true,
+			Unique:     true,
+		},
+		{
+			Name:       common.CCLogicIndexNamePrefix + "roleID",
+			Keys:       bson.D{{"role_id", 1}},
+			Background: true,
+		},
+		{
+			Name:       common.CCLogicIndexNamePrefix + "users",
+			Keys:       bson.D{{"users", 1}},
+			Background: true,
+		},
+	},
+}
+
+func initAuthRoleTables(ctx context.Context, db dal.RDB) error {
+	for table, indexes := range tableIndexes {
+		exists, err := db.HasTable(ctx, table)
+		if err != nil {
+			blog.Errorf("check if table %s exists failed, err: %v", table, err)
+			return err
+		}
+
+		if !exists {
+			if err = db.CreateTable(ctx, table); err != nil && !db.IsDuplicatedError(err) {
+				blog.Errorf("create table %s failed, err: %v", table, err)
+				return err
+			}
+		}
+
+		existIndexes, err := db.Table(table).Indexes(ctx)
+		if err != nil {
+			blog.Errorf("get table %s index failed, err: %v", table, err)
+			return err
+		}
+
+		existIndexMap := make(map[string]struct{})
+		for _, index := range existIndexes {
+			existIndexMap[index.Name] = struct{}{}
+		}
+
+		for _, index := range indexes {
+			if _, exist := existIndexMap[index.Name]; exist {
+				continue
+			}
+
+			err = db.Table(table).CreateIndex(ctx, index)
+			if err != nil && !db.IsDuplicatedError(err) {
+				blog.Errorf("create table %s index %+v failed, err: %v", table, index, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
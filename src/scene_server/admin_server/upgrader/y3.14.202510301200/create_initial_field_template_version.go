@@ -0,0 +1,106 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_14_202510301200
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+)
+
+// createInitialFieldTemplateVersions take a snapshot of the current attributes and uniques of the existing field
+// templates as their first version, so that they can be compared with the later versions. their relations keep
+// version 0, which means following the current field template content.
+func createInitialFieldTemplateVersions(ctx context.Context, db dal.RDB) error {
+	cond := mapstr.MapStr{
+		common.BKDBOR: []mapstr.MapStr{
+			{common.BKVersion: mapstr.MapStr{common.BKDBExists: false}},
+			{common.BKVersion: 0},
+		},
+	}
+	templates := make([]metadata.FieldTemplate, 0)
+	err := db.Table(common.BKTableNameFieldTemplate).Find(cond).
+		Fields(common.BKFieldID, common.BkSupplierAccount).All(ctx, &templates)
+	if err != nil {
+		blog.Errorf("find field templates without version failed, err: %v", err)
+		return err
+	}
+
+	for _, template := range templates {
+		if err := createInitialFieldTemplateVersion(ctx, db, &template); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createInitialFieldTemplateVersion(ctx context.Context, db dal.RDB, template *metadata.FieldTemplate) error {
+	cond := mapstr.MapStr{common.BKTemplateID: template.ID}
+	attrs := make([]metadata.FieldTemplateAttr, 0)
+	err := db.Table(common.BKTableNameObjAttDesTemplate).Find(cond).Sort(common.BKPropertyIndexField).
+		All(ctx, &attrs)
+	if err != nil {
+		blog.Errorf("list field template %d attrs failed, err: %v", template.ID, err)
+		return err
+	}
+
+	uniques := make([]metadata.FieldTemplateUnique, 0)
+	err = db.Table(common.BKTableNameObjectUniqueTemplate).Find(cond).Sort(common.BKFieldID).All(ctx, &uniques)
+	if err != nil {
+		blog.Errorf("list field template %d uniques failed, err: %v", template.ID, err)
+		return err
+	}
+
+	id, err := db.NextSequence(ctx, common.BKTableNameFieldTemplateVersion)
+	if err != nil {
+		blog.Errorf("get field template version sequence id failed, err: %v", err)
+		return err
+	}
+
+	version := &metadata.FieldTemplateVersion{
+		ID:         int64(id),
+		TemplateID: template.ID,
+		Version:    1,
+		Attributes: attrs,
+		Uniques:    uniques,
+		OwnerID:    template.OwnerID,
+		Creator:    common.CCSystemOperatorUserName,
+		CreateTime: &metadata.Time{Time: time.Now()},
+	}
+
+	// the version may be created by the former upgrade that failed before updating the field template
+	err = db.Table(common.BKTableNameFieldTemplateVersion).Insert(ctx, version)
+	if err != nil && !db.IsDuplicatedError(err) {
+		blog.Errorf("create field template %d initial version failed, err: %v", template.ID, err)
+		return err
+	}
+
+	tmplCond := mapstr.MapStr{common.BKFieldID: template.ID}
+	updateData := mapstr.MapStr{common.BKVersion: version.Version}
+	if err = db.Table(common.BKTableNameFieldTemplate).Update(ctx, tmplCond, updateData); err != nil {
+		blog.Errorf("update field template %d version failed, err: %v", template.ID, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_14_202510301200
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var fieldTemplateVersionIndexes = []types.Index{
+	{
+		Name:       common.CCLogicUniqueIdxNamePrefix + common.BKFieldID,
+		Keys:       bson.D{{common.BKFieldID, 1}},
+		Background: true,
+		Unique:     true,
+	},
+	{
+		Name:       common.CCLogicUniqueIdxNamePrefix + "bkTemplateID_version",
+		Keys:       bson.D{{common.BKTemplateID, 1}, {common.BKVersion, 1}},
+		Background: true,
+		Unique:     true,
+	},
+}
+
+// initFieldTemplateVersionTable create the field template version table and its indexes
+func initFieldTemplateVersionTable(ctx context.Context, db dal.RDB) error {
+	table := common.BKTableNameFieldTemplateVersion
+	exists, err := db.HasTable(ctx, table)
+	if err != nil {
+		blog.Errorf("check if table %s exists failed, err: %v", table, err)
+		return err
+	}
+
+	if !exists {
+		if err = db.CreateTable(ctx, table); err != nil && !db.IsDuplicatedError(err) {
+			blog.Errorf("create table %s failed, err: %v", table, err)
+			return err
+		}
+	}
+
+	existIndexes, err := db.Table(table).Indexes(ctx)
+	if err != nil {
+		blog.Errorf("get table %s index failed, err: %v", table, err)
+		return err
+	}
+
+	existIndexMap := make(map[string]struct{})
+	for _, index := range existIndexes {
+		existIndexMap[index.Name] = struct{}{}
+	}
+
+	for _, index := range fieldTemplateVersionIndexes {
+		if _, exist := existIndexMap[index.Name]; exist {
+			continue
+		}
+
+		err = db.Table(table).CreateIndex(ctx, index)
+		if err != nil && !db.IsDuplicatedError(err) {
+			blog.Errorf("create table %s index %+v failed, err: %v", table, index, err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -49,6 +49,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: authServer.onAuthConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv: backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover,
 			TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo: svrInfo,
@@ -22,7 +22,7 @@ import (
 	"fmt"
 	"time"
 
-	"configcenter/src/ac/iam"
+	"configcenter/src/ac"
 	"configcenter/src/common"
 	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
@@ -31,6 +31,7 @@ import (
 	"configcenter/src/common/cryptor"
 	headerutil "configcenter/src/common/http/header/util"
 	"configcenter/src/common/types"
+	"configcenter/src/common/zkclient"
 	"configcenter/src/scene_server/cloud_server/app/options"
 	"configcenter/src/scene_server/cloud_server/cloudsync"
 	"configcenter/src/scene_server/cloud_server/logics"
@@ -51,6 +52,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: process.onCloudConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv: backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover,
 			TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo: svrInfo,
@@ -92,7 +94,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	}
 	process.Service.SetEncryptor(accountCryptor)
 
-	authorizer := iam.NewAuthorizer(engine.CoreAPI)
+	authorizer := ac.NewAuthorizer(engine.CoreAPI)
 	service.SetAuthorizer(authorizer)
 
 	mongoConf := mongoConfig.GetMongoConf()
@@ -100,8 +102,12 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	process.Service.Logics = logics.NewLogics(service.Engine, accountCryptor, authorizer)
 
 	process.setSyncPeriod()
+	var zkClient *zkclient.ZkClient
+	if svcManageClient := service.Engine.ServiceManageClient(); svcManageClient != nil {
+		zkClient = svcManageClient.Client()
+	}
 	syncConf := cloudsync.SyncConf{
-		ZKClient:  service.Engine.ServiceManageClient().Client(),
+		ZKClient:  zkClient,
 		Logics:    process.Service.Logics,
 		UUID:      input.SrvInfo.UUID,
 		MongoConf: mongoConf,
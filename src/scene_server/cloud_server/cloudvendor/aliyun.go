@@ -0,0 +1,340 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+)
+
+func init() {
+	Register(metadata.Aliyun, &aliyunClient{vendorName: metadata.Aliyun})
+}
+
+// aliyunClient 阿里云客户端，阿里云的go sdk依赖较多，这里直接使用RPC风格的OpenAPI
+type aliyunClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	// ecsEndpoint和vpcEndpoint用于专有云等需要指定接入地址的场景，默认为公有云的地址
+	ecsEndpoint string
+	vpcEndpoint string
+}
+
+const (
+	aliyunMinPageSize int64 = 1
+	aliyunMaxPageSize int64 = 100
+
+	aliyunEcsEndpoint = "https://ecs.aliyuncs.com"
+	aliyunVpcEndpoint = "https://vpc.aliyuncs.com"
+	aliyunEcsVersion  = "2014-05-26"
+	aliyunVpcVersion  = "2016-04-28"
+)
+
+// NewVendorClient 创建云厂商客户端
+func (c *aliyunClient) NewVendorClient(conf metadata.CloudAccountConf) VendorClient {
+	client := &aliyunClient{
+		vendorName:  metadata.Aliyun,
+		secretID:    conf.SecretID,
+		secretKey:   conf.SecretKey,
+		ecsEndpoint: aliyunEcsEndpoint,
+		vpcEndpoint: aliyunVpcEndpoint,
+	}
+	if conf.Endpoint != "" {
+		client.ecsEndpoint = strings.TrimSuffix(conf.Endpoint, "/")
+		client.vpcEndpoint = client.ecsEndpoint
+	}
+	return client
+}
+
+type aliyunRegionsResp struct {
+	Regions struct {
+		Region []struct {
+			RegionId  string `json:"RegionId"`
+			LocalName string `json:"LocalName"`
+			Status    string `json:"Status"`
+		} `json:"Region"`
+	} `json:"Regions"`
+}
+
+// GetRegions 获取地域列表
+// API文档：https://help.aliyun.com/document_detail/25609.html
+func (c *aliyunClient) GetRegions() ([]*metadata.Region, error) {
+	resp := new(aliyunRegionsResp)
+	if err := c.call(c.ecsEndpoint, aliyunEcsVersion, "DescribeRegions", nil, resp); err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*metadata.Region, 0)
+	for _, region := range resp.Regions.Region {
+		regionSet = append(regionSet, &metadata.Region{
+			RegionId:    region.RegionId,
+			RegionName:  region.LocalName,
+			RegionState: region.Status,
+		})
+	}
+
+	return regionSet, nil
+}
+
+type aliyunVpcsResp struct {
+	TotalCount int64 `json:"TotalCount"`
+	Vpcs       struct {
+		Vpc []struct {
+			VpcId   string `json:"VpcId"`
+			VpcName string `json:"VpcName"`
+		} `json:"Vpc"`
+	} `json:"Vpcs"`
+}
+
+// GetVpcs 获取vpc列表
+// API文档：https://help.aliyun.com/document_detail/35739.html
+func (c *aliyunClient) GetVpcs(region string, opt *ccom.VpcOpt) (*metadata.VpcsInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultVpcOpt()
+	}
+	vpcsInfo := new(metadata.VpcsInfo)
+	loopCnt := 0
+	var totalCnt int64 = 0
+	params := c.newFilterParams(region, opt.Filters)
+	params.Set("PageSize", strconv.FormatInt(c.pageSize(opt.Limit), 10))
+	// 在limit小于全部数据量的情况下，获取limit数量的数据，否则获取全部数据
+	for pageNumber := 1; ; pageNumber++ {
+		params.Set("PageNumber", strconv.Itoa(pageNumber))
+		resp := new(aliyunVpcsResp)
+		if err := c.call(c.vpcEndpoint, aliyunVpcVersion, "DescribeVpcs", params, resp); err != nil {
+			return nil, err
+		}
+		for _, vpc := range resp.Vpcs.Vpc {
+			vpcsInfo.VpcSet = append(vpcsInfo.VpcSet, &metadata.Vpc{
+				VpcId:   vpc.VpcId,
+				VpcName: vpc.VpcName,
+			})
+		}
+		totalCnt = resp.TotalCount
+		// 在获取到limit数量或者全部数据的情况下，退出循环
+		if opt.Limit <= int64(len(vpcsInfo.VpcSet)) || int64(len(vpcsInfo.VpcSet)) >= totalCnt ||
+			len(resp.Vpcs.Vpc) == 0 {
+			break
+		}
+		loopCnt++
+		if loopCnt > ccom.MaxLoopCnt {
+			blog.Errorf("DescribeVpcs loopCnt:%d, bigger than MaxLoopCnt, TotalCount:%d", loopCnt, totalCnt)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	vpcsInfo.Count = totalCnt
+
+	return vpcsInfo, nil
+}
+
+type aliyunIPAddress struct {
+	IpAddress []string `json:"IpAddress"`
+}
+
+type aliyunInstancesResp struct {
+	TotalCount int64 `json:"TotalCount"`
+	Instances  struct {
+		Instance []struct {
+			InstanceId      string          `json:"InstanceId"`
+			Status          string          `json:"Status"`
+			PublicIpAddress aliyunIPAddress `json:"PublicIpAddress"`
+			InnerIpAddress  aliyunIPAddress `json:"InnerIpAddress"`
+			EipAddress      struct {
+				IpAddress string `json:"IpAddress"`
+			} `json:"EipAddress"`
+			VpcAttributes struct {
+				VpcId            string          `json:"VpcId"`
+				PrivateIpAddress aliyunIPAddress `json:"PrivateIpAddress"`
+			} `json:"VpcAttributes"`
+		} `json:"Instance"`
+	} `json:"Instances"`
+}
+
+// GetInstances 获取实例列表
+// API文档：https://help.aliyun.com/document_detail/25506.html
+func (c *aliyunClient) GetInstances(region string, opt *ccom.InstanceOpt) (*metadata.InstancesInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	instancesInfo := new(metadata.InstancesInfo)
+	loopCnt := 0
+	var totalCnt int64 = 0
+	params := c.newFilterParams(region, opt.Filters)
+	params.Set("PageSize", strconv.FormatInt(c.pageSize(opt.Limit), 10))
+	// 在limit小于全部数据量的情况下，获取limit数量的数据，否则获取全部数据
+	for pageNumber := 1; ; pageNumber++ {
+		params.Set("PageNumber", strconv.Itoa(pageNumber))
+		resp := new(aliyunInstancesResp)
+		if err := c.call(c.ecsEndpoint, aliyunEcsVersion, "DescribeInstances", params, resp); err != nil {
+			return nil, err
+		}
+
+		for _, inst := range resp.Instances.Instance {
+			privateIP := ""
+			if len(inst.VpcAttributes.PrivateIpAddress.IpAddress) > 0 {
+				privateIP = inst.VpcAttributes.PrivateIpAddress.IpAddress[0]
+			} else if len(inst.InnerIpAddress.IpAddress) > 0 {
+				privateIP = inst.InnerIpAddress.IpAddress[0]
+			}
+
+			publicIP := inst.EipAddress.IpAddress
+			if publicIP == "" && len(inst.PublicIpAddress.IpAddress) > 0 {
+				publicIP = inst.PublicIpAddress.IpAddress[0]
+			}
+
+			instancesInfo.InstanceSet = append(instancesInfo.InstanceSet, &metadata.Instance{
+				InstanceId:    inst.InstanceId,
+				PrivateIp:     privateIP,
+				PublicIp:      publicIP,
+				InstanceState: ccom.CovertInstState(inst.Status),
+				VpcId:         inst.VpcAttributes.VpcId,
+			})
+		}
+
+		totalCnt = resp.TotalCount
+		// 在获取到limit数量或者全部数据的情况下，退出循环
+		if opt.Limit <= int64(len(instancesInfo.InstanceSet)) || int64(len(instancesInfo.InstanceSet)) >= totalCnt ||
+			len(resp.Instances.Instance) == 0 {
+			break
+		}
+		loopCnt++
+		if loopCnt > ccom.MaxLoopCnt {
+			blog.Errorf("DescribeInstances loopCnt:%d, bigger than MaxLoopCnt, TotalCount:%d", loopCnt, totalCnt)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	instancesInfo.Count = totalCnt
+
+	return instancesInfo, nil
+}
+
+// GetInstancesTotalCnt 获取实例总个数
+func (c *aliyunClient) GetInstancesTotalCnt(region string, opt *ccom.InstanceOpt) (int64, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	// 直接将limit设为最小值，能最快地获取到实例总个数
+	opt.Limit = aliyunMinPageSize
+	instsInfo, err := c.GetInstances(region, opt)
+	if err != nil {
+		return 0, err
+	}
+	return instsInfo.Count, nil
+}
+
+// pageSize 按API要求，PageSize的取值范围为1～100，不在该范围的设为最大值
+func (c *aliyunClient) pageSize(limit int64) int64 {
+	if limit < aliyunMinPageSize || limit > aliyunMaxPageSize {
+		return aliyunMaxPageSize
+	}
+	return limit
+}
+
+// newFilterParams 设置地域和过滤条件，过滤条件vpc-id转为阿里云的参数VpcId
+func (c *aliyunClient) newFilterParams(region string, filters []*ccom.Filter) url.Values {
+	params := url.Values{}
+	params.Set("RegionId", region)
+	if vpcID := getVpcIDFilter(filters); vpcID != "" {
+		params.Set("VpcId", vpcID)
+	}
+	return params
+}
+
+// call 调用阿里云RPC风格的接口
+func (c *aliyunClient) call(endpoint, version, action string, params url.Values, result interface{}) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	query.Set("Action", action)
+	query.Set("Version", version)
+	query.Set("Format", "JSON")
+	query.Set("AccessKeyId", c.secretID)
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("SignatureVersion", "1.0")
+	query.Set("SignatureNonce", hex.EncodeToString(nonce))
+	query.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	query.Set("Signature", aliyunSignature(http.MethodGet, query, c.secretKey))
+
+	req, err := http.NewRequest(http.MethodGet, endpoint+"/?"+aliyunCanonicalQuery(query), nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := doVendorRequest(req, result); err != nil {
+		// 阿里云鉴权失败时返回的是400或404，需要根据错误码识别
+		if strings.Contains(err.Error(), "InvalidAccessKeyId") || strings.Contains(err.Error(),
+			"SignatureDoesNotMatch") {
+			return fmt.Errorf("AuthFailure: %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// aliyunSignature 计算阿里云RPC接口的签名
+// 文档：https://help.aliyun.com/document_detail/315526.html
+func aliyunSignature(method string, query url.Values, secretKey string) string {
+	stringToSign := method + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(aliyunCanonicalQuery(query))
+	mac := hmac.New(sha1.New, []byte(secretKey+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunCanonicalQuery 将请求参数按参数名排序并编码
+func aliyunCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, aliyunPercentEncode(key)+"="+aliyunPercentEncode(query.Get(key)))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// aliyunPercentEncode 按RFC3986编码，和url.QueryEscape的区别在于空格、*和~的处理
+func aliyunPercentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	s = strings.ReplaceAll(s, "%7E", "~")
+	return s
+}
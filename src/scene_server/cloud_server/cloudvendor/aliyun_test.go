@@ -0,0 +1,227 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliyunTestSecretID  = "test-access-key-id"
+	aliyunTestSecretKey = "test-access-key-secret"
+)
+
+func TestAliyunSignature(t *testing.T) {
+	// 签名文档中的示例
+	query := url.Values{}
+	query.Set("Action", "DescribeRegions")
+	query.Set("Format", "XML")
+	query.Set("Version", "2014-05-26")
+	query.Set("AccessKeyId", "testid")
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("Timestamp", "2016-02-23T12:46:24Z")
+	query.Set("SignatureVersion", "1.0")
+	query.Set("SignatureNonce", "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf")
+
+	require.Equal(t, "OLeaidS1JvxuMvnyHOwuJ+uX5qY=", aliyunSignature("GET", query, "testsecret"))
+}
+
+func TestAliyunPercentEncode(t *testing.T) {
+	require.Equal(t, "a%20b%2Ac~d%2F", aliyunPercentEncode("a b*c~d/"))
+}
+
+// aliyunTestPage 按PageNumber和PageSize参数返回当前页的数据
+func aliyunTestPage[T any](query url.Values, items []T) []T {
+	pageSize, _ := strconv.Atoi(query.Get("PageSize"))
+	pageNumber, _ := strconv.Atoi(query.Get("PageNumber"))
+	start := (pageNumber - 1) * pageSize
+	if start < 0 || start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// newAliyunStub 模拟阿里云ECS、VPC的RPC接口的服务，校验请求的签名
+func newAliyunStub(t *testing.T) *httptest.Server {
+	vpcs := []map[string]string{
+		{"VpcId": "vpc-1", "VpcName": "prod"},
+		{"VpcId": "vpc-2", "VpcName": "test"},
+		{"VpcId": "vpc-3", "VpcName": "dev"},
+	}
+	instances := []map[string]interface{}{
+		{"InstanceId": "i-1", "Status": "Running", "EipAddress": map[string]string{"IpAddress": "47.0.0.1"},
+			"PublicIpAddress": map[string][]string{"IpAddress": {"47.0.0.11"}},
+			"VpcAttributes": map[string]interface{}{"VpcId": "vpc-1",
+				"PrivateIpAddress": map[string][]string{"IpAddress": {"172.16.0.10"}}}},
+		{"InstanceId": "i-2", "Status": "Stopped", "PublicIpAddress": map[string][]string{"IpAddress": {"47.0.0.2"}},
+			"VpcAttributes": map[string]interface{}{"VpcId": "vpc-2",
+				"PrivateIpAddress": map[string][]string{"IpAddress": {"172.16.1.10"}}}},
+		{"InstanceId": "i-3", "Status": "Starting", "InnerIpAddress": map[string][]string{"IpAddress": {"10.0.0.3"}}},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("AccessKeyId") != aliyunTestSecretID {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"Code":"InvalidAccessKeyId.NotFound"}`))
+			return
+		}
+		signature := query.Get("Signature")
+		query.Del("Signature")
+		if signature != aliyunSignature(r.Method, query, aliyunTestSecretKey) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"SignatureDoesNotMatch"}`))
+			return
+		}
+
+		switch query.Get("Action") {
+		case "DescribeRegions":
+			w.Write([]byte(`{"Regions":{"Region":[{"RegionId":"cn-hangzhou","LocalName":"华东1（杭州）",
+				"Status":"available"},{"RegionId":"cn-beijing","LocalName":"华北2（北京）","Status":"available"}]}}`))
+		case "DescribeVpcs":
+			matched := make([]map[string]string, 0)
+			for _, vpc := range vpcs {
+				if vpcID := query.Get("VpcId"); vpcID == "" || vpc["VpcId"] == vpcID {
+					matched = append(matched, vpc)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"TotalCount": len(matched),
+				"Vpcs": map[string]interface{}{"Vpc": aliyunTestPage(query, matched)}})
+		case "DescribeInstances":
+			matched := make([]map[string]interface{}, 0)
+			for _, inst := range instances {
+				vpc, _ := inst["VpcAttributes"].(map[string]interface{})
+				if vpcID := query.Get("VpcId"); vpcID == "" || (vpc != nil && vpc["VpcId"] == vpcID) {
+					matched = append(matched, inst)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"TotalCount": len(matched),
+				"Instances": map[string]interface{}{"Instance": aliyunTestPage(query, matched)}})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"InvalidAction.NotFound"}`))
+		}
+	}))
+}
+
+func newAliyunTestClient(t *testing.T, endpoint, secretKey string) VendorClient {
+	client, err := GetVendorClient(metadata.CloudAccountConf{
+		VendorName: metadata.Aliyun,
+		SecretID:   aliyunTestSecretID,
+		SecretKey:  secretKey,
+		Endpoint:   endpoint,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestAliyunGetRegions(t *testing.T) {
+	server := newAliyunStub(t)
+	defer server.Close()
+
+	client := newAliyunTestClient(t, server.URL+"/", aliyunTestSecretKey)
+	regions, err := client.GetRegions()
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	require.Equal(t, metadata.Region{RegionId: "cn-hangzhou", RegionName: "华东1（杭州）", RegionState: "available"},
+		*regions[0])
+	require.Equal(t, "cn-beijing", regions[1].RegionId)
+}
+
+func TestAliyunAuthFailure(t *testing.T) {
+	server := newAliyunStub(t)
+	defer server.Close()
+
+	client := newAliyunTestClient(t, server.URL, "wrong-secret")
+	_, err := client.GetRegions()
+	require.Error(t, err)
+	require.Contains(t, strings.ToLower(err.Error()), "authfailure")
+}
+
+func TestAliyunGetVpcs(t *testing.T) {
+	server := newAliyunStub(t)
+	defer server.Close()
+
+	client := newAliyunTestClient(t, server.URL, aliyunTestSecretKey)
+	vpcsInfo, err := client.GetVpcs("cn-hangzhou", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, vpcsInfo.Count)
+	require.Len(t, vpcsInfo.VpcSet, 3)
+	require.Equal(t, metadata.Vpc{VpcId: "vpc-1", VpcName: "prod"}, *vpcsInfo.VpcSet[0])
+
+	// 每页获取2个，获取全部数据需要翻页
+	vpcsInfo, err = client.GetVpcs("cn-hangzhou", &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: 2}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, vpcsInfo.Count)
+	require.Len(t, vpcsInfo.VpcSet, 2)
+
+	opt := &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: ccom.MaxLimit, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"vpc-3"}),
+	}}}}
+	vpcsInfo, err = client.GetVpcs("cn-hangzhou", opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, vpcsInfo.Count)
+	require.Equal(t, "dev", vpcsInfo.VpcSet[0].VpcName)
+}
+
+func TestAliyunGetInstances(t *testing.T) {
+	server := newAliyunStub(t)
+	defer server.Close()
+
+	client := newAliyunTestClient(t, server.URL, aliyunTestSecretKey)
+	instsInfo, err := client.GetInstances("cn-hangzhou", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 3)
+	// 优先使用弹性公网ip和vpc的私有ip
+	require.Equal(t, metadata.Instance{InstanceId: "i-1", PrivateIp: "172.16.0.10", PublicIp: "47.0.0.1",
+		InstanceState: common.BKCloudHostStatusRunning, VpcId: "vpc-1"}, *instsInfo.InstanceSet[0])
+	require.Equal(t, metadata.Instance{InstanceId: "i-2", PrivateIp: "172.16.1.10", PublicIp: "47.0.0.2",
+		InstanceState: common.BKCloudHostStatusStopped, VpcId: "vpc-2"}, *instsInfo.InstanceSet[1])
+	require.Equal(t, metadata.Instance{InstanceId: "i-3", PrivateIp: "10.0.0.3",
+		InstanceState: common.BKCloudHostStatusStarting}, *instsInfo.InstanceSet[2])
+
+	opt := &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{Limit: ccom.MaxLimit, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"vpc-2"}),
+	}}}}
+	instsInfo, err = client.GetInstances("cn-hangzhou", opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, instsInfo.Count)
+	require.Equal(t, "i-2", instsInfo.InstanceSet[0].InstanceId)
+
+	cnt, err := client.GetInstancesTotalCnt("cn-hangzhou", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, cnt)
+}
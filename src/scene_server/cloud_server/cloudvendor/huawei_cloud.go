@@ -0,0 +1,376 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+)
+
+func init() {
+	Register(metadata.HuaweiCloud, &huaweiClient{vendorName: metadata.HuaweiCloud})
+}
+
+// huaweiClient 华为云客户端，使用AK/SK签名调用华为云的OpenAPI
+type huaweiClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	// domain 华为云服务的域名后缀，各服务的接入地址为https://{service}.{region}.{domain}，用于华为云Stack等场景
+	domain string
+	// projectIDs 地域和项目id的映射，ecs和vpc的接口需要使用地域对应的项目id
+	projectIDs map[string]string
+}
+
+const (
+	huaweiMinPageSize int64 = 1
+	huaweiMaxPageSize int64 = 1000
+
+	huaweiDefaultDomain = "myhuaweicloud.com"
+	huaweiSignAlgorithm = "SDK-HMAC-SHA256"
+	huaweiDateFormat    = "20060102T150405Z"
+	huaweiDateHeader    = "X-Sdk-Date"
+)
+
+// NewVendorClient 创建云厂商客户端
+func (c *huaweiClient) NewVendorClient(conf metadata.CloudAccountConf) VendorClient {
+	client := &huaweiClient{
+		vendorName: metadata.HuaweiCloud,
+		secretID:   conf.SecretID,
+		secretKey:  conf.SecretKey,
+		domain:     huaweiDefaultDomain,
+		projectIDs: make(map[string]string),
+	}
+	if conf.Endpoint != "" {
+		client.domain = strings.Trim(conf.Endpoint, "/")
+	}
+	return client
+}
+
+type huaweiRegionsResp struct {
+	Regions []struct {
+		ID      string            `json:"id"`
+		Locales map[string]string `json:"locales"`
+	} `json:"regions"`
+}
+
+// GetRegions 获取地域列表
+// API文档：https://support.huaweicloud.com/api-iam/iam_05_0001.html
+func (c *huaweiClient) GetRegions() ([]*metadata.Region, error) {
+	resp := new(huaweiRegionsResp)
+	if err := c.get(c.iamEndpoint(), "/v3/regions", nil, resp); err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*metadata.Region, 0)
+	for _, region := range resp.Regions {
+		name := region.Locales["zh-cn"]
+		if name == "" {
+			name = region.ID
+		}
+		regionSet = append(regionSet, &metadata.Region{
+			RegionId:    region.ID,
+			RegionName:  name,
+			RegionState: "available",
+		})
+	}
+
+	return regionSet, nil
+}
+
+type huaweiVpcsResp struct {
+	Vpcs []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"vpcs"`
+}
+
+// GetVpcs 获取vpc列表
+// API文档：https://support.huaweicloud.com/api-vpc/vpc_api01_0003.html
+func (c *huaweiClient) GetVpcs(region string, opt *ccom.VpcOpt) (*metadata.VpcsInfo, error) {
+	projectID, err := c.getProjectID(region)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt == nil {
+		opt = ccom.GetDefaultVpcOpt()
+	}
+	vpcsInfo := new(metadata.VpcsInfo)
+	loopCnt := 0
+	pageSize := c.pageSize(opt.Limit)
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(pageSize, 10))
+	if vpcID := getVpcIDFilter(opt.Filters); vpcID != "" {
+		query.Set("id", vpcID)
+	}
+	// 接口不返回总数，通过marker分页获取全部数据
+	for {
+		resp := new(huaweiVpcsResp)
+		if err := c.get(c.serviceEndpoint("vpc", region), "/v1/"+projectID+"/vpcs", query, resp); err != nil {
+			return nil, err
+		}
+		for _, vpc := range resp.Vpcs {
+			vpcsInfo.VpcSet = append(vpcsInfo.VpcSet, &metadata.Vpc{
+				VpcId:   vpc.ID,
+				VpcName: vpc.Name,
+			})
+		}
+		if opt.Limit <= int64(len(vpcsInfo.VpcSet)) || int64(len(resp.Vpcs)) < pageSize {
+			break
+		}
+		query.Set("marker", resp.Vpcs[len(resp.Vpcs)-1].ID)
+		loopCnt++
+		if loopCnt > ccom.MaxLoopCnt {
+			blog.Errorf("ListVpcs loopCnt:%d, bigger than MaxLoopCnt", loopCnt)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	vpcsInfo.Count = int64(len(vpcsInfo.VpcSet))
+
+	return vpcsInfo, nil
+}
+
+type huaweiServersResp struct {
+	Count   int64 `json:"count"`
+	Servers []struct {
+		ID        string `json:"id"`
+		Status    string `json:"status"`
+		Addresses map[string][]struct {
+			Addr    string `json:"addr"`
+			Version string `json:"version"`
+			Type    string `json:"OS-EXT-IPS:type"`
+		} `json:"addresses"`
+		Metadata map[string]string `json:"metadata"`
+	} `json:"servers"`
+}
+
+// GetInstances 获取实例列表
+// API文档：https://support.huaweicloud.com/api-ecs/ecs_02_0103.html
+func (c *huaweiClient) GetInstances(region string, opt *ccom.InstanceOpt) (*metadata.InstancesInfo, error) {
+	projectID, err := c.getProjectID(region)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	// 接口不支持按vpc过滤，需要获取全部实例后再过滤，此时实例总数为过滤后的个数
+	vpcFilter := getVpcIDFilter(opt.Filters)
+	pageSize := c.pageSize(opt.Limit)
+	if vpcFilter != "" {
+		pageSize = huaweiMaxPageSize
+	}
+
+	instancesInfo := new(metadata.InstancesInfo)
+	var matchedCnt, scannedCnt int64
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(pageSize, 10))
+	// offset为页码，从1开始
+	for page := 1; ; page++ {
+		query.Set("offset", strconv.Itoa(page))
+		resp := new(huaweiServersResp)
+		err := c.get(c.serviceEndpoint("ecs", region), "/v1/"+projectID+"/cloudservers/detail", query, resp)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range resp.Servers {
+			vpcID := inst.Metadata["vpc_id"]
+			privateIP, publicIP := "", ""
+			for addrVpcID, addresses := range inst.Addresses {
+				if vpcID == "" {
+					vpcID = addrVpcID
+				}
+				for _, addr := range addresses {
+					if addr.Type == "floating" && publicIP == "" {
+						publicIP = addr.Addr
+					}
+					if addr.Type == "fixed" && privateIP == "" && addr.Version == "4" {
+						privateIP = addr.Addr
+					}
+				}
+			}
+
+			if vpcFilter != "" && vpcID != vpcFilter {
+				continue
+			}
+			matchedCnt++
+			if int64(len(instancesInfo.InstanceSet)) >= opt.Limit {
+				continue
+			}
+			instancesInfo.InstanceSet = append(instancesInfo.InstanceSet, &metadata.Instance{
+				InstanceId:    inst.ID,
+				PrivateIp:     privateIP,
+				PublicIp:      publicIP,
+				InstanceState: ccom.CovertInstState(inst.Status),
+				VpcId:         vpcID,
+			})
+		}
+
+		scannedCnt += int64(len(resp.Servers))
+		if vpcFilter == "" {
+			matchedCnt = resp.Count
+		}
+		// 在获取到limit数量或者全部数据的情况下，退出循环，有vpc过滤条件时需要遍历全部数据以获取总数
+		if (vpcFilter == "" && opt.Limit <= int64(len(instancesInfo.InstanceSet))) || scannedCnt >= resp.Count ||
+			len(resp.Servers) == 0 {
+			break
+		}
+		if page > ccom.MaxLoopCnt {
+			blog.Errorf("ListServersDetails loopCnt:%d, bigger than MaxLoopCnt, TotalCount:%d", page, resp.Count)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	instancesInfo.Count = matchedCnt
+
+	return instancesInfo, nil
+}
+
+// GetInstancesTotalCnt 获取实例总个数
+func (c *huaweiClient) GetInstancesTotalCnt(region string, opt *ccom.InstanceOpt) (int64, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	// 直接将limit设为最小值，能最快地获取到实例总个数
+	opt.Limit = huaweiMinPageSize
+	instsInfo, err := c.GetInstances(region, opt)
+	if err != nil {
+		return 0, err
+	}
+	return instsInfo.Count, nil
+}
+
+type huaweiProjectsResp struct {
+	Projects []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"projects"`
+}
+
+// getProjectID 获取地域对应的项目id
+// API文档：https://support.huaweicloud.com/api-iam/iam_06_0001.html
+func (c *huaweiClient) getProjectID(region string) (string, error) {
+	if projectID, exists := c.projectIDs[region]; exists {
+		return projectID, nil
+	}
+
+	query := url.Values{}
+	query.Set("name", region)
+	resp := new(huaweiProjectsResp)
+	if err := c.get(c.iamEndpoint(), "/v3/projects", query, resp); err != nil {
+		return "", err
+	}
+	for _, project := range resp.Projects {
+		if project.Name == region {
+			c.projectIDs[region] = project.ID
+			return project.ID, nil
+		}
+	}
+	return "", fmt.Errorf("project of region %s is not found", region)
+}
+
+// pageSize 按API要求，limit的取值范围为1～1000，不在该范围的设为最大值
+func (c *huaweiClient) pageSize(limit int64) int64 {
+	if limit < huaweiMinPageSize || limit > huaweiMaxPageSize {
+		return huaweiMaxPageSize
+	}
+	return limit
+}
+
+func (c *huaweiClient) iamEndpoint() string {
+	return "https://iam." + c.domain
+}
+
+func (c *huaweiClient) serviceEndpoint(service, region string) string {
+	return fmt.Sprintf("https://%s.%s.%s", service, region, c.domain)
+}
+
+// get 发送签名后的GET请求
+func (c *huaweiClient) get(endpoint, path string, query url.Values, result interface{}) error {
+	rawURL := endpoint + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	huaweiSign(req, c.secretID, c.secretKey, time.Now())
+
+	_, err = doVendorRequest(req, result)
+	return err
+}
+
+// huaweiSign 使用AK/SK对没有请求体的请求签名
+// 文档：https://support.huaweicloud.com/devg-apisign/api-sign-algorithm.html
+func huaweiSign(req *http.Request, ak, sk string, now time.Time) {
+	date := now.UTC().Format(huaweiDateFormat)
+	req.Header.Set(huaweiDateHeader, date)
+
+	signedHeaders := []string{"host", strings.ToLower(huaweiDateHeader)}
+	canonicalHeaders := "host:" + req.URL.Host + "\n" + strings.ToLower(huaweiDateHeader) + ":" + date + "\n"
+
+	canonicalURI := req.URL.EscapedPath()
+	if !strings.HasSuffix(canonicalURI, "/") {
+		canonicalURI += "/"
+	}
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, huaweiEscape(key)+"="+huaweiEscape(query.Get(key)))
+	}
+
+	emptyBodyHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{req.Method, canonicalURI, strings.Join(pairs, "&"), canonicalHeaders,
+		strings.Join(signedHeaders, ";"), hex.EncodeToString(emptyBodyHash[:])}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := huaweiSignAlgorithm + "\n" + date + "\n" + hex.EncodeToString(requestHash[:])
+
+	mac := hmac.New(sha256.New, []byte(sk))
+	mac.Write([]byte(stringToSign))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Access=%s, SignedHeaders=%s, Signature=%s", huaweiSignAlgorithm,
+		ak, strings.Join(signedHeaders, ";"), signature))
+}
+
+// huaweiEscape 按RFC3986编码
+func huaweiEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,247 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	huaweiTestSecretID  = "test-ak"
+	huaweiTestSecretKey = "test-sk"
+	huaweiTestDomain    = "huawei.test"
+	huaweiTestRegion    = "cn-north-4"
+	huaweiTestProjectID = "project-1"
+	// huaweiTestVpcCnt 超过一页的vpc个数，用于验证通过marker翻页
+	huaweiTestVpcCnt = 1001
+)
+
+// newHuaweiStub 模拟华为云IAM、VPC、ECS接口的服务，校验请求的签名
+func newHuaweiStub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	handle := func(host, path string, handler func(w http.ResponseWriter, r *http.Request)) {
+		mux.HandleFunc(host+"."+huaweiTestDomain+path, func(w http.ResponseWriter, r *http.Request) {
+			date, err := time.Parse(huaweiDateFormat, r.Header.Get(huaweiDateHeader))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			req, _ := http.NewRequest(r.Method, "https://"+r.Host+r.URL.RequestURI(), nil)
+			huaweiSign(req, huaweiTestSecretID, huaweiTestSecretKey, date)
+			if req.Header.Get("Authorization") != r.Header.Get("Authorization") {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error_msg":"Incorrect IAM authentication information","error_code":"APIGW.0301"}`))
+				return
+			}
+			handler(w, r)
+		})
+	}
+
+	handle("iam", "/v3/regions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"regions":[{"id":"cn-north-4","locales":{"zh-cn":"华北-北京四","en-us":"CN North-Beijing4"}},
+			{"id":"ap-southeast-1","locales":{"en-us":"AP-Hong Kong"}}]}`))
+	})
+
+	handle("iam", "/v3/projects", func(w http.ResponseWriter, r *http.Request) {
+		projects := make([]map[string]string, 0)
+		if r.URL.Query().Get("name") == huaweiTestRegion {
+			projects = append(projects, map[string]string{"id": huaweiTestProjectID, "name": huaweiTestRegion})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"projects": projects})
+	})
+
+	vpcs := make([]map[string]string, huaweiTestVpcCnt)
+	for idx := range vpcs {
+		vpcs[idx] = map[string]string{"id": fmt.Sprintf("vpc-%d", idx), "name": fmt.Sprintf("vpc %d", idx)}
+	}
+	handle("vpc."+huaweiTestRegion, "/v1/"+huaweiTestProjectID+"/vpcs", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		matched := make([]map[string]string, 0)
+		started := query.Get("marker") == ""
+		for _, vpc := range vpcs {
+			if !started {
+				started = vpc["id"] == query.Get("marker")
+				continue
+			}
+			if id := query.Get("id"); id != "" && vpc["id"] != id {
+				continue
+			}
+			if len(matched) < limit {
+				matched = append(matched, vpc)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"vpcs": matched})
+	})
+
+	servers := []string{
+		`{"id":"server-1","status":"ACTIVE","metadata":{"vpc_id":"vpc-1"},"addresses":{"vpc-1":[
+			{"addr":"192.168.0.10","version":"4","OS-EXT-IPS:type":"fixed"},
+			{"addr":"121.0.0.1","version":"4","OS-EXT-IPS:type":"floating"}]}}`,
+		`{"id":"server-2","status":"SHUTOFF","addresses":{"vpc-2":[
+			{"addr":"fe80::2","version":"6","OS-EXT-IPS:type":"fixed"},
+			{"addr":"192.168.1.10","version":"4","OS-EXT-IPS:type":"fixed"}]}}`,
+		`{"id":"server-3","status":"BUILD","metadata":{"vpc_id":"vpc-1"},"addresses":{"vpc-1":[
+			{"addr":"192.168.0.11","version":"4","OS-EXT-IPS:type":"fixed"}]}}`,
+	}
+	handle("ecs."+huaweiTestRegion, "/v1/"+huaweiTestProjectID+"/cloudservers/detail",
+		func(w http.ResponseWriter, r *http.Request) {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			page, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			start, end := (page-1)*limit, page*limit
+			if start > len(servers) {
+				start = len(servers)
+			}
+			if end > len(servers) {
+				end = len(servers)
+			}
+			w.Write([]byte(fmt.Sprintf(`{"count":%d,"servers":[%s]}`, len(servers),
+				strings.Join(servers[start:end], ","))))
+		})
+
+	return httptest.NewTLSServer(mux)
+}
+
+// newHuaweiTestClient 华为云各服务的接入地址由服务名、地域和域名拼接而成，因此将厂商http客户端的请求都发送到模拟服务
+func newHuaweiTestClient(t *testing.T, server *httptest.Server, secretKey string) VendorClient {
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	// 模拟服务的证书签发给example.com
+	transport.TLSClientConfig.ServerName = "example.com"
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return new(net.Dialer).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	originClient := vendorHTTPClient
+	vendorHTTPClient = &http.Client{Transport: transport, Timeout: originClient.Timeout}
+	t.Cleanup(func() {
+		vendorHTTPClient = originClient
+	})
+
+	client, err := GetVendorClient(metadata.CloudAccountConf{
+		VendorName: metadata.HuaweiCloud,
+		SecretID:   huaweiTestSecretID,
+		SecretKey:  secretKey,
+		Endpoint:   huaweiTestDomain,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestHuaweiGetRegions(t *testing.T) {
+	server := newHuaweiStub(t)
+	defer server.Close()
+
+	client := newHuaweiTestClient(t, server, huaweiTestSecretKey)
+	regions, err := client.GetRegions()
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	require.Equal(t, metadata.Region{RegionId: "cn-north-4", RegionName: "华北-北京四", RegionState: "available"},
+		*regions[0])
+	// 没有中文名称的地域使用地域id作为名称
+	require.Equal(t, "ap-southeast-1", regions[1].RegionName)
+}
+
+func TestHuaweiAuthFailure(t *testing.T) {
+	server := newHuaweiStub(t)
+	defer server.Close()
+
+	client := newHuaweiTestClient(t, server, "wrong-sk")
+	_, err := client.GetRegions()
+	require.Error(t, err)
+	require.Contains(t, strings.ToLower(err.Error()), "authfailure")
+}
+
+func TestHuaweiGetVpcs(t *testing.T) {
+	server := newHuaweiStub(t)
+	defer server.Close()
+
+	client := newHuaweiTestClient(t, server, huaweiTestSecretKey)
+	vpcsInfo, err := client.GetVpcs(huaweiTestRegion, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, huaweiTestVpcCnt, vpcsInfo.Count)
+	require.Equal(t, metadata.Vpc{VpcId: "vpc-0", VpcName: "vpc 0"}, *vpcsInfo.VpcSet[0])
+	require.Equal(t, "vpc-1000", vpcsInfo.VpcSet[huaweiTestVpcCnt-1].VpcId)
+
+	vpcsInfo, err = client.GetVpcs(huaweiTestRegion, &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: 2}})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, vpcsInfo.Count)
+
+	opt := &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: ccom.MaxLimit, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"vpc-7"}),
+	}}}}
+	vpcsInfo, err = client.GetVpcs(huaweiTestRegion, opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, vpcsInfo.Count)
+	require.Equal(t, "vpc 7", vpcsInfo.VpcSet[0].VpcName)
+
+	// 地域没有对应的项目
+	_, err = client.GetVpcs("cn-south-1", nil)
+	require.Error(t, err)
+}
+
+func TestHuaweiGetInstances(t *testing.T) {
+	server := newHuaweiStub(t)
+	defer server.Close()
+
+	client := newHuaweiTestClient(t, server, huaweiTestSecretKey)
+	instsInfo, err := client.GetInstances(huaweiTestRegion, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 3)
+	require.Equal(t, metadata.Instance{InstanceId: "server-1", PrivateIp: "192.168.0.10", PublicIp: "121.0.0.1",
+		InstanceState: common.BKCloudHostStatusRunning, VpcId: "vpc-1"}, *instsInfo.InstanceSet[0])
+	// 没有vpc_id元数据的实例使用网络所属的vpc，私有ip只取ipv4地址
+	require.Equal(t, metadata.Instance{InstanceId: "server-2", PrivateIp: "192.168.1.10",
+		InstanceState: common.BKCloudHostStatusStopped, VpcId: "vpc-2"}, *instsInfo.InstanceSet[1])
+	require.Equal(t, common.BKCloudHostStatusStarting, instsInfo.InstanceSet[2].InstanceState)
+
+	instsInfo, err = client.GetInstances(huaweiTestRegion, &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{Limit: 2}})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 2)
+
+	// 按vpc过滤时实例总数为过滤后的个数
+	opt := &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{Limit: 1, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"vpc-1"}),
+	}}}}
+	instsInfo, err = client.GetInstances(huaweiTestRegion, opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 1)
+	require.Equal(t, "server-1", instsInfo.InstanceSet[0].InstanceId)
+
+	cnt, err := client.GetInstancesTotalCnt(huaweiTestRegion, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, cnt)
+}
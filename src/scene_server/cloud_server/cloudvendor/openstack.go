@@ -0,0 +1,361 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+)
+
+func init() {
+	Register(metadata.OpenStack, &openStackClient{vendorName: metadata.OpenStack})
+}
+
+// openStackClient OpenStack客户端，使用Keystone v3的应用凭据认证，secretID为应用凭据id，secretKey为应用凭据密钥，
+// endpoint为Keystone v3的地址，如http://keystone:5000/v3，计算(Nova)和网络(Neutron)服务的地址从服务目录中获取，
+// OpenStack中的网络(network)对应为vpc
+type openStackClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	endpoint   string
+	// token和catalog在第一次请求时获取
+	token   string
+	catalog []openStackService
+}
+
+const (
+	openStackMinPageSize int64 = 1
+	openStackMaxPageSize int64 = 1000
+
+	openStackTokenHeader = "X-Auth-Token"
+	openStackCompute     = "compute"
+	openStackNetwork     = "network"
+)
+
+// NewVendorClient 创建云厂商客户端
+func (c *openStackClient) NewVendorClient(conf metadata.CloudAccountConf) VendorClient {
+	return &openStackClient{
+		vendorName: metadata.OpenStack,
+		secretID:   conf.SecretID,
+		secretKey:  conf.SecretKey,
+		endpoint:   strings.TrimSuffix(conf.Endpoint, "/"),
+	}
+}
+
+type openStackService struct {
+	Type      string `json:"type"`
+	Endpoints []struct {
+		Interface string `json:"interface"`
+		RegionID  string `json:"region_id"`
+		URL       string `json:"url"`
+	} `json:"endpoints"`
+}
+
+// GetRegions 获取地域列表，即服务目录中提供计算服务的地域
+// API文档：https://docs.openstack.org/api-ref/identity/v3/#password-authentication-with-unscoped-authorization
+func (c *openStackClient) GetRegions() ([]*metadata.Region, error) {
+	if err := c.authenticate(); err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*metadata.Region, 0)
+	exists := make(map[string]bool)
+	for _, service := range c.catalog {
+		if service.Type != openStackCompute {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface != "public" || exists[endpoint.RegionID] {
+				continue
+			}
+			exists[endpoint.RegionID] = true
+			regionSet = append(regionSet, &metadata.Region{
+				RegionId:    endpoint.RegionID,
+				RegionName:  endpoint.RegionID,
+				RegionState: "available",
+			})
+		}
+	}
+
+	return regionSet, nil
+}
+
+type openStackNetworksResp struct {
+	Networks []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"networks"`
+}
+
+// GetVpcs 获取vpc列表
+// API文档：https://docs.openstack.org/api-ref/network/v2/#list-networks
+func (c *openStackClient) GetVpcs(region string, opt *ccom.VpcOpt) (*metadata.VpcsInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultVpcOpt()
+	}
+	endpoint, err := c.serviceEndpoint(openStackNetwork, region)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcsInfo := new(metadata.VpcsInfo)
+	loopCnt := 0
+	pageSize := c.pageSize(opt.Limit)
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(pageSize, 10))
+	if vpcID := getVpcIDFilter(opt.Filters); vpcID != "" {
+		query.Set("id", vpcID)
+	}
+	// 接口不返回总数，通过marker分页获取全部数据
+	for {
+		resp := new(openStackNetworksResp)
+		if err := c.get(endpoint+"/v2.0/networks", query, resp); err != nil {
+			return nil, err
+		}
+		for _, network := range resp.Networks {
+			vpcsInfo.VpcSet = append(vpcsInfo.VpcSet, &metadata.Vpc{
+				VpcId:   network.ID,
+				VpcName: network.Name,
+			})
+		}
+		if opt.Limit <= int64(len(vpcsInfo.VpcSet)) || int64(len(resp.Networks)) < pageSize {
+			break
+		}
+		query.Set("marker", resp.Networks[len(resp.Networks)-1].ID)
+		loopCnt++
+		if loopCnt > ccom.MaxLoopCnt {
+			blog.Errorf("ListNetworks loopCnt:%d, bigger than MaxLoopCnt", loopCnt)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	if int64(len(vpcsInfo.VpcSet)) > opt.Limit {
+		vpcsInfo.VpcSet = vpcsInfo.VpcSet[:opt.Limit]
+	}
+	vpcsInfo.Count = int64(len(vpcsInfo.VpcSet))
+
+	return vpcsInfo, nil
+}
+
+type openStackServersResp struct {
+	Servers []struct {
+		ID        string `json:"id"`
+		Status    string `json:"status"`
+		Addresses map[string][]struct {
+			Addr    string `json:"addr"`
+			Version int    `json:"version"`
+			Type    string `json:"OS-EXT-IPS:type"`
+		} `json:"addresses"`
+	} `json:"servers"`
+}
+
+// GetInstances 获取实例列表
+// API文档：https://docs.openstack.org/api-ref/compute/#list-servers-detailed
+func (c *openStackClient) GetInstances(region string, opt *ccom.InstanceOpt) (*metadata.InstancesInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	endpoint, err := c.serviceEndpoint(openStackCompute, region)
+	if err != nil {
+		return nil, err
+	}
+
+	// 实例的地址是以网络名称为key的，需要转换为网络id
+	vpcsInfo, err := c.GetVpcs(region, nil)
+	if err != nil {
+		return nil, err
+	}
+	networkIDs := make(map[string]string)
+	for _, vpc := range vpcsInfo.VpcSet {
+		networkIDs[vpc.VpcName] = vpc.VpcId
+	}
+
+	// 接口既不返回总数也不支持按网络过滤，需要遍历全部实例获取总数并过滤
+	vpcFilter := getVpcIDFilter(opt.Filters)
+	instancesInfo := new(metadata.InstancesInfo)
+	var matchedCnt int64
+	loopCnt := 0
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(openStackMaxPageSize, 10))
+	for {
+		resp := new(openStackServersResp)
+		if err := c.get(endpoint+"/servers/detail", query, resp); err != nil {
+			return nil, err
+		}
+
+		for _, inst := range resp.Servers {
+			vpcID, privateIP, publicIP := "", "", ""
+			for networkName, addresses := range inst.Addresses {
+				if vpcID == "" {
+					vpcID = networkIDs[networkName]
+				}
+				for _, addr := range addresses {
+					if addr.Type == "floating" && publicIP == "" {
+						publicIP = addr.Addr
+					}
+					if addr.Type != "floating" && privateIP == "" && addr.Version == 4 {
+						privateIP = addr.Addr
+					}
+				}
+			}
+
+			if vpcFilter != "" && vpcID != vpcFilter {
+				continue
+			}
+			matchedCnt++
+			if int64(len(instancesInfo.InstanceSet)) >= opt.Limit {
+				continue
+			}
+			instancesInfo.InstanceSet = append(instancesInfo.InstanceSet, &metadata.Instance{
+				InstanceId:    inst.ID,
+				PrivateIp:     privateIP,
+				PublicIp:      publicIP,
+				InstanceState: ccom.CovertInstState(inst.Status),
+				VpcId:         vpcID,
+			})
+		}
+
+		if int64(len(resp.Servers)) < openStackMaxPageSize {
+			break
+		}
+		query.Set("marker", resp.Servers[len(resp.Servers)-1].ID)
+		loopCnt++
+		if loopCnt > ccom.MaxLoopCnt {
+			blog.Errorf("ListServersDetailed loopCnt:%d, bigger than MaxLoopCnt", loopCnt)
+			return nil, ccom.ErrorLoopCnt
+		}
+	}
+	instancesInfo.Count = matchedCnt
+
+	return instancesInfo, nil
+}
+
+// GetInstancesTotalCnt 获取实例总个数
+func (c *openStackClient) GetInstancesTotalCnt(region string, opt *ccom.InstanceOpt) (int64, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+	// 总数需要遍历全部实例获取，只需返回最少的实例
+	opt.Limit = openStackMinPageSize
+	instsInfo, err := c.GetInstances(region, opt)
+	if err != nil {
+		return 0, err
+	}
+	return instsInfo.Count, nil
+}
+
+// authenticate 使用应用凭据获取token和服务目录
+// API文档：https://docs.openstack.org/api-ref/identity/v3/#authenticating-with-an-application-credential
+func (c *openStackClient) authenticate() error {
+	if c.token != "" {
+		return nil
+	}
+	if c.endpoint == "" {
+		return fmt.Errorf("openstack keystone endpoint is not set")
+	}
+
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"application_credential"},
+				"application_credential": map[string]string{
+					"id":     c.secretID,
+					"secret": c.secretKey,
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+"/auth/tokens", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	result := new(struct {
+		Token struct {
+			Catalog []openStackService `json:"catalog"`
+		} `json:"token"`
+	})
+	resp, err := doVendorRequest(req, result)
+	if err != nil {
+		return err
+	}
+
+	c.token = resp.Header.Get("X-Subject-Token")
+	if c.token == "" {
+		return fmt.Errorf("AuthFailure: openstack keystone returns no token")
+	}
+	c.catalog = result.Token.Catalog
+	return nil
+}
+
+// serviceEndpoint 从服务目录中获取地域下服务的公共访问地址
+func (c *openStackClient) serviceEndpoint(serviceType, region string) (string, error) {
+	if err := c.authenticate(); err != nil {
+		return "", err
+	}
+
+	for _, service := range c.catalog {
+		if service.Type != serviceType {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface == "public" && endpoint.RegionID == region {
+				return strings.TrimSuffix(endpoint.URL, "/"), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("openstack %s service of region %s is not found", serviceType, region)
+}
+
+// pageSize 按API要求，limit最大为1000，不在该范围的设为最大值
+func (c *openStackClient) pageSize(limit int64) int64 {
+	if limit < openStackMinPageSize || limit > openStackMaxPageSize {
+		return openStackMaxPageSize
+	}
+	return limit
+}
+
+// get 发送带有token的GET请求
+func (c *openStackClient) get(rawURL string, query url.Values, result interface{}) error {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(openStackTokenHeader, c.token)
+	req.Header.Set("Accept", "application/json")
+
+	_, err = doVendorRequest(req, result)
+	return err
+}
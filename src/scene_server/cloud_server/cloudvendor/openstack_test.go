@@ -0,0 +1,197 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	openStackTestCredID     = "app-cred-id"
+	openStackTestCredSecret = "app-cred-secret"
+	openStackTestToken      = "test-token"
+)
+
+// newOpenStackStub 模拟Keystone、Nova、Neutron接口的服务
+func newOpenStackStub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	mux.HandleFunc("/identity/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), openStackTestCredID) ||
+			!strings.Contains(string(body), openStackTestCredSecret) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":401,"title":"Unauthorized"}}`))
+			return
+		}
+		w.Header().Set("X-Subject-Token", openStackTestToken)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token":{"catalog":[
+			{"type":"compute","endpoints":[
+				{"interface":"public","region_id":"RegionOne","url":"` + server.URL + `/compute/v2.1/"},
+				{"interface":"internal","region_id":"RegionOne","url":"http://internal/compute/v2.1"},
+				{"interface":"public","region_id":"RegionTwo","url":"` + server.URL + `/compute/v2.1"}]},
+			{"type":"network","endpoints":[
+				{"interface":"public","region_id":"RegionOne","url":"` + server.URL + `/network"}]}]}}`))
+	})
+
+	checkToken := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get(openStackTokenHeader) != openStackTestToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	mux.HandleFunc("/network/v2.0/networks", func(w http.ResponseWriter, r *http.Request) {
+		if !checkToken(w, r) {
+			return
+		}
+		networks := []map[string]string{{"id": "net-1", "name": "private"}, {"id": "net-2", "name": "shared"}}
+		if id := r.URL.Query().Get("id"); id != "" {
+			filtered := make([]map[string]string, 0)
+			for _, network := range networks {
+				if network["id"] == id {
+					filtered = append(filtered, network)
+				}
+			}
+			networks = filtered
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"networks": networks})
+	})
+
+	mux.HandleFunc("/compute/v2.1/servers/detail", func(w http.ResponseWriter, r *http.Request) {
+		if !checkToken(w, r) {
+			return
+		}
+		w.Write([]byte(`{"servers":[
+			{"id":"vm-1","status":"ACTIVE","addresses":{"private":[
+				{"addr":"192.168.0.10","version":4,"OS-EXT-IPS:type":"fixed"},
+				{"addr":"10.0.0.10","version":4,"OS-EXT-IPS:type":"floating"}]}},
+			{"id":"vm-2","status":"SHUTOFF","addresses":{"shared":[
+				{"addr":"fe80::1","version":6,"OS-EXT-IPS:type":"fixed"},
+				{"addr":"172.16.0.2","version":4,"OS-EXT-IPS:type":"fixed"}]}},
+			{"id":"vm-3","status":"BUILD","addresses":{"private":[
+				{"addr":"192.168.0.11","version":4,"OS-EXT-IPS:type":"fixed"}]}}]}`))
+	})
+
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func newOpenStackTestClient(t *testing.T, endpoint, secretKey string) VendorClient {
+	client, err := GetVendorClient(metadata.CloudAccountConf{
+		VendorName: metadata.OpenStack,
+		SecretID:   openStackTestCredID,
+		SecretKey:  secretKey,
+		Endpoint:   endpoint,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestOpenStackGetRegions(t *testing.T) {
+	server := newOpenStackStub(t)
+	defer server.Close()
+
+	client := newOpenStackTestClient(t, server.URL+"/identity/v3/", openStackTestCredSecret)
+	regions, err := client.GetRegions()
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	require.Equal(t, "RegionOne", regions[0].RegionId)
+	require.Equal(t, "RegionTwo", regions[1].RegionId)
+}
+
+func TestOpenStackAuthFailure(t *testing.T) {
+	server := newOpenStackStub(t)
+	defer server.Close()
+
+	client := newOpenStackTestClient(t, server.URL+"/identity/v3", "wrong-secret")
+	_, err := client.GetRegions()
+	require.Error(t, err)
+	require.Contains(t, strings.ToLower(err.Error()), "authfailure")
+
+	client = newOpenStackTestClient(t, "", openStackTestCredSecret)
+	_, err = client.GetRegions()
+	require.Error(t, err)
+}
+
+func TestOpenStackGetVpcs(t *testing.T) {
+	server := newOpenStackStub(t)
+	defer server.Close()
+
+	client := newOpenStackTestClient(t, server.URL+"/identity/v3", openStackTestCredSecret)
+	vpcsInfo, err := client.GetVpcs("RegionOne", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, vpcsInfo.Count)
+	require.Equal(t, "net-1", vpcsInfo.VpcSet[0].VpcId)
+	require.Equal(t, "private", vpcsInfo.VpcSet[0].VpcName)
+
+	opt := &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: ccom.MaxLimit, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"net-2"}),
+	}}}}
+	vpcsInfo, err = client.GetVpcs("RegionOne", opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, vpcsInfo.Count)
+	require.Equal(t, "shared", vpcsInfo.VpcSet[0].VpcName)
+
+	// RegionTwo没有网络服务
+	_, err = client.GetVpcs("RegionTwo", nil)
+	require.Error(t, err)
+}
+
+func TestOpenStackGetInstances(t *testing.T) {
+	server := newOpenStackStub(t)
+	defer server.Close()
+
+	client := newOpenStackTestClient(t, server.URL+"/identity/v3", openStackTestCredSecret)
+	instsInfo, err := client.GetInstances("RegionOne", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 3)
+	require.Equal(t, metadata.Instance{InstanceId: "vm-1", PrivateIp: "192.168.0.10", PublicIp: "10.0.0.10",
+		InstanceState: common.BKCloudHostStatusRunning, VpcId: "net-1"}, *instsInfo.InstanceSet[0])
+	require.Equal(t, metadata.Instance{InstanceId: "vm-2", PrivateIp: "172.16.0.2", InstanceState: common.BKCloudHostStatusStopped,
+		VpcId: "net-2"}, *instsInfo.InstanceSet[1])
+	require.Equal(t, common.BKCloudHostStatusStarting, instsInfo.InstanceSet[2].InstanceState)
+
+	opt := &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{Limit: 1, Filters: []*ccom.Filter{{
+		Name:   ccom.StringPtr("vpc-id"),
+		Values: ccom.StringPtrs([]string{"net-1"}),
+	}}}}
+	instsInfo, err = client.GetInstances("RegionOne", opt)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, instsInfo.Count)
+	require.Len(t, instsInfo.InstanceSet, 1)
+
+	cnt, err := client.GetInstancesTotalCnt("RegionOne", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, cnt)
+}
@@ -46,11 +46,11 @@ const (
 )
 
 // NewVendorClient 创建云厂商客户端
-func (c *tcClient) NewVendorClient(secretID, secretKey string) VendorClient {
+func (c *tcClient) NewVendorClient(conf metadata.CloudAccountConf) VendorClient {
 	return &tcClient{
 		vendorName: metadata.TencentCloud,
-		secretID:   secretID,
-		secretKey:  secretKey,
+		secretID:   conf.SecretID,
+		secretKey:  conf.SecretKey,
 	}
 }
 
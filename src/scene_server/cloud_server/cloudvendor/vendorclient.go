@@ -19,6 +19,7 @@ package cloudvendor
 import (
 	"fmt"
 
+	"configcenter/src/common/blog"
 	"configcenter/src/common/metadata"
 	ccom "configcenter/src/scene_server/cloud_server/common"
 )
@@ -28,7 +29,7 @@ var vendorClients = make(map[string]VendorClient, 0)
 // VendorClient TODO
 type VendorClient interface {
 	// NewVendorClient 创建云厂商客户端
-	NewVendorClient(secretID, secretKey string) VendorClient
+	NewVendorClient(conf metadata.CloudAccountConf) VendorClient
 	// GetRegions 获取地域列表
 	GetRegions() ([]*metadata.Region, error)
 	// GetVpcs 获取vpc列表
@@ -51,6 +52,20 @@ func GetVendorClient(conf metadata.CloudAccountConf) (VendorClient, error) {
 	if client, ok = vendorClients[conf.VendorName]; !ok {
 		return nil, fmt.Errorf("vendor %s is not supported", conf.VendorName)
 	}
-	cli := client.NewVendorClient(conf.SecretID, conf.SecretKey)
+	cli := client.NewVendorClient(conf)
 	return cli, nil
 }
+
+// getVpcIDFilter 获取vpc-id过滤条件的值，用于接口不支持通用过滤条件的云厂商
+func getVpcIDFilter(filters []*ccom.Filter) string {
+	for _, filter := range filters {
+		if filter == nil || filter.Name == nil || len(filter.Values) == 0 || filter.Values[0] == nil {
+			continue
+		}
+		if *filter.Name == "vpc-id" {
+			return *filter.Values[0]
+		}
+		blog.Warnf("cloud vendor filter %s is not supported, skip it", *filter.Name)
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vendorHTTPClient 没有官方sdk可用的云厂商使用的http客户端
+var vendorHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// doVendorRequest 发送云厂商接口请求，并将返回的json结果解析到result中
+// 鉴权失败时返回的错误中带有AuthFailure，与其它云厂商sdk的错误保持一致，以便上层识别出账户密钥错误
+func doVendorRequest(req *http.Request, result interface{}) (*http.Response, error) {
+	resp, err := vendorHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("AuthFailure: %s %s failed, status: %d, body: %s", req.Method, req.URL.Path,
+			resp.StatusCode, body)
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return nil, fmt.Errorf("%s %s failed, status: %d, body: %s", req.Method, req.URL.Path, resp.StatusCode, body)
+	}
+
+	if result == nil || len(strings.TrimSpace(string(body))) == 0 {
+		return resp, nil
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("decode %s %s response failed, err: %v, body: %s", req.Method, req.URL.Path, err, body)
+	}
+	return resp, nil
+}
@@ -31,13 +31,13 @@ import (
 // CovertInstState 将不同云厂商的实例状态转为统一的实例状态
 func CovertInstState(instState string) string {
 	switch strings.ToLower(instState) {
-	case "starting", "pending", "rebooting":
+	case "starting", "pending", "rebooting", "build", "reboot", "hard_reboot":
 		return common.BKCloudHostStatusStarting
-	case "running":
+	case "running", "active":
 		return common.BKCloudHostStatusRunning
 	case "stopping", "shutting-down", "terminating":
 		return common.BKCloudHostStatusStopping
-	case "stopped", "shutdown", "terminated":
+	case "stopped", "shutdown", "terminated", "shutoff", "deleted":
 		return common.BKCloudHostStatusStopped
 	default:
 		blog.Infof("convert to unknow state, the origin instState:%s", instState)
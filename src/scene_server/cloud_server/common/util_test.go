@@ -23,12 +23,13 @@ import (
 )
 
 func TestCovertInstState(t *testing.T) {
-	states := []string{"starting", "pending", "rebooting", "STARTING", "PENDING", "REBOOTING"}
+	states := []string{"starting", "pending", "rebooting", "STARTING", "PENDING", "REBOOTING", "BUILD", "REBOOT",
+		"HARD_REBOOT"}
 	for _, state := range states {
 		require.Equal(t, "starting", CovertInstState(state))
 	}
 
-	states = []string{"running", "RUNNING"}
+	states = []string{"running", "RUNNING", "ACTIVE"}
 	for _, state := range states {
 		require.Equal(t, "running", CovertInstState(state))
 	}
@@ -38,7 +39,7 @@ func TestCovertInstState(t *testing.T) {
 		require.Equal(t, "stopping", CovertInstState(state))
 	}
 
-	states = []string{"stopped", "shutdown", "terminated", "STOPPED", "SHUTDOWN", "TERMINATED"}
+	states = []string{"stopped", "shutdown", "terminated", "STOPPED", "SHUTDOWN", "TERMINATED", "SHUTOFF", "DELETED"}
 	for _, state := range states {
 		require.Equal(t, "stopped", CovertInstState(state))
 	}
@@ -24,14 +24,15 @@ import (
 	"sync"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
 	apiutil "configcenter/src/apimachinery/util"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	headerutil "configcenter/src/common/http/header/util"
 	"configcenter/src/common/metadata"
@@ -40,6 +41,7 @@ import (
 	"configcenter/src/scene_server/datacollection/app/options"
 	"configcenter/src/scene_server/datacollection/collections"
 	"configcenter/src/scene_server/datacollection/collections/hostsnap"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect"
 	"configcenter/src/scene_server/datacollection/collections/middleware"
 	"configcenter/src/scene_server/datacollection/collections/netcollect"
 	svc "configcenter/src/scene_server/datacollection/service"
@@ -159,6 +161,9 @@ type DataCollection struct {
 	// hash collections hash object, that updates target nodes in dynamic mode,
 	// and calculates node base on hash key of data.
 	hash *collections.Hash
+
+	// kubeCryptor encrypts the kube cluster credentials, it is nil if crypto is not enabled.
+	kubeCryptor cryptor.Cryptor
 }
 
 // NewDataCollection creates a new DataCollection object.
@@ -175,6 +180,7 @@ func NewDataCollection(ctx context.Context, op *options.ServerOption) (*DataColl
 	engine, err := backbone.NewBackbone(ctx, &backbone.BackboneParameter{
 		ConfigUpdate: newDataCollection.OnHostConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo:      svrInfo,
 	})
@@ -331,6 +337,19 @@ func (c *DataCollection) initModules() error {
 
 	// build logics comm.
 	c.service.SetLogics(mgoCli, esb)
+	go c.service.LoopSNMPDiscover()
+
+	// create kube cluster credentials cryptor.
+	cryptoConfig, err := cc.Crypto("crypto")
+	if err != nil {
+		return fmt.Errorf("get crypto configs, %+v", err)
+	}
+	if cryptoConfig.Enabled {
+		if c.kubeCryptor, err = cryptor.NewCrypto(cryptoConfig); err != nil {
+			return fmt.Errorf("create kube credential cryptor, %+v", err)
+		}
+	}
+	c.service.SetCryptor(c.kubeCryptor)
 
 	// connect to cc main redis.
 	redisCli, err := redis.NewFromConfig(c.config.CCRedis)
@@ -401,7 +420,7 @@ func (c *DataCollection) initModules() error {
 	}
 
 	iamCli := new(iam.IAM)
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		blog.Info("enable auth center access")
 		iamCli, err = iam.NewIAM(c.config.Auth, c.engine.Metric().Registry())
 		if err != nil {
@@ -411,6 +430,7 @@ func (c *DataCollection) initModules() error {
 		blog.Infof("disable auth center access")
 	}
 	c.authManager = extensions.NewAuthManager(c.engine.CoreAPI, iamCli)
+	c.service.SetAuthManager(c.authManager)
 
 	return nil
 }
@@ -556,6 +576,10 @@ func (c *DataCollection) Run() error {
 
 	blog.Info("run collect porters success!")
 
+	// run kube collectors of the registered clusters.
+	kubeManager := kubecollect.NewManager(c.db, c.engine.CoreAPI.TopoServer().Kube(), c.kubeCryptor, c.hash)
+	go kubeManager.Run(c.ctx)
+
 	return nil
 }
 
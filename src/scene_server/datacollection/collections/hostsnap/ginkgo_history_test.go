@@ -0,0 +1,140 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package hostsnap
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/memory"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const attrHistoryConfig = `
+datacollection:
+  hostsnap:
+    history:
+      enabled: true
+`
+
+var _ = Describe("Hostsnap attribute history", func() {
+	var h *HostSnap
+	const hostID int64 = 1
+
+	findHistory := func() []metadata.HostAttrHistory {
+		history := make([]metadata.HostAttrHistory, 0)
+		cond := mapstr.MapStr{common.BKHostIDField: hostID, metadata.HostAttrFieldField: "bk_os_name"}
+		err := h.db.Table(common.BKTableNameHostAttrHistory).Find(cond).Sort(common.BKFieldID).All(h.ctx, &history)
+		Expect(err).NotTo(HaveOccurred())
+		return history
+	}
+
+	findDrift := func() []metadata.HostAttrDrift {
+		drifts := make([]metadata.HostAttrDrift, 0)
+		cond := mapstr.MapStr{common.BKHostIDField: hostID}
+		err := h.db.Table(common.BKTableNameHostAttrDrift).Find(cond).All(h.ctx, &drifts)
+		Expect(err).NotTo(HaveOccurred())
+		return drifts
+	}
+
+	BeforeEach(func() {
+		Expect(cc.SetCommonFromByte([]byte(attrHistoryConfig))).NotTo(HaveOccurred())
+		h = &HostSnap{ctx: context.Background(), db: memory.New(), attrHistory: newAttrHistory()}
+	})
+
+	AfterEach(func() {
+		delete(ignoreCompareField, "bk_os_name")
+	})
+
+	It("test value changes from A to B and back to A", func() {
+		host := `{"bk_host_id":1,"bk_os_name":"A"}`
+		for _, value := range []string{"A", "A", "B", "A"} {
+			h.recordAttrHistory("", host, `{"bk_os_name":"`+value+`"}`, hostID, nil, nil)
+		}
+
+		history := findHistory()
+		Expect(len(history)).To(Equal(3))
+		for idx, value := range []string{"A", "B", "A"} {
+			Expect(history[idx].Value).To(Equal(value))
+			Expect(history[idx].OwnerID).To(Equal(common.BKDefaultOwnerID))
+		}
+		Expect(history[0].ID).NotTo(Equal(history[2].ID))
+		Expect(findDrift()).To(BeEmpty())
+	})
+
+	It("test drift appears and clears", func() {
+		ignoreCompareField["bk_os_name"] = struct{}{}
+		host := `{"bk_host_id":1,"bk_os_name":"A"}`
+
+		h.recordAttrHistory("", host, `{"bk_os_name":"A"}`, hostID, nil, nil)
+		Expect(findDrift()).To(BeEmpty())
+
+		h.recordAttrHistory("", host, `{"bk_os_name":"B"}`, hostID, nil, nil)
+		drifts := findDrift()
+		Expect(len(drifts)).To(Equal(1))
+		Expect(drifts[0].Field).To(Equal("bk_os_name"))
+		Expect(drifts[0].CollectedValue).To(Equal("B"))
+		Expect(drifts[0].HostValue).To(Equal("A"))
+
+		h.recordAttrHistory("", host, `{"bk_os_name":"A"}`, hostID, nil, nil)
+		Expect(findDrift()).To(BeEmpty())
+	})
+
+	It("test expired history and drift are cleaned", func() {
+		now := time.Now()
+		expired := now.Add(-48 * time.Hour)
+		history := []metadata.HostAttrHistory{
+			{ID: 1, HostID: hostID, Field: "bk_os_name", Value: "A", FirstSeen: expired, LastSeen: expired},
+			{ID: 2, HostID: hostID, Field: "bk_os_name", Value: "B", FirstSeen: expired, LastSeen: now},
+		}
+		Expect(h.db.Table(common.BKTableNameHostAttrHistory).Insert(h.ctx, history)).NotTo(HaveOccurred())
+		drift := &metadata.HostAttrDrift{HostID: hostID, Field: "bk_os_name", CollectedValue: "B", HostValue: "A",
+			CreateTime: expired, LastTime: expired}
+		Expect(h.db.Table(common.BKTableNameHostAttrDrift).Insert(h.ctx, drift)).NotTo(HaveOccurred())
+
+		h.deleteExpiredAttrHistory(now.Add(-24 * time.Hour))
+
+		remain := findHistory()
+		Expect(len(remain)).To(Equal(1))
+		Expect(remain[0].ID).To(Equal(int64(2)))
+		Expect(findDrift()).To(BeEmpty())
+	})
+
+	It("test idle host states are evicted and loaded again", func() {
+		h.recordAttrHistory("", `{"bk_host_id":1}`, `{"bk_os_name":"A"}`, hostID, nil, nil)
+		h.recordAttrHistory("", `{"bk_host_id":2}`, `{"bk_os_name":"A"}`, 2, nil, nil)
+		h.attrHistory.hosts[2].recordTime = time.Now().Add(-3 * time.Hour)
+
+		h.attrHistory.evictIdle(time.Now().Add(-2 * time.Hour))
+		Expect(h.attrHistory.hosts).To(HaveKey(hostID))
+		Expect(h.attrHistory.hosts).NotTo(HaveKey(int64(2)))
+
+		// the evicted state is loaded from db, so the unchanged value does not start a new history period
+		h.recordAttrHistory("", `{"bk_host_id":2}`, `{"bk_os_name":"A"}`, 2, nil, nil)
+		Expect(h.attrHistory.hosts[2].ids).To(HaveKey("bk_os_name"))
+		history := make([]metadata.HostAttrHistory, 0)
+		cond := mapstr.MapStr{common.BKHostIDField: 2}
+		Expect(h.db.Table(common.BKTableNameHostAttrHistory).Find(cond).All(h.ctx, &history)).NotTo(HaveOccurred())
+		Expect(len(history)).To(Equal(1))
+	})
+})
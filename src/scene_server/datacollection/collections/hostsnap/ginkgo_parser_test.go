@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostsnap
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tidwall/gjson"
+)
+
+const nodeExporterMetrics = `# TYPE node_uname_info gauge
+node_uname_info{domainname="(none)",machine="x86_64",nodename="host-1",release="5.4.0-42-generic",sysname="Linux"} 1
+node_os_info{id="ubuntu",name="Ubuntu",version_id="20.04"} 1
+node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="0",mode="user"} 10
+node_cpu_seconds_total{cpu="1",mode="idle"} 100
+node_memory_MemTotal_bytes 8.589934592e+09
+node_memory_MemAvailable_bytes 4.294967296e+09
+node_filesystem_size_bytes{device="/dev/vda1",fstype="ext4",mountpoint="/"} 1.073741824e+11
+node_filesystem_size_bytes{device="/dev/vda1",fstype="ext4",mountpoint="/var/lib/docker"} 1.073741824e+11
+node_filesystem_size_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1.073741824e+09
+node_filesystem_free_bytes{device="/dev/vda1",fstype="ext4",mountpoint="/"} 5.36870912e+10
+node_network_info{address="52:54:00:19:2e:e8",device="eth0",operstate="up"} 1
+`
+
+const telegrafMetrics = `{"metrics":[
+{"name":"system","tags":{"host":"host-2"},"fields":{"n_cpus":4,"load1":0.5,"load5":0.3,"load15":0.1},
+"timestamp":1505811427},
+{"name":"mem","tags":{"host":"host-2"},"fields":{"total":17179869184,"used":4294967296,"used_percent":25},
+"timestamp":1505811427},
+{"name":"disk","tags":{"host":"host-2","device":"sda1","path":"/","fstype":"xfs"},
+"fields":{"total":214748364800,"used":10737418240},"timestamp":1505811427}
+]}`
+
+const osqueryResults = `[
+{"name":"pack_cmdb_system_info","unixTime":1505811427,"snapshot":[{"hostname":"host-3","cpu_type":"x86_64",
+"cpu_brand":"Intel(R) Xeon(R) CPU E5-26xx v3","cpu_logical_cores":"8","physical_memory":"34359738368"}]},
+{"name":"pack_cmdb_os_version","unixTime":1505811427,"snapshot":[{"name":"CentOS Linux","platform":"centos",
+"version":"7.9.2009"}]},
+{"name":"pack_cmdb_kernel_info","unixTime":1505811427,"snapshot":[{"version":"3.10.0-1160.el7.x86_64"}]},
+{"name":"pack_cmdb_interface_addresses","unixTime":1505811427,"snapshot":[{"interface":"eth0",
+"address":"127.0.0.2"},{"interface":"lo","address":"127.0.0.1"}]},
+{"name":"pack_cmdb_interface_details","unixTime":1505811427,"snapshot":[{"interface":"eth0",
+"mac":"52:54:00:19:2e:e9"}]}
+]`
+
+func newCollectorMsg(format, data string) *string {
+	msg := `{"cloudid":0,"ip":"127.0.0.2","bk_agent_id":"","bk_data_format":"` + format +
+		`","timestamp":1505811427,"data":` + data + `}`
+	return &msg
+}
+
+var _ = Describe("collector message parser test", func() {
+	It("parse node_exporter metrics", func() {
+		data, err := json.Marshal(nodeExporterMetrics)
+		Expect(err).NotTo(HaveOccurred())
+		snapMsg, err := convertCollectorMsg(newCollectorMsg("node_exporter", string(data)))
+		Expect(err).NotTo(HaveOccurred())
+
+		val := gjson.Parse(*snapMsg)
+		setter, _ := parseSetter(&val, "127.0.0.2", "")
+		Expect(setter["bk_host_name"]).To(Equal("host-1"))
+		Expect(setter["bk_os_type"]).To(Equal(common.HostOSTypeEnumLinux))
+		Expect(setter["bk_os_name"]).To(Equal("linux ubuntu"))
+		Expect(setter["bk_os_version"]).To(Equal("20.04"))
+		Expect(setter[common.BKOsKernelVersionField]).To(Equal("5.4.0-42-generic"))
+		Expect(setter["bk_cpu"]).To(Equal(int64(2)))
+		Expect(setter["bk_mem"]).To(Equal(uint64(8192)))
+		Expect(setter["bk_disk"]).To(Equal(uint64(100)))
+
+		snapshot, err := ParseHostSnap(&val)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gjson.Get(*snapshot, "Cpu").Int()).To(Equal(int64(2)))
+		Expect(gjson.Get(*snapshot, "memUsed").Int()).To(Equal(int64(4096)))
+	})
+
+	It("parse telegraf metrics", func() {
+		snapMsg, err := convertCollectorMsg(newCollectorMsg("telegraf", telegrafMetrics))
+		Expect(err).NotTo(HaveOccurred())
+
+		val := gjson.Parse(*snapMsg)
+		setter, _ := parseSetter(&val, "127.0.0.2", "")
+		Expect(setter["bk_host_name"]).To(Equal("host-2"))
+		Expect(setter["bk_cpu"]).To(Equal(int64(4)))
+		Expect(setter["bk_mem"]).To(Equal(uint64(16384)))
+		Expect(setter["bk_disk"]).To(Equal(uint64(200)))
+		Expect(setter).NotTo(HaveKey("bk_os_type"))
+	})
+
+	It("parse osquery results", func() {
+		snapMsg, err := convertCollectorMsg(newCollectorMsg("osquery", osqueryResults))
+		Expect(err).NotTo(HaveOccurred())
+
+		val := gjson.Parse(*snapMsg)
+		ipv4, _ := getIPsFromMsg(&val, "", "")
+		Expect(ipv4).To(ConsistOf("127.0.0.2"))
+
+		setter, _ := parseSetter(&val, "127.0.0.2", "")
+		Expect(setter["bk_host_name"]).To(Equal("host-3"))
+		Expect(setter["bk_cpu"]).To(Equal(int64(8)))
+		Expect(setter["bk_cpu_module"]).To(Equal("Intel(R) Xeon(R) CPU E5-26xx v3"))
+		Expect(setter["bk_mem"]).To(Equal(uint64(32768)))
+		Expect(setter["bk_os_name"]).To(Equal("linux centos"))
+		Expect(setter["bk_os_version"]).To(Equal("7.9.2009"))
+		Expect(setter["bk_mac"]).To(Equal("52:54:00:19:2e:e9"))
+	})
+
+	It("keep gse message and reject unknown format", func() {
+		msg := MockMessage
+		snapMsg, err := convertCollectorMsg(&msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapMsg).To(Equal(&msg))
+
+		_, err = convertCollectorMsg(newCollectorMsg("unknown", "{}"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,363 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package hostsnap
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// defaultAttrHistoryIntervalMinutes is the default interval to refresh the last seen time of unchanged values
+	defaultAttrHistoryIntervalMinutes = 60
+	// minAttrHistoryIntervalMinutes is the minimum interval to refresh the last seen time of unchanged values
+	minAttrHistoryIntervalMinutes = 1
+	// defaultAttrHistoryRetentionDays is the default days to keep the host attribute history and drift
+	defaultAttrHistoryRetentionDays = 30
+	// minAttrHistoryRetentionDays is the minimum days to keep the host attribute history and drift
+	minAttrHistoryRetentionDays = 1
+	// attrHistoryCleanInterval is the interval to clean up the expired host attribute history and drift
+	attrHistoryCleanInterval = time.Hour
+	// attrHistoryIdleIntervals is the number of record intervals after which the recorded state of a host that is not
+	// reported any more is evicted from memory
+	attrHistoryIdleIntervals = 2
+)
+
+// attrHistory records the time series of the collected host attributes and the drift between the collected value and
+// the value that hostsnap is configured not to overwrite.
+type attrHistory struct {
+	lock  sync.Mutex
+	hosts map[int64]*hostAttrState
+}
+
+// hostAttrState is the recorded state of a host, it is loaded from db when the host is first reported after start.
+type hostAttrState struct {
+	lock sync.Mutex
+	// values is the collected value of each field in the latest history period
+	values map[string]string
+	// ids is the id of the latest history period of each field
+	ids map[string]int64
+	// drifts is the collected value and host value of each drifted field
+	drifts map[string][2]string
+	// recordTime is the last time that the last seen time of the unchanged values is refreshed
+	recordTime time.Time
+}
+
+func newAttrHistory() *attrHistory {
+	return &attrHistory{hosts: make(map[int64]*hostAttrState)}
+}
+
+func getAttrHistoryConfig() (bool, time.Duration, time.Duration) {
+	enabled, err := cc.Bool("datacollection.hostsnap.history.enabled")
+	if err != nil || !enabled {
+		return false, 0, 0
+	}
+
+	interval := getLimitConfig("datacollection.hostsnap.history.recordIntervalMinutes",
+		defaultAttrHistoryIntervalMinutes, minAttrHistoryIntervalMinutes)
+	retention := getLimitConfig("datacollection.hostsnap.history.retentionDays", defaultAttrHistoryRetentionDays,
+		minAttrHistoryRetentionDays)
+	return true, time.Duration(interval) * time.Minute, time.Duration(retention) * 24 * time.Hour
+}
+
+// getCollectedAttrValues get the collected value of the host attributes from the setter, ips are recorded with the
+// reported ips since the setter only contains ips in the dynamic addressing scenario.
+func getCollectedAttrValues(raw string, ipv4, ipv6 []string) map[string]string {
+	values := make(map[string]string)
+	elements := gjson.GetMany(raw, compareFields...)
+	for idx, field := range compareFields {
+		if field == common.BKHostInnerIPField || field == common.BKHostInnerIPv6Field {
+			continue
+		}
+		if !elements[idx].Exists() {
+			continue
+		}
+		values[field] = elements[idx].String()
+	}
+
+	if len(ipv4) > 0 {
+		values[common.BKHostInnerIPField] = joinSortedIPs(ipv4)
+	}
+
+	if len(ipv6) > 0 {
+		standardIPv6, err := common.ConvertHostIpv6Val(append(make([]string, 0, len(ipv6)), ipv6...))
+		if err != nil {
+			standardIPv6 = ipv6
+		}
+		values[common.BKHostInnerIPv6Field] = joinSortedIPs(standardIPv6)
+	}
+
+	return values
+}
+
+func joinSortedIPs(ips []string) string {
+	sorted := append(make([]string, 0, len(ips)), ips...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// recordAttrHistory records the collected host attributes, a new history period is created when the collected value
+// changes, otherwise the last seen time of the period is refreshed every record interval.
+func (h *HostSnap) recordAttrHistory(rid, host, raw string, hostID int64, ipv4, ipv6 []string) {
+	enabled, interval, _ := getAttrHistoryConfig()
+	if !enabled {
+		return
+	}
+
+	ownerID := gjson.Get(host, common.BkSupplierAccount).String()
+	if ownerID == "" {
+		ownerID = common.BKDefaultOwnerID
+	}
+
+	state, err := h.getHostAttrState(rid, hostID)
+	if err != nil {
+		return
+	}
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	now := time.Now()
+	refresh := now.Sub(state.recordTime) >= interval
+	values := getCollectedAttrValues(raw, ipv4, ipv6)
+
+	unchangedIDs := make([]int64, 0)
+	for field, value := range values {
+		if id, ok := state.ids[field]; ok && state.values[field] == value {
+			unchangedIDs = append(unchangedIDs, id)
+			continue
+		}
+
+		id, err := h.db.NextSequence(h.ctx, common.BKTableNameHostAttrHistory)
+		if err != nil {
+			blog.Errorf("generate host attribute history id failed, err: %v, rid: %s", err, rid)
+			continue
+		}
+
+		history := &metadata.HostAttrHistory{ID: int64(id), HostID: hostID, Field: field, Value: value,
+			FirstSeen: now, LastSeen: now, OwnerID: ownerID}
+		if err := h.db.Table(common.BKTableNameHostAttrHistory).Insert(h.ctx, history); err != nil {
+			blog.Errorf("insert host attribute history %+v failed, err: %v, rid: %s", history, err, rid)
+			continue
+		}
+
+		state.ids[field] = history.ID
+		state.values[field] = value
+	}
+
+	h.recordAttrDrift(rid, host, raw, hostID, ownerID, state, refresh)
+
+	if !refresh {
+		return
+	}
+	state.recordTime = now
+
+	if len(unchangedIDs) == 0 {
+		return
+	}
+
+	cond := mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: unchangedIDs}}
+	data := mapstr.MapStr{metadata.HostAttrLastSeenField: now}
+	if _, err := h.db.Table(common.BKTableNameHostAttrHistory).UpdateMany(h.ctx, cond, data); err != nil {
+		blog.Errorf("update host %d attribute history last seen time failed, err: %v, rid: %s", hostID, err, rid)
+	}
+}
+
+// recordAttrDrift records the fields that hostsnap is configured not to overwrite whose collected value differs from
+// the host value, and removes the drift when they are consistent again.
+func (h *HostSnap) recordAttrDrift(rid, host, raw string, hostID int64, ownerID string, state *hostAttrState,
+	refresh bool) {
+
+	now := time.Now()
+	for field := range ignoreCompareField {
+		collected := gjson.Get(raw, field)
+		if !collected.Exists() {
+			continue
+		}
+
+		cond := mapstr.MapStr{common.BKHostIDField: hostID, metadata.HostAttrFieldField: field}
+		hostValue := gjson.Get(host, field).String()
+		if collected.String() == hostValue {
+			if _, ok := state.drifts[field]; !ok {
+				continue
+			}
+
+			if err := h.db.Table(common.BKTableNameHostAttrDrift).Delete(h.ctx, cond); err != nil {
+				blog.Errorf("delete host attribute drift failed, cond: %+v, err: %v, rid: %s", cond, err, rid)
+				continue
+			}
+			delete(state.drifts, field)
+			continue
+		}
+
+		drift := [2]string{collected.String(), hostValue}
+		prev, exists := state.drifts[field]
+		if exists && prev == drift && !refresh {
+			continue
+		}
+
+		if !exists {
+			doc := &metadata.HostAttrDrift{HostID: hostID, Field: field, CollectedValue: drift[0], HostValue: drift[1],
+				OwnerID: ownerID, CreateTime: now, LastTime: now}
+			if err := h.db.Table(common.BKTableNameHostAttrDrift).Insert(h.ctx, doc); err != nil {
+				blog.Errorf("insert host attribute drift %+v failed, err: %v, rid: %s", doc, err, rid)
+				continue
+			}
+			state.drifts[field] = drift
+			continue
+		}
+
+		data := mapstr.MapStr{
+			metadata.HostAttrCollectedValueField: drift[0],
+			metadata.HostAttrHostValueField:      drift[1],
+			common.LastTimeField:                 now,
+		}
+		if err := h.db.Table(common.BKTableNameHostAttrDrift).Update(h.ctx, cond, data); err != nil {
+			blog.Errorf("update host attribute drift failed, cond: %+v, err: %v, rid: %s", cond, err, rid)
+			continue
+		}
+		state.drifts[field] = drift
+	}
+}
+
+// getHostAttrState get the recorded state of the host, load it from db if it is not in memory.
+func (h *HostSnap) getHostAttrState(rid string, hostID int64) (*hostAttrState, error) {
+	h.attrHistory.lock.Lock()
+	defer h.attrHistory.lock.Unlock()
+
+	if state, ok := h.attrHistory.hosts[hostID]; ok {
+		return state, nil
+	}
+
+	state := &hostAttrState{
+		values: make(map[string]string),
+		ids:    make(map[string]int64),
+		drifts: make(map[string][2]string),
+	}
+
+	// get the latest history period of each field of the host
+	pipeline := []mapstr.MapStr{
+		{common.BKDBMatch: mapstr.MapStr{common.BKHostIDField: hostID}},
+		{common.BKDBSort: mapstr.MapStr{metadata.HostAttrLastSeenField: -1}},
+		{common.BKDBGroup: mapstr.MapStr{
+			"_id":                       "$" + metadata.HostAttrFieldField,
+			common.BKFieldID:            mapstr.MapStr{"$first": "$" + common.BKFieldID},
+			metadata.HostAttrValueField: mapstr.MapStr{"$first": "$" + metadata.HostAttrValueField},
+		}},
+	}
+
+	latest := make([]struct {
+		Field string `bson:"_id"`
+		ID    int64  `bson:"id"`
+		Value string `bson:"value"`
+	}, 0)
+	err := h.db.Table(common.BKTableNameHostAttrHistory).AggregateAll(h.ctx, pipeline, &latest)
+	if err != nil {
+		blog.Errorf("get host %d latest attribute history failed, err: %v, rid: %s", hostID, err, rid)
+		return nil, err
+	}
+
+	for _, period := range latest {
+		state.ids[period.Field] = period.ID
+		state.values[period.Field] = period.Value
+	}
+
+	drifts := make([]metadata.HostAttrDrift, 0)
+	err = h.db.Table(common.BKTableNameHostAttrDrift).Find(mapstr.MapStr{common.BKHostIDField: hostID}).
+		All(h.ctx, &drifts)
+	if err != nil {
+		blog.Errorf("get host %d attribute drift failed, err: %v, rid: %s", hostID, err, rid)
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		state.drifts[drift.Field] = [2]string{drift.CollectedValue, drift.HostValue}
+	}
+
+	h.attrHistory.hosts[hostID] = state
+	return state, nil
+}
+
+// cleanExpiredAttrHistory cleans up the host attribute history and drift that exceeds the retention days.
+func (h *HostSnap) cleanExpiredAttrHistory() {
+	for {
+		time.Sleep(attrHistoryCleanInterval)
+
+		enabled, interval, retention := getAttrHistoryConfig()
+		if !enabled {
+			// the recorded states are useless when the history is disabled
+			h.attrHistory.evictIdle(time.Now())
+			continue
+		}
+
+		h.attrHistory.evictIdle(time.Now().Add(-attrHistoryIdleIntervals * interval))
+
+		expireTime := time.Now().Add(-retention)
+		if !h.ServiceManageInterface.IsMaster() {
+			continue
+		}
+
+		h.deleteExpiredAttrHistory(expireTime)
+	}
+}
+
+// evictIdle evicts the recorded states of the hosts that are not refreshed since the idle time, so that the states
+// of the removed or no longer reported hosts do not stay in memory. the state is loaded from db again when the host
+// is reported later.
+func (a *attrHistory) evictIdle(idleTime time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for hostID, state := range a.hosts {
+		state.lock.Lock()
+		if state.recordTime.Before(idleTime) {
+			delete(a.hosts, hostID)
+		}
+		state.lock.Unlock()
+	}
+}
+
+// deleteExpiredAttrHistory deletes the host attribute history and drift that are last seen before the expire time.
+func (h *HostSnap) deleteExpiredAttrHistory(expireTime time.Time) {
+	rid := util.GenerateRID()
+	ctx := context.WithValue(h.ctx, common.ContextRequestIDField, rid)
+
+	cond := mapstr.MapStr{metadata.HostAttrLastSeenField: mapstr.MapStr{common.BKDBLT: expireTime}}
+	count, err := h.db.Table(common.BKTableNameHostAttrHistory).DeleteMany(ctx, cond)
+	if err != nil {
+		blog.Errorf("delete expired host attribute history failed, err: %v, rid: %s", err, rid)
+	} else {
+		blog.Infof("deleted %d expired host attribute history before %s, rid: %s", count, expireTime, rid)
+	}
+
+	cond = mapstr.MapStr{common.LastTimeField: mapstr.MapStr{common.BKDBLT: expireTime}}
+	if _, err := h.db.Table(common.BKTableNameHostAttrDrift).DeleteMany(ctx, cond); err != nil {
+		blog.Errorf("delete expired host attribute drift failed, err: %v, rid: %s", err, rid)
+	}
+}
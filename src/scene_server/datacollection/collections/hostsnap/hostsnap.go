@@ -86,6 +86,8 @@ type HostSnap struct {
 	ctx       context.Context
 	db        dal.RDB
 	window    *Window
+	// attrHistory records the collected host attribute history and drift
+	attrHistory *attrHistory
 }
 
 // NewHostSnap new hostsnap
@@ -101,7 +103,9 @@ func NewHostSnap(ctx context.Context, redisCli redis.Client, db dal.RDB, engine
 		Engine:      engine,
 		filter:      newFilter(),
 		window:      newWindow(),
+		attrHistory: newAttrHistory(),
 	}
+	go h.cleanExpiredAttrHistory()
 	return h
 }
 
@@ -306,7 +310,14 @@ func (h *HostSnap) Analyze(msg *string, sourceType string) (bool, error) {
 
 	header, rid := newHeaderWithRid()
 
-	agentID, ipv4, ipv6, cloudID, val, err := getBaseInfoFromCollectorsMsg(msg, rid)
+	// convert the message reported by other collectors like node_exporter to the gse host snapshot message
+	snapMsg, err := convertCollectorMsg(msg)
+	if err != nil {
+		blog.Errorf("convert collector message failed, msg: %s, err: %v, rid: %s", *msg, err, rid)
+		return false, err
+	}
+
+	agentID, ipv4, ipv6, cloudID, val, err := getBaseInfoFromCollectorsMsg(snapMsg, rid)
 	if err != nil {
 		blog.Errorf("parse base info failed, msg: %s, err: %v, rid: %s", *msg, err, rid)
 		return false, err
@@ -369,6 +380,8 @@ func (h *HostSnap) Analyze(msg *string, sourceType string) (bool, error) {
 		setter, raw = parseSetter(&val, innerIP, outerIP)
 	}
 
+	h.recordAttrHistory(rid, host, raw, hostID, ipv4, ipv6)
+
 	// no need to update
 	if !needToUpdate(raw, host, elements[3].String()) {
 		return false, nil
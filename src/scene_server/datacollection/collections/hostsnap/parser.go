@@ -0,0 +1,334 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostsnap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common/json"
+
+	"github.com/tidwall/gjson"
+)
+
+// the host snapshot message reported by other collectors than gse is wrapped with an envelope like this:
+//
+//	{
+//	    "cloudid": 0,
+//	    "ip": "127.0.0.1",
+//	    "bk_agent_id": "",
+//	    "bk_data_format": "node_exporter",
+//	    "timestamp": 1505811427,
+//	    "data": "the original data reported by the collector"
+//	}
+//
+// the cloudid, ip and bk_agent_id fields are used to find the host, and the data field is parsed by the parser
+// registered with the name of bk_data_format field, then converted to the gse host snapshot message.
+const (
+	// dataFormatField is the field of the message which specifies the data format of the collector
+	dataFormatField = "bk_data_format"
+)
+
+// Parser parses the host snapshot data reported by the collector of the data format.
+type Parser interface {
+	// Name returns the data format name, which is the value of the bk_data_format field of the message.
+	Name() string
+
+	// Parse parses the data field of the message to host snapshot.
+	Parse(data gjson.Result) (*Snapshot, error)
+}
+
+var (
+	parsers    = make(map[string]Parser)
+	parserLock sync.RWMutex
+)
+
+// RegisterParser registers a host snapshot parser, so that the message with its data format can be analyzed.
+func RegisterParser(parser Parser) error {
+	if parser == nil || parser.Name() == "" {
+		return errors.New("parser name can not be empty")
+	}
+
+	parserLock.Lock()
+	defer parserLock.Unlock()
+
+	if _, exists := parsers[parser.Name()]; exists {
+		return fmt.Errorf("parser %s is already registered", parser.Name())
+	}
+	parsers[parser.Name()] = parser
+	return nil
+}
+
+// getParser returns the parser of the data format
+func getParser(format string) (Parser, bool) {
+	parserLock.RLock()
+	defer parserLock.RUnlock()
+
+	parser, exists := parsers[format]
+	return parser, exists
+}
+
+func init() {
+	for _, parser := range []Parser{new(nodeExporterParser), new(telegrafParser), new(osqueryParser)} {
+		if err := RegisterParser(parser); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// convertCollectorMsg converts the message reported by other collectors to the gse host snapshot message, the
+// message is returned as it is if it is reported by gse.
+func convertCollectorMsg(msg *string) (*string, error) {
+	format := gjson.Get(*msg, dataFormatField)
+	if !format.Exists() {
+		return msg, nil
+	}
+
+	parser, exists := getParser(format.String())
+	if !exists {
+		return nil, fmt.Errorf("data format %s is not supported", format.String())
+	}
+
+	envelope := gjson.Parse(*msg)
+	data := envelope.Get("data")
+	// the data can be reported as a json string, parse it so that the parsers do not need to care about it
+	if data.Type == gjson.String && gjson.Valid(data.String()) {
+		data = gjson.Parse(data.String())
+	}
+
+	snapshot, err := parser.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s data failed, err: %v", format.String(), err)
+	}
+	snapshot.complete()
+
+	timestamp := envelope.Get("timestamp").Int()
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+	snapshot.Datetime = time.Unix(timestamp, 0).Format("2006-01-02 15:04:05")
+
+	converted, err := json.Marshal(&gseSnapshotMsg{
+		CloudID: envelope.Get("cloudid").Int(),
+		IP:      envelope.Get("ip").String(),
+		AgentID: envelope.Get("bk_agent_id").String(),
+		Data:    snapshot,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := string(converted)
+	return &result, nil
+}
+
+// gseSnapshotMsg is the host snapshot message reported by gse
+type gseSnapshotMsg struct {
+	CloudID int64     `json:"cloudid"`
+	IP      string    `json:"ip"`
+	AgentID string    `json:"bk_agent_id"`
+	Data    *Snapshot `json:"data"`
+}
+
+// Snapshot is the host snapshot with the same structure as the gse host snapshot data, the fields which are not
+// collected can be left empty, and they will not be updated to the host.
+type Snapshot struct {
+	Datetime string         `json:"datetime"`
+	Cpu      SnapshotCpu    `json:"cpu"`
+	Disk     SnapshotDisk   `json:"disk"`
+	Load     SnapshotLoad   `json:"load"`
+	Mem      SnapshotMem    `json:"mem"`
+	Net      SnapshotNet    `json:"net"`
+	System   SnapshotSystem `json:"system"`
+}
+
+// SnapshotCpu is the cpu info of the host snapshot
+type SnapshotCpu struct {
+	CpuInfo    []SnapshotCpuInfo `json:"cpuinfo"`
+	PerUsage   []float64         `json:"per_usage"`
+	TotalUsage float64           `json:"total_usage"`
+}
+
+// SnapshotCpuInfo is the cpu model info of the host snapshot
+type SnapshotCpuInfo struct {
+	Cores     int64  `json:"cores"`
+	ModelName string `json:"modelName"`
+}
+
+// SnapshotDisk is the disk info of the host snapshot
+type SnapshotDisk struct {
+	Usage []SnapshotDiskUsage `json:"usage"`
+}
+
+// SnapshotDiskUsage is the usage of one disk partition, the unit is byte
+type SnapshotDiskUsage struct {
+	Device string `json:"-"`
+	Path   string `json:"path"`
+	Fstype string `json:"fstype"`
+	Total  uint64 `json:"total"`
+	Used   uint64 `json:"used"`
+}
+
+// SnapshotLoad is the load info of the host snapshot
+type SnapshotLoad struct {
+	LoadAvg SnapshotLoadAvg `json:"load_avg"`
+}
+
+// SnapshotLoadAvg is the load average of the host snapshot
+type SnapshotLoadAvg struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// SnapshotMem is the memory info of the host snapshot
+type SnapshotMem struct {
+	MemInfo SnapshotMemInfo `json:"meminfo"`
+}
+
+// SnapshotMemInfo is the memory usage of the host snapshot, the unit is byte
+type SnapshotMemInfo struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	UsedPercent float64 `json:"usedPercent"`
+}
+
+// SnapshotNet is the network info of the host snapshot
+type SnapshotNet struct {
+	Interface []SnapshotInterface `json:"interface"`
+}
+
+// SnapshotInterface is the network interface of the host snapshot
+type SnapshotInterface struct {
+	Name         string         `json:"name"`
+	HardwareAddr string         `json:"hardwareaddr"`
+	Addrs        []SnapshotAddr `json:"addrs"`
+}
+
+// SnapshotAddr is the address of the network interface
+type SnapshotAddr struct {
+	Addr string `json:"addr"`
+}
+
+// SnapshotSystem is the system info of the host snapshot
+type SnapshotSystem struct {
+	Info SnapshotSystemInfo `json:"info"`
+}
+
+// SnapshotSystemInfo is the operating system info of the host snapshot
+type SnapshotSystemInfo struct {
+	Hostname        string `json:"hostname"`
+	BootTime        uint64 `json:"bootTime"`
+	OS              string `json:"os"`
+	Platform        string `json:"platform"`
+	PlatformVersion string `json:"platformVersion"`
+	KernelVersion   string `json:"kernelVersion"`
+	SystemType      string `json:"systemtype"`
+	// Arch is the machine architecture like x86_64, which is used to get the system type
+	Arch string `json:"-"`
+}
+
+// addDiskUsage adds the disk usage, the partitions of the same device are only counted once
+func (s *Snapshot) addDiskUsage(usage SnapshotDiskUsage) {
+	if _, skip := ignoredFsTypes[usage.Fstype]; skip || usage.Total == 0 {
+		return
+	}
+
+	for _, exists := range s.Disk.Usage {
+		if usage.Device != "" && exists.Device == usage.Device {
+			return
+		}
+	}
+	s.Disk.Usage = append(s.Disk.Usage, usage)
+}
+
+// getInterface returns the network interface with the name, the interface is added if it does not exist
+func (s *Snapshot) getInterface(name string) *SnapshotInterface {
+	for idx := range s.Net.Interface {
+		if s.Net.Interface[idx].Name == name {
+			return &s.Net.Interface[idx]
+		}
+	}
+	s.Net.Interface = append(s.Net.Interface, SnapshotInterface{Name: name, Addrs: make([]SnapshotAddr, 0)})
+	return &s.Net.Interface[len(s.Net.Interface)-1]
+}
+
+// complete fills the fields which can be derived from the others, and sorts the slices to generate stable message
+func (s *Snapshot) complete() {
+	var cores int64
+	for _, info := range s.Cpu.CpuInfo {
+		cores += info.Cores
+	}
+	// the cpu number of the host snapshot is counted by the per cpu usage
+	if len(s.Cpu.PerUsage) == 0 && cores > 0 {
+		s.Cpu.PerUsage = make([]float64, cores)
+		for idx := range s.Cpu.PerUsage {
+			s.Cpu.PerUsage[idx] = s.Cpu.TotalUsage
+		}
+	}
+
+	if s.Mem.MemInfo.UsedPercent == 0 && s.Mem.MemInfo.Total > 0 {
+		s.Mem.MemInfo.UsedPercent = 100 * float64(s.Mem.MemInfo.Used) / float64(s.Mem.MemInfo.Total)
+	}
+
+	if s.System.Info.SystemType == "" {
+		s.System.Info.SystemType = getSystemType(s.System.Info.Arch)
+	}
+
+	sort.Slice(s.Disk.Usage, func(i, j int) bool {
+		return s.Disk.Usage[i].Path < s.Disk.Usage[j].Path
+	})
+	sort.Slice(s.Net.Interface, func(i, j int) bool {
+		return s.Net.Interface[i].Name < s.Net.Interface[j].Name
+	})
+
+	if s.Cpu.CpuInfo == nil {
+		s.Cpu.CpuInfo = make([]SnapshotCpuInfo, 0)
+	}
+	if s.Cpu.PerUsage == nil {
+		s.Cpu.PerUsage = make([]float64, 0)
+	}
+	if s.Disk.Usage == nil {
+		s.Disk.Usage = make([]SnapshotDiskUsage, 0)
+	}
+	if s.Net.Interface == nil {
+		s.Net.Interface = make([]SnapshotInterface, 0)
+	}
+}
+
+// ignoredFsTypes are the pseudo file systems which are not counted in the disk size of the host
+var ignoredFsTypes = map[string]struct{}{
+	"tmpfs": {}, "devtmpfs": {}, "overlay": {}, "squashfs": {}, "proc": {}, "sysfs": {}, "cgroup": {},
+	"cgroup2": {}, "nsfs": {}, "devpts": {}, "mqueue": {}, "autofs": {}, "iso9660": {},
+}
+
+// getSystemType returns the os bit of the host by the machine architecture, like x86_64, aarch64 and i386
+func getSystemType(arch string) string {
+	switch {
+	case arch == "":
+		return ""
+	case strings.Contains(arch, "64"):
+		return "64-bit"
+	case strings.Contains(arch, "86"), strings.Contains(arch, "arm"):
+		return "32-bit"
+	default:
+		return ""
+	}
+}
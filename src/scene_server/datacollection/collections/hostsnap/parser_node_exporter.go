@@ -0,0 +1,148 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostsnap
+
+import (
+	"errors"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/tidwall/gjson"
+)
+
+// nodeExporterParser parses the metrics scraped from prometheus node_exporter in text exposition format
+type nodeExporterParser struct{}
+
+// Name returns the data format name of node_exporter
+func (p *nodeExporterParser) Name() string {
+	return "node_exporter"
+}
+
+// Parse parses the node_exporter metrics to host snapshot
+func (p *nodeExporterParser) Parse(data gjson.Result) (*Snapshot, error) {
+	if data.Type != gjson.String || data.String() == "" {
+		return nil, errors.New("node_exporter data must be metrics in text format")
+	}
+
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(strings.NewReader(data.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := new(Snapshot)
+	info := &snapshot.System.Info
+
+	for _, metric := range families["node_uname_info"].GetMetric() {
+		labels := getMetricLabels(metric)
+		info.Hostname = labels["nodename"]
+		info.OS = strings.ToLower(labels["sysname"])
+		info.KernelVersion = labels["release"]
+		info.Arch = labels["machine"]
+	}
+
+	for _, metric := range families["node_os_info"].GetMetric() {
+		labels := getMetricLabels(metric)
+		info.Platform = labels["id"]
+		info.PlatformVersion = labels["version_id"]
+	}
+	info.BootTime = uint64(getMetricValue(families["node_boot_time_seconds"]))
+
+	// count the cpu by the cpu label, node_cpu_info is only reported with --collector.cpu.info flag
+	cpus, modelName := make(map[string]struct{}), ""
+	for _, name := range []string{"node_cpu_info", "node_cpu_seconds_total"} {
+		for _, metric := range families[name].GetMetric() {
+			labels := getMetricLabels(metric)
+			cpus[labels["cpu"]] = struct{}{}
+			if modelName == "" {
+				modelName = labels["model_name"]
+			}
+		}
+	}
+	if len(cpus) > 0 {
+		snapshot.Cpu.CpuInfo = []SnapshotCpuInfo{{Cores: int64(len(cpus)), ModelName: modelName}}
+	}
+
+	memTotal := uint64(getMetricValue(families["node_memory_MemTotal_bytes"]))
+	memAvailable := uint64(getMetricValue(families["node_memory_MemAvailable_bytes"]))
+	snapshot.Mem.MemInfo.Total = memTotal
+	if memAvailable > 0 && memAvailable <= memTotal {
+		snapshot.Mem.MemInfo.Used = memTotal - memAvailable
+	}
+
+	freeBytes := make(map[string]float64)
+	for _, metric := range families["node_filesystem_free_bytes"].GetMetric() {
+		freeBytes[getMetricLabels(metric)["mountpoint"]] = getValue(metric)
+	}
+	for _, metric := range families["node_filesystem_size_bytes"].GetMetric() {
+		labels := getMetricLabels(metric)
+		usage := SnapshotDiskUsage{
+			Device: labels["device"],
+			Path:   labels["mountpoint"],
+			Fstype: labels["fstype"],
+			Total:  uint64(getValue(metric)),
+		}
+		if free := uint64(freeBytes[usage.Path]); free <= usage.Total {
+			usage.Used = usage.Total - free
+		}
+		snapshot.addDiskUsage(usage)
+	}
+
+	snapshot.Load.LoadAvg = SnapshotLoadAvg{
+		Load1:  getMetricValue(families["node_load1"]),
+		Load5:  getMetricValue(families["node_load5"]),
+		Load15: getMetricValue(families["node_load15"]),
+	}
+
+	for _, metric := range families["node_network_info"].GetMetric() {
+		labels := getMetricLabels(metric)
+		if labels["device"] == "" || labels["device"] == "lo" {
+			continue
+		}
+		snapshot.getInterface(labels["device"]).HardwareAddr = labels["address"]
+	}
+
+	return snapshot, nil
+}
+
+// getMetricLabels returns the labels of the metric as a map
+func getMetricLabels(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	return labels
+}
+
+// getMetricValue returns the value of the first metric of the metric family
+func getMetricValue(family *dto.MetricFamily) float64 {
+	for _, metric := range family.GetMetric() {
+		return getValue(metric)
+	}
+	return 0
+}
+
+// getValue returns the value of the gauge, counter or untyped metric, the metrics without TYPE line are untyped
+func getValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.Gauge != nil:
+		return metric.GetGauge().GetValue()
+	case metric.Counter != nil:
+		return metric.GetCounter().GetValue()
+	default:
+		return metric.GetUntyped().GetValue()
+	}
+}
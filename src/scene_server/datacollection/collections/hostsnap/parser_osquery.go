@@ -0,0 +1,186 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostsnap
+
+import (
+	"errors"
+	"strings"
+
+	"configcenter/src/common"
+
+	"github.com/tidwall/gjson"
+)
+
+// osquery tables used to generate the host snapshot, the query names in the pack must end with the table name,
+// e.g. pack_cmdb_system_info, so that the rows of the query results can be recognized.
+const (
+	osquerySystemInfo        = "system_info"
+	osqueryOsVersion         = "os_version"
+	osqueryKernelInfo        = "kernel_info"
+	osqueryUptime            = "uptime"
+	osqueryMemoryInfo        = "memory_info"
+	osqueryMounts            = "mounts"
+	osqueryInterfaceAddrs    = "interface_addresses"
+	osqueryInterfaceDetails  = "interface_details"
+	osqueryLoopbackInterface = "lo"
+)
+
+var osqueryTables = []string{osquerySystemInfo, osqueryOsVersion, osqueryKernelInfo, osqueryUptime,
+	osqueryMemoryInfo, osqueryMounts, osqueryInterfaceAddrs, osqueryInterfaceDetails}
+
+// osqueryParser parses the query results logged by osquery, the data can be an array of results or a single result.
+// the results can be logged in snapshot, differential or event format.
+type osqueryParser struct{}
+
+// Name returns the data format name of osquery
+func (p *osqueryParser) Name() string {
+	return "osquery"
+}
+
+// Parse parses the osquery results to host snapshot
+func (p *osqueryParser) Parse(data gjson.Result) (*Snapshot, error) {
+	results := []gjson.Result{data}
+	if data.IsArray() {
+		results = data.Array()
+	}
+
+	rows := make(map[string][]gjson.Result)
+	var unixTime uint64
+	for _, result := range results {
+		table := getOsqueryTable(result.Get("name").String())
+		if table == "" {
+			continue
+		}
+		if ts := result.Get("unixTime").Uint(); ts > unixTime {
+			unixTime = ts
+		}
+
+		switch {
+		case result.Get("snapshot").Exists():
+			rows[table] = append(rows[table], result.Get("snapshot").Array()...)
+		case result.Get("diffResults").Exists():
+			rows[table] = append(rows[table], result.Get("diffResults.added").Array()...)
+		case result.Get("columns").Exists() && result.Get("action").String() != "removed":
+			rows[table] = append(rows[table], result.Get("columns"))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("osquery data has no results of the supported tables")
+	}
+
+	snapshot := new(Snapshot)
+	info := &snapshot.System.Info
+
+	for _, row := range rows[osquerySystemInfo] {
+		info.Hostname = row.Get("hostname").String()
+		info.Arch = row.Get("cpu_type").String()
+		snapshot.Cpu.CpuInfo = []SnapshotCpuInfo{{
+			Cores:     row.Get("cpu_logical_cores").Int(),
+			ModelName: strings.TrimSpace(row.Get("cpu_brand").String()),
+		}}
+		snapshot.Mem.MemInfo.Total = row.Get("physical_memory").Uint()
+	}
+
+	for _, row := range rows[osqueryOsVersion] {
+		info.Platform = row.Get("platform").String()
+		info.PlatformVersion = row.Get("version").String()
+		info.OS = getOsqueryOS(info.Platform)
+		// the platform of gse windows snapshot is the full os name, like Microsoft Windows Server 2012 R2
+		if info.OS == common.HostOSTypeName[common.HostOSTypeEnumWindows] {
+			info.Platform = row.Get("name").String()
+		}
+		if arch := row.Get("arch").String(); arch != "" && info.Arch == "" {
+			info.Arch = arch
+		}
+	}
+
+	for _, row := range rows[osqueryKernelInfo] {
+		info.KernelVersion = row.Get("version").String()
+	}
+
+	for _, row := range rows[osqueryUptime] {
+		if uptime := row.Get("total_seconds").Uint(); uptime > 0 && unixTime > uptime {
+			info.BootTime = unixTime - uptime
+		}
+	}
+
+	for _, row := range rows[osqueryMemoryInfo] {
+		total, available := row.Get("memory_total").Uint(), row.Get("memory_available").Uint()
+		snapshot.Mem.MemInfo.Total = total
+		if available <= total {
+			snapshot.Mem.MemInfo.Used = total - available
+		}
+	}
+
+	for _, row := range rows[osqueryMounts] {
+		blockSize := row.Get("blocks_size").Uint()
+		blocks, free := row.Get("blocks").Uint(), row.Get("blocks_free").Uint()
+		usage := SnapshotDiskUsage{
+			Device: row.Get("device").String(),
+			Path:   row.Get("path").String(),
+			Fstype: row.Get("type").String(),
+			Total:  blocks * blockSize,
+		}
+		if free <= blocks {
+			usage.Used = (blocks - free) * blockSize
+		}
+		snapshot.addDiskUsage(usage)
+	}
+
+	for _, row := range rows[osqueryInterfaceDetails] {
+		name := row.Get("interface").String()
+		if name == "" || name == osqueryLoopbackInterface {
+			continue
+		}
+		snapshot.getInterface(name).HardwareAddr = row.Get("mac").String()
+	}
+
+	for _, row := range rows[osqueryInterfaceAddrs] {
+		name, addr := row.Get("interface").String(), row.Get("address").String()
+		if name == "" || name == osqueryLoopbackInterface || addr == "" {
+			continue
+		}
+		iface := snapshot.getInterface(name)
+		iface.Addrs = append(iface.Addrs, SnapshotAddr{Addr: addr})
+	}
+
+	return snapshot, nil
+}
+
+// getOsqueryTable returns the table of the query by the query name
+func getOsqueryTable(name string) string {
+	for _, table := range osqueryTables {
+		if strings.HasSuffix(name, table) {
+			return table
+		}
+	}
+	return ""
+}
+
+// getOsqueryOS returns the os type name by the platform of os_version table
+func getOsqueryOS(platform string) string {
+	platform = strings.ToLower(platform)
+	switch platform {
+	case "":
+		return ""
+	case common.HostOSTypeName[common.HostOSTypeEnumWindows], common.HostOSTypeName[common.HostOSTypeEnumMacOS],
+		common.HostOSTypeName[common.HostOSTypeEnumFreeBSD]:
+		return platform
+	default:
+		return common.HostOSTypeName[common.HostOSTypeEnumLinux]
+	}
+}
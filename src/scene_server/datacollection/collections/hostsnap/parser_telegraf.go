@@ -0,0 +1,123 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostsnap
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// telegrafParser parses the metrics reported by telegraf with json data format, the data can be a batch of metrics
+// like {"metrics":[{"name":"mem","fields":{},"tags":{},"timestamp":1505811427}]} or a single metric.
+// the inputs.system, inputs.mem, inputs.disk, inputs.cpu and inputs.kernel plugins are used.
+type telegrafParser struct{}
+
+// Name returns the data format name of telegraf
+func (p *telegrafParser) Name() string {
+	return "telegraf"
+}
+
+// Parse parses the telegraf metrics to host snapshot
+func (p *telegrafParser) Parse(data gjson.Result) (*Snapshot, error) {
+	metrics := data.Get("metrics").Array()
+	if !data.Get("metrics").Exists() && data.Get("name").Exists() {
+		metrics = []gjson.Result{data}
+	}
+	if len(metrics) == 0 {
+		return nil, errors.New("telegraf data has no metrics")
+	}
+
+	snapshot := new(Snapshot)
+	info := &snapshot.System.Info
+	perUsage := make(map[string]float64)
+	var cpuNum int64
+
+	for _, metric := range metrics {
+		fields, tags := metric.Get("fields"), metric.Get("tags")
+		if info.Hostname == "" {
+			info.Hostname = tags.Get("host").String()
+		}
+
+		switch metric.Get("name").String() {
+		case "system":
+			if fields.Get("n_cpus").Exists() {
+				cpuNum = fields.Get("n_cpus").Int()
+			}
+			if fields.Get("load1").Exists() {
+				snapshot.Load.LoadAvg = SnapshotLoadAvg{
+					Load1:  fields.Get("load1").Float(),
+					Load5:  fields.Get("load5").Float(),
+					Load15: fields.Get("load15").Float(),
+				}
+			}
+			if uptime := fields.Get("uptime").Uint(); uptime > 0 && info.BootTime == 0 {
+				info.BootTime = metric.Get("timestamp").Uint() - uptime
+			}
+
+		case "kernel":
+			if bootTime := fields.Get("boot_time").Uint(); bootTime > 0 {
+				info.BootTime = bootTime
+			}
+
+		case "mem":
+			snapshot.Mem.MemInfo = SnapshotMemInfo{
+				Total:       fields.Get("total").Uint(),
+				Used:        fields.Get("used").Uint(),
+				UsedPercent: fields.Get("used_percent").Float(),
+			}
+
+		case "disk":
+			snapshot.addDiskUsage(SnapshotDiskUsage{
+				Device: tags.Get("device").String(),
+				Path:   tags.Get("path").String(),
+				Fstype: tags.Get("fstype").String(),
+				Total:  fields.Get("total").Uint(),
+				Used:   fields.Get("used").Uint(),
+			})
+
+		case "cpu":
+			if !fields.Get("usage_idle").Exists() {
+				continue
+			}
+			usage := 100 - fields.Get("usage_idle").Float()
+			cpu := tags.Get("cpu").String()
+			if cpu == "cpu-total" {
+				snapshot.Cpu.TotalUsage = usage
+				continue
+			}
+			if strings.HasPrefix(cpu, "cpu") {
+				perUsage[cpu] = usage
+			}
+		}
+	}
+
+	if cpuNum == 0 {
+		cpuNum = int64(len(perUsage))
+	}
+	if cpuNum > 0 {
+		snapshot.Cpu.CpuInfo = []SnapshotCpuInfo{{Cores: cpuNum}}
+	}
+	if int64(len(perUsage)) == cpuNum {
+		for idx := int64(0); idx < cpuNum; idx++ {
+			snapshot.Cpu.PerUsage = append(snapshot.Cpu.PerUsage, perUsage["cpu"+strconv.FormatInt(idx, 10)])
+		}
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,202 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kubeapi "configcenter/src/apimachinery/toposerver/kube"
+	"configcenter/src/common/blog"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+)
+
+const (
+	// syncCheckInterval is the interval to check if all the informers have synced
+	syncCheckInterval = time.Second
+	// reconcileDelay is the delay to reconcile after a change, so that a burst of changes is reconciled once
+	reconcileDelay = 5 * time.Second
+	// resyncInterval is the interval to reconcile the cluster even if nothing is changed, in case that the
+	// resources in cc are changed by others or the previous reconciliation partly failed
+	resyncInterval = 10 * time.Minute
+)
+
+// collector collects the resources of one kubernetes cluster into cc
+type collector struct {
+	cred  *types.ClusterCredential
+	kube  kubeapi.KubeOperationInterface
+	hosts HostGetter
+	// saveStatus saves the collecting status of the cluster
+	saveStatus func(ctx context.Context, status *types.CollectStatus)
+
+	nodes      *informer
+	namespaces *informer
+	pods       *informer
+	workloads  map[types.WorkloadType]*informer
+
+	changed chan struct{}
+}
+
+func newCollector(cred *types.ClusterCredential, client kubeclient.Interface, kube kubeapi.KubeOperationInterface,
+	hosts HostGetter, saveStatus func(ctx context.Context, status *types.CollectStatus)) *collector {
+
+	c := &collector{
+		cred:       cred,
+		kube:       kube,
+		hosts:      hosts,
+		saveStatus: saveStatus,
+		workloads:  make(map[types.WorkloadType]*informer),
+		changed:    make(chan struct{}, 1),
+	}
+
+	name := fmt.Sprintf("cluster %d", cred.ClusterID)
+	c.nodes = newInformer(name, client, kubeclient.Nodes, c.notify)
+	c.namespaces = newInformer(name, client, kubeclient.Namespaces, c.notify)
+	c.pods = newInformer(name, client, kubeclient.Pods, c.notify)
+	for _, res := range workloadResources {
+		c.workloads[res.kind] = newInformer(name, client, res.resource, c.notify)
+	}
+	return c
+}
+
+func (c *collector) informers() []*informer {
+	informers := []*informer{c.nodes, c.namespaces, c.pods}
+	for _, res := range workloadResources {
+		informers = append(informers, c.workloads[res.kind])
+	}
+	return informers
+}
+
+// notify marks the cluster as changed, it never blocks the informers
+func (c *collector) notify() {
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run runs the informers and reconciles the cluster on changes until the context is done
+func (c *collector) run(ctx context.Context) {
+	blog.Infof("[KubeCollect] start collecting cluster %d", c.cred.ClusterID)
+	c.saveStatus(ctx, &types.CollectStatus{State: types.CollectStateSyncing})
+
+	for _, inf := range c.informers() {
+		go inf.run(ctx)
+	}
+
+	if !c.waitForSync(ctx) {
+		return
+	}
+	c.reconcile(ctx)
+
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			blog.Infof("[KubeCollect] stop collecting cluster %d", c.cred.ClusterID)
+			return
+		case <-ticker.C:
+		case <-c.changed:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconcileDelay):
+			}
+		}
+
+		// drain the change that happens during the delay, it is reconciled in this round
+		select {
+		case <-c.changed:
+		default:
+		}
+		c.reconcile(ctx)
+	}
+}
+
+// waitForSync waits until all the informers have listed their resources, returns false if the context is done
+func (c *collector) waitForSync(ctx context.Context) bool {
+	for {
+		synced := true
+		for _, inf := range c.informers() {
+			if !inf.hasSynced() {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(syncCheckInterval):
+		}
+	}
+}
+
+// snapshot returns the current resources of the cluster and the errors of the informers
+func (c *collector) snapshot() (*snapshot, []string) {
+	errs := make([]string, 0)
+	list := func(inf *informer) []kubeclient.Object {
+		objects, err := inf.list()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("watch %s failed, err: %v", inf.resource, err))
+		}
+		return objects
+	}
+
+	snap := &snapshot{
+		nodes:      list(c.nodes),
+		namespaces: list(c.namespaces),
+		pods:       list(c.pods),
+		workloads:  make(map[types.WorkloadType][]kubeclient.Object),
+	}
+	for kind, inf := range c.workloads {
+		snap.workloads[kind] = list(inf)
+	}
+	return snap, errs
+}
+
+// reconcile reconciles the current resources of the cluster into cc and saves the result status
+func (c *collector) reconcile(ctx context.Context) {
+	snap, errs := c.snapshot()
+
+	status, err := newReconciler(ctx, c.kube, c.hosts, c.cred).reconcile(snap)
+	if status == nil {
+		status = &types.CollectStatus{LastSyncTime: time.Now().Unix()}
+	}
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	status.State = types.CollectStateSuccess
+	if len(errs) > 0 {
+		status.State = types.CollectStateFailed
+		status.Message = strings.Join(errs, "; ")
+		blog.Errorf("[KubeCollect] reconcile cluster %d failed, err: %s", c.cred.ClusterID, status.Message)
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	c.saveStatus(ctx, status)
+}
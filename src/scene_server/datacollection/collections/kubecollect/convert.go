@@ -0,0 +1,457 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"configcenter/src/common/criteria/enumor"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+)
+
+const (
+	// nodeRoleLabelPrefix is the prefix of the labels that mark the roles of a node
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	// nodeRoleLabel is the legacy label that marks the role of a node
+	nodeRoleLabel = "kubernetes.io/role"
+	// podTemplateHashLabel is the label that deployment adds to its replicasets and pods
+	podTemplateHashLabel = "pod-template-hash"
+	// podsWorkloadName is the name of the pods workload that pods without a known owner belong to
+	podsWorkloadName = "pods"
+	// podRunningPhase is the phase of a running pod
+	podRunningPhase = "Running"
+)
+
+// workloadResources is the kubernetes resources of the workload kinds, in the order they are reconciled
+var workloadResources = []struct {
+	kind     types.WorkloadType
+	resource kubeclient.Resource
+}{
+	{kind: types.KubeDeployment, resource: kubeclient.Deployments},
+	{kind: types.KubeStatefulSet, resource: kubeclient.StatefulSets},
+	{kind: types.KubeDaemonSet, resource: kubeclient.DaemonSets},
+	{kind: types.KubeGameDeployment, resource: kubeclient.GameDeployments},
+	{kind: types.KubeGameStatefulSet, resource: kubeclient.GameStatefulSets},
+	{kind: types.KubeCronJob, resource: kubeclient.CronJobs},
+	{kind: types.KubeJob, resource: kubeclient.Jobs},
+}
+
+// ownerKinds maps the kind of the pod owner reference to the workload type
+var ownerKinds = map[string]types.WorkloadType{
+	"Deployment":      types.KubeDeployment,
+	"StatefulSet":     types.KubeStatefulSet,
+	"DaemonSet":       types.KubeDaemonSet,
+	"GameDeployment":  types.KubeGameDeployment,
+	"GameStatefulSet": types.KubeGameStatefulSet,
+	"CronJob":         types.KubeCronJob,
+	"Job":             types.KubeJob,
+}
+
+type kubeNode struct {
+	Spec struct {
+		PodCIDR       string `json:"podCIDR"`
+		Unschedulable bool   `json:"unschedulable"`
+		Taints        []struct {
+			Key    string `json:"key"`
+			Value  string `json:"value"`
+			Effect string `json:"effect"`
+		} `json:"taints"`
+	} `json:"spec"`
+	Status struct {
+		Addresses []struct {
+			Type    string `json:"type"`
+			Address string `json:"address"`
+		} `json:"addresses"`
+		NodeInfo struct {
+			ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+		} `json:"nodeInfo"`
+	} `json:"status"`
+}
+
+// convertNode converts the kubernetes node to the cc node without the cluster and host info
+func convertNode(obj *kubeclient.Object) (*types.Node, error) {
+	kn := new(kubeNode)
+	if err := obj.Into(kn); err != nil {
+		return nil, fmt.Errorf("decode node %s failed, err: %v", obj.Key(), err)
+	}
+
+	name := obj.Metadata.Name
+	labels := enumor.MapStringType(copyLabels(obj.Metadata.Labels))
+
+	roles := make([]string, 0)
+	for key := range obj.Metadata.Labels {
+		if strings.HasPrefix(key, nodeRoleLabelPrefix) && len(key) > len(nodeRoleLabelPrefix) {
+			roles = append(roles, strings.TrimPrefix(key, nodeRoleLabelPrefix))
+		}
+	}
+	if role := obj.Metadata.Labels[nodeRoleLabel]; role != "" && len(roles) == 0 {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	rolesStr := strings.Join(roles, ",")
+
+	taints := make(enumor.MapStringType)
+	for _, taint := range kn.Spec.Taints {
+		taints[taint.Key] = taint.Value + ":" + taint.Effect
+	}
+
+	internalIPs, externalIPs := make([]string, 0), make([]string, 0)
+	hostname := ""
+	for _, addr := range kn.Status.Addresses {
+		switch addr.Type {
+		case "InternalIP":
+			internalIPs = append(internalIPs, addr.Address)
+		case "ExternalIP":
+			externalIPs = append(externalIPs, addr.Address)
+		case "Hostname":
+			hostname = addr.Address
+		}
+	}
+
+	unschedulable := kn.Spec.Unschedulable
+	runtime := kn.Status.NodeInfo.ContainerRuntimeVersion
+	podCidr := kn.Spec.PodCIDR
+
+	return &types.Node{
+		Name:             &name,
+		Roles:            &rolesStr,
+		Labels:           &labels,
+		Taints:           &taints,
+		Unschedulable:    &unschedulable,
+		InternalIP:       &internalIPs,
+		ExternalIP:       &externalIPs,
+		HostName:         &hostname,
+		RuntimeComponent: &runtime,
+		PodCidr:          &podCidr,
+	}, nil
+}
+
+// convertNamespace converts the kubernetes namespace to the cc namespace without the cluster and biz info
+func convertNamespace(obj *kubeclient.Object) *types.Namespace {
+	labels := copyLabels(obj.Metadata.Labels)
+	return &types.Namespace{
+		Name:   obj.Metadata.Name,
+		Labels: &labels,
+	}
+}
+
+type kubeLabelSelector struct {
+	MatchLabels      map[string]string `json:"matchLabels"`
+	MatchExpressions []struct {
+		Key      string   `json:"key"`
+		Operator string   `json:"operator"`
+		Values   []string `json:"values"`
+	} `json:"matchExpressions"`
+}
+
+// kubeIntOrString is the kubernetes int or string value, like 1 or "25%"
+type kubeIntOrString struct {
+	value types.IntOrString
+}
+
+// UnmarshalJSON decodes the int or string value
+func (k *kubeIntOrString) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		k.value.Type = types.StringType
+		return json.Unmarshal(data, &k.value.StrVal)
+	}
+	k.value.Type = types.IntType
+	return json.Unmarshal(data, &k.value.IntVal)
+}
+
+type kubeUpdateStrategy struct {
+	Type          string `json:"type"`
+	RollingUpdate *struct {
+		Partition      *int32           `json:"partition"`
+		MaxUnavailable *kubeIntOrString `json:"maxUnavailable"`
+		MaxSurge       *kubeIntOrString `json:"maxSurge"`
+	} `json:"rollingUpdate"`
+}
+
+type kubeWorkload struct {
+	Spec struct {
+		Replicas        *int64              `json:"replicas"`
+		Selector        *kubeLabelSelector  `json:"selector"`
+		MinReadySeconds *int64              `json:"minReadySeconds"`
+		Strategy        *kubeUpdateStrategy `json:"strategy"`
+		UpdateStrategy  *kubeUpdateStrategy `json:"updateStrategy"`
+	} `json:"spec"`
+}
+
+// convertWorkload converts the kubernetes workload to the cc workload of the kind without the namespace info,
+// the fields are encoded to the cc json names and decoded to the workload of the kind, so that the fields not
+// supported by the kind are dropped.
+func convertWorkload(kind types.WorkloadType, obj *kubeclient.Object) (types.WorkloadInterface, error) {
+	kw := new(kubeWorkload)
+	if err := obj.Into(kw); err != nil {
+		return nil, fmt.Errorf("decode %s %s failed, err: %v", kind, obj.Key(), err)
+	}
+
+	fields := map[string]interface{}{
+		types.KubeNameField: obj.Metadata.Name,
+		types.LabelsField:   copyLabels(obj.Metadata.Labels),
+	}
+
+	spec := kw.Spec
+	if spec.Replicas != nil {
+		fields[types.ReplicasField] = *spec.Replicas
+	}
+	if spec.MinReadySeconds != nil {
+		fields[types.MinReadySecondsField] = *spec.MinReadySeconds
+	}
+
+	if spec.Selector != nil {
+		selector := types.LabelSelector{
+			MatchLabels:      spec.Selector.MatchLabels,
+			MatchExpressions: make([]types.LabelSelectorRequirement, 0),
+		}
+		for _, expr := range spec.Selector.MatchExpressions {
+			selector.MatchExpressions = append(selector.MatchExpressions, types.LabelSelectorRequirement{
+				Key:      expr.Key,
+				Operator: types.LabelSelectorOperator(expr.Operator),
+				Values:   expr.Values,
+			})
+		}
+		fields[types.SelectorField] = selector
+	}
+
+	strategy := spec.Strategy
+	if strategy == nil {
+		strategy = spec.UpdateStrategy
+	}
+	if strategy != nil && strategy.Type != "" {
+		fields[types.StrategyTypeField] = strategy.Type
+		if strategy.RollingUpdate != nil {
+			rollingUpdate := map[string]interface{}{"partition": strategy.RollingUpdate.Partition}
+			if strategy.RollingUpdate.MaxUnavailable != nil {
+				rollingUpdate["max_unavailable"] = strategy.RollingUpdate.MaxUnavailable.value
+			}
+			if strategy.RollingUpdate.MaxSurge != nil {
+				rollingUpdate["max_surge"] = strategy.RollingUpdate.MaxSurge.value
+			}
+			fields[types.RollingUpdateStrategyField] = rollingUpdate
+		}
+	}
+
+	return newWorkload(kind, fields)
+}
+
+// newWorkload creates a workload of the kind by the fields with cc json names
+func newWorkload(kind types.WorkloadType, fields map[string]interface{}) (types.WorkloadInterface, error) {
+	js, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	wl, err := kind.NewInst()
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(js, wl); err != nil {
+		return nil, err
+	}
+	return wl, nil
+}
+
+type kubePod struct {
+	Spec struct {
+		NodeName     string            `json:"nodeName"`
+		Priority     *int32            `json:"priority"`
+		NodeSelector map[string]string `json:"nodeSelector"`
+		Tolerations  []json.RawMessage `json:"tolerations"`
+		Volumes      []json.RawMessage `json:"volumes"`
+		Containers   []struct {
+			Name          string            `json:"name"`
+			Image         string            `json:"image"`
+			Args          []string          `json:"args"`
+			Ports         []json.RawMessage `json:"ports"`
+			Env           []json.RawMessage `json:"env"`
+			VolumeMounts  []json.RawMessage `json:"volumeMounts"`
+			LivenessProbe json.RawMessage   `json:"livenessProbe"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string        `json:"phase"`
+		PodIP             string        `json:"podIP"`
+		PodIPs            []types.PodIP `json:"podIPs"`
+		QOSClass          string        `json:"qosClass"`
+		ContainerStatuses []struct {
+			Name        string `json:"name"`
+			ContainerID string `json:"containerID"`
+			State       struct {
+				Running *struct {
+					StartedAt string `json:"startedAt"`
+				} `json:"running"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// podInfo is the converted kubernetes pod
+type podInfo struct {
+	nodeName  string
+	namespace string
+	// ownerKind and ownerName is the workload that the pod belongs to
+	ownerKind types.WorkloadType
+	ownerName string
+	// ready is true when the pod is running on a node and all of its containers have been started
+	ready      bool
+	pod        types.Pod
+	containers []types.Container
+}
+
+// convertPod converts the kubernetes pod to the cc pod and containers without the topology info
+func convertPod(obj *kubeclient.Object) (*podInfo, error) {
+	kp := new(kubePod)
+	if err := obj.Into(kp); err != nil {
+		return nil, fmt.Errorf("decode pod %s failed, err: %v", obj.Key(), err)
+	}
+
+	info := &podInfo{
+		nodeName:  kp.Spec.NodeName,
+		namespace: obj.Metadata.Namespace,
+		ready: kp.Status.Phase == podRunningPhase && kp.Spec.NodeName != "" &&
+			obj.Metadata.DeletionTimestamp == nil,
+	}
+	info.ownerKind, info.ownerName = podOwner(obj)
+
+	name := obj.Metadata.Name
+	labels := copyLabels(obj.Metadata.Labels)
+	nodeSelectors := copyLabels(kp.Spec.NodeSelector)
+	ip := kp.Status.PodIP
+	ips := kp.Status.PodIPs
+	if ips == nil {
+		ips = make([]types.PodIP, 0)
+	}
+	qosClass := types.PodQOSClass(kp.Status.QOSClass)
+	info.pod = types.Pod{
+		Name:          &name,
+		Priority:      kp.Spec.Priority,
+		Labels:        &labels,
+		IP:            &ip,
+		IPs:           &ips,
+		Volumes:       decodeEach[types.Volume](kp.Spec.Volumes),
+		QOSClass:      &qosClass,
+		NodeSelectors: &nodeSelectors,
+		Tolerations:   decodeEach[types.Toleration](kp.Spec.Tolerations),
+	}
+
+	statuses := make(map[string]int)
+	for idx, status := range kp.Status.ContainerStatuses {
+		statuses[status.Name] = idx
+	}
+
+	info.containers = make([]types.Container, 0, len(kp.Spec.Containers))
+	for idx := range kp.Spec.Containers {
+		c := kp.Spec.Containers[idx]
+		container := types.Container{
+			Name:        &c.Name,
+			Image:       &c.Image,
+			Args:        &c.Args,
+			Ports:       decodeEach[types.ContainerPort](c.Ports),
+			Environment: decodeEach[types.EnvVar](c.Env),
+			Mounts:      decodeEach[types.VolumeMount](c.VolumeMounts),
+		}
+		if len(c.LivenessProbe) > 0 {
+			probe := new(types.Probe)
+			if err := json.Unmarshal(c.LivenessProbe, probe); err == nil {
+				container.Liveness = probe
+			}
+		}
+
+		statusIdx, exists := statuses[c.Name]
+		if !exists || kp.Status.ContainerStatuses[statusIdx].ContainerID == "" {
+			info.ready = false
+			info.containers = append(info.containers, container)
+			continue
+		}
+
+		status := kp.Status.ContainerStatuses[statusIdx]
+		containerID := status.ContainerID
+		container.ContainerID = &containerID
+		if status.State.Running != nil {
+			if started, err := time.Parse(time.RFC3339, status.State.Running.StartedAt); err == nil {
+				startedAt := started.Unix()
+				container.Started = &startedAt
+			}
+		}
+		info.containers = append(info.containers, container)
+	}
+
+	return info, nil
+}
+
+// podOwner returns the workload that the pod belongs to, the replicaset owner is resolved to its deployment by
+// the pod template hash, and pods without a known owner belong to the pods workload of the namespace.
+func podOwner(obj *kubeclient.Object) (types.WorkloadType, string) {
+	var owner *kubeclient.OwnerReference
+	for idx := range obj.Metadata.OwnerReferences {
+		ref := &obj.Metadata.OwnerReferences[idx]
+		if ref.Controller != nil && *ref.Controller {
+			owner = ref
+			break
+		}
+		if owner == nil {
+			owner = ref
+		}
+	}
+
+	if owner == nil {
+		return types.KubePodWorkload, podsWorkloadName
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		hash := obj.Metadata.Labels[podTemplateHashLabel]
+		if hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return types.KubeDeployment, strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return types.KubePodWorkload, podsWorkloadName
+	}
+
+	kind, exists := ownerKinds[owner.Kind]
+	if !exists {
+		return types.KubePodWorkload, podsWorkloadName
+	}
+	return kind, owner.Name
+}
+
+// decodeEach decodes the kubernetes values one by one, the values that can not be decoded to the cc structure,
+// like the ones with resource quantities, are dropped
+func decodeEach[T any](raws []json.RawMessage) *[]T {
+	result := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		value := new(T)
+		if err := json.Unmarshal(raw, value); err != nil {
+			continue
+		}
+		result = append(result, *value)
+	}
+	return &result
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"context"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+)
+
+// dbHostGetter gets the hosts from the cc database
+type dbHostGetter struct {
+	db dal.RDB
+}
+
+// NewHostGetter creates a HostGetter that reads the hosts from the cc database
+func NewHostGetter(db dal.RDB) HostGetter {
+	return &dbHostGetter{db: db}
+}
+
+// GetHostIDsByIP returns the map of the ip to the id of the host with the inner ip in the business
+func (g *dbHostGetter) GetHostIDsByIP(ctx context.Context, bizID int64, ips []string) (map[string]int64, error) {
+	ips = util.StrArrayUnique(ips)
+	hostCond := map[string]interface{}{
+		common.BKHostInnerIPField: map[string]interface{}{common.BKDBIN: ips},
+	}
+	hosts := make([]metadata.HostMapStr, 0)
+	err := g.db.Table(common.BKTableNameBaseHost).Find(hostCond).
+		Fields(common.BKHostIDField, common.BKHostInnerIPField).All(ctx, &hosts)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return make(map[string]int64), nil
+	}
+
+	hostIPs := make(map[int64][]string)
+	hostIDs := make([]int64, 0, len(hosts))
+	for _, host := range hosts {
+		hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+		if err != nil {
+			return nil, err
+		}
+		innerIP, _ := host[common.BKHostInnerIPField].(string)
+		hostIPs[hostID] = strings.Split(innerIP, ",")
+		hostIDs = append(hostIDs, hostID)
+	}
+
+	relCond := map[string]interface{}{
+		common.BKAppIDField:  bizID,
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs},
+	}
+	relations := make([]metadata.ModuleHost, 0)
+	err = g.db.Table(common.BKTableNameModuleHostConfig).Find(relCond).Fields(common.BKHostIDField).
+		All(ctx, &relations)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{})
+	for _, ip := range ips {
+		wanted[ip] = struct{}{}
+	}
+
+	result := make(map[string]int64)
+	ambiguous := make(map[string]struct{})
+	seen := make(map[int64]struct{})
+	for _, rel := range relations {
+		if _, ok := seen[rel.HostID]; ok {
+			continue
+		}
+		seen[rel.HostID] = struct{}{}
+
+		for _, ip := range hostIPs[rel.HostID] {
+			if _, ok := wanted[ip]; !ok {
+				continue
+			}
+			if id, ok := result[ip]; ok && id != rel.HostID {
+				ambiguous[ip] = struct{}{}
+			}
+			result[ip] = rel.HostID
+		}
+	}
+
+	for ip := range ambiguous {
+		delete(result, ip)
+	}
+	return result, nil
+}
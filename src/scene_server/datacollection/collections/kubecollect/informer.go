@@ -0,0 +1,201 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+)
+
+const (
+	// listPageSize is the page size to list the kubernetes resources
+	listPageSize = 500
+	// relistInterval is the interval to relist the resource after the list or watch failed
+	relistInterval = 10 * time.Second
+	// notServedRelistInterval is the interval to relist the resource that is not served by the cluster
+	notServedRelistInterval = 10 * time.Minute
+)
+
+// informer lists and watches a kubernetes resource, and keeps the objects of the resource in memory
+type informer struct {
+	name     string
+	client   kubeclient.Interface
+	resource kubeclient.Resource
+	// onChange is called after the objects are changed
+	onChange func()
+
+	lock    sync.RWMutex
+	synced  bool
+	lastErr error
+	objects map[string]kubeclient.Object
+}
+
+func newInformer(name string, client kubeclient.Interface, res kubeclient.Resource, onChange func()) *informer {
+	return &informer{
+		name:     name,
+		client:   client,
+		resource: res,
+		onChange: onChange,
+		objects:  make(map[string]kubeclient.Object),
+	}
+}
+
+// run lists and watches the resource until the context is done
+func (i *informer) run(ctx context.Context) {
+	for {
+		err := i.listAndWatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		interval := relistInterval
+		if kubeclient.IsNotFound(err) {
+			// the resource like a crd is not served, regard it as synced with no objects
+			i.replace(make(map[string]kubeclient.Object), nil)
+			interval = notServedRelistInterval
+		} else if err != nil {
+			blog.Errorf("[KubeCollect] %s list and watch %s failed, err: %v", i.name, i.resource, err)
+			i.setError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// listAndWatch lists all objects of the resource, then watches the changes until the watch fails
+func (i *informer) listAndWatch(ctx context.Context) error {
+	objects := make(map[string]kubeclient.Object)
+	opts := kubeclient.ListOptions{Limit: listPageSize}
+	rv := ""
+	for {
+		list, err := i.client.List(ctx, i.resource, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range list.Items {
+			objects[obj.Key()] = obj
+		}
+		rv = list.ResourceVersion
+
+		if list.Continue == "" {
+			break
+		}
+		opts.Continue = list.Continue
+	}
+	i.replace(objects, nil)
+
+	for {
+		watcher, err := i.client.Watch(ctx, i.resource, kubeclient.ListOptions{ResourceVersion: rv})
+		if err != nil {
+			return err
+		}
+
+		rv, err = i.consume(ctx, watcher, rv)
+		watcher.Stop()
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// consume handles the watch events until the watch ends, returns the latest resource version
+func (i *informer) consume(ctx context.Context, watcher kubeclient.Watcher, rv string) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return rv, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return rv, nil
+			}
+
+			switch event.Type {
+			case kubeclient.Error:
+				return rv, kubeclient.ErrorFromEvent(event)
+			case kubeclient.Bookmark:
+			case kubeclient.Added, kubeclient.Modified, kubeclient.Deleted:
+				i.apply(event)
+			default:
+				return rv, fmt.Errorf("unknown watch event type %s", event.Type)
+			}
+
+			if event.Object.Metadata.ResourceVersion != "" {
+				rv = event.Object.Metadata.ResourceVersion
+			}
+		}
+	}
+}
+
+func (i *informer) apply(event kubeclient.Event) {
+	i.lock.Lock()
+	if event.Type == kubeclient.Deleted {
+		delete(i.objects, event.Object.Key())
+	} else {
+		i.objects[event.Object.Key()] = event.Object
+	}
+	i.lock.Unlock()
+
+	i.onChange()
+}
+
+func (i *informer) replace(objects map[string]kubeclient.Object, err error) {
+	i.lock.Lock()
+	i.objects = objects
+	i.synced = true
+	i.lastErr = err
+	i.lock.Unlock()
+
+	i.onChange()
+}
+
+func (i *informer) setError(err error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.lastErr = err
+}
+
+// hasSynced returns if the resource has been listed at least once
+func (i *informer) hasSynced() bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.synced
+}
+
+// list returns the objects of the resource and the last list or watch error
+func (i *informer) list() ([]kubeclient.Object, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	objects := make([]kubeclient.Object, 0, len(i.objects))
+	for _, obj := range i.objects {
+		objects = append(objects, obj)
+	}
+	return objects, i.lastErr
+}
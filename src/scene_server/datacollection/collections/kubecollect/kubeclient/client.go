@@ -0,0 +1,405 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubeclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// listTimeout is the timeout of a list request
+	listTimeout = time.Minute
+	// defaultWatchTimeoutSeconds is the default server side timeout of a watch request
+	defaultWatchTimeoutSeconds = 300
+)
+
+// kubeConfig is the subset of the kubeconfig file that the client supports
+type kubeConfig struct {
+	CurrentContext string             `yaml:"current-context"`
+	Clusters       []namedCluster     `yaml:"clusters"`
+	Users          []namedAuthInfo    `yaml:"users"`
+	Contexts       []namedKubeContext `yaml:"contexts"`
+}
+
+type namedCluster struct {
+	Name    string      `yaml:"name"`
+	Cluster clusterInfo `yaml:"cluster"`
+}
+
+type clusterInfo struct {
+	Server                   string `yaml:"server"`
+	TLSServerName            string `yaml:"tls-server-name"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+}
+
+type namedAuthInfo struct {
+	Name string   `yaml:"name"`
+	User authInfo `yaml:"user"`
+}
+
+type authInfo struct {
+	ClientCertificate     string      `yaml:"client-certificate"`
+	ClientCertificateData string      `yaml:"client-certificate-data"`
+	ClientKey             string      `yaml:"client-key"`
+	ClientKeyData         string      `yaml:"client-key-data"`
+	Token                 string      `yaml:"token"`
+	TokenFile             string      `yaml:"tokenFile"`
+	Username              string      `yaml:"username"`
+	Password              string      `yaml:"password"`
+	Exec                  interface{} `yaml:"exec"`
+	AuthProvider          interface{} `yaml:"auth-provider"`
+}
+
+type namedKubeContext struct {
+	Name    string      `yaml:"name"`
+	Context kubeContext `yaml:"context"`
+}
+
+type kubeContext struct {
+	Cluster string `yaml:"cluster"`
+	User    string `yaml:"user"`
+}
+
+// Config is the resolved config to access a cluster
+type Config struct {
+	Host      string
+	TLSConfig *tls.Config
+	Token     string
+	Username  string
+	Password  string
+}
+
+// ParseKubeConfig parses the kubeconfig content and resolves the config of the given context, the current
+// context is used if contextName is empty. Only embedded certificates and static credentials are supported since
+// the kubeconfig is stored in db, file references and credential plugins are rejected.
+func ParseKubeConfig(content []byte, contextName string) (*Config, error) {
+	kc := new(kubeConfig)
+	if err := yaml.Unmarshal(content, kc); err != nil {
+		return nil, fmt.Errorf("parse kubeconfig failed, err: %v", err)
+	}
+
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	if contextName == "" {
+		return nil, errors.New("kubeconfig context is not set and has no current context")
+	}
+
+	var kctx *kubeContext
+	for idx := range kc.Contexts {
+		if kc.Contexts[idx].Name == contextName {
+			kctx = &kc.Contexts[idx].Context
+			break
+		}
+	}
+	if kctx == nil {
+		return nil, fmt.Errorf("kubeconfig context %s not found", contextName)
+	}
+
+	var cluster *clusterInfo
+	for idx := range kc.Clusters {
+		if kc.Clusters[idx].Name == kctx.Cluster {
+			cluster = &kc.Clusters[idx].Cluster
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("kubeconfig cluster %s not found", kctx.Cluster)
+	}
+
+	user := new(authInfo)
+	for idx := range kc.Users {
+		if kc.Users[idx].Name == kctx.User {
+			user = &kc.Users[idx].User
+			break
+		}
+	}
+
+	return newConfig(cluster, user)
+}
+
+func newConfig(cluster *clusterInfo, user *authInfo) (*Config, error) {
+	if cluster.Server == "" {
+		return nil, errors.New("kubeconfig cluster server is not set")
+	}
+	if _, err := url.Parse(cluster.Server); err != nil {
+		return nil, fmt.Errorf("kubeconfig cluster server %s is invalid, err: %v", cluster.Server, err)
+	}
+
+	if cluster.CertificateAuthority != "" || user.ClientCertificate != "" || user.ClientKey != "" ||
+		user.TokenFile != "" {
+		return nil, errors.New("kubeconfig file references are not supported, embed the data instead")
+	}
+	if user.Exec != nil || user.AuthProvider != nil {
+		return nil, errors.New("kubeconfig credential plugins are not supported")
+	}
+
+	tlsConf := &tls.Config{
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+
+	if cluster.CertificateAuthorityData != "" {
+		caData, err := base64.StdEncoding.DecodeString(cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("decode certificate-authority-data failed, err: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, errors.New("certificate-authority-data has no valid certificate")
+		}
+		tlsConf.RootCAs = pool
+	}
+
+	if user.ClientCertificateData != "" || user.ClientKeyData != "" {
+		certData, err := base64.StdEncoding.DecodeString(user.ClientCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("decode client-certificate-data failed, err: %v", err)
+		}
+		keyData, err := base64.StdEncoding.DecodeString(user.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("decode client-key-data failed, err: %v", err)
+		}
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate failed, err: %v", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+
+	return &Config{
+		Host:      strings.TrimSuffix(cluster.Server, "/"),
+		TLSConfig: tlsConf,
+		Token:     user.Token,
+		Username:  user.Username,
+		Password:  user.Password,
+	}, nil
+}
+
+// client is the kubernetes api client that accesses the api server with http
+type client struct {
+	config  *Config
+	httpCli *http.Client
+}
+
+// NewForKubeConfig creates a new client by the kubeconfig content and context
+func NewForKubeConfig(content []byte, contextName string) (Interface, error) {
+	conf, err := ParseKubeConfig(content, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return NewForConfig(conf), nil
+}
+
+// NewForConfig creates a new client by the resolved config
+func NewForConfig(conf *Config) Interface {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:     conf.TLSConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &client{
+		config:  conf,
+		httpCli: &http.Client{Transport: transport},
+	}
+}
+
+func (c *client) newRequest(ctx context.Context, res Resource, query url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.Host+res.Path()+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case c.config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	return req, nil
+}
+
+// List lists one page of the resource in all namespaces
+func (c *client) List(ctx context.Context, res Resource, opts ListOptions) (*ObjectList, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+	if opts.Continue != "" {
+		query.Set("continue", opts.Continue)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	req, err := c.newRequest(ctx, res, query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeStatusError(resp)
+	}
+
+	list := struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+			Continue        string `json:"continue"`
+		} `json:"metadata"`
+		Items []Object `json:"items"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decode %s list failed, err: %v", res, err)
+	}
+
+	return &ObjectList{
+		ResourceVersion: list.Metadata.ResourceVersion,
+		Continue:        list.Metadata.Continue,
+		Items:           list.Items,
+	}, nil
+}
+
+// Watch watches the resource in all namespaces from the resource version of the options
+func (c *client) Watch(ctx context.Context, res Resource, opts ListOptions) (Watcher, error) {
+	timeout := opts.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultWatchTimeoutSeconds
+	}
+
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("timeoutSeconds", strconv.FormatInt(timeout, 10))
+	if opts.ResourceVersion != "" {
+		query.Set("resourceVersion", opts.ResourceVersion)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := c.newRequest(ctx, res, query)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, decodeStatusError(resp)
+	}
+
+	w := &streamWatcher{
+		body:   resp.Body,
+		cancel: cancel,
+		result: make(chan Event),
+		done:   make(chan struct{}),
+	}
+	go w.receive()
+	return w, nil
+}
+
+func decodeStatusError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	status := new(StatusError)
+	if err := json.Unmarshal(body, status); err != nil || status.Code == 0 {
+		status.Code = resp.StatusCode
+		status.Reason = resp.Status
+		status.Message = string(body)
+	}
+	return status
+}
+
+// streamWatcher decodes the watch events from the chunked response body
+type streamWatcher struct {
+	body     io.ReadCloser
+	cancel   context.CancelFunc
+	result   chan Event
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// ResultChan returns the channel of the events
+func (w *streamWatcher) ResultChan() <-chan Event {
+	return w.result
+}
+
+// Stop stops the watch
+func (w *streamWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.cancel()
+	})
+}
+
+func (w *streamWatcher) receive() {
+	defer close(w.result)
+	defer w.body.Close()
+	defer w.Stop()
+
+	decoder := json.NewDecoder(w.body)
+	for {
+		event := Event{}
+		if err := decoder.Decode(&event); err != nil {
+			return
+		}
+
+		select {
+		case w.result <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,269 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubeclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// fakeWatchBuffer is the buffer size of a fake watcher, the watcher is closed when the buffer is full and the
+// client is expected to watch again from the latest resource version it received
+const fakeWatchBuffer = 1024
+
+// Fake is an in-memory Interface used to test the collector, objects are added, updated and deleted directly
+// and the changes are sent to the watchers of the resource.
+type Fake struct {
+	lock      sync.Mutex
+	rv        int64
+	objects   map[Resource]map[string]Object
+	history   map[Resource][]fakeEvent
+	watchers  map[Resource][]*fakeWatcher
+	notServed map[Resource]bool
+}
+
+type fakeEvent struct {
+	rv    int64
+	event Event
+}
+
+// NewFake creates a new empty fake client
+func NewFake() *Fake {
+	return &Fake{
+		objects:   make(map[Resource]map[string]Object),
+		history:   make(map[Resource][]fakeEvent),
+		watchers:  make(map[Resource][]*fakeWatcher),
+		notServed: make(map[Resource]bool),
+	}
+}
+
+// NotServe makes the resource not served by the fake cluster, like a crd that is not installed
+func (f *Fake) NotServe(res Resource) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.notServed[res] = true
+}
+
+// Add adds a kubernetes object of the resource, obj is any value that can be encoded to the object json
+func (f *Fake) Add(res Resource, obj interface{}) error {
+	return f.apply(res, Added, obj)
+}
+
+// Update updates a kubernetes object of the resource
+func (f *Fake) Update(res Resource, obj interface{}) error {
+	return f.apply(res, Modified, obj)
+}
+
+// Delete deletes a kubernetes object of the resource by its namespace and name
+func (f *Fake) Delete(res Resource, namespace, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := (&Object{Metadata: ObjectMeta{Namespace: namespace, Name: name}}).Key()
+	obj, exists := f.objects[res][key]
+	if !exists {
+		return fmt.Errorf("%s %s not found", res, key)
+	}
+	delete(f.objects[res], key)
+	f.rv++
+	f.broadcast(res, Event{Type: Deleted, Object: obj})
+	return nil
+}
+
+func (f *Fake) apply(res Resource, eventType EventType, value interface{}) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.rv++
+	obj, err := fakeObject(value, f.rv)
+	if err != nil {
+		return err
+	}
+
+	if f.objects[res] == nil {
+		f.objects[res] = make(map[string]Object)
+	}
+	_, exists := f.objects[res][obj.Key()]
+	switch {
+	case eventType == Added && exists:
+		return fmt.Errorf("%s %s already exists", res, obj.Key())
+	case eventType == Modified && !exists:
+		return fmt.Errorf("%s %s not found", res, obj.Key())
+	}
+
+	f.objects[res][obj.Key()] = *obj
+	f.broadcast(res, Event{Type: eventType, Object: *obj})
+	return nil
+}
+
+// fakeObject encodes the value to an object json with the resource version set
+func fakeObject(value interface{}, rv int64) (*Object, error) {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	if err = json.Unmarshal(js, &raw); err != nil {
+		return nil, err
+	}
+	meta, ok := raw["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("object has no metadata")
+	}
+	meta["resourceVersion"] = strconv.FormatInt(rv, 10)
+
+	if js, err = json.Marshal(raw); err != nil {
+		return nil, err
+	}
+
+	obj := new(Object)
+	if err = obj.UnmarshalJSON(js); err != nil {
+		return nil, err
+	}
+	if obj.Metadata.Name == "" {
+		return nil, errors.New("object has no name")
+	}
+	return obj, nil
+}
+
+func (f *Fake) broadcast(res Resource, event Event) {
+	f.history[res] = append(f.history[res], fakeEvent{rv: f.rv, event: event})
+
+	alive := f.watchers[res][:0]
+	for _, w := range f.watchers[res] {
+		if w.send(event) {
+			alive = append(alive, w)
+		}
+	}
+	f.watchers[res] = alive
+}
+
+// List lists one page of the resource, the continue token is the offset of the next page
+func (f *Fake) List(_ context.Context, res Resource, opts ListOptions) (*ObjectList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.notServed[res] {
+		return nil, &StatusError{Code: http.StatusNotFound, Reason: "NotFound", Message: res.String()}
+	}
+
+	keys := make([]string, 0, len(f.objects[res]))
+	for key := range f.objects[res] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if opts.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, &StatusError{Code: http.StatusBadRequest, Reason: "BadRequest", Message: "invalid continue"}
+		}
+	}
+
+	end := len(keys)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+
+	list := &ObjectList{ResourceVersion: strconv.FormatInt(f.rv, 10), Items: make([]Object, 0)}
+	for _, key := range keys[start:end] {
+		list.Items = append(list.Items, f.objects[res][key])
+	}
+	if end < len(keys) {
+		list.Continue = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+// Watch watches the resource, the events after the resource version of the options are replayed first
+func (f *Fake) Watch(ctx context.Context, res Resource, opts ListOptions) (Watcher, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.notServed[res] {
+		return nil, &StatusError{Code: http.StatusNotFound, Reason: "NotFound", Message: res.String()}
+	}
+
+	rv, err := strconv.ParseInt(opts.ResourceVersion, 10, 64)
+	if opts.ResourceVersion != "" && err != nil {
+		return nil, &StatusError{Code: http.StatusGone, Reason: "Expired", Message: "invalid resource version"}
+	}
+
+	w := &fakeWatcher{result: make(chan Event, fakeWatchBuffer)}
+	for _, history := range f.history[res] {
+		if history.rv > rv && !w.send(history.event) {
+			return w, nil
+		}
+	}
+	f.watchers[res] = append(f.watchers[res], w)
+
+	go func() {
+		<-ctx.Done()
+		w.Stop()
+	}()
+	return w, nil
+}
+
+// fakeWatcher is the watcher of the fake client
+type fakeWatcher struct {
+	lock    sync.Mutex
+	stopped bool
+	result  chan Event
+}
+
+// send sends the event to the watcher without blocking, returns false if the watcher is stopped
+func (w *fakeWatcher) send(event Event) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stopped {
+		return false
+	}
+
+	select {
+	case w.result <- event:
+		return true
+	default:
+		w.stopped = true
+		close(w.result)
+		return false
+	}
+}
+
+// ResultChan returns the channel of the events
+func (w *fakeWatcher) ResultChan() <-chan Event {
+	return w.result
+}
+
+// Stop stops the watcher
+func (w *fakeWatcher) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.stopped {
+		w.stopped = true
+		close(w.result)
+	}
+}
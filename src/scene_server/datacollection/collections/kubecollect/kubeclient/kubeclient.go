@@ -0,0 +1,223 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package kubeclient is a minimal kubernetes api client that lists and watches the resources collected by
+// datacollection, it decodes nothing but the object metadata and leaves the rest to the collector.
+package kubeclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Resource is a kind of kubernetes api resource
+type Resource struct {
+	// Group api group of the resource, empty for the core group
+	Group string
+	// Version api version of the resource
+	Version string
+	// Name plural name of the resource, e.g. deployments
+	Name string
+}
+
+// Path returns the api path to list or watch the resource in all namespaces
+func (r Resource) Path() string {
+	if r.Group == "" {
+		return fmt.Sprintf("/api/%s/%s", r.Version, r.Name)
+	}
+	return fmt.Sprintf("/apis/%s/%s/%s", r.Group, r.Version, r.Name)
+}
+
+// String returns the resource name with its group
+func (r Resource) String() string {
+	if r.Group == "" {
+		return r.Name
+	}
+	return r.Name + "." + r.Group
+}
+
+// the resources collected by datacollection
+var (
+	Nodes            = Resource{Version: "v1", Name: "nodes"}
+	Namespaces       = Resource{Version: "v1", Name: "namespaces"}
+	Pods             = Resource{Version: "v1", Name: "pods"}
+	Deployments      = Resource{Group: "apps", Version: "v1", Name: "deployments"}
+	StatefulSets     = Resource{Group: "apps", Version: "v1", Name: "statefulsets"}
+	DaemonSets       = Resource{Group: "apps", Version: "v1", Name: "daemonsets"}
+	Jobs             = Resource{Group: "batch", Version: "v1", Name: "jobs"}
+	CronJobs         = Resource{Group: "batch", Version: "v1", Name: "cronjobs"}
+	GameDeployments  = Resource{Group: "tkex.tencent.com", Version: "v1alpha1", Name: "gamedeployments"}
+	GameStatefulSets = Resource{Group: "tkex.tencent.com", Version: "v1alpha1", Name: "gamestatefulsets"}
+)
+
+// OwnerReference is the owner of a kubernetes object
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller *bool  `json:"controller,omitempty"`
+}
+
+// ObjectMeta is the metadata of a kubernetes object
+type ObjectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace,omitempty"`
+	UID               string            `json:"uid,omitempty"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	OwnerReferences   []OwnerReference  `json:"ownerReferences,omitempty"`
+	DeletionTimestamp *string           `json:"deletionTimestamp,omitempty"`
+}
+
+// Object is a kubernetes object with its metadata decoded and the raw json kept
+type Object struct {
+	Metadata ObjectMeta
+	Raw      json.RawMessage
+}
+
+// Key returns the unique key of the object in its resource, which is namespace/name or name
+func (o *Object) Key() string {
+	if o.Metadata.Namespace == "" {
+		return o.Metadata.Name
+	}
+	return o.Metadata.Namespace + "/" + o.Metadata.Name
+}
+
+// Into decodes the raw json of the object into the given structure
+func (o *Object) Into(v interface{}) error {
+	return json.Unmarshal(o.Raw, v)
+}
+
+// UnmarshalJSON decodes the metadata of the object and keeps the raw json
+func (o *Object) UnmarshalJSON(data []byte) error {
+	meta := struct {
+		Metadata ObjectMeta `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	o.Metadata = meta.Metadata
+	o.Raw = append(o.Raw[:0], data...)
+	return nil
+}
+
+// MarshalJSON returns the raw json of the object
+func (o Object) MarshalJSON() ([]byte, error) {
+	if len(o.Raw) == 0 {
+		return json.Marshal(map[string]interface{}{"metadata": o.Metadata})
+	}
+	return o.Raw, nil
+}
+
+// ListOptions is the options to list or watch resources
+type ListOptions struct {
+	// Limit the max count of objects in one page
+	Limit int64
+	// Continue the continue token returned by the previous page
+	Continue string
+	// ResourceVersion the resource version to start watching from
+	ResourceVersion string
+	// TimeoutSeconds the timeout of a watch request
+	TimeoutSeconds int64
+}
+
+// ObjectList is a page of listed objects
+type ObjectList struct {
+	ResourceVersion string
+	Continue        string
+	Items           []Object
+}
+
+// EventType is the type of watch event
+type EventType string
+
+const (
+	// Added object is added
+	Added EventType = "ADDED"
+	// Modified object is modified
+	Modified EventType = "MODIFIED"
+	// Deleted object is deleted
+	Deleted EventType = "DELETED"
+	// Bookmark carries the latest resource version only
+	Bookmark EventType = "BOOKMARK"
+	// Error the watch failed, the object is a status
+	Error EventType = "ERROR"
+)
+
+// Event is a watch event
+type Event struct {
+	Type   EventType `json:"type"`
+	Object Object    `json:"object"`
+}
+
+// Watcher is a watch stream of a resource
+type Watcher interface {
+	// ResultChan returns the channel of the events, it is closed when the watch ends
+	ResultChan() <-chan Event
+	// Stop stops the watch
+	Stop()
+}
+
+// Interface is the kubernetes client used by the collector
+type Interface interface {
+	// List lists one page of the resource in all namespaces
+	List(ctx context.Context, res Resource, opts ListOptions) (*ObjectList, error)
+	// Watch watches the resource in all namespaces from the resource version of the options
+	Watch(ctx context.Context, res Resource, opts ListOptions) (Watcher, error)
+}
+
+// StatusError is the error status returned by the kubernetes api server
+type StatusError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("kubernetes api error, code: %d, reason: %s, message: %s", e.Code, e.Reason, e.Message)
+}
+
+// IsGone returns if the error means the resource version is too old and the resource need to be relisted
+func IsGone(err error) bool {
+	return statusCode(err) == http.StatusGone
+}
+
+// IsNotFound returns if the error means the resource is not served by the cluster, e.g. the crd is not installed
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+func statusCode(err error) int {
+	statusErr := new(StatusError)
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	return 0
+}
+
+// ErrorFromEvent returns the error carried by an ERROR watch event
+func ErrorFromEvent(event Event) error {
+	status := new(StatusError)
+	if err := event.Object.Into(status); err != nil || status.Code == 0 {
+		return fmt.Errorf("watch failed, status: %s", string(event.Object.Raw))
+	}
+	return status
+}
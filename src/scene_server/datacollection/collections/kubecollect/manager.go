@@ -0,0 +1,175 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package kubecollect collects the resources of the registered kubernetes clusters into cc
+package kubecollect
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	kubeapi "configcenter/src/apimachinery/toposerver/kube"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+	"configcenter/src/storage/dal"
+)
+
+const (
+	// loadInterval is the interval to load the registered cluster credentials
+	loadInterval = 30 * time.Second
+)
+
+// Sharder decides if the data with the key should be handled by this instance
+type Sharder interface {
+	IsMatch(key string) bool
+}
+
+// Manager runs a collector for each enabled cluster credential that is handled by this instance, the collector is
+// restarted when the credential is changed and stopped when it is disabled or deleted
+type Manager struct {
+	db      dal.RDB
+	kube    kubeapi.KubeOperationInterface
+	hosts   HostGetter
+	cryptor cryptor.Cryptor
+	sharder Sharder
+	// newClient creates the kubernetes client by the kubeconfig, it can be replaced in tests
+	newClient func(content []byte, contextName string) (kubeclient.Interface, error)
+
+	collectors map[int64]*runningCollector
+}
+
+type runningCollector struct {
+	lastTime int64
+	cancel   context.CancelFunc
+}
+
+// NewManager creates a kube collector manager, the kubeconfig is decrypted by the cryptor if it is not nil
+func NewManager(db dal.RDB, kube kubeapi.KubeOperationInterface, crypto cryptor.Cryptor, sharder Sharder) *Manager {
+	return &Manager{
+		db:         db,
+		kube:       kube,
+		hosts:      NewHostGetter(db),
+		cryptor:    crypto,
+		sharder:    sharder,
+		newClient:  kubeclient.NewForKubeConfig,
+		collectors: make(map[int64]*runningCollector),
+	}
+}
+
+// Run loads the cluster credentials and runs the collectors until the context is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(loadInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.sync(ctx); err != nil {
+			blog.Errorf("[KubeCollect] sync cluster collectors failed, err: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, running := range m.collectors {
+				running.cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync starts the collectors of the new or changed credentials and stops the stale ones
+func (m *Manager) sync(ctx context.Context) error {
+	cond := map[string]interface{}{types.EnabledField: true}
+	creds := make([]types.ClusterCredential, 0)
+	if err := m.db.Table(types.BKTableNameClusterCredential).Find(cond).All(ctx, &creds); err != nil {
+		return fmt.Errorf("find cluster credentials failed, err: %v", err)
+	}
+
+	wanted := make(map[int64]struct{})
+	for idx := range creds {
+		cred := &creds[idx]
+		if !m.sharder.IsMatch(strconv.FormatInt(cred.ClusterID, 10)) {
+			continue
+		}
+		wanted[cred.ClusterID] = struct{}{}
+
+		running, exists := m.collectors[cred.ClusterID]
+		if exists && running.lastTime == cred.LastTime {
+			continue
+		}
+		if exists {
+			running.cancel()
+			delete(m.collectors, cred.ClusterID)
+		}
+
+		if err := m.start(ctx, cred); err != nil {
+			blog.Errorf("[KubeCollect] start collecting cluster %d failed, err: %v", cred.ClusterID, err)
+			m.saveStatus(ctx, cred.ClusterID, &types.CollectStatus{State: types.CollectStateFailed,
+				LastSyncTime: time.Now().Unix(), Message: err.Error()})
+		}
+	}
+
+	for clusterID, running := range m.collectors {
+		if _, ok := wanted[clusterID]; !ok {
+			running.cancel()
+			delete(m.collectors, clusterID)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) start(ctx context.Context, cred *types.ClusterCredential) error {
+	kubeConfig := cred.KubeConfig
+	if m.cryptor != nil {
+		var err error
+		if kubeConfig, err = m.cryptor.Decrypt(kubeConfig); err != nil {
+			return fmt.Errorf("decrypt kubeconfig failed, err: %v", err)
+		}
+	}
+
+	client, err := m.newClient([]byte(kubeConfig), cred.Context)
+	if err != nil {
+		return err
+	}
+
+	clusterID := cred.ClusterID
+	if cred.Modifier == "" {
+		cred.Modifier = common.CCSystemCollectorUserName
+	}
+	saveStatus := func(ctx context.Context, status *types.CollectStatus) {
+		m.saveStatus(ctx, clusterID, status)
+	}
+
+	collectorCtx, cancel := context.WithCancel(ctx)
+	m.collectors[clusterID] = &runningCollector{lastTime: cred.LastTime, cancel: cancel}
+	go newCollector(cred, client, m.kube, m.hosts, saveStatus).run(collectorCtx)
+	return nil
+}
+
+// saveStatus saves the collecting status of the cluster, the revision is not changed so that the collector is
+// not restarted by it
+func (m *Manager) saveStatus(ctx context.Context, clusterID int64, status *types.CollectStatus) {
+	cond := map[string]interface{}{types.BKClusterIDFiled: clusterID}
+	data := map[string]interface{}{types.SyncStatusField: status}
+	if err := m.db.Table(types.BKTableNameClusterCredential).Update(ctx, cond, data); err != nil {
+		blog.Errorf("[KubeCollect] save status of cluster %d failed, err: %v", clusterID, err)
+	}
+}
@@ -0,0 +1,869 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	kubeapi "configcenter/src/apimachinery/toposerver/kube"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+)
+
+const (
+	// writeBatchSize is the max count of resources to create, update or delete in one request
+	writeBatchSize = 100
+)
+
+var (
+	nodeCompareFields = []string{types.RolesField, types.LabelsField, types.TaintsField, types.UnschedulableField,
+		types.InternalIPField, types.ExternalIPField, types.HostnameField, types.RuntimeComponentField,
+		types.PodCidrField}
+	namespaceCompareFields = []string{types.LabelsField}
+	workloadCompareFields  = []string{types.LabelsField, types.SelectorField, types.ReplicasField,
+		types.MinReadySecondsField, types.StrategyTypeField, types.RollingUpdateStrategyField}
+)
+
+// HostGetter gets the cc hosts that the kubernetes nodes run on
+type HostGetter interface {
+	// GetHostIDsByIP returns the map of the ip to the id of the host with the inner ip in the business,
+	// the ips that match more than one host are not returned
+	GetHostIDsByIP(ctx context.Context, bizID int64, ips []string) (map[string]int64, error)
+}
+
+// snapshot is the kubernetes resources of a cluster at a moment
+type snapshot struct {
+	nodes      []kubeclient.Object
+	namespaces []kubeclient.Object
+	workloads  map[types.WorkloadType][]kubeclient.Object
+	pods       []kubeclient.Object
+}
+
+// ccResource is the id and business of a resource in cc
+type ccResource struct {
+	id    int64
+	bizID int64
+}
+
+// reconciler makes the kube resources of a cluster in cc consistent with the snapshot of the cluster,
+// a new reconciler is used for each reconciliation
+type reconciler struct {
+	ctx    context.Context
+	kube   kubeapi.KubeOperationInterface
+	hosts  HostGetter
+	cred   *types.ClusterCredential
+	header http.Header
+	rid    string
+
+	cluster *types.Cluster
+	status  *types.CollectStatus
+	errs    []string
+
+	// nodes is the map of the node name to the node in cc
+	nodes map[string]types.Node
+	// namespaces is the map of the namespace name to the namespace in cc
+	namespaces map[string]ccResource
+	// workloads is the map of the workload kind to the map of namespace/name to the workload in cc
+	workloads map[types.WorkloadType]map[string]ccResource
+}
+
+func newReconciler(ctx context.Context, kube kubeapi.KubeOperationInterface, hosts HostGetter,
+	cred *types.ClusterCredential) *reconciler {
+
+	header := headerutil.BuildHeader(cred.Modifier, cred.SupplierAccount)
+	return &reconciler{
+		ctx:        ctx,
+		kube:       kube,
+		hosts:      hosts,
+		cred:       cred,
+		header:     header,
+		rid:        httpheader.GetRid(header),
+		status:     &types.CollectStatus{Conflicts: make([]types.NsBizConflict, 0), UnmatchedNodes: make([]string, 0)},
+		errs:       make([]string, 0),
+		nodes:      make(map[string]types.Node),
+		namespaces: make(map[string]ccResource),
+		workloads:  make(map[types.WorkloadType]map[string]ccResource),
+	}
+}
+
+// reconcile reconciles the snapshot into cc, the resources are created and updated from top to bottom, then the
+// stale ones are deleted from bottom to top, so that the parent resource always exists for its children.
+// The failed operations do not stop the reconciliation, they are returned as one error at the end.
+func (r *reconciler) reconcile(snap *snapshot) (*types.CollectStatus, error) {
+	if err := r.getCluster(); err != nil {
+		return nil, err
+	}
+
+	existNodes, err := r.listNodes()
+	if err != nil {
+		return nil, fmt.Errorf("list cc nodes failed, err: %v", err)
+	}
+	existNamespaces, err := r.listNamespaces()
+	if err != nil {
+		return nil, fmt.Errorf("list cc namespaces failed, err: %v", err)
+	}
+	existWorkloads := make(map[types.WorkloadType][]types.WorkloadInterface)
+	for _, kind := range workloadKinds() {
+		if existWorkloads[kind], err = r.listWorkloads(kind); err != nil {
+			return nil, fmt.Errorf("list cc %s workloads failed, err: %v", kind, err)
+		}
+	}
+	existPods, err := r.listPods()
+	if err != nil {
+		return nil, fmt.Errorf("list cc pods failed, err: %v", err)
+	}
+
+	pods := make([]*podInfo, 0, len(snap.pods))
+	for idx := range snap.pods {
+		pod, err := convertPod(&snap.pods[idx])
+		if err != nil {
+			r.addError(err)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+
+	staleNodes := r.syncNodes(snap.nodes, existNodes)
+	staleNamespaces := r.syncNamespaces(snap.namespaces, existNamespaces)
+	staleWorkloads := r.syncWorkloads(snap.workloads, pods, existWorkloads)
+	r.syncPods(snap.pods, pods, existPods)
+	r.deleteWorkloads(staleWorkloads)
+	r.deleteNamespaces(staleNamespaces)
+	r.deleteNodes(staleNodes)
+
+	sort.Slice(r.status.Conflicts, func(i, j int) bool {
+		return r.status.Conflicts[i].Namespace < r.status.Conflicts[j].Namespace
+	})
+	sort.Strings(r.status.UnmatchedNodes)
+	r.status.LastSyncTime = time.Now().Unix()
+
+	if len(r.errs) > 0 {
+		return r.status, errors.New(strings.Join(r.errs, "; "))
+	}
+	return r.status, nil
+}
+
+func (r *reconciler) addError(err error) {
+	blog.Errorf("[KubeCollect] reconcile cluster %d failed, err: %v, rid: %s", r.cred.ClusterID, err, r.rid)
+	r.errs = append(r.errs, err.Error())
+}
+
+func (r *reconciler) clusterFilter() *filter.Expression {
+	return filtertools.GenAtomFilter(types.BKClusterIDFiled, filter.Equal, r.cluster.ID)
+}
+
+func (r *reconciler) getCluster() error {
+	opt := &types.QueryClusterOption{
+		BizID:  r.cred.BizID,
+		Filter: filtertools.GenAtomFilter(types.BKIDField, filter.Equal, r.cred.ClusterID),
+		Page:   metadata.BasePage{Limit: 1},
+	}
+	resp, err := r.kube.SearchCluster(r.ctx, r.header, opt)
+	if err != nil {
+		return fmt.Errorf("search cluster %d failed, err: %v", r.cred.ClusterID, err)
+	}
+
+	clusters := make([]types.Cluster, 0)
+	if err := decodeInfo(resp.Data, &clusters); err != nil {
+		return fmt.Errorf("decode cluster %d failed, err: %v", r.cred.ClusterID, err)
+	}
+	if len(clusters) == 0 || clusters[0].Uid == nil {
+		return fmt.Errorf("cluster %d is not found in business %d", r.cred.ClusterID, r.cred.BizID)
+	}
+
+	r.cluster = &clusters[0]
+	return nil
+}
+
+func (r *reconciler) listNodes() ([]types.Node, error) {
+	result := make([]types.Node, 0)
+	opt := &types.QueryNodeOption{
+		BizID:  r.cluster.BizID,
+		Filter: r.clusterFilter(),
+		Page:   metadata.BasePage{Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+	}
+	for {
+		resp, err := r.kube.SearchNode(r.ctx, r.header, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes := make([]types.Node, 0)
+		if decodeErr := decodeInfo(resp.Data, &nodes); decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, nodes...)
+
+		if len(nodes) < opt.Page.Limit {
+			return result, nil
+		}
+		opt.Page.Start += opt.Page.Limit
+	}
+}
+
+func (r *reconciler) listNamespaces() ([]types.Namespace, error) {
+	result := make([]types.Namespace, 0)
+	opt := &types.NsQueryOption{
+		BizID:  r.cluster.BizID,
+		Filter: r.clusterFilter(),
+		Page:   metadata.BasePage{Limit: types.NsQueryLimit, Sort: types.BKIDField},
+	}
+	for {
+		resp, err := r.kube.ListNamespace(r.ctx, r.header, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaces := make([]types.Namespace, 0)
+		if decodeErr := decodeInfo(resp, &namespaces); decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, namespaces...)
+
+		if len(namespaces) < opt.Page.Limit {
+			return result, nil
+		}
+		opt.Page.Start += opt.Page.Limit
+	}
+}
+
+func (r *reconciler) listWorkloads(kind types.WorkloadType) ([]types.WorkloadInterface, error) {
+	result := make([]types.WorkloadInterface, 0)
+	opt := &types.WlQueryOption{
+		BizID:  r.cluster.BizID,
+		Filter: r.clusterFilter(),
+		Page:   metadata.BasePage{Limit: types.WlQueryLimit, Sort: types.BKIDField},
+	}
+	for {
+		resp, err := r.kube.ListWorkload(r.ctx, r.header, kind, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		js, jsErr := json.Marshal(resp.Info)
+		if jsErr != nil {
+			return nil, jsErr
+		}
+		workloads, jsErr := types.WlArrayUnmarshalJSON(kind, js)
+		if jsErr != nil {
+			return nil, jsErr
+		}
+		result = append(result, workloads...)
+
+		if len(workloads) < opt.Page.Limit {
+			return result, nil
+		}
+		opt.Page.Start += opt.Page.Limit
+	}
+}
+
+func (r *reconciler) listPods() ([]types.Pod, error) {
+	result := make([]types.Pod, 0)
+	opt := &types.PodQueryOption{
+		BizID:  r.cluster.BizID,
+		Filter: r.clusterFilter(),
+		Fields: []string{types.BKIDField, types.BKBizIDField, types.KubeNameField, types.NamespaceField,
+			types.RefField, types.BKNodeIDField, common.BKHostIDField, types.IPField},
+		Page: metadata.BasePage{Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+	}
+	for {
+		resp, err := r.kube.ListPod(r.ctx, r.header, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		pods := make([]types.Pod, 0)
+		if decodeErr := decodeInfo(resp, &pods); decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, pods...)
+
+		if len(pods) < opt.Page.Limit {
+			return result, nil
+		}
+		opt.Page.Start += opt.Page.Limit
+	}
+}
+
+// syncNodes creates the new nodes whose hosts are found and updates the changed ones, returns the stale nodes
+func (r *reconciler) syncNodes(objects []kubeclient.Object, exists []types.Node) []ccResource {
+	existMap := make(map[string]types.Node)
+	for _, node := range exists {
+		if node.Name != nil {
+			existMap[*node.Name] = node
+		}
+	}
+
+	creates := make([]*types.Node, 0)
+	ips := make([]string, 0)
+	desired := make(map[string]struct{})
+	for idx := range objects {
+		node, err := convertNode(&objects[idx])
+		if err != nil {
+			r.addError(err)
+			continue
+		}
+		desired[*node.Name] = struct{}{}
+
+		exist, ok := existMap[*node.Name]
+		if !ok {
+			creates = append(creates, node)
+			ips = append(ips, *node.InternalIP...)
+			continue
+		}
+		r.nodes[*node.Name] = exist
+
+		changed, err := fieldsChanged(node, &exist, nodeCompareFields)
+		if err != nil || !changed {
+			continue
+		}
+
+		update := *node
+		update.Name = nil
+		opt := &types.UpdateNodeOption{BizID: exist.BizID, UpdateNodeByIDsOption: types.UpdateNodeByIDsOption{
+			IDs: []int64{exist.ID}, Data: update}}
+		if err = r.kube.UpdateNodeFields(r.ctx, r.header, opt); err != nil {
+			r.addError(fmt.Errorf("update node %s failed, err: %v", *node.Name, err))
+		}
+	}
+
+	r.createNodes(creates, ips)
+
+	stale := make([]ccResource, 0)
+	for name, node := range existMap {
+		if _, ok := desired[name]; !ok {
+			stale = append(stale, ccResource{id: node.ID, bizID: node.BizID})
+		}
+	}
+	return stale
+}
+
+func (r *reconciler) createNodes(nodes []*types.Node, ips []string) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	hostIDs := make(map[string]int64)
+	if len(ips) > 0 {
+		var err error
+		hostIDs, err = r.hosts.GetHostIDsByIP(r.ctx, r.cluster.BizID, ips)
+		if err != nil {
+			r.addError(fmt.Errorf("get hosts of nodes failed, err: %v", err))
+			return
+		}
+	}
+
+	creates := make([]types.OneNodeCreateOption, 0)
+	for _, node := range nodes {
+		hostID := int64(0)
+		for _, ip := range *node.InternalIP {
+			if hostIDs[ip] != 0 {
+				hostID = hostIDs[ip]
+				break
+			}
+		}
+
+		if hostID == 0 {
+			r.status.UnmatchedNodes = append(r.status.UnmatchedNodes, *node.Name)
+			continue
+		}
+
+		creates = append(creates, types.OneNodeCreateOption{BizID: r.cluster.BizID, HostID: hostID,
+			ClusterID: r.cluster.ID, Node: *node})
+	}
+
+	for start := 0; start < len(creates); start += writeBatchSize {
+		batch := creates[start:minInt(start+writeBatchSize, len(creates))]
+		ids, err := r.kube.BatchCreateNode(r.ctx, r.header, &types.CreateNodesOption{BizID: r.cluster.BizID,
+			Nodes: batch})
+		if err != nil {
+			r.addError(fmt.Errorf("create nodes failed, err: %v", err))
+			continue
+		}
+
+		for idx, id := range ids {
+			node := batch[idx].Node
+			node.ID, node.BizID, node.HostID, node.ClusterID = id, r.cluster.BizID, batch[idx].HostID, r.cluster.ID
+			r.nodes[*node.Name] = node
+		}
+	}
+}
+
+// syncNamespaces creates the new namespaces and updates the changed ones, returns the stale namespaces.
+// The business of a namespace is the value of its business label, or the business of the cluster. A namespace
+// that already exists in cc keeps its business, the difference is recorded as a conflict and left unchanged.
+func (r *reconciler) syncNamespaces(objects []kubeclient.Object, exists []types.Namespace) []ccResource {
+	existMap := make(map[string]types.Namespace)
+	for _, ns := range exists {
+		existMap[ns.Name] = ns
+	}
+
+	creates := make(map[int64][]types.Namespace)
+	desired := make(map[string]struct{})
+	for idx := range objects {
+		ns := convertNamespace(&objects[idx])
+		desired[ns.Name] = struct{}{}
+		bizID := r.namespaceBiz(&objects[idx])
+
+		exist, ok := existMap[ns.Name]
+		if !ok {
+			ns.ClusterSpec = types.ClusterSpec{BizID: bizID, ClusterID: r.cluster.ID, ClusterUID: *r.cluster.Uid}
+			creates[bizID] = append(creates[bizID], *ns)
+			continue
+		}
+		r.namespaces[ns.Name] = ccResource{id: exist.ID, bizID: exist.BizID}
+
+		if exist.BizID != bizID {
+			r.addConflict(ns.Name, exist.BizID, bizID, "namespace belongs to another business in cc")
+		}
+
+		changed, err := fieldsChanged(ns, &exist, namespaceCompareFields)
+		if err != nil || !changed {
+			continue
+		}
+
+		opt := &types.NsUpdateOption{BizID: exist.BizID, NsUpdateByIDsOption: types.NsUpdateByIDsOption{
+			IDs: []int64{exist.ID}, Data: &types.Namespace{Labels: ns.Labels}}}
+		if err = r.kube.UpdateNamespace(r.ctx, r.header, opt); err != nil {
+			r.addError(fmt.Errorf("update namespace %s failed, err: %v", ns.Name, err))
+		}
+	}
+
+	for bizID, namespaces := range creates {
+		for start := 0; start < len(namespaces); start += writeBatchSize {
+			batch := namespaces[start:minInt(start+writeBatchSize, len(namespaces))]
+			resp, err := r.kube.CreateNamespace(r.ctx, r.header, &types.NsCreateOption{BizID: bizID, Data: batch})
+			if err != nil {
+				r.addError(fmt.Errorf("create namespaces in business %d failed, err: %v", bizID, err))
+				continue
+			}
+
+			for idx, id := range resp.IDs {
+				r.namespaces[batch[idx].Name] = ccResource{id: id, bizID: bizID}
+			}
+		}
+	}
+
+	stale := make([]ccResource, 0)
+	for name, ns := range existMap {
+		if _, ok := desired[name]; !ok {
+			stale = append(stale, ccResource{id: ns.ID, bizID: ns.BizID})
+		}
+	}
+	return stale
+}
+
+// namespaceBiz returns the business that the namespace is expected to belong to
+func (r *reconciler) namespaceBiz(obj *kubeclient.Object) int64 {
+	if r.cred.NsBizLabel == "" {
+		return r.cluster.BizID
+	}
+
+	value, ok := obj.Metadata.Labels[r.cred.NsBizLabel]
+	if !ok || value == "" {
+		return r.cluster.BizID
+	}
+
+	bizID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || bizID <= 0 {
+		r.addConflict(obj.Metadata.Name, r.cluster.BizID, 0, fmt.Sprintf("business label value %s is invalid",
+			value))
+		return r.cluster.BizID
+	}
+
+	if bizID != r.cluster.BizID && (r.cluster.Type == nil || *r.cluster.Type != types.SharedClusterType) {
+		r.addConflict(obj.Metadata.Name, r.cluster.BizID, bizID, "cluster is not a shared cluster")
+		return r.cluster.BizID
+	}
+	return bizID
+}
+
+func (r *reconciler) addConflict(namespace string, bizID, expectBizID int64, reason string) {
+	r.status.Conflicts = append(r.status.Conflicts, types.NsBizConflict{
+		Namespace:   namespace,
+		BizID:       bizID,
+		ExpectBizID: expectBizID,
+		Reason:      reason,
+	})
+}
+
+// syncWorkloads creates the new workloads and updates the changed ones, returns the stale workloads of each kind.
+// A pods workload is desired for each namespace that has pods without a known owner.
+func (r *reconciler) syncWorkloads(objects map[types.WorkloadType][]kubeclient.Object, pods []*podInfo,
+	exists map[types.WorkloadType][]types.WorkloadInterface) map[types.WorkloadType][]ccResource {
+
+	desired := make(map[types.WorkloadType]map[string]types.WorkloadInterface)
+	for kind, kindObjects := range objects {
+		desired[kind] = make(map[string]types.WorkloadInterface)
+		for idx := range kindObjects {
+			wl, err := convertWorkload(kind, &kindObjects[idx])
+			if err != nil {
+				r.addError(err)
+				continue
+			}
+			desired[kind][kindObjects[idx].Key()] = wl
+		}
+	}
+
+	desired[types.KubePodWorkload] = make(map[string]types.WorkloadInterface)
+	for _, pod := range pods {
+		if pod.ownerKind != types.KubePodWorkload {
+			continue
+		}
+		key := pod.namespace + "/" + podsWorkloadName
+		if _, ok := desired[types.KubePodWorkload][key]; ok {
+			continue
+		}
+		wl, err := newWorkload(types.KubePodWorkload, map[string]interface{}{types.KubeNameField: podsWorkloadName})
+		if err != nil {
+			r.addError(err)
+			continue
+		}
+		desired[types.KubePodWorkload][key] = wl
+	}
+
+	stale := make(map[types.WorkloadType][]ccResource)
+	for _, kind := range workloadKinds() {
+		r.workloads[kind] = make(map[string]ccResource)
+		stale[kind] = r.syncKindWorkloads(kind, desired[kind], exists[kind])
+	}
+	return stale
+}
+
+func (r *reconciler) syncKindWorkloads(kind types.WorkloadType, desired map[string]types.WorkloadInterface,
+	exists []types.WorkloadInterface) []ccResource {
+
+	existMap := make(map[string]types.WorkloadInterface)
+	for _, wl := range exists {
+		base := wl.GetWorkloadBase()
+		existMap[base.Namespace+"/"+base.Name] = wl
+	}
+
+	creates := make(map[int64][]types.WorkloadInterface)
+	createKeys := make(map[int64][]string)
+	for key, wl := range desired {
+		exist, ok := existMap[key]
+		if ok {
+			existBase := exist.GetWorkloadBase()
+			r.workloads[kind][key] = ccResource{id: existBase.ID, bizID: existBase.BizID}
+
+			changed, err := fieldsChanged(wl, exist, workloadCompareFields)
+			if err != nil || !changed {
+				continue
+			}
+
+			// the name is not editable, send the editable fields only
+			wl.SetWorkloadBase(types.WorkloadBase{})
+			opt := &types.WlUpdateOption{BizID: existBase.BizID, WlUpdateByIDsOption: types.WlUpdateByIDsOption{
+				Kind: kind, IDs: []int64{existBase.ID}, Data: wl}}
+			if err = r.kube.UpdateWorkload(r.ctx, r.header, kind, opt); err != nil {
+				r.addError(fmt.Errorf("update %s %s failed, err: %v", kind, key, err))
+			}
+			continue
+		}
+
+		nsName := strings.SplitN(key, "/", 2)[0]
+		ns, ok := r.namespaces[nsName]
+		if !ok {
+			// the namespace is failed to be created, the workload is created in the next reconciliation
+			continue
+		}
+
+		base := wl.GetWorkloadBase()
+		base.NamespaceSpec = types.NamespaceSpec{
+			ClusterSpec: types.ClusterSpec{BizID: ns.bizID, ClusterID: r.cluster.ID, ClusterUID: *r.cluster.Uid},
+			NamespaceID: ns.id,
+			Namespace:   nsName,
+		}
+		wl.SetWorkloadBase(base)
+		creates[ns.bizID] = append(creates[ns.bizID], wl)
+		createKeys[ns.bizID] = append(createKeys[ns.bizID], key)
+	}
+
+	for bizID, workloads := range creates {
+		for start := 0; start < len(workloads); start += writeBatchSize {
+			end := minInt(start+writeBatchSize, len(workloads))
+			opt := &types.WlCreateOption{BizID: bizID, Kind: kind, Data: workloads[start:end]}
+			resp, err := r.kube.CreateWorkload(r.ctx, r.header, kind, opt)
+			if err != nil {
+				r.addError(fmt.Errorf("create %s workloads in business %d failed, err: %v", kind, bizID, err))
+				continue
+			}
+
+			for idx, id := range resp.IDs {
+				r.workloads[kind][createKeys[bizID][start+idx]] = ccResource{id: id, bizID: bizID}
+			}
+		}
+	}
+
+	stale := make([]ccResource, 0)
+	for key, wl := range existMap {
+		if _, ok := desired[key]; !ok {
+			base := wl.GetWorkloadBase()
+			stale = append(stale, ccResource{id: base.ID, bizID: base.BizID})
+		}
+	}
+	return stale
+}
+
+// syncPods creates the ready pods whose node and workload exist in cc, and deletes the pods that are gone or
+// not ready. There is no api to update a pod, so a pod whose workload, node or ip is changed is recreated.
+func (r *reconciler) syncPods(objects []kubeclient.Object, pods []*podInfo, exists []types.Pod) {
+	existMap := make(map[string]types.Pod)
+	for _, pod := range exists {
+		if pod.Name != nil {
+			existMap[pod.Namespace+"/"+*pod.Name] = pod
+		}
+	}
+
+	desired := make(map[string]types.PodsInfo)
+	for _, pod := range pods {
+		info, ok := r.podCreateInfo(pod)
+		if ok {
+			desired[pod.namespace+"/"+*pod.pod.Name] = info
+		}
+	}
+
+	deletes := make(map[int64][]int64)
+	creates := make(map[int64][]types.PodsInfo)
+	for key, info := range desired {
+		exist, ok := existMap[key]
+		if ok && samePod(&exist, &info) {
+			continue
+		}
+		if ok {
+			deletes[exist.BizID] = append(deletes[exist.BizID], exist.ID)
+		}
+		bizID := r.namespaces[info.Pod.Namespace].bizID
+		creates[bizID] = append(creates[bizID], info)
+	}
+
+	for key, exist := range existMap {
+		if _, ok := desired[key]; !ok {
+			deletes[exist.BizID] = append(deletes[exist.BizID], exist.ID)
+		}
+	}
+
+	for bizID, ids := range deletes {
+		for start := 0; start < len(ids); start += writeBatchSize {
+			opt := &types.DeletePodsOption{Data: []types.DeletePodData{{BizID: bizID,
+				PodIDs: ids[start:minInt(start+writeBatchSize, len(ids))]}}}
+			if err := r.kube.DeletePods(r.ctx, r.header, opt); err != nil {
+				r.addError(fmt.Errorf("delete pods in business %d failed, err: %v", bizID, err))
+			}
+		}
+	}
+
+	for bizID, infos := range creates {
+		for start := 0; start < len(infos); start += writeBatchSize {
+			opt := &types.CreatePodsOption{Data: []types.PodsInfoArray{{BizID: bizID,
+				Pods: infos[start:minInt(start+writeBatchSize, len(infos))]}}}
+			if _, err := r.kube.BatchCreatePod(r.ctx, r.header, opt); err != nil {
+				r.addError(fmt.Errorf("create pods in business %d failed, err: %v", bizID, err))
+			}
+		}
+	}
+}
+
+// podCreateInfo returns the pod create info if the pod is ready and its node, namespace and workload exist in cc
+func (r *reconciler) podCreateInfo(pod *podInfo) (types.PodsInfo, bool) {
+	if !pod.ready {
+		return types.PodsInfo{}, false
+	}
+
+	node, ok := r.nodes[pod.nodeName]
+	if !ok {
+		return types.PodsInfo{}, false
+	}
+	ns, ok := r.namespaces[pod.namespace]
+	if !ok {
+		return types.PodsInfo{}, false
+	}
+	wl, ok := r.workloads[pod.ownerKind][pod.namespace+"/"+pod.ownerName]
+	if !ok {
+		return types.PodsInfo{}, false
+	}
+
+	info := types.PodsInfo{
+		Spec: types.SpecSimpleInfo{
+			ClusterID:   r.cluster.ID,
+			NamespaceID: ns.id,
+			Ref:         types.Reference{Kind: pod.ownerKind, Name: pod.ownerName, ID: wl.id},
+			NodeID:      node.ID,
+		},
+		HostID:     node.HostID,
+		Pod:        pod.pod,
+		Containers: pod.containers,
+	}
+	info.Pod.Namespace = pod.namespace
+	info.Pod.Operator = &[]string{r.cred.Modifier}
+	return info, true
+}
+
+// samePod returns if the pod in cc is the same one as the pod to be created
+func samePod(exist *types.Pod, info *types.PodsInfo) bool {
+	if exist.Ref == nil || exist.Ref.Kind != info.Spec.Ref.Kind || exist.Ref.ID != info.Spec.Ref.ID {
+		return false
+	}
+	if exist.NodeID != info.Spec.NodeID || exist.HostID != info.HostID {
+		return false
+	}
+
+	existIP, ip := "", ""
+	if exist.IP != nil {
+		existIP = *exist.IP
+	}
+	if info.Pod.IP != nil {
+		ip = *info.Pod.IP
+	}
+	return existIP == ip
+}
+
+func (r *reconciler) deleteWorkloads(stale map[types.WorkloadType][]ccResource) {
+	for _, kind := range workloadKinds() {
+		for bizID, ids := range groupByBiz(stale[kind]) {
+			for start := 0; start < len(ids); start += writeBatchSize {
+				opt := &types.WlDeleteOption{BizID: bizID, WlDeleteByIDsOption: types.WlDeleteByIDsOption{
+					IDs: ids[start:minInt(start+writeBatchSize, len(ids))]}}
+				if err := r.kube.DeleteWorkload(r.ctx, r.header, kind, opt); err != nil {
+					r.addError(fmt.Errorf("delete %s workloads in business %d failed, err: %v", kind, bizID, err))
+				}
+			}
+		}
+	}
+}
+
+func (r *reconciler) deleteNamespaces(stale []ccResource) {
+	for bizID, ids := range groupByBiz(stale) {
+		for start := 0; start < len(ids); start += writeBatchSize {
+			opt := &types.NsDeleteOption{BizID: bizID, NsDeleteByIDsOption: types.NsDeleteByIDsOption{
+				IDs: ids[start:minInt(start+writeBatchSize, len(ids))]}}
+			if err := r.kube.DeleteNamespace(r.ctx, r.header, opt); err != nil {
+				r.addError(fmt.Errorf("delete namespaces in business %d failed, err: %v", bizID, err))
+			}
+		}
+	}
+}
+
+func (r *reconciler) deleteNodes(stale []ccResource) {
+	for bizID, ids := range groupByBiz(stale) {
+		for start := 0; start < len(ids); start += writeBatchSize {
+			opt := &types.BatchDeleteNodeOption{BizID: bizID, BatchDeleteNodeByIDsOption: types.BatchDeleteNodeByIDsOption{
+				IDs: ids[start:minInt(start+writeBatchSize, len(ids))]}}
+			if err := r.kube.BatchDeleteNode(r.ctx, r.header, opt); err != nil {
+				r.addError(fmt.Errorf("delete nodes in business %d failed, err: %v", bizID, err))
+			}
+		}
+	}
+}
+
+// workloadKinds returns all the workload kinds that the collector reconciles
+func workloadKinds() []types.WorkloadType {
+	kinds := make([]types.WorkloadType, 0, len(workloadResources)+1)
+	for _, res := range workloadResources {
+		kinds = append(kinds, res.kind)
+	}
+	return append(kinds, types.KubePodWorkload)
+}
+
+// fieldsChanged returns if any of the fields of the desired value differs from the existing one, both values are
+// compared by their json encoding. The fields that are not set in the desired value are not compared since they
+// can not be cleared by the update apis.
+func fieldsChanged(desired, exist interface{}, fields []string) (bool, error) {
+	desiredMap, err := toJSONMap(desired)
+	if err != nil {
+		return false, err
+	}
+	existMap, err := toJSONMap(exist)
+	if err != nil {
+		return false, err
+	}
+
+	for _, field := range fields {
+		value, ok := desiredMap[field]
+		if !ok || value == nil {
+			continue
+		}
+		if !reflect.DeepEqual(value, existMap[field]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func toJSONMap(value interface{}) (map[string]interface{}, error) {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	if err = json.Unmarshal(js, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodeInfo decodes the info field of the search result data into the value
+func decodeInfo(data interface{}, value interface{}) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Info json.RawMessage `json:"info"`
+	}{}
+	if err = json.Unmarshal(js, &result); err != nil {
+		return err
+	}
+	if len(result.Info) == 0 || string(result.Info) == "null" {
+		return nil
+	}
+	return json.Unmarshal(result.Info, value)
+}
+
+func groupByBiz(resources []ccResource) map[int64][]int64 {
+	result := make(map[int64][]int64)
+	for _, res := range resources {
+		result[res.bizID] = append(result[res.bizID], res.id)
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
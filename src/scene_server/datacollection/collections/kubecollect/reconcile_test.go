@@ -0,0 +1,540 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kubecollect
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kubeapi "configcenter/src/apimachinery/toposerver/kube"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+)
+
+const (
+	fakeNodeTable = "node"
+	fakeNsTable   = "namespace"
+	fakePodTable  = "pod"
+)
+
+// fakeKube is an in-memory topo server kube client, it checks the references between the resources like cc does
+type fakeKube struct {
+	kubeapi.KubeOperationInterface
+
+	lock    sync.Mutex
+	cluster types.Cluster
+	nextID  int64
+	tables  map[string][]mapstr.MapStr
+}
+
+func newFakeKube(cluster types.Cluster) *fakeKube {
+	return &fakeKube{cluster: cluster, tables: make(map[string][]mapstr.MapStr)}
+}
+
+func (f *fakeKube) insert(table string, value interface{}, extra map[string]interface{}) int64 {
+	row, err := toJSONMap(value)
+	if err != nil {
+		panic(err)
+	}
+	for key, val := range extra {
+		row[key] = val
+	}
+	f.nextID++
+	row[types.BKIDField] = f.nextID
+	f.tables[table] = append(f.tables[table], row)
+	return f.nextID
+}
+
+func (f *fakeKube) update(table string, ids []int64, value interface{}) {
+	data, err := toJSONMap(value)
+	if err != nil {
+		panic(err)
+	}
+	for _, row := range f.tables[table] {
+		if util.InArray(rowInt(row, types.BKIDField), ids) {
+			for key, val := range data {
+				row[key] = val
+			}
+		}
+	}
+}
+
+func (f *fakeKube) remove(table string, ids []int64) {
+	rows := make([]mapstr.MapStr, 0)
+	for _, row := range f.tables[table] {
+		if !util.InArray(rowInt(row, types.BKIDField), ids) {
+			rows = append(rows, row)
+		}
+	}
+	f.tables[table] = rows
+}
+
+// find returns the rows of the table whose field value is in the values
+func (f *fakeKube) find(table, field string, values []int64) []mapstr.MapStr {
+	rows := make([]mapstr.MapStr, 0)
+	for _, row := range f.tables[table] {
+		if util.InArray(rowInt(row, field), values) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (f *fakeKube) page(table string, page metadata.BasePage) []mapstr.MapStr {
+	rows := f.tables[table]
+	if page.Start >= len(rows) {
+		return make([]mapstr.MapStr, 0)
+	}
+	end := page.Start + page.Limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[page.Start:end]
+}
+
+func rowInt(row mapstr.MapStr, field string) int64 {
+	val, _ := util.GetInt64ByInterface(row[field])
+	return val
+}
+
+func podRefID(row mapstr.MapStr) int64 {
+	ref, _ := row[types.RefField].(map[string]interface{})
+	return rowInt(ref, types.BKIDField)
+}
+
+func fakeErr(format string, args ...interface{}) errors.CCErrorCoder {
+	return errors.New(common.CCErrCommParamsInvalid, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeKube) SearchCluster(_ context.Context, _ http.Header, _ *types.QueryClusterOption) (
+	*metadata.Response, errors.CCErrorCoder) {
+	return &metadata.Response{Data: map[string]interface{}{"info": []types.Cluster{f.cluster}}}, nil
+}
+
+func (f *fakeKube) SearchNode(_ context.Context, _ http.Header, opt *types.QueryNodeOption) (*metadata.Response,
+	errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &metadata.Response{Data: map[string]interface{}{"info": f.page(fakeNodeTable, opt.Page)}}, nil
+}
+
+func (f *fakeKube) BatchCreateNode(_ context.Context, _ http.Header, opt *types.CreateNodesOption) ([]int64,
+	errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ids := make([]int64, 0)
+	for _, node := range opt.Nodes {
+		if node.HostID == 0 {
+			return nil, fakeErr("related host invalid")
+		}
+		ids = append(ids, f.insert(fakeNodeTable, node.Node, map[string]interface{}{
+			common.BKAppIDField: opt.BizID, common.BKHostIDField: node.HostID,
+			types.BKClusterIDFiled: node.ClusterID}))
+	}
+	return ids, nil
+}
+
+func (f *fakeKube) UpdateNodeFields(_ context.Context, _ http.Header, opt *types.UpdateNodeOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.update(fakeNodeTable, opt.IDs, opt.Data)
+	return nil
+}
+
+func (f *fakeKube) BatchDeleteNode(_ context.Context, _ http.Header,
+	opt *types.BatchDeleteNodeOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(f.find(fakePodTable, types.BKNodeIDField, opt.IDs)) > 0 {
+		return fakeErr("nodes %v have pods", opt.IDs)
+	}
+	f.remove(fakeNodeTable, opt.IDs)
+	return nil
+}
+
+func (f *fakeKube) ListNamespace(_ context.Context, _ http.Header, opt *types.NsQueryOption) (
+	*metadata.InstDataInfo, errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &metadata.InstDataInfo{Info: f.page(fakeNsTable, opt.Page)}, nil
+}
+
+func (f *fakeKube) CreateNamespace(_ context.Context, _ http.Header, opt *types.NsCreateOption) (*metadata.RspIDs,
+	errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if opt.BizID != f.cluster.BizID && *f.cluster.Type != types.SharedClusterType {
+		return nil, fakeErr("cluster is not shared")
+	}
+	ids := make([]int64, 0)
+	for _, ns := range opt.Data {
+		ids = append(ids, f.insert(fakeNsTable, ns, map[string]interface{}{common.BKAppIDField: opt.BizID}))
+	}
+	return &metadata.RspIDs{IDs: ids}, nil
+}
+
+func (f *fakeKube) UpdateNamespace(_ context.Context, _ http.Header, opt *types.NsUpdateOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.update(fakeNsTable, opt.IDs, opt.Data)
+	return nil
+}
+
+func (f *fakeKube) DeleteNamespace(_ context.Context, _ http.Header, opt *types.NsDeleteOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, kind := range workloadKinds() {
+		if len(f.find(string(kind), types.BKNamespaceIDField, opt.IDs)) > 0 {
+			return fakeErr("namespaces %v have workloads", opt.IDs)
+		}
+	}
+	f.remove(fakeNsTable, opt.IDs)
+	return nil
+}
+
+func (f *fakeKube) ListWorkload(_ context.Context, _ http.Header, kind types.WorkloadType,
+	opt *types.WlQueryOption) (*metadata.InstDataInfo, errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &metadata.InstDataInfo{Info: f.page(string(kind), opt.Page)}, nil
+}
+
+func (f *fakeKube) CreateWorkload(_ context.Context, _ http.Header, kind types.WorkloadType,
+	opt *types.WlCreateOption) (*metadata.RspIDs, errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ids := make([]int64, 0)
+	for _, wl := range opt.Data {
+		nsID := wl.GetWorkloadBase().NamespaceID
+		if len(f.find(fakeNsTable, types.BKIDField, []int64{nsID})) == 0 {
+			return nil, fakeErr("namespace %d not exists", nsID)
+		}
+		ids = append(ids, f.insert(string(kind), wl, map[string]interface{}{common.BKAppIDField: opt.BizID}))
+	}
+	return &metadata.RspIDs{IDs: ids}, nil
+}
+
+func (f *fakeKube) UpdateWorkload(_ context.Context, _ http.Header, kind types.WorkloadType,
+	opt *types.WlUpdateOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.update(string(kind), opt.IDs, opt.Data)
+	return nil
+}
+
+func (f *fakeKube) DeleteWorkload(_ context.Context, _ http.Header, kind types.WorkloadType,
+	opt *types.WlDeleteOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, pod := range f.tables[fakePodTable] {
+		if util.InArray(podRefID(pod), opt.IDs) {
+			return fakeErr("%s workloads %v have pods", kind, opt.IDs)
+		}
+	}
+	f.remove(string(kind), opt.IDs)
+	return nil
+}
+
+func (f *fakeKube) ListPod(_ context.Context, _ http.Header, opt *types.PodQueryOption) (*metadata.InstDataInfo,
+	errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &metadata.InstDataInfo{Info: f.page(fakePodTable, opt.Page)}, nil
+}
+
+func (f *fakeKube) BatchCreatePod(_ context.Context, _ http.Header, opt *types.CreatePodsOption) ([]int64,
+	errors.CCErrorCoder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	ids := make([]int64, 0)
+	for _, data := range opt.Data {
+		for _, info := range data.Pods {
+			wls := f.find(string(info.Spec.Ref.Kind), types.BKIDField, []int64{info.Spec.Ref.ID})
+			if len(wls) == 0 || rowInt(wls[0], common.BKAppIDField) != data.BizID {
+				return nil, fakeErr("workload %d is invalid", info.Spec.Ref.ID)
+			}
+			if len(info.Containers) == 0 || info.Pod.Operator == nil {
+				return nil, fakeErr("pod %s is invalid", *info.Pod.Name)
+			}
+			ids = append(ids, f.insert(fakePodTable, info.Pod, map[string]interface{}{
+				common.BKAppIDField: data.BizID, types.RefField: info.Spec.Ref, types.BKNodeIDField: info.Spec.NodeID,
+				common.BKHostIDField: info.HostID, types.BKNamespaceIDField: info.Spec.NamespaceID}))
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeKube) DeletePods(_ context.Context, _ http.Header, opt *types.DeletePodsOption) errors.CCErrorCoder {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, data := range opt.Data {
+		f.remove(fakePodTable, data.PodIDs)
+	}
+	return nil
+}
+
+// names returns the sorted names of the rows in the table
+func (f *fakeKube) names(table string) []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	names := make([]string, 0)
+	for _, row := range f.tables[table] {
+		names = append(names, fmt.Sprintf("%v/%v", row[types.NamespaceField], row[types.KubeNameField]))
+	}
+	sort.Strings(names)
+	return names
+}
+
+type fakeHosts map[string]int64
+
+func (h fakeHosts) GetHostIDsByIP(_ context.Context, _ int64, ips []string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	for _, ip := range ips {
+		if id, ok := h[ip]; ok {
+			result[ip] = id
+		}
+	}
+	return result, nil
+}
+
+func kubeNodeObject(name, ip string) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "labels": map[string]string{nodeRoleLabelPrefix + "node": ""}},
+		"spec":     map[string]interface{}{"podCIDR": "172.16.0.0/24"},
+		"status": map[string]interface{}{
+			"addresses": []map[string]string{{"type": "InternalIP", "address": ip}},
+			"nodeInfo":  map[string]string{"containerRuntimeVersion": "containerd://1.6.9"},
+		},
+	}
+}
+
+func kubeNamespaceObject(name string, labels map[string]string) map[string]interface{} {
+	return map[string]interface{}{"metadata": map[string]interface{}{"name": name, "labels": labels}}
+}
+
+func kubeDeploymentObject(namespace, name string, replicas int) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{"matchLabels": map[string]string{"app": name}},
+			"strategy": map[string]interface{}{"type": "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": 1}},
+		},
+	}
+}
+
+func kubePodObject(namespace, name, phase string, owner map[string]interface{}) map[string]interface{} {
+	meta := map[string]interface{}{"name": name, "namespace": namespace,
+		"labels": map[string]string{podTemplateHashLabel: "5d4f"}}
+	if owner != nil {
+		meta["ownerReferences"] = []map[string]interface{}{owner}
+	}
+	return map[string]interface{}{
+		"metadata": meta,
+		"spec": map[string]interface{}{
+			"nodeName":   "node-1",
+			"containers": []map[string]interface{}{{"name": "main", "image": "nginx:1.23"}},
+		},
+		"status": map[string]interface{}{
+			"phase": phase,
+			"podIP": "172.16.0.5",
+			"containerStatuses": []map[string]interface{}{{"name": "main", "containerID": "containerd://" + name,
+				"state": map[string]interface{}{"running": map[string]string{"startedAt": "2022-01-02T15:04:05Z"}}}},
+		},
+	}
+}
+
+// eventually waits until the condition is true, the informers handle the watch events asynchronously
+func eventually(t *testing.T, desc string, cond func() bool) {
+	for i := 0; i < 500; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %s", desc)
+}
+
+func TestCollectorReconcile(t *testing.T) {
+	client := kubeclient.NewFake()
+	client.NotServe(kubeclient.GameDeployments)
+	client.NotServe(kubeclient.GameStatefulSets)
+
+	deploymentOwner := map[string]interface{}{"kind": "ReplicaSet", "name": "nginx-5d4f", "controller": true}
+	objects := []struct {
+		res kubeclient.Resource
+		obj map[string]interface{}
+	}{
+		{kubeclient.Nodes, kubeNodeObject("node-1", "10.0.0.1")},
+		{kubeclient.Nodes, kubeNodeObject("node-2", "10.0.0.2")},
+		{kubeclient.Namespaces, kubeNamespaceObject("default", nil)},
+		{kubeclient.Namespaces, kubeNamespaceObject("team", map[string]string{"cc/biz": "3"})},
+		{kubeclient.Deployments, kubeDeploymentObject("default", "nginx", 2)},
+		{kubeclient.Pods, kubePodObject("default", "nginx-5d4f-a", podRunningPhase, deploymentOwner)},
+		{kubeclient.Pods, kubePodObject("default", "nginx-5d4f-b", "Pending", deploymentOwner)},
+		{kubeclient.Pods, kubePodObject("team", "debug", podRunningPhase, nil)},
+	}
+	for _, item := range objects {
+		if err := client.Add(item.res, item.obj); err != nil {
+			t.Fatalf("add %s failed, err: %v", item.res, err)
+		}
+	}
+
+	uid, clusterType := "BCS-K8S-00001", types.SharedClusterType
+	kube := newFakeKube(types.Cluster{ID: 10, BizID: 2, Uid: &uid, Type: &clusterType})
+	cred := &types.ClusterCredential{BizID: 2, ClusterID: 10, NsBizLabel: "cc/biz",
+		SupplierAccount: common.BKDefaultOwnerID}
+	cred.Modifier = "admin"
+
+	statuses := make(chan *types.CollectStatus, 10)
+	c := newCollector(cred, client, kube, fakeHosts{"10.0.0.1": 100}, func(_ context.Context,
+		status *types.CollectStatus) {
+		statuses <- status
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, inf := range c.informers() {
+		go inf.run(ctx)
+	}
+	if !c.waitForSync(ctx) {
+		t.Fatalf("wait for informers sync failed")
+	}
+
+	c.reconcile(ctx)
+	status := <-statuses
+	if status.State != types.CollectStateSuccess {
+		t.Fatalf("reconcile failed, message: %s", status.Message)
+	}
+	if !reflect.DeepEqual(status.UnmatchedNodes, []string{"node-2"}) {
+		t.Errorf("unmatched nodes %v, expected [node-2]", status.UnmatchedNodes)
+	}
+
+	if names := kube.names(fakeNodeTable); !reflect.DeepEqual(names, []string{"<nil>/node-1"}) {
+		t.Errorf("nodes %v, expected node-1 only", names)
+	}
+	if names := kube.names(fakeNsTable); !reflect.DeepEqual(names, []string{"<nil>/default", "<nil>/team"}) {
+		t.Errorf("namespaces %v, expected default and team", names)
+	}
+	if names := kube.names(string(types.KubeDeployment)); !reflect.DeepEqual(names, []string{"default/nginx"}) {
+		t.Errorf("deployments %v, expected default/nginx", names)
+	}
+	if names := kube.names(string(types.KubePodWorkload)); !reflect.DeepEqual(names, []string{"team/pods"}) {
+		t.Errorf("pods workloads %v, expected team/pods", names)
+	}
+	if names := kube.names(fakePodTable); !reflect.DeepEqual(names, []string{"default/nginx-5d4f-a", "team/debug"}) {
+		t.Errorf("pods %v, expected the running pods", names)
+	}
+
+	// the namespace with the business label belongs to the business of the label
+	for _, ns := range kube.tables[fakeNsTable] {
+		expected := int64(2)
+		if ns[types.KubeNameField] == "team" {
+			expected = 3
+		}
+		if rowInt(ns, common.BKAppIDField) != expected {
+			t.Errorf("namespace %v business %v, expected %d", ns[types.KubeNameField], ns[common.BKAppIDField],
+				expected)
+		}
+	}
+
+	// delete the team namespace with its pod and scale the deployment, the stale resources are deleted from
+	// the pods to the namespaces, and the changed deployment is updated
+	if err := client.Delete(kubeclient.Pods, "team", "debug"); err != nil {
+		t.Fatalf("delete pod failed, err: %v", err)
+	}
+	if err := client.Delete(kubeclient.Namespaces, "", "team"); err != nil {
+		t.Fatalf("delete namespace failed, err: %v", err)
+	}
+	if err := client.Update(kubeclient.Deployments, kubeDeploymentObject("default", "nginx", 3)); err != nil {
+		t.Fatalf("update deployment failed, err: %v", err)
+	}
+	eventually(t, "the informers to handle the changes", func() bool {
+		pods, _ := c.pods.list()
+		namespaces, _ := c.namespaces.list()
+		deployments, _ := c.workloads[types.KubeDeployment].list()
+		return len(pods) == 2 && len(namespaces) == 1 && len(deployments) == 1 &&
+			strings.Contains(string(deployments[0].Raw), `"replicas":3`)
+	})
+
+	c.reconcile(ctx)
+	status = <-statuses
+	if status.State != types.CollectStateSuccess {
+		t.Fatalf("reconcile changes failed, message: %s", status.Message)
+	}
+
+	if names := kube.names(fakeNsTable); !reflect.DeepEqual(names, []string{"<nil>/default"}) {
+		t.Errorf("namespaces %v, expected default only", names)
+	}
+	if names := kube.names(string(types.KubePodWorkload)); len(names) != 0 {
+		t.Errorf("pods workloads %v, expected none", names)
+	}
+	if names := kube.names(fakePodTable); !reflect.DeepEqual(names, []string{"default/nginx-5d4f-a"}) {
+		t.Errorf("pods %v, expected default/nginx-5d4f-a only", names)
+	}
+	replicas := rowInt(kube.tables[string(types.KubeDeployment)][0], types.ReplicasField)
+	if replicas != 3 {
+		t.Errorf("deployment replicas %d, expected 3", replicas)
+	}
+}
+
+func TestFieldsChanged(t *testing.T) {
+	labels := map[string]string{"app": "nginx"}
+	desired := &types.Namespace{Name: "default", Labels: &labels}
+
+	same := map[string]string{"app": "nginx"}
+	changed, err := fieldsChanged(desired, &types.Namespace{ID: 1, Name: "default", Labels: &same},
+		namespaceCompareFields)
+	if err != nil || changed {
+		t.Errorf("same labels should not be changed, changed: %v, err: %v", changed, err)
+	}
+
+	other := map[string]string{"app": "redis"}
+	changed, err = fieldsChanged(desired, &types.Namespace{ID: 1, Name: "default", Labels: &other},
+		namespaceCompareFields)
+	if err != nil || !changed {
+		t.Errorf("different labels should be changed, changed: %v, err: %v", changed, err)
+	}
+
+	// the fields not set in the desired value are not compared
+	changed, err = fieldsChanged(&types.Namespace{Name: "default"}, &types.Namespace{Labels: &other},
+		namespaceCompareFields)
+	if err != nil || changed {
+		t.Errorf("unset labels should not be changed, changed: %v, err: %v", changed, err)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package snmp is a snmp v2c and v3 client used by netcollect to discover the network devices, and a simulator
+// agent for tests. the snmp messages and the user based security model are handled by gosnmp.
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Version is the snmp version
+type Version string
+
+// the supported snmp versions
+const (
+	V2c Version = "v2c"
+	V3  Version = "v3"
+)
+
+// SecurityLevel is the snmp v3 security level
+type SecurityLevel string
+
+// the snmp v3 security levels
+const (
+	NoAuthNoPriv SecurityLevel = "noAuthNoPriv"
+	AuthNoPriv   SecurityLevel = "authNoPriv"
+	AuthPriv     SecurityLevel = "authPriv"
+)
+
+// AuthProtocol is the snmp v3 authentication protocol
+type AuthProtocol string
+
+// the snmp v3 authentication protocols
+const (
+	MD5 AuthProtocol = "MD5"
+	SHA AuthProtocol = "SHA"
+)
+
+// PrivProtocol is the snmp v3 privacy protocol
+type PrivProtocol string
+
+// the snmp v3 privacy protocols
+const (
+	DES PrivProtocol = "DES"
+	AES PrivProtocol = "AES"
+)
+
+var (
+	securityLevels = map[SecurityLevel]gosnmp.SnmpV3MsgFlags{
+		NoAuthNoPriv: gosnmp.NoAuthNoPriv,
+		AuthNoPriv:   gosnmp.AuthNoPriv,
+		AuthPriv:     gosnmp.AuthPriv,
+	}
+	authProtocols = map[AuthProtocol]gosnmp.SnmpV3AuthProtocol{MD5: gosnmp.MD5, SHA: gosnmp.SHA}
+	privProtocols = map[PrivProtocol]gosnmp.SnmpV3PrivProtocol{DES: gosnmp.DES, AES: gosnmp.AES}
+)
+
+const (
+	defaultPort           = 161
+	defaultTimeout        = 2 * time.Second
+	defaultMaxOids        = 10
+	defaultMaxRepetitions = 10
+	// maxWalkVariables is the max variables of one walk to avoid the endless walk of a broken agent
+	maxWalkVariables = 10000
+	// minPasswordLength is the min length of the snmp v3 password
+	minPasswordLength = 8
+)
+
+var errWalkLimit = errors.New("snmp: too many variables to walk")
+
+// Config is the snmp client config
+type Config struct {
+	Version Version
+	Port    int
+	// Community is the community of snmp v2c
+	Community string
+	Timeout   time.Duration
+	Retries   int
+	// MaxOids is the max oids of one get request
+	MaxOids int
+	// MaxRepetitions is the max repetitions of the get bulk request used by walk
+	MaxRepetitions int
+
+	// the user based security model params of snmp v3
+	UserName      string
+	SecurityLevel SecurityLevel
+	AuthProtocol  AuthProtocol
+	AuthPassword  string
+	PrivProtocol  PrivProtocol
+	PrivPassword  string
+}
+
+// Validate validates the config and sets the default values
+func (c *Config) Validate() error {
+	if c.Port == 0 {
+		c.Port = defaultPort
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.MaxOids <= 0 {
+		c.MaxOids = defaultMaxOids
+	}
+	if c.MaxRepetitions <= 0 {
+		c.MaxRepetitions = defaultMaxRepetitions
+	}
+
+	switch c.Version {
+	case V2c:
+		if c.Community == "" {
+			return errors.New("snmp: community is not set")
+		}
+		return nil
+	case V3:
+	default:
+		return fmt.Errorf("snmp: unsupported version %s", c.Version)
+	}
+
+	if c.UserName == "" {
+		return errors.New("snmp: user name is not set")
+	}
+
+	switch c.SecurityLevel {
+	case NoAuthNoPriv:
+		return nil
+	case AuthNoPriv, AuthPriv:
+	case "":
+		c.SecurityLevel = NoAuthNoPriv
+		return nil
+	default:
+		return fmt.Errorf("snmp: unsupported security level %s", c.SecurityLevel)
+	}
+
+	if _, ok := authProtocols[c.AuthProtocol]; !ok {
+		return fmt.Errorf("snmp: unsupported auth protocol %s", c.AuthProtocol)
+	}
+	if len(c.AuthPassword) < minPasswordLength {
+		return fmt.Errorf("snmp: auth password must be at least %d characters", minPasswordLength)
+	}
+
+	if c.SecurityLevel == AuthNoPriv {
+		return nil
+	}
+
+	if _, ok := privProtocols[c.PrivProtocol]; !ok {
+		return fmt.Errorf("snmp: unsupported priv protocol %s", c.PrivProtocol)
+	}
+	if len(c.PrivPassword) < minPasswordLength {
+		return fmt.Errorf("snmp: priv password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+// newGoSNMP creates the gosnmp session of the validated config
+func (c *Config) newGoSNMP(target string) *gosnmp.GoSNMP {
+	session := &gosnmp.GoSNMP{
+		Target:         target,
+		Port:           uint16(c.Port),
+		Transport:      "udp",
+		Community:      c.Community,
+		Version:        gosnmp.Version2c,
+		Timeout:        c.Timeout,
+		Retries:        c.Retries,
+		MaxOids:        c.MaxOids,
+		MaxRepetitions: uint32(c.MaxRepetitions),
+	}
+	if c.Version != V3 {
+		return session
+	}
+
+	session.Version = gosnmp.Version3
+	session.SecurityModel = gosnmp.UserSecurityModel
+	session.MsgFlags = securityLevels[c.SecurityLevel]
+	session.SecurityParameters = c.newSecurityParameters()
+	return session
+}
+
+func (c *Config) newSecurityParameters() *gosnmp.UsmSecurityParameters {
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:               c.UserName,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	if c.SecurityLevel == AuthNoPriv || c.SecurityLevel == AuthPriv {
+		params.AuthenticationProtocol = authProtocols[c.AuthProtocol]
+		params.AuthenticationPassphrase = c.AuthPassword
+	}
+	if c.SecurityLevel == AuthPriv {
+		params.PrivacyProtocol = privProtocols[c.PrivProtocol]
+		params.PrivacyPassphrase = c.PrivPassword
+	}
+	return params
+}
+
+// Client is the snmp client of an agent
+type Client struct {
+	lock    sync.Mutex
+	config  Config
+	session *gosnmp.GoSNMP
+}
+
+// NewClient creates a snmp client of the agent, the v3 engine is discovered on the first request
+func NewClient(target string, config Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	session := config.newGoSNMP(target)
+	if err := session.Connect(); err != nil {
+		return nil, err
+	}
+	return &Client{config: config, session: session}, nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return c.session.Conn.Close()
+}
+
+// Get gets the variables of the oids
+func (c *Client) Get(oids ...string) ([]Variable, error) {
+	return c.getVariables(c.session.Get, oids)
+}
+
+// GetNext gets the next variables of the oids
+func (c *Client) GetNext(oids ...string) ([]Variable, error) {
+	return c.getVariables(c.session.GetNext, oids)
+}
+
+func (c *Client) getVariables(get func(oids []string) (*gosnmp.SnmpPacket, error), oids []string) ([]Variable,
+	error) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	vars := make([]Variable, 0, len(oids))
+	for start := 0; start < len(oids); start += c.config.MaxOids {
+		end := start + c.config.MaxOids
+		if end > len(oids) {
+			end = len(oids)
+		}
+
+		resp, err := get(oids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if resp.Error != gosnmp.NoError {
+			return nil, fmt.Errorf("snmp: response error status %d, index %d", resp.Error, resp.ErrorIndex)
+		}
+
+		for _, pdu := range resp.Variables {
+			vars = append(vars, newVariable(pdu))
+		}
+	}
+	return vars, nil
+}
+
+// Walk gets all variables in the sub tree of the root oid with the get bulk request
+func (c *Client) Walk(root string) ([]Variable, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	vars := make([]Variable, 0)
+	err := c.session.BulkWalk(root, func(pdu gosnmp.SnmpPDU) error {
+		if len(vars) >= maxWalkVariables {
+			return errWalkLimit
+		}
+		vars = append(vars, newVariable(pdu))
+		return nil
+	})
+	if err != nil && err != errWalkLimit {
+		return nil, err
+	}
+	return vars, nil
+}
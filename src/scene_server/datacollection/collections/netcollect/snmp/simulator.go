@@ -0,0 +1,320 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package snmp
+
+import (
+	"crypto/rand"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	// timeWindow is the time window of the snmp v3 message, as defined in rfc3414 3.2.7
+	timeWindow = 150
+	// maxMessageSize is the max size of the snmp message over udp
+	maxMessageSize = 65507
+)
+
+// the usm statistics oids reported by the agent when the message can not be processed
+const (
+	usmStatsUnsupportedSecLevels = "1.3.6.1.6.3.15.1.1.1.0"
+	usmStatsNotInTimeWindows     = "1.3.6.1.6.3.15.1.1.2.0"
+	usmStatsUnknownUserNames     = "1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsUnknownEngineIDs     = "1.3.6.1.6.3.15.1.1.4.0"
+	usmStatsWrongDigests         = "1.3.6.1.6.3.15.1.1.5.0"
+	usmStatsDecryptionErrors     = "1.3.6.1.6.3.15.1.1.6.0"
+)
+
+// Simulator is a local snmp agent which serves the variables of a device, it is used to test the snmp discovery
+// without a real network device.
+type Simulator struct {
+	config Config
+	conn   *net.UDPConn
+	vars   []Variable
+	// session decodes the requests with the community or the security parameters of the agent
+	session  *gosnmp.GoSNMP
+	engineID string
+	start    time.Time
+	wg       sync.WaitGroup
+}
+
+// NewSimulator starts a snmp agent on the address like 127.0.0.1:0 with the variables, the agent accepts the
+// community or the user of the config.
+func NewSimulator(address string, config Config, vars []Variable) (*Simulator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append(make([]Variable, 0, len(vars)), vars...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return CompareOID(sorted[i].OID, sorted[j].OID) < 0
+	})
+
+	s := &Simulator{config: config, conn: conn, vars: sorted, session: config.newGoSNMP(""), start: time.Now()}
+	if config.Version == V3 {
+		s.engineID = "\x80\x00\x1f\x88\x04cmdb-sim-" + conn.LocalAddr().String()
+		params := s.session.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		params.AuthoritativeEngineID = s.engineID
+		params.AuthoritativeEngineBoots = 1
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Port returns the udp port that the simulator listens on
+func (s *Simulator) Port() int {
+	_, port, _ := net.SplitHostPort(s.conn.LocalAddr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Close stops the simulator
+func (s *Simulator) Close() error {
+	err := s.conn.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Simulator) serve() {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		var resp []byte
+		if s.config.Version == V2c {
+			resp = s.handleV2c(buf[:n])
+		} else {
+			resp = s.handleV3(buf[:n])
+		}
+
+		if resp != nil {
+			_, _ = s.conn.WriteToUDP(resp, addr)
+		}
+	}
+}
+
+func (s *Simulator) handleV2c(data []byte) []byte {
+	req, err := s.session.SnmpDecodePacket(data)
+	if err != nil || req.Community != s.config.Community {
+		return nil
+	}
+
+	resp := s.process(req)
+	resp.Version = gosnmp.Version2c
+	resp.Community = req.Community
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func (s *Simulator) handleV3(data []byte) (resp []byte) {
+	// gosnmp panics when the scoped pdu decrypted with a wrong privacy key is malformed, the message is dropped
+	// like the other asn.1 parse errors of the agent.
+	defer func() {
+		if recover() != nil {
+			resp = nil
+		}
+	}()
+
+	// the message is authenticated and decrypted with the keys localized by the engine id of the agent, the
+	// authentication parameters are blanked when the message is decoded, so the copies of the message are decoded.
+	req := s.session.UnmarshalTrap(append([]byte{}, data...), true)
+	header := req
+	var decodeErr error
+	if req == nil {
+		// decode the message without authentication to report why it can not be processed
+		header, decodeErr = s.session.SnmpDecodePacket(append([]byte{}, data...))
+	}
+
+	params, ok := header.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if header.Version != gosnmp.Version3 || !ok {
+		return nil
+	}
+
+	flags := s.session.MsgFlags & gosnmp.AuthPriv
+	switch {
+	case params.AuthoritativeEngineID != s.engineID:
+		return s.report(header, usmStatsUnknownEngineIDs, gosnmp.NoAuthNoPriv)
+	case params.UserName != s.config.UserName:
+		return s.report(header, usmStatsUnknownUserNames, gosnmp.NoAuthNoPriv)
+	case header.MsgFlags&gosnmp.AuthPriv != flags:
+		return s.report(header, usmStatsUnsupportedSecLevels, gosnmp.NoAuthNoPriv)
+	case decodeErr != nil:
+		return s.report(header, usmStatsDecryptionErrors, gosnmp.NoAuthNoPriv)
+	case req == nil:
+		return s.report(header, usmStatsWrongDigests, gosnmp.NoAuthNoPriv)
+	}
+
+	if flags&gosnmp.AuthNoPriv != 0 {
+		boots, engineTime := s.engineTime()
+		reqTime := int64(params.AuthoritativeEngineTime)
+		if params.AuthoritativeEngineBoots != boots || reqTime < int64(engineTime)-timeWindow ||
+			reqTime > int64(engineTime)+timeWindow {
+			return s.report(req, usmStatsNotInTimeWindows, gosnmp.AuthNoPriv)
+		}
+	}
+
+	return s.marshalV3(req, s.process(req), flags)
+}
+
+// engineTime returns the boots and the time of the agent engine
+func (s *Simulator) engineTime() (uint32, uint32) {
+	return 1, uint32(time.Since(s.start) / time.Second)
+}
+
+// report responds the report pdu of the usm statistics oid when the message is reportable
+func (s *Simulator) report(req *gosnmp.SnmpPacket, oid string, flags gosnmp.SnmpV3MsgFlags) []byte {
+	if req.MsgFlags&gosnmp.Reportable == 0 {
+		return nil
+	}
+
+	resp := &gosnmp.SnmpPacket{
+		PDUType:   gosnmp.Report,
+		Variables: []gosnmp.SnmpPDU{{Name: oid, Type: Counter32, Value: uint32(1)}},
+	}
+	return s.marshalV3(req, resp, flags)
+}
+
+// marshalV3 marshals the snmp v3 response of the request, the response is authenticated and encrypted with the
+// keys localized by the request if the flags require.
+func (s *Simulator) marshalV3(req, resp *gosnmp.SnmpPacket, flags gosnmp.SnmpV3MsgFlags) []byte {
+	params, ok := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return nil
+	}
+
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil
+	}
+
+	boots, engineTime := s.engineTime()
+	resp.Version = gosnmp.Version3
+	resp.MsgFlags = flags
+	resp.MsgID = req.MsgID
+	resp.RequestID = req.RequestID
+	resp.SecurityModel = gosnmp.UserSecurityModel
+	resp.ContextEngineID = s.engineID
+	resp.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		AuthoritativeEngineID:    s.engineID,
+		AuthoritativeEngineBoots: boots,
+		AuthoritativeEngineTime:  engineTime,
+		UserName:                 params.UserName,
+		PrivacyParameters:        salt,
+		AuthenticationProtocol:   params.AuthenticationProtocol,
+		PrivacyProtocol:          params.PrivacyProtocol,
+		SecretKey:                params.SecretKey,
+		PrivacyKey:               params.PrivacyKey,
+	}
+
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// process handles the get, get next and get bulk request
+func (s *Simulator) process(req *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	resp := &gosnmp.SnmpPacket{PDUType: gosnmp.GetResponse, RequestID: req.RequestID}
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		for _, v := range req.Variables {
+			resp.Variables = append(resp.Variables, s.get(v.Name).toPDU())
+		}
+
+	case gosnmp.GetNextRequest:
+		for _, v := range req.Variables {
+			resp.Variables = append(resp.Variables, s.next(v.Name).toPDU())
+		}
+
+	case gosnmp.GetBulkRequest:
+		nonRepeaters := int(req.NonRepeaters)
+		if nonRepeaters > len(req.Variables) {
+			nonRepeaters = len(req.Variables)
+		}
+		for _, v := range req.Variables[:nonRepeaters] {
+			resp.Variables = append(resp.Variables, s.next(v.Name).toPDU())
+		}
+
+		repeaters := req.Variables[nonRepeaters:]
+		last := make([]string, len(repeaters))
+		for idx, v := range repeaters {
+			last[idx] = v.Name
+		}
+		// gosnmp does not decode the max repetitions of the request, the max repetitions of the agent is used
+		maxRepetitions := int(req.MaxRepetitions)
+		if maxRepetitions == 0 {
+			maxRepetitions = s.config.MaxRepetitions
+		}
+		for rep := 0; rep < maxRepetitions && len(repeaters) > 0; rep++ {
+			for idx := range repeaters {
+				next := s.next(last[idx])
+				resp.Variables = append(resp.Variables, next.toPDU())
+				last[idx] = next.OID
+			}
+		}
+
+	default:
+		resp.Error = gosnmp.GenErr
+	}
+	return resp
+}
+
+func (s *Simulator) get(oid string) Variable {
+	idx := sort.Search(len(s.vars), func(i int) bool {
+		return CompareOID(s.vars[i].OID, oid) >= 0
+	})
+	if idx < len(s.vars) && CompareOID(s.vars[idx].OID, oid) == 0 {
+		return s.vars[idx]
+	}
+	return Variable{OID: oid, Type: NoSuchInstance}
+}
+
+func (s *Simulator) next(oid string) Variable {
+	idx := sort.Search(len(s.vars), func(i int) bool {
+		return CompareOID(s.vars[i].OID, oid) > 0
+	})
+	if idx < len(s.vars) {
+		return s.vars[idx]
+	}
+	return Variable{OID: oid, Type: EndOfMibView}
+}
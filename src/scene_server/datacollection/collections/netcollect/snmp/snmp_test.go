@@ -0,0 +1,125 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package snmp
+
+import (
+	"testing"
+	"time"
+)
+
+var testVars = []Variable{
+	{OID: "1.3.6.1.2.1.1.1.0", Type: OctetString, Value: []byte("Huawei Versatile Routing Platform")},
+	{OID: "1.3.6.1.2.1.1.2.0", Type: ObjectIdentifier, Value: "1.3.6.1.4.1.2011.2.23.96"},
+	{OID: "1.3.6.1.2.1.1.3.0", Type: TimeTicks, Value: uint64(4294967295)},
+	{OID: "1.3.6.1.2.1.1.5.0", Type: OctetString, Value: []byte("switch-a")},
+	{OID: "1.3.6.1.2.1.2.1.0", Type: Integer, Value: int64(-129)},
+	{OID: "1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: IPAddress, Value: "10.0.0.1"},
+	{OID: "1.0.8802.1.1.2.1.4.1.1.9.0.1.1", Type: OctetString, Value: []byte("switch-b")},
+	{OID: "1.0.8802.1.1.2.1.4.1.1.9.0.2.1", Type: OctetString, Value: []byte("switch-c")},
+	{OID: "1.0.8802.1.1.2.1.4.1.1.9.0.10.1", Type: OctetString, Value: []byte("switch-d")},
+}
+
+func TestVariablePDU(t *testing.T) {
+	for _, v := range testVars {
+		converted := newVariable(v.toPDU())
+		if converted.OID != v.OID || converted.Type != v.Type || converted.String() != v.String() {
+			t.Errorf("variable %+v is converted as %+v", v, converted)
+		}
+	}
+}
+
+func TestCompareOID(t *testing.T) {
+	if CompareOID("1.3.6.1.2", "1.3.6.1.10") >= 0 {
+		t.Errorf("1.3.6.1.2 should be less than 1.3.6.1.10")
+	}
+	if CompareOID("1.3.6.1", "1.3.6.1.0") >= 0 {
+		t.Errorf("1.3.6.1 should be less than 1.3.6.1.0")
+	}
+	if !HasOIDPrefix(".1.3.6.1.4.1.2011.2", "1.3.6.1.4.1.2011") || HasOIDPrefix("1.3.6.1.4.1.20110", "1.3.6.1.4.1.2011") {
+		t.Errorf("oid prefix is not matched by sub identifier")
+	}
+}
+
+func TestClientWithSimulator(t *testing.T) {
+	configs := map[string]Config{
+		"v2c":             {Version: V2c, Community: "public"},
+		"v3 noAuthNoPriv": {Version: V3, UserName: "cmdb"},
+		"v3 authNoPriv": {Version: V3, UserName: "cmdb", SecurityLevel: AuthNoPriv, AuthProtocol: MD5,
+			AuthPassword: "auth-password"},
+		"v3 authPriv des": {Version: V3, UserName: "cmdb", SecurityLevel: AuthPriv, AuthProtocol: SHA,
+			AuthPassword: "auth-password", PrivProtocol: DES, PrivPassword: "priv-password"},
+		"v3 authPriv aes": {Version: V3, UserName: "cmdb", SecurityLevel: AuthPriv, AuthProtocol: SHA,
+			AuthPassword: "auth-password", PrivProtocol: AES, PrivPassword: "priv-password"},
+	}
+
+	for name, config := range configs {
+		sim, err := NewSimulator("127.0.0.1:0", config, testVars)
+		if err != nil {
+			t.Fatalf("%s: start simulator failed, err: %v", name, err)
+		}
+
+		config.Port = sim.Port()
+		config.Timeout = time.Second
+		config.MaxRepetitions = 2
+		client, err := NewClient("127.0.0.1", config)
+		if err != nil {
+			t.Fatalf("%s: create client failed, err: %v", name, err)
+		}
+
+		vars, err := client.Get("1.3.6.1.2.1.1.2.0", "1.3.6.1.2.1.1.5.0", "1.3.6.1.2.1.1.6.0")
+		if err != nil {
+			t.Fatalf("%s: get failed, err: %v", name, err)
+		}
+		if len(vars) != 3 || vars[0].String() != "1.3.6.1.4.1.2011.2.23.96" || vars[1].String() != "switch-a" ||
+			vars[2].Exists() {
+			t.Errorf("%s: get unexpected variables %+v", name, vars)
+		}
+
+		vars, err = client.Walk("1.0.8802.1.1.2.1.4.1.1.9")
+		if err != nil {
+			t.Fatalf("%s: walk failed, err: %v", name, err)
+		}
+		if len(vars) != 3 || vars[2].String() != "switch-d" {
+			t.Errorf("%s: walk unexpected variables %+v", name, vars)
+		}
+
+		_ = client.Close()
+		_ = sim.Close()
+	}
+}
+
+func TestClientWrongCredential(t *testing.T) {
+	config := Config{Version: V3, UserName: "cmdb", SecurityLevel: AuthNoPriv, AuthProtocol: SHA,
+		AuthPassword: "auth-password"}
+	sim, err := NewSimulator("127.0.0.1:0", config, testVars)
+	if err != nil {
+		t.Fatalf("start simulator failed, err: %v", err)
+	}
+	defer sim.Close()
+
+	config.Port = sim.Port()
+	config.AuthPassword = "wrong-password"
+	client, err := NewClient("127.0.0.1", config)
+	if err != nil {
+		t.Fatalf("create client failed, err: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get("1.3.6.1.2.1.1.2.0"); err == nil {
+		t.Errorf("get with wrong password should fail")
+	}
+}
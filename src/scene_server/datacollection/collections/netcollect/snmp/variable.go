@@ -0,0 +1,152 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Asn1BER is the ber tag of the snmp value
+type Asn1BER = gosnmp.Asn1BER
+
+// the ber tags of the snmp values
+const (
+	Integer          = gosnmp.Integer
+	OctetString      = gosnmp.OctetString
+	Null             = gosnmp.Null
+	ObjectIdentifier = gosnmp.ObjectIdentifier
+	IPAddress        = gosnmp.IPAddress
+	Counter32        = gosnmp.Counter32
+	Gauge32          = gosnmp.Gauge32
+	TimeTicks        = gosnmp.TimeTicks
+	Opaque           = gosnmp.Opaque
+	Counter64        = gosnmp.Counter64
+	NoSuchObject     = gosnmp.NoSuchObject
+	NoSuchInstance   = gosnmp.NoSuchInstance
+	EndOfMibView     = gosnmp.EndOfMibView
+)
+
+// parseOID parses the dotted oid string, the leading dot is optional
+func parseOID(oid string) ([]uint64, error) {
+	oid = strings.TrimPrefix(oid, ".")
+	if oid == "" {
+		return nil, errors.New("snmp: empty oid")
+	}
+
+	parts := strings.Split(oid, ".")
+	ids := make([]uint64, len(parts))
+	for idx, part := range parts {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("snmp: invalid oid %s", oid)
+		}
+		ids[idx] = id
+	}
+	return ids, nil
+}
+
+// CompareOID compares two oids in lexicographical order of their sub identifiers
+func CompareOID(a, b string) int {
+	aIDs, _ := parseOID(a)
+	bIDs, _ := parseOID(b)
+	for idx := 0; idx < len(aIDs) && idx < len(bIDs); idx++ {
+		if aIDs[idx] != bIDs[idx] {
+			if aIDs[idx] < bIDs[idx] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+// HasOIDPrefix checks if the oid is in the sub tree of the prefix oid
+func HasOIDPrefix(oid, prefix string) bool {
+	oid, prefix = strings.TrimPrefix(oid, "."), strings.TrimPrefix(prefix, ".")
+	return oid == prefix || strings.HasPrefix(oid, prefix+".")
+}
+
+// Variable is a snmp variable binding
+type Variable struct {
+	OID  string
+	Type Asn1BER
+	// Value is int64 for Integer, []byte for OctetString and Opaque, string for ObjectIdentifier and IPAddress,
+	// uint64 for Counter32, Gauge32, TimeTicks and Counter64, and nil for the others.
+	Value interface{}
+}
+
+// String returns the printable value of the variable
+func (v Variable) String() string {
+	switch val := v.Value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// Exists returns if the variable has a value
+func (v Variable) Exists() bool {
+	return v.Type != NoSuchObject && v.Type != NoSuchInstance && v.Type != EndOfMibView && v.Type != Null
+}
+
+// newVariable converts the variable binding decoded by gosnmp to the variable, the oids are without the leading dot
+func newVariable(pdu gosnmp.SnmpPDU) Variable {
+	v := Variable{OID: strings.TrimPrefix(pdu.Name, "."), Type: pdu.Type}
+	switch pdu.Type {
+	case Integer:
+		v.Value = gosnmp.ToBigInt(pdu.Value).Int64()
+	case OctetString, Opaque:
+		if val, ok := pdu.Value.([]byte); ok {
+			v.Value = val
+		}
+	case ObjectIdentifier:
+		if val, ok := pdu.Value.(string); ok {
+			v.Value = strings.TrimPrefix(val, ".")
+		}
+	case IPAddress:
+		if val, ok := pdu.Value.(string); ok {
+			v.Value = val
+		}
+	case Counter32, Gauge32, TimeTicks, Counter64:
+		v.Value = gosnmp.ToBigInt(pdu.Value).Uint64()
+	}
+	return v
+}
+
+// toPDU converts the variable to the variable binding that can be encoded by gosnmp
+func (v Variable) toPDU() gosnmp.SnmpPDU {
+	pdu := gosnmp.SnmpPDU{Name: v.OID, Type: v.Type, Value: v.Value}
+	switch val := v.Value.(type) {
+	case int64:
+		pdu.Value = int(val)
+	case uint64:
+		if v.Type != Counter64 {
+			pdu.Value = uint32(val)
+		}
+	}
+	return pdu
+}
@@ -0,0 +1,204 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+	"configcenter/src/scene_server/datacollection/collections/kubecollect/kubeclient"
+	"configcenter/src/storage/dal/table"
+)
+
+// RegisterKubeCredential registers the credential of a cluster to be collected, the credential of a registered
+// cluster is replaced, returns the id of the credential
+func (lgc *Logics) RegisterKubeCredential(header http.Header, opt *types.RegisterCredentialOption) (int64, error) {
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(header))
+	rid := httpheader.GetRid(header)
+
+	if _, err := kubeclient.ParseKubeConfig([]byte(opt.KubeConfig), opt.Context); err != nil {
+		blog.Errorf("[KubeCollect] kubeconfig of cluster %d is invalid, err: %v, rid: %s", opt.ClusterID, err, rid)
+		return 0, defErr.Errorf(common.CCErrCommParamsInvalid, types.KubeConfigField)
+	}
+
+	if err := lgc.checkKubeClusterExist(header, opt.BizID, opt.ClusterID); err != nil {
+		return 0, err
+	}
+
+	kubeConfig := opt.KubeConfig
+	if lgc.cryptor != nil {
+		var err error
+		if kubeConfig, err = lgc.cryptor.Encrypt(kubeConfig); err != nil {
+			blog.Errorf("[KubeCollect] encrypt kubeconfig of cluster %d failed, err: %v, rid: %s", opt.ClusterID,
+				err, rid)
+			return 0, defErr.Errorf(common.CCErrCommParamsInvalid, types.KubeConfigField)
+		}
+	}
+
+	enabled := true
+	if opt.Enabled != nil {
+		enabled = *opt.Enabled
+	}
+
+	cond := map[string]interface{}{types.BKClusterIDFiled: opt.ClusterID}
+	exists := make([]types.ClusterCredential, 0)
+	if err := lgc.db.Table(types.BKTableNameClusterCredential).Find(cond).All(lgc.ctx, &exists); err != nil {
+		blog.Errorf("[KubeCollect] find credential of cluster %d failed, err: %v, rid: %s", opt.ClusterID, err, rid)
+		return 0, defErr.Error(common.CCErrCommDBSelectFailed)
+	}
+
+	user := httpheader.GetUser(header)
+	now := time.Now().Unix()
+	if len(exists) > 0 {
+		data := map[string]interface{}{
+			types.KubeConfigField:  kubeConfig,
+			types.KubeContextField: opt.Context,
+			types.NsBizLabelField:  opt.NsBizLabel,
+			types.EnabledField:     enabled,
+			common.ModifierField:   user,
+			common.LastTimeField:   now,
+		}
+		if err := lgc.db.Table(types.BKTableNameClusterCredential).Update(lgc.ctx, cond, data); err != nil {
+			blog.Errorf("[KubeCollect] update credential of cluster %d failed, err: %v, rid: %s", opt.ClusterID,
+				err, rid)
+			return 0, defErr.Error(common.CCErrCommDBUpdateFailed)
+		}
+		return exists[0].ID, nil
+	}
+
+	id, err := lgc.db.NextSequence(lgc.ctx, types.BKTableNameClusterCredential)
+	if err != nil {
+		blog.Errorf("[KubeCollect] generate credential id failed, err: %v, rid: %s", err, rid)
+		return 0, defErr.Error(common.CCErrCommDBInsertFailed)
+	}
+
+	cred := &types.ClusterCredential{
+		ID:              int64(id),
+		BizID:           opt.BizID,
+		ClusterID:       opt.ClusterID,
+		KubeConfig:      kubeConfig,
+		Context:         opt.Context,
+		NsBizLabel:      opt.NsBizLabel,
+		Enabled:         enabled,
+		SupplierAccount: httpheader.GetSupplierAccount(header),
+		Revision:        table.Revision{Creator: user, Modifier: user, CreateTime: now, LastTime: now},
+	}
+	if err = lgc.db.Table(types.BKTableNameClusterCredential).Insert(lgc.ctx, cred); err != nil {
+		blog.Errorf("[KubeCollect] create credential of cluster %d failed, err: %v, rid: %s", opt.ClusterID, err,
+			rid)
+		return 0, defErr.Error(common.CCErrCommDBInsertFailed)
+	}
+
+	return cred.ID, nil
+}
+
+// checkKubeClusterExist checks if the cluster exists in the business
+func (lgc *Logics) checkKubeClusterExist(header http.Header, bizID, clusterID int64) error {
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(header))
+	rid := httpheader.GetRid(header)
+
+	opt := &types.QueryClusterOption{
+		BizID:  bizID,
+		Filter: filtertools.GenAtomFilter(types.BKIDField, filter.Equal, clusterID),
+		Page:   metadata.BasePage{EnableCount: true},
+	}
+	resp, err := lgc.CoreAPI.TopoServer().Kube().SearchCluster(lgc.ctx, header, opt)
+	if err != nil {
+		blog.Errorf("[KubeCollect] search cluster %d failed, err: %v, rid: %s", clusterID, err, rid)
+		return err
+	}
+
+	js, jsErr := json.Marshal(resp.Data)
+	if jsErr != nil {
+		blog.Errorf("[KubeCollect] marshal cluster count failed, err: %v, rid: %s", jsErr, rid)
+		return defErr.Error(common.CCErrCommJSONMarshalFailed)
+	}
+	result := new(metadata.CommonCountResult)
+	if jsErr = json.Unmarshal(js, result); jsErr != nil {
+		blog.Errorf("[KubeCollect] unmarshal cluster count failed, err: %v, rid: %s", jsErr, rid)
+		return defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+	}
+
+	if result.Count == 0 {
+		blog.Errorf("[KubeCollect] cluster %d is not found in business %d, rid: %s", clusterID, bizID, rid)
+		return defErr.Errorf(common.CCErrCommParamsInvalid, types.BKClusterIDFiled)
+	}
+	return nil
+}
+
+// SearchKubeCredential searches the cluster credentials of the business, the kubeconfig is not returned
+func (lgc *Logics) SearchKubeCredential(header http.Header, opt *types.SearchCredentialOption) (
+	*types.SearchCredentialResult, error) {
+
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(header))
+	rid := httpheader.GetRid(header)
+
+	cond := map[string]interface{}{common.BKAppIDField: opt.BizID}
+	if len(opt.ClusterIDs) > 0 {
+		cond[types.BKClusterIDFiled] = map[string]interface{}{common.BKDBIN: opt.ClusterIDs}
+	}
+
+	if opt.Page.EnableCount {
+		count, err := lgc.db.Table(types.BKTableNameClusterCredential).Find(cond).Count(lgc.ctx)
+		if err != nil {
+			blog.Errorf("[KubeCollect] count credentials by %+v failed, err: %v, rid: %s", cond, err, rid)
+			return nil, defErr.Error(common.CCErrCommDBSelectFailed)
+		}
+		return &types.SearchCredentialResult{Count: int64(count), Info: make([]types.ClusterCredential, 0)}, nil
+	}
+
+	sort := opt.Page.Sort
+	if sort == "" {
+		sort = types.BKIDField
+	}
+	creds := make([]types.ClusterCredential, 0)
+	err := lgc.db.Table(types.BKTableNameClusterCredential).Find(cond).Sort(sort).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).All(lgc.ctx, &creds)
+	if err != nil {
+		blog.Errorf("[KubeCollect] find credentials by %+v failed, err: %v, rid: %s", cond, err, rid)
+		return nil, defErr.Error(common.CCErrCommDBSelectFailed)
+	}
+
+	for idx := range creds {
+		creds[idx].KubeConfig = ""
+	}
+	return &types.SearchCredentialResult{Info: creds}, nil
+}
+
+// DeleteKubeCredential deletes the cluster credentials of the business, the collected resources are kept
+func (lgc *Logics) DeleteKubeCredential(header http.Header, opt *types.DeleteCredentialOption) error {
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(header))
+	rid := httpheader.GetRid(header)
+
+	cond := map[string]interface{}{
+		common.BKAppIDField:    opt.BizID,
+		types.BKClusterIDFiled: map[string]interface{}{common.BKDBIN: opt.ClusterIDs},
+	}
+	if err := lgc.db.Table(types.BKTableNameClusterCredential).Delete(lgc.ctx, cond); err != nil {
+		blog.Errorf("[KubeCollect] delete credentials by %+v failed, err: %v, rid: %s", cond, err, rid)
+		return defErr.Error(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
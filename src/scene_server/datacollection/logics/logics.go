@@ -21,6 +21,7 @@ import (
 	"context"
 
 	"configcenter/src/common/backbone"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/storage/dal"
 	"configcenter/src/thirdparty/esbserver"
 )
@@ -31,9 +32,16 @@ type Logics struct {
 	db  dal.RDB
 	ESB esbserver.EsbClientInterface
 	ctx context.Context
+	// cryptor encrypts the kube cluster credentials, they are saved in plain text if it is nil
+	cryptor cryptor.Cryptor
 }
 
 // NewLogics TODO
 func NewLogics(ctx context.Context, engine *backbone.Engine, mgoCli dal.RDB, esb esbserver.EsbClientInterface) *Logics {
 	return &Logics{ctx: ctx, db: mgoCli, Engine: engine, ESB: esb}
 }
+
+// SetCryptor setups the cryptor of the kube cluster credentials
+func (lgc *Logics) SetCryptor(crypto cryptor.Cryptor) {
+	lgc.cryptor = crypto
+}
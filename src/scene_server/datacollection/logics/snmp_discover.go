@@ -0,0 +1,816 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/datacollection/collections/netcollect/snmp"
+)
+
+const (
+	snmpSysDescrOID    = "1.3.6.1.2.1.1.1.0"
+	snmpSysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+	snmpSysNameOID     = "1.3.6.1.2.1.1.5.0"
+
+	// lldpRemSysNameOID is the lldpRemSysName column of LLDP-MIB lldpRemTable, indexed by
+	// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+	lldpRemSysNameOID = "1.0.8802.1.1.2.1.4.1.1.9"
+	// lldpRemManAddrIfSubtypeOID is the lldpRemManAddrIfSubtype column of LLDP-MIB lldpRemManAddrTable, the
+	// management address is in its index after the lldpRemTable index as subtype.length.address
+	lldpRemManAddrIfSubtypeOID = "1.0.8802.1.1.2.1.4.2.1.3"
+	// cdpCacheAddressOID is the cdpCacheAddress column of CISCO-CDP-MIB cdpCacheTable
+	cdpCacheAddressOID = "1.3.6.1.4.1.9.9.23.1.2.1.1.4"
+	// cdpCacheDeviceIDOID is the cdpCacheDeviceId column of CISCO-CDP-MIB cdpCacheTable
+	cdpCacheDeviceIDOID = "1.3.6.1.4.1.9.9.23.1.2.1.1.6"
+
+	// maxSNMPDiscoverAddress is the max addresses of the scan range of one collector
+	maxSNMPDiscoverAddress = 4096
+	// snmpDiscoverWorker is the concurrent count of the snmp probe
+	snmpDiscoverWorker = 32
+	// defaultSNMPTimeoutSeconds is the default timeout of the snmp request when sweeping the scan range
+	defaultSNMPTimeoutSeconds = 2
+	// snmpDiscoverLoopInterval is the interval to check if the collectors need to be discovered periodically
+	snmpDiscoverLoopInterval = time.Minute
+)
+
+// snmpNeighbor is the lldp or cdp neighbor of the discovered device
+type snmpNeighbor struct {
+	name    string
+	address string
+}
+
+// discoveredDevice is the device that responds to the snmp probe
+type discoveredDevice struct {
+	ip          string
+	sysObjectID string
+	sysName     string
+	sysDescr    string
+	model       *metadata.NetcollectDevice
+	attributes  []metadata.NetcollectReportAttribute
+	neighbors   []snmpNeighbor
+}
+
+// instKey returns the instance name of the device, which is the sysName or the ip if sysName is not set
+func (d *discoveredDevice) instKey() string {
+	if d.sysName != "" {
+		return d.sysName
+	}
+	return d.ip
+}
+
+// SNMPDiscover discovers the devices in the scan range of the netcollectors with snmp, the discovered devices and
+// their lldp or cdp neighbors are saved as netcollect reports to be confirmed.
+func (lgc *Logics) SNMPDiscover(header http.Header, param metadata.ParamNetcollectSNMPDiscover) (
+	*metadata.RspNetcollectSNMPDiscover, error) {
+	rid := httpheader.GetRid(header)
+
+	cond := map[string]interface{}{
+		common.BKCloudIDField: param.CloudID,
+		"config.snmp":         map[string]interface{}{common.BKDBExists: true},
+	}
+	if param.InnerIP != "" {
+		cond[common.BKHostInnerIPField] = param.InnerIP
+	}
+
+	collectors := make([]metadata.Netcollector, 0)
+	if err := lgc.db.Table(common.BKTableNameNetcollectConfig).Find(cond).All(lgc.ctx, &collectors); err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] find collectors by %+v failed, err: %v, rid: %s", cond, err, rid)
+		return nil, err
+	}
+	if len(collectors) == 0 {
+		blog.Errorf("[NetDevice][SNMPDiscover] no collector with snmp config found by %+v, rid: %s", cond, rid)
+		return nil, errors.New("no netcollector with snmp config found")
+	}
+
+	result := &metadata.RspNetcollectSNMPDiscover{
+		Unmatched: make([]metadata.NetcollectUnmatchedDevice, 0),
+		Errors:    make([]string, 0),
+	}
+	for idx := range collectors {
+		if err := lgc.discoverCollector(header, &collectors[idx], result); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("discover collector %d:%s failed, err: %v",
+				collectors[idx].CloudID, collectors[idx].InnerIP, err))
+		}
+	}
+
+	return result, nil
+}
+
+// LoopSNMPDiscover discovers the collectors whose snmp discover period is set periodically
+func (lgc *Logics) LoopSNMPDiscover() {
+	for {
+		time.Sleep(snmpDiscoverLoopInterval)
+
+		if !lgc.ServiceManageInterface.IsMaster() {
+			continue
+		}
+
+		collectors, err := lgc.findPeriodSNMPCollectors()
+		if err != nil {
+			continue
+		}
+
+		for idx := range collectors {
+			collector := &collectors[idx]
+			if !isSNMPDiscoverDue(collector) {
+				continue
+			}
+
+			rid := util.GenerateRID()
+			header := headerutil.GenCommonHeader(common.CCSystemCollectorUserName, common.BKDefaultOwnerID, rid)
+			result := new(metadata.RspNetcollectSNMPDiscover)
+			if err := lgc.discoverCollector(header, collector, result); err != nil {
+				blog.Errorf("[NetDevice][LoopSNMPDiscover] discover collector %d:%s failed, err: %v, rid: %s",
+					collector.CloudID, collector.InnerIP, err, rid)
+				continue
+			}
+			blog.Infof("[NetDevice][LoopSNMPDiscover] discover collector %d:%s success, result: %+v, rid: %s",
+				collector.CloudID, collector.InnerIP, result, rid)
+		}
+	}
+}
+
+// findPeriodSNMPCollectors finds the collectors whose snmp discover period is set
+func (lgc *Logics) findPeriodSNMPCollectors() ([]metadata.Netcollector, error) {
+	cond := map[string]interface{}{
+		"config.snmp.discover_period": map[string]interface{}{common.BKDBExists: true, common.BKDBNE: ""},
+	}
+	collectors := make([]metadata.Netcollector, 0)
+	if err := lgc.db.Table(common.BKTableNameNetcollectConfig).Find(cond).All(lgc.ctx, &collectors); err != nil {
+		blog.Errorf("[NetDevice][LoopSNMPDiscover] find collectors by %+v failed, err: %v", cond, err)
+		return nil, err
+	}
+	return collectors, nil
+}
+
+// isSNMPDiscoverDue checks if the snmp discover period of the collector has passed since its last discovery
+func isSNMPDiscoverDue(collector *metadata.Netcollector) bool {
+	if collector.Config.SNMP == nil {
+		return false
+	}
+
+	period, err := time.ParseDuration(collector.Config.SNMP.DiscoverPeriod)
+	if err != nil || period <= 0 {
+		return false
+	}
+	return collector.LastDiscoverTime == nil || time.Since(*collector.LastDiscoverTime) >= period
+}
+
+// ValidateSNMPConfig validates the snmp config of the netcollector
+func ValidateSNMPConfig(config *metadata.NetcollectConfig) error {
+	if config.SNMP == nil {
+		return nil
+	}
+
+	if _, err := newSNMPConfig(config); err != nil {
+		return err
+	}
+
+	if _, err := expandScanRange(config.ScanRange, maxSNMPDiscoverAddress); err != nil {
+		return err
+	}
+
+	if config.SNMP.DiscoverPeriod != "" {
+		period, err := time.ParseDuration(config.SNMP.DiscoverPeriod)
+		if err != nil || period < time.Hour {
+			return fmt.Errorf("snmp discover period %s is invalid, it should be a duration like 24h and at least 1h",
+				config.SNMP.DiscoverPeriod)
+		}
+	}
+	return nil
+}
+
+func newSNMPConfig(config *metadata.NetcollectConfig) (snmp.Config, error) {
+	if config.SNMP == nil {
+		return snmp.Config{}, errors.New("snmp config is not set")
+	}
+
+	timeout := config.SNMP.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultSNMPTimeoutSeconds
+	}
+
+	snmpConfig := snmp.Config{
+		Version:       snmp.Version(config.SNMP.Version),
+		Port:          config.SNMP.Port,
+		Community:     config.Community,
+		Timeout:       time.Duration(timeout) * time.Second,
+		Retries:       config.SNMP.Retries,
+		UserName:      config.SNMP.UserName,
+		SecurityLevel: snmp.SecurityLevel(config.SNMP.SecurityLevel),
+		AuthProtocol:  snmp.AuthProtocol(config.SNMP.AuthProtocol),
+		AuthPassword:  config.SNMP.AuthPassword,
+		PrivProtocol:  snmp.PrivProtocol(config.SNMP.PrivProtocol),
+		PrivPassword:  config.SNMP.PrivPassword,
+	}
+	if err := snmpConfig.Validate(); err != nil {
+		return snmp.Config{}, err
+	}
+	return snmpConfig, nil
+}
+
+// discoverCollector sweeps the scan range of the collector, and saves the matched devices as netcollect reports
+func (lgc *Logics) discoverCollector(header http.Header, collector *metadata.Netcollector,
+	result *metadata.RspNetcollectSNMPDiscover) error {
+	rid := httpheader.GetRid(header)
+
+	config, err := newSNMPConfig(&collector.Config)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := expandScanRange(collector.Config.ScanRange, maxSNMPDiscoverAddress)
+	if err != nil {
+		return err
+	}
+
+	models, properties, err := lgc.findSNMPDeviceModels(httpheader.GetSupplierAccount(header))
+	if err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] find device models failed, err: %v, rid: %s", err, rid)
+		return err
+	}
+
+	devices := sweepSNMPDevices(addresses, config, models, properties)
+	result.Scanned += len(addresses)
+	result.Responded += len(devices)
+
+	matched := make([]*discoveredDevice, 0)
+	for _, device := range devices {
+		if device.model == nil {
+			result.Unmatched = append(result.Unmatched, metadata.NetcollectUnmatchedDevice{
+				CloudID:     collector.CloudID,
+				IP:          device.ip,
+				SysObjectID: device.sysObjectID,
+				SysName:     device.sysName,
+				SysDescr:    device.sysDescr,
+			})
+			continue
+		}
+		matched = append(matched, device)
+	}
+	blog.Infof("[NetDevice][SNMPDiscover] collector %d:%s scanned %d addresses, %d responded, %d matched, rid: %s",
+		collector.CloudID, collector.InnerIP, len(addresses), len(devices), len(matched), rid)
+
+	hostIPs, err := lgc.findNeighborHostIPs(header, collector.CloudID, matched)
+	if err != nil {
+		return err
+	}
+
+	asstIDs, err := lgc.findSNMPAssociationIDs(header, matched)
+	if err != nil {
+		return err
+	}
+
+	reports := buildSNMPDiscoverReports(collector, matched, hostIPs, asstIDs, httpheader.GetSupplierAccount(header))
+	for idx := range reports {
+		if err := lgc.saveSNMPDiscoverReport(&reports[idx]); err != nil {
+			blog.Errorf("[NetDevice][SNMPDiscover] save report %+v failed, err: %v, rid: %s", reports[idx], err, rid)
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Reports++
+		result.Associations += len(reports[idx].Associations)
+	}
+
+	filter := map[string]interface{}{
+		common.BKCloudIDField:     collector.CloudID,
+		common.BKHostInnerIPField: collector.InnerIP,
+	}
+	data := map[string]interface{}{"last_discover_time": time.Now()}
+	if err := lgc.db.Table(common.BKTableNameNetcollectConfig).Update(lgc.ctx, filter, data); err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] update collector %+v discover time failed, err: %v, rid: %s", filter,
+			err, rid)
+		return err
+	}
+	return nil
+}
+
+// findSNMPDeviceModels finds the device models with sysObjectID and their properties
+func (lgc *Logics) findSNMPDeviceModels(ownerID string) ([]metadata.NetcollectDevice,
+	map[uint64][]metadata.NetcollectProperty, error) {
+
+	deviceCond := map[string]interface{}{
+		common.BKOwnerIDField: ownerID,
+		"sys_object_id":       map[string]interface{}{common.BKDBExists: true, common.BKDBNE: ""},
+	}
+	devices := make([]metadata.NetcollectDevice, 0)
+	if err := lgc.db.Table(common.BKTableNameNetcollectDevice).Find(deviceCond).All(lgc.ctx, &devices); err != nil {
+		return nil, nil, err
+	}
+
+	deviceIDs := make([]uint64, len(devices))
+	for idx, device := range devices {
+		deviceIDs[idx] = device.DeviceID
+	}
+
+	propertyCond := map[string]interface{}{
+		common.BKOwnerIDField:  ownerID,
+		common.BKDeviceIDField: map[string]interface{}{common.BKDBIN: deviceIDs},
+	}
+	properties := make([]metadata.NetcollectProperty, 0)
+	err := lgc.db.Table(common.BKTableNameNetcollectProperty).Find(propertyCond).All(lgc.ctx, &properties)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	propertyMap := make(map[uint64][]metadata.NetcollectProperty)
+	for _, property := range properties {
+		propertyMap[property.DeviceID] = append(propertyMap[property.DeviceID], property)
+	}
+	return devices, propertyMap, nil
+}
+
+// sweepSNMPDevices probes the addresses concurrently, returns the devices that respond sorted by ip
+func sweepSNMPDevices(addresses []string, config snmp.Config, models []metadata.NetcollectDevice,
+	properties map[uint64][]metadata.NetcollectProperty) []*discoveredDevice {
+
+	devices := make([]*discoveredDevice, 0)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	pipeline := make(chan struct{}, snmpDiscoverWorker)
+	for _, address := range addresses {
+		pipeline <- struct{}{}
+		wg.Add(1)
+		go func(address string) {
+			defer func() {
+				<-pipeline
+				wg.Done()
+			}()
+
+			device := probeSNMPDevice(address, config, models, properties)
+			if device == nil {
+				return
+			}
+			lock.Lock()
+			devices = append(devices, device)
+			lock.Unlock()
+		}(address)
+	}
+	wg.Wait()
+
+	sort.Slice(devices, func(i, j int) bool {
+		return compareIP(devices[i].ip, devices[j].ip) < 0
+	})
+	return devices
+}
+
+// probeSNMPDevice gets the system info of the address, and the properties and neighbors of the matched device
+func probeSNMPDevice(address string, config snmp.Config, models []metadata.NetcollectDevice,
+	properties map[uint64][]metadata.NetcollectProperty) *discoveredDevice {
+
+	client, err := snmp.NewClient(address, config)
+	if err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] create snmp client of %s failed, err: %v", address, err)
+		return nil
+	}
+	defer client.Close()
+
+	vars, err := client.Get(snmpSysObjectIDOID, snmpSysNameOID, snmpSysDescrOID)
+	if err != nil {
+		blog.V(4).Infof("[NetDevice][SNMPDiscover] get system info of %s failed, err: %v", address, err)
+		return nil
+	}
+	if len(vars) != 3 || !vars[0].Exists() {
+		return nil
+	}
+
+	device := &discoveredDevice{
+		ip:          address,
+		sysObjectID: strings.TrimPrefix(vars[0].String(), "."),
+		sysName:     strings.TrimSpace(vars[1].String()),
+		sysDescr:    strings.TrimSpace(vars[2].String()),
+	}
+
+	device.model = matchDeviceModel(device.sysObjectID, models)
+	if device.model == nil {
+		return device
+	}
+
+	device.attributes = getSNMPDeviceAttributes(client, device, properties[device.model.DeviceID])
+	device.neighbors = getSNMPNeighbors(client, address)
+	return device
+}
+
+// matchDeviceModel returns the device model whose sysObjectID is the longest prefix of the sysObjectID
+func matchDeviceModel(sysObjectID string, models []metadata.NetcollectDevice) *metadata.NetcollectDevice {
+	var matched *metadata.NetcollectDevice
+	for idx := range models {
+		if !snmp.HasOIDPrefix(sysObjectID, models[idx].SysObjectID) {
+			continue
+		}
+		if matched == nil || len(models[idx].SysObjectID) > len(matched.SysObjectID) {
+			matched = &models[idx]
+		}
+	}
+	return matched
+}
+
+func getSNMPDeviceAttributes(client *snmp.Client, device *discoveredDevice,
+	properties []metadata.NetcollectProperty) []metadata.NetcollectReportAttribute {
+
+	attributes := []metadata.NetcollectReportAttribute{{PropertyID: common.BKInstNameField, CurValue: device.instKey()}}
+	for _, property := range properties {
+		if property.PropertyID == common.BKInstNameField {
+			continue
+		}
+
+		var vars []snmp.Variable
+		var err error
+		if property.Action == common.SNMPActionGetNext {
+			vars, err = client.GetNext(property.OID)
+		} else {
+			vars, err = client.Get(property.OID)
+		}
+		if err != nil {
+			blog.Errorf("[NetDevice][SNMPDiscover] get property %s oid %s of %s failed, err: %v", property.PropertyID,
+				property.OID, device.ip, err)
+			continue
+		}
+		if len(vars) == 0 || !vars[0].Exists() {
+			continue
+		}
+
+		attributes = append(attributes, metadata.NetcollectReportAttribute{
+			PropertyID: property.PropertyID,
+			CurValue:   vars[0].String(),
+		})
+	}
+	return attributes
+}
+
+// getSNMPNeighbors gets the lldp and cdp neighbors of the device
+func getSNMPNeighbors(client *snmp.Client, address string) []snmpNeighbor {
+	walk := func(oid string) []snmp.Variable {
+		vars, err := client.Walk(oid)
+		if err != nil {
+			blog.V(4).Infof("[NetDevice][SNMPDiscover] walk %s of %s failed, err: %v", oid, address, err)
+			return nil
+		}
+		return vars
+	}
+
+	neighbors := parseLLDPNeighbors(walk(lldpRemSysNameOID), walk(lldpRemManAddrIfSubtypeOID))
+	return append(neighbors, parseCDPNeighbors(walk(cdpCacheAddressOID), walk(cdpCacheDeviceIDOID))...)
+}
+
+// parseLLDPNeighbors parses the neighbors from the lldpRemSysName and lldpRemManAddrIfSubtype columns
+func parseLLDPNeighbors(names, addresses []snmp.Variable) []snmpNeighbor {
+	keys := make([]string, 0)
+	neighborMap := make(map[string]*snmpNeighbor)
+	getNeighbor := func(key string) *snmpNeighbor {
+		if neighbor, ok := neighborMap[key]; ok {
+			return neighbor
+		}
+		keys = append(keys, key)
+		neighborMap[key] = new(snmpNeighbor)
+		return neighborMap[key]
+	}
+
+	for _, v := range names {
+		key := strings.TrimPrefix(v.OID, lldpRemSysNameOID+".")
+		if strings.Count(key, ".") != 2 {
+			continue
+		}
+		getNeighbor(key).name = strings.TrimSpace(v.String())
+	}
+
+	for _, v := range addresses {
+		// the index is timemark.port.index.subtype.length.address, subtype 1 is ipv4
+		index := strings.Split(strings.TrimPrefix(v.OID, lldpRemManAddrIfSubtypeOID+"."), ".")
+		if len(index) != 9 || index[3] != "1" || index[4] != "4" {
+			continue
+		}
+
+		neighbor := getNeighbor(strings.Join(index[:3], "."))
+		if neighbor.address == "" {
+			neighbor.address = strings.Join(index[5:], ".")
+		}
+	}
+
+	neighbors := make([]snmpNeighbor, 0, len(keys))
+	for _, key := range keys {
+		neighbors = append(neighbors, *neighborMap[key])
+	}
+	return neighbors
+}
+
+// parseCDPNeighbors parses the neighbors from the cdpCacheAddress and cdpCacheDeviceId columns
+func parseCDPNeighbors(addresses, deviceIDs []snmp.Variable) []snmpNeighbor {
+	keys := make([]string, 0)
+	neighborMap := make(map[string]*snmpNeighbor)
+	for _, v := range addresses {
+		key := strings.TrimPrefix(v.OID, cdpCacheAddressOID+".")
+		address, ok := v.Value.([]byte)
+		if !ok || len(address) != net.IPv4len {
+			continue
+		}
+		keys = append(keys, key)
+		neighborMap[key] = &snmpNeighbor{address: net.IP(address).String()}
+	}
+
+	for _, v := range deviceIDs {
+		key := strings.TrimPrefix(v.OID, cdpCacheDeviceIDOID+".")
+		neighbor, ok := neighborMap[key]
+		if !ok {
+			keys = append(keys, key)
+			neighbor = new(snmpNeighbor)
+			neighborMap[key] = neighbor
+		}
+		neighbor.name = strings.TrimSpace(v.String())
+	}
+
+	neighbors := make([]snmpNeighbor, 0, len(keys))
+	for _, key := range keys {
+		neighbors = append(neighbors, *neighborMap[key])
+	}
+	return neighbors
+}
+
+// findNeighborHostIPs finds the neighbor addresses that are the inner ips of the hosts in the cloud area
+func (lgc *Logics) findNeighborHostIPs(header http.Header, cloudID int64, devices []*discoveredDevice) (
+	map[string]struct{}, error) {
+
+	addresses := make([]string, 0)
+	for _, device := range devices {
+		for _, neighbor := range device.neighbors {
+			if neighbor.address != "" {
+				addresses = append(addresses, neighbor.address)
+			}
+		}
+	}
+
+	hostIPs := make(map[string]struct{})
+	if len(addresses) == 0 {
+		return hostIPs, nil
+	}
+	addresses = util.StrArrayUnique(addresses)
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKCloudIDField:     cloudID,
+			common.BKHostInnerIPField: mapstr.MapStr{common.BKDBIN: addresses},
+		},
+		Fields: []string{common.BKHostInnerIPField},
+	}
+	hosts, err := lgc.findInst(header, common.BKInnerObjIDHost, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	addressMap := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		addressMap[address] = struct{}{}
+	}
+
+	for _, host := range hosts {
+		// the inner ip of the host is stored as an array, and may be returned as a comma separated string
+		ips := make([]string, 0)
+		switch value := host[common.BKHostInnerIPField].(type) {
+		case []interface{}:
+			for _, ip := range value {
+				ips = append(ips, util.GetStrByInterface(ip))
+			}
+		default:
+			ips = strings.Split(util.GetStrByInterface(value), ",")
+		}
+
+		for _, ip := range ips {
+			if _, ok := addressMap[ip]; ok {
+				hostIPs[ip] = struct{}{}
+			}
+		}
+	}
+	return hostIPs, nil
+}
+
+// findSNMPAssociationIDs finds the model associations between the device models and the host model
+func (lgc *Logics) findSNMPAssociationIDs(header http.Header, devices []*discoveredDevice) (map[string]string,
+	error) {
+
+	objIDs := []string{common.BKInnerObjIDHost}
+	for _, device := range devices {
+		objIDs = append(objIDs, device.model.ObjectID)
+	}
+	objIDs = util.StrArrayUnique(objIDs)
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKObjIDField:     mapstr.MapStr{common.BKDBIN: objIDs},
+			common.BKAsstObjIDField: mapstr.MapStr{common.BKDBIN: objIDs},
+		},
+		Page: metadata.BasePage{Limit: common.BKNoLimit, Sort: common.BKFieldID},
+	}
+	resp, err := lgc.CoreAPI.CoreService().Association().ReadModelAssociation(context.Background(), header, cond)
+	if err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] read model association by %+v failed, err: %v, rid: %s", cond, err,
+			httpheader.GetRid(header))
+		return nil, err
+	}
+
+	asstIDs := make(map[string]string)
+	for _, asst := range resp.Info {
+		key := snmpAsstKey(asst.ObjectID, asst.AsstObjID)
+		if _, ok := asstIDs[key]; !ok {
+			asstIDs[key] = asst.AssociationName
+		}
+	}
+	return asstIDs, nil
+}
+
+func snmpAsstKey(objID, asstObjID string) string {
+	return objID + ":" + asstObjID
+}
+
+// buildSNMPDiscoverReports builds the netcollect reports of the matched devices, the neighbors that are discovered
+// devices or hosts are reported as the associations if the model association exists.
+func buildSNMPDiscoverReports(collector *metadata.Netcollector, devices []*discoveredDevice,
+	hostIPs map[string]struct{}, asstIDs map[string]string, ownerID string) []metadata.NetcollectReport {
+
+	byIP := make(map[string]int, len(devices))
+	byName := make(map[string]int, len(devices))
+	reports := make([]metadata.NetcollectReport, len(devices))
+	for idx, device := range devices {
+		byIP[device.ip] = idx
+		if device.sysName != "" {
+			byName[strings.ToLower(device.sysName)] = idx
+		}
+
+		reports[idx] = metadata.NetcollectReport{
+			CloudID:      collector.CloudID,
+			ObjectID:     device.model.ObjectID,
+			InnerIP:      collector.InnerIP,
+			OwnerID:      ownerID,
+			InstKey:      device.instKey(),
+			LastTime:     metadata.Now(),
+			Attributes:   device.attributes,
+			Associations: make([]metadata.NetcollectReportAssociation, 0),
+		}
+	}
+
+	added := make(map[string]struct{})
+	addAssociation := func(idx int, asstObjID, asstInstName string) {
+		objAsstID, ok := asstIDs[snmpAsstKey(reports[idx].ObjectID, asstObjID)]
+		if !ok {
+			return
+		}
+
+		key := strings.Join([]string{reports[idx].ObjectID, reports[idx].InstKey, asstObjID, asstInstName}, ":")
+		if _, ok := added[key]; ok {
+			return
+		}
+		added[key] = struct{}{}
+
+		reports[idx].Associations = append(reports[idx].Associations, metadata.NetcollectReportAssociation{
+			AsstInstName: asstInstName,
+			AsstObjectID: asstObjID,
+			ObjectAsstID: objAsstID,
+		})
+	}
+
+	for idx, device := range devices {
+		for _, neighbor := range device.neighbors {
+			peer, ok := byIP[neighbor.address]
+			if !ok && neighbor.name != "" {
+				peer, ok = byName[strings.ToLower(neighbor.name)]
+			}
+
+			if ok {
+				if peer == idx {
+					continue
+				}
+
+				// the device pair is reported once in the direction of the model association
+				src, dst := idx, peer
+				if reports[src].InstKey > reports[dst].InstKey {
+					src, dst = dst, src
+				}
+				if _, exists := asstIDs[snmpAsstKey(reports[src].ObjectID, reports[dst].ObjectID)]; !exists {
+					src, dst = dst, src
+				}
+				addAssociation(src, reports[dst].ObjectID, reports[dst].InstKey)
+				continue
+			}
+
+			if _, isHost := hostIPs[neighbor.address]; isHost {
+				addAssociation(idx, common.BKInnerObjIDHost, neighbor.address)
+			}
+		}
+	}
+	return reports
+}
+
+func (lgc *Logics) saveSNMPDiscoverReport(report *metadata.NetcollectReport) error {
+	filter := map[string]interface{}{
+		common.BKCloudIDField: report.CloudID,
+		common.BKObjIDField:   report.ObjectID,
+		common.BKInstKeyField: report.InstKey,
+	}
+
+	count, err := lgc.db.Table(common.BKTableNameNetcollectReport).Find(filter).Count(lgc.ctx)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return lgc.db.Table(common.BKTableNameNetcollectReport).Insert(lgc.ctx, report)
+	}
+	return lgc.db.Table(common.BKTableNameNetcollectReport).Update(lgc.ctx, filter, report)
+}
+
+// expandScanRange expands the scan range to ipv4 addresses, the scan range item can be a cidr like 10.0.0.0/24, a
+// range like 10.0.0.1-10.0.0.100 or a single ip, the network and broadcast address of the cidr are excluded.
+func expandScanRange(scanRange []string, limit int) ([]string, error) {
+	addresses := make([]string, 0)
+	seen := make(map[string]struct{})
+	add := func(start, end *big.Int) error {
+		for ip := new(big.Int).Set(start); ip.Cmp(end) <= 0; ip.Add(ip, big.NewInt(1)) {
+			address := net.IP(ip.FillBytes(make([]byte, net.IPv4len))).String()
+			if _, ok := seen[address]; ok {
+				continue
+			}
+			if len(addresses) >= limit {
+				return fmt.Errorf("scan range exceeds the max %d addresses", limit)
+			}
+			seen[address] = struct{}{}
+			addresses = append(addresses, address)
+		}
+		return nil
+	}
+
+	for _, item := range scanRange {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.Contains(item, "/"):
+			_, network, err := net.ParseCIDR(item)
+			if err != nil || network.IP.To4() == nil {
+				return nil, fmt.Errorf("scan range %s is not a valid ipv4 cidr", item)
+			}
+			ones, bits := network.Mask.Size()
+			start := new(big.Int).SetBytes(network.IP.To4())
+			end := new(big.Int).Add(start, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+			end.Sub(end, big.NewInt(1))
+			if bits-ones >= 2 {
+				start.Add(start, big.NewInt(1))
+				end.Sub(end, big.NewInt(1))
+			}
+			if err := add(start, end); err != nil {
+				return nil, err
+			}
+
+		case strings.Contains(item, "-"):
+			parts := strings.SplitN(item, "-", 2)
+			start, end := net.ParseIP(strings.TrimSpace(parts[0])).To4(), net.ParseIP(strings.TrimSpace(parts[1])).To4()
+			if start == nil || end == nil || compareIP(start.String(), end.String()) > 0 {
+				return nil, fmt.Errorf("scan range %s is not a valid ipv4 range", item)
+			}
+			if err := add(new(big.Int).SetBytes(start), new(big.Int).SetBytes(end)); err != nil {
+				return nil, err
+			}
+
+		default:
+			ip := net.ParseIP(item).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("scan range %s is not a valid ipv4 address", item)
+			}
+			value := new(big.Int).SetBytes(ip)
+			if err := add(value, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return addresses, nil
+}
+
+func compareIP(a, b string) int {
+	return new(big.Int).SetBytes(net.ParseIP(a).To4()).Cmp(new(big.Int).SetBytes(net.ParseIP(b).To4()))
+}
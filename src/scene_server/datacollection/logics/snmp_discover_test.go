@@ -0,0 +1,243 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/datacollection/collections/netcollect/snmp"
+	"configcenter/src/storage/dal/memory"
+)
+
+func TestExpandScanRange(t *testing.T) {
+	addresses, err := expandScanRange([]string{"10.0.0.0/30", "10.0.0.2-10.0.0.4", " 192.168.1.1 "}, 10)
+	if err != nil {
+		t.Fatalf("expand scan range failed, err: %v", err)
+	}
+	expected := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "192.168.1.1"}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("expand scan range got %v, expected %v", addresses, expected)
+	}
+
+	addresses, err = expandScanRange([]string{"10.0.0.8/31"}, 10)
+	if err != nil || !reflect.DeepEqual(addresses, []string{"10.0.0.8", "10.0.0.9"}) {
+		t.Errorf("expand /31 got %v, err: %v", addresses, err)
+	}
+
+	invalid := [][]string{{"10.0.0.0/8"}, {"10.0.0.5-10.0.0.1"}, {"10.0.0.256"}, {"fe80::1/64"}}
+	for _, scanRange := range invalid {
+		if _, err := expandScanRange(scanRange, maxSNMPDiscoverAddress); err == nil {
+			t.Errorf("expand scan range %v should fail", scanRange)
+		}
+	}
+}
+
+func TestMatchDeviceModel(t *testing.T) {
+	models := []metadata.NetcollectDevice{
+		{DeviceID: 1, SysObjectID: "1.3.6.1.4.1.2011"},
+		{DeviceID: 2, SysObjectID: "1.3.6.1.4.1.2011.2.23"},
+		{DeviceID: 3, SysObjectID: "1.3.6.1.4.1.9"},
+	}
+
+	cases := map[string]uint64{
+		"1.3.6.1.4.1.2011.2.23.96": 2,
+		"1.3.6.1.4.1.2011.2.62":    1,
+		"1.3.6.1.4.1.9.1.1208":     3,
+		"1.3.6.1.4.1.99":           0,
+		"1.3.6.1.4.1.20110":        0,
+	}
+	for sysObjectID, deviceID := range cases {
+		model := matchDeviceModel(sysObjectID, models)
+		if deviceID == 0 {
+			if model != nil {
+				t.Errorf("%s should not match, but matched %d", sysObjectID, model.DeviceID)
+			}
+			continue
+		}
+		if model == nil || model.DeviceID != deviceID {
+			t.Errorf("%s should match %d, but matched %+v", sysObjectID, deviceID, model)
+		}
+	}
+}
+
+func TestParseNeighbors(t *testing.T) {
+	names := []snmp.Variable{
+		{OID: lldpRemSysNameOID + ".0.1.1", Type: snmp.OctetString, Value: []byte("switch-b")},
+		{OID: lldpRemSysNameOID + ".0.2.3", Type: snmp.OctetString, Value: []byte("server-1")},
+	}
+	addresses := []snmp.Variable{
+		{OID: lldpRemManAddrIfSubtypeOID + ".0.1.1.1.4.10.0.0.2", Type: snmp.Integer, Value: int64(2)},
+		{OID: lldpRemManAddrIfSubtypeOID + ".0.2.3.1.4.10.0.1.10", Type: snmp.Integer, Value: int64(2)},
+		{OID: lldpRemManAddrIfSubtypeOID + ".0.2.3.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.1",
+			Type: snmp.Integer, Value: int64(2)},
+	}
+	lldp := parseLLDPNeighbors(names, addresses)
+	expected := []snmpNeighbor{{name: "switch-b", address: "10.0.0.2"}, {name: "server-1", address: "10.0.1.10"}}
+	if !reflect.DeepEqual(lldp, expected) {
+		t.Errorf("parse lldp neighbors got %+v, expected %+v", lldp, expected)
+	}
+
+	cdpAddresses := []snmp.Variable{
+		{OID: cdpCacheAddressOID + ".3.1", Type: snmp.OctetString, Value: []byte{10, 0, 0, 3}},
+	}
+	cdpDeviceIDs := []snmp.Variable{
+		{OID: cdpCacheDeviceIDOID + ".3.1", Type: snmp.OctetString, Value: []byte("switch-c")},
+		{OID: cdpCacheDeviceIDOID + ".4.1", Type: snmp.OctetString, Value: []byte("switch-d")},
+	}
+	cdp := parseCDPNeighbors(cdpAddresses, cdpDeviceIDs)
+	expected = []snmpNeighbor{{name: "switch-c", address: "10.0.0.3"}, {name: "switch-d"}}
+	if !reflect.DeepEqual(cdp, expected) {
+		t.Errorf("parse cdp neighbors got %+v, expected %+v", cdp, expected)
+	}
+}
+
+func TestBuildSNMPDiscoverReports(t *testing.T) {
+	switchModel := &metadata.NetcollectDevice{ObjectID: "bk_switch"}
+	devices := []*discoveredDevice{
+		{ip: "10.0.0.1", sysName: "switch-a", model: switchModel, neighbors: []snmpNeighbor{
+			{name: "switch-b", address: "10.0.0.2"}, {address: "10.0.1.10"}, {address: "10.0.1.11"}}},
+		{ip: "10.0.0.2", model: switchModel, neighbors: []snmpNeighbor{{name: "switch-a", address: "10.0.0.254"}}},
+	}
+	hostIPs := map[string]struct{}{"10.0.1.10": {}}
+	asstIDs := map[string]string{
+		snmpAsstKey("bk_switch", "bk_switch"):             "bk_switch_connect_bk_switch",
+		snmpAsstKey("bk_switch", common.BKInnerObjIDHost): "bk_switch_connect_host",
+	}
+
+	collector := &metadata.Netcollector{CloudID: 0, InnerIP: "10.0.0.100"}
+	reports := buildSNMPDiscoverReports(collector, devices, hostIPs, asstIDs, "0")
+	if len(reports) != 2 {
+		t.Fatalf("build reports got %d reports, expected 2", len(reports))
+	}
+	if reports[0].InstKey != "switch-a" || reports[1].InstKey != "10.0.0.2" {
+		t.Errorf("build reports got unexpected inst keys %s, %s", reports[0].InstKey, reports[1].InstKey)
+	}
+
+	// the switch pair is reported once, and the unknown neighbor is ignored
+	expected := []metadata.NetcollectReportAssociation{
+		{AsstInstName: "10.0.1.10", AsstObjectID: common.BKInnerObjIDHost, ObjectAsstID: "bk_switch_connect_host"},
+	}
+	if !reflect.DeepEqual(reports[0].Associations, expected) {
+		t.Errorf("switch-a got associations %+v, expected %+v", reports[0].Associations, expected)
+	}
+	expected = []metadata.NetcollectReportAssociation{
+		{AsstInstName: "switch-a", AsstObjectID: "bk_switch", ObjectAsstID: "bk_switch_connect_bk_switch"},
+	}
+	if !reflect.DeepEqual(reports[1].Associations, expected) {
+		t.Errorf("10.0.0.2 got associations %+v, expected %+v", reports[1].Associations, expected)
+	}
+
+	// the device pair is reported in the direction of the model association
+	routerModel := &metadata.NetcollectDevice{ObjectID: "bk_router"}
+	devices[1].model = routerModel
+	asstIDs = map[string]string{snmpAsstKey("bk_router", "bk_switch"): "bk_router_connect_bk_switch"}
+	reports = buildSNMPDiscoverReports(collector, devices, hostIPs, asstIDs, "0")
+	if len(reports[0].Associations) != 0 || len(reports[1].Associations) != 1 ||
+		reports[1].Associations[0].AsstInstName != "switch-a" {
+		t.Errorf("build reports got unexpected associations %+v, %+v", reports[0].Associations,
+			reports[1].Associations)
+	}
+}
+
+func TestProbeSNMPDevice(t *testing.T) {
+	vars := []snmp.Variable{
+		{OID: snmpSysDescrOID, Type: snmp.OctetString, Value: []byte("Huawei Versatile Routing Platform")},
+		{OID: snmpSysObjectIDOID, Type: snmp.ObjectIdentifier, Value: "1.3.6.1.4.1.2011.2.23.96"},
+		{OID: snmpSysNameOID, Type: snmp.OctetString, Value: []byte("switch-a")},
+		{OID: "1.3.6.1.2.1.2.1.0", Type: snmp.Integer, Value: int64(48)},
+		{OID: lldpRemSysNameOID + ".0.1.1", Type: snmp.OctetString, Value: []byte("switch-b")},
+		{OID: lldpRemManAddrIfSubtypeOID + ".0.1.1.1.4.10.0.0.2", Type: snmp.Integer, Value: int64(2)},
+	}
+	config := snmp.Config{Version: snmp.V3, UserName: "cmdb", SecurityLevel: snmp.AuthPriv, AuthProtocol: snmp.SHA,
+		AuthPassword: "auth-password", PrivProtocol: snmp.AES, PrivPassword: "priv-password"}
+	sim, err := snmp.NewSimulator("127.0.0.1:0", config, vars)
+	if err != nil {
+		t.Fatalf("start simulator failed, err: %v", err)
+	}
+	defer sim.Close()
+
+	config.Port = sim.Port()
+	config.Timeout = time.Second
+	models := []metadata.NetcollectDevice{{DeviceID: 1, ObjectID: "bk_switch", SysObjectID: "1.3.6.1.4.1.2011"}}
+	properties := map[uint64][]metadata.NetcollectProperty{1: {
+		{DeviceID: 1, PropertyID: "bk_port_count", OID: "1.3.6.1.2.1.2.1.0", Action: common.SNMPActionGet},
+		{DeviceID: 1, PropertyID: "bk_vendor", OID: "1.3.6.1.2.1.1.1", Action: common.SNMPActionGetNext},
+	}}
+
+	device := probeSNMPDevice("127.0.0.1", config, models, properties)
+	if device == nil || device.model == nil || device.model.DeviceID != 1 {
+		t.Fatalf("probe device got unexpected device %+v", device)
+	}
+	expected := []metadata.NetcollectReportAttribute{
+		{PropertyID: common.BKInstNameField, CurValue: "switch-a"},
+		{PropertyID: "bk_port_count", CurValue: "48"},
+		{PropertyID: "bk_vendor", CurValue: "Huawei Versatile Routing Platform"},
+	}
+	if !reflect.DeepEqual(device.attributes, expected) {
+		t.Errorf("probe device got attributes %+v, expected %+v", device.attributes, expected)
+	}
+	if !reflect.DeepEqual(device.neighbors, []snmpNeighbor{{name: "switch-b", address: "10.0.0.2"}}) {
+		t.Errorf("probe device got unexpected neighbors %+v", device.neighbors)
+	}
+
+	config.Timeout = 100 * time.Millisecond
+	config.PrivPassword = "wrong-password"
+	if device := probeSNMPDevice("127.0.0.1", config, models, properties); device != nil {
+		t.Errorf("probe device with wrong credential should fail, but got %+v", device)
+	}
+}
+
+func TestFindPeriodSNMPCollectors(t *testing.T) {
+	lgc := &Logics{ctx: context.Background(), db: memory.New()}
+	collectors := []metadata.Netcollector{
+		{CloudID: 0, InnerIP: "10.0.0.1"},
+		{CloudID: 0, InnerIP: "10.0.0.2", Config: metadata.NetcollectConfig{
+			SNMP: &metadata.NetcollectSNMPConfig{Version: "v2c"}}},
+		{CloudID: 0, InnerIP: "10.0.0.3", Config: metadata.NetcollectConfig{
+			SNMP: &metadata.NetcollectSNMPConfig{Version: "v2c", DiscoverPeriod: "24h"}}},
+	}
+	if err := lgc.db.Table(common.BKTableNameNetcollectConfig).Insert(lgc.ctx, collectors); err != nil {
+		t.Fatalf("insert collectors failed, err: %v", err)
+	}
+
+	found, err := lgc.findPeriodSNMPCollectors()
+	if err != nil {
+		t.Fatalf("find period snmp collectors failed, err: %v", err)
+	}
+	if len(found) != 1 || found[0].InnerIP != "10.0.0.3" {
+		t.Fatalf("find period snmp collectors got %+v, expected collector 10.0.0.3", found)
+	}
+	if !isSNMPDiscoverDue(&found[0]) {
+		t.Errorf("collector that is never discovered should be due")
+	}
+
+	// collector without snmp config is never due
+	if isSNMPDiscoverDue(&collectors[0]) {
+		t.Errorf("collector without snmp config should not be due")
+	}
+
+	lastTime := time.Now().Add(-time.Hour)
+	found[0].LastDiscoverTime = &lastTime
+	if isSNMPDiscoverDue(&found[0]) {
+		t.Errorf("collector discovered an hour ago should not be due for a 24h period")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"configcenter/src/common/blog"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/datacollection/logics"
 )
 
 // SearchCollector TODO
@@ -80,6 +81,12 @@ func (s *Service) UpdateCollector(req *restful.Request, resp *restful.Response)
 			&metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsLostField, common.BKHostInnerIPField)})
 		return
 	}
+	if err := logics.ValidateSNMPConfig(&cond.Config); err != nil {
+		blog.Errorf("[NetDevice][UpdateCollector] snmp config is invalid, err: %v", err)
+		resp.WriteError(http.StatusBadRequest,
+			&metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, err.Error())})
+		return
+	}
 
 	err := s.logics.UpdateCollector(pheader, cond)
 	if err != nil {
@@ -116,3 +123,27 @@ func (s *Service) DiscoverNetDevice(req *restful.Request, resp *restful.Response
 	resp.WriteEntity(metadata.NewSuccessResp(nil))
 	return
 }
+
+// SNMPDiscover discovers the network devices in the scan range of the collectors by snmp
+func (s *Service) SNMPDiscover(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(pheader))
+
+	cond := metadata.ParamNetcollectSNMPDiscover{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&cond); err != nil {
+		blog.Errorf("[NetDevice][SNMPDiscover] decode body failed, err: %v", err)
+		resp.WriteError(http.StatusBadRequest,
+			&metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	blog.Infof("[NetDevice][SNMPDiscover] discover by %+v", cond)
+
+	result, err := s.logics.SNMPDiscover(pheader, cond)
+	if err != nil {
+		resp.WriteError(http.StatusInternalServerError,
+			&metadata.RespError{Msg: defErr.Error(common.CCErrCollectNetCollectorDiscoverFail)})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
@@ -0,0 +1,146 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	acmeta "configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// RegisterKubeCredential registers the credential of a kube cluster to be collected
+func (s *Service) RegisterKubeCredential(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(pheader))
+	rid := httpheader.GetRid(pheader)
+
+	opt := new(types.RegisterCredentialOption)
+	if err := json.NewDecoder(req.Request.Body).Decode(opt); err != nil {
+		blog.Errorf("[KubeCollect] decode register credential option failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest,
+			&metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: rawErr.ToCCError(defErr)})
+		return
+	}
+
+	if !s.authorizeKube(req, resp, opt.BizID, acmeta.Update) {
+		return
+	}
+
+	id, err := s.logics.RegisterKubeCredential(pheader, opt)
+	if err != nil {
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: err})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.RspID{ID: id}))
+}
+
+// SearchKubeCredential searches the credentials of the kube clusters in a business, the kubeconfig is not returned
+func (s *Service) SearchKubeCredential(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(pheader))
+	rid := httpheader.GetRid(pheader)
+
+	opt := new(types.SearchCredentialOption)
+	if err := json.NewDecoder(req.Request.Body).Decode(opt); err != nil {
+		blog.Errorf("[KubeCollect] decode search credential option failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest,
+			&metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: rawErr.ToCCError(defErr)})
+		return
+	}
+
+	if !s.authorizeKube(req, resp, opt.BizID, acmeta.Find) {
+		return
+	}
+
+	result, err := s.logics.SearchKubeCredential(pheader, opt)
+	if err != nil {
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: err})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// DeleteKubeCredential deletes the credentials of the kube clusters, the clusters are no longer collected
+func (s *Service) DeleteKubeCredential(req *restful.Request, resp *restful.Response) {
+	pheader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(pheader))
+	rid := httpheader.GetRid(pheader)
+
+	opt := new(types.DeleteCredentialOption)
+	if err := json.NewDecoder(req.Request.Body).Decode(opt); err != nil {
+		blog.Errorf("[KubeCollect] decode delete credential option failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest,
+			&metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: rawErr.ToCCError(defErr)})
+		return
+	}
+
+	if !s.authorizeKube(req, resp, opt.BizID, acmeta.Update) {
+		return
+	}
+
+	if err := s.logics.DeleteKubeCredential(pheader, opt); err != nil {
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: err})
+		return
+	}
+
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
+
+// authorizeKube authorizes the kube cluster action in the business, the kube apis are skipped by the api server's
+// authorization, so they are authorized here. It writes the no permission response if the user is not authorized.
+func (s *Service) authorizeKube(req *restful.Request, resp *restful.Response, bizID int64,
+	action acmeta.Action) bool {
+
+	if s.authManager == nil {
+		return true
+	}
+
+	kit := rest.NewKitFromHeader(req.Request.Header, s.engine.CCErr)
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeCluster, Action: action},
+		BusinessID: bizID}
+	if noAuthResp, authorized := s.authManager.Authorize(kit, authRes); !authorized {
+		resp.WriteEntity(noAuthResp)
+		return false
+	}
+	return true
+}
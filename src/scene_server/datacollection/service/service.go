@@ -21,8 +21,10 @@ import (
 	"context"
 	"fmt"
 
+	"configcenter/src/ac/extensions"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/metric"
@@ -50,6 +52,8 @@ type Service struct {
 	netCli  redis.Client
 
 	logics *logics.Logics
+
+	authManager *extensions.AuthManager
 }
 
 // NewService creates a new Service object.
@@ -62,6 +66,21 @@ func (s *Service) SetLogics(db dal.RDB, esb esbserver.EsbClientInterface) {
 	s.logics = logics.NewLogics(s.ctx, s.engine, db, esb)
 }
 
+// LoopSNMPDiscover discovers the network devices by snmp periodically.
+func (s *Service) LoopSNMPDiscover() {
+	s.logics.LoopSNMPDiscover()
+}
+
+// SetCryptor setups the cryptor of the kube cluster credentials.
+func (s *Service) SetCryptor(crypto cryptor.Cryptor) {
+	s.logics.SetCryptor(crypto)
+}
+
+// SetAuthManager setups auth manager.
+func (s *Service) SetAuthManager(authManager *extensions.AuthManager) {
+	s.authManager = authManager
+}
+
 // SetDB setups database.
 func (s *Service) SetDB(db dal.RDB) {
 	s.db = db
@@ -120,6 +139,11 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/netcollect/collector/action/search").To(s.SearchCollector))
 	api.Route(api.POST("/netcollect/collector/action/update").To(s.UpdateCollector))
 	api.Route(api.POST("/netcollect/collector/action/discover").To(s.DiscoverNetDevice))
+	api.Route(api.POST("/netcollect/collector/action/snmp_discover").To(s.SNMPDiscover))
+
+	api.Route(api.POST("/kube/cluster/credential/action/register").To(s.RegisterKubeCredential))
+	api.Route(api.POST("/kube/cluster/credential/action/search").To(s.SearchKubeCredential))
+	api.Route(api.DELETE("/kube/cluster/credential/action/delete").To(s.DeleteKubeCredential))
 
 	container.Add(api)
 
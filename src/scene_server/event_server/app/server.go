@@ -23,9 +23,9 @@ import (
 	"sync"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
@@ -34,6 +34,7 @@ import (
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/event_server/app/options"
 	svc "configcenter/src/scene_server/event_server/service"
+	"configcenter/src/scene_server/event_server/subscription"
 	"configcenter/src/scene_server/event_server/sync/hostidentifier"
 	eventtype "configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal"
@@ -90,6 +91,7 @@ func NewEventServer(ctx context.Context, op *options.ServerOption) (*EventServer
 	engine, err := backbone.NewBackbone(ctx, &backbone.BackboneParameter{
 		ConfigUpdate: newEventServer.OnHostConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo:      svrInfo,
 	})
@@ -183,7 +185,7 @@ func (es *EventServer) initConfigs() error {
 	}
 
 	es.config.Auth, err = iam.ParseConfigFromKV("authServer", nil)
-	if err != nil {
+	if err != nil && ac.EnableIAM() {
 		blog.Errorf("parse auth center config failed: %v", err)
 		return err
 	}
@@ -244,10 +246,10 @@ func (es *EventServer) initModules() error {
 	blog.Infof("init modules, connected to cc redis, %+v", es.config.Redis)
 
 	// initialize auth authorizer
-	es.service.SetAuthorizer(iam.NewAuthorizer(es.engine.CoreAPI))
+	es.service.SetAuthorizer(ac.NewAuthorizer(es.engine.CoreAPI))
 
 	iamCli := new(iam.IAM)
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		blog.Info("enable auth center access")
 		iamCli, err = iam.NewIAM(es.config.Auth, es.engine.Metric().Registry())
 		if err != nil {
@@ -258,6 +260,11 @@ func (es *EventServer) initModules() error {
 	}
 	es.service.AuthManager = extensions.NewAuthManager(es.engine.CoreAPI, iamCli)
 
+	// run event webhook subscription dispatcher
+	dispatcher := subscription.NewDispatcher(es.engine, db)
+	es.service.SetDeliverer(dispatcher.Deliverer())
+	go dispatcher.Run(es.ctx)
+
 	return nil
 }
 
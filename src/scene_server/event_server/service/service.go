@@ -32,6 +32,7 @@ import (
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	"configcenter/src/common/webservice/restfulservice"
+	"configcenter/src/scene_server/event_server/subscription"
 	"configcenter/src/scene_server/event_server/sync/hostidentifier"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/redis"
@@ -49,6 +50,7 @@ type Service struct {
 	cache       redis.Client
 	authorizer  ac.AuthorizeInterface
 	AuthManager *extensions.AuthManager
+	deliverer   *subscription.Deliverer
 
 	// SyncData is sync host identifier operator
 	SyncData *hostidentifier.HostIdentifier
@@ -74,6 +76,11 @@ func (s *Service) SetAuthorizer(authorizer ac.AuthorizeInterface) {
 	s.authorizer = authorizer
 }
 
+// SetDeliverer setups event subscription deliverer.
+func (s *Service) SetDeliverer(deliverer *subscription.Deliverer) {
+	s.deliverer = deliverer
+}
+
 // WebService setups a new restful web service.
 func (s *Service) WebService() *restful.Container {
 	container := restful.NewContainer()
@@ -116,6 +123,19 @@ func (s *Service) initService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/host_identifier_push_result",
 		Handler: s.GetHostIdentifierPushResult})
 
+	// event webhook subscription apis
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/subscription", Handler: s.CreateSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/subscription", Handler: s.UpdateSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/subscription",
+		Handler: s.DeleteSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/subscription", Handler: s.ListSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/subscription/delivery",
+		Handler: s.ListSubscriptionDelivery})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/subscription/dead_letter",
+		Handler: s.ListSubscriptionDeadLetter})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/redeliver/subscription/dead_letter",
+		Handler: s.RedeliverSubscriptionDeadLetter})
+
 	utility.AddToRestfulWebService(web)
 
 }
@@ -0,0 +1,461 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"time"
+
+	"configcenter/pkg/event/subscription"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+)
+
+// CreateSubscription create event webhook subscription
+func (s *Service) CreateSubscription(ctx *rest.Contexts) {
+	data := new(subscription.SubscriptionData)
+	if err := ctx.DecodeInto(data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := data.Validate(false); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if authResp, authorized := s.authorizeSubscription(ctx.Kit, data.Resources); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	cond := mapstr.MapStr{common.BkSupplierAccount: ctx.Kit.SupplierAccount}
+	cnt, err := s.db.Table(subscription.BKTableNameEventSubscription).Find(cond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count event subscriptions failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if cnt >= subscription.MaxSubscriptionLimit {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommValExceedMaxFailed, "subscription count",
+			subscription.MaxSubscriptionLimit))
+		return
+	}
+
+	cond[subscription.NameField] = data.Name
+	cnt, err = s.db.Table(subscription.BKTableNameEventSubscription).Find(cond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count event subscriptions by name failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if cnt > 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, subscription.NameField))
+		return
+	}
+
+	id, err := s.db.NextSequence(ctx.Kit.Ctx, subscription.BKTableNameEventSubscription)
+	if err != nil {
+		blog.Errorf("generate event subscription id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed))
+		return
+	}
+
+	now := time.Now()
+	sub := &subscription.Subscription{
+		ID:               int64(id),
+		SubscriptionData: *data,
+		Cursors:          make(map[watch.CursorType]string),
+		SupplierAccount:  ctx.Kit.SupplierAccount,
+		Creator:          ctx.Kit.User,
+		Modifier:         ctx.Kit.User,
+		CreateTime:       now,
+		LastTime:         now,
+	}
+
+	if err = s.db.Table(subscription.BKTableNameEventSubscription).Insert(ctx.Kit.Ctx, sub); err != nil {
+		blog.Errorf("create event subscription failed, data: %+v, err: %v, rid: %s", data, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: sub.ID})
+}
+
+// UpdateSubscription update event webhook subscription
+func (s *Service) UpdateSubscription(ctx *rest.Contexts) {
+	opt := new(subscription.UpdateSubscriptionOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	sub, err := s.getSubscription(ctx.Kit, opt.ID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// both the previous and the new resources need to be authorized
+	if authResp, authorized := s.authorizeSubscription(ctx.Kit, append(sub.Resources,
+		opt.Data.Resources...)); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	if opt.Data.Name != sub.Name {
+		cond := mapstr.MapStr{
+			common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+			subscription.NameField:   opt.Data.Name,
+		}
+		cnt, err := s.db.Table(subscription.BKTableNameEventSubscription).Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count event subscriptions by name failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+
+		if cnt > 0 {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, subscription.NameField))
+			return
+		}
+	}
+
+	updateData := mapstr.MapStr{
+		subscription.NameField:        opt.Data.Name,
+		subscription.CallbackURLField: opt.Data.CallbackURL,
+		subscription.ResourcesField:   opt.Data.Resources,
+		subscription.EventTypesField:  opt.Data.EventTypes,
+		subscription.FilterField:      opt.Data.Filter,
+		subscription.BatchSizeField:   opt.Data.BatchSize,
+		subscription.MaxRetryField:    opt.Data.MaxRetry,
+		subscription.TimeoutField:     opt.Data.Timeout,
+		subscription.EnabledField:     opt.Data.Enabled,
+		common.ModifierField:          ctx.Kit.User,
+		common.LastTimeField:          time.Now(),
+	}
+	if opt.Data.Secret != "" {
+		updateData[subscription.SecretField] = opt.Data.Secret
+	}
+
+	cond := mapstr.MapStr{
+		subscription.IDField:     opt.ID,
+		common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+	}
+	err = s.db.Table(subscription.BKTableNameEventSubscription).Update(ctx.Kit.Ctx, cond, updateData)
+	if err != nil {
+		blog.Errorf("update event subscription %d failed, err: %v, rid: %s", opt.ID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteSubscription delete event webhook subscription with its delivery histories and dead letters
+func (s *Service) DeleteSubscription(ctx *rest.Contexts) {
+	opt := new(subscription.DeleteSubscriptionOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	sub, err := s.getSubscription(ctx.Kit, opt.ID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if authResp, authorized := s.authorizeSubscription(ctx.Kit, sub.Resources); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	cond := mapstr.MapStr{subscription.IDField: opt.ID}
+	if err = s.db.Table(subscription.BKTableNameEventSubscription).Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete event subscription %d failed, err: %v, rid: %s", opt.ID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	relCond := mapstr.MapStr{subscription.SubscriptionIDField: opt.ID}
+	for _, table := range []string{subscription.BKTableNameEventDelivery, subscription.BKTableNameEventDeadLetter} {
+		if err = s.db.Table(table).Delete(ctx.Kit.Ctx, relCond); err != nil {
+			blog.Errorf("delete subscription %d data in %s failed, err: %v, rid: %s", opt.ID, table, err,
+				ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+			return
+		}
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListSubscription list event webhook subscriptions, the secrets are not returned
+func (s *Service) ListSubscription(ctx *rest.Contexts) {
+	opt := new(subscription.ListSubscriptionOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := mapstr.MapStr{common.BkSupplierAccount: ctx.Kit.SupplierAccount}
+	if len(opt.IDs) > 0 {
+		cond[subscription.IDField] = mapstr.MapStr{common.BKDBIN: opt.IDs}
+	}
+
+	table := s.db.Table(subscription.BKTableNameEventSubscription)
+	if opt.Page.EnableCount {
+		cnt, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count event subscriptions failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntity(subscription.ListSubscriptionResult{Count: cnt})
+		return
+	}
+
+	subs := make([]subscription.Subscription, 0)
+	err := table.Find(cond).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).Sort(opt.Page.Sort).
+		All(ctx.Kit.Ctx, &subs)
+	if err != nil {
+		blog.Errorf("list event subscriptions failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	for idx := range subs {
+		subs[idx].Secret = ""
+	}
+
+	ctx.RespEntity(subscription.ListSubscriptionResult{Info: subs})
+}
+
+// ListSubscriptionDelivery list the delivery histories of an event webhook subscription
+func (s *Service) ListSubscriptionDelivery(ctx *rest.Contexts) {
+	opt := new(subscription.ListDeliveryOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if _, err := s.getSubscription(ctx.Kit, opt.SubscriptionID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := mapstr.MapStr{subscription.SubscriptionIDField: opt.SubscriptionID}
+	if opt.Status != "" {
+		cond[subscription.StatusField] = opt.Status
+	}
+
+	table := s.db.Table(subscription.BKTableNameEventDelivery)
+	if opt.Page.EnableCount {
+		cnt, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count event deliveries failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntity(subscription.ListDeliveryResult{Count: cnt})
+		return
+	}
+
+	if opt.Page.Sort == "" {
+		opt.Page.Sort = "-" + subscription.IDField
+	}
+
+	deliveries := make([]subscription.Delivery, 0)
+	err := table.Find(cond).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).Sort(opt.Page.Sort).
+		All(ctx.Kit.Ctx, &deliveries)
+	if err != nil {
+		blog.Errorf("list event deliveries failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(subscription.ListDeliveryResult{Info: deliveries})
+}
+
+// ListSubscriptionDeadLetter list the dead letters of an event webhook subscription
+func (s *Service) ListSubscriptionDeadLetter(ctx *rest.Contexts) {
+	opt := new(subscription.ListDeliveryOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// dead letters have no status
+	opt.Status = ""
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if _, err := s.getSubscription(ctx.Kit, opt.SubscriptionID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := mapstr.MapStr{subscription.SubscriptionIDField: opt.SubscriptionID}
+	table := s.db.Table(subscription.BKTableNameEventDeadLetter)
+	if opt.Page.EnableCount {
+		cnt, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count event dead letters failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntity(subscription.ListDeadLetterResult{Count: cnt})
+		return
+	}
+
+	if opt.Page.Sort == "" {
+		opt.Page.Sort = "-" + subscription.IDField
+	}
+
+	letters := make([]subscription.DeadLetter, 0)
+	err := table.Find(cond).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).Sort(opt.Page.Sort).
+		All(ctx.Kit.Ctx, &letters)
+	if err != nil {
+		blog.Errorf("list event dead letters failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(subscription.ListDeadLetterResult{Info: letters})
+}
+
+// RedeliverSubscriptionDeadLetter push the dead letters to the subscription again, returns the delivery results
+func (s *Service) RedeliverSubscriptionDeadLetter(ctx *rest.Contexts) {
+	opt := new(subscription.RedeliverOpt)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	sub, err := s.getSubscription(ctx.Kit, opt.SubscriptionID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if authResp, authorized := s.authorizeSubscription(ctx.Kit, sub.Resources); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	cond := mapstr.MapStr{
+		subscription.SubscriptionIDField: opt.SubscriptionID,
+		subscription.IDField:             mapstr.MapStr{common.BKDBIN: opt.IDs},
+	}
+	letters := make([]subscription.DeadLetter, 0)
+	err = s.db.Table(subscription.BKTableNameEventDeadLetter).Find(cond).All(ctx.Kit.Ctx, &letters)
+	if err != nil {
+		blog.Errorf("get event dead letters failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	deliveries := make([]subscription.Delivery, 0)
+	for idx := range letters {
+		delivery, err := s.deliverer.Redeliver(ctx.Kit, sub, &letters[idx])
+		if err != nil {
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+			return
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	ctx.RespEntity(subscription.ListDeliveryResult{Info: deliveries})
+}
+
+// getSubscription get event webhook subscription by id in the supplier account of the request
+func (s *Service) getSubscription(kit *rest.Kit, id int64) (*subscription.Subscription, error) {
+	cond := mapstr.MapStr{
+		subscription.IDField:     id,
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+
+	sub := new(subscription.Subscription)
+	err := s.db.Table(subscription.BKTableNameEventSubscription).Find(cond).One(kit.Ctx, sub)
+	if err != nil {
+		if s.db.IsNotFoundError(err) {
+			return nil, kit.CCError.CCErrorf(common.CCErrCommNotFound)
+		}
+		blog.Errorf("get event subscription %d failed, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return sub, nil
+}
+
+// authorizeSubscription authorize the watch permission of the subscribed resources
+func (s *Service) authorizeSubscription(kit *rest.Kit, resources []watch.CursorType) (*metadata.BaseResp, bool) {
+	authRes := make([]meta.ResourceAttribute, 0)
+	for _, resource := range resources {
+		switch resource {
+		case watch.HostIdentifier:
+			// redirect host identity resource to host resource in iam.
+			resource = watch.Host
+		case watch.BizSetRelation:
+			// redirect biz set relation resource to biz set resource in iam.
+			resource = watch.BizSet
+		}
+
+		authRes = append(authRes, meta.ResourceAttribute{
+			Basic: meta.Basic{
+				Type:   meta.EventWatch,
+				Action: meta.Action(resource),
+			},
+		})
+	}
+
+	return s.AuthManager.Authorize(kit, authRes...)
+}
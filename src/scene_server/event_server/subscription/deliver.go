@@ -0,0 +1,284 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/pkg/event/subscription"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal"
+)
+
+const (
+	// defaultRetryBackoff is the wait duration before the first retry, it is doubled after each retry
+	defaultRetryBackoff = time.Second
+	// maxErrorLength is the max length of the error message stored in the delivery history
+	maxErrorLength = 1024
+)
+
+// Deliverer pushes events to the subscription callback url and records the delivery results
+type Deliverer struct {
+	db      dal.RDB
+	client  *http.Client
+	backoff time.Duration
+}
+
+// NewDeliverer new event deliverer
+func NewDeliverer(db dal.RDB) *Deliverer {
+	return &Deliverer{
+		db:      db,
+		client:  &http.Client{},
+		backoff: defaultRetryBackoff,
+	}
+}
+
+// sendResult is the result of sending a webhook request with retries
+type sendResult struct {
+	attempts   int
+	statusCode int
+	err        error
+}
+
+// send posts the body to the callback url of the subscription, it retries at most sub.MaxRetry times until the
+// receiver responds with a 2xx status code
+func (d *Deliverer) send(ctx context.Context, sub *subscription.Subscription, deliveryID int64,
+	body []byte) *sendResult {
+
+	result := new(sendResult)
+	backoff := d.backoff
+	for result.attempts <= sub.MaxRetry {
+		if result.attempts > 0 {
+			select {
+			case <-ctx.Done():
+				result.err = ctx.Err()
+				return result
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		result.attempts++
+
+		result.statusCode, result.err = d.post(ctx, sub, deliveryID, body)
+		if result.err == nil {
+			return result
+		}
+	}
+
+	return result
+}
+
+// post sends one signed webhook request
+func (d *Deliverer) post(ctx context.Context, sub *subscription.Subscription, deliveryID int64, body []byte) (int,
+	error) {
+
+	timeout := time.Duration(sub.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = subscription.DefaultTimeout * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(subscription.HeaderSubscriptionID, strconv.FormatInt(sub.ID, 10))
+	req.Header.Set(subscription.HeaderDeliveryID, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(subscription.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(subscription.HeaderSignature, subscription.Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp.StatusCode, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+	return resp.StatusCode, fmt.Errorf("callback responded with status code %d, body: %s", resp.StatusCode,
+		respBody)
+}
+
+// Deliver pushes a batch of events to the subscription, the events are stored as a dead letter if they can not be
+// delivered after all retries. error is returned only when the delivery result can not be saved.
+func (d *Deliverer) Deliver(kit *rest.Kit, sub *subscription.Subscription, resource watch.CursorType,
+	events []*watch.WatchEventDetail) (*subscription.Delivery, error) {
+
+	deliveryID, err := d.db.NextSequence(kit.Ctx, subscription.BKTableNameEventDelivery)
+	if err != nil {
+		blog.Errorf("generate event delivery id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	payload := &subscription.Payload{
+		SubscriptionID: sub.ID,
+		DeliveryID:     int64(deliveryID),
+		Resource:       resource,
+		Events:         events,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		blog.Errorf("marshal event payload failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	delivery := &subscription.Delivery{
+		ID:              int64(deliveryID),
+		SubscriptionID:  sub.ID,
+		Resource:        resource,
+		EventCount:      len(events),
+		SupplierAccount: sub.SupplierAccount,
+	}
+	if len(events) > 0 {
+		delivery.StartCursor = events[0].Cursor
+		delivery.EndCursor = events[len(events)-1].Cursor
+	}
+
+	if err = d.sendAndRecord(kit, sub, delivery, body); err != nil {
+		return nil, err
+	}
+
+	if delivery.Status == subscription.DeliverySuccess {
+		return delivery, nil
+	}
+
+	letterID, err := d.db.NextSequence(kit.Ctx, subscription.BKTableNameEventDeadLetter)
+	if err != nil {
+		blog.Errorf("generate event dead letter id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	letter := &subscription.DeadLetter{
+		ID:              int64(letterID),
+		SubscriptionID:  sub.ID,
+		DeliveryID:      delivery.ID,
+		Resource:        resource,
+		Payload:         string(body),
+		Error:           delivery.Error,
+		SupplierAccount: sub.SupplierAccount,
+		CreateTime:      time.Now(),
+	}
+	if err = d.db.Table(subscription.BKTableNameEventDeadLetter).Insert(kit.Ctx, letter); err != nil {
+		blog.Errorf("save event dead letter failed, letter: %+v, err: %v, rid: %s", letter, err, kit.Rid)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver pushes the payload of a dead letter to the subscription again, the dead letter is removed if it is
+// delivered successfully.
+func (d *Deliverer) Redeliver(kit *rest.Kit, sub *subscription.Subscription, letter *subscription.DeadLetter) (
+	*subscription.Delivery, error) {
+
+	deliveryID, err := d.db.NextSequence(kit.Ctx, subscription.BKTableNameEventDelivery)
+	if err != nil {
+		blog.Errorf("generate event delivery id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	payload := new(subscription.Payload)
+	if err = json.Unmarshal([]byte(letter.Payload), payload); err != nil {
+		blog.Errorf("unmarshal dead letter %d payload failed, err: %v, rid: %s", letter.ID, err, kit.Rid)
+		return nil, err
+	}
+
+	delivery := &subscription.Delivery{
+		ID:              int64(deliveryID),
+		SubscriptionID:  sub.ID,
+		Resource:        letter.Resource,
+		EventCount:      len(payload.Events),
+		SupplierAccount: sub.SupplierAccount,
+	}
+	if len(payload.Events) > 0 {
+		delivery.StartCursor = payload.Events[0].Cursor
+		delivery.EndCursor = payload.Events[len(payload.Events)-1].Cursor
+	}
+
+	if err = d.sendAndRecord(kit, sub, delivery, []byte(letter.Payload)); err != nil {
+		return nil, err
+	}
+
+	letterCond := mapstr.MapStr{subscription.IDField: letter.ID}
+	if delivery.Status == subscription.DeliverySuccess {
+		err = d.db.Table(subscription.BKTableNameEventDeadLetter).Delete(kit.Ctx, letterCond)
+		if err != nil {
+			blog.Errorf("delete delivered dead letter %d failed, err: %v, rid: %s", letter.ID, err, kit.Rid)
+			return nil, err
+		}
+		return delivery, nil
+	}
+
+	updateData := mapstr.MapStr{
+		subscription.DeliveryIDField: delivery.ID,
+		subscription.ErrorField:      delivery.Error,
+	}
+	err = d.db.Table(subscription.BKTableNameEventDeadLetter).Update(kit.Ctx, letterCond, updateData)
+	if err != nil {
+		blog.Errorf("update dead letter %d failed, err: %v, rid: %s", letter.ID, err, kit.Rid)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// sendAndRecord sends the webhook request and saves the delivery history
+func (d *Deliverer) sendAndRecord(kit *rest.Kit, sub *subscription.Subscription, delivery *subscription.Delivery,
+	body []byte) error {
+
+	start := time.Now()
+	result := d.send(kit.Ctx, sub, delivery.ID, body)
+
+	delivery.Status = subscription.DeliverySuccess
+	delivery.Attempts = result.attempts
+	delivery.StatusCode = result.statusCode
+	delivery.Duration = time.Since(start).Milliseconds()
+	delivery.CreateTime = time.Now()
+	if result.err != nil {
+		delivery.Status = subscription.DeliveryFailed
+		delivery.Error = result.err.Error()
+		if len(delivery.Error) > maxErrorLength {
+			delivery.Error = delivery.Error[:maxErrorLength]
+		}
+		blog.Errorf("deliver events to subscription %d failed, delivery: %d, attempts: %d, err: %v, rid: %s",
+			sub.ID, delivery.ID, result.attempts, result.err, kit.Rid)
+	}
+
+	if err := d.db.Table(subscription.BKTableNameEventDelivery).Insert(kit.Ctx, delivery); err != nil {
+		blog.Errorf("save event delivery %+v failed, err: %v, rid: %s", delivery, err, kit.Rid)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package subscription
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"configcenter/pkg/event/subscription"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	attempts, failAll := 0, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(subscription.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, subscription.Sign("secret", timestamp, body), r.Header.Get(subscription.HeaderSignature))
+		require.Equal(t, "1", r.Header.Get(subscription.HeaderSubscriptionID))
+		require.Equal(t, "2", r.Header.Get(subscription.HeaderDeliveryID))
+
+		// the first request fails, the retried one succeeds
+		if attempts == 1 || failAll {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	deliverer := &Deliverer{client: server.Client(), backoff: time.Millisecond}
+	sub := &subscription.Subscription{
+		ID: 1,
+		SubscriptionData: subscription.SubscriptionData{
+			CallbackURL: server.URL,
+			Secret:      "secret",
+			MaxRetry:    1,
+			Timeout:     1,
+		},
+	}
+
+	result := deliverer.send(context.Background(), sub, 2, []byte(`{"events":[]}`))
+	require.NoError(t, result.err)
+	require.Equal(t, 2, result.attempts)
+	require.Equal(t, http.StatusOK, result.statusCode)
+
+	// all retries fail
+	failAll = true
+	result = deliverer.send(context.Background(), sub, 2, []byte(`{"events":[]}`))
+	require.Error(t, result.err)
+	require.Equal(t, 2, result.attempts)
+	require.Equal(t, http.StatusInternalServerError, result.statusCode)
+}
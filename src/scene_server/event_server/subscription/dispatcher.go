@@ -0,0 +1,141 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package subscription pushes the resource change events to the webhook subscriptions
+package subscription
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"configcenter/pkg/event/subscription"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal"
+)
+
+// reloadInterval is the interval to reload the subscriptions and adjust the running workers
+const reloadInterval = 30 * time.Second
+
+// Dispatcher runs a worker for each resource of all the enabled subscriptions, the workers only run on the master
+// event server. Events are delivered at least once, the receivers should be idempotent by the event cursor.
+type Dispatcher struct {
+	engine    *backbone.Engine
+	db        dal.RDB
+	deliverer *Deliverer
+
+	lock    sync.Mutex
+	workers map[workerKey]*worker
+}
+
+type workerKey struct {
+	id       int64
+	resource watch.CursorType
+}
+
+// NewDispatcher new subscription event dispatcher
+func NewDispatcher(engine *backbone.Engine, db dal.RDB) *Dispatcher {
+	return &Dispatcher{
+		engine:    engine,
+		db:        db,
+		deliverer: NewDeliverer(db),
+		workers:   make(map[workerKey]*worker),
+	}
+}
+
+// Deliverer returns the event deliverer of the dispatcher
+func (d *Dispatcher) Deliverer() *Deliverer {
+	return d.deliverer
+}
+
+// Run loops to reload the subscriptions until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		if d.engine.Discovery().IsMaster() {
+			if err := d.reload(ctx); err != nil {
+				blog.Errorf("reload event subscriptions failed, err: %v", err)
+			}
+		} else {
+			blog.V(4).Infof("loop dispatch subscription events, but not master, skip.")
+			d.stopAll()
+		}
+
+		select {
+		case <-ctx.Done():
+			d.stopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reload starts the workers of the new subscriptions, and restarts the workers of the changed subscriptions
+func (d *Dispatcher) reload(ctx context.Context) error {
+	subs := make([]subscription.Subscription, 0)
+	cond := mapstr.MapStr{subscription.EnabledField: true}
+	if err := d.db.Table(subscription.BKTableNameEventSubscription).Find(cond).All(ctx, &subs); err != nil {
+		return err
+	}
+
+	expected := make(map[workerKey]*subscription.Subscription)
+	for idx := range subs {
+		sub := &subs[idx]
+		for _, resource := range sub.Resources {
+			expected[workerKey{id: sub.ID, resource: resource}] = sub
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for key, w := range d.workers {
+		sub, exists := expected[key]
+		if exists && sub.LastTime.Equal(w.sub.LastTime) {
+			continue
+		}
+		w.stop()
+		delete(d.workers, key)
+	}
+
+	for key, sub := range expected {
+		if _, exists := d.workers[key]; exists {
+			continue
+		}
+
+		w := newWorker(d.engine, d.db, d.deliverer, sub, key.resource)
+		d.workers[key] = w
+		w.start(ctx)
+		blog.Infof("start event subscription %d worker for resource %s", sub.ID, key.resource)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) stopAll() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for key, w := range d.workers {
+		w.stop()
+		delete(d.workers, key)
+	}
+}
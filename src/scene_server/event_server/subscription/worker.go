@@ -0,0 +1,167 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"configcenter/pkg/event/subscription"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal"
+)
+
+// worker watches the events of one resource and delivers them to the subscription
+type worker struct {
+	engine    *backbone.Engine
+	db        dal.RDB
+	deliverer *Deliverer
+	sub       *subscription.Subscription
+	resource  watch.CursorType
+	cursor    string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newWorker(engine *backbone.Engine, db dal.RDB, deliverer *Deliverer, sub *subscription.Subscription,
+	resource watch.CursorType) *worker {
+
+	return &worker{
+		engine:    engine,
+		db:        db,
+		deliverer: deliverer,
+		sub:       sub,
+		resource:  resource,
+		cursor:    sub.Cursors[resource],
+		done:      make(chan struct{}),
+	}
+}
+
+// start runs the worker in background
+func (w *worker) start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.run(ctx)
+}
+
+// run watches and delivers the events until the worker is stopped
+func (w *worker) run(ctx context.Context) {
+	defer close(w.done)
+
+	for ctx.Err() == nil {
+		header := headerutil.GenCommonHeader(common.CCSystemOperatorUserName, w.sub.SupplierAccount,
+			util.GenerateRID())
+		kit := rest.NewKitFromHeader(header, w.engine.CCErr)
+		kit.Ctx = util.SetContextValueByHTTPHeader(ctx, header)
+
+		if err := w.watchAndDeliver(kit); err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// stop cancels the worker and waits until it exits
+func (w *worker) stop() {
+	w.cancel()
+	<-w.done
+}
+
+func (w *worker) watchAndDeliver(kit *rest.Kit) error {
+	opts := &watch.WatchEventOptions{
+		EventTypes: w.sub.EventTypes,
+		Resource:   w.resource,
+		Cursor:     w.cursor,
+	}
+	if w.cursor == "" {
+		opts.StartFrom = time.Now().Unix()
+	}
+
+	resp, ccErr := w.engine.CoreAPI.CacheService().Cache().Event().InnerWatchEvent(kit.Ctx, kit.Header, opts)
+	if ccErr != nil {
+		if ccErr.GetCode() == common.CCErrEventChainNodeNotExist {
+			// the events after the cursor are expired, they can not be delivered anymore
+			blog.Errorf("subscription %d %s cursor %s expired, reset to watch from now, rid: %s", w.sub.ID,
+				w.resource, w.cursor, kit.Rid)
+			w.saveCursor(kit, "")
+			return ccErr
+		}
+		blog.Errorf("watch subscription %d %s events failed, err: %v, rid: %s", w.sub.ID, w.resource, ccErr,
+			kit.Rid)
+		return ccErr
+	}
+
+	if len(resp.Events) == 0 {
+		return nil
+	}
+
+	if !resp.Watched {
+		// no event is watched, only the latest cursor is returned
+		w.saveCursor(kit, resp.Events[0].Cursor)
+		return nil
+	}
+
+	batch := make([]*watch.WatchEventDetail, 0)
+	for idx, event := range resp.Events {
+		matched, err := w.sub.Match(event)
+		if err != nil {
+			blog.Errorf("match subscription %d event %s failed, skip it, err: %v, rid: %s", w.sub.ID,
+				event.Cursor, err, kit.Rid)
+		}
+		if matched {
+			batch = append(batch, event)
+		}
+
+		if len(batch) < w.sub.BatchSize && idx < len(resp.Events)-1 {
+			continue
+		}
+
+		if len(batch) > 0 {
+			if _, err = w.deliverer.Deliver(kit, w.sub, w.resource, batch); err != nil {
+				return err
+			}
+			batch = make([]*watch.WatchEventDetail, 0)
+		}
+		w.saveCursor(kit, event.Cursor)
+	}
+
+	return nil
+}
+
+// saveCursor persists the cursor of the last handled event, so that the worker can continue from it after restart
+func (w *worker) saveCursor(kit *rest.Kit, cursor string) {
+	if cursor == w.cursor {
+		return
+	}
+	w.cursor = cursor
+
+	cond := mapstr.MapStr{subscription.IDField: w.sub.ID}
+	data := mapstr.MapStr{subscription.CursorsField + "." + string(w.resource): cursor}
+	if err := w.db.Table(subscription.BKTableNameEventSubscription).Update(kit.Ctx, cond, data); err != nil {
+		blog.Errorf("save subscription %d %s cursor %s failed, err: %v, rid: %s", w.sub.ID, w.resource, cursor,
+			err, kit.Rid)
+	}
+}
@@ -51,4 +51,22 @@ type Config struct {
 	Redis redis.Config
 	// Auth is auth config
 	Auth iam.AuthConfig
+	// DynamicGroupExport is dynamic group export file retention config
+	DynamicGroupExport DynamicGroupExportConfig
+	// HostApplyReconcile is host apply rule reconcile config
+	HostApplyReconcile HostApplyReconcileConfig
+}
+
+// DynamicGroupExportConfig dynamic group export file retention config
+type DynamicGroupExportConfig struct {
+	// RetentionDays is the days that exported files are kept, default is 7
+	RetentionDays int
+	// MaxFilesPerGroup is the max count of exported files kept for each dynamic group, 0 means unlimited
+	MaxFilesPerGroup int
+}
+
+// HostApplyReconcileConfig host apply rule reconcile config
+type HostApplyReconcileConfig struct {
+	// IntervalMinutes is the interval of reconciling the hosts of the enabled businesses, default is 60
+	IntervalMinutes int
 }
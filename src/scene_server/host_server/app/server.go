@@ -22,10 +22,10 @@ import (
 	"fmt"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
@@ -52,6 +52,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		ConfigUpdate: hostSrv.onHostConfigUpdate,
 		SrvInfo:      svrInfo,
 	}
@@ -92,7 +93,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	}
 
 	iamCli := new(iam.IAM)
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		blog.Info("enable auth center access")
 		iamCli, err = iam.NewIAM(hostSrv.Config.Auth, engine.Metric().Registry())
 		if err != nil {
@@ -111,6 +112,9 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	hostSrv.Core = engine
 	hostSrv.Service = service
 
+	go service.TimerExportDynamicGroup(ctx)
+	go service.TimerReconcileHostApply(ctx)
+
 	err = backbone.StartServer(ctx, cancel, engine, service.WebService(), true)
 	if err != nil {
 		blog.Errorf("start backbone failed, err: %+v", err)
@@ -139,4 +143,14 @@ func (h *HostServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 	if h.Config == nil {
 		h.Config = new(options.Config)
 	}
+	h.Config.DynamicGroupExport.RetentionDays, _ = cc.Int("hostServer.dynamicGroupExport.retentionDays")
+	if h.Config.DynamicGroupExport.RetentionDays <= 0 {
+		h.Config.DynamicGroupExport.RetentionDays = 7
+	}
+	h.Config.DynamicGroupExport.MaxFilesPerGroup, _ = cc.Int("hostServer.dynamicGroupExport.maxFilesPerGroup")
+
+	h.Config.HostApplyReconcile.IntervalMinutes, _ = cc.Int("hostServer.hostApplyReconcile.intervalMinutes")
+	if h.Config.HostApplyReconcile.IntervalMinutes <= 0 {
+		h.Config.HostApplyReconcile.IntervalMinutes = 60
+	}
 }
@@ -0,0 +1,242 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"configcenter/pkg/excel"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+const dynamicGroupExportSheet = "data"
+
+// DynamicGroupExportWriter writes the dynamic group execution result into the export file
+type DynamicGroupExportWriter interface {
+	// WriteRows write the dynamic group execution result rows
+	WriteRows(rows []mapstr.MapStr) error
+	// Close flush the written rows into the file and close it
+	Close() error
+}
+
+// NewDynamicGroupExportWriter create the export file of the format and write the header into it, names are the
+// display names of the fields that are used as the header of the csv and xlsx file
+func NewDynamicGroupExportWriter(format metadata.DynamicGroupExportFormat, filePath string, fields []string,
+	names map[string]string) (DynamicGroupExportWriter, error) {
+
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModeDir|os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	header := make([]string, len(fields))
+	for idx, field := range fields {
+		header[idx] = field
+		if name := names[field]; len(name) > 0 {
+			header[idx] = name
+		}
+	}
+
+	switch format {
+	case metadata.DynamicGroupExportCSV:
+		return newCsvExportWriter(filePath, fields, header)
+	case metadata.DynamicGroupExportJSONLines:
+		return newJSONLinesExportWriter(filePath, fields)
+	case metadata.DynamicGroupExportXLSX:
+		return newXlsxExportWriter(filePath, fields, header)
+	default:
+		return nil, fmt.Errorf("dynamic group export format %s is invalid", format)
+	}
+}
+
+type csvExportWriter struct {
+	file   *os.File
+	writer *csv.Writer
+	fields []string
+}
+
+func newCsvExportWriter(filePath string, fields, header []string) (*csvExportWriter, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &csvExportWriter{file: file, writer: csv.NewWriter(file), fields: fields}
+	if err = w.writer.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteRows write rows as csv records
+func (w *csvExportWriter) WriteRows(rows []mapstr.MapStr) error {
+	for _, row := range rows {
+		record := make([]string, len(w.fields))
+		for idx, field := range w.fields {
+			record[idx] = FormatExportValue(row[field])
+		}
+
+		if err := w.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flush csv records and close the file
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+type jsonLinesExportWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+	fields []string
+}
+
+func newJSONLinesExportWriter(filePath string, fields []string) (*jsonLinesExportWriter, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonLinesExportWriter{file: file, writer: bufio.NewWriter(file), fields: fields}, nil
+}
+
+// WriteRows write rows as json objects, one object per line
+func (w *jsonLinesExportWriter) WriteRows(rows []mapstr.MapStr) error {
+	for _, row := range rows {
+		data := make(map[string]interface{}, len(w.fields))
+		for _, field := range w.fields {
+			data[field] = row[field]
+		}
+
+		line, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		if _, err = w.writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flush json lines and close the file
+func (w *jsonLinesExportWriter) Close() error {
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+type xlsxExportWriter struct {
+	excel  *excel.Excel
+	fields []string
+	rowIdx int
+}
+
+func newXlsxExportWriter(filePath string, fields, header []string) (*xlsxExportWriter, error) {
+	file, err := excel.NewExcel(excel.FilePath(filePath), excel.OpenOrCreate())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = file.CreateSheet(dynamicGroupExportSheet); err != nil {
+		return nil, err
+	}
+
+	w := &xlsxExportWriter{excel: file, fields: fields}
+	headerRow := make([]excel.Cell, len(header))
+	for idx, name := range header {
+		headerRow[idx] = excel.Cell{Value: name}
+	}
+
+	if err = w.excel.StreamingWrite(dynamicGroupExportSheet, w.rowIdx, [][]excel.Cell{headerRow}); err != nil {
+		return nil, err
+	}
+	w.rowIdx++
+	return w, nil
+}
+
+// WriteRows write rows into the excel sheet
+func (w *xlsxExportWriter) WriteRows(rows []mapstr.MapStr) error {
+	data := make([][]excel.Cell, len(rows))
+	for rowIdx, row := range rows {
+		data[rowIdx] = make([]excel.Cell, len(w.fields))
+		for idx, field := range w.fields {
+			data[rowIdx][idx] = excel.Cell{Value: FormatExportValue(row[field])}
+		}
+	}
+
+	if err := w.excel.StreamingWrite(dynamicGroupExportSheet, w.rowIdx, data); err != nil {
+		return err
+	}
+	w.rowIdx += len(rows)
+	return nil
+}
+
+// Close flush the excel sheet and save the file
+func (w *xlsxExportWriter) Close() error {
+	if err := w.excel.Flush([]string{dynamicGroupExportSheet}); err != nil {
+		return err
+	}
+	return w.excel.Close()
+}
+
+// FormatExportValue format the field value to the text of the csv and xlsx cell
+func FormatExportValue(val interface{}) string {
+	switch value := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case []string:
+		return strings.Join(value, ",")
+	case []interface{}:
+		items := make([]string, len(value))
+		for idx, item := range value {
+			items[idx] = FormatExportValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}, mapstr.MapStr, []map[string]interface{}, []mapstr.MapStr:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
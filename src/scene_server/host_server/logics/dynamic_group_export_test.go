@@ -0,0 +1,114 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func TestFormatExportValue(t *testing.T) {
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		val    interface{}
+		expect string
+	}{
+		{val: nil, expect: ""},
+		{val: "host", expect: "host"},
+		{val: int64(10), expect: "10"},
+		{val: 1.5, expect: "1.5"},
+		{val: true, expect: "true"},
+		{val: testTime, expect: "2024-01-02T03:04:05Z"},
+		{val: []string{"127.0.0.1", "127.0.0.2"}, expect: "127.0.0.1,127.0.0.2"},
+		{val: []interface{}{"a", int64(1)}, expect: "a,1"},
+		{val: map[string]interface{}{"a": 1}, expect: `{"a":1}`},
+	}
+
+	for _, c := range cases {
+		if actual := FormatExportValue(c.val); actual != c.expect {
+			t.Errorf("format %#v, expect %s, actual %s", c.val, c.expect, actual)
+		}
+	}
+}
+
+func TestDynamicGroupExportWriter(t *testing.T) {
+	dir := t.TempDir()
+	fields := []string{"bk_host_id", "bk_host_innerip"}
+	names := map[string]string{"bk_host_innerip": "IP"}
+	rows := []mapstr.MapStr{
+		{"bk_host_id": int64(1), "bk_host_innerip": "127.0.0.1", "bk_cloud_id": int64(0)},
+		{"bk_host_id": int64(2), "bk_host_innerip": "127.0.0.2,127.0.0.3"},
+	}
+
+	expects := map[metadata.DynamicGroupExportFormat]string{
+		metadata.DynamicGroupExportCSV: "bk_host_id,IP\n1,127.0.0.1\n2,\"127.0.0.2,127.0.0.3\"\n",
+		metadata.DynamicGroupExportJSONLines: "{\"bk_host_id\":1,\"bk_host_innerip\":\"127.0.0.1\"}\n" +
+			"{\"bk_host_id\":2,\"bk_host_innerip\":\"127.0.0.2,127.0.0.3\"}\n",
+	}
+
+	for format, expect := range expects {
+		filePath := filepath.Join(dir, "export."+string(format))
+		writer, err := NewDynamicGroupExportWriter(format, filePath, fields, names)
+		if err != nil {
+			t.Fatalf("create %s writer failed, err: %v", format, err)
+		}
+
+		if err = writer.WriteRows(rows); err != nil {
+			t.Fatalf("write %s rows failed, err: %v", format, err)
+		}
+
+		if err = writer.Close(); err != nil {
+			t.Fatalf("close %s writer failed, err: %v", format, err)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("read %s file failed, err: %v", format, err)
+		}
+
+		if string(data) != expect {
+			t.Errorf("%s file content is not as expected, expect: %q, actual: %q", format, expect, string(data))
+		}
+	}
+
+	xlsxPath := filepath.Join(dir, "export.xlsx")
+	writer, err := NewDynamicGroupExportWriter(metadata.DynamicGroupExportXLSX, xlsxPath, fields, names)
+	if err != nil {
+		t.Fatalf("create xlsx writer failed, err: %v", err)
+	}
+
+	if err = writer.WriteRows(rows); err != nil {
+		t.Fatalf("write xlsx rows failed, err: %v", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatalf("close xlsx writer failed, err: %v", err)
+	}
+
+	if info, err := os.Stat(xlsxPath); err != nil || info.Size() == 0 {
+		t.Errorf("xlsx file is not generated, err: %v", err)
+	}
+
+	if _, err := NewDynamicGroupExportWriter("xml", filepath.Join(dir, "export.xml"), fields, names); err == nil {
+		t.Errorf("create writer with invalid format should fail")
+	}
+}
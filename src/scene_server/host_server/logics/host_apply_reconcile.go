@@ -0,0 +1,169 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"encoding/json"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+type hostApplyRuleKey struct {
+	moduleID          int64
+	serviceTemplateID int64
+	attributeID       int64
+}
+
+// OverlayHostApplyRules applies the unsaved rule changes on the saved rules, the additional rules replace the value
+// of the saved rule with the same module or service template and attribute, the removed rules are dropped.
+func OverlayHostApplyRules(bizID int64, rules []metadata.HostApplyRule,
+	additionalRules []metadata.CreateHostApplyRuleOption, removeRuleIDs []int64) []metadata.HostApplyRule {
+
+	ruleIndex := make(map[hostApplyRuleKey]int)
+	result := make([]metadata.HostApplyRule, 0, len(rules)+len(additionalRules))
+	for _, rule := range rules {
+		if util.InArray(rule.ID, removeRuleIDs) {
+			continue
+		}
+		ruleIndex[hostApplyRuleKey{rule.ModuleID, rule.ServiceTemplateID, rule.AttributeID}] = len(result)
+		result = append(result, rule)
+	}
+
+	for _, item := range additionalRules {
+		key := hostApplyRuleKey{item.ModuleID, item.ServiceTemplateID, item.AttributeID}
+		if idx, exists := ruleIndex[key]; exists {
+			result[idx].PropertyValue = item.PropertyValue
+			continue
+		}
+
+		ruleIndex[key] = len(result)
+		result = append(result, metadata.HostApplyRule{BizID: bizID, ModuleID: item.ModuleID,
+			ServiceTemplateID: item.ServiceTemplateID, AttributeID: item.AttributeID,
+			PropertyValue: item.PropertyValue})
+	}
+
+	return result
+}
+
+// HostApplyFinalRules returns the rules that take effect on each module, the rules of the module's service template
+// take effect if host apply is enabled on the template, otherwise the module's own rules take effect if host apply
+// is enabled on the module. The service template rules are copied with the module id set.
+func HostApplyFinalRules(modules []metadata.ModuleInst, templateEnabled map[int64]bool,
+	rules []metadata.HostApplyRule) []metadata.HostApplyRule {
+
+	moduleRules := make(map[int64][]metadata.HostApplyRule)
+	templateRules := make(map[int64][]metadata.HostApplyRule)
+	for _, rule := range rules {
+		if rule.ServiceTemplateID != 0 {
+			templateRules[rule.ServiceTemplateID] = append(templateRules[rule.ServiceTemplateID], rule)
+			continue
+		}
+		moduleRules[rule.ModuleID] = append(moduleRules[rule.ModuleID], rule)
+	}
+
+	finalRules := make([]metadata.HostApplyRule, 0)
+	for _, module := range modules {
+		if module.ServiceTemplateID != 0 && templateEnabled[module.ServiceTemplateID] {
+			for _, rule := range templateRules[module.ServiceTemplateID] {
+				rule.ModuleID = module.ModuleID
+				finalRules = append(finalRules, rule)
+			}
+			continue
+		}
+
+		if module.HostApplyEnabled {
+			finalRules = append(finalRules, moduleRules[module.ModuleID]...)
+		}
+	}
+
+	return finalRules
+}
+
+// HostApplyConflicts converts the apply plans of the drifted hosts to the conflicts to record, the fields whose rules
+// of the host's modules disagree with each other are marked as ambiguous.
+func HostApplyConflicts(bizID int64, plans []metadata.OneHostApplyPlan, now time.Time) []metadata.HostApplyConflict {
+	conflicts := make([]metadata.HostApplyConflict, 0)
+	for _, plan := range plans {
+		if len(plan.ConflictFields) == 0 {
+			continue
+		}
+
+		expectValues := make(map[int64]interface{})
+		for _, field := range plan.UpdateFields {
+			expectValues[field.AttributeID] = field.PropertyValue
+		}
+
+		conflict := metadata.HostApplyConflict{
+			BizID:      bizID,
+			HostID:     plan.HostID,
+			ModuleIDs:  plan.ModuleIDs,
+			Fields:     make([]metadata.HostApplyConflictDrift, 0, len(plan.ConflictFields)),
+			ErrMsg:     plan.ErrMsg,
+			DetectTime: now,
+		}
+
+		for _, field := range plan.ConflictFields {
+			drift := metadata.HostApplyConflictDrift{
+				AttributeID:   field.AttributeID,
+				PropertyID:    field.PropertyID,
+				PropertyValue: field.PropertyValue,
+				Ambiguous:     isHostApplyRulesAmbiguous(field.Rules),
+			}
+
+			if !drift.Ambiguous {
+				drift.ExpectValue = expectValues[field.AttributeID]
+				if drift.ExpectValue == nil && len(field.Rules) > 0 {
+					drift.ExpectValue = field.Rules[0].PropertyValue
+				}
+			}
+			conflict.Fields = append(conflict.Fields, drift)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts
+}
+
+// HostApplyCorrectData returns the data to correct the drifted host, the ambiguous fields are not corrected
+func HostApplyCorrectData(conflict *metadata.HostApplyConflict) map[string]interface{} {
+	data := make(map[string]interface{})
+	for _, field := range conflict.Fields {
+		if field.Ambiguous || field.ExpectValue == nil {
+			continue
+		}
+		data[field.PropertyID] = field.ExpectValue
+	}
+	return data
+}
+
+// isHostApplyRulesAmbiguous returns if the rules of the same attribute have different values
+func isHostApplyRulesAmbiguous(rules []metadata.HostApplyRule) bool {
+	if len(rules) < 2 {
+		return false
+	}
+
+	first, _ := json.Marshal(rules[0].PropertyValue)
+	for _, rule := range rules[1:] {
+		value, _ := json.Marshal(rule.PropertyValue)
+		if string(value) != string(first) {
+			return true
+		}
+	}
+	return false
+}
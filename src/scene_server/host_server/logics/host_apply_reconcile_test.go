@@ -0,0 +1,118 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package logics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestOverlayHostApplyRules(t *testing.T) {
+	rules := []metadata.HostApplyRule{
+		{ID: 1, BizID: 2, ModuleID: 10, AttributeID: 100, PropertyValue: "a"},
+		{ID: 2, BizID: 2, ServiceTemplateID: 20, AttributeID: 100, PropertyValue: "b"},
+		{ID: 3, BizID: 2, ModuleID: 11, AttributeID: 101, PropertyValue: "c"},
+	}
+	additional := []metadata.CreateHostApplyRuleOption{
+		{ModuleID: 10, AttributeID: 100, PropertyValue: "x"},
+		{ServiceTemplateID: 20, AttributeID: 101, PropertyValue: "y"},
+	}
+
+	result := OverlayHostApplyRules(2, rules, additional, []int64{3})
+	expect := []metadata.HostApplyRule{
+		{ID: 1, BizID: 2, ModuleID: 10, AttributeID: 100, PropertyValue: "x"},
+		{ID: 2, BizID: 2, ServiceTemplateID: 20, AttributeID: 100, PropertyValue: "b"},
+		{BizID: 2, ServiceTemplateID: 20, AttributeID: 101, PropertyValue: "y"},
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("overlay rules is not as expected, expect: %+v, actual: %+v", expect, result)
+	}
+
+	if rules[0].PropertyValue != "a" {
+		t.Errorf("saved rules should not be changed, actual: %v", rules[0].PropertyValue)
+	}
+}
+
+func TestHostApplyFinalRules(t *testing.T) {
+	rules := []metadata.HostApplyRule{
+		{ID: 1, ModuleID: 10, AttributeID: 100, PropertyValue: "module"},
+		{ID: 2, ServiceTemplateID: 20, AttributeID: 100, PropertyValue: "template"},
+		{ID: 3, ModuleID: 12, AttributeID: 100, PropertyValue: "disabled"},
+	}
+	modules := []metadata.ModuleInst{
+		{ModuleID: 10, HostApplyEnabled: true},
+		{ModuleID: 11, ServiceTemplateID: 20},
+		{ModuleID: 12},
+	}
+
+	result := HostApplyFinalRules(modules, map[int64]bool{20: true}, rules)
+	expect := []metadata.HostApplyRule{
+		{ID: 1, ModuleID: 10, AttributeID: 100, PropertyValue: "module"},
+		{ID: 2, ModuleID: 11, ServiceTemplateID: 20, AttributeID: 100, PropertyValue: "template"},
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("final rules is not as expected, expect: %+v, actual: %+v", expect, result)
+	}
+}
+
+func TestHostApplyConflicts(t *testing.T) {
+	now := time.Date(2025, 10, 27, 12, 0, 0, 0, time.UTC)
+	plans := []metadata.OneHostApplyPlan{
+		{HostID: 1, ModuleIDs: []int64{10}},
+		{
+			HostID:    2,
+			ModuleIDs: []int64{10, 11},
+			UpdateFields: []metadata.HostApplyUpdateField{
+				{AttributeID: 100, PropertyID: "operator", PropertyValue: "admin"},
+				{AttributeID: 101, PropertyID: "bk_comment", PropertyValue: "x"},
+			},
+			ConflictFields: []metadata.HostApplyConflictField{
+				{AttributeID: 100, PropertyID: "operator", PropertyValue: "user",
+					Rules: []metadata.HostApplyRule{{ModuleID: 10, PropertyValue: "admin"}}},
+				{AttributeID: 101, PropertyID: "bk_comment", PropertyValue: "",
+					Rules: []metadata.HostApplyRule{{ModuleID: 10, PropertyValue: "x"}, {ModuleID: 11, PropertyValue: "y"}}},
+			},
+		},
+	}
+
+	conflicts := HostApplyConflicts(5, plans, now)
+	if len(conflicts) != 1 {
+		t.Fatalf("conflict count should be 1, actual: %d", len(conflicts))
+	}
+
+	expect := metadata.HostApplyConflict{
+		BizID:     5,
+		HostID:    2,
+		ModuleIDs: []int64{10, 11},
+		Fields: []metadata.HostApplyConflictDrift{
+			{AttributeID: 100, PropertyID: "operator", PropertyValue: "user", ExpectValue: "admin"},
+			{AttributeID: 101, PropertyID: "bk_comment", PropertyValue: "", Ambiguous: true},
+		},
+		DetectTime: now,
+	}
+	if !reflect.DeepEqual(conflicts[0], expect) {
+		t.Errorf("conflict is not as expected, expect: %+v, actual: %+v", expect, conflicts[0])
+	}
+
+	data := HostApplyCorrectData(&conflicts[0])
+	if !reflect.DeepEqual(data, map[string]interface{}{"operator": "admin"}) {
+		t.Errorf("correct data is not as expected, actual: %+v", data)
+	}
+}
@@ -371,7 +371,8 @@ func init() {
 		comparableOpMap[op] = struct{}{}
 	}
 
-	numericAttrTypes := []string{common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeDate, common.FieldTypeTime}
+	numericAttrTypes := []string{common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeDate, common.FieldTypeTime,
+		common.FieldTypeComputed}
 	for _, attrType := range numericAttrTypes {
 		attrTypeSupportedOpMap[attrType] = comparableOpMap
 	}
@@ -379,7 +380,7 @@ func init() {
 
 func (sh *searchHost) validCondValueType(attrType string, value interface{}) error {
 	switch attrType {
-	case common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeOrganization:
+	case common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeOrganization, common.FieldTypeComputed:
 		if !util.IsNumeric(value) {
 			return fmt.Errorf("%s attribute type only support numeric value", attrType)
 		}
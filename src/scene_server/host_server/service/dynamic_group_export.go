@@ -0,0 +1,743 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/host_server/logics"
+)
+
+// ExportDynamicGroup creates an async task to export the execution result of target dynamic group into file.
+func (s *Service) ExportDynamicGroup(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+	groupID := ctx.Request.PathParameter(common.BKFieldID)
+
+	spec := new(meta.DynamicGroupExportSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := spec.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.createDynamicGroupExport(ctx.Kit, bizID, groupID, 0, spec)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// createDynamicGroupExport creates the export file record and the async task that generates the file.
+func (s *Service) createDynamicGroupExport(kit *rest.Kit, bizID int64, groupID string, scheduleID int64,
+	spec *meta.DynamicGroupExportSpec) (*meta.DynamicGroupExportResult, error) {
+
+	groupRes, err := s.CoreAPI.CoreService().Host().GetDynamicGroup(kit.Ctx, strconv.FormatInt(bizID, 10), groupID,
+		kit.Header)
+	if err != nil {
+		blog.Errorf("get dynamic group failed, err: %v, bizID: %d, ID: %s, rid: %s", err, bizID, groupID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !groupRes.Result {
+		blog.Errorf("get dynamic group failed, errcode: %d, errmsg: %s, bizID: %d, ID: %s, rid: %s", groupRes.Code,
+			groupRes.ErrMsg, bizID, groupID, kit.Rid)
+		return nil, groupRes.CCError()
+	}
+	if len(groupRes.Data.Name) == 0 {
+		blog.Errorf("dynamic group not found, bizID: %d, ID: %s, rid: %s", bizID, groupID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	result := new(meta.DynamicGroupExportResult)
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(kit.Ctx, kit.Header, func() error {
+		file := &meta.DynamicGroupExportFile{
+			AppID:                  bizID,
+			GroupID:                groupID,
+			ScheduleID:             scheduleID,
+			DynamicGroupExportSpec: *spec,
+			Status:                 meta.DynamicGroupExportWaiting,
+			FileName: fmt.Sprintf("%s_%s.%s", groupRes.Data.Name, time.Now().Format("20060102150405"),
+				spec.Format),
+		}
+		fileRes, err := s.CoreAPI.CoreService().DynamicGroupExport().CreateExportFile(kit.Ctx, kit.Header, file)
+		if err != nil {
+			blog.Errorf("create dynamic group export file failed, file: %+v, err: %v, rid: %s", file, err, kit.Rid)
+			return err
+		}
+
+		taskOpt := meta.DynamicGroupExportTaskOption{AppID: bizID, FileID: fileRes.ID}
+		task, err := s.CoreAPI.TaskServer().Task().Create(kit.Ctx, kit.Header, common.DynamicGroupExportTaskFlag,
+			fileRes.ID, []interface{}{taskOpt})
+		if err != nil {
+			blog.Errorf("create dynamic group export task failed, opt: %+v, err: %v, rid: %s", taskOpt, err, kit.Rid)
+			return err
+		}
+
+		updateOpt := &meta.UpdateOption{
+			Condition: mapstr.MapStr{common.BKFieldID: fileRes.ID},
+			Data:      mapstr.MapStr{common.BKTaskIDField: task.TaskID},
+		}
+		if err = s.CoreAPI.CoreService().DynamicGroupExport().UpdateExportFile(kit.Ctx, kit.Header,
+			updateOpt); err != nil {
+			blog.Errorf("update dynamic group export file task id failed, opt: %+v, err: %v, rid: %s", updateOpt,
+				err, kit.Rid)
+			return err
+		}
+
+		result.FileID = fileRes.ID
+		result.TaskID = task.TaskID
+		return nil
+	})
+	if txnErr != nil {
+		return nil, txnErr
+	}
+
+	return result, nil
+}
+
+// DynamicGroupExportTask executes the dynamic group export task, writes the execution result into the export file.
+func (s *Service) DynamicGroupExportTask(ctx *rest.Contexts) {
+	opt := new(meta.DynamicGroupExportTaskOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	listOpt := &meta.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppIDField: opt.AppID, common.BKFieldID: opt.FileID},
+		Page:      meta.BasePage{Limit: 1},
+	}
+	files, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportFile(ctx.Kit.Ctx, ctx.Kit.Header, listOpt)
+	if err != nil {
+		blog.Errorf("list dynamic group export file failed, opt: %+v, err: %v, rid: %s", opt, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(files.Info) == 0 {
+		blog.Errorf("dynamic group export file %d is not exist, rid: %s", opt.FileID, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	file := &files.Info[0]
+	if file.Status == meta.DynamicGroupExportFinished {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	executing := mapstr.MapStr{"status": meta.DynamicGroupExportExecuting}
+	if err := s.updateExportFile(ctx.Kit, file.ID, executing); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	data, genErr := s.generateExportFile(ctx.Kit, file)
+	if genErr != nil {
+		blog.Errorf("generate dynamic group export file %d failed, err: %v, rid: %s", file.ID, genErr, ctx.Kit.Rid)
+		failedData := mapstr.MapStr{"status": meta.DynamicGroupExportFailed, "err_msg": genErr.Error()}
+		if updateErr := s.updateExportFile(ctx.Kit, file.ID, failedData); updateErr != nil {
+			blog.Errorf("update dynamic group export file %d failed, err: %v, rid: %s", file.ID, updateErr,
+				ctx.Kit.Rid)
+		}
+		ctx.RespAutoError(genErr)
+		return
+	}
+
+	data["status"] = meta.DynamicGroupExportFinished
+	if err := s.updateExportFile(ctx.Kit, file.ID, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	s.trimDynamicGroupExportFiles(ctx.Kit, file.AppID, file.GroupID)
+	ctx.RespEntity(nil)
+}
+
+func (s *Service) updateExportFile(kit *rest.Kit, id int64, data mapstr.MapStr) error {
+	opt := &meta.UpdateOption{Condition: mapstr.MapStr{common.BKFieldID: id}, Data: data}
+	if err := s.CoreAPI.CoreService().DynamicGroupExport().UpdateExportFile(kit.Ctx, kit.Header, opt); err != nil {
+		blog.Errorf("update dynamic group export file failed, opt: %+v, err: %v, rid: %s", opt, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// generateExportFile executes the dynamic group page by page and writes the result into a local temporary file,
+// then saves the file content as chunks, returns the file statistics that needs to be updated to the file record.
+func (s *Service) generateExportFile(kit *rest.Kit, file *meta.DynamicGroupExportFile) (mapstr.MapStr, error) {
+	input := &meta.ExecuteOption{
+		Fields:         file.Fields,
+		Page:           meta.BasePage{Limit: common.BKMaxLimitSize},
+		DisableCounter: true,
+	}
+	groupRes, conds, err := s.checkAndBuildParam(kit, input, file.AppID, file.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	objID := groupRes.Data.ObjID
+
+	attrs, err := s.Logic.SearchObjectAttributes(kit, file.AppID, objID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	for _, attr := range attrs {
+		names[attr.PropertyID] = attr.PropertyName
+	}
+
+	filePath := filepath.Join(os.TempDir(), "cc_dynamic_group_export", fmt.Sprintf("%d.%s", file.ID, file.Format))
+	defer os.Remove(filePath)
+
+	writer, err := logics.NewDynamicGroupExportWriter(file.Format, filePath, file.Fields, names)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int64(0)
+	for {
+		var rows []mapstr.MapStr
+		switch objID {
+		case common.BKInnerObjIDHost:
+			cond := &meta.HostCommonSearch{AppID: file.AppID, Condition: conds, Page: input.Page}
+			data, err := s.Logic.ExecuteHostDynamicGroup(kit, cond, input.Fields, input.DisableCounter)
+			if err != nil {
+				writer.Close()
+				return nil, err
+			}
+			rows = data.Info
+		case common.BKInnerObjIDSet:
+			cond := &meta.SetCommonSearch{AppID: file.AppID, Condition: conds, Page: input.Page}
+			data, err := s.Logic.ExecuteSetDynamicGroup(kit, cond, input.Fields, input.DisableCounter)
+			if err != nil {
+				writer.Close()
+				return nil, err
+			}
+			rows = data.Info
+		default:
+			writer.Close()
+			return nil, fmt.Errorf("dynamic group object type %s is invalid", objID)
+		}
+
+		if err = writer.WriteRows(rows); err != nil {
+			writer.Close()
+			return nil, err
+		}
+
+		count += int64(len(rows))
+		if len(rows) < input.Page.Limit {
+			break
+		}
+		input.Page.Start += input.Page.Limit
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	size, chunkCount, err := s.saveExportFileChunks(kit, file.ID, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapstr.MapStr{"size": size, "count": count, "chunk_count": chunkCount}, nil
+}
+
+// saveExportFileChunks saves the content of the export file as chunks, returns the file size and chunk count.
+func (s *Service) saveExportFileChunks(kit *rest.Kit, fileID int64, filePath string) (int64, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	size, index := int64(0), int64(0)
+	buf := make([]byte, meta.DynamicGroupExportChunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			chunk := &meta.DynamicGroupExportFileChunk{FileID: fileID, Index: index, Data: buf[:n]}
+			if err := s.CoreAPI.CoreService().DynamicGroupExport().CreateExportChunk(kit.Ctx, kit.Header,
+				chunk); err != nil {
+				blog.Errorf("save dynamic group export file %d chunk %d failed, err: %v, rid: %s", fileID, index,
+					err, kit.Rid)
+				return 0, 0, err
+			}
+			size += int64(n)
+			index++
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return size, index, nil
+}
+
+// trimDynamicGroupExportFiles removes the oldest export files of the dynamic group that exceeds max file count.
+func (s *Service) trimDynamicGroupExportFiles(kit *rest.Kit, bizID int64, groupID string) {
+	maxCount := s.Config.DynamicGroupExport.MaxFilesPerGroup
+	if maxCount <= 0 {
+		return
+	}
+
+	// skip the newest files that should be kept, the rest of the files are deleted
+	listOpt := &meta.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppIDField: bizID, "group_id": groupID},
+		Fields:    []string{common.BKFieldID},
+		Page: meta.BasePage{
+			Start: maxCount,
+			Limit: common.BKMaxLimitSize,
+			Sort:  "-" + common.CreateTimeField,
+		},
+		DisableCounter: true,
+	}
+	files, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportFile(kit.Ctx, kit.Header, listOpt)
+	if err != nil {
+		blog.Errorf("list dynamic group export files failed, opt: %+v, err: %v, rid: %s", listOpt, err, kit.Rid)
+		return
+	}
+	if len(files.Info) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(files.Info))
+	for idx, file := range files.Info {
+		ids[idx] = file.ID
+	}
+
+	delOpt := &meta.DeleteOption{Condition: mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: ids}}}
+	if err = s.CoreAPI.CoreService().DynamicGroupExport().DeleteExportFile(kit.Ctx, kit.Header, delOpt); err != nil {
+		blog.Errorf("delete exceeded dynamic group export files failed, ids: %v, err: %v, rid: %s", ids, err,
+			kit.Rid)
+	}
+}
+
+// SearchDynamicGroupExportFile searches the export files of dynamic groups in the business.
+func (s *Service) SearchDynamicGroupExportFile(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt := new(meta.SearchDynamicGroupExportOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	listOpt := &meta.QueryCondition{
+		Condition: buildDynamicGroupExportCond(bizID, opt),
+		Page:      opt.Page,
+	}
+	if len(listOpt.Page.Sort) == 0 {
+		listOpt.Page.Sort = "-" + common.CreateTimeField
+	}
+
+	files, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportFile(ctx.Kit.Ctx, ctx.Kit.Header, listOpt)
+	if err != nil {
+		blog.Errorf("search dynamic group export file failed, opt: %+v, err: %v, rid: %s", opt, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(files)
+}
+
+func buildDynamicGroupExportCond(bizID int64, opt *meta.SearchDynamicGroupExportOption) mapstr.MapStr {
+	cond := mapstr.MapStr{common.BKAppIDField: bizID}
+	if len(opt.GroupID) > 0 {
+		cond["group_id"] = opt.GroupID
+	}
+	if len(opt.IDs) > 0 {
+		cond[common.BKFieldID] = mapstr.MapStr{common.BKDBIN: opt.IDs}
+	}
+	return cond
+}
+
+// GetDynamicGroupExportFileChunk returns a content chunk of the dynamic group export file.
+func (s *Service) GetDynamicGroupExportFileChunk(ctx *rest.Contexts) {
+	file, err := s.getDynamicGroupExportFile(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	index, err := strconv.ParseInt(ctx.Request.PathParameter("index"), 10, 64)
+	if err != nil || index < 0 || index >= file.ChunkCount {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "index"))
+		return
+	}
+
+	opt := &meta.FindDynamicGroupExportChunkOption{FileID: file.ID, Index: index}
+	chunk, err := s.CoreAPI.CoreService().DynamicGroupExport().FindExportChunk(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("find dynamic group export file chunk failed, opt: %+v, err: %v, rid: %s", opt, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(chunk)
+}
+
+// DeleteDynamicGroupExportFile deletes the dynamic group export file.
+func (s *Service) DeleteDynamicGroupExportFile(ctx *rest.Contexts) {
+	file, err := s.getDynamicGroupExportFile(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	opt := &meta.DeleteOption{Condition: mapstr.MapStr{common.BKFieldID: file.ID}}
+	if err = s.CoreAPI.CoreService().DynamicGroupExport().DeleteExportFile(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt); err != nil {
+		blog.Errorf("delete dynamic group export file %d failed, err: %v, rid: %s", file.ID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// getDynamicGroupExportFile gets the export file specified by the path parameters, the file must belong to the
+// dynamic group, so that the permission of the dynamic group applies to the file.
+func (s *Service) getDynamicGroupExportFile(ctx *rest.Contexts) (*meta.DynamicGroupExportFile, error) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)
+	}
+
+	fileID, err := strconv.ParseInt(ctx.Request.PathParameter("file_id"), 10, 64)
+	if err != nil {
+		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "file_id")
+	}
+
+	opt := &meta.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKAppIDField: bizID,
+			"group_id":          ctx.Request.PathParameter(common.BKFieldID),
+			common.BKFieldID:    fileID,
+		},
+		Page:           meta.BasePage{Limit: 1},
+		DisableCounter: true,
+	}
+	files, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportFile(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("list dynamic group export file failed, opt: %+v, err: %v, rid: %s", opt, err, ctx.Kit.Rid)
+		return nil, err
+	}
+	if len(files.Info) == 0 {
+		blog.Errorf("dynamic group export file %d is not exist, rid: %s", fileID, ctx.Kit.Rid)
+		return nil, ctx.Kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	return &files.Info[0], nil
+}
+
+// CreateDynamicGroupExportSchedule creates a schedule that exports the dynamic group periodically.
+func (s *Service) CreateDynamicGroupExportSchedule(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+	groupID := ctx.Request.PathParameter(common.BKFieldID)
+
+	spec := new(meta.DynamicGroupExportScheduleSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := spec.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	groupRes, err := s.CoreAPI.CoreService().Host().GetDynamicGroup(ctx.Kit.Ctx, strconv.FormatInt(bizID, 10),
+		groupID, ctx.Kit.Header)
+	if err != nil {
+		blog.Errorf("get dynamic group failed, err: %v, bizID: %d, ID: %s, rid: %s", err, bizID, groupID,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed))
+		return
+	}
+	if !groupRes.Result {
+		ctx.RespAutoError(groupRes.CCError())
+		return
+	}
+	if len(groupRes.Data.Name) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	schedule := &meta.DynamicGroupExportSchedule{
+		AppID:                  bizID,
+		GroupID:                groupID,
+		DynamicGroupExportSpec: spec.DynamicGroupExportSpec,
+		Cron:                   spec.Cron,
+		Enable:                 spec.Enable,
+	}
+	result, err := s.CoreAPI.CoreService().DynamicGroupExport().CreateExportSchedule(ctx.Kit.Ctx, ctx.Kit.Header,
+		schedule)
+	if err != nil {
+		blog.Errorf("create dynamic group export schedule failed, schedule: %+v, err: %v, rid: %s", schedule, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// UpdateDynamicGroupExportSchedule updates the dynamic group export schedule.
+func (s *Service) UpdateDynamicGroupExportSchedule(ctx *rest.Contexts) {
+	cond, err := buildExportScheduleCond(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	spec := new(meta.DynamicGroupExportScheduleSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := spec.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	opt := &meta.UpdateOption{
+		Condition: cond,
+		Data: mapstr.MapStr{
+			"format":             spec.Format,
+			"fields":             spec.Fields,
+			"cron":               spec.Cron,
+			"enable":             spec.Enable,
+			common.ModifierField: ctx.Kit.User,
+		},
+	}
+	if err = s.CoreAPI.CoreService().DynamicGroupExport().UpdateExportSchedule(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt); err != nil {
+		blog.Errorf("update dynamic group export schedule failed, opt: %+v, err: %v, rid: %s", opt, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteDynamicGroupExportSchedule deletes the dynamic group export schedule.
+func (s *Service) DeleteDynamicGroupExportSchedule(ctx *rest.Contexts) {
+	cond, err := buildExportScheduleCond(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	opt := &meta.DeleteOption{Condition: cond}
+	if err = s.CoreAPI.CoreService().DynamicGroupExport().DeleteExportSchedule(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt); err != nil {
+		blog.Errorf("delete dynamic group export schedule failed, opt: %+v, err: %v, rid: %s", opt, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+func buildExportScheduleCond(ctx *rest.Contexts) (mapstr.MapStr, error) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)
+	}
+
+	scheduleID, err := strconv.ParseInt(ctx.Request.PathParameter("schedule_id"), 10, 64)
+	if err != nil {
+		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "schedule_id")
+	}
+
+	return mapstr.MapStr{
+		common.BKAppIDField: bizID,
+		"group_id":          ctx.Request.PathParameter(common.BKFieldID),
+		common.BKFieldID:    scheduleID,
+	}, nil
+}
+
+// SearchDynamicGroupExportSchedule searches the dynamic group export schedules in the business.
+func (s *Service) SearchDynamicGroupExportSchedule(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt := new(meta.SearchDynamicGroupExportOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	listOpt := &meta.QueryCondition{Condition: buildDynamicGroupExportCond(bizID, opt), Page: opt.Page}
+	schedules, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportSchedule(ctx.Kit.Ctx, ctx.Kit.Header,
+		listOpt)
+	if err != nil {
+		blog.Errorf("search dynamic group export schedule failed, opt: %+v, err: %v, rid: %s", opt, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(schedules)
+}
+
+// TimerExportDynamicGroup triggers the dynamic group export schedules and cleans up the expired export files.
+func (s *Service) TimerExportDynamicGroup(ctx context.Context) {
+	lastCleanTime := time.Time{}
+	for {
+		time.Sleep(time.Minute)
+
+		if !s.Engine.ServiceManageInterface.IsMaster() {
+			continue
+		}
+
+		rid := util.GenerateRID()
+		header := headerutil.GenCommonHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID, rid)
+		kit := rest.NewKitFromHeader(header, s.CCErr)
+
+		s.runDynamicGroupExportSchedules(kit)
+
+		if time.Since(lastCleanTime) < time.Hour {
+			continue
+		}
+		lastCleanTime = time.Now()
+
+		blog.Infof("begin clean up expired dynamic group export files, rid: %s", rid)
+		s.cleanExpiredDynamicGroupExportFiles(kit)
+	}
+}
+
+func (s *Service) runDynamicGroupExportSchedules(kit *rest.Kit) {
+	listOpt := &meta.QueryCondition{
+		Condition:      mapstr.MapStr{"enable": true},
+		Page:           meta.BasePage{Limit: common.BKMaxLimitSize, Sort: common.BKFieldID},
+		DisableCounter: true,
+	}
+
+	now := time.Now()
+	for {
+		schedules, err := s.CoreAPI.CoreService().DynamicGroupExport().ListExportSchedule(kit.Ctx, kit.Header,
+			listOpt)
+		if err != nil {
+			blog.Errorf("list dynamic group export schedules failed, err: %v, rid: %s", err, kit.Rid)
+			return
+		}
+
+		for _, schedule := range schedules.Info {
+			nextRunTime, err := schedule.NextRunTime()
+			if err != nil {
+				blog.Errorf("schedule %d cron %s is invalid, err: %v, rid: %s", schedule.ID, schedule.Cron, err,
+					kit.Rid)
+				continue
+			}
+			if nextRunTime.After(now) {
+				continue
+			}
+
+			s.runDynamicGroupExportSchedule(kit, &schedule, now)
+		}
+
+		if len(schedules.Info) < listOpt.Page.Limit {
+			return
+		}
+		listOpt.Page.Start += listOpt.Page.Limit
+	}
+}
+
+// runDynamicGroupExportSchedule exports the dynamic group as the creator of the schedule, the last run time is
+// updated even if the export failed, so that the failed schedule is retried at the next scheduled time.
+func (s *Service) runDynamicGroupExportSchedule(kit *rest.Kit, schedule *meta.DynamicGroupExportSchedule,
+	now time.Time) {
+
+	header := headerutil.GenCommonHeader(schedule.Creator, schedule.OwnerID, kit.Rid)
+	scheduleKit := rest.NewKitFromHeader(header, s.CCErr)
+	_, err := s.createDynamicGroupExport(scheduleKit, schedule.AppID, schedule.GroupID, schedule.ID,
+		&schedule.DynamicGroupExportSpec)
+	if err != nil {
+		blog.Errorf("export dynamic group by schedule %d failed, err: %v, rid: %s", schedule.ID, err, kit.Rid)
+	}
+
+	opt := &meta.UpdateOption{
+		Condition: mapstr.MapStr{common.BKFieldID: schedule.ID},
+		Data:      mapstr.MapStr{meta.DynamicGroupExportLastRunTimeField: now.Unix()},
+	}
+	if err = s.CoreAPI.CoreService().DynamicGroupExport().UpdateExportSchedule(kit.Ctx, kit.Header,
+		opt); err != nil {
+		blog.Errorf("update schedule %d last run time failed, err: %v, rid: %s", schedule.ID, err, kit.Rid)
+	}
+}
+
+func (s *Service) cleanExpiredDynamicGroupExportFiles(kit *rest.Kit) {
+	expireTime := time.Now().AddDate(0, 0, -s.Config.DynamicGroupExport.RetentionDays)
+	opt := &meta.DeleteOption{
+		Condition: mapstr.MapStr{common.CreateTimeField: mapstr.MapStr{common.BKDBLT: expireTime.Unix()}},
+	}
+	if err := s.CoreAPI.CoreService().DynamicGroupExport().DeleteExportFile(kit.Ctx, kit.Header, opt); err != nil {
+		blog.Errorf("delete expired dynamic group export files failed, err: %v, rid: %s", err, kit.Rid)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
 	meta "configcenter/src/common/metadata"
 	parser "configcenter/src/common/paraparse"
 	"configcenter/src/scene_server/host_server/logics"
@@ -319,6 +320,18 @@ func (s *Service) DeleteDynamicGroup(ctx *rest.Contexts) {
 			return result.CCError()
 		}
 
+		// export schedules of the dynamic group can not run any more, delete them with the group.
+		delScheduleOpt := &meta.DeleteOption{
+			Condition: mapstr.MapStr{common.BKAppIDField: dynamicGroup.AppID, "group_id": targetID},
+		}
+		err = s.CoreAPI.CoreService().DynamicGroupExport().DeleteExportSchedule(ctx.Kit.Ctx, ctx.Kit.Header,
+			delScheduleOpt)
+		if err != nil {
+			blog.Errorf("delete dynamic group export schedules failed, err: %v, bizID: %s, id: %s, rid: %s", err,
+				bizID, targetID, ctx.Kit.Rid)
+			return err
+		}
+
 		// audit log.
 		audit := auditlog.NewDynamicGroupAuditLog(s.CoreAPI.CoreService())
 		auditParam := auditlog.NewGenerateAuditCommonParameter(ctx.Kit, meta.AuditDelete)
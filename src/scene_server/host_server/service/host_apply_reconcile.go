@@ -0,0 +1,549 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/host_server/logics"
+)
+
+// hostApplyReconcileBatchSize is the count of hosts whose apply plans are generated at one time
+const hostApplyReconcileBatchSize = 500
+
+// PreviewHostApplyRule previews the effect of the host apply rule change on all the hosts of the affected modules
+// and service templates, the change is not saved.
+func (s *Service) PreviewHostApplyRule(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := new(metadata.PreviewHostApplyRuleOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	option.BizID = bizID
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.previewHostApplyRule(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+func (s *Service) previewHostApplyRule(kit *rest.Kit, option *metadata.PreviewHostApplyRuleOption) (
+	*metadata.HostApplyPlanResult, error) {
+
+	ruleOption := metadata.ListHostApplyRuleOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	rules, ccErr := s.CoreAPI.CoreService().HostApplyRule().ListHostApplyRule(kit.Ctx, kit.Header, option.BizID,
+		ruleOption)
+	if ccErr != nil {
+		blog.Errorf("list host apply rule failed, bizID: %d, err: %v, rid: %s", option.BizID, ccErr, kit.Rid)
+		return nil, ccErr
+	}
+
+	// collect the modules and service templates whose rules are changed, they are treated as host apply enabled
+	changedModuleIDs, changedTemplateIDs := make([]int64, 0), make([]int64, 0)
+	for _, rule := range option.AdditionalRules {
+		if rule.ServiceTemplateID != 0 {
+			changedTemplateIDs = append(changedTemplateIDs, rule.ServiceTemplateID)
+			continue
+		}
+		changedModuleIDs = append(changedModuleIDs, rule.ModuleID)
+	}
+	for _, rule := range rules.Info {
+		if !util.InArray(rule.ID, option.RemoveRuleIDs) {
+			continue
+		}
+		if rule.ServiceTemplateID != 0 {
+			changedTemplateIDs = append(changedTemplateIDs, rule.ServiceTemplateID)
+			continue
+		}
+		changedModuleIDs = append(changedModuleIDs, rule.ModuleID)
+	}
+
+	hostModules, moduleIDs, err := s.getHostApplyPreviewHosts(kit, option.BizID, changedModuleIDs,
+		changedTemplateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hostModules) == 0 {
+		return &metadata.HostApplyPlanResult{Plans: make([]metadata.OneHostApplyPlan, 0),
+			Rules: make([]metadata.HostApplyRule, 0)}, nil
+	}
+
+	modules, err := s.getModuleRelateHostApply(kit, option.BizID, moduleIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	templateIDs := make([]int64, 0)
+	for index, module := range modules {
+		if module.ServiceTemplateID != 0 {
+			templateIDs = append(templateIDs, module.ServiceTemplateID)
+		}
+		if util.InArray(module.ModuleID, changedModuleIDs) {
+			modules[index].HostApplyEnabled = true
+		}
+	}
+
+	templateEnabled := make(map[int64]bool)
+	if len(templateIDs) != 0 {
+		templateEnabled, err = s.getSrvTemplateApplyStatus(kit, option.BizID, util.IntArrayUnique(templateIDs))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, templateID := range changedTemplateIDs {
+		templateEnabled[templateID] = true
+	}
+
+	overlayRules := logics.OverlayHostApplyRules(option.BizID, rules.Info, option.AdditionalRules,
+		option.RemoveRuleIDs)
+	planOption := metadata.HostApplyPlanOption{
+		Rules:       logics.HostApplyFinalRules(modules, templateEnabled, overlayRules),
+		HostModules: hostModules,
+	}
+
+	planResult, ccErr := s.CoreAPI.CoreService().HostApplyRule().GenerateApplyPlan(kit.Ctx, kit.Header,
+		option.BizID, planOption)
+	if ccErr != nil {
+		blog.Errorf("generate apply plan failed, bizID: %d, opt: %v, err: %v, rid: %s", option.BizID, planOption,
+			ccErr, kit.Rid)
+		return nil, ccErr
+	}
+	planResult.Rules = planOption.Rules
+	return &planResult, nil
+}
+
+// getHostApplyPreviewHosts returns all the module relations of the hosts in the changed modules and the modules
+// of the changed service templates, and the ids of all these hosts' modules.
+func (s *Service) getHostApplyPreviewHosts(kit *rest.Kit, bizID int64, changedModuleIDs, changedTemplateIDs []int64) (
+	[]metadata.Host2Modules, []int64, error) {
+
+	affectedModuleIDs := util.IntArrayUnique(changedModuleIDs)
+	if len(changedTemplateIDs) != 0 {
+		templateModules, err := s.getModuleRelateHostApply(kit, bizID, nil, util.IntArrayUnique(changedTemplateIDs))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, module := range templateModules {
+			affectedModuleIDs = append(affectedModuleIDs, module.ModuleID)
+		}
+	}
+
+	if len(affectedModuleIDs) == 0 {
+		return make([]metadata.Host2Modules, 0), make([]int64, 0), nil
+	}
+
+	hostIDs, err := s.getHostIDByCondition(kit, bizID, util.IntArrayUnique(affectedModuleIDs), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(hostIDs) == 0 {
+		return make([]metadata.Host2Modules, 0), make([]int64, 0), nil
+	}
+
+	return s.getHostApplyHostModules(kit, bizID, nil, hostIDs)
+}
+
+// getHostApplyHostModules returns the module relations of the hosts in the modules or of the hosts, and the ids of
+// the modules in these relations.
+func (s *Service) getHostApplyHostModules(kit *rest.Kit, bizID int64, moduleIDs, hostIDs []int64) (
+	[]metadata.Host2Modules, []int64, error) {
+
+	relationReq := &metadata.HostModuleRelationRequest{
+		ApplicationID: bizID,
+		ModuleIDArr:   moduleIDs,
+		HostIDArr:     hostIDs,
+		Page:          metadata.BasePage{Limit: common.BKNoLimit},
+		Fields:        []string{common.BKModuleIDField, common.BKHostIDField},
+	}
+	relations, err := s.CoreAPI.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, relationReq)
+	if err != nil {
+		blog.Errorf("get host module relation failed, req: %v, err: %v, rid: %s", relationReq, err, kit.Rid)
+		return nil, nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+
+	hostModuleMap := make(map[int64][]int64)
+	hostIDOrder := make([]int64, 0)
+	allModuleIDs := make([]int64, 0)
+	for _, relation := range relations.Info {
+		if _, exists := hostModuleMap[relation.HostID]; !exists {
+			hostIDOrder = append(hostIDOrder, relation.HostID)
+		}
+		hostModuleMap[relation.HostID] = append(hostModuleMap[relation.HostID], relation.ModuleID)
+		allModuleIDs = append(allModuleIDs, relation.ModuleID)
+	}
+
+	hostModules := make([]metadata.Host2Modules, 0, len(hostIDOrder))
+	for _, hostID := range hostIDOrder {
+		hostModules = append(hostModules, metadata.Host2Modules{HostID: hostID, ModuleIDs: hostModuleMap[hostID]})
+	}
+
+	return hostModules, util.IntArrayUnique(allModuleIDs), nil
+}
+
+// UpdateHostApplyReconcileSetting updates the host apply reconcile setting of the business
+func (s *Service) UpdateHostApplyReconcileSetting(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := new(metadata.UpdateHostApplyReconcileSettingOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	setting := &metadata.HostApplyReconcileSetting{
+		BizID:       bizID,
+		Enabled:     option.Enabled,
+		AutoCorrect: option.AutoCorrect,
+	}
+	if err := s.CoreAPI.CoreService().HostApplyReconcile().UpdateReconcileSetting(ctx.Kit.Ctx, ctx.Kit.Header,
+		setting); err != nil {
+		blog.Errorf("update host apply reconcile setting failed, setting: %+v, err: %v, rid: %s", setting, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// GetHostApplyReconcileSetting returns the host apply reconcile setting of the business
+func (s *Service) GetHostApplyReconcileSetting(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setting, err := s.getHostApplyReconcileSetting(ctx.Kit, bizID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(setting)
+}
+
+// getHostApplyReconcileSetting returns the setting of the business, the business is disabled if it is not set
+func (s *Service) getHostApplyReconcileSetting(kit *rest.Kit, bizID int64) (*metadata.HostApplyReconcileSetting,
+	error) {
+
+	listOpt := &metadata.ListHostApplyReconcileSettingOption{BizIDs: []int64{bizID}}
+	settings, err := s.CoreAPI.CoreService().HostApplyReconcile().ListReconcileSetting(kit.Ctx, kit.Header, listOpt)
+	if err != nil {
+		blog.Errorf("list host apply reconcile setting failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(settings) == 0 {
+		return &metadata.HostApplyReconcileSetting{BizID: bizID}, nil
+	}
+	return &settings[0], nil
+}
+
+// ListHostApplyConflict lists the hosts of the business that drifted away from their host apply rules, which are
+// recorded by the last reconciliation.
+func (s *Service) ListHostApplyConflict(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := new(metadata.ListHostApplyConflictOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	option.BizID = bizID
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.CoreAPI.CoreService().HostApplyReconcile().ListConflict(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("list host apply conflict failed, option: %+v, err: %v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// RunHostApplyReconcile reconciles the hosts of the business immediately with its reconcile setting
+func (s *Service) RunHostApplyReconcile(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setting, err := s.getHostApplyReconcileSetting(ctx.Kit, bizID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.reconcileBizHostApply(ctx.Kit, setting)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// TimerReconcileHostApply reconciles the hosts of the businesses that are opted in periodically
+func (s *Service) TimerReconcileHostApply(ctx context.Context) {
+	lastRunTime := time.Time{}
+	for {
+		time.Sleep(time.Minute)
+
+		if !s.Engine.ServiceManageInterface.IsMaster() {
+			continue
+		}
+
+		interval := time.Duration(s.Config.HostApplyReconcile.IntervalMinutes) * time.Minute
+		if time.Since(lastRunTime) < interval {
+			continue
+		}
+		lastRunTime = time.Now()
+
+		rid := util.GenerateRID()
+		header := headerutil.GenCommonHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID, rid)
+		kit := rest.NewKitFromHeader(header, s.CCErr)
+
+		listOpt := &metadata.ListHostApplyReconcileSettingOption{OnlyEnabled: true}
+		settings, err := s.CoreAPI.CoreService().HostApplyReconcile().ListReconcileSetting(kit.Ctx, kit.Header,
+			listOpt)
+		if err != nil {
+			blog.Errorf("list enabled host apply reconcile settings failed, err: %v, rid: %s", err, rid)
+			continue
+		}
+
+		for index := range settings {
+			result, err := s.reconcileBizHostApply(kit, &settings[index])
+			if err != nil {
+				blog.Errorf("reconcile host apply failed, bizID: %d, err: %v, rid: %s", settings[index].BizID, err,
+					rid)
+				continue
+			}
+			blog.Infof("reconcile host apply success, bizID: %d, result: %+v, rid: %s", settings[index].BizID,
+				result, rid)
+		}
+	}
+}
+
+// reconcileBizHostApply detects the hosts of the business that drifted away from the final host apply rules of
+// their modules, corrects them if auto correct is enabled, and replaces the recorded conflicts of the business.
+func (s *Service) reconcileBizHostApply(kit *rest.Kit, setting *metadata.HostApplyReconcileSetting) (
+	*metadata.HostApplyReconcileResult, error) {
+
+	bizID := setting.BizID
+	rules, err := s.getRulesPriorityFromTemplate(kit, nil, bizID)
+	if err != nil {
+		blog.Errorf("get host apply final rules failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, err
+	}
+
+	result := new(metadata.HostApplyReconcileResult)
+	conflicts := make([]metadata.HostApplyConflict, 0)
+	if len(rules) != 0 {
+		moduleIDs := make([]int64, 0)
+		for _, rule := range rules {
+			moduleIDs = append(moduleIDs, rule.ModuleID)
+		}
+
+		hostModules, _, err := s.getHostApplyHostModules(kit, bizID, util.IntArrayUnique(moduleIDs), nil)
+		if err != nil {
+			return nil, err
+		}
+		result.HostCount = len(hostModules)
+
+		now := time.Now()
+		for start := 0; start < len(hostModules); start += hostApplyReconcileBatchSize {
+			end := start + hostApplyReconcileBatchSize
+			if end > len(hostModules) {
+				end = len(hostModules)
+			}
+
+			planOption := metadata.HostApplyPlanOption{Rules: rules, HostModules: hostModules[start:end]}
+			planResult, ccErr := s.CoreAPI.CoreService().HostApplyRule().GenerateApplyPlan(kit.Ctx, kit.Header,
+				bizID, planOption)
+			if ccErr != nil {
+				blog.Errorf("generate apply plan failed, bizID: %d, err: %v, rid: %s", bizID, ccErr, kit.Rid)
+				return nil, ccErr
+			}
+
+			conflicts = append(conflicts, logics.HostApplyConflicts(bizID, planResult.Plans, now)...)
+		}
+	}
+
+	if setting.AutoCorrect {
+		conflicts, result.CorrectedCount = s.correctHostApplyConflicts(kit, bizID, conflicts)
+	}
+	result.ConflictCount = len(conflicts)
+
+	replaceOpt := &metadata.ReplaceHostApplyConflictOption{BizID: bizID, Conflicts: conflicts}
+	if err := s.CoreAPI.CoreService().HostApplyReconcile().ReplaceConflict(kit.Ctx, kit.Header,
+		replaceOpt); err != nil {
+		blog.Errorf("replace host apply conflicts failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// correctHostApplyConflicts updates the drifted hosts to the expected values, the hosts with the same update data
+// are updated together. It returns the conflicts that still exist and the count of the corrected hosts.
+func (s *Service) correctHostApplyConflicts(kit *rest.Kit, bizID int64, conflicts []metadata.HostApplyConflict) (
+	[]metadata.HostApplyConflict, int) {
+
+	updateMap := make(map[string][]int)
+	updateData := make(map[string]map[string]interface{})
+	for index := range conflicts {
+		if conflicts[index].ErrMsg != "" {
+			continue
+		}
+
+		data := logics.HostApplyCorrectData(&conflicts[index])
+		if len(data) == 0 {
+			continue
+		}
+
+		dataStr, err := json.Marshal(data)
+		if err != nil {
+			blog.Errorf("marshal host apply correct data failed, data: %v, err: %v, rid: %s", data, err, kit.Rid)
+			conflicts[index].ErrMsg = err.Error()
+			continue
+		}
+		updateMap[string(dataStr)] = append(updateMap[string(dataStr)], index)
+		updateData[string(dataStr)] = data
+	}
+
+	corrected := make(map[int]bool)
+	for dataStr, indexes := range updateMap {
+		hostIDs := make([]int64, len(indexes))
+		for i, index := range indexes {
+			hostIDs[i] = conflicts[index].HostID
+		}
+
+		if err := s.correctHostApplyHosts(kit, bizID, hostIDs, updateData[dataStr]); err != nil {
+			for _, index := range indexes {
+				conflicts[index].ErrMsg = err.Error()
+			}
+			continue
+		}
+
+		for _, index := range indexes {
+			corrected[index] = true
+		}
+	}
+
+	// the corrected hosts only keep the ambiguous fields that can not be corrected
+	remains := make([]metadata.HostApplyConflict, 0)
+	for index, conflict := range conflicts {
+		if !corrected[index] {
+			remains = append(remains, conflict)
+			continue
+		}
+
+		fields := make([]metadata.HostApplyConflictDrift, 0)
+		for _, field := range conflict.Fields {
+			if field.Ambiguous {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) != 0 {
+			conflict.Fields = fields
+			remains = append(remains, conflict)
+		}
+	}
+
+	return remains, len(corrected)
+}
+
+// correctHostApplyHosts updates the hosts with the data and saves the audit logs
+func (s *Service) correctHostApplyHosts(kit *rest.Kit, bizID int64, hostIDs []int64,
+	data map[string]interface{}) errors.CCErrorCoder {
+
+	cond := map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}}
+
+	audit := auditlog.NewHostAudit(s.CoreAPI.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditUpdate).WithUpdateFields(data).
+		WithOperateFrom(metadata.FromHostApplyReconcile)
+	auditLogs, err := audit.GenerateAuditLogByCond(auditParam, bizID, cond)
+	if err != nil {
+		blog.Errorf("generate host audit log failed, hostIDs: %v, err: %v, rid: %s", hostIDs, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrAuditTakeSnapshotFailed)
+	}
+
+	updateOp := &metadata.UpdateOption{Data: data, Condition: cond}
+	if _, err := s.CoreAPI.CoreService().Instance().UpdateInstance(kit.Ctx, kit.Header, common.BKInnerObjIDHost,
+		updateOp); err != nil {
+		blog.Errorf("update host failed, option: %v, err: %v, rid: %s", updateOp, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+
+	if len(auditLogs) == 0 {
+		return nil
+	}
+
+	if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save host apply reconcile audit log failed, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrAuditSaveLogFailed)
+	}
+
+	return nil
+}
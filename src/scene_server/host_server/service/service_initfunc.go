@@ -160,6 +160,14 @@ func (s *Service) initHost(web *restful.WebService) {
 		Handler: s.SearchHostWithKube})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/hosts/all/property",
 		Handler: s.UpdateHostAllProperty})
+
+	// collected host attribute history and drift
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/attr_history",
+		Handler: s.FindHostAttrHistory})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/attr_history/value",
+		Handler: s.FindHostAttrValue})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/attr_drift",
+		Handler: s.FindHostAttrDrift})
 	utility.AddToRestfulWebService(web)
 
 }
@@ -226,6 +234,20 @@ func (s *Service) initHostapplyrule(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/check/objectattr/host_apply_enabled",
 		Handler: s.CheckAttrHostApplyEnabled})
 
+	// 主机属性自动应用规则变更预览及巡检
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/findmany/host_apply_plan/bk_biz_id/{bk_biz_id}/preview", Handler: s.PreviewHostApplyRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut,
+		Path:    "/update/host_apply_plan/bk_biz_id/{bk_biz_id}/reconcile_setting",
+		Handler: s.UpdateHostApplyReconcileSetting})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path:    "/find/host_apply_plan/bk_biz_id/{bk_biz_id}/reconcile_setting",
+		Handler: s.GetHostApplyReconcileSetting})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/findmany/host_apply_plan/bk_biz_id/{bk_biz_id}/conflict", Handler: s.ListHostApplyConflict})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/updatemany/host_apply_plan/bk_biz_id/{bk_biz_id}/reconcile", Handler: s.RunHostApplyReconcile})
+
 	utility.AddToRestfulWebService(web)
 }
 
@@ -368,6 +390,69 @@ func (s *Service) initDynamicGroup(web *restful.WebService) {
 		Handler: s.ExecuteDynamicGroup,
 	})
 
+	// create an async task to export dynamic group data into file.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/dynamicgroup/export/{bk_biz_id}/{id}",
+		Handler: s.ExportDynamicGroup,
+	})
+
+	// the async task that generates the dynamic group export file.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/dynamicgroup/export/task",
+		Handler: s.DynamicGroupExportTask,
+	})
+
+	// search dynamic group export files.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/dynamicgroup/export/search/file/{bk_biz_id}",
+		Handler: s.SearchDynamicGroupExportFile,
+	})
+
+	// get content chunk of dynamic group export file.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodGet,
+		Path:    "/dynamicgroup/export/file/{bk_biz_id}/{id}/{file_id}/chunk/{index}",
+		Handler: s.GetDynamicGroupExportFileChunk,
+	})
+
+	// delete dynamic group export file.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodDelete,
+		Path:    "/dynamicgroup/export/file/{bk_biz_id}/{id}/{file_id}",
+		Handler: s.DeleteDynamicGroupExportFile,
+	})
+
+	// create dynamic group export schedule.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/dynamicgroup/export/schedule/{bk_biz_id}/{id}",
+		Handler: s.CreateDynamicGroupExportSchedule,
+	})
+
+	// update dynamic group export schedule.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPut,
+		Path:    "/dynamicgroup/export/schedule/{bk_biz_id}/{id}/{schedule_id}",
+		Handler: s.UpdateDynamicGroupExportSchedule,
+	})
+
+	// delete dynamic group export schedule.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodDelete,
+		Path:    "/dynamicgroup/export/schedule/{bk_biz_id}/{id}/{schedule_id}",
+		Handler: s.DeleteDynamicGroupExportSchedule,
+	})
+
+	// search dynamic group export schedules.
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/dynamicgroup/export/search/schedule/{bk_biz_id}",
+		Handler: s.SearchDynamicGroupExportSchedule,
+	})
+
 	utility.AddToRestfulWebService(web)
 }
 
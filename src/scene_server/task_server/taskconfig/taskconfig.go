@@ -54,6 +54,8 @@ func init() {
 		"/topo/v3/sync/field_template/object/task", 1, 2)
 	AddCodeTaskConfig(common.SyncInstIDRuleTaskFlag, types.CC_MODULE_TOPO,
 		"/topo/v3/sync/id_rule/inst/task", 1, 2)
+	AddCodeTaskConfig(common.DynamicGroupExportTaskFlag, types.CC_MODULE_HOST,
+		"/host/v3/dynamicgroup/export/task", 1, 30)
 }
 
 // AddCodeTaskConfig add task
@@ -22,15 +22,17 @@ import (
 	"fmt"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/topo_server/app/options"
 	"configcenter/src/scene_server/topo_server/logics"
+	"configcenter/src/scene_server/topo_server/logics/fulltext"
 	"configcenter/src/scene_server/topo_server/service"
 	"configcenter/src/storage/driver/redis"
 	"configcenter/src/thirdparty/elasticsearch"
@@ -74,6 +76,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		ConfigUpdate: server.onTopoConfigUpdate,
 		SrvInfo:      svrInfo,
 	}
@@ -98,18 +101,13 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return err
 	}
 
-	essrv := new(elasticsearch.EsSrv)
-	if server.Config.Es.FullTextSearch == "on" {
-		esClient, err := elasticsearch.NewEsClient(server.Config.Es)
-		if err != nil {
-			blog.Errorf("failed to create elastic search client, err:%s", err.Error())
-			return fmt.Errorf("new es client failed, err: %v", err)
-		}
-		essrv.Client = esClient
+	fullText, err := newFullTextEngine(ctx, engine, server.Config.Es)
+	if err != nil {
+		return err
 	}
 
 	iamCli := new(iam.IAM)
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		blog.Info("enable auth center access")
 		iamCli, err = iam.NewIAM(server.Config.Auth, engine.Metric().Registry())
 		if err != nil {
@@ -124,7 +122,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		Language:    engine.Language,
 		Engine:      engine,
 		AuthManager: authManager,
-		Es:          essrv,
+		FullText:    fullText,
 		Logics:      logics.New(engine.CoreAPI, authManager, engine.Language),
 		Error:       engine.CCErr,
 		Config:      server.Config,
@@ -140,6 +138,41 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	return nil
 }
 
+// newFullTextEngine creates the fulltext search engine, returns nil if the fulltext search is not enabled.
+func newFullTextEngine(ctx context.Context, engine *backbone.Engine, conf elasticsearch.EsConfig) (fulltext.Engine,
+	error) {
+
+	if conf.FullTextSearch != "on" {
+		return nil, nil
+	}
+
+	switch conf.Engine {
+	case "", metadata.FullTextEngineElastic:
+		esClient, err := elasticsearch.NewEsClient(conf)
+		if err != nil {
+			blog.Errorf("failed to create elastic search client, err:%s", err.Error())
+			return nil, fmt.Errorf("new es client failed, err: %v", err)
+		}
+		return fulltext.NewElasticEngine(&elasticsearch.EsSrv{Client: esClient}), nil
+
+	case metadata.FullTextEngineLocal:
+		mongoConf, err := engine.WithMongo()
+		if err != nil {
+			return nil, err
+		}
+
+		blog.Infof("fulltext search uses the local engine")
+		localEngine, err := fulltext.NewLocalEngine(ctx, mongoConf.GetMongoConf())
+		if err != nil {
+			return nil, fmt.Errorf("new local fulltext search engine failed, err: %v", err)
+		}
+		return localEngine, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported fulltext search engine: %s", conf.Engine)
+	}
+}
+
 const waitForSeconds = 180
 
 // CheckForReadiness TODO
@@ -42,6 +42,15 @@ var innerFieldMap = map[string]struct{}{
 func (t *template) CompareFieldTemplateAttr(kit *rest.Kit, opt *metadata.CompareFieldTmplAttrOption, forUI bool) (
 	*metadata.CompareFieldTmplAttrsRes, *metadata.ListFieldTmpltSyncStatusResult, error) {
 
+	// compare with the attributes of the specified field template version
+	if opt.Version != 0 {
+		version, err := t.GetFieldTemplateVersion(kit, opt.TemplateID, opt.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+		opt.Attrs = version.Attributes
+	}
+
 	// check compare options that is not related to object attribute
 	objID, err := t.comparator.getObjIDAndValidate(kit, opt.ObjectID)
 	if err != nil {
@@ -34,6 +34,20 @@ import (
 func (t *template) CompareFieldTemplateUnique(kit *rest.Kit, opt *metadata.CompareFieldTmplUniqueOption, forUI bool) (
 	*metadata.CompareFieldTmplUniquesRes, *metadata.ListFieldTmpltSyncStatusResult, error) {
 
+	// compare with the uniques of the specified field template version
+	if opt.Version != 0 {
+		version, err := t.GetFieldTemplateVersion(kit, opt.TemplateID, opt.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		uniques, rawErr := version.UniqueOptions()
+		if rawErr.ErrCode != 0 {
+			return nil, nil, rawErr.ToCCError(kit.CCError)
+		}
+		opt.Uniques = uniques
+	}
+
 	// check compare options that is not related to object unique
 	objID, err := t.comparator.getObjIDAndValidate(kit, opt.ObjectID)
 	if err != nil {
@@ -43,6 +43,11 @@ type FieldTemplateOperation interface {
 	DeleteFieldTemplateAttr(kit *rest.Kit, templateID int64, attrIDs []int64, needAuditLog bool) error
 	DeleteFieldTemplateUnique(kit *rest.Kit, templateID int64, uniques []int64, needAuditLog bool) error
 	UpdateFieldTemplateInfo(kit *rest.Kit, template *metadata.FieldTemplate) error
+	CreateFieldTemplateVersion(kit *rest.Kit, templateID int64) (*metadata.FieldTemplateVersion, error)
+	GetFieldTemplateVersion(kit *rest.Kit, templateID, version int64) (*metadata.FieldTemplateVersion, error)
+	CompareFieldTemplateVersion(kit *rest.Kit, opt *metadata.CompareFieldTmplVersionOption) (
+		*metadata.CompareFieldTmplVersionRes, error)
+	GetObjFieldTemplateVersion(kit *rest.Kit, templateID, objectID int64) (*metadata.FieldTemplateVersion, error)
 }
 
 // NewFieldTemplateOperation create a new field template operation instance
@@ -0,0 +1,132 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fieldtmpl
+
+import (
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CreateFieldTemplateVersion take a snapshot of the current field template attributes and uniques as a new version
+func (t *template) CreateFieldTemplateVersion(kit *rest.Kit, templateID int64) (*metadata.FieldTemplateVersion,
+	error) {
+
+	version, err := t.clientSet.CoreService().FieldTemplate().CreateFieldTemplateVersion(kit.Ctx, kit.Header,
+		templateID)
+	if err != nil {
+		blog.Errorf("create field template version failed, template id: %d, err: %v, rid: %s", templateID, err,
+			kit.Rid)
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// GetFieldTemplateVersion get the specified version snapshot of the field template
+func (t *template) GetFieldTemplateVersion(kit *rest.Kit, templateID, version int64) (*metadata.FieldTemplateVersion,
+	error) {
+
+	versionFilter, err := filtertools.And(filtertools.GenAtomFilter(common.BKTemplateID, filter.Equal, templateID),
+		filtertools.GenAtomFilter(common.BKVersion, filter.Equal, version))
+	if err != nil {
+		blog.Errorf("gen filter failed, template id: %d, version: %d, err: %v, rid: %s", templateID, version, err,
+			kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKVersion)
+	}
+
+	listOpt := &metadata.CommonQueryOption{
+		CommonFilterOption: metadata.CommonFilterOption{Filter: versionFilter},
+		Page:               metadata.BasePage{Limit: 1},
+	}
+
+	res, ccErr := t.clientSet.CoreService().FieldTemplate().ListFieldTemplateVersion(kit.Ctx, kit.Header, listOpt)
+	if ccErr != nil {
+		blog.Errorf("list field template version failed, opt: %+v, err: %v, rid: %s", listOpt, ccErr, kit.Rid)
+		return nil, ccErr
+	}
+
+	if len(res.Info) != 1 {
+		blog.Errorf("field template version not found, template id: %d, version: %d, rid: %s", templateID, version,
+			kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKVersion)
+	}
+
+	return &res.Info[0], nil
+}
+
+// CompareFieldTemplateVersion compare two versions of the field template
+func (t *template) CompareFieldTemplateVersion(kit *rest.Kit, opt *metadata.CompareFieldTmplVersionOption) (
+	*metadata.CompareFieldTmplVersionRes, error) {
+
+	from, err := t.GetFieldTemplateVersion(kit, opt.TemplateID, opt.FromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := t.GetFieldTemplateVersion(kit, opt.TemplateID, opt.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	res, rawErr := metadata.DiffFieldTemplateVersion(from, to)
+	if rawErr.ErrCode != 0 {
+		blog.Errorf("diff field template version failed, opt: %+v, err: %v, rid: %s", opt, rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+
+	return res, nil
+}
+
+// GetObjFieldTemplateVersion get the field template version snapshot that the object is pinned to, returns nil if
+// the object is not pinned to any version, which means the object follows the current field template content
+func (t *template) GetObjFieldTemplateVersion(kit *rest.Kit, templateID, objectID int64) (
+	*metadata.FieldTemplateVersion, error) {
+
+	relFilter, err := filtertools.And(filtertools.GenAtomFilter(common.BKTemplateID, filter.Equal, templateID),
+		filtertools.GenAtomFilter(common.ObjectIDField, filter.Equal, objectID))
+	if err != nil {
+		blog.Errorf("gen filter failed, template id: %d, object id: %d, err: %v, rid: %s", templateID, objectID, err,
+			kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.ObjectIDField)
+	}
+
+	listOpt := &metadata.CommonQueryOption{
+		CommonFilterOption: metadata.CommonFilterOption{Filter: relFilter},
+		Page:               metadata.BasePage{Limit: 1},
+	}
+
+	res, ccErr := t.clientSet.CoreService().FieldTemplate().ListObjFieldTmplRel(kit.Ctx, kit.Header, listOpt)
+	if ccErr != nil {
+		blog.Errorf("list field template relation failed, opt: %+v, err: %v, rid: %s", listOpt, ccErr, kit.Rid)
+		return nil, ccErr
+	}
+
+	if len(res.Info) != 1 {
+		blog.Errorf("object %d is not bound to field template %d, rid: %s", objectID, templateID, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.ObjectIDField)
+	}
+
+	if res.Info[0].Version == 0 {
+		return nil, nil
+	}
+
+	return t.GetFieldTemplateVersion(kit, templateID, res.Info[0].Version)
+}
@@ -0,0 +1,215 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fieldtmpl
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/coreservice"
+	fieldtmplapi "configcenter/src/apimachinery/coreservice/field_template"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// fakeClientSet is the client set that only implements the field template version and relation apis of core service
+type fakeClientSet struct {
+	apimachinery.ClientSetInterface
+	coreService *fakeCoreService
+}
+
+func (f *fakeClientSet) CoreService() coreservice.CoreServiceClientInterface {
+	return f.coreService
+}
+
+type fakeCoreService struct {
+	coreservice.CoreServiceClientInterface
+	fieldTemplate *fakeFieldTemplate
+}
+
+func (f *fakeCoreService) FieldTemplate() fieldtmplapi.Interface {
+	return f.fieldTemplate
+}
+
+type fakeFieldTemplate struct {
+	fieldtmplapi.Interface
+	versions  []metadata.FieldTemplateVersion
+	relations []metadata.ObjFieldTemplateRelation
+}
+
+func (f *fakeFieldTemplate) ListFieldTemplateVersion(_ context.Context, _ http.Header,
+	opt *metadata.CommonQueryOption) (*metadata.FieldTemplateVersionInfo, errors.CCErrorCoder) {
+
+	res := &metadata.FieldTemplateVersionInfo{Info: make([]metadata.FieldTemplateVersion, 0)}
+	for _, version := range f.versions {
+		data := filter.MapStr{common.BKTemplateID: version.TemplateID, common.BKVersion: version.Version}
+		if matched, err := opt.Filter.Match(data); err != nil || !matched {
+			continue
+		}
+		res.Info = append(res.Info, version)
+	}
+	return res, nil
+}
+
+func (f *fakeFieldTemplate) ListObjFieldTmplRel(_ context.Context, _ http.Header,
+	opt *metadata.CommonQueryOption) (*metadata.ObjFieldTmplRelInfo, errors.CCErrorCoder) {
+
+	res := &metadata.ObjFieldTmplRelInfo{Info: make([]metadata.ObjFieldTemplateRelation, 0)}
+	for _, relation := range f.relations {
+		data := filter.MapStr{common.BKTemplateID: relation.TemplateID, common.ObjectIDField: relation.ObjectID}
+		if matched, err := opt.Filter.Match(data); err != nil || !matched {
+			continue
+		}
+		res.Info = append(res.Info, relation)
+	}
+	return res, nil
+}
+
+func newTestTemplate(t *testing.T, fieldTemplate *fakeFieldTemplate) (*template, *rest.Kit) {
+	errFactory, err := errors.NewFactory("../../../../../resources/errors/")
+	if err != nil {
+		t.Fatalf("new error factory failed, err: %v", err)
+	}
+
+	kit := rest.NewKitFromHeader(http.Header{}, errFactory)
+	clientSet := &fakeClientSet{coreService: &fakeCoreService{fieldTemplate: fieldTemplate}}
+	return &template{clientSet: clientSet}, kit
+}
+
+func TestCompareFieldTemplateVersion(t *testing.T) {
+	from := metadata.FieldTemplateVersion{
+		TemplateID: 1,
+		Version:    1,
+		Attributes: []metadata.FieldTemplateAttr{
+			{ID: 1, PropertyID: "a", PropertyName: "A", PropertyType: "singlechar"},
+			{ID: 2, PropertyID: "b", PropertyName: "B", PropertyType: "int", PropertyIndex: 1},
+			{ID: 3, PropertyID: "c", PropertyName: "C", PropertyType: "bool", PropertyIndex: 2},
+		},
+		Uniques: []metadata.FieldTemplateUnique{
+			{FieldTmplUniqueCommonField: metadata.FieldTmplUniqueCommonField{ID: 1}, Keys: []int64{1}},
+			{FieldTmplUniqueCommonField: metadata.FieldTmplUniqueCommonField{ID: 2}, Keys: []int64{1, 2}},
+		},
+	}
+
+	to := metadata.FieldTemplateVersion{
+		TemplateID: 1,
+		Version:    2,
+		Attributes: []metadata.FieldTemplateAttr{
+			// index change only is not treated as an update
+			{ID: 1, PropertyID: "a", PropertyName: "A", PropertyType: "singlechar", PropertyIndex: 1},
+			{ID: 2, PropertyID: "b", PropertyName: "B2", PropertyType: "int", Unit: "s"},
+			{ID: 4, PropertyID: "d", PropertyName: "D", PropertyType: "singlechar", PropertyIndex: 2},
+		},
+		Uniques: []metadata.FieldTemplateUnique{
+			{FieldTmplUniqueCommonField: metadata.FieldTmplUniqueCommonField{ID: 2}, Keys: []int64{2, 4}},
+			{FieldTmplUniqueCommonField: metadata.FieldTmplUniqueCommonField{ID: 3}, Keys: []int64{4}},
+		},
+	}
+
+	// the version of another template with the same version number should not be compared
+	other := metadata.FieldTemplateVersion{TemplateID: 2, Version: 2}
+
+	tmpl, kit := newTestTemplate(t, &fakeFieldTemplate{versions: []metadata.FieldTemplateVersion{from, to, other}})
+	res, err := tmpl.CompareFieldTemplateVersion(kit,
+		&metadata.CompareFieldTmplVersionOption{TemplateID: 1, FromVersion: 1, ToVersion: 2})
+	if err != nil {
+		t.Fatalf("compare field template version failed, err: %v", err)
+	}
+
+	if len(res.Attributes.Create) != 1 || res.Attributes.Create[0].PropertyID != "d" {
+		t.Errorf("unexpected created attributes: %+v", res.Attributes.Create)
+	}
+
+	if len(res.Attributes.Delete) != 1 || res.Attributes.Delete[0].PropertyID != "c" {
+		t.Errorf("unexpected deleted attributes: %+v", res.Attributes.Delete)
+	}
+
+	if len(res.Attributes.Update) != 1 || res.Attributes.Update[0].PropertyID != "b" ||
+		!reflect.DeepEqual(res.Attributes.Update[0].ChangedFields, []string{"bk_property_name", "unit"}) {
+		t.Errorf("unexpected updated attributes: %+v", res.Attributes.Update)
+	}
+
+	wantUniques := metadata.FieldTmplVersionUniqueDiff{
+		Create: []metadata.FieldTmplUniqueForUpdate{{ID: 3, Keys: []string{"d"}}},
+		Update: []metadata.FieldTmplVersionUniqueChange{{ID: 2, Before: []string{"a", "b"},
+			After: []string{"b", "d"}}},
+		Delete: []metadata.FieldTmplUniqueForUpdate{{ID: 1, Keys: []string{"a"}}},
+	}
+	if !reflect.DeepEqual(res.Uniques, wantUniques) {
+		t.Errorf("unexpected unique difference: %+v, want: %+v", res.Uniques, wantUniques)
+	}
+
+	_, err = tmpl.CompareFieldTemplateVersion(kit,
+		&metadata.CompareFieldTmplVersionOption{TemplateID: 1, FromVersion: 1, ToVersion: 3})
+	if err == nil {
+		t.Errorf("compare with a version that does not exist should fail")
+	}
+}
+
+func TestCompareFieldTemplateVersionInvalidUnique(t *testing.T) {
+	version := metadata.FieldTemplateVersion{
+		TemplateID: 1,
+		Version:    1,
+		Attributes: []metadata.FieldTemplateAttr{{ID: 1, PropertyID: "a"}},
+		Uniques:    []metadata.FieldTemplateUnique{{Keys: []int64{2}}},
+	}
+
+	tmpl, kit := newTestTemplate(t, &fakeFieldTemplate{versions: []metadata.FieldTemplateVersion{version}})
+	_, err := tmpl.CompareFieldTemplateVersion(kit,
+		&metadata.CompareFieldTmplVersionOption{TemplateID: 1, FromVersion: 1, ToVersion: 1})
+	if err == nil {
+		t.Errorf("unique with unknown attribute key should be invalid")
+	}
+}
+
+func TestGetObjFieldTemplateVersion(t *testing.T) {
+	versions := []metadata.FieldTemplateVersion{
+		{TemplateID: 1, Version: 1, Attributes: []metadata.FieldTemplateAttr{{ID: 1, PropertyID: "a"}}},
+		{TemplateID: 1, Version: 2, Attributes: []metadata.FieldTemplateAttr{{ID: 1, PropertyID: "a"},
+			{ID: 2, PropertyID: "b"}}},
+	}
+	relations := []metadata.ObjFieldTemplateRelation{
+		{TemplateID: 1, ObjectID: 10},
+		{TemplateID: 1, ObjectID: 11, Version: 1},
+	}
+	tmpl, kit := newTestTemplate(t, &fakeFieldTemplate{versions: versions, relations: relations})
+
+	// the object that is not pinned to a version follows the current field template content
+	version, err := tmpl.GetObjFieldTemplateVersion(kit, 1, 10)
+	if err != nil || version != nil {
+		t.Errorf("object not pinned to a version should get no version, got %+v, err: %v", version, err)
+	}
+
+	// the pinned object is synced with its pinned version instead of the latest one
+	version, err = tmpl.GetObjFieldTemplateVersion(kit, 1, 11)
+	if err != nil {
+		t.Fatalf("get pinned object field template version failed, err: %v", err)
+	}
+	if version.Version != 1 || len(version.Attributes) != 1 {
+		t.Errorf("pinned object should get version 1, got %+v", version)
+	}
+
+	if _, err = tmpl.GetObjFieldTemplateVersion(kit, 1, 12); err == nil {
+		t.Errorf("object that is not bound to the field template should fail")
+	}
+}
@@ -0,0 +1,235 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fulltext
+
+import (
+	"errors"
+	"fmt"
+
+	"configcenter/src/common"
+	ccjson "configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+
+	"github.com/tidwall/gjson"
+)
+
+// modelDocIDPrefix is the prefix of the model document id in the inverted index.
+const modelDocIDPrefix = "model:"
+
+// nullMetaID is the meta_id of the model document, model is searched by meta_bk_obj_id instead.
+const nullMetaID = "0"
+
+// instanceDocID returns the document id of the instance in the inverted index.
+func instanceDocID(collection, oid string) string {
+	return collection + ":" + oid
+}
+
+// modelDocID returns the document id of the model in the inverted index.
+func modelDocID(objID string) string {
+	return modelDocIDPrefix + objID
+}
+
+// instanceIndex returns the index name and the instance id field of the instance collection.
+func instanceIndex(collection string) (string, string) {
+	switch collection {
+	case common.BKTableNameBaseBizSet:
+		return metadata.IndexNameBizSet, common.BKBizSetIDField
+	case common.BKTableNameBaseApp:
+		return metadata.IndexNameBiz, common.BKAppIDField
+	case common.BKTableNameBaseSet:
+		return metadata.IndexNameSet, common.BKSetIDField
+	case common.BKTableNameBaseModule:
+		return metadata.IndexNameModule, common.BKModuleIDField
+	case common.BKTableNameBaseHost:
+		return metadata.IndexNameHost, common.BKHostIDField
+	default:
+		return metadata.IndexNameObjectInstance, common.BKInstIDField
+	}
+}
+
+// cleanInstance removes the fields that do not need to be searched from the instance, the same as monstache.
+func cleanInstance(collection string, instance map[string]interface{}) map[string]interface{} {
+	doc := make(map[string]interface{}, len(instance))
+	for key, value := range instance {
+		doc[key] = value
+	}
+
+	// do not need to search "_id","create_time","last_time","bk_supplier_account" and "bk_parent_id".
+	delete(doc, "_id")
+	delete(doc, common.CreateTimeField)
+	delete(doc, common.LastTimeField)
+	delete(doc, common.BKOwnerIDField)
+	delete(doc, common.BKParentIDField)
+
+	switch collection {
+	case common.BKTableNameBaseBizSet:
+		delete(doc, common.BKDefaultField)
+		delete(doc, common.BKBizSetScopeField)
+
+	case common.BKTableNameBaseApp:
+		delete(doc, common.BKDefaultField)
+
+	case common.BKTableNameBaseSet:
+		delete(doc, common.BKAppIDField)
+		delete(doc, common.BKSetTemplateIDField)
+		delete(doc, common.BKDefaultField)
+
+	case common.BKTableNameBaseModule:
+		delete(doc, common.BKDefaultField)
+		delete(doc, common.BKSetTemplateIDField)
+		delete(doc, common.BKAppIDField)
+		delete(doc, common.BKSetIDField)
+		delete(doc, common.BKServiceCategoryIDField)
+
+	case common.BKTableNameBaseHost:
+		delete(doc, common.BKOperationTimeField)
+
+	default:
+		delete(doc, common.BKObjIDField)
+	}
+
+	return doc
+}
+
+// analysisKeywords returns all the leaf values of the document as the keywords without repetition.
+func analysisKeywords(doc interface{}) ([]string, error) {
+	jsonDoc, err := ccjson.MarshalToString(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return compressKeywords(analysisJSONKeywords(gjson.Parse(jsonDoc))), nil
+}
+
+// analysisJSONKeywords returns all the leaf values of the json.
+func analysisJSONKeywords(result gjson.Result) []string {
+	keywords := make([]string, 0)
+	if !result.IsObject() && !result.IsArray() {
+		keywords = append(keywords, result.String())
+		return keywords
+	}
+
+	result.ForEach(func(key, value gjson.Result) bool {
+		keywords = append(keywords, analysisJSONKeywords(value)...)
+		return true
+	})
+
+	return keywords
+}
+
+// compressKeywords returns the keywords without repetition.
+func compressKeywords(keywords []string) []string {
+	compressedKeywords := make([]string, 0)
+	keywordsMap := make(map[string]struct{})
+	for _, keyword := range keywords {
+		if _, exist := keywordsMap[keyword]; exist {
+			continue
+		}
+		compressedKeywords = append(compressedKeywords, keyword)
+		keywordsMap[keyword] = struct{}{}
+	}
+
+	return compressedKeywords
+}
+
+// buildInstanceDocument builds the search document of the instance, enums is the map of the enum property id to
+// its option id to name map, the enum values are searched by the option names.
+func buildInstanceDocument(collection, objID, oid string, instance map[string]interface{},
+	enums map[string]map[string]string) (*Document, error) {
+
+	index, idField := instanceIndex(collection)
+	if instance[idField] == nil {
+		return nil, fmt.Errorf("instance %s field is missing", idField)
+	}
+
+	doc := cleanInstance(collection, instance)
+	for propertyID, options := range enums {
+		if value, ok := doc[propertyID].(string); ok {
+			doc[propertyID] = options[value]
+		}
+	}
+
+	keywords, err := analysisKeywords(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	source := map[string]interface{}{
+		metadata.IndexPropertyID:                fmt.Sprintf("%v", instance[idField]),
+		metadata.IndexPropertyDataKind:          metadata.DataKindInstance,
+		metadata.IndexPropertyBKObjID:           objID,
+		metadata.IndexPropertyBKSupplierAccount: instance[common.BKOwnerIDField],
+		metadata.IndexPropertyBKBizID:           instance[common.BKAppIDField],
+	}
+
+	switch collection {
+	case common.BKTableNameBaseBizSet:
+		source[metadata.IndexPropertyBKBizSetID] = instance[common.BKBizSetIDField]
+		delete(source, metadata.IndexPropertyBKBizID)
+	case common.BKTableNameBaseSet:
+		source[metadata.IndexPropertyBKParentID] = instance[common.BKParentIDField]
+	case common.BKTableNameBaseHost:
+		source[metadata.IndexPropertyBKCloudID] = instance[common.BKCloudIDField]
+		delete(source, metadata.IndexPropertyBKBizID)
+	}
+
+	return &Document{
+		ID:       instanceDocID(collection, oid),
+		Index:    index,
+		Source:   source,
+		Keywords: keywords,
+	}, nil
+}
+
+// buildModelDocument builds the search document of the model, the model is searched by its id, name and the ids
+// and names of its attributes.
+func buildModelDocument(object *metadata.Object, attrs []*metadata.Attribute) (*Document, error) {
+	if object == nil || object.ObjectID == "" {
+		return nil, errors.New("model object id is missing")
+	}
+
+	values := []interface{}{map[string]interface{}{
+		common.BKObjIDField:   object.ObjectID,
+		common.BKObjNameField: object.ObjectName,
+	}}
+	for _, attr := range attrs {
+		if attr.ID == 0 {
+			continue
+		}
+		values = append(values, map[string]interface{}{
+			common.BKPropertyIDField:   attr.PropertyID,
+			common.BKPropertyNameField: attr.PropertyName,
+		})
+	}
+
+	keywords, err := analysisKeywords(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		ID:    modelDocID(object.ObjectID),
+		Index: metadata.IndexNameModel,
+		Source: map[string]interface{}{
+			metadata.IndexPropertyID:                nullMetaID,
+			metadata.IndexPropertyDataKind:          metadata.DataKindModel,
+			metadata.IndexPropertyBKObjID:           object.ObjectID,
+			metadata.IndexPropertyBKSupplierAccount: object.OwnerID,
+		},
+		Keywords: keywords,
+	}, nil
+}
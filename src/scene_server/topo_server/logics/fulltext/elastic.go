@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fulltext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/thirdparty/elasticsearch"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// elasticEngine is the fulltext search engine that searches the documents in elasticsearch.
+type elasticEngine struct {
+	es *elasticsearch.EsSrv
+}
+
+// NewElasticEngine creates a fulltext search engine based on elasticsearch.
+func NewElasticEngine(es *elasticsearch.EsSrv) Engine {
+	return &elasticEngine{es: es}
+}
+
+// Search search the documents in elasticsearch.
+func (e *elasticEngine) Search(ctx context.Context, query *Query, indexes []string, from, size int) (*SearchResult,
+	error) {
+
+	esQuery := e.buildQuery(query)
+	if source, err := esQuery.Source(); err == nil {
+		blog.V(5).Infof("fulltext elastic query[%s], indexes[%s], rid: %s", source, indexes,
+			util.ExtractRequestIDFromContext(ctx))
+	}
+
+	result, err := e.es.Search(ctx, esQuery, indexes, from, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Hits == nil || result.Hits.TotalHits == nil {
+		return nil, errors.New("invalid elastic search result")
+	}
+
+	searchResult := &SearchResult{
+		Total: result.Hits.TotalHits.Value,
+		Hits:  make([]*Hit, 0, len(result.Hits.Hits)),
+	}
+	for _, hit := range result.Hits.Hits {
+		source := make(map[string]interface{})
+		if err := json.Unmarshal(hit.Source, &source); err != nil {
+			blog.Warnf("fulltext handle search result source data failed, err: %v, rid: %s", err,
+				util.ExtractRequestIDFromContext(ctx))
+			continue
+		}
+		searchResult.Hits = append(searchResult.Hits, &Hit{Source: source, Highlight: hit.Highlight})
+	}
+
+	return searchResult, nil
+}
+
+// Count count the documents in elasticsearch.
+func (e *elasticEngine) Count(ctx context.Context, query *Query, indexes []string) (int64, error) {
+	return e.es.Count(ctx, e.buildQuery(query), indexes)
+}
+
+// buildQuery build the elastic query, the query_string searches both the keywords and the table keywords.
+func (e *elasticEngine) buildQuery(query *Query) elastic.Query {
+	boolQuery := elastic.NewBoolQuery()
+	if len(query.OwnerID) != 0 {
+		boolQuery.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKSupplierAccount, query.OwnerID))
+	}
+	if len(query.BizID) != 0 {
+		boolQuery.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKBizID, query.BizID))
+	}
+
+	// NOTE: 搜索文档同时支持属性和表格属性的keyword搜索
+	boolQuery.Must(elastic.NewQueryStringQuery(query.QueryString).
+		Field(metadata.IndexPropertyKeywords).
+		Field(tableFulltextRegex))
+
+	for property, values := range query.Conditions {
+		boolQuery.Must(elastic.NewTermsQuery(property, values...))
+	}
+
+	return boolQuery
+}
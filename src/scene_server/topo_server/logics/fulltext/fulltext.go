@@ -0,0 +1,72 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package fulltext provides the fulltext search engines of topo server, the elasticsearch engine searches the
+// documents synchronized by monstache, the local engine maintains an embedded inverted index by itself, so that the
+// fulltext search can be used without elasticsearch.
+package fulltext
+
+import (
+	"context"
+	"fmt"
+
+	"configcenter/src/common/metadata"
+)
+
+// tableFulltextRegex table fulltext search field regex.
+var tableFulltextRegex = fmt.Sprintf("%s...%s", metadata.TablePropertyName, metadata.IndexPropertyTypeKeyword)
+
+// Engine is the fulltext search engine interface.
+type Engine interface {
+	// Search returns the documents in the indexes that matches the query, from and size is the paging settings.
+	Search(ctx context.Context, query *Query, indexes []string, from, size int) (*SearchResult, error)
+
+	// Count returns the number of documents in the indexes that matches the query.
+	Count(ctx context.Context, query *Query, indexes []string) (int64, error)
+}
+
+// Query is the fulltext search query.
+type Query struct {
+	// OwnerID supplier account.
+	OwnerID string
+
+	// BizID business id.
+	BizID string
+
+	// QueryString escaped query_string keyword, e.g. *keyword*.
+	QueryString string
+
+	// Conditions index property to the values, the document property must be one of the values.
+	Conditions map[string][]interface{}
+}
+
+// Hit is a fulltext search hit document.
+type Hit struct {
+	// Source document source, which includes the index properties like meta_id, meta_bk_obj_id.
+	Source map[string]interface{}
+
+	// Highlight document field to the highlighted values.
+	Highlight map[string][]string
+}
+
+// SearchResult is the fulltext search result.
+type SearchResult struct {
+	// Total total number of the matched documents.
+	Total int64
+
+	// Hits the matched documents of the page.
+	Hits []*Hit
+}
@@ -0,0 +1,328 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fulltext
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// gramSize is the rune size of the grams in the inverted index, the keywords are split into grams so that the
+// documents can be searched by any substring of their keywords like the elastic wildcard query *keyword*.
+const gramSize = 3
+
+// Document is a searchable document of the inverted index, it's the same as the elastic document.
+type Document struct {
+	// ID unique id of the document in the index.
+	ID string
+
+	// Index name of the index that the document belongs to.
+	Index string
+
+	// Source document index properties like meta_id, meta_bk_obj_id, keywords are not included.
+	Source map[string]interface{}
+
+	// Keywords all the keywords of the document.
+	Keywords []string
+
+	// lowerKeywords the lower case keywords that are used to match the query.
+	lowerKeywords []string
+}
+
+// Index is an embedded inverted index that serves the fulltext search.
+type Index struct {
+	lock sync.RWMutex
+	docs map[string]*Document
+	// grams is the map of the gram to the ids of the documents whose keywords contains the gram.
+	grams map[string]map[string]struct{}
+}
+
+// NewIndex creates a new empty inverted index.
+func NewIndex() *Index {
+	return &Index{
+		docs:  make(map[string]*Document),
+		grams: make(map[string]map[string]struct{}),
+	}
+}
+
+// Upsert add the document to the index, or replace the document with the same id.
+func (idx *Index) Upsert(doc *Document) {
+	doc.lowerKeywords = make([]string, len(doc.Keywords))
+	for i, keyword := range doc.Keywords {
+		doc.lowerKeywords[i] = strings.ToLower(keyword)
+	}
+
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.remove(doc.ID)
+	idx.docs[doc.ID] = doc
+	for gram := range splitGrams(doc.lowerKeywords) {
+		if idx.grams[gram] == nil {
+			idx.grams[gram] = make(map[string]struct{})
+		}
+		idx.grams[gram][doc.ID] = struct{}{}
+	}
+}
+
+// Delete removes the document from the index.
+func (idx *Index) Delete(id string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	idx.remove(id)
+}
+
+// DeleteByObjID removes the model document and all the instance documents of the object from the index.
+func (idx *Index) DeleteByObjID(objID string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	for id, doc := range idx.docs {
+		if doc.Source[metadata.IndexPropertyBKObjID] == objID {
+			idx.remove(id)
+		}
+	}
+}
+
+// remove removes the document and its grams from the index, the caller must hold the write lock.
+func (idx *Index) remove(id string) {
+	doc, exists := idx.docs[id]
+	if !exists {
+		return
+	}
+
+	for gram := range splitGrams(doc.lowerKeywords) {
+		delete(idx.grams[gram], id)
+		if len(idx.grams[gram]) == 0 {
+			delete(idx.grams, gram)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Get returns the document with the id.
+func (idx *Index) Get(id string) (*Document, bool) {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	doc, exists := idx.docs[id]
+	return doc, exists
+}
+
+// Search returns the total number and the paged hits of the documents in the indexes that matches the query, the
+// hits are sorted by the relevance like elastic.
+func (idx *Index) Search(query *Query, indexes []string, from, size int) (int64, []*Hit) {
+	matches := idx.match(query, indexes)
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].doc.ID < matches[j].doc.ID
+	})
+
+	hits := make([]*Hit, 0)
+	for i := from; i < len(matches) && i < from+size; i++ {
+		source := make(map[string]interface{}, len(matches[i].doc.Source)+1)
+		for key, value := range matches[i].doc.Source {
+			source[key] = value
+		}
+		source[metadata.IndexPropertyKeywords] = matches[i].doc.Keywords
+
+		highlights := make([]string, len(matches[i].keywords))
+		for k, keyword := range matches[i].keywords {
+			highlights[k] = "<em>" + keyword + "</em>"
+		}
+
+		hits = append(hits, &Hit{
+			Source:    source,
+			Highlight: map[string][]string{metadata.IndexPropertyKeywords: highlights},
+		})
+	}
+
+	return int64(len(matches)), hits
+}
+
+// Count returns the number of the documents in the indexes that matches the query.
+func (idx *Index) Count(query *Query, indexes []string) int64 {
+	return int64(len(idx.match(query, indexes)))
+}
+
+// match is a matched document with its matched keywords and relevance score.
+type match struct {
+	doc      *Document
+	keywords []string
+	score    int
+}
+
+// match returns all the documents that matches the query.
+func (idx *Index) match(query *Query, indexes []string) []match {
+	terms := parseQueryString(query.QueryString)
+	if len(terms) == 0 {
+		return make([]match, 0)
+	}
+
+	indexMap := make(map[string]struct{}, len(indexes))
+	for _, index := range indexes {
+		indexMap[index] = struct{}{}
+	}
+
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	matches := make([]match, 0)
+	for id := range idx.candidates(terms) {
+		doc := idx.docs[id]
+		if _, exists := indexMap[doc.Index]; !exists {
+			continue
+		}
+
+		if !matchConditions(doc, query) {
+			continue
+		}
+
+		matched := match{doc: doc}
+		for i, keyword := range doc.lowerKeywords {
+			for _, term := range terms {
+				if !strings.Contains(keyword, term) {
+					continue
+				}
+				matched.keywords = append(matched.keywords, doc.Keywords[i])
+				matched.score++
+				// exact matched keyword is more relevant
+				if keyword == term {
+					matched.score += len(doc.Keywords)
+				}
+				break
+			}
+		}
+
+		if len(matched.keywords) > 0 {
+			matches = append(matches, matched)
+		}
+	}
+
+	return matches
+}
+
+// candidates returns the ids of the documents that may match the terms, a document matches if its keywords contains
+// any of the terms, so the candidates are the union of the candidates of each term.
+func (idx *Index) candidates(terms []string) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, term := range terms {
+		runes := []rune(term)
+		// the term is shorter than a gram, all the documents are candidates
+		if len(runes) < gramSize {
+			for id := range idx.docs {
+				result[id] = struct{}{}
+			}
+			return result
+		}
+
+		// the candidates of the term is the intersection of the documents of all its grams
+		var termIDs map[string]struct{}
+		for i := 0; i+gramSize <= len(runes); i++ {
+			ids := idx.grams[string(runes[i:i+gramSize])]
+			if termIDs == nil {
+				termIDs = ids
+				continue
+			}
+
+			intersection := make(map[string]struct{})
+			for id := range termIDs {
+				if _, exists := ids[id]; exists {
+					intersection[id] = struct{}{}
+				}
+			}
+			termIDs = intersection
+		}
+
+		for id := range termIDs {
+			result[id] = struct{}{}
+		}
+	}
+	return result
+}
+
+// matchConditions checks if the document matches the supplier account, business and property conditions.
+func matchConditions(doc *Document, query *Query) bool {
+	if len(query.OwnerID) != 0 &&
+		util.GetStrByInterface(doc.Source[metadata.IndexPropertyBKSupplierAccount]) != query.OwnerID {
+		return false
+	}
+
+	if len(query.BizID) != 0 && util.GetStrByInterface(doc.Source[metadata.IndexPropertyBKBizID]) != query.BizID {
+		return false
+	}
+
+	for property, values := range query.Conditions {
+		value := util.GetStrByInterface(doc.Source[property])
+		matched := false
+		for _, v := range values {
+			if util.GetStrByInterface(v) == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitGrams splits the keywords into the grams, the keyword that is shorter than a gram is a gram itself.
+func splitGrams(keywords []string) map[string]struct{} {
+	grams := make(map[string]struct{})
+	for _, keyword := range keywords {
+		runes := []rune(keyword)
+		if len(runes) < gramSize {
+			grams[keyword] = struct{}{}
+			continue
+		}
+
+		for i := 0; i+gramSize <= len(runes); i++ {
+			grams[string(runes[i:i+gramSize])] = struct{}{}
+		}
+	}
+	return grams
+}
+
+// parseQueryString parses the escaped query_string keyword like *key\-word* into the lower case terms to match, the
+// terms are separated by the whitespaces, and a document matches if it matches any of the terms.
+func parseQueryString(queryString string) []string {
+	raw := strings.Trim(queryString, "*")
+
+	unescaped := make([]rune, 0, len(raw))
+	escaped := false
+	for _, r := range raw {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		unescaped = append(unescaped, r)
+	}
+
+	return strings.Fields(strings.ToLower(string(unescaped)))
+}
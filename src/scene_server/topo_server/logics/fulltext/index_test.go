@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fulltext
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	idx := NewIndex()
+
+	hosts := []map[string]interface{}{
+		{common.BKHostIDField: int64(1), common.BKHostInnerIPField: "192.168.1.10", common.BKHostNameField: "web-01",
+			common.BKOwnerIDField: "0", common.BKCloudIDField: int64(0)},
+		{common.BKHostIDField: int64(2), common.BKHostInnerIPField: "192.168.1.11", common.BKHostNameField: "db-01",
+			common.BKOwnerIDField: "0", common.BKCloudIDField: int64(0)},
+	}
+	for i, host := range hosts {
+		doc, err := buildInstanceDocument(common.BKTableNameBaseHost, common.BKInnerObjIDHost, string(rune('a'+i)),
+			host, nil)
+		require.NoError(t, err)
+		idx.Upsert(doc)
+	}
+
+	inst := map[string]interface{}{common.BKInstIDField: int64(3), common.BKInstNameField: "switch-web",
+		common.BKObjIDField: "switch", common.BKOwnerIDField: "0", common.BKAppIDField: int64(5), "status": "1"}
+	enums := map[string]map[string]string{"status": {"1": "online"}}
+	doc, err := buildInstanceDocument(common.GetObjectInstTableName("switch", "0"), "switch", "c", inst, enums)
+	require.NoError(t, err)
+	idx.Upsert(doc)
+
+	model, err := buildModelDocument(&metadata.Object{ObjectID: "switch", ObjectName: "交换机", OwnerID: "0"},
+		[]*metadata.Attribute{{ID: 1, ObjectID: "switch", PropertyID: "vendor", PropertyName: "厂商"}})
+	require.NoError(t, err)
+	idx.Upsert(model)
+
+	return idx
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := newTestIndex(t)
+	all := []string{metadata.IndexNameHost, metadata.IndexNameObjectInstance, metadata.IndexNameModel}
+
+	total, hits := idx.Search(&Query{QueryString: `*192.168.1*`}, all, 0, 10)
+	require.EqualValues(t, 2, total)
+	require.Len(t, hits, 2)
+	require.Equal(t, "1", hits[0].Source[metadata.IndexPropertyID])
+	require.Equal(t, []string{"<em>192.168.1.10</em>"}, hits[0].Highlight[metadata.IndexPropertyKeywords])
+
+	// case insensitive search and paging
+	total, hits = idx.Search(&Query{QueryString: `*WEB*`}, all, 1, 10)
+	require.EqualValues(t, 2, total)
+	require.Len(t, hits, 1)
+
+	// exact matched keyword comes first
+	_, hits = idx.Search(&Query{QueryString: `*web\-01*`}, all, 0, 10)
+	require.Len(t, hits, 1)
+	require.Equal(t, common.BKInnerObjIDHost, hits[0].Source[metadata.IndexPropertyBKObjID])
+
+	// enum values are searched by the option name
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*online*"}, all))
+	require.EqualValues(t, 0, idx.Count(&Query{QueryString: "*1*"}, []string{metadata.IndexNameObjectInstance}))
+
+	// short keyword searches all the documents
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*厂商*"}, all))
+
+	// conditions
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*switch*", BizID: "5"}, all))
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*switch*",
+		Conditions: map[string][]interface{}{metadata.IndexPropertyDataKind: {metadata.DataKindModel}}}, all))
+	require.EqualValues(t, 0, idx.Count(&Query{QueryString: "*switch*"}, []string{metadata.IndexNameHost}))
+}
+
+func TestIndexUpsertDelete(t *testing.T) {
+	idx := newTestIndex(t)
+	all := []string{metadata.IndexNameHost}
+
+	host := map[string]interface{}{common.BKHostIDField: int64(1), common.BKHostInnerIPField: "10.0.0.1",
+		common.BKOwnerIDField: "0"}
+	doc, err := buildInstanceDocument(common.BKTableNameBaseHost, common.BKInnerObjIDHost, "a", host, nil)
+	require.NoError(t, err)
+	idx.Upsert(doc)
+
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*192.168*"}, all))
+	require.EqualValues(t, 1, idx.Count(&Query{QueryString: "*10.0.0*"}, all))
+
+	idx.Delete(instanceDocID(common.BKTableNameBaseHost, "a"))
+	require.EqualValues(t, 0, idx.Count(&Query{QueryString: "*10.0.0*"}, all))
+	_, exists := idx.Get(instanceDocID(common.BKTableNameBaseHost, "a"))
+	require.False(t, exists)
+}
+
+func TestIndexDeleteByObjID(t *testing.T) {
+	idx := newTestIndex(t)
+	all := []string{metadata.IndexNameHost, metadata.IndexNameObjectInstance, metadata.IndexNameModel}
+
+	inst := map[string]interface{}{common.BKInstIDField: int64(4), common.BKInstNameField: "switch-db",
+		common.BKObjIDField: "switch", common.BKOwnerIDField: "0"}
+	doc, err := buildInstanceDocument(common.GetObjectInstTableName("switch", "0"), "switch", "d", inst, nil)
+	require.NoError(t, err)
+	idx.Upsert(doc)
+	require.EqualValues(t, 2, idx.Count(&Query{QueryString: "*switch*"}, []string{metadata.IndexNameObjectInstance}))
+
+	idx.DeleteByObjID("switch")
+	require.EqualValues(t, 0, idx.Count(&Query{QueryString: "*switch*"}, all))
+	_, exists := idx.Get(modelDocID("switch"))
+	require.False(t, exists)
+
+	// the documents of the other objects are not removed
+	require.EqualValues(t, 2, idx.Count(&Query{QueryString: "*192.168.1*"}, all))
+}
@@ -0,0 +1,372 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fulltext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal/mongo/local"
+	"configcenter/src/storage/reflector"
+	"configcenter/src/storage/stream/types"
+)
+
+// localEngine is the fulltext search engine that searches the documents in an embedded inverted index, the index is
+// synchronized from the mongodb change stream of the models, attributes and instances.
+type localEngine struct {
+	ctx       context.Context
+	index     *Index
+	reflector reflector.Interface
+
+	lock sync.RWMutex
+	// objects is the map of the model document oid to the model, it's used to find the model when it's deleted.
+	objects map[string]*metadata.Object
+	// attrs is the map of the attribute document oid to the attribute.
+	attrs map[string]*metadata.Attribute
+	// resPoolBiz is the resource pool business ids, resource pool and its sets are not searched.
+	resPoolBiz map[int64]struct{}
+	// instWatchers is the map of the common object id to the cancel func of its instance table watcher.
+	instWatchers map[string]context.CancelFunc
+}
+
+// NewLocalEngine creates a fulltext search engine based on the embedded inverted index, and starts to synchronize
+// the index from mongodb in the background.
+func NewLocalEngine(ctx context.Context, conf local.MongoConf) (Engine, error) {
+	r, err := reflector.NewReflector(conf)
+	if err != nil {
+		return nil, fmt.Errorf("new reflector failed, err: %v", err)
+	}
+
+	e := &localEngine{
+		ctx:          ctx,
+		index:        NewIndex(),
+		reflector:    r,
+		objects:      make(map[string]*metadata.Object),
+		attrs:        make(map[string]*metadata.Attribute),
+		resPoolBiz:   make(map[int64]struct{}),
+		instWatchers: make(map[string]context.CancelFunc),
+	}
+
+	go e.run()
+	return e, nil
+}
+
+// Search search the documents in the inverted index.
+func (e *localEngine) Search(_ context.Context, query *Query, indexes []string, from, size int) (*SearchResult,
+	error) {
+
+	total, hits := e.index.Search(query, indexes, from, size)
+	return &SearchResult{Total: total, Hits: hits}, nil
+}
+
+// Count count the documents in the inverted index.
+func (e *localEngine) Count(_ context.Context, query *Query, indexes []string) (int64, error) {
+	return e.index.Count(query, indexes), nil
+}
+
+// run synchronizes the inverted index. the models, attributes and businesses are listed at first, because the enum
+// options and the resource pool businesses are needed when the instances are indexed.
+func (e *localEngine) run() {
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	if err := e.listWatch(e.ctx, common.BKTableNameObjDes, new(metadata.Object), e.onObjectChange,
+		e.onObjectDelete, wg.Done); err != nil {
+		blog.Errorf("list watch model for fulltext search failed, err: %v", err)
+		return
+	}
+
+	if err := e.listWatch(e.ctx, common.BKTableNameObjAttDes, new(metadata.Attribute), e.onAttributeChange,
+		e.onAttributeDelete, wg.Done); err != nil {
+		blog.Errorf("list watch attribute for fulltext search failed, err: %v", err)
+		return
+	}
+
+	if err := e.listWatchInstance(e.ctx, common.BKTableNameBaseApp, common.BKInnerObjIDApp, wg.Done); err != nil {
+		blog.Errorf("list watch biz for fulltext search failed, err: %v", err)
+		return
+	}
+
+	wg.Wait()
+
+	instances := map[string]string{
+		common.BKTableNameBaseBizSet: common.BKInnerObjIDBizSet,
+		common.BKTableNameBaseSet:    common.BKInnerObjIDSet,
+		common.BKTableNameBaseModule: common.BKInnerObjIDModule,
+		common.BKTableNameBaseHost:   common.BKInnerObjIDHost,
+	}
+	for collection, objID := range instances {
+		if err := e.listWatchInstance(e.ctx, collection, objID, nil); err != nil {
+			blog.Errorf("list watch %s for fulltext search failed, err: %v", objID, err)
+			return
+		}
+	}
+
+	e.lock.RLock()
+	objects := make([]*metadata.Object, 0, len(e.objects))
+	for _, object := range e.objects {
+		objects = append(objects, object)
+	}
+	e.lock.RUnlock()
+
+	for _, object := range objects {
+		e.watchObjectInstance(object)
+	}
+
+	blog.Infof("fulltext search local engine starts to synchronize all the instances")
+}
+
+// listWatch list and watch the collection, onChange is called when the document is listed, added or updated.
+func (e *localEngine) listWatch(ctx context.Context, collection string, eventStruct interface{},
+	onChange func(event *types.Event), onDelete func(event *types.Event), onListerDone func()) error {
+
+	opts := &types.ListWatchOptions{
+		Options: types.Options{
+			EventStruct: eventStruct,
+			Collection:  collection,
+		},
+	}
+
+	if onListerDone == nil {
+		onListerDone = func() {}
+	}
+
+	capable := &reflector.Capable{
+		OnChange: reflector.OnChangeEvent{
+			OnLister:     onChange,
+			OnAdd:        onChange,
+			OnUpdate:     onChange,
+			OnDelete:     onDelete,
+			OnListerDone: onListerDone,
+		},
+	}
+
+	return e.reflector.ListWatcher(ctx, opts, capable)
+}
+
+// listWatchInstance list and watch the instance collection of the object.
+func (e *localEngine) listWatchInstance(ctx context.Context, collection, objID string, onListerDone func()) error {
+	onChange := func(event *types.Event) {
+		// the watcher of the deleted object is cancelled, its documents are already removed from the index
+		if ctx.Err() != nil {
+			return
+		}
+
+		instance, ok := event.Document.(*map[string]interface{})
+		if !ok || instance == nil {
+			blog.Errorf("fulltext received invalid %s instance event, oid: %s", objID, event.Oid)
+			return
+		}
+		e.upsertInstance(collection, objID, event.Oid, *instance)
+	}
+
+	onDelete := func(event *types.Event) {
+		e.index.Delete(instanceDocID(collection, event.Oid))
+	}
+
+	return e.listWatch(ctx, collection, new(map[string]interface{}), onChange, onDelete, onListerDone)
+}
+
+// upsertInstance indexes the instance document.
+func (e *localEngine) upsertInstance(collection, objID, oid string, instance map[string]interface{}) {
+	switch collection {
+	case common.BKTableNameBaseApp:
+		bizID, _ := util.GetInt64ByInterface(instance[common.BKAppIDField])
+		if util.GetStrByInterface(instance[common.BKDefaultField]) == fmt.Sprint(common.DefaultAppFlag) {
+			e.lock.Lock()
+			e.resPoolBiz[bizID] = struct{}{}
+			e.lock.Unlock()
+			return
+		}
+
+	case common.BKTableNameBaseSet:
+		bizID, _ := util.GetInt64ByInterface(instance[common.BKAppIDField])
+		e.lock.RLock()
+		_, isResPool := e.resPoolBiz[bizID]
+		e.lock.RUnlock()
+		if isResPool {
+			return
+		}
+	}
+
+	doc, err := buildInstanceDocument(collection, objID, oid, instance, e.enumOptions(objID))
+	if err != nil {
+		blog.Errorf("build %s instance fulltext document failed, oid: %s, err: %v", objID, oid, err)
+		return
+	}
+	e.index.Upsert(doc)
+}
+
+// enumOptions returns the enum property id to its option id to name map of the object.
+func (e *localEngine) enumOptions(objID string) map[string]map[string]string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	enums := make(map[string]map[string]string)
+	for _, attr := range e.attrs {
+		if attr.ObjectID != objID || attr.PropertyType != common.FieldTypeEnum {
+			continue
+		}
+
+		options, err := metadata.ParseEnumOption(attr.Option)
+		if err != nil {
+			blog.Warnf("parse %s attribute %s enum option failed, err: %v", objID, attr.PropertyID, err)
+			continue
+		}
+
+		enums[attr.PropertyID] = make(map[string]string, len(options))
+		for _, option := range options {
+			enums[attr.PropertyID][option.ID] = option.Name
+		}
+	}
+	return enums
+}
+
+// onObjectChange indexes the model, and starts to watch the instances of the common object.
+func (e *localEngine) onObjectChange(event *types.Event) {
+	object, ok := event.Document.(*metadata.Object)
+	if !ok || object == nil {
+		blog.Errorf("fulltext received invalid model event, oid: %s", event.Oid)
+		return
+	}
+
+	e.lock.Lock()
+	e.objects[event.Oid] = object
+	e.lock.Unlock()
+
+	e.upsertModel(object.ObjectID)
+
+	// the instances of the listed objects are watched after all the objects are listed, the newly created object's
+	// instances are watched immediately.
+	if event.OperationType == types.Insert {
+		e.watchObjectInstance(object)
+	}
+}
+
+// onObjectDelete removes the model and its instances from the index.
+func (e *localEngine) onObjectDelete(event *types.Event) {
+	e.lock.Lock()
+	object, exists := e.objects[event.Oid]
+	delete(e.objects, event.Oid)
+	if exists {
+		if cancel, watching := e.instWatchers[object.ObjectID]; watching {
+			cancel()
+			delete(e.instWatchers, object.ObjectID)
+		}
+	}
+	e.lock.Unlock()
+
+	// the instance watcher is cancelled, so the instance documents are not deleted by their delete events, they
+	// are removed with the model document here.
+	if exists {
+		e.index.DeleteByObjID(object.ObjectID)
+	}
+}
+
+// onAttributeChange updates the model document of the attribute.
+func (e *localEngine) onAttributeChange(event *types.Event) {
+	attr, ok := event.Document.(*metadata.Attribute)
+	if !ok || attr == nil {
+		blog.Errorf("fulltext received invalid attribute event, oid: %s", event.Oid)
+		return
+	}
+
+	e.lock.Lock()
+	e.attrs[event.Oid] = attr
+	e.lock.Unlock()
+
+	e.upsertModel(attr.ObjectID)
+}
+
+// onAttributeDelete updates the model document of the deleted attribute.
+func (e *localEngine) onAttributeDelete(event *types.Event) {
+	e.lock.Lock()
+	attr, exists := e.attrs[event.Oid]
+	delete(e.attrs, event.Oid)
+	e.lock.Unlock()
+
+	if exists {
+		e.upsertModel(attr.ObjectID)
+	}
+}
+
+// upsertModel indexes the model document with its attributes.
+func (e *localEngine) upsertModel(objID string) {
+	// table objects are not searched as models
+	if metadata.GetModelQuoteSrcObjID(objID) != objID {
+		return
+	}
+
+	e.lock.RLock()
+	var object *metadata.Object
+	for _, obj := range e.objects {
+		if obj.ObjectID == objID {
+			object = obj
+			break
+		}
+	}
+
+	attrs := make([]*metadata.Attribute, 0)
+	for _, attr := range e.attrs {
+		if attr.ObjectID == objID {
+			attrs = append(attrs, attr)
+		}
+	}
+	e.lock.RUnlock()
+
+	// the model is not listed yet, it will be indexed when the model is listed.
+	if object == nil {
+		return
+	}
+
+	doc, err := buildModelDocument(object, attrs)
+	if err != nil {
+		blog.Errorf("build model %s fulltext document failed, err: %v", objID, err)
+		return
+	}
+	e.index.Upsert(doc)
+}
+
+// watchObjectInstance list and watch the instance table of the common object.
+func (e *localEngine) watchObjectInstance(object *metadata.Object) {
+	if common.IsInnerModel(object.ObjectID) || metadata.GetModelQuoteSrcObjID(object.ObjectID) != object.ObjectID {
+		return
+	}
+
+	e.lock.Lock()
+	if _, exists := e.instWatchers[object.ObjectID]; exists {
+		e.lock.Unlock()
+		return
+	}
+	watchCtx, cancel := context.WithCancel(e.ctx)
+	e.instWatchers[object.ObjectID] = cancel
+	e.lock.Unlock()
+
+	collection := common.GetObjectInstTableName(object.ObjectID, object.OwnerID)
+	if err := e.listWatchInstance(watchCtx, collection, object.ObjectID, nil); err != nil {
+		blog.Errorf("list watch %s instance for fulltext search failed, err: %v", object.ObjectID, err)
+		e.lock.Lock()
+		cancel()
+		delete(e.instWatchers, object.ObjectID)
+		e.lock.Unlock()
+	}
+}
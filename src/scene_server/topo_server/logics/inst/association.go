@@ -36,6 +36,8 @@ type AssociationOperationInterface interface {
 	DeleteInstAssociation(kit *rest.Kit, objID string, asstIDList []int64) (uint64, error)
 	// CheckAssociations returns error if the instances has associations with exist instances, clear dirty associations
 	CheckAssociations(*rest.Kit, string, []int64) error
+	// SearchInstAssociationGraph traverse the associations from the start instance hop by hop, returns the graph
+	SearchInstAssociationGraph(kit *rest.Kit, opt *metadata.InstAsstGraphOption) (*metadata.InstAsstGraph, error)
 
 	// SearchMainlineAssociationInstTopo search mainline association topo by objID and instID
 	SearchMainlineAssociationInstTopo(kit *rest.Kit, objID string, instID int64,
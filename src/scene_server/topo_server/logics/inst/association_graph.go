@@ -0,0 +1,373 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package inst
+
+import (
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// asstGraphNeighbor is an adjacent instance found from the current instance of the graph traversal
+type asstGraphNeighbor struct {
+	edge   metadata.InstAsstGraphEdge
+	cur    string
+	objID  string
+	instID int64
+}
+
+// SearchInstAssociationGraph traverse the associations from the start instance hop by hop, returns the graph of
+// the traversed instances and associations
+func (assoc *association) SearchInstAssociationGraph(kit *rest.Kit, opt *metadata.InstAsstGraphOption) (
+	*metadata.InstAsstGraph, error) {
+
+	builder := newAsstGraphBuilder(opt.ObjID, opt.InstID, opt.MaxNodes, opt.MaxEdges)
+
+	withMainline := opt.WithMainline && (len(opt.AsstKindIDs) == 0 ||
+		util.InStrArr(opt.AsstKindIDs, common.AssociationKindMainline))
+	var mainlineParent, mainlineChild map[string]string
+	if withMainline {
+		var err error
+		mainlineParent, mainlineChild, err = assoc.getMainlineObjectRelation(kit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	frontier := map[string][]int64{opt.ObjID: {opt.InstID}}
+	for depth := 0; depth < opt.Depth && len(frontier) > 0; depth++ {
+		objIDs := make([]string, 0, len(frontier))
+		for objID := range frontier {
+			objIDs = append(objIDs, objID)
+		}
+		sort.Strings(objIDs)
+
+		next := make(map[string][]int64)
+		for _, objID := range objIDs {
+			neighbors, err := assoc.findInstAsstNeighbors(kit, opt, objID, frontier[objID], builder)
+			if err != nil {
+				return nil, err
+			}
+
+			if withMainline {
+				mainlineNeighbors, err := assoc.findMainlineNeighbors(kit, opt.Direction, objID, frontier[objID],
+					mainlineParent, mainlineChild, builder)
+				if err != nil {
+					return nil, err
+				}
+				neighbors = append(neighbors, mainlineNeighbors...)
+			}
+
+			for _, neighbor := range neighbors {
+				if len(opt.ObjIDs) > 0 && !util.InStrArr(opt.ObjIDs, neighbor.objID) {
+					continue
+				}
+
+				if builder.addEdge(neighbor.edge, neighbor.cur, neighbor.objID, neighbor.instID) {
+					next[neighbor.objID] = append(next[neighbor.objID], neighbor.instID)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	graph := builder.graph()
+	if err := assoc.fillAsstGraphNodeName(kit, graph.Nodes); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// findInstAsstNeighbors find the instances that are associated with the instances of the object, at most the edge
+// quota of the builder associations are found
+func (assoc *association) findInstAsstNeighbors(kit *rest.Kit, opt *metadata.InstAsstGraphOption, objID string,
+	instIDs []int64, builder *asstGraphBuilder) ([]asstGraphNeighbor, error) {
+
+	outCond := mapstr.MapStr{
+		common.BKObjIDField:  objID,
+		common.BKInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+	}
+	inCond := mapstr.MapStr{
+		common.BKAsstObjIDField:  objID,
+		common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+	}
+
+	var cond mapstr.MapStr
+	switch opt.Direction {
+	case metadata.InstAsstDirectionOut:
+		cond = outCond
+	case metadata.InstAsstDirectionIn:
+		cond = inCond
+	default:
+		cond = mapstr.MapStr{common.BKDBOR: []mapstr.MapStr{outCond, inCond}}
+	}
+
+	if len(opt.AsstKindIDs) > 0 {
+		cond = mapstr.MapStr{common.BKDBAND: []mapstr.MapStr{cond,
+			{common.AssociationKindIDField: mapstr.MapStr{common.BKDBIN: opt.AsstKindIDs}}}}
+	}
+
+	limit := builder.edgeQuota()
+	input := &metadata.InstAsstQueryCondition{
+		ObjID: objID,
+		Cond: metadata.QueryCondition{
+			Condition:      cond,
+			Page:           metadata.BasePage{Limit: limit},
+			DisableCounter: true,
+		},
+	}
+
+	resp, err := assoc.clientSet.CoreService().Association().ReadInstAssociation(kit.Ctx, kit.Header, input)
+	if err != nil {
+		blog.Errorf("search instance associations failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+	builder.checkQuota(limit, len(resp.Info))
+
+	instIDMap := make(map[int64]struct{})
+	for _, instID := range instIDs {
+		instIDMap[instID] = struct{}{}
+	}
+
+	neighbors := make([]asstGraphNeighbor, 0)
+	for _, asst := range resp.Info {
+		edge := metadata.InstAsstGraphEdge{
+			AsstID:     asst.ID,
+			ObjAsstID:  asst.ObjectAsstID,
+			AsstKindID: asst.AssociationKindID,
+			From:       metadata.InstAsstGraphNodeID(asst.ObjectID, asst.InstID),
+			To:         metadata.InstAsstGraphNodeID(asst.AsstObjectID, asst.AsstInstID),
+		}
+
+		// an association between two instances of the same object may be found from both of them
+		_, isSrc := instIDMap[asst.InstID]
+		_, isDst := instIDMap[asst.AsstInstID]
+		if asst.ObjectID == objID && isSrc && opt.Direction != metadata.InstAsstDirectionIn {
+			neighbors = append(neighbors, asstGraphNeighbor{edge: edge, cur: edge.From, objID: asst.AsstObjectID,
+				instID: asst.AsstInstID})
+		}
+		if asst.AsstObjectID == objID && isDst && opt.Direction != metadata.InstAsstDirectionOut {
+			neighbors = append(neighbors, asstGraphNeighbor{edge: edge, cur: edge.To, objID: asst.ObjectID,
+				instID: asst.InstID})
+		}
+	}
+
+	return neighbors, nil
+}
+
+// getMainlineObjectRelation get the parent and child object of each mainline object
+func (assoc *association) getMainlineObjectRelation(kit *rest.Kit) (map[string]string, map[string]string, error) {
+	input := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.AssociationKindIDField: common.AssociationKindMainline},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+
+	resp, err := assoc.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, input)
+	if err != nil {
+		blog.Errorf("search mainline associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, nil, err
+	}
+
+	parent, child := make(map[string]string), make(map[string]string)
+	for _, asst := range resp.Info {
+		parent[asst.ObjectID] = asst.AsstObjID
+		child[asst.AsstObjID] = asst.ObjectID
+	}
+
+	return parent, child, nil
+}
+
+// findMainlineNeighbors find the mainline parents and children of the instances of the object, mainline relations
+// point from the child to the parent
+func (assoc *association) findMainlineNeighbors(kit *rest.Kit, direction metadata.InstAsstDirection, objID string,
+	instIDs []int64, parentObj, childObj map[string]string, builder *asstGraphBuilder) ([]asstGraphNeighbor, error) {
+
+	neighbors := make([]asstGraphNeighbor, 0)
+
+	if parentObjID, exists := parentObj[objID]; exists && direction != metadata.InstAsstDirectionIn {
+		limit := builder.edgeQuota()
+		var relations [][2]int64
+		var err error
+		if objID == common.BKInnerObjIDHost {
+			relations, err = assoc.findHostModuleRelation(kit, common.BKHostIDField, instIDs, limit)
+		} else {
+			relations, err = assoc.findMainlineParentRelation(kit, objID, common.BKFieldID, instIDs, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+		builder.checkQuota(limit, len(relations))
+
+		for _, relation := range relations {
+			edge := metadata.InstAsstGraphEdge{
+				AsstKindID: common.AssociationKindMainline,
+				From:       metadata.InstAsstGraphNodeID(objID, relation[0]),
+				To:         metadata.InstAsstGraphNodeID(parentObjID, relation[1]),
+			}
+			neighbors = append(neighbors, asstGraphNeighbor{edge: edge, cur: edge.From, objID: parentObjID,
+				instID: relation[1]})
+		}
+	}
+
+	if childObjID, exists := childObj[objID]; exists && direction != metadata.InstAsstDirectionOut {
+		limit := builder.edgeQuota()
+		var relations [][2]int64
+		var err error
+		if childObjID == common.BKInnerObjIDHost {
+			relations, err = assoc.findHostModuleRelation(kit, common.BKModuleIDField, instIDs, limit)
+		} else {
+			relations, err = assoc.findMainlineParentRelation(kit, childObjID, common.BKParentIDField, instIDs,
+				limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+		builder.checkQuota(limit, len(relations))
+
+		for _, relation := range relations {
+			edge := metadata.InstAsstGraphEdge{
+				AsstKindID: common.AssociationKindMainline,
+				From:       metadata.InstAsstGraphNodeID(childObjID, relation[0]),
+				To:         metadata.InstAsstGraphNodeID(objID, relation[1]),
+			}
+			neighbors = append(neighbors, asstGraphNeighbor{edge: edge, cur: edge.To, objID: childObjID,
+				instID: relation[0]})
+		}
+	}
+
+	return neighbors, nil
+}
+
+// findHostModuleRelation find host and module relations by the host ids or module ids, returns the relations as
+// [host id, module id] pairs
+func (assoc *association) findHostModuleRelation(kit *rest.Kit, field string, ids []int64, limit int) (
+	[][2]int64, error) {
+
+	input := &metadata.HostModuleRelationRequest{
+		Page:   metadata.BasePage{Limit: limit},
+		Fields: []string{common.BKHostIDField, common.BKModuleIDField},
+	}
+	if field == common.BKHostIDField {
+		input.HostIDArr = ids
+	} else {
+		input.ModuleIDArr = ids
+	}
+
+	resp, err := assoc.clientSet.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, input)
+	if err != nil {
+		blog.Errorf("get host module relation failed, input: %+v, err: %v, rid: %s", input, err, kit.Rid)
+		return nil, err
+	}
+
+	relations := make([][2]int64, 0, len(resp.Info))
+	for _, relation := range resp.Info {
+		relations = append(relations, [2]int64{relation.HostID, relation.ModuleID})
+	}
+
+	return relations, nil
+}
+
+// findMainlineParentRelation find the mainline instances of the object whose id or parent id field is in the
+// ids, returns the relations as [instance id, parent id] pairs
+func (assoc *association) findMainlineParentRelation(kit *rest.Kit, objID, field string, ids []int64,
+	limit int) ([][2]int64, error) {
+
+	idField := common.GetInstIDField(objID)
+	if field == common.BKFieldID {
+		field = idField
+	}
+
+	input := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{field: mapstr.MapStr{common.BKDBIN: ids}},
+		Fields:         []string{idField, common.BKParentIDField},
+		Page:           metadata.BasePage{Limit: limit},
+		DisableCounter: true,
+	}
+
+	resp, err := assoc.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, input)
+	if err != nil {
+		blog.Errorf("search %s instances failed, input: %+v, err: %v, rid: %s", objID, input, err, kit.Rid)
+		return nil, err
+	}
+
+	relations := make([][2]int64, 0, len(resp.Info))
+	for _, inst := range resp.Info {
+		instID, err := util.GetInt64ByInterface(inst[idField])
+		if err != nil {
+			blog.Errorf("parse %s instance id failed, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, idField)
+		}
+
+		parentID, err := util.GetInt64ByInterface(inst[common.BKParentIDField])
+		if err != nil {
+			blog.Errorf("parse %s instance parent id failed, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKParentIDField)
+		}
+
+		relations = append(relations, [2]int64{instID, parentID})
+	}
+
+	return relations, nil
+}
+
+// fillAsstGraphNodeName fill the instance names of the graph nodes
+func (assoc *association) fillAsstGraphNodeName(kit *rest.Kit, nodes []metadata.InstAsstGraphNode) error {
+	objInstIDs := make(map[string][]int64)
+	for _, node := range nodes {
+		objInstIDs[node.ObjID] = append(objInstIDs[node.ObjID], node.InstID)
+	}
+
+	instNames := make(map[string]string)
+	for objID, instIDs := range objInstIDs {
+		idField := common.GetInstIDField(objID)
+		nameField := common.GetInstNameField(objID)
+		input := &metadata.QueryCondition{
+			Condition:      mapstr.MapStr{idField: mapstr.MapStr{common.BKDBIN: instIDs}},
+			Fields:         []string{idField, nameField},
+			Page:           metadata.BasePage{Limit: len(instIDs)},
+			DisableCounter: true,
+		}
+
+		resp, err := assoc.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, input)
+		if err != nil {
+			blog.Errorf("search %s instances failed, input: %+v, err: %v, rid: %s", objID, input, err, kit.Rid)
+			return err
+		}
+
+		for _, inst := range resp.Info {
+			instID, err := util.GetInt64ByInterface(inst[idField])
+			if err != nil {
+				blog.Errorf("parse %s instance id failed, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+				return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, idField)
+			}
+			instNames[metadata.InstAsstGraphNodeID(objID, instID)] = util.GetStrByInterface(inst[nameField])
+		}
+	}
+
+	for idx := range nodes {
+		nodes[idx].InstName = instNames[nodes[idx].ID]
+	}
+
+	return nil
+}
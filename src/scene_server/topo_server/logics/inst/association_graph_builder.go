@@ -0,0 +1,166 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package inst
+
+import (
+	"sort"
+	"strings"
+
+	"configcenter/src/common/metadata"
+)
+
+// asstGraphBuilder builds the instance association graph in breadth first order, it deduplicates the nodes and
+// edges, records the shortest path of each node and detects the cycles
+type asstGraphBuilder struct {
+	maxNodes  int
+	maxEdges  int
+	nodes     map[string]*metadata.InstAsstGraphNode
+	nodeIDs   []string
+	edgeKeys  map[string]struct{}
+	edges     []metadata.InstAsstGraphEdge
+	cycleKeys map[string]struct{}
+	cycles    [][]string
+	truncated bool
+}
+
+func newAsstGraphBuilder(objID string, instID int64, maxNodes, maxEdges int) *asstGraphBuilder {
+	id := metadata.InstAsstGraphNodeID(objID, instID)
+	return &asstGraphBuilder{
+		maxNodes: maxNodes,
+		maxEdges: maxEdges,
+		nodes: map[string]*metadata.InstAsstGraphNode{
+			id: {ID: id, ObjID: objID, InstID: instID, Path: []string{id}},
+		},
+		nodeIDs:   []string{id},
+		edgeKeys:  make(map[string]struct{}),
+		edges:     make([]metadata.InstAsstGraphEdge, 0),
+		cycleKeys: make(map[string]struct{}),
+		cycles:    make([][]string, 0),
+	}
+}
+
+// edgeQuota returns the count of edges that can still be added, plus one so that the exceeding can be detected
+func (g *asstGraphBuilder) edgeQuota() int {
+	return g.maxEdges - len(g.edges) + 1
+}
+
+// checkQuota marks the graph as truncated if a query returns as many records as its quota, because the records
+// that are traversed before may be returned again and take up the quota, so the rest records may be missed
+func (g *asstGraphBuilder) checkQuota(quota, count int) {
+	if count >= quota {
+		g.truncated = true
+	}
+}
+
+// addEdge adds the edge that is found from the current node to the next node, returns true if the next node is
+// newly discovered and needs to be traversed in the next hop
+func (g *asstGraphBuilder) addEdge(edge metadata.InstAsstGraphEdge, cur string, nextObjID string,
+	nextInstID int64) bool {
+
+	edgeKey := strings.Join([]string{edge.From, edge.To, edge.AsstKindID, edge.ObjAsstID}, "|")
+	if _, exists := g.edgeKeys[edgeKey]; exists {
+		return false
+	}
+
+	if len(g.edges) >= g.maxEdges {
+		g.truncated = true
+		return false
+	}
+
+	curNode := g.nodes[cur]
+	next := metadata.InstAsstGraphNodeID(nextObjID, nextInstID)
+	nextNode, exists := g.nodes[next]
+	if !exists {
+		if len(g.nodes) >= g.maxNodes {
+			g.truncated = true
+			return false
+		}
+
+		path := make([]string, len(curNode.Path), len(curNode.Path)+1)
+		copy(path, curNode.Path)
+		g.nodes[next] = &metadata.InstAsstGraphNode{ID: next, ObjID: nextObjID, InstID: nextInstID,
+			Depth: curNode.Depth + 1, Path: append(path, next)}
+		g.nodeIDs = append(g.nodeIDs, next)
+	}
+
+	g.edgeKeys[edgeKey] = struct{}{}
+	g.edges = append(g.edges, edge)
+
+	if exists {
+		g.addCycle(curNode, nextNode)
+	}
+	return !exists
+}
+
+// addCycle records the cycle formed by the edge between two discovered nodes and their shortest paths, the
+// association direction is not considered, multiple associations between the same adjacent nodes are not cycles
+func (g *asstGraphBuilder) addCycle(cur, next *metadata.InstAsstGraphNode) {
+	if isParentPath(cur.Path, next.Path) || isParentPath(next.Path, cur.Path) {
+		return
+	}
+
+	commonLen := 0
+	for commonLen < len(cur.Path) && commonLen < len(next.Path) && cur.Path[commonLen] == next.Path[commonLen] {
+		commonLen++
+	}
+
+	// cycle starts from the last common ancestor, goes to the current node, then back to the ancestor by the next
+	cycle := make([]string, 0)
+	cycle = append(cycle, cur.Path[commonLen-1:]...)
+	for i := len(next.Path) - 1; i >= commonLen; i-- {
+		cycle = append(cycle, next.Path[i])
+	}
+
+	sorted := make([]string, len(cycle))
+	copy(sorted, cycle)
+	sort.Strings(sorted)
+	key := strings.Join(sorted, "|")
+	if _, exists := g.cycleKeys[key]; exists {
+		return
+	}
+	g.cycleKeys[key] = struct{}{}
+	g.cycles = append(g.cycles, cycle)
+}
+
+// isParentPath checks if the child path is the parent path plus one node
+func isParentPath(parent, child []string) bool {
+	if len(child) != len(parent)+1 {
+		return false
+	}
+
+	for i := range parent {
+		if parent[i] != child[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// graph returns the built instance association graph
+func (g *asstGraphBuilder) graph() *metadata.InstAsstGraph {
+	nodes := make([]metadata.InstAsstGraphNode, 0, len(g.nodeIDs))
+	for _, id := range g.nodeIDs {
+		nodes = append(nodes, *g.nodes[id])
+	}
+
+	return &metadata.InstAsstGraph{
+		Nodes:     nodes,
+		Edges:     g.edges,
+		Cycles:    g.cycles,
+		Truncated: g.truncated,
+	}
+}
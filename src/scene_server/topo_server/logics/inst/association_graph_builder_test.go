@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package inst
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestAsstGraphBuilder(t *testing.T) {
+	builder := newAsstGraphBuilder("app", 1, 10, 10)
+	edge := func(from, to string) metadata.InstAsstGraphEdge {
+		return metadata.InstAsstGraphEdge{AsstKindID: "connect", From: from, To: to}
+	}
+
+	// app:1 -> db:1, app:1 -> db:2, db:1 -> host:1, db:2 -> host:1 forms a cycle
+	if !builder.addEdge(edge("app:1", "db:1"), "app:1", "db", 1) {
+		t.Fatalf("expect db:1 is newly discovered")
+	}
+	if !builder.addEdge(edge("app:1", "db:2"), "app:1", "db", 2) {
+		t.Fatalf("expect db:2 is newly discovered")
+	}
+	if builder.addEdge(edge("app:1", "db:1"), "db:1", "app", 1) {
+		t.Fatalf("expect duplicate edge is ignored")
+	}
+	if !builder.addEdge(edge("db:1", "host:1"), "db:1", "host", 1) {
+		t.Fatalf("expect host:1 is newly discovered")
+	}
+	if builder.addEdge(edge("db:2", "host:1"), "db:2", "host", 1) {
+		t.Fatalf("expect host:1 is not newly discovered")
+	}
+
+	graph := builder.graph()
+	if len(graph.Nodes) != 4 || len(graph.Edges) != 4 || graph.Truncated {
+		t.Fatalf("unexpected graph: %+v", graph)
+	}
+
+	host := graph.Nodes[3]
+	if host.ID != "host:1" || host.Depth != 2 || !reflect.DeepEqual(host.Path, []string{"app:1", "db:1", "host:1"}) {
+		t.Errorf("unexpected host node: %+v", host)
+	}
+
+	expectCycles := [][]string{{"app:1", "db:2", "host:1", "db:1"}}
+	if !reflect.DeepEqual(graph.Cycles, expectCycles) {
+		t.Errorf("expect cycles %v, but got %v", expectCycles, graph.Cycles)
+	}
+
+	// another association between adjacent instances is not a cycle
+	if builder.addEdge(metadata.InstAsstGraphEdge{AsstKindID: "run", From: "app:1", To: "db:1"}, "app:1", "db",
+		1) {
+		t.Fatalf("expect db:1 is not newly discovered")
+	}
+	if len(builder.graph().Cycles) != 1 {
+		t.Errorf("expect multiple associations between adjacent instances is not a cycle")
+	}
+}
+
+func TestAsstGraphBuilderLimit(t *testing.T) {
+	builder := newAsstGraphBuilder("app", 1, 2, 10)
+	builder.addEdge(metadata.InstAsstGraphEdge{From: "app:1", To: "db:1"}, "app:1", "db", 1)
+	if builder.addEdge(metadata.InstAsstGraphEdge{From: "app:1", To: "db:2"}, "app:1", "db", 2) {
+		t.Fatalf("expect node limit is enforced")
+	}
+
+	graph := builder.graph()
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 1 || !graph.Truncated {
+		t.Errorf("unexpected graph: %+v", graph)
+	}
+}
+
+func TestAsstGraphBuilderDuplicateQuota(t *testing.T) {
+	builder := newAsstGraphBuilder("app", 1, 10, 3)
+	builder.addEdge(metadata.InstAsstGraphEdge{From: "app:1", To: "db:1"}, "app:1", "db", 1)
+	builder.addEdge(metadata.InstAsstGraphEdge{From: "db:2", To: "app:1"}, "app:1", "db", 2)
+
+	// the next hop queries the edges of db:1 and db:2 in both direction, the already traversed edges are found
+	// again and take up the quota, so the other edges of them are not returned by the query
+	quota := builder.edgeQuota()
+	found := []metadata.InstAsstGraphEdge{{From: "app:1", To: "db:1"}, {From: "db:2", To: "app:1"}}
+	if quota != len(found) {
+		t.Fatalf("unexpected edge quota %d", quota)
+	}
+	if builder.addEdge(found[0], "db:1", "app", 1) || builder.addEdge(found[1], "db:2", "app", 1) {
+		t.Fatalf("expect duplicate edges are ignored")
+	}
+	builder.checkQuota(quota, len(found))
+
+	graph := builder.graph()
+	if len(graph.Edges) != 2 || !graph.Truncated {
+		t.Errorf("expect graph is truncated when duplicate edges fill the quota, graph: %+v", graph)
+	}
+
+	// query that returns fewer records than the quota means that all the edges are found
+	builder = newAsstGraphBuilder("app", 1, 10, 3)
+	builder.checkQuota(builder.edgeQuota(), 1)
+	if builder.graph().Truncated {
+		t.Errorf("expect graph is not truncated")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	fieldtemplate "configcenter/src/scene_server/topo_server/logics/field_template"
 	"configcenter/src/scene_server/topo_server/logics/inst"
 	"configcenter/src/scene_server/topo_server/logics/kube"
+	"configcenter/src/scene_server/topo_server/logics/manifest"
 	"configcenter/src/scene_server/topo_server/logics/model"
 	modelquote "configcenter/src/scene_server/topo_server/logics/model_quote"
 	"configcenter/src/scene_server/topo_server/logics/operation"
@@ -51,6 +52,7 @@ type Logics interface {
 	ProjectOperation() inst.ProjectOperationInterface
 	ModelQuoteOperation() modelquote.ModelQuoteOperation
 	FieldTemplateOperation() fieldtemplate.FieldTemplateOperation
+	ManifestOperation() manifest.ManifestOperation
 }
 
 type logics struct {
@@ -73,6 +75,7 @@ type logics struct {
 	project           inst.ProjectOperationInterface
 	modelQuote        modelquote.ModelQuoteOperation
 	fieldTemplate     fieldtemplate.FieldTemplateOperation
+	manifest          manifest.ManifestOperation
 }
 
 // New create a logics manager
@@ -107,6 +110,19 @@ func New(client apimachinery.ClientSetInterface, authManager *extensions.AuthMan
 	businessOperation.SetProxy(instOperation, moduleOperation, setOperation)
 	businessSetOperation.SetProxy(instOperation)
 	projectOperation.SetProxy(instOperation)
+	fieldTemplateOperation := fieldtemplate.NewFieldTemplateOperation(client, associationOperation)
+	manifestOperation := manifest.NewManifestOperation(client, authManager, manifest.Dependence{
+		Classification: classificationOperation,
+		Object:         objectOperation,
+		Attribute:      attributeOperation,
+		Group:          groupOperation,
+		Association:    associationOperation,
+		FieldTemplate:  fieldTemplateOperation,
+		Business:       businessOperation,
+		Set:            setOperation,
+		Module:         moduleOperation,
+		Inst:           instOperation,
+	})
 	return &logics{
 		classification:    classificationOperation,
 		set:               setOperation,
@@ -126,7 +142,8 @@ func New(client apimachinery.ClientSetInterface, authManager *extensions.AuthMan
 		kube:              kubeOperation,
 		project:           projectOperation,
 		modelQuote:        modelquote.NewModelQuoteOperation(client),
-		fieldTemplate:     fieldtemplate.NewFieldTemplateOperation(client, associationOperation),
+		fieldTemplate:     fieldTemplateOperation,
+		manifest:          manifestOperation,
 	}
 }
 
@@ -224,3 +241,8 @@ func (l *logics) ModelQuoteOperation() modelquote.ModelQuoteOperation {
 func (l *logics) FieldTemplateOperation() fieldtemplate.FieldTemplateOperation {
 	return l.fieldTemplate
 }
+
+// ManifestOperation return an instance providing topology manifest operations
+func (l *logics) ManifestOperation() manifest.ManifestOperation {
+	return l.manifest
+}
@@ -0,0 +1,194 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	asstAliasNameField = "bk_obj_asst_name"
+	asstOnDeleteField  = "on_delete"
+)
+
+func (p *planner) planAssociations() error {
+	if len(p.manifest.Associations) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0)
+	objIDs := make([]string, 0)
+	asstKinds := make([]string, 0)
+	for _, asst := range p.manifest.Associations {
+		names = append(names, asst.AssociationName)
+		objIDs = append(objIDs, asst.ObjectID, asst.AsstObjID)
+		asstKinds = append(asstKinds, asst.AsstKindID)
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.AssociationObjAsstIDField: mapstr.MapStr{common.BKDBIN: names}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Association().ReadModelAssociation(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read model associations failed, names: %v, err: %v, rid: %s", names, err, p.kit.Rid)
+		return err
+	}
+	liveMap := make(map[string]metadata.Association)
+	for _, asst := range res.Info {
+		liveMap[asst.AssociationName] = asst
+	}
+
+	objects, err := p.getAsstObjects(objIDs)
+	if err != nil {
+		return err
+	}
+	kinds, err := p.getAsstKinds(asstKinds)
+	if err != nil {
+		return err
+	}
+
+	for idx := range p.manifest.Associations {
+		asst := p.manifest.Associations[idx]
+		live, exists := liveMap[asst.AssociationName]
+		if !exists {
+			p.planCreateAssociation(&asst, objects, kinds)
+			continue
+		}
+
+		p.planUpdateAssociation(&asst, &live)
+	}
+
+	return nil
+}
+
+// getAsstObjects returns the models used by the associations, including the live ones and the ones in the manifest
+func (p *planner) getAsstObjects(objIDs []string) (map[string]struct{}, error) {
+	objects := make(map[string]struct{})
+	for objID := range p.objects {
+		objects[objID] = struct{}{}
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKObjIDField: mapstr.MapStr{common.BKDBIN: objIDs}},
+		Fields:    []string{common.BKObjIDField},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Model().ReadModel(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read association models failed, obj ids: %v, err: %v, rid: %s", objIDs, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, obj := range res.Info {
+		objects[obj.ObjectID] = struct{}{}
+	}
+
+	return objects, nil
+}
+
+func (p *planner) getAsstKinds(asstKinds []string) (map[string]struct{}, error) {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.AssociationKindIDField: mapstr.MapStr{common.BKDBIN: asstKinds}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Association().ReadAssociationType(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read association kinds failed, kinds: %v, err: %v, rid: %s", asstKinds, err, p.kit.Rid)
+		return nil, err
+	}
+
+	kinds := make(map[string]struct{})
+	for _, kind := range res.Info {
+		kinds[kind.AssociationKindID] = struct{}{}
+	}
+	return kinds, nil
+}
+
+func (p *planner) planCreateAssociation(asst *metadata.ManifestAssociation, objects,
+	kinds map[string]struct{}) {
+
+	for _, objID := range []string{asst.ObjectID, asst.AsstObjID} {
+		if _, exists := objects[objID]; !exists {
+			p.addConflict(metadata.ManifestKindAssociation, asst.AssociationName, "model %s does not exist", objID)
+			return
+		}
+	}
+	if _, exists := kinds[asst.AsstKindID]; !exists {
+		p.addConflict(metadata.ManifestKindAssociation, asst.AssociationName, "association kind %s does not exist",
+			asst.AsstKindID)
+		return
+	}
+
+	p.addChange(metadata.ManifestKindAssociation, metadata.ManifestActionCreate, asst.AssociationName, nil,
+		func() error {
+			_, err := p.op.dep.Association.CreateCommonAssociation(p.kit, &metadata.Association{
+				OwnerID:              p.kit.SupplierAccount,
+				AssociationName:      asst.AssociationName,
+				AssociationAliasName: asst.AliasName,
+				ObjectID:             asst.ObjectID,
+				AsstObjID:            asst.AsstObjID,
+				AsstKindID:           asst.AsstKindID,
+				Mapping:              asst.Mapping,
+				OnDelete:             asst.OnDelete,
+			})
+			return err
+		})
+}
+
+func (p *planner) planUpdateAssociation(asst *metadata.ManifestAssociation, live *metadata.Association) {
+	if live.AsstKindID == common.AssociationKindMainline {
+		p.addConflict(metadata.ManifestKindAssociation, asst.AssociationName, "mainline association can not be "+
+			"managed by manifest")
+		return
+	}
+
+	if live.ObjectID != asst.ObjectID || live.AsstObjID != asst.AsstObjID || live.AsstKindID != asst.AsstKindID ||
+		live.Mapping != asst.Mapping {
+		p.addConflict(metadata.ManifestKindAssociation, asst.AssociationName, "the models, kind and mapping of "+
+			"association can not be changed")
+		return
+	}
+
+	want := make(mapstr.MapStr)
+	if len(asst.AliasName) != 0 {
+		want[asstAliasNameField] = asst.AliasName
+	}
+	if len(asst.OnDelete) != 0 {
+		want[asstOnDeleteField] = asst.OnDelete
+	}
+	liveData := mapstr.MapStr{
+		asstAliasNameField: live.AssociationAliasName,
+		asstOnDeleteField:  live.OnDelete,
+	}
+	diffs := diffFields(liveData, want)
+	if len(diffs) == 0 {
+		return
+	}
+
+	if live.IsPre != nil && *live.IsPre {
+		p.addConflict(metadata.ManifestKindAssociation, asst.AssociationName, "preset association can not be changed")
+		return
+	}
+
+	p.addChange(metadata.ManifestKindAssociation, metadata.ManifestActionUpdate, asst.AssociationName, diffs,
+		func() error {
+			return p.op.dep.Association.UpdateObjectAssociation(p.kit, diffData(diffs), live.ID)
+		})
+}
@@ -0,0 +1,216 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// toMapStr converts the manifest resource to map by its json form, the optional fields that are not set are
+// omitted, so they are left unmanaged when comparing with the live resource
+func toMapStr(value interface{}) (mapstr.MapStr, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(mapstr.MapStr)
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// normalizeValue converts the value to its json form, so that the values decoded from the db and from the
+// manifest can be compared, like int64 and float64 numbers
+func normalizeValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+// diffFields compares the fields in want with the live ones, the fields that are not in want are unmanaged
+func diffFields(live, want mapstr.MapStr) []metadata.ManifestFieldDiff {
+	fields := make([]string, 0, len(want))
+	for field := range want {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	diffs := make([]metadata.ManifestFieldDiff, 0)
+	for _, field := range fields {
+		oldVal, newVal := normalizeValue(live[field]), normalizeValue(want[field])
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		diffs = append(diffs, metadata.ManifestFieldDiff{Field: field, Old: oldVal, New: newVal})
+	}
+	return diffs
+}
+
+// diffData returns the data of the changed fields to update
+func diffData(diffs []metadata.ManifestFieldDiff) mapstr.MapStr {
+	data := make(mapstr.MapStr)
+	for _, diff := range diffs {
+		data[diff.Field] = diff.New
+	}
+	return data
+}
+
+// uniqueKey generates the key of the unique rule regardless of the order of its property ids
+func uniqueKey(keys []string) string {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+	return metadata.ManifestUniqueKey(sorted)
+}
+
+// liveAttrData converts the live attribute to map with all the fields of the manifest attribute
+func liveAttrData(attr *metadata.ManifestAttribute) mapstr.MapStr {
+	return mapstr.MapStr{
+		common.BKPropertyIDField:           attr.PropertyID,
+		common.BKPropertyNameField:         attr.PropertyName,
+		common.BKPropertyTypeField:         attr.PropertyType,
+		common.BKPropertyGroupField:        attr.PropertyGroup,
+		common.BKPropertyIndexField:        attr.PropertyIndex,
+		metadata.AttributeFieldUnit:        attr.Unit,
+		metadata.AttributeFieldPlaceHolder: attr.Placeholder,
+		metadata.AttributeFieldIsEditable:  attr.IsEditable,
+		common.BKIsRequiredField:           attr.IsRequired,
+		common.BKIsMultipleField:           attr.IsMultiple,
+		common.BKOptionField:               attr.Option,
+		common.BKDefaultField:              attr.Default,
+		common.BKDescriptionField:          attr.Description,
+	}
+}
+
+// attrFromModel converts the model attribute to the manifest attribute
+func attrFromModel(attr *metadata.Attribute) metadata.ManifestAttribute {
+	result := metadata.ManifestAttribute{
+		PropertyID:    attr.PropertyID,
+		PropertyName:  attr.PropertyName,
+		PropertyType:  attr.PropertyType,
+		PropertyGroup: attr.PropertyGroup,
+		PropertyIndex: attr.PropertyIndex,
+		Unit:          attr.Unit,
+		Placeholder:   attr.Placeholder,
+		IsEditable:    attr.IsEditable,
+		IsRequired:    attr.IsRequired,
+		Option:        attr.Option,
+		Default:       attr.Default,
+		Description:   attr.Description,
+	}
+	if attr.IsMultiple != nil {
+		result.IsMultiple = *attr.IsMultiple
+	}
+	return result
+}
+
+// attrToModel converts the manifest attribute to the model attribute
+func attrToModel(objID string, attr *metadata.ManifestAttribute) *metadata.Attribute {
+	isMultiple := attr.IsMultiple
+	return &metadata.Attribute{
+		ObjectID:      objID,
+		PropertyID:    attr.PropertyID,
+		PropertyName:  attr.PropertyName,
+		PropertyType:  attr.PropertyType,
+		PropertyGroup: attr.PropertyGroup,
+		PropertyIndex: attr.PropertyIndex,
+		Unit:          attr.Unit,
+		Placeholder:   attr.Placeholder,
+		IsEditable:    attr.IsEditable,
+		IsRequired:    attr.IsRequired,
+		IsMultiple:    &isMultiple,
+		Option:        attr.Option,
+		Default:       attr.Default,
+		Description:   attr.Description,
+	}
+}
+
+// attrFromTemplate converts the field template attribute to the manifest attribute
+func attrFromTemplate(attr *metadata.FieldTemplateAttr) metadata.ManifestAttribute {
+	return metadata.ManifestAttribute{
+		PropertyID:    attr.PropertyID,
+		PropertyName:  attr.PropertyName,
+		PropertyType:  attr.PropertyType,
+		PropertyIndex: attr.PropertyIndex,
+		Unit:          attr.Unit,
+		Placeholder:   attr.Placeholder.Value,
+		IsEditable:    attr.Editable.Value,
+		IsRequired:    attr.Required.Value,
+		IsMultiple:    attr.IsMultiple,
+		Option:        attr.Option,
+		Default:       attr.Default,
+	}
+}
+
+// attrToTemplate converts the manifest attribute to the field template attribute, the manifest does not manage
+// the locks of the template attribute, so the live locks are kept
+func attrToTemplate(attr *metadata.ManifestAttribute, live *metadata.FieldTemplateAttr) metadata.FieldTemplateAttr {
+	result := metadata.FieldTemplateAttr{
+		PropertyID:    attr.PropertyID,
+		PropertyType:  attr.PropertyType,
+		PropertyName:  attr.PropertyName,
+		PropertyIndex: attr.PropertyIndex,
+		Unit:          attr.Unit,
+		Placeholder:   metadata.AttrPlaceholder{Value: attr.Placeholder},
+		Editable:      metadata.AttrEditable{Value: attr.IsEditable},
+		Required:      metadata.AttrRequired{Value: attr.IsRequired},
+		Option:        attr.Option,
+		Default:       attr.Default,
+		IsMultiple:    attr.IsMultiple,
+	}
+
+	if live != nil {
+		result.ID = live.ID
+		result.TemplateID = live.TemplateID
+		result.Placeholder.Lock = live.Placeholder.Lock
+		result.Editable.Lock = live.Editable.Lock
+		result.Required.Lock = live.Required.Lock
+	}
+	return result
+}
+
+// mergeAttr overlays the managed fields of the manifest attribute on the live one
+func mergeAttr(live metadata.ManifestAttribute, want mapstr.MapStr) (metadata.ManifestAttribute, error) {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return live, err
+	}
+
+	if err := json.Unmarshal(data, &live); err != nil {
+		return live, err
+	}
+	return live, nil
+}
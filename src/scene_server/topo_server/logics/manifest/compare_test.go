@@ -0,0 +1,101 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFields(t *testing.T) {
+	live := mapstr.MapStr{
+		common.BKPropertyNameField:  "name",
+		common.BKPropertyIndexField: int64(3),
+		common.BKDescriptionField:   "unmanaged",
+	}
+	want := mapstr.MapStr{
+		common.BKPropertyNameField:  "new name",
+		common.BKPropertyIndexField: float64(3),
+		common.BKIsRequiredField:    true,
+	}
+
+	diffs := diffFields(live, want)
+	require.Equal(t, []metadata.ManifestFieldDiff{
+		{Field: common.BKPropertyNameField, Old: "name", New: "new name"},
+		{Field: common.BKIsRequiredField, Old: nil, New: true},
+	}, diffs)
+
+	require.Equal(t, mapstr.MapStr{common.BKIsRequiredField: true, common.BKPropertyNameField: "new name"},
+		diffData(diffs))
+	require.Empty(t, diffFields(live, mapstr.MapStr{common.BKPropertyIndexField: 3}))
+}
+
+func TestUniqueKey(t *testing.T) {
+	keys := []string{"b", "a", "c"}
+	require.Equal(t, uniqueKey([]string{"a", "b", "c"}), uniqueKey(keys))
+	require.Equal(t, []string{"b", "a", "c"}, keys)
+	require.NotEqual(t, uniqueKey([]string{"a", "b"}), uniqueKey(keys))
+}
+
+func TestMergeAttr(t *testing.T) {
+	live := metadata.ManifestAttribute{
+		PropertyID:   "port",
+		PropertyName: "Port",
+		PropertyType: common.FieldTypeInt,
+		Unit:         "",
+		Description:  "service port",
+		IsEditable:   true,
+	}
+
+	merged, err := mergeAttr(live, mapstr.MapStr{metadata.AttributeFieldUnit: "n", common.BKIsRequiredField: true})
+	require.NoError(t, err)
+	require.Equal(t, "n", merged.Unit)
+	require.True(t, merged.IsRequired)
+	require.True(t, merged.IsEditable)
+	require.Equal(t, "service port", merged.Description)
+	require.Equal(t, "", live.Unit)
+}
+
+func TestDigestPlan(t *testing.T) {
+	plan := &metadata.ManifestPlan{
+		Changes: []metadata.ManifestChange{{
+			Kind:   metadata.ManifestKindModel,
+			Action: metadata.ManifestActionUpdate,
+			Key:    "switch",
+			Fields: []metadata.ManifestFieldDiff{{Field: common.BKObjNameField, Old: "a", New: "b"}},
+		}},
+	}
+
+	digest, err := digestPlan(plan)
+	require.NoError(t, err)
+
+	// the digest does not depend on the digest field itself
+	plan.Digest = digest
+	again, err := digestPlan(plan)
+	require.NoError(t, err)
+	require.Equal(t, digest, again)
+
+	plan.Changes[0].Fields[0].New = "c"
+	changed, err := digestPlan(plan)
+	require.NoError(t, err)
+	require.NotEqual(t, digest, changed)
+}
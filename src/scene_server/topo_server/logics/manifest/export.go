@@ -0,0 +1,468 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// Export exports the live models and business topology to a manifest, the preset and the field template managed
+// attributes are not exported, and the hidden models are skipped
+func (m *manifest) Export(kit *rest.Kit, opt *metadata.ExportManifestOption) (*metadata.Manifest, error) {
+	result := &metadata.Manifest{Version: metadata.ManifestVersion}
+
+	classifications, err := m.exportModels(kit, opt.ObjectIDs, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.exportClassifications(kit, opt.ObjectIDs, classifications, result); err != nil {
+		return nil, err
+	}
+
+	if err := m.exportFieldTemplates(kit, result); err != nil {
+		return nil, err
+	}
+
+	if len(opt.BizNames) == 0 {
+		return result, nil
+	}
+
+	if err := m.exportBusinesses(kit, opt.BizNames, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m *manifest) exportClassifications(kit *rest.Kit, objIDs []string, used map[string]struct{},
+	result *metadata.Manifest) error {
+
+	cond := &metadata.QueryCondition{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	res, err := m.clientSet.CoreService().Model().ReadModelClassification(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read classifications failed, err: %v, rid: %s", err, kit.Rid)
+		return err
+	}
+
+	for _, cls := range res.Info {
+		// only the classifications of the exported models are exported when the models are specified
+		if _, exists := used[cls.ClassificationID]; len(objIDs) != 0 && !exists {
+			continue
+		}
+
+		result.Classifications = append(result.Classifications, metadata.ManifestClassification{
+			ID:   cls.ClassificationID,
+			Name: cls.ClassificationName,
+			Icon: cls.ClassificationIcon,
+		})
+	}
+	return nil
+}
+
+// exportModels exports the models with their groups, attributes, uniques and associations, returns the
+// classifications of the exported models
+func (m *manifest) exportModels(kit *rest.Kit, objIDs []string, result *metadata.Manifest) (map[string]struct{},
+	error) {
+
+	cond := mapstr.MapStr{metadata.ModelFieldIsHidden: mapstr.MapStr{common.BKDBNE: true}}
+	if len(objIDs) != 0 {
+		cond[common.BKObjIDField] = mapstr.MapStr{common.BKDBIN: objIDs}
+	}
+	objRes, err := m.clientSet.CoreService().Model().ReadModel(kit.Ctx, kit.Header,
+		&metadata.QueryCondition{Condition: cond, Page: metadata.BasePage{Limit: common.BKNoLimit}})
+	if err != nil {
+		blog.Errorf("read models failed, obj ids: %v, err: %v, rid: %s", objIDs, err, kit.Rid)
+		return nil, err
+	}
+
+	// the custom mainline models can not be created by manifest, so they are not exported
+	mainlineAssts, err := m.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header,
+		&metadata.QueryCondition{Condition: mapstr.MapStr{
+			common.AssociationKindIDField: common.AssociationKindMainline,
+		}})
+	if err != nil {
+		blog.Errorf("read mainline associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+	customMainline := make(map[string]struct{})
+	for _, asst := range mainlineAssts.Info {
+		if !common.IsInnerModel(asst.ObjectID) {
+			customMainline[asst.ObjectID] = struct{}{}
+		}
+	}
+
+	p := &planner{kit: kit, op: m, manifest: &metadata.Manifest{}}
+	for _, obj := range objRes.Info {
+		if _, exists := customMainline[obj.ObjectID]; exists {
+			continue
+		}
+		p.manifest.Models = append(p.manifest.Models, metadata.ManifestModel{ObjectID: obj.ObjectID})
+	}
+	if len(p.manifest.Models) == 0 {
+		return make(map[string]struct{}), nil
+	}
+
+	live, err := p.getLiveModels()
+	if err != nil {
+		return nil, err
+	}
+
+	classifications := make(map[string]struct{})
+	exported := make([]string, 0)
+	for _, obj := range objRes.Info {
+		if _, exists := customMainline[obj.ObjectID]; exists {
+			continue
+		}
+		classifications[obj.ObjCls] = struct{}{}
+		exported = append(exported, obj.ObjectID)
+		result.Models = append(result.Models, exportModel(&obj, live))
+	}
+
+	if err := m.exportAssociations(kit, exported, result); err != nil {
+		return nil, err
+	}
+	return classifications, nil
+}
+
+func exportModel(obj *metadata.Object, live *liveModels) metadata.ManifestModel {
+	model := metadata.ManifestModel{
+		ObjectID:       obj.ObjectID,
+		Name:           obj.ObjectName,
+		Classification: obj.ObjCls,
+		Icon:           obj.ObjIcon,
+	}
+
+	for _, group := range live.groups[obj.ObjectID] {
+		if group.IsDefault || group.IsPre {
+			continue
+		}
+		model.Groups = append(model.Groups, metadata.ManifestGroup{
+			ID:         group.GroupID,
+			Name:       group.GroupName,
+			Index:      group.GroupIndex,
+			IsCollapse: group.IsCollapse,
+		})
+	}
+	sort.Slice(model.Groups, func(i, j int) bool {
+		return model.Groups[i].Index < model.Groups[j].Index
+	})
+
+	attrs := make([]metadata.Attribute, 0)
+	propertyIDs := make(map[uint64]string)
+	for _, attr := range live.attrs[obj.ObjectID] {
+		propertyIDs[uint64(attr.ID)] = attr.PropertyID
+		if attr.IsPre || attr.TemplateID != 0 || attr.PropertyType == common.FieldTypeInnerTable {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].PropertyIndex != attrs[j].PropertyIndex {
+			return attrs[i].PropertyIndex < attrs[j].PropertyIndex
+		}
+		return attrs[i].ID < attrs[j].ID
+	})
+	for idx := range attrs {
+		model.Attributes = append(model.Attributes, attrFromModel(&attrs[idx]))
+	}
+
+	for _, unique := range live.uniques[obj.ObjectID] {
+		if unique.Ispre || unique.TemplateID != 0 {
+			continue
+		}
+
+		keys := make([]string, 0)
+		for _, key := range unique.Keys {
+			if propertyID, exists := propertyIDs[key.ID]; exists {
+				keys = append(keys, propertyID)
+			}
+		}
+		if len(keys) == len(unique.Keys) {
+			model.Uniques = append(model.Uniques, keys)
+		}
+	}
+
+	return model
+}
+
+func (m *manifest) exportAssociations(kit *rest.Kit, objIDs []string, result *metadata.Manifest) error {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKObjIDField:           mapstr.MapStr{common.BKDBIN: objIDs},
+			common.BKAsstObjIDField:       mapstr.MapStr{common.BKDBIN: objIDs},
+			common.AssociationKindIDField: mapstr.MapStr{common.BKDBNE: common.AssociationKindMainline},
+		},
+		Page: metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := m.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read model associations failed, err: %v, rid: %s", err, kit.Rid)
+		return err
+	}
+
+	for _, asst := range res.Info {
+		if asst.IsPre != nil && *asst.IsPre {
+			continue
+		}
+
+		result.Associations = append(result.Associations, metadata.ManifestAssociation{
+			AssociationName: asst.AssociationName,
+			AliasName:       asst.AssociationAliasName,
+			ObjectID:        asst.ObjectID,
+			AsstObjID:       asst.AsstObjID,
+			AsstKindID:      asst.AsstKindID,
+			Mapping:         asst.Mapping,
+			OnDelete:        asst.OnDelete,
+		})
+	}
+	return nil
+}
+
+func (m *manifest) exportFieldTemplates(kit *rest.Kit, result *metadata.Manifest) error {
+	opt := &metadata.CommonQueryOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	res, ccErr := m.clientSet.CoreService().FieldTemplate().ListFieldTemplate(kit.Ctx, kit.Header, opt)
+	if ccErr != nil {
+		blog.Errorf("list field templates failed, err: %v, rid: %s", ccErr, kit.Rid)
+		return ccErr
+	}
+	if len(res.Info) == 0 {
+		return nil
+	}
+
+	p := &planner{kit: kit, op: m, manifest: &metadata.Manifest{}}
+	for _, template := range res.Info {
+		p.manifest.FieldTemplates = append(p.manifest.FieldTemplates,
+			metadata.ManifestFieldTemplate{Name: template.Name})
+	}
+	liveMap, err := p.getLiveFieldTemplates()
+	if err != nil {
+		return err
+	}
+
+	for _, template := range res.Info {
+		live := liveMap[template.Name]
+		exported := metadata.ManifestFieldTemplate{
+			Name:        template.Name,
+			Description: template.Description,
+			Attributes:  make([]metadata.ManifestAttribute, 0),
+		}
+
+		propertyIDs := make(map[int64]string)
+		for _, attr := range live.attrs {
+			propertyIDs[attr.ID] = attr.PropertyID
+			exported.Attributes = append(exported.Attributes, attrFromTemplate(&attr))
+		}
+		sort.Slice(exported.Attributes, func(i, j int) bool {
+			return exported.Attributes[i].PropertyIndex < exported.Attributes[j].PropertyIndex
+		})
+
+		for _, unique := range live.uniques {
+			keys := make([]string, 0)
+			for _, id := range unique.Keys {
+				keys = append(keys, propertyIDs[id])
+			}
+			exported.Uniques = append(exported.Uniques, keys)
+		}
+
+		result.FieldTemplates = append(result.FieldTemplates, exported)
+	}
+	return nil
+}
+
+func (m *manifest) exportBusinesses(kit *rest.Kit, bizNames []string, result *metadata.Manifest) error {
+	p := &planner{
+		kit:        kit,
+		op:         m,
+		manifest:   &metadata.Manifest{},
+		bizIDs:     make(map[string]int64),
+		svcTmplIDs: make(map[string]int64),
+		setTmplIDs: make(map[string]int64),
+		setIDs:     make(map[string]int64),
+		plan:       &metadata.ManifestPlan{},
+	}
+	for _, name := range bizNames {
+		p.manifest.Businesses = append(p.manifest.Businesses, metadata.ManifestBusiness{Name: name})
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppNameField: mapstr.MapStr{common.BKDBIN: bizNames}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	bizRes, err := m.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDApp,
+		cond)
+	if err != nil {
+		blog.Errorf("read businesses failed, names: %v, err: %v, rid: %s", bizNames, err, kit.Rid)
+		return err
+	}
+
+	bizAttrs, err := m.getExportBizAttrs(kit)
+	if err != nil {
+		return err
+	}
+
+	for _, biz := range bizRes.Info {
+		name := util.GetStrByInterface(biz[common.BKAppNameField])
+		bizID, err := util.GetInt64ByInterface(biz[common.BKAppIDField])
+		if err != nil {
+			blog.Errorf("parse business id failed, biz: %v, err: %v, rid: %s", biz, err, kit.Rid)
+			return err
+		}
+
+		exported := metadata.ManifestBusiness{Name: name, Attributes: make(map[string]interface{})}
+		for _, propertyID := range bizAttrs {
+			if value, exists := biz[propertyID]; exists && value != nil {
+				exported.Attributes[propertyID] = value
+			}
+		}
+
+		if err := p.exportBizTemplates(name, bizID, result); err != nil {
+			return err
+		}
+		if exported.Sets, err = p.exportBizTopology(name, bizID); err != nil {
+			return err
+		}
+		result.Businesses = append(result.Businesses, exported)
+	}
+
+	return nil
+}
+
+// getExportBizAttrs returns the editable attributes of the business that can be managed by manifest
+func (m *manifest) getExportBizAttrs(kit *rest.Kit) ([]string, error) {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKObjIDField: common.BKInnerObjIDApp, common.BKAppIDField: 0},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := m.clientSet.CoreService().Model().ReadModelAttrByCondition(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read business attributes failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	propertyIDs := make([]string, 0)
+	for _, attr := range res.Info {
+		if !attr.IsEditable || attr.PropertyID == common.BKAppNameField ||
+			attr.PropertyType == common.FieldTypeInnerTable {
+			continue
+		}
+		propertyIDs = append(propertyIDs, attr.PropertyID)
+	}
+	return propertyIDs, nil
+}
+
+func (p *planner) exportBizTemplates(bizName string, bizID int64, result *metadata.Manifest) error {
+	categories, err := p.getCategoryPaths(bizID)
+	if err != nil {
+		return err
+	}
+	categoryPaths := make(map[int64]string)
+	for path, id := range categories {
+		categoryPaths[id] = path
+	}
+
+	opt := &metadata.ListServiceTemplateOption{BusinessID: bizID, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	res, err := p.op.clientSet.CoreService().Process().ListServiceTemplates(p.kit.Ctx, p.kit.Header, opt)
+	if err != nil {
+		blog.Errorf("list service templates failed, biz: %d, err: %v, rid: %s", bizID, err, p.kit.Rid)
+		return err
+	}
+	for _, template := range res.Info {
+		p.svcTmplIDs[metadata.ManifestKey(bizName, template.Name)] = template.ID
+		result.ServiceTemplates = append(result.ServiceTemplates, metadata.ManifestServiceTemplate{
+			BizName:  bizName,
+			Name:     template.Name,
+			Category: categoryPaths[template.ServiceCategoryID],
+		})
+	}
+
+	relations := make(map[string][]string)
+	if err := p.getLiveSetTemplates(bizName, bizID, relations); err != nil {
+		return err
+	}
+	names := make([]string, 0)
+	for key := range relations {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		result.SetTemplates = append(result.SetTemplates, metadata.ManifestSetTemplate{
+			BizName:          bizName,
+			Name:             strings.TrimPrefix(key, metadata.ManifestKey(bizName, "")),
+			ServiceTemplates: relations[key],
+		})
+	}
+
+	return nil
+}
+
+// exportBizTopology exports the sets and modules of the business, the idle set and the modules of the sets created
+// by set template are managed by the system, so they are not exported
+func (p *planner) exportBizTopology(bizName string, bizID int64) ([]metadata.ManifestSet, error) {
+	live, err := p.getLiveTopology(bizID)
+	if err != nil {
+		return nil, err
+	}
+
+	setTmplNames := bizResourceNames(bizName, p.setTmplIDs)
+	svcTmplNames := bizResourceNames(bizName, p.svcTmplIDs)
+
+	sets := make([]metadata.ManifestSet, 0)
+	for name, liveSet := range live.sets {
+		if isDefault, _ := util.GetInt64ByInterface(liveSet[common.BKDefaultField]); isDefault != 0 {
+			continue
+		}
+
+		setID, _ := util.GetInt64ByInterface(liveSet[common.BKSetIDField])
+		setTmplID, _ := util.GetInt64ByInterface(liveSet[common.BKSetTemplateIDField])
+		set := metadata.ManifestSet{Name: name, SetTemplate: setTmplNames[setTmplID]}
+		if setTmplID == common.SetTemplateIDNotSet {
+			for moduleName, module := range live.modules[setID] {
+				svcTmplID, _ := util.GetInt64ByInterface(module[common.BKServiceTemplateIDField])
+				set.Modules = append(set.Modules, metadata.ManifestModule{
+					Name:            moduleName,
+					ServiceTemplate: svcTmplNames[svcTmplID],
+				})
+			}
+			sort.Slice(set.Modules, func(i, j int) bool { return set.Modules[i].Name < set.Modules[j].Name })
+		}
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+
+	return sets, nil
+}
+
+// bizResourceNames returns the names of the resources of the business by their ids
+func bizResourceNames(bizName string, ids map[string]int64) map[int64]string {
+	prefix := metadata.ManifestKey(bizName, "")
+	names := make(map[int64]string)
+	for key, id := range ids {
+		if strings.HasPrefix(key, prefix) {
+			names[id] = strings.TrimPrefix(key, prefix)
+		}
+	}
+	return names
+}
@@ -0,0 +1,363 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	"configcenter/src/ac/iam"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+)
+
+// liveFieldTemplate is the live field template with its attributes and uniques
+type liveFieldTemplate struct {
+	template metadata.FieldTemplate
+	attrs    map[string]metadata.FieldTemplateAttr
+	uniques  []metadata.FieldTemplateUnique
+}
+
+func (p *planner) planFieldTemplates() error {
+	if len(p.manifest.FieldTemplates) == 0 {
+		return nil
+	}
+
+	liveMap, err := p.getLiveFieldTemplates()
+	if err != nil {
+		return err
+	}
+
+	for name, live := range liveMap {
+		p.fieldTmplIDs[name] = live.template.ID
+		for propertyID, attr := range live.attrs {
+			p.fieldTmplAttrIDs[metadata.ManifestKey(name, propertyID)] = attr.ID
+		}
+	}
+
+	for idx := range p.manifest.FieldTemplates {
+		template := &p.manifest.FieldTemplates[idx]
+		live, exists := liveMap[template.Name]
+		if !exists {
+			p.planCreateFieldTemplate(template)
+			continue
+		}
+
+		p.planUpdateFieldTemplate(template, live)
+	}
+
+	return nil
+}
+
+func (p *planner) getLiveFieldTemplates() (map[string]*liveFieldTemplate, error) {
+	names := make([]string, 0)
+	for _, template := range p.manifest.FieldTemplates {
+		names = append(names, template.Name)
+	}
+
+	opt := &metadata.CommonQueryOption{
+		CommonFilterOption: metadata.CommonFilterOption{
+			Filter: filtertools.GenAtomFilter(common.BKFieldName, filter.In, names),
+		},
+		Page: metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().FieldTemplate().ListFieldTemplate(p.kit.Ctx, p.kit.Header, opt)
+	if err != nil {
+		blog.Errorf("list field templates failed, names: %v, err: %v, rid: %s", names, err, p.kit.Rid)
+		return nil, err
+	}
+
+	liveMap := make(map[string]*liveFieldTemplate)
+	if len(res.Info) == 0 {
+		return liveMap, nil
+	}
+
+	ids := make([]int64, 0)
+	nameMap := make(map[int64]string)
+	for _, template := range res.Info {
+		liveMap[template.Name] = &liveFieldTemplate{
+			template: template,
+			attrs:    make(map[string]metadata.FieldTemplateAttr),
+		}
+		ids = append(ids, template.ID)
+		nameMap[template.ID] = template.Name
+	}
+
+	opt.Filter = filtertools.GenAtomFilter(common.BKTemplateID, filter.In, ids)
+	attrRes, err := p.op.clientSet.CoreService().FieldTemplate().ListFieldTemplateAttr(p.kit.Ctx, p.kit.Header, opt)
+	if err != nil {
+		blog.Errorf("list field template attributes failed, ids: %v, err: %v, rid: %s", ids, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, attr := range attrRes.Info {
+		liveMap[nameMap[attr.TemplateID]].attrs[attr.PropertyID] = attr
+	}
+
+	uniqueRes, err := p.op.clientSet.CoreService().FieldTemplate().ListFieldTemplateUnique(p.kit.Ctx, p.kit.Header,
+		opt)
+	if err != nil {
+		blog.Errorf("list field template uniques failed, ids: %v, err: %v, rid: %s", ids, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, unique := range uniqueRes.Info {
+		live := liveMap[nameMap[unique.TemplateID]]
+		live.uniques = append(live.uniques, unique)
+	}
+
+	return liveMap, nil
+}
+
+func (p *planner) planCreateFieldTemplate(template *metadata.ManifestFieldTemplate) {
+	opt := &metadata.CreateFieldTmplOption{
+		FieldTemplate: metadata.FieldTemplate{
+			Name:        template.Name,
+			Description: template.Description,
+			OwnerID:     p.kit.SupplierAccount,
+		},
+		Attributes: make([]metadata.FieldTemplateAttr, 0),
+		Uniques:    make([]metadata.FieldTmplUniqueOption, 0),
+	}
+
+	for idx := range template.Attributes {
+		attr := attrToTemplate(&template.Attributes[idx], nil)
+		if conflict := p.checkFieldTemplateAttr(template.Name, &attr); conflict {
+			return
+		}
+		opt.Attributes = append(opt.Attributes, attr)
+	}
+	for _, keys := range template.Uniques {
+		opt.Uniques = append(opt.Uniques, metadata.FieldTmplUniqueOption{Keys: keys})
+	}
+
+	if err := opt.Validate(); err.ErrCode != 0 {
+		p.addConflict(metadata.ManifestKindFieldTemplate, template.Name, "invalid field template: %s",
+			err.ToCCError(p.kit.CCError).Error())
+		return
+	}
+
+	p.addChange(metadata.ManifestKindFieldTemplate, metadata.ManifestActionCreate, template.Name, nil, func() error {
+		res, err := p.op.dep.FieldTemplate.CreateFieldTemplate(p.kit, opt)
+		if err != nil {
+			return err
+		}
+		p.fieldTmplIDs[template.Name] = res.ID
+		p.markFieldTmplChanged(res.ID)
+		return p.registerCreator(iam.FieldGroupingTemplate, res.ID, template.Name)
+	})
+}
+
+// checkFieldTemplateAttr checks the field template attribute, returns true and adds the conflict if it is invalid
+func (p *planner) checkFieldTemplateAttr(name string, attr *metadata.FieldTemplateAttr) bool {
+	key := metadata.ManifestKey(name, attr.PropertyID)
+	if attr.PropertyType == common.FieldTypeInnerTable {
+		p.addConflict(metadata.ManifestKindFieldTemplateAttr, key, "table attributes are not supported by manifest")
+		return true
+	}
+
+	if err := attr.ValidateBase(); err.ErrCode != 0 {
+		p.addConflict(metadata.ManifestKindFieldTemplateAttr, key, "invalid attribute: %s",
+			err.ToCCError(p.kit.CCError).Error())
+		return true
+	}
+	return false
+}
+
+func (p *planner) planUpdateFieldTemplate(template *metadata.ManifestFieldTemplate, live *liveFieldTemplate) {
+	if len(template.Description) != 0 && template.Description != live.template.Description {
+		diffs := []metadata.ManifestFieldDiff{{
+			Field: common.BKDescriptionField,
+			Old:   live.template.Description,
+			New:   template.Description,
+		}}
+		p.addChange(metadata.ManifestKindFieldTemplate, metadata.ManifestActionUpdate, template.Name, diffs,
+			func() error {
+				data := live.template
+				data.Description = template.Description
+				return p.op.dep.FieldTemplate.UpdateFieldTemplateInfo(p.kit, &data)
+			})
+	}
+
+	templateID := live.template.ID
+	for idx := range template.Attributes {
+		p.planFieldTemplateAttr(template.Name, templateID, &template.Attributes[idx], live.attrs)
+	}
+
+	propertyIDs := make(map[int64]string)
+	for _, attr := range live.attrs {
+		propertyIDs[attr.ID] = attr.PropertyID
+	}
+	uniqueKeys := make(map[string]struct{})
+	for _, unique := range live.uniques {
+		keys := make([]string, 0)
+		for _, id := range unique.Keys {
+			keys = append(keys, propertyIDs[id])
+		}
+		uniqueKeys[uniqueKey(keys)] = struct{}{}
+	}
+
+	attrs := make(map[string]struct{})
+	for propertyID := range live.attrs {
+		attrs[propertyID] = struct{}{}
+	}
+	for _, attr := range template.Attributes {
+		attrs[attr.PropertyID] = struct{}{}
+	}
+
+	for idx := range template.Uniques {
+		keys := template.Uniques[idx]
+		if _, exists := uniqueKeys[uniqueKey(keys)]; exists {
+			continue
+		}
+		p.planFieldTemplateUnique(template.Name, templateID, keys, attrs)
+	}
+}
+
+func (p *planner) planFieldTemplateAttr(name string, templateID int64, attr *metadata.ManifestAttribute,
+	liveAttrs map[string]metadata.FieldTemplateAttr) {
+
+	key := metadata.ManifestKey(name, attr.PropertyID)
+	live, exists := liveAttrs[attr.PropertyID]
+	if !exists {
+		data := attrToTemplate(attr, nil)
+		data.TemplateID = templateID
+		if p.checkFieldTemplateAttr(name, &data) {
+			return
+		}
+
+		p.addChange(metadata.ManifestKindFieldTemplateAttr, metadata.ManifestActionCreate, key, nil, func() error {
+			res, err := p.op.clientSet.CoreService().FieldTemplate().CreateFieldTemplateAttrs(p.kit.Ctx,
+				p.kit.Header, templateID, []metadata.FieldTemplateAttr{data})
+			if err != nil {
+				blog.Errorf("create field template %s attribute failed, err: %v, rid: %s", key, err, p.kit.Rid)
+				return err
+			}
+			p.fieldTmplAttrIDs[key] = res.IDs[0]
+			p.markFieldTmplChanged(templateID)
+
+			audit := auditlog.NewFieldTmplAuditLog(p.op.clientSet.CoreService())
+			generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditCreate)
+			auditLogs, auditErr := audit.GenerateFieldTmplAttrAuditLog(generateAuditParameter, res.IDs, nil)
+			if auditErr != nil {
+				blog.Errorf("generate field template attribute audit log failed, err: %v, rid: %s", auditErr,
+					p.kit.Rid)
+				return auditErr
+			}
+			return audit.SaveAuditLog(p.kit, auditLogs...)
+		})
+		return
+	}
+
+	want, err := toMapStr(attr)
+	if err != nil {
+		p.addConflict(metadata.ManifestKindFieldTemplateAttr, key, "invalid attribute: %v", err)
+		return
+	}
+	liveAttr := attrFromTemplate(&live)
+	diffs := diffFields(liveAttrData(&liveAttr), want)
+	if len(diffs) == 0 {
+		return
+	}
+
+	if live.PropertyType != attr.PropertyType {
+		p.addConflict(metadata.ManifestKindFieldTemplateAttr, key, "attribute type can not be changed from %s to %s",
+			live.PropertyType, attr.PropertyType)
+		return
+	}
+
+	merged, err := mergeAttr(liveAttr, diffData(diffs))
+	if err != nil {
+		p.addConflict(metadata.ManifestKindFieldTemplateAttr, key, "invalid attribute: %v", err)
+		return
+	}
+	data := attrToTemplate(&merged, &live)
+	if p.checkFieldTemplateAttr(name, &data) {
+		return
+	}
+
+	p.addChange(metadata.ManifestKindFieldTemplateAttr, metadata.ManifestActionUpdate, key, diffs, func() error {
+		err := p.op.clientSet.CoreService().FieldTemplate().UpdateFieldTemplateAttrs(p.kit.Ctx, p.kit.Header,
+			templateID, []metadata.FieldTemplateAttr{data})
+		if err != nil {
+			blog.Errorf("update field template %s attribute failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		p.markFieldTmplChanged(templateID)
+
+		audit := auditlog.NewFieldTmplAuditLog(p.op.clientSet.CoreService())
+		generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditUpdate)
+		auditLogs, auditErr := audit.GenerateFieldTmplAttrAuditLog(generateAuditParameter, nil,
+			[]metadata.FieldTemplateAttr{data})
+		if auditErr != nil {
+			blog.Errorf("generate field template attribute audit log failed, err: %v, rid: %s", auditErr, p.kit.Rid)
+			return auditErr
+		}
+		return audit.SaveAuditLog(p.kit, auditLogs...)
+	})
+}
+
+func (p *planner) planFieldTemplateUnique(name string, templateID int64, keys []string,
+	attrs map[string]struct{}) {
+
+	key := metadata.ManifestKey(name, uniqueKey(keys))
+	for _, propertyID := range keys {
+		if _, exists := attrs[propertyID]; !exists {
+			p.addConflict(metadata.ManifestKindFieldTemplateUnique, key, "attribute %s does not exist", propertyID)
+			return
+		}
+	}
+
+	p.addChange(metadata.ManifestKindFieldTemplateUnique, metadata.ManifestActionCreate, key, nil, func() error {
+		unique := metadata.FieldTemplateUnique{Keys: make([]int64, 0)}
+		unique.TemplateID = templateID
+		for _, propertyID := range keys {
+			id, exists := p.fieldTmplAttrIDs[metadata.ManifestKey(name, propertyID)]
+			if !exists {
+				blog.Errorf("field template %s attribute %s not found, rid: %s", name, propertyID, p.kit.Rid)
+				return p.kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "uniques."+propertyID)
+			}
+			unique.Keys = append(unique.Keys, id)
+		}
+
+		res, err := p.op.clientSet.CoreService().FieldTemplate().CreateFieldTemplateUniques(p.kit.Ctx, p.kit.Header,
+			templateID, []metadata.FieldTemplateUnique{unique})
+		if err != nil {
+			blog.Errorf("create field template %s unique failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		p.markFieldTmplChanged(templateID)
+
+		audit := auditlog.NewFieldTmplAuditLog(p.op.clientSet.CoreService())
+		generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditCreate)
+		auditLogs, auditErr := audit.GenerateFieldTmplUniqueAuditLog(generateAuditParameter, res.IDs, nil)
+		if auditErr != nil {
+			blog.Errorf("generate field template unique audit log failed, err: %v, rid: %s", auditErr, p.kit.Rid)
+			return auditErr
+		}
+		return audit.SaveAuditLog(p.kit, auditLogs...)
+	})
+}
+
+// markFieldTmplChanged marks the field template as changed, so that a new version of it is created after applying
+func (p *planner) markFieldTmplChanged(id int64) {
+	for _, changedID := range p.changedFieldTmplIDs {
+		if changedID == id {
+			return
+		}
+	}
+	p.changedFieldTmplIDs = append(p.changedFieldTmplIDs, id)
+}
@@ -0,0 +1,245 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package manifest plans and applies the declarative topology manifest against the live system
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"configcenter/src/ac/extensions"
+	"configcenter/src/ac/iam"
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	fieldtemplate "configcenter/src/scene_server/topo_server/logics/field_template"
+	"configcenter/src/scene_server/topo_server/logics/inst"
+	"configcenter/src/scene_server/topo_server/logics/model"
+)
+
+// ManifestOperation manifest operation methods
+type ManifestOperation interface {
+	// Export exports the live models and business topology to a manifest
+	Export(kit *rest.Kit, opt *metadata.ExportManifestOption) (*metadata.Manifest, error)
+	// Plan compares the manifest with the live system and returns the changes and conflicts
+	Plan(kit *rest.Kit, manifest *metadata.Manifest) (*metadata.ManifestPlan, error)
+	// Apply plans the manifest again and applies the changes if the plan still matches the digest,
+	// it should be called in a transaction
+	Apply(kit *rest.Kit, manifest *metadata.Manifest, digest string) (*metadata.ManifestPlan, error)
+}
+
+// Dependence is the other operations that the manifest operation depends on
+type Dependence struct {
+	Classification model.ClassificationOperationInterface
+	Object         model.ObjectOperationInterface
+	Attribute      model.AttributeOperationInterface
+	Group          model.GroupOperationInterface
+	Association    model.AssociationOperationInterface
+	FieldTemplate  fieldtemplate.FieldTemplateOperation
+	Business       inst.BusinessOperationInterface
+	Set            inst.SetOperationInterface
+	Module         inst.ModuleOperationInterface
+	Inst           inst.InstOperationInterface
+}
+
+// NewManifestOperation create a new manifest operation instance
+func NewManifestOperation(client apimachinery.ClientSetInterface, authManager *extensions.AuthManager,
+	dep Dependence) ManifestOperation {
+
+	return &manifest{
+		clientSet:   client,
+		authManager: authManager,
+		dep:         dep,
+	}
+}
+
+type manifest struct {
+	clientSet   apimachinery.ClientSetInterface
+	authManager *extensions.AuthManager
+	dep         Dependence
+}
+
+// Plan compares the manifest with the live system and returns the changes and conflicts
+func (m *manifest) Plan(kit *rest.Kit, manifest *metadata.Manifest) (*metadata.ManifestPlan, error) {
+	p, err := m.newPlanner(kit, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+// Apply plans the manifest again and applies the changes if the plan still matches the digest
+func (m *manifest) Apply(kit *rest.Kit, manifest *metadata.Manifest, digest string) (*metadata.ManifestPlan,
+	error) {
+
+	p, err := m.newPlanner(kit, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.plan.Conflicts) > 0 {
+		blog.Errorf("manifest has conflicts, conflicts: %+v, rid: %s", p.plan.Conflicts, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommManifestHasConflicts, len(p.plan.Conflicts))
+	}
+
+	if p.plan.Digest != digest {
+		blog.Errorf("manifest plan digest %s mismatch the applied one %s, rid: %s", p.plan.Digest, digest, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommManifestPlanChanged)
+	}
+
+	for idx, step := range p.steps {
+		if err := step(); err != nil {
+			change := p.plan.Changes[idx]
+			blog.Errorf("apply manifest change failed, kind: %s, action: %s, key: %s, err: %v, rid: %s",
+				change.Kind, change.Action, change.Key, err, kit.Rid)
+			return nil, err
+		}
+	}
+
+	for _, id := range p.changedFieldTmplIDs {
+		if _, err := m.dep.FieldTemplate.CreateFieldTemplateVersion(kit, id); err != nil {
+			blog.Errorf("create field template %d version failed, err: %v, rid: %s", id, err, kit.Rid)
+			return nil, err
+		}
+	}
+
+	return p.plan, nil
+}
+
+// planner compares the manifest with the live system, each change in the plan has a step to apply it
+type planner struct {
+	kit      *rest.Kit
+	op       *manifest
+	manifest *metadata.Manifest
+	plan     *metadata.ManifestPlan
+	steps    []func() error
+
+	// classifications and objects are the ids of the live or planned classifications and models
+	classifications map[string]struct{}
+	objects         map[string]struct{}
+
+	// the following maps are the ids of the resources by their keys in the manifest, they are filled with the
+	// live resources when planning and with the created ones when applying, so the later steps can refer to the
+	// resources created by the former ones
+	attrIDs          map[string]int64
+	fieldTmplIDs     map[string]int64
+	fieldTmplAttrIDs map[string]int64
+	bizIDs           map[string]int64
+	svcTmplIDs       map[string]int64
+	setTmplIDs       map[string]int64
+	setIDs           map[string]int64
+
+	// changedFieldTmplIDs are the ids of the field templates created or changed when applying, a new version of
+	// them is created after all the changes are applied, so that the models bound to them can be synced
+	changedFieldTmplIDs []int64
+}
+
+func (m *manifest) newPlanner(kit *rest.Kit, manifest *metadata.Manifest) (*planner, error) {
+	p := &planner{
+		kit:      kit,
+		op:       m,
+		manifest: manifest,
+		plan: &metadata.ManifestPlan{
+			Changes:   make([]metadata.ManifestChange, 0),
+			Conflicts: make([]metadata.ManifestConflict, 0),
+		},
+		steps:            make([]func() error, 0),
+		classifications:  make(map[string]struct{}),
+		objects:          make(map[string]struct{}),
+		attrIDs:          make(map[string]int64),
+		fieldTmplIDs:     make(map[string]int64),
+		fieldTmplAttrIDs: make(map[string]int64),
+		bizIDs:           make(map[string]int64),
+		svcTmplIDs:       make(map[string]int64),
+		setTmplIDs:       make(map[string]int64),
+		setIDs:           make(map[string]int64),
+	}
+
+	planFuncs := []func() error{p.planClassifications, p.planModels, p.planAssociations, p.planFieldTemplates,
+		p.planBusinesses, p.planServiceTemplates, p.planSetTemplates, p.planSets}
+	for _, planFunc := range planFuncs {
+		if err := planFunc(); err != nil {
+			return nil, err
+		}
+	}
+
+	digest, err := digestPlan(p.plan)
+	if err != nil {
+		blog.Errorf("generate manifest plan digest failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+	p.plan.Digest = digest
+
+	return p, nil
+}
+
+func (p *planner) addChange(kind metadata.ManifestKind, action metadata.ManifestAction, key string,
+	fields []metadata.ManifestFieldDiff, step func() error) {
+
+	p.plan.Changes = append(p.plan.Changes, metadata.ManifestChange{
+		Kind:   kind,
+		Action: action,
+		Key:    key,
+		Fields: fields,
+	})
+	p.steps = append(p.steps, step)
+}
+
+func (p *planner) addConflict(kind metadata.ManifestKind, key string, format string, args ...interface{}) {
+	p.plan.Conflicts = append(p.plan.Conflicts, metadata.ManifestConflict{
+		Kind:    kind,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// registerCreator registers the creator action of the created resource to iam
+func (p *planner) registerCreator(resType iam.TypeID, id int64, name string) error {
+	if !auth.EnableAuthorize() {
+		return nil
+	}
+
+	iamInstance := metadata.IamInstanceWithCreator{
+		Type:    string(resType),
+		ID:      strconv.FormatInt(id, 10),
+		Name:    name,
+		Creator: p.kit.User,
+	}
+	_, err := p.op.authManager.Authorizer.RegisterResourceCreatorAction(p.kit.Ctx, p.kit.Header, iamInstance)
+	if err != nil {
+		blog.Errorf("register created %s %d to iam failed, err: %v, rid: %s", resType, id, err, p.kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// digestPlan generates the digest of the changes and conflicts of the plan
+func digestPlan(plan *metadata.ManifestPlan) (string, error) {
+	data, err := json.Marshal(map[string]interface{}{"changes": plan.Changes, "conflicts": plan.Conflicts})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
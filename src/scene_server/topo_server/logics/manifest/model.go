@@ -0,0 +1,548 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"strconv"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/redis"
+)
+
+const (
+	// defaultIcon is the icon of the classification or model created by manifest without icon
+	defaultIcon = "icon-cc-default"
+	// defaultGroupID is the id of the default attribute group of the model
+	defaultGroupID = "default"
+)
+
+func (p *planner) planClassifications() error {
+	ids := make([]string, 0)
+	for _, cls := range p.manifest.Classifications {
+		ids = append(ids, cls.ID)
+	}
+	for _, model := range p.manifest.Models {
+		if len(model.Classification) != 0 {
+			ids = append(ids, model.Classification)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKClassificationIDField: mapstr.MapStr{common.BKDBIN: ids}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Model().ReadModelClassification(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read classifications failed, ids: %v, err: %v, rid: %s", ids, err, p.kit.Rid)
+		return err
+	}
+
+	liveMap := make(map[string]metadata.Classification)
+	for _, cls := range res.Info {
+		liveMap[cls.ClassificationID] = cls
+		p.classifications[cls.ClassificationID] = struct{}{}
+	}
+
+	for idx := range p.manifest.Classifications {
+		cls := p.manifest.Classifications[idx]
+		p.classifications[cls.ID] = struct{}{}
+
+		want := mapstr.MapStr{common.BKClassificationNameField: cls.Name}
+		if len(cls.Icon) != 0 {
+			want[common.BKClassificationIconField] = cls.Icon
+		}
+
+		live, exists := liveMap[cls.ID]
+		if !exists {
+			if len(cls.Icon) == 0 {
+				want[common.BKClassificationIconField] = defaultIcon
+			}
+			want[common.BKClassificationIDField] = cls.ID
+			p.addChange(metadata.ManifestKindClassification, metadata.ManifestActionCreate, cls.ID, nil,
+				func() error {
+					created, err := p.op.dep.Classification.CreateClassification(p.kit, want)
+					if err != nil {
+						return err
+					}
+					return p.registerCreator(iam.SysModelGroup, created.ID, created.ClassificationName)
+				})
+			continue
+		}
+
+		liveData := mapstr.MapStr{
+			common.BKClassificationNameField: live.ClassificationName,
+			common.BKClassificationIconField: live.ClassificationIcon,
+		}
+		diffs := diffFields(liveData, want)
+		if len(diffs) == 0 {
+			continue
+		}
+		p.addChange(metadata.ManifestKindClassification, metadata.ManifestActionUpdate, cls.ID, diffs,
+			func() error {
+				return p.op.dep.Classification.UpdateClassification(p.kit, diffData(diffs), live.ID)
+			})
+	}
+
+	return nil
+}
+
+// liveModels is the live models with their groups, attributes and uniques of the models in the manifest
+type liveModels struct {
+	objects map[string]metadata.Object
+	groups  map[string]map[string]metadata.Group
+	attrs   map[string]map[string]metadata.Attribute
+	uniques map[string][]metadata.ObjectUnique
+}
+
+func (p *planner) planModels() error {
+	if len(p.manifest.Models) == 0 {
+		return nil
+	}
+
+	live, err := p.getLiveModels()
+	if err != nil {
+		return err
+	}
+
+	for objID := range live.objects {
+		p.objects[objID] = struct{}{}
+	}
+	for objID, attrs := range live.attrs {
+		for propertyID, attr := range attrs {
+			p.attrIDs[metadata.ManifestKey(objID, propertyID)] = attr.ID
+		}
+	}
+
+	for idx := range p.manifest.Models {
+		model := &p.manifest.Models[idx]
+		p.objects[model.ObjectID] = struct{}{}
+		obj, exists := live.objects[model.ObjectID]
+		if !exists {
+			p.planCreateModel(model)
+		} else if !obj.IsPre {
+			p.planUpdateModel(model, &obj)
+		}
+
+		p.planGroups(model, live.groups[model.ObjectID])
+		p.planAttributes(model, live.groups[model.ObjectID], live.attrs[model.ObjectID])
+		if err := p.planUniques(model, exists, live.attrs[model.ObjectID],
+			live.uniques[model.ObjectID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) getLiveModels() (*liveModels, error) {
+	objIDs := make([]string, 0)
+	for _, model := range p.manifest.Models {
+		objIDs = append(objIDs, model.ObjectID)
+	}
+
+	live := &liveModels{
+		objects: make(map[string]metadata.Object),
+		groups:  make(map[string]map[string]metadata.Group),
+		attrs:   make(map[string]map[string]metadata.Attribute),
+		uniques: make(map[string][]metadata.ObjectUnique),
+	}
+
+	objCond := mapstr.MapStr{common.BKObjIDField: mapstr.MapStr{common.BKDBIN: objIDs}}
+	bizObjCond := mapstr.MapStr{common.BKObjIDField: mapstr.MapStr{common.BKDBIN: objIDs}, common.BKAppIDField: 0}
+	page := metadata.BasePage{Limit: common.BKNoLimit}
+
+	objRes, err := p.op.clientSet.CoreService().Model().ReadModel(p.kit.Ctx, p.kit.Header,
+		&metadata.QueryCondition{Condition: objCond, Page: page})
+	if err != nil {
+		blog.Errorf("read models failed, obj ids: %v, err: %v, rid: %s", objIDs, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, obj := range objRes.Info {
+		live.objects[obj.ObjectID] = obj
+	}
+
+	groupRes, err := p.op.clientSet.CoreService().Model().ReadAttributeGroupByCondition(p.kit.Ctx, p.kit.Header,
+		metadata.QueryCondition{Condition: bizObjCond, Page: page})
+	if err != nil {
+		blog.Errorf("read attribute groups failed, obj ids: %v, err: %v, rid: %s", objIDs, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, group := range groupRes.Info {
+		if _, exists := live.groups[group.ObjectID]; !exists {
+			live.groups[group.ObjectID] = make(map[string]metadata.Group)
+		}
+		live.groups[group.ObjectID][group.GroupID] = group
+	}
+
+	attrRes, err := p.op.clientSet.CoreService().Model().ReadModelAttrByCondition(p.kit.Ctx, p.kit.Header,
+		&metadata.QueryCondition{Condition: bizObjCond, Page: page})
+	if err != nil {
+		blog.Errorf("read attributes failed, obj ids: %v, err: %v, rid: %s", objIDs, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, attr := range attrRes.Info {
+		if _, exists := live.attrs[attr.ObjectID]; !exists {
+			live.attrs[attr.ObjectID] = make(map[string]metadata.Attribute)
+		}
+		live.attrs[attr.ObjectID][attr.PropertyID] = attr
+	}
+
+	uniqueRes, err := p.op.clientSet.CoreService().Model().ReadModelAttrUnique(p.kit.Ctx, p.kit.Header,
+		metadata.QueryCondition{Condition: objCond, Page: page})
+	if err != nil {
+		blog.Errorf("read uniques failed, obj ids: %v, err: %v, rid: %s", objIDs, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, unique := range uniqueRes.Info {
+		live.uniques[unique.ObjID] = append(live.uniques[unique.ObjID], unique)
+	}
+
+	return live, nil
+}
+
+func (p *planner) planCreateModel(model *metadata.ManifestModel) {
+	if _, exists := p.classifications[model.Classification]; !exists {
+		p.addConflict(metadata.ManifestKindModel, model.ObjectID, "classification %s does not exist",
+			model.Classification)
+		return
+	}
+
+	icon := model.Icon
+	if len(icon) == 0 {
+		icon = defaultIcon
+	}
+	data := mapstr.MapStr{
+		common.BKObjIDField:            model.ObjectID,
+		common.BKObjNameField:          model.Name,
+		common.BKClassificationIDField: model.Classification,
+		common.BKObjIconField:          icon,
+	}
+
+	p.addChange(metadata.ManifestKindModel, metadata.ManifestActionCreate, model.ObjectID, nil, func() error {
+		obj, err := p.op.dep.Object.CreateObject(p.kit, false, data)
+		if err != nil {
+			return err
+		}
+
+		if auth.EnableAuthorize() {
+			iamInstances := []metadata.IamInstanceWithCreator{{
+				Type:    string(iam.SysModel),
+				ID:      strconv.FormatInt(obj.ID, 10),
+				Name:    obj.ObjectName,
+				Creator: p.kit.User,
+			}}
+			err = p.op.authManager.CreateObjectOnIAM(p.kit.Ctx, p.kit.Header, []metadata.Object{*obj}, iamInstances,
+				redis.Client())
+			if err != nil {
+				blog.Errorf("create object %s on iam failed, err: %v, rid: %s", obj.ObjectID, err, p.kit.Rid)
+				return err
+			}
+		}
+
+		// the preset attributes are created with the model, they can be used by the unique rules
+		return p.loadAttrIDs(obj.ObjectID)
+	})
+}
+
+func (p *planner) loadAttrIDs(objID string) error {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKObjIDField: objID, common.BKAppIDField: 0},
+		Fields:    []string{common.BKFieldID, common.BKPropertyIDField},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Model().ReadModelAttrByCondition(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read attributes of object %s failed, err: %v, rid: %s", objID, err, p.kit.Rid)
+		return err
+	}
+
+	for _, attr := range res.Info {
+		p.attrIDs[metadata.ManifestKey(objID, attr.PropertyID)] = attr.ID
+	}
+	return nil
+}
+
+func (p *planner) planUpdateModel(model *metadata.ManifestModel, obj *metadata.Object) {
+	want := mapstr.MapStr{
+		common.BKObjNameField:          model.Name,
+		common.BKClassificationIDField: model.Classification,
+	}
+	if len(model.Icon) != 0 {
+		want[common.BKObjIconField] = model.Icon
+	}
+	liveData := mapstr.MapStr{
+		common.BKObjNameField:          obj.ObjectName,
+		common.BKClassificationIDField: obj.ObjCls,
+		common.BKObjIconField:          obj.ObjIcon,
+	}
+
+	diffs := diffFields(liveData, want)
+	if len(diffs) == 0 {
+		return
+	}
+
+	if _, exists := p.classifications[model.Classification]; !exists {
+		p.addConflict(metadata.ManifestKindModel, model.ObjectID, "classification %s does not exist",
+			model.Classification)
+		return
+	}
+
+	p.addChange(metadata.ManifestKindModel, metadata.ManifestActionUpdate, model.ObjectID, diffs, func() error {
+		return p.op.dep.Object.UpdateObject(p.kit, diffData(diffs), obj.ID)
+	})
+}
+
+func (p *planner) planGroups(model *metadata.ManifestModel, liveGroups map[string]metadata.Group) {
+	for idx := range model.Groups {
+		group := model.Groups[idx]
+		key := metadata.ManifestKey(model.ObjectID, group.ID)
+
+		live, exists := liveGroups[group.ID]
+		if !exists {
+			p.addChange(metadata.ManifestKindAttributeGroup, metadata.ManifestActionCreate, key, nil, func() error {
+				_, err := p.op.dep.Group.CreateObjectGroup(p.kit, &metadata.Group{
+					ObjectID:   model.ObjectID,
+					GroupID:    group.ID,
+					GroupName:  group.Name,
+					GroupIndex: group.Index,
+					IsCollapse: group.IsCollapse,
+					OwnerID:    p.kit.SupplierAccount,
+				})
+				return err
+			})
+			continue
+		}
+
+		want := mapstr.MapStr{
+			common.BKPropertyGroupNameField:  group.Name,
+			common.BKPropertyGroupIndexField: group.Index,
+		}
+		if group.IsCollapse {
+			want[common.BKIsCollapseField] = true
+		}
+		liveData := mapstr.MapStr{
+			common.BKPropertyGroupNameField:  live.GroupName,
+			common.BKPropertyGroupIndexField: live.GroupIndex,
+			common.BKIsCollapseField:         live.IsCollapse,
+		}
+		diffs := diffFields(liveData, want)
+		if len(diffs) == 0 {
+			continue
+		}
+
+		p.addChange(metadata.ManifestKindAttributeGroup, metadata.ManifestActionUpdate, key, diffs, func() error {
+			cond := &metadata.UpdateGroupCondition{}
+			cond.Condition.ID = live.ID
+			cond.Data.Name = &group.Name
+			cond.Data.Index = &group.Index
+			if group.IsCollapse {
+				cond.Data.IsCollapse = &group.IsCollapse
+			}
+			return p.op.dep.Group.UpdateObjectGroup(p.kit, cond)
+		})
+	}
+}
+
+func (p *planner) planAttributes(model *metadata.ManifestModel, liveGroups map[string]metadata.Group,
+	liveAttrs map[string]metadata.Attribute) {
+
+	groups := map[string]struct{}{defaultGroupID: {}}
+	for groupID := range liveGroups {
+		groups[groupID] = struct{}{}
+	}
+	for _, group := range model.Groups {
+		groups[group.ID] = struct{}{}
+	}
+
+	for idx := range model.Attributes {
+		attr := model.Attributes[idx]
+		key := metadata.ManifestKey(model.ObjectID, attr.PropertyID)
+
+		if attr.PropertyType == common.FieldTypeInnerTable {
+			p.addConflict(metadata.ManifestKindAttribute, key, "table attributes are not supported by manifest")
+			continue
+		}
+		if _, exists := groups[attr.PropertyGroup]; len(attr.PropertyGroup) != 0 && !exists {
+			p.addConflict(metadata.ManifestKindAttribute, key, "attribute group %s does not exist", attr.PropertyGroup)
+			continue
+		}
+
+		live, exists := liveAttrs[attr.PropertyID]
+		if !exists {
+			if len(attr.PropertyGroup) == 0 {
+				attr.PropertyGroup = defaultGroupID
+			}
+			p.addChange(metadata.ManifestKindAttribute, metadata.ManifestActionCreate, key, nil, func() error {
+				data := attrToModel(model.ObjectID, &attr)
+				data.OwnerID = p.kit.SupplierAccount
+				created, err := p.op.dep.Attribute.CreateObjectAttribute(p.kit, data)
+				if err != nil {
+					return err
+				}
+				p.attrIDs[key] = created.ID
+				return nil
+			})
+			continue
+		}
+
+		want, err := toMapStr(attr)
+		if err != nil {
+			blog.Errorf("convert attribute %s to map failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			p.addConflict(metadata.ManifestKindAttribute, key, "invalid attribute: %v", err)
+			continue
+		}
+		liveAttr := attrFromModel(&live)
+		diffs := diffFields(liveAttrData(&liveAttr), want)
+		if len(diffs) == 0 {
+			continue
+		}
+
+		switch {
+		case live.PropertyType != attr.PropertyType:
+			p.addConflict(metadata.ManifestKindAttribute, key, "attribute type can not be changed from %s to %s",
+				live.PropertyType, attr.PropertyType)
+		case live.IsPre:
+			p.addConflict(metadata.ManifestKindAttribute, key, "preset attribute can not be changed")
+		case live.TemplateID != 0:
+			p.addConflict(metadata.ManifestKindAttribute, key, "attribute is managed by field template %d",
+				live.TemplateID)
+		default:
+			p.addChange(metadata.ManifestKindAttribute, metadata.ManifestActionUpdate, key, diffs, func() error {
+				return p.op.dep.Attribute.UpdateObjectAttribute(p.kit, diffData(diffs), live.ID, 0, false)
+			})
+		}
+	}
+}
+
+func (p *planner) planUniques(model *metadata.ManifestModel, objExists bool, liveAttrs map[string]metadata.Attribute,
+	liveUniques []metadata.ObjectUnique) error {
+
+	propertyIDs := make(map[uint64]string)
+	for _, attr := range liveAttrs {
+		propertyIDs[uint64(attr.ID)] = attr.PropertyID
+	}
+
+	uniqueKeys := make(map[string]struct{})
+	for _, unique := range liveUniques {
+		keys := make([]string, 0)
+		for _, key := range unique.Keys {
+			keys = append(keys, propertyIDs[key.ID])
+		}
+		uniqueKeys[uniqueKey(keys)] = struct{}{}
+	}
+	if !objExists {
+		// the unique rule of the instance name is created with the model
+		uniqueKeys[uniqueKey([]string{common.GetInstNameField(model.ObjectID)})] = struct{}{}
+	}
+
+	attrs := make(map[string]struct{})
+	for propertyID := range liveAttrs {
+		attrs[propertyID] = struct{}{}
+	}
+	for _, attr := range model.Attributes {
+		attrs[attr.PropertyID] = struct{}{}
+	}
+	attrs[common.GetInstNameField(model.ObjectID)] = struct{}{}
+
+	for idx := range model.Uniques {
+		keys := model.Uniques[idx]
+		key := metadata.ManifestKey(model.ObjectID, uniqueKey(keys))
+		if _, exists := uniqueKeys[uniqueKey(keys)]; exists {
+			continue
+		}
+
+		if conflict, err := p.checkUnique(model.ObjectID, keys, objExists, attrs); err != nil {
+			return err
+		} else if len(conflict) != 0 {
+			p.addConflict(metadata.ManifestKindUnique, key, conflict)
+			continue
+		}
+
+		p.addChange(metadata.ManifestKindUnique, metadata.ManifestActionCreate, key, nil, func() error {
+			return p.createUnique(model.ObjectID, keys)
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) checkUnique(objID string, keys []string, objExists bool, attrs map[string]struct{}) (string,
+	error) {
+
+	for _, propertyID := range keys {
+		if _, exists := attrs[propertyID]; !exists {
+			return "attribute " + propertyID + " does not exist", nil
+		}
+	}
+
+	if !objExists || objID == common.BKInnerObjIDHost {
+		return "", nil
+	}
+
+	// the unique rules of the mainline models can not be changed
+	isMainline, err := p.op.dep.Association.IsMainlineObject(p.kit, objID)
+	if err != nil {
+		blog.Errorf("check if object %s is mainline failed, err: %v, rid: %s", objID, err, p.kit.Rid)
+		return "", err
+	}
+	if isMainline {
+		return "unique rules of mainline model can not be changed", nil
+	}
+	return "", nil
+}
+
+func (p *planner) createUnique(objID string, keys []string) error {
+	uniqueKeys := make([]metadata.UniqueKey, 0)
+	for _, propertyID := range keys {
+		id, exists := p.attrIDs[metadata.ManifestKey(objID, propertyID)]
+		if !exists {
+			blog.Errorf("attribute %s of object %s not found, rid: %s", propertyID, objID, p.kit.Rid)
+			return p.kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "uniques."+propertyID)
+		}
+		uniqueKeys = append(uniqueKeys, metadata.UniqueKey{Kind: metadata.UniqueKeyKindProperty, ID: uint64(id)})
+	}
+
+	unique := metadata.CreateModelAttrUnique{Data: metadata.ObjectUnique{ObjID: objID, Keys: uniqueKeys}}
+	rsp, err := p.op.clientSet.CoreService().Model().CreateModelAttrUnique(p.kit.Ctx, p.kit.Header, objID, unique)
+	if err != nil {
+		blog.Errorf("create unique for object %s failed, keys: %v, err: %v, rid: %s", objID, keys, err, p.kit.Rid)
+		return err
+	}
+
+	audit := auditlog.NewObjectUniqueAuditLog(p.op.clientSet.CoreService())
+	generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditCreate)
+	auditLog, err := audit.GenerateAuditLog(generateAuditParameter, int64(rsp.Created.ID), nil)
+	if err != nil {
+		blog.Errorf("generate unique audit log failed, err: %v, rid: %s", err, p.kit.Rid)
+		return err
+	}
+
+	if err := audit.SaveAuditLog(p.kit, *auditLog); err != nil {
+		blog.Errorf("save unique audit log failed, err: %v, rid: %s", err, p.kit.Rid)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// liveTopology is the live sets and modules of a business
+type liveTopology struct {
+	// sets is the live sets by their names
+	sets map[string]mapstr.MapStr
+	// modules is the live modules by the set id and the module name
+	modules map[int64]map[string]mapstr.MapStr
+}
+
+func (p *planner) planSets() error {
+	var isCustomMainline *bool
+	for idx := range p.manifest.Businesses {
+		biz := &p.manifest.Businesses[idx]
+		if len(biz.Sets) == 0 {
+			continue
+		}
+
+		live := &liveTopology{sets: make(map[string]mapstr.MapStr), modules: make(map[int64]map[string]mapstr.MapStr)}
+		if bizID, exists := p.bizIDs[biz.Name]; exists {
+			var err error
+			if live, err = p.getLiveTopology(bizID); err != nil {
+				return err
+			}
+		}
+
+		for setIdx := range biz.Sets {
+			set := &biz.Sets[setIdx]
+			liveSet, exists := live.sets[set.Name]
+			if exists {
+				if err := p.planUpdateSet(biz.Name, set, liveSet, live); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// the sets can only be created under the business when there is no custom mainline levels
+			if isCustomMainline == nil {
+				isCustom, err := p.isCustomMainline()
+				if err != nil {
+					return err
+				}
+				isCustomMainline = &isCustom
+			}
+			if *isCustomMainline {
+				p.addConflict(metadata.ManifestKindSet, metadata.ManifestKey(biz.Name, set.Name),
+					"sets can not be created in business with custom mainline levels")
+				continue
+			}
+
+			p.planCreateSet(biz.Name, set)
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) isCustomMainline() (bool, error) {
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.AssociationKindIDField: common.AssociationKindMainline,
+			common.BKAsstObjIDField:       common.BKInnerObjIDApp,
+		},
+	}
+	res, err := p.op.clientSet.CoreService().Association().ReadModelAssociation(p.kit.Ctx, p.kit.Header, cond)
+	if err != nil {
+		blog.Errorf("read business mainline association failed, err: %v, rid: %s", err, p.kit.Rid)
+		return false, err
+	}
+
+	for _, asst := range res.Info {
+		if asst.ObjectID != common.BKInnerObjIDSet {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *planner) getLiveTopology(bizID int64) (*liveTopology, error) {
+	live := &liveTopology{sets: make(map[string]mapstr.MapStr), modules: make(map[int64]map[string]mapstr.MapStr)}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppIDField: bizID},
+		Fields:    []string{common.BKSetIDField, common.BKSetNameField, common.BKSetTemplateIDField, common.BKDefaultField},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	setRes, err := p.op.clientSet.CoreService().Instance().ReadInstance(p.kit.Ctx, p.kit.Header,
+		common.BKInnerObjIDSet, cond)
+	if err != nil {
+		blog.Errorf("read sets of biz %d failed, err: %v, rid: %s", bizID, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, set := range setRes.Info {
+		live.sets[util.GetStrByInterface(set[common.BKSetNameField])] = set
+	}
+
+	cond.Fields = []string{common.BKModuleIDField, common.BKModuleNameField, common.BKSetIDField,
+		common.BKServiceTemplateIDField}
+	moduleRes, err := p.op.clientSet.CoreService().Instance().ReadInstance(p.kit.Ctx, p.kit.Header,
+		common.BKInnerObjIDModule, cond)
+	if err != nil {
+		blog.Errorf("read modules of biz %d failed, err: %v, rid: %s", bizID, err, p.kit.Rid)
+		return nil, err
+	}
+	for _, module := range moduleRes.Info {
+		setID, err := util.GetInt64ByInterface(module[common.BKSetIDField])
+		if err != nil {
+			blog.Errorf("parse module set id failed, module: %v, err: %v, rid: %s", module, err, p.kit.Rid)
+			return nil, err
+		}
+		if _, exists := live.modules[setID]; !exists {
+			live.modules[setID] = make(map[string]mapstr.MapStr)
+		}
+		live.modules[setID][util.GetStrByInterface(module[common.BKModuleNameField])] = module
+	}
+
+	return live, nil
+}
+
+// checkTemplateRef checks if the referred template exists or is going to be created by the manifest
+func (p *planner) checkTemplateRef(ids map[string]int64, kind metadata.ManifestKind, bizName, name string) bool {
+	if len(name) == 0 {
+		return true
+	}
+
+	key := metadata.ManifestKey(bizName, name)
+	if _, exists := ids[key]; exists {
+		return true
+	}
+
+	for _, change := range p.plan.Changes {
+		if change.Kind == kind && change.Action == metadata.ManifestActionCreate && change.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *planner) planCreateSet(bizName string, set *metadata.ManifestSet) {
+	key := metadata.ManifestKey(bizName, set.Name)
+	if !p.checkTemplateRef(p.setTmplIDs, metadata.ManifestKindSetTemplate, bizName, set.SetTemplate) {
+		p.addConflict(metadata.ManifestKindSet, key, "set template %s does not exist", set.SetTemplate)
+		return
+	}
+
+	p.addChange(metadata.ManifestKindSet, metadata.ManifestActionCreate, key, nil, func() error {
+		bizID, err := p.getID(p.bizIDs, metadata.ManifestKindBusiness, bizName)
+		if err != nil {
+			return err
+		}
+
+		data := mapstr.MapStr{
+			common.BKSetNameField:       set.Name,
+			common.BKInstParentStr:      bizID,
+			common.BKAppIDField:         bizID,
+			common.BKSetTemplateIDField: common.SetTemplateIDNotSet,
+		}
+		if len(set.SetTemplate) != 0 {
+			setTmplKey := metadata.ManifestKey(bizName, set.SetTemplate)
+			if data[common.BKSetTemplateIDField], err = p.getID(p.setTmplIDs, metadata.ManifestKindSetTemplate,
+				setTmplKey); err != nil {
+				return err
+			}
+		}
+
+		created, err := p.op.dep.Set.CreateSet(p.kit, bizID, data)
+		if err != nil {
+			return err
+		}
+		setID, err := created.Int64(common.BKSetIDField)
+		if err != nil {
+			blog.Errorf("get created set id failed, set: %v, err: %v, rid: %s", created, err, p.kit.Rid)
+			return err
+		}
+		p.setIDs[key] = setID
+		return nil
+	})
+
+	for idx := range set.Modules {
+		p.planCreateModule(bizName, set.Name, &set.Modules[idx])
+	}
+}
+
+func (p *planner) planUpdateSet(bizName string, set *metadata.ManifestSet, liveSet mapstr.MapStr,
+	live *liveTopology) error {
+
+	key := metadata.ManifestKey(bizName, set.Name)
+	setID, err := util.GetInt64ByInterface(liveSet[common.BKSetIDField])
+	if err != nil {
+		blog.Errorf("parse set id failed, set: %v, err: %v, rid: %s", liveSet, err, p.kit.Rid)
+		return err
+	}
+	p.setIDs[key] = setID
+
+	setTmplID, _ := util.GetInt64ByInterface(liveSet[common.BKSetTemplateIDField])
+	if len(set.SetTemplate) != 0 {
+		if wantID, exists := p.setTmplIDs[metadata.ManifestKey(bizName, set.SetTemplate)]; !exists ||
+			wantID != setTmplID {
+			p.addConflict(metadata.ManifestKindSet, key, "set template of the set can not be changed to %s",
+				set.SetTemplate)
+		}
+		return nil
+	}
+
+	for idx := range set.Modules {
+		module := &set.Modules[idx]
+		liveModule, exists := live.modules[setID][module.Name]
+		if !exists {
+			if setTmplID != common.SetTemplateIDNotSet {
+				p.addConflict(metadata.ManifestKindModule, metadata.ManifestKey(key, module.Name),
+					"modules can not be added to the set created by set template")
+				continue
+			}
+			p.planCreateModule(bizName, set.Name, module)
+			continue
+		}
+
+		if len(module.ServiceTemplate) == 0 {
+			continue
+		}
+		svcTmplID, _ := util.GetInt64ByInterface(liveModule[common.BKServiceTemplateIDField])
+		if wantID, exists := p.svcTmplIDs[metadata.ManifestKey(bizName, module.ServiceTemplate)]; !exists ||
+			wantID != svcTmplID {
+			p.addConflict(metadata.ManifestKindModule, metadata.ManifestKey(key, module.Name),
+				"service template of the module can not be changed to %s", module.ServiceTemplate)
+		}
+	}
+
+	return nil
+}
+
+func (p *planner) planCreateModule(bizName, setName string, module *metadata.ManifestModule) {
+	setKey := metadata.ManifestKey(bizName, setName)
+	key := metadata.ManifestKey(setKey, module.Name)
+	if !p.checkTemplateRef(p.svcTmplIDs, metadata.ManifestKindServiceTemplate, bizName, module.ServiceTemplate) {
+		p.addConflict(metadata.ManifestKindModule, key, "service template %s does not exist", module.ServiceTemplate)
+		return
+	}
+
+	p.addChange(metadata.ManifestKindModule, metadata.ManifestActionCreate, key, nil, func() error {
+		bizID, err := p.getID(p.bizIDs, metadata.ManifestKindBusiness, bizName)
+		if err != nil {
+			return err
+		}
+		setID, err := p.getID(p.setIDs, metadata.ManifestKindSet, setKey)
+		if err != nil {
+			return err
+		}
+
+		data := mapstr.MapStr{
+			common.BKModuleNameField:        module.Name,
+			common.BKInstParentStr:          setID,
+			common.BKServiceTemplateIDField: common.ServiceTemplateIDNotSet,
+		}
+		if len(module.ServiceTemplate) != 0 {
+			svcTmplKey := metadata.ManifestKey(bizName, module.ServiceTemplate)
+			if data[common.BKServiceTemplateIDField], err = p.getID(p.svcTmplIDs,
+				metadata.ManifestKindServiceTemplate, svcTmplKey); err != nil {
+				return err
+			}
+		}
+
+		_, err = p.op.dep.Module.CreateModule(p.kit, bizID, setID, data)
+		return err
+	})
+}
@@ -0,0 +1,468 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package manifest
+
+import (
+	"sort"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// getID returns the id of the live or created resource by its key in the manifest
+func (p *planner) getID(ids map[string]int64, kind metadata.ManifestKind, key string) (int64, error) {
+	id, exists := ids[key]
+	if !exists {
+		blog.Errorf("%s %s is not found, rid: %s", kind, key, p.kit.Rid)
+		return 0, p.kit.CCError.CCErrorf(common.CCErrCommNotFound)
+	}
+	return id, nil
+}
+
+// bizNames returns all the business names used in the manifest
+func (p *planner) bizNames() []string {
+	nameMap := make(map[string]struct{})
+	for _, biz := range p.manifest.Businesses {
+		nameMap[biz.Name] = struct{}{}
+	}
+	for _, template := range p.manifest.ServiceTemplates {
+		nameMap[template.BizName] = struct{}{}
+	}
+	for _, template := range p.manifest.SetTemplates {
+		nameMap[template.BizName] = struct{}{}
+	}
+
+	names := make([]string, 0, len(nameMap))
+	for name := range nameMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *planner) planBusinesses() error {
+	names := p.bizNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppNameField: mapstr.MapStr{common.BKDBIN: names}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := p.op.clientSet.CoreService().Instance().ReadInstance(p.kit.Ctx, p.kit.Header,
+		common.BKInnerObjIDApp, cond)
+	if err != nil {
+		blog.Errorf("read businesses failed, names: %v, err: %v, rid: %s", names, err, p.kit.Rid)
+		return err
+	}
+
+	liveMap := make(map[string]mapstr.MapStr)
+	for _, biz := range res.Info {
+		name := util.GetStrByInterface(biz[common.BKAppNameField])
+		bizID, err := util.GetInt64ByInterface(biz[common.BKAppIDField])
+		if err != nil {
+			blog.Errorf("parse business id failed, biz: %v, err: %v, rid: %s", biz, err, p.kit.Rid)
+			return err
+		}
+		liveMap[name] = biz
+		p.bizIDs[name] = bizID
+	}
+
+	for idx := range p.manifest.Businesses {
+		biz := p.manifest.Businesses[idx]
+		want := make(mapstr.MapStr)
+		for field, value := range biz.Attributes {
+			want[field] = value
+		}
+		want.Remove(common.BKAppIDField)
+		want.Remove(common.BKAppNameField)
+
+		live, exists := liveMap[biz.Name]
+		if !exists {
+			p.planCreateBusiness(biz.Name, want)
+			continue
+		}
+
+		diffs := diffFields(live, want)
+		if len(diffs) == 0 {
+			continue
+		}
+		bizID := p.bizIDs[biz.Name]
+		p.addChange(metadata.ManifestKindBusiness, metadata.ManifestActionUpdate, biz.Name, diffs, func() error {
+			cond := mapstr.MapStr{common.BKAppIDField: bizID}
+			return p.op.dep.Inst.UpdateInst(p.kit, cond, diffData(diffs), common.BKInnerObjIDApp)
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) planCreateBusiness(name string, data mapstr.MapStr) {
+	data.Set(common.BKAppNameField, name)
+	data.Set(common.BKDefaultField, common.DefaultFlagDefaultValue)
+
+	p.addChange(metadata.ManifestKindBusiness, metadata.ManifestActionCreate, name, nil, func() error {
+		biz, err := p.op.dep.Business.CreateBusiness(p.kit, data)
+		if err != nil {
+			return err
+		}
+
+		bizID, err := biz.Int64(common.BKAppIDField)
+		if err != nil {
+			blog.Errorf("get created biz id failed, biz: %v, err: %v, rid: %s", biz, err, p.kit.Rid)
+			return err
+		}
+		p.bizIDs[name] = bizID
+		return p.registerCreator(iam.Business, bizID, name)
+	})
+}
+
+// isBizKnown checks if the business exists or is going to be created by the manifest
+func (p *planner) isBizKnown(name string) bool {
+	if _, exists := p.bizIDs[name]; exists {
+		return true
+	}
+	for _, biz := range p.manifest.Businesses {
+		if biz.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *planner) planServiceTemplates() error {
+	categories := make(map[string]map[string]int64)
+	liveMap := make(map[string]metadata.ServiceTemplate)
+	for _, name := range p.bizNames() {
+		bizID := p.bizIDs[name]
+		var err error
+		if categories[name], err = p.getCategoryPaths(bizID); err != nil {
+			return err
+		}
+
+		if bizID == 0 {
+			continue
+		}
+		opt := &metadata.ListServiceTemplateOption{BusinessID: bizID, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+		res, err := p.op.clientSet.CoreService().Process().ListServiceTemplates(p.kit.Ctx, p.kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list service templates failed, biz: %d, err: %v, rid: %s", bizID, err, p.kit.Rid)
+			return err
+		}
+		for _, template := range res.Info {
+			key := metadata.ManifestKey(name, template.Name)
+			p.svcTmplIDs[key] = template.ID
+			liveMap[key] = template
+		}
+	}
+
+	for idx := range p.manifest.ServiceTemplates {
+		template := p.manifest.ServiceTemplates[idx]
+		key := metadata.ManifestKey(template.BizName, template.Name)
+		if !p.isBizKnown(template.BizName) {
+			p.addConflict(metadata.ManifestKindServiceTemplate, key, "business %s does not exist", template.BizName)
+			continue
+		}
+
+		categoryID, exists := categories[template.BizName][template.Category]
+		if !exists {
+			p.addConflict(metadata.ManifestKindServiceTemplate, key, "service category %s does not exist",
+				template.Category)
+			continue
+		}
+
+		live, exists := liveMap[key]
+		if !exists {
+			p.planCreateServiceTemplate(&template, categoryID)
+			continue
+		}
+
+		if live.ServiceCategoryID != categoryID {
+			p.planUpdateServiceTemplate(key, live, categoryID)
+		}
+	}
+
+	return nil
+}
+
+// getCategoryPaths returns the ids of the second level service categories by their paths like "parent/child",
+// the global categories are used if the business is not created yet
+func (p *planner) getCategoryPaths(bizID int64) (map[string]int64, error) {
+	opt := metadata.ListServiceCategoriesOption{BusinessID: bizID}
+	res, err := p.op.clientSet.CoreService().Process().ListServiceCategories(p.kit.Ctx, p.kit.Header, opt)
+	if err != nil {
+		blog.Errorf("list service categories failed, biz: %d, err: %v, rid: %s", bizID, err, p.kit.Rid)
+		return nil, err
+	}
+
+	names := make(map[int64]string)
+	for _, category := range res.Info {
+		names[category.ServiceCategory.ID] = category.ServiceCategory.Name
+	}
+
+	paths := make(map[string]int64)
+	for _, category := range res.Info {
+		if category.ServiceCategory.ParentID == 0 {
+			continue
+		}
+		parent := names[category.ServiceCategory.ParentID]
+		paths[metadata.ManifestKey(parent, category.ServiceCategory.Name)] = category.ServiceCategory.ID
+	}
+	return paths, nil
+}
+
+func (p *planner) planCreateServiceTemplate(template *metadata.ManifestServiceTemplate, categoryID int64) {
+	key := metadata.ManifestKey(template.BizName, template.Name)
+	p.addChange(metadata.ManifestKindServiceTemplate, metadata.ManifestActionCreate, key, nil, func() error {
+		bizID, err := p.getID(p.bizIDs, metadata.ManifestKindBusiness, template.BizName)
+		if err != nil {
+			return err
+		}
+
+		created, err := p.op.clientSet.CoreService().Process().CreateServiceTemplate(p.kit.Ctx, p.kit.Header,
+			&metadata.ServiceTemplate{BizID: bizID, Name: template.Name, ServiceCategoryID: categoryID})
+		if err != nil {
+			blog.Errorf("create service template %s failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		p.svcTmplIDs[key] = created.ID
+
+		audit := auditlog.NewServiceTemplateAuditLog(p.op.clientSet.CoreService())
+		generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditCreate)
+		if err := audit.SaveAuditLog(p.kit, *audit.GenerateAuditLog(generateAuditParameter, created)); err != nil {
+			blog.Errorf("save service template %s audit log failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		return p.registerCreator(iam.BizProcessServiceTemplate, created.ID, created.Name)
+	})
+}
+
+func (p *planner) planUpdateServiceTemplate(key string, live metadata.ServiceTemplate, categoryID int64) {
+	diffs := []metadata.ManifestFieldDiff{{
+		Field: common.BKServiceCategoryIDField,
+		Old:   live.ServiceCategoryID,
+		New:   categoryID,
+	}}
+	p.addChange(metadata.ManifestKindServiceTemplate, metadata.ManifestActionUpdate, key, diffs, func() error {
+		updated := live
+		updated.ServiceCategoryID = categoryID
+		_, err := p.op.clientSet.CoreService().Process().UpdateServiceTemplate(p.kit.Ctx, p.kit.Header, live.ID,
+			&updated)
+		if err != nil {
+			blog.Errorf("update service template %s failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+
+		audit := auditlog.NewServiceTemplateAuditLog(p.op.clientSet.CoreService())
+		generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditUpdate).
+			WithUpdateFields(map[string]interface{}{common.BKServiceCategoryIDField: categoryID})
+		if err := audit.SaveAuditLog(p.kit, *audit.GenerateAuditLog(generateAuditParameter, &live)); err != nil {
+			blog.Errorf("save service template %s audit log failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		return nil
+	})
+}
+
+func (p *planner) planSetTemplates() error {
+	// relations is the service template names of the live set templates by the set template keys
+	relations := make(map[string][]string)
+	for _, name := range p.bizNames() {
+		bizID := p.bizIDs[name]
+		if bizID == 0 {
+			continue
+		}
+		if err := p.getLiveSetTemplates(name, bizID, relations); err != nil {
+			return err
+		}
+	}
+
+	for idx := range p.manifest.SetTemplates {
+		template := p.manifest.SetTemplates[idx]
+		key := metadata.ManifestKey(template.BizName, template.Name)
+		if !p.isBizKnown(template.BizName) {
+			p.addConflict(metadata.ManifestKindSetTemplate, key, "business %s does not exist", template.BizName)
+			continue
+		}
+
+		if conflict := p.checkSetTemplate(&template); len(conflict) != 0 {
+			p.addConflict(metadata.ManifestKindSetTemplate, key, conflict)
+			continue
+		}
+
+		liveNames, exists := relations[key]
+		if !exists {
+			if len(template.ServiceTemplates) == 0 {
+				p.addConflict(metadata.ManifestKindSetTemplate, key, "service templates must be set")
+				continue
+			}
+			p.planCreateSetTemplate(&template)
+			continue
+		}
+
+		// the service templates are only added to the set template, so the modules are never removed
+		names := util.StrArrayUnique(append(append([]string{}, liveNames...), template.ServiceTemplates...))
+		if len(names) == len(liveNames) {
+			continue
+		}
+		sort.Strings(names)
+		diffs := []metadata.ManifestFieldDiff{{Field: "service_templates", Old: liveNames, New: names}}
+		p.addChange(metadata.ManifestKindSetTemplate, metadata.ManifestActionUpdate, key, diffs, func() error {
+			return p.updateSetTemplate(&template, liveNames, names)
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) getLiveSetTemplates(bizName string, bizID int64, relations map[string][]string) error {
+	svcTmplNames := bizResourceNames(bizName, p.svcTmplIDs)
+
+	opt := metadata.ListSetTemplateOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	res, err := p.op.clientSet.CoreService().SetTemplate().ListSetTemplate(p.kit.Ctx, p.kit.Header, bizID, opt)
+	if err != nil {
+		blog.Errorf("list set templates failed, biz: %d, err: %v, rid: %s", bizID, err, p.kit.Rid)
+		return err
+	}
+
+	for _, template := range res.Info {
+		key := metadata.ManifestKey(bizName, template.Name)
+		p.setTmplIDs[key] = template.ID
+
+		rels, err := p.op.clientSet.CoreService().SetTemplate().ListSetServiceTemplateRelations(p.kit.Ctx,
+			p.kit.Header, bizID, template.ID)
+		if err != nil {
+			blog.Errorf("list set template %d relations failed, err: %v, rid: %s", template.ID, err, p.kit.Rid)
+			return err
+		}
+
+		names := make([]string, 0)
+		for _, rel := range rels {
+			if name, exists := svcTmplNames[rel.ServiceTemplateID]; exists {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		relations[key] = names
+	}
+
+	return nil
+}
+
+// checkSetTemplate checks if the service templates of the set template exist, returns the conflict message
+func (p *planner) checkSetTemplate(template *metadata.ManifestSetTemplate) string {
+	planned := make(map[string]struct{})
+	for _, svcTmpl := range p.manifest.ServiceTemplates {
+		planned[metadata.ManifestKey(svcTmpl.BizName, svcTmpl.Name)] = struct{}{}
+	}
+
+	for _, name := range template.ServiceTemplates {
+		key := metadata.ManifestKey(template.BizName, name)
+		if _, exists := p.svcTmplIDs[key]; exists {
+			continue
+		}
+		if _, exists := planned[key]; !exists {
+			return "service template " + name + " does not exist"
+		}
+	}
+	return ""
+}
+
+func (p *planner) getSvcTmplIDs(bizName string, names []string) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, name := range names {
+		id, err := p.getID(p.svcTmplIDs, metadata.ManifestKindServiceTemplate, metadata.ManifestKey(bizName, name))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (p *planner) planCreateSetTemplate(template *metadata.ManifestSetTemplate) {
+	key := metadata.ManifestKey(template.BizName, template.Name)
+	p.addChange(metadata.ManifestKindSetTemplate, metadata.ManifestActionCreate, key, nil, func() error {
+		bizID, err := p.getID(p.bizIDs, metadata.ManifestKindBusiness, template.BizName)
+		if err != nil {
+			return err
+		}
+		svcTmplIDs, err := p.getSvcTmplIDs(template.BizName, template.ServiceTemplates)
+		if err != nil {
+			return err
+		}
+
+		opt := metadata.CreateSetTemplateOption{Name: template.Name, ServiceTemplateIDs: svcTmplIDs}
+		created, err := p.op.clientSet.CoreService().SetTemplate().CreateSetTemplate(p.kit.Ctx, p.kit.Header, bizID,
+			opt)
+		if err != nil {
+			blog.Errorf("create set template %s failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		p.setTmplIDs[key] = created.ID
+
+		audit := auditlog.NewSetTemplateAuditLog(p.op.clientSet.CoreService())
+		generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditCreate)
+		auditLog := audit.GenerateAuditLog(generateAuditParameter, &created, svcTmplIDs)
+		if err := audit.SaveAuditLog(p.kit, *auditLog); err != nil {
+			blog.Errorf("save set template %s audit log failed, err: %v, rid: %s", key, err, p.kit.Rid)
+			return err
+		}
+		return p.registerCreator(iam.BizSetTemplate, created.ID, created.Name)
+	})
+}
+
+func (p *planner) updateSetTemplate(template *metadata.ManifestSetTemplate, liveNames, names []string) error {
+	key := metadata.ManifestKey(template.BizName, template.Name)
+	bizID, err := p.getID(p.bizIDs, metadata.ManifestKindBusiness, template.BizName)
+	if err != nil {
+		return err
+	}
+	liveSvcTmplIDs, err := p.getSvcTmplIDs(template.BizName, liveNames)
+	if err != nil {
+		return err
+	}
+	svcTmplIDs, err := p.getSvcTmplIDs(template.BizName, names)
+	if err != nil {
+		return err
+	}
+
+	opt := metadata.UpdateSetTemplateOption{Name: template.Name, ServiceTemplateIDs: svcTmplIDs}
+	updated, err := p.op.clientSet.CoreService().SetTemplate().UpdateSetTemplate(p.kit.Ctx, p.kit.Header, bizID,
+		p.setTmplIDs[key], opt)
+	if err != nil {
+		blog.Errorf("update set template %s failed, err: %v, rid: %s", key, err, p.kit.Rid)
+		return err
+	}
+
+	// only the service templates of the set template are changed, so the updated one is used as the previous data
+	audit := auditlog.NewSetTemplateAuditLog(p.op.clientSet.CoreService())
+	generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(p.kit, metadata.AuditUpdate).
+		WithUpdateFields(map[string]interface{}{"service_template_ids": svcTmplIDs})
+	auditLog := audit.GenerateAuditLog(generateAuditParameter, &updated, liveSvcTmplIDs)
+	if err := audit.SaveAuditLog(p.kit, *auditLog); err != nil {
+		blog.Errorf("save set template %s audit log failed, err: %v, rid: %s", key, err, p.kit.Rid)
+		return err
+	}
+	return nil
+}
@@ -1430,3 +1430,43 @@ func (s *Service) SearchAssociationInstWithBizID(ctx *rest.Contexts) {
 
 	ctx.RespEntity(ret.Info)
 }
+
+// SearchInstAssociationGraph search the instance association graph traversed from the start instance for change
+// impact analysis, the user must have the find permission of all the traversed objects' instances
+func (s *Service) SearchInstAssociationGraph(ctx *rest.Contexts) {
+	opt := new(metadata.InstAsstGraphOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+	graph, err := s.Logics.InstAssociationOperation().SearchInstAssociationGraph(ctx.Kit, opt)
+	if err != nil {
+		blog.Errorf("search instance association graph failed, opt: %+v, err: %v, rid: %s", opt, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	objIDs := make([]string, 0)
+	for _, node := range graph.Nodes {
+		objIDs = append(objIDs, node.ObjID)
+	}
+
+	authResp, authorized, err := s.AuthManager.HasFindModelInstAuth(ctx.Kit, util.StrArrayUnique(objIDs))
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	ctx.RespEntity(graph)
+}
@@ -18,6 +18,7 @@ package service
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
 	"configcenter/src/common/blog"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/http/rest"
@@ -379,3 +380,60 @@ func buildInstAuditCondition(ctx *rest.Contexts, query metadata.InstAuditConditi
 
 	return cond, nil
 }
+
+// SearchInstSnapshot reconstruct an instance at the specified time by replaying its audit logs
+func (s *Service) SearchInstSnapshot(ctx *rest.Contexts) {
+	opt := new(metadata.InstSnapshotOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+	snapshot, err := s.Engine.CoreAPI.CoreService().Audit().SearchInstSnapshot(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("search instance snapshot failed, err: %v, opt: %#v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(snapshot)
+}
+
+// DiffInstSnapshot compare an instance between two points in time by replaying its audit logs
+func (s *Service) DiffInstSnapshot(ctx *rest.Contexts) {
+	opt := new(metadata.InstSnapshotDiffOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+	start, err := s.Engine.CoreAPI.CoreService().Audit().SearchInstSnapshot(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt.StartOption())
+	if err != nil {
+		blog.Errorf("search instance start snapshot failed, err: %v, opt: %#v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	end, err := s.Engine.CoreAPI.CoreService().Audit().SearchInstSnapshot(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt.EndOption())
+	if err != nil {
+		blog.Errorf("search instance end snapshot failed, err: %v, opt: %#v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(auditlog.DiffInstSnapshot(start, end))
+}
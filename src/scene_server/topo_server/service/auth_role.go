@@ -0,0 +1,194 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/ac/local"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CreateAuthRole create a role of the built-in local authorizer
+func (s *Service) CreateAuthRole(ctx *rest.Contexts) {
+	opt := new(metadata.AuthRoleSpec)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := validateAuthRole(ctx.Kit, opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := s.Engine.CoreAPI.CoreService().AuthRole().CreateRole(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("create auth role failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: id})
+}
+
+// UpdateAuthRole update a role of the built-in local authorizer, its policies are replaced by the new ones
+func (s *Service) UpdateAuthRole(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	opt := new(metadata.AuthRoleSpec)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := validateAuthRole(ctx.Kit, opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().AuthRole().UpdateRole(ctx.Kit.Ctx, ctx.Kit.Header, id,
+		opt); err != nil {
+		blog.Errorf("update auth role %d failed, err: %v, opt: %+v, rid: %s", id, err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteAuthRole delete a role of the built-in local authorizer together with its bindings
+func (s *Service) DeleteAuthRole(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().AuthRole().DeleteRole(ctx.Kit.Ctx, ctx.Kit.Header, id); err != nil {
+		blog.Errorf("delete auth role %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// SearchAuthRole search roles of the built-in local authorizer
+func (s *Service) SearchAuthRole(ctx *rest.Contexts) {
+	opt := new(metadata.SearchAuthRoleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	roles, err := s.Engine.CoreAPI.CoreService().AuthRole().ListRole(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("search auth roles failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(roles)
+}
+
+// CreateAuthRoleBinding bind a role of the built-in local authorizer to users
+func (s *Service) CreateAuthRoleBinding(ctx *rest.Contexts) {
+	opt := new(metadata.CreateAuthRoleBindingOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	id, err := s.Engine.CoreAPI.CoreService().AuthRole().CreateRoleBinding(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("create auth role binding failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: id})
+}
+
+// DeleteAuthRoleBinding delete a role binding of the built-in local authorizer
+func (s *Service) DeleteAuthRoleBinding(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	err = s.Engine.CoreAPI.CoreService().AuthRole().DeleteRoleBinding(ctx.Kit.Ctx, ctx.Kit.Header, id)
+	if err != nil {
+		blog.Errorf("delete auth role binding %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// SearchAuthRoleBinding search role bindings of the built-in local authorizer
+func (s *Service) SearchAuthRoleBinding(ctx *rest.Contexts) {
+	opt := new(metadata.SearchAuthRoleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	bindings, err := s.Engine.CoreAPI.CoreService().AuthRole().ListRoleBinding(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("search auth role bindings failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(bindings)
+}
+
+// validateAuthRole validate the role, its policies must use the actions and resource types defined in iam model
+func validateAuthRole(kit *rest.Kit, role *metadata.AuthRoleSpec) error {
+	if rawErr := role.Validate(); rawErr.ErrCode != 0 {
+		return rawErr.ToCCError(kit.CCError)
+	}
+
+	if err := local.ValidatePolicies(role.Policies); err != nil {
+		blog.Errorf("auth role policies are invalid, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "policies")
+	}
+
+	return nil
+}
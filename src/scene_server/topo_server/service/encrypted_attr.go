@@ -0,0 +1,60 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// DecryptInstAttr get the plaintext value of an encrypted attribute of an instance,
+// only the user who has the permission to edit the instance is allowed to see it.
+func (s *Service) DecryptInstAttr(cts *rest.Contexts) {
+	objID := cts.Request.PathParameter(common.BKObjIDField)
+
+	opt := new(metadata.DecryptInstAttrOption)
+	if err := cts.DecodeInto(opt); err != nil {
+		cts.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		cts.RespAutoError(rawErr.ToCCError(cts.Kit.CCError))
+		return
+	}
+
+	err := s.AuthManager.AuthorizeByInstanceID(cts.Kit.Ctx, cts.Kit.Header, meta.Update, objID, opt.InstID)
+	if err != nil {
+		blog.Errorf("authorize decrypt %s inst %d attr failed, err: %v, rid: %s", objID, opt.InstID, err, cts.Kit.Rid)
+		cts.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.Engine.CoreAPI.CoreService().Instance().DecryptInstAttr(cts.Kit.Ctx, cts.Kit.Header, objID,
+		opt)
+	if ccErr != nil {
+		blog.Errorf("decrypt %s inst %d attr %s failed, err: %v, rid: %s", objID, opt.InstID, opt.PropertyID, ccErr,
+			cts.Kit.Rid)
+		cts.RespAutoError(ccErr)
+		return
+	}
+
+	cts.RespEntity(result)
+}
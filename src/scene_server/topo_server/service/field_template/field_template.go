@@ -337,6 +337,10 @@ func (s *service) CreateFieldTemplate(ctx *rest.Contexts) {
 			return err
 		}
 
+		if _, err = s.logics.FieldTemplateOperation().CreateFieldTemplateVersion(ctx.Kit, res.ID); err != nil {
+			return err
+		}
+
 		// register business resource creator action to iam
 		if auth.EnableAuthorize() {
 			iamInstance := metadata.IamInstanceWithCreator{
@@ -451,6 +455,10 @@ func (s *service) CloneFieldTemplate(ctx *rest.Contexts) {
 			return err
 		}
 
+		if _, err = s.logics.FieldTemplateOperation().CreateFieldTemplateVersion(ctx.Kit, res.ID); err != nil {
+			return err
+		}
+
 		// register business resource creator action to iam
 		if auth.EnableAuthorize() {
 			iamInstance := metadata.IamInstanceWithCreator{
@@ -562,33 +570,8 @@ func (s *service) UpdateFieldTemplate(ctx *rest.Contexts) {
 	}
 
 	txnErr := s.clientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
-		if err := s.logics.FieldTemplateOperation().UpdateFieldTemplateInfo(ctx.Kit, &opt.FieldTemplate); err != nil {
-			blog.Errorf("update field template info failed, data: %v, err: %v, rid: %s", opt.FieldTemplate, err,
-				ctx.Kit.Rid)
-			return err
-		}
-
-		// because deleting attribute requires deleting its unique, we need to delete the unique first.
-		if err := s.deleteFieldTmplUnique(ctx.Kit, opt.ID, opt.Uniques); err != nil {
-			blog.Errorf("delete field template unique failed, template id: %d, cond: %v, err: %v, rid: %s", opt.ID,
-				opt.Uniques, err, ctx.Kit.Rid)
-			return err
-		}
-
-		propertyIDToIDMap, err := s.updateFieldTmplAttr(ctx.Kit, opt.ID, opt.Attributes)
-		if err != nil {
-			blog.Errorf("update field template attribute failed, data: %v, err: %v, rid: %s", opt.Attributes, err,
-				ctx.Kit.Rid)
-			return err
-		}
-
-		if err := s.updateFieldTmplUnique(ctx.Kit, opt.ID, propertyIDToIDMap, opt.Uniques); err != nil {
-			blog.Errorf("update field template unique failed, data: %v, err: %v, rid: %s", opt.Uniques, err,
-				ctx.Kit.Rid)
-			return err
-		}
-
-		return nil
+		_, err := s.updateFieldTemplate(ctx.Kit, opt)
+		return err
 	})
 
 	if txnErr != nil {
@@ -600,6 +583,38 @@ func (s *service) UpdateFieldTemplate(ctx *rest.Contexts) {
 
 }
 
+// updateFieldTemplate update field template brief information, attributes and uniques, then take a snapshot of the
+// updated field template as a new version
+func (s *service) updateFieldTemplate(kit *rest.Kit, opt *metadata.UpdateFieldTmplOption) (
+	*metadata.FieldTemplateVersion, error) {
+
+	if err := s.logics.FieldTemplateOperation().UpdateFieldTemplateInfo(kit, &opt.FieldTemplate); err != nil {
+		blog.Errorf("update field template info failed, data: %v, err: %v, rid: %s", opt.FieldTemplate, err, kit.Rid)
+		return nil, err
+	}
+
+	// because deleting attribute requires deleting its unique, we need to delete the unique first.
+	if err := s.deleteFieldTmplUnique(kit, opt.ID, opt.Uniques); err != nil {
+		blog.Errorf("delete field template unique failed, template id: %d, cond: %v, err: %v, rid: %s", opt.ID,
+			opt.Uniques, err, kit.Rid)
+		return nil, err
+	}
+
+	propertyIDToIDMap, err := s.updateFieldTmplAttr(kit, opt.ID, opt.Attributes)
+	if err != nil {
+		blog.Errorf("update field template attribute failed, data: %v, err: %v, rid: %s", opt.Attributes, err,
+			kit.Rid)
+		return nil, err
+	}
+
+	if err := s.updateFieldTmplUnique(kit, opt.ID, propertyIDToIDMap, opt.Uniques); err != nil {
+		blog.Errorf("update field template unique failed, data: %v, err: %v, rid: %s", opt.Uniques, err, kit.Rid)
+		return nil, err
+	}
+
+	return s.logics.FieldTemplateOperation().CreateFieldTemplateVersion(kit, opt.ID)
+}
+
 func (s *service) deleteFieldTmplUnique(kit *rest.Kit, templateID int64,
 	uniques []metadata.FieldTmplUniqueOption) error {
 
@@ -396,8 +396,24 @@ func tmplAttrConvertObjAttr(user, objID string, attr metadata.FieldTemplateAttr)
 // self-incrementing ID in the scene of creating a unique index. In order to be compatible
 // with this scenario, the request of the comparison function is unified as propertyID. The
 // function of this function is to The auto-increment ID in the database is converted to propertyID.
-func (s *service) getFieldTemplateUniqueByID(kit *rest.Kit, op *metadata.SyncObjectTask) (
-	*metadata.CompareFieldTmplUniqueOption, error) {
+// If the object is pinned to a field template version, the uniques of the version are used instead.
+func (s *service) getFieldTemplateUniqueByID(kit *rest.Kit, op *metadata.SyncObjectTask,
+	version *metadata.FieldTemplateVersion) (*metadata.CompareFieldTmplUniqueOption, error) {
+
+	if version != nil {
+		uniques, rawErr := version.UniqueOptions()
+		if rawErr.ErrCode != 0 {
+			blog.Errorf("convert field template version uniques failed, template id: %d, version: %d, err: %v, "+
+				"rid: %s", op.TemplateID, version.Version, rawErr, kit.Rid)
+			return nil, rawErr.ToCCError(kit.CCError)
+		}
+
+		return &metadata.CompareFieldTmplUniqueOption{
+			TemplateID: op.TemplateID,
+			ObjectID:   op.ObjectID,
+			Uniques:    uniques,
+		}, nil
+	}
 
 	uniqueFilter := filtertools.GenAtomFilter(common.BKTemplateID, filter.Equal, op.TemplateID)
 	listOpt := &metadata.CommonQueryOption{
@@ -543,13 +559,19 @@ func (s *service) preprocessTmplUnique(kit *rest.Kit, objectID string, input *me
 	return createUniques, updateUniques, nil
 }
 
-func (s *service) getCreateAndUpdateAttr(kit *rest.Kit, option *metadata.SyncObjectTask, objectID string) (
-	[]*metadata.Attribute, []metadata.CompareOneFieldTmplAttrRes, error) {
-
-	// 1、get the full content of the field template
-	tmplAttrs, ccErr := s.getTemplateAttrByID(kit, option.TemplateID, []string{})
-	if ccErr != nil {
-		return nil, nil, ccErr
+func (s *service) getCreateAndUpdateAttr(kit *rest.Kit, option *metadata.SyncObjectTask, objectID string,
+	version *metadata.FieldTemplateVersion) ([]*metadata.Attribute, []metadata.CompareOneFieldTmplAttrRes, error) {
+
+	// 1、get the full content of the field template, use the pinned version of the object if it exists
+	var tmplAttrs []metadata.FieldTemplateAttr
+	if version != nil {
+		tmplAttrs = version.Attributes
+	} else {
+		var ccErr errors.CCErrorCoder
+		tmplAttrs, ccErr = s.getTemplateAttrByID(kit, option.TemplateID, []string{})
+		if ccErr != nil {
+			return nil, nil, ccErr
+		}
 	}
 
 	// verify the validity of field attributes and obtain
@@ -578,7 +600,14 @@ func (s *service) getCreateAndUpdateAttr(kit *rest.Kit, option *metadata.SyncObj
 
 func (s *service) doSyncFieldTemplateTask(kit *rest.Kit, option *metadata.SyncObjectTask, objectID string) error {
 
-	createAttr, updateAttr, err := s.getCreateAndUpdateAttr(kit, option, objectID)
+	// the object is synchronized to the field template version it is pinned to
+	version, err := s.logics.FieldTemplateOperation().GetObjFieldTemplateVersion(kit, option.TemplateID,
+		option.ObjectID)
+	if err != nil {
+		return err
+	}
+
+	createAttr, updateAttr, err := s.getCreateAndUpdateAttr(kit, option, objectID, version)
 	if err != nil {
 		return err
 	}
@@ -609,7 +638,7 @@ func (s *service) doSyncFieldTemplateTask(kit *rest.Kit, option *metadata.SyncOb
 
 		// 3、unique validation for preprocessed template synchronization
 
-		uniqueOp, err := s.getFieldTemplateUniqueByID(kit, option)
+		uniqueOp, err := s.getFieldTemplateUniqueByID(kit, option, version)
 		if err != nil {
 			return err
 		}
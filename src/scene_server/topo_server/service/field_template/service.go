@@ -108,4 +108,14 @@ func InitFieldTemplate(utility *rest.RestUtility, c *capability.Capability) {
 
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/field_template/model/status",
 		Handler: s.ListFieldTemplateModelStatus})
+
+	// field template version
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/field_template/version",
+		Handler: s.ListFieldTemplateVersion})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/field_template/version/difference",
+		Handler: s.CompareFieldTemplateVersion})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/field_template/object/version",
+		Handler: s.UpgradeObjFieldTemplateVersion})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/field_template/rollback",
+		Handler: s.RollbackFieldTemplate})
 }
@@ -223,42 +223,18 @@ func (s *service) SyncFieldTemplateInfoToObjects(ctx *rest.Contexts) {
 		return
 	}
 
-	idPausedMap, err := s.getObjectPausedAttr(ctx.Kit, opt.ObjectIDs)
+	// synchronizing field template to objects means upgrading them to the latest field template version
+	version, err := s.getFieldTemplateLatestVersion(ctx.Kit, opt.TemplateID)
 	if err != nil {
 		ctx.RespAutoError(err)
 		return
 	}
 
-	tasks := make([]metadata.CreateTaskRequest, 0)
-	for _, objID := range opt.ObjectIDs {
-		if idPausedMap[objID] {
-			blog.Warnf("the model has been paused, no attr synchronization, object id: %d, rid: %s", objID, ctx.Kit.Rid)
-			continue
-		}
-
-		tasks = append(tasks, metadata.CreateTaskRequest{
-			TaskType: common.SyncFieldTemplateTaskFlag,
-			InstID:   opt.TemplateID,
-			Extra:    objID,
-			Data: []interface{}{metadata.SyncObjectTask{
-				TemplateID: opt.TemplateID,
-				ObjectID:   objID,
-			}},
-		})
-	}
-
-	taskIDs := make([]string, 0)
+	var taskIDs []string
 	txnErr := s.clientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
-		taskRes, err := s.clientSet.TaskServer().Task().CreateFieldTemplateBatch(ctx.Kit.Ctx, ctx.Kit.Header, tasks)
-		if err != nil {
-			blog.Errorf("create field template sync task(%#v) failed, err: %v, rid: %s", tasks, err, ctx.Kit.Rid)
-			return err
-		}
-		for id := range taskRes {
-			taskIDs = append(taskIDs, taskRes[id].TaskID)
-		}
-		blog.V(4).Infof("successfully created field template sync task: %#v, rid: %s", taskRes, ctx.Kit.Rid)
-		return nil
+		var err error
+		taskIDs, err = s.syncFieldTemplateToObjects(ctx.Kit, opt.TemplateID, version, opt.ObjectIDs)
+		return err
 	})
 
 	if txnErr != nil {
@@ -0,0 +1,337 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fieldtmpl
+
+import (
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// ListFieldTemplateVersion list field template versions.
+func (s *service) ListFieldTemplateVersion(cts *rest.Contexts) {
+	opt := new(metadata.ListFieldTmplVersionOption)
+	if err := cts.DecodeInto(opt); err != nil {
+		cts.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		cts.RespAutoError(rawErr.ToCCError(cts.Kit.CCError))
+		return
+	}
+
+	// check if user has the permission of the field template
+	if authResp, authorized := s.auth.Authorize(cts.Kit, meta.ResourceAttribute{Basic: meta.Basic{
+		Type: meta.FieldTemplate, Action: meta.Find, InstanceID: opt.TemplateID}}); !authorized {
+		cts.RespNoAuth(authResp)
+		return
+	}
+
+	versionFilter, err := filtertools.And(filtertools.GenAtomFilter(common.BKTemplateID, filter.Equal,
+		opt.TemplateID), opt.Filter)
+	if err != nil {
+		blog.Errorf("list field template versions failed, err: %v, opt: %+v, rid: %s", err, opt, cts.Kit.Rid)
+		cts.RespAutoError(err)
+		return
+	}
+
+	listOpt := &metadata.CommonQueryOption{
+		CommonFilterOption: metadata.CommonFilterOption{Filter: versionFilter},
+		Page:               opt.Page,
+		Fields:             opt.Fields,
+	}
+
+	res, err := s.clientSet.CoreService().FieldTemplate().ListFieldTemplateVersion(cts.Kit.Ctx, cts.Kit.Header,
+		listOpt)
+	if err != nil {
+		blog.Errorf("list field template versions failed, err: %v, opt: %+v, rid: %s", err, opt, cts.Kit.Rid)
+		cts.RespAutoError(err)
+		return
+	}
+
+	cts.RespEntity(res)
+}
+
+// CompareFieldTemplateVersion compare the attributes and uniques of two field template versions.
+func (s *service) CompareFieldTemplateVersion(cts *rest.Contexts) {
+	opt := new(metadata.CompareFieldTmplVersionOption)
+	if err := cts.DecodeInto(opt); err != nil {
+		cts.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		cts.RespAutoError(rawErr.ToCCError(cts.Kit.CCError))
+		return
+	}
+
+	// check if user has the permission of the field template
+	if authResp, authorized := s.auth.Authorize(cts.Kit, meta.ResourceAttribute{Basic: meta.Basic{
+		Type: meta.FieldTemplate, Action: meta.Find, InstanceID: opt.TemplateID}}); !authorized {
+		cts.RespNoAuth(authResp)
+		return
+	}
+
+	res, err := s.logics.FieldTemplateOperation().CompareFieldTemplateVersion(cts.Kit, opt)
+	if err != nil {
+		cts.RespAutoError(err)
+		return
+	}
+
+	cts.RespEntity(res)
+}
+
+// UpgradeObjFieldTemplateVersion pin the specified objects to a field template version and synchronize the version
+// to them, so that the objects bound to the field template can be upgraded or downgraded one by one.
+func (s *service) UpgradeObjFieldTemplateVersion(ctx *rest.Contexts) {
+	opt := new(metadata.UpgradeObjFieldTmplVersionOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	objIDs := util.IntArrayUnique(opt.ObjectIDs)
+	if authResp, authorized := s.authorizeObjsBindFieldTemplate(ctx.Kit, opt.TemplateID, objIDs); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	var taskIDs []string
+	txnErr := s.clientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		var err error
+		taskIDs, err = s.syncFieldTemplateToObjects(ctx.Kit, opt.TemplateID, opt.Version, objIDs)
+		return err
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(taskIDs)
+}
+
+// RollbackFieldTemplate rollback field template attributes and uniques to the content of an earlier version.
+// the rollback generates a new version instead of removing the later versions, the bound objects can then be
+// upgraded to the new version.
+func (s *service) RollbackFieldTemplate(ctx *rest.Contexts) {
+	opt := new(metadata.RollbackFieldTmplOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if authResp, authorized := s.auth.Authorize(ctx.Kit, meta.ResourceAttribute{Basic: meta.Basic{
+		Type: meta.FieldTemplate, Action: meta.Update, InstanceID: opt.ID}}); !authorized {
+		ctx.RespNoAuth(authResp)
+		return
+	}
+
+	updateOpt, err := s.buildRollbackOpt(ctx.Kit, opt)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	var version *metadata.FieldTemplateVersion
+	txnErr := s.clientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		var err error
+		version, err = s.updateFieldTemplate(ctx.Kit, updateOpt)
+		return err
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(version)
+}
+
+// buildRollbackOpt build the field template update option from the version snapshot to rollback to, attributes
+// are matched with current attributes by property id, uniques are matched by id, unmatched ones will be recreated.
+func (s *service) buildRollbackOpt(kit *rest.Kit, opt *metadata.RollbackFieldTmplOption) (
+	*metadata.UpdateFieldTmplOption, error) {
+
+	template, err := s.getFieldTemplateByID(kit, opt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.logics.FieldTemplateOperation().GetFieldTemplateVersion(kit, opt.ID, opt.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	curAttrs, ccErr := s.getTemplateAttrByID(kit, opt.ID, []string{common.BKFieldID, common.BKPropertyIDField})
+	if ccErr != nil {
+		return nil, ccErr
+	}
+
+	propertyIDToIDMap := make(map[string]int64)
+	for _, attr := range curAttrs {
+		propertyIDToIDMap[attr.PropertyID] = attr.ID
+	}
+
+	idToPropertyIDMap := make(map[int64]string)
+	attrs := make([]metadata.FieldTemplateAttr, len(version.Attributes))
+	for idx, attr := range version.Attributes {
+		idToPropertyIDMap[attr.ID] = attr.PropertyID
+		attr.ID = propertyIDToIDMap[attr.PropertyID]
+		attrs[idx] = attr
+	}
+
+	curUniqueIDs, err := s.getFieldTmplUniqueIDs(kit, opt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	uniques := make([]metadata.FieldTmplUniqueOption, len(version.Uniques))
+	for idx, unique := range version.Uniques {
+		uniqueOpt, rawErr := unique.Convert(idToPropertyIDMap)
+		if rawErr.ErrCode != 0 {
+			blog.Errorf("convert field template version unique failed, unique: %+v, err: %v, rid: %s", unique,
+				rawErr, kit.Rid)
+			return nil, rawErr.ToCCError(kit.CCError)
+		}
+
+		if _, exists := curUniqueIDs[unique.ID]; !exists {
+			uniqueOpt.ID = 0
+		}
+		uniques[idx] = *uniqueOpt
+	}
+
+	updateOpt := &metadata.UpdateFieldTmplOption{
+		FieldTemplate: *template,
+		Attributes:    attrs,
+		Uniques:       uniques,
+	}
+
+	if rawErr := updateOpt.Validate(); rawErr.ErrCode != 0 {
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+
+	return updateOpt, nil
+}
+
+func (s *service) getFieldTemplateByID(kit *rest.Kit, id int64) (*metadata.FieldTemplate, error) {
+	tmplOpt := &metadata.CommonQueryOption{
+		CommonFilterOption: metadata.CommonFilterOption{
+			Filter: filtertools.GenAtomFilter(common.BKFieldID, filter.Equal, id),
+		},
+		Page: metadata.BasePage{Limit: common.BKNoLimit},
+	}
+
+	res, err := s.clientSet.CoreService().FieldTemplate().ListFieldTemplate(kit.Ctx, kit.Header, tmplOpt)
+	if err != nil {
+		blog.Errorf("find field template failed, err: %v, opt: %+v, rid: %s", err, tmplOpt, kit.Rid)
+		return nil, err
+	}
+
+	if len(res.Info) != 1 {
+		blog.Errorf("field template id is invalid, id: %d, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID)
+	}
+
+	return &res.Info[0], nil
+}
+
+// getFieldTemplateLatestVersion get the latest version of field template, 0 means that the field template has no
+// version, which is created before versioning and has not been updated since then
+func (s *service) getFieldTemplateLatestVersion(kit *rest.Kit, id int64) (int64, error) {
+	template, err := s.getFieldTemplateByID(kit, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return template.Version, nil
+}
+
+// syncFieldTemplateToObjects pin the objects to the field template version and create tasks to synchronize the
+// version to the objects, paused objects are skipped. version 0 means synchronize the current template content.
+func (s *service) syncFieldTemplateToObjects(kit *rest.Kit, templateID, version int64, objIDs []int64) ([]string,
+	error) {
+
+	idPausedMap, err := s.getObjectPausedAttr(kit, objIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	syncObjIDs := make([]int64, 0)
+	tasks := make([]metadata.CreateTaskRequest, 0)
+	for _, objID := range objIDs {
+		if idPausedMap[objID] {
+			blog.Warnf("the model has been paused, no attr synchronization, object id: %d, rid: %s", objID, kit.Rid)
+			continue
+		}
+
+		syncObjIDs = append(syncObjIDs, objID)
+		tasks = append(tasks, metadata.CreateTaskRequest{
+			TaskType: common.SyncFieldTemplateTaskFlag,
+			InstID:   templateID,
+			Extra:    objID,
+			Data: []interface{}{metadata.SyncObjectTask{
+				TemplateID: templateID,
+				ObjectID:   objID,
+			}},
+		})
+	}
+
+	if version != 0 && len(syncObjIDs) > 0 {
+		pinOpt := &metadata.UpgradeObjFieldTmplVersionOption{
+			TemplateID: templateID,
+			ObjectIDs:  syncObjIDs,
+			Version:    version,
+		}
+		if err = s.clientSet.CoreService().FieldTemplate().UpdateObjFieldTmplRelVersion(kit.Ctx, kit.Header,
+			pinOpt); err != nil {
+			blog.Errorf("pin objects to field template version failed, opt: %+v, err: %v, rid: %s", pinOpt, err,
+				kit.Rid)
+			return nil, err
+		}
+	}
+
+	taskRes, err := s.clientSet.TaskServer().Task().CreateFieldTemplateBatch(kit.Ctx, kit.Header, tasks)
+	if err != nil {
+		blog.Errorf("create field template sync task(%#v) failed, err: %v, rid: %s", tasks, err, kit.Rid)
+		return nil, err
+	}
+
+	taskIDs := make([]string, 0)
+	for id := range taskRes {
+		taskIDs = append(taskIDs, taskRes[id].TaskID)
+	}
+	blog.V(4).Infof("successfully created field template sync task: %#v, rid: %s", taskRes, kit.Rid)
+
+	return taskIDs, nil
+}
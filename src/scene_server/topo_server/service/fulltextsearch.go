@@ -17,8 +17,6 @@
 package service
 
 import (
-	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -35,8 +33,7 @@ import (
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
-
-	"github.com/olivere/elastic/v7"
+	"configcenter/src/scene_server/topo_server/logics/fulltext"
 )
 
 var (
@@ -84,9 +81,6 @@ var (
 
 	// esQueryStringLengthLimit query_string length limit(utf-8).
 	esQueryStringLengthLimit = 50
-
-	// tableFulltextRegex table fulltext search regex.
-	tableFulltextRegex = fmt.Sprintf("%s...%s", metadata.TablePropertyName, metadata.IndexPropertyTypeKeyword)
 )
 
 // SearchResult fulltext search result.
@@ -178,12 +172,12 @@ type FullTextSearchCondition struct {
 	Conditions map[string]interface{}
 }
 
-// FullTextSearchESQuery fulltext search elastic query.
-type FullTextSearchESQuery struct {
-	// Query elastic query.
-	Query elastic.Query
+// FullTextSearchCountQuery fulltext search sub-count query.
+type FullTextSearchCountQuery struct {
+	// Query fulltext search engine query.
+	Query *fulltext.Query
 
-	// Condition elastic search condition.
+	// Condition fulltext search condition.
 	Condition *FullTextSearchCondition
 }
 
@@ -286,9 +280,9 @@ func (r *FullTextSearchReq) Validate() error {
 	return nil
 }
 
-// generateESQueryConditions parse and handle models/instances filter, generate main elastic
+// generateQueryConditions parse and handle models/instances filter, generate main
 // query, sub-count aggregations query.
-func (r *FullTextSearchFilter) generateESQueryConditions() []*FullTextSearchCondition {
+func (r *FullTextSearchFilter) generateQueryConditions() []*FullTextSearchCondition {
 
 	searchFilterConditions := make([]*FullTextSearchCondition, 0)
 	for _, model := range r.Models {
@@ -355,9 +349,9 @@ func (r *FullTextSearchFilter) generateESQueryConditions() []*FullTextSearchCond
 	return searchFilterConditions
 }
 
-// GenerateESQuery returns the elastic query for main search and sub-count searches.
-func (r *FullTextSearchReq) GenerateESQuery() (elastic.Query, []string, []*FullTextSearchESQuery) {
-	// elastic query conditions for each model or instance.
+// GenerateQuery returns the fulltext search engine query for main search and sub-count searches.
+func (r *FullTextSearchReq) GenerateQuery() (*fulltext.Query, []string, []*FullTextSearchCountQuery) {
+	// query conditions for each model or instance.
 	var (
 		// main search use objIdCond firstly.
 		bObjIdFlag bool
@@ -366,43 +360,36 @@ func (r *FullTextSearchReq) GenerateESQuery() (elastic.Query, []string, []*FullT
 		subResource []*FullTextSearchCondition
 	)
 
-	// build elastic count aggregations conditions for search.
-	subCountQueries := make([]*FullTextSearchESQuery, 0)
+	// build count aggregations conditions for search.
+	subCountQueries := make([]*FullTextSearchCountQuery, 0)
 
-	// build elastic main query and indexes for search.
+	// build main query and indexes for search.
 	indexes := make([]string, 0)
 
-	filterCond := r.Filter.generateESQueryConditions()
+	filterCond := r.Filter.generateQueryConditions()
 
 	if r.SubResource != nil {
-		subResource = r.SubResource.generateESQueryConditions()
+		subResource = r.SubResource.generateQueryConditions()
 	}
 	indexMap := make(map[string]struct{})
 
 	// main query.
-	query := elastic.NewBoolQuery()
 	queryConditions := make(map[string][]interface{})
-	if len(r.OwnerID) != 0 {
-		query.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKSupplierAccount, r.OwnerID))
-	}
-	if len(r.BizID) != 0 {
-		query.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKBizID, r.BizID))
+	query := &fulltext.Query{
+		OwnerID:     r.OwnerID,
+		BizID:       r.BizID,
+		QueryString: r.QueryString,
+		Conditions:  queryConditions,
 	}
-	// NOTE: 搜索文档同时支持属性和表格属性的keyword搜索
-	stringQuery := elastic.NewQueryStringQuery(r.QueryString).
-		Field(metadata.IndexPropertyKeywords).
-		Field(tableFulltextRegex)
-
-	query.Must(stringQuery)
 
 	//  main search select objIdCond firstly.
 	if len(subResource) > 0 {
 		for _, cond := range subResource {
-			// build elastic main query condition.
+			// build main query condition.
 			for property, value := range cond.Conditions {
 				queryConditions[property] = append(queryConditions[property], value)
 			}
-			// build elastic main query indexes.
+			// build main query indexes.
 			if _, exist := indexMap[cond.IndexName]; !exist {
 				indexes = append(indexes, cond.IndexName)
 				indexMap[cond.IndexName] = struct{}{}
@@ -413,12 +400,11 @@ func (r *FullTextSearchReq) GenerateESQuery() (elastic.Query, []string, []*FullT
 
 	// sub aggregations query.
 	for _, condFilter := range filterCond {
-		boolQuery := elastic.NewBoolQuery()
-		if len(r.OwnerID) != 0 {
-			boolQuery.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKSupplierAccount, r.OwnerID))
-		}
-		if len(r.BizID) != 0 {
-			boolQuery.Must(elastic.NewMatchQuery(metadata.IndexPropertyBKBizID, r.BizID))
+		subQuery := &fulltext.Query{
+			OwnerID:     r.OwnerID,
+			BizID:       r.BizID,
+			QueryString: r.QueryString,
+			Conditions:  make(map[string][]interface{}),
 		}
 
 		// handle filter conditions.
@@ -427,7 +413,7 @@ func (r *FullTextSearchReq) GenerateESQuery() (elastic.Query, []string, []*FullT
 				//  when objId is nil  main query use filterCond
 				queryConditions[property] = append(queryConditions[property], value)
 			}
-			boolQuery.Must(elastic.NewMatchQuery(property, value))
+			subQuery.Conditions[property] = []interface{}{value}
 		}
 
 		// if objId is nil use condFilter build main query indexes.
@@ -438,22 +424,17 @@ func (r *FullTextSearchReq) GenerateESQuery() (elastic.Query, []string, []*FullT
 			}
 		}
 
-		// assign sub aggregation conditions and field.
-		boolQuery.Must(stringQuery)
-		subCountQueries = append(subCountQueries, &FullTextSearchESQuery{Query: boolQuery, Condition: condFilter})
-	}
-
-	// add all sub aggregation conditions to main query.
-	for property, value := range queryConditions {
-		query.Must(elastic.NewTermsQuery(property, value...))
+		// assign sub aggregation conditions.
+		subCountQueries = append(subCountQueries, &FullTextSearchCountQuery{Query: subQuery, Condition: condFilter})
 	}
 
 	return query, indexes, subCountQueries
 }
 
 // fullTextAggregation count aggregations in multi goroutines mode.
-func (s *Service) fullTextAggregation(ctx *rest.Contexts, esQueries []*FullTextSearchESQuery) ([]Aggregation, error) {
-	// elastic pipeline sub aggregation search.
+func (s *Service) fullTextAggregation(ctx *rest.Contexts, countQueries []*FullTextSearchCountQuery) ([]Aggregation,
+	error) {
+	// pipeline sub aggregation search.
 	var (
 		pipelineErr error
 		wg          sync.WaitGroup
@@ -462,36 +443,37 @@ func (s *Service) fullTextAggregation(ctx *rest.Contexts, esQueries []*FullTextS
 	// control max gcoroutines num.
 	pipeline := make(chan struct{}, esPipelineConcurrency)
 	// pipeline results.
-	aggregationQueryTmp := make([]Aggregation, len(esQueries))
+	aggregationQueryTmp := make([]Aggregation, len(countQueries))
 	aggregationQueryResults := make([]Aggregation, 0)
 
 	// search with multi goroutines.
-	for idx, query := range esQueries {
+	for idx, query := range countQueries {
 		// try to start one search.
 		pipeline <- struct{}{}
 		wg.Add(1)
 
 		// start one search gcoroutine.
-		go func(ctx *rest.Contexts, idx int, esQuery *FullTextSearchESQuery) {
+		go func(ctx *rest.Contexts, idx int, countQuery *FullTextSearchCountQuery) {
 			defer func() {
 				// one search gcoroutine done.
 				wg.Done()
 				<-pipeline
 			}()
 
-			count, err := s.Es.Count(ctx.Kit.Ctx, esQuery.Query, []string{esQuery.Condition.IndexName})
+			count, err := s.FullText.Count(ctx.Kit.Ctx, countQuery.Query, []string{countQuery.Condition.IndexName})
 			if err != nil {
-				blog.Errorf("fulltext search count failed,query cond: %s err: %+v, rid: %s", esQuery.Query, err, ctx.Kit.Rid)
+				blog.Errorf("fulltext search count failed, query cond: %+v, err: %+v, rid: %s", countQuery.Query, err,
+					ctx.Kit.Rid)
 				pipelineErr = err
 				return
 			}
 
 			aggregation := Aggregation{
 				Kind:  metadata.DataKindInstance,
-				Key:   util.GetStrByInterface(esQuery.Condition.Conditions[metadata.IndexPropertyBKObjID]),
+				Key:   util.GetStrByInterface(countQuery.Condition.Conditions[metadata.IndexPropertyBKObjID]),
 				Count: count,
 			}
-			if esQuery.Condition.IndexName == metadata.IndexNameModel {
+			if countQuery.Condition.IndexName == metadata.IndexNameModel {
 				aggregation.Kind = metadata.DataKindModel
 			}
 			aggregationQueryTmp[idx] = aggregation
@@ -514,8 +496,8 @@ func (s *Service) fullTextAggregation(ctx *rest.Contexts, esQueries []*FullTextS
 	return aggregationQueryResults, nil
 }
 
-// fullTextMetadata returns metadata base on the elastic hits.
-func (s *Service) fullTextMetadata(ctx *rest.Contexts, hits []*elastic.SearchHit, request FullTextSearchReq) (
+// fullTextMetadata returns metadata base on the fulltext search hits.
+func (s *Service) fullTextMetadata(ctx *rest.Contexts, hits []*fulltext.Hit, request FullTextSearchReq) (
 	[]SearchResult, error) {
 
 	// for meta_bk_obj_id in es.
@@ -525,17 +507,13 @@ func (s *Service) fullTextMetadata(ctx *rest.Contexts, hits []*elastic.SearchHit
 	instMetadataConditions := make(map[string][]int64)
 
 	// search the highlight fields for instance.
-	insHits := make(map[string]map[int64]*elastic.SearchHit)
+	insHits := make(map[string]map[int64]*fulltext.Hit)
 
 	// search the highlight fields for model.
-	objHits := make(map[string]*elastic.SearchHit)
+	objHits := make(map[string]*fulltext.Hit)
 
 	for _, hit := range hits {
-		source := make(map[string]interface{})
-		if err := json.Unmarshal(hit.Source, &source); err != nil {
-			blog.Warnf("fulltext handle search result source data failed, err: %+v,  rid: %s", err, ctx.Kit.Rid)
-			continue
-		}
+		source := hit.Source
 		objectID := util.GetStrByInterface(source[metadata.IndexPropertyBKObjID])
 		dataKind := util.GetStrByInterface(source[metadata.IndexPropertyDataKind])
 		metaID, err := strconv.ParseInt(util.GetStrByInterface(source[metadata.IndexPropertyID]), 10, 64)
@@ -550,7 +528,7 @@ func (s *Service) fullTextMetadata(ctx *rest.Contexts, hits []*elastic.SearchHit
 		} else if dataKind == metadata.DataKindInstance {
 			instMetadataConditions[objectID] = append(instMetadataConditions[objectID], metaID)
 			if insHits[objectID] == nil {
-				insHits[objectID] = make(map[int64]*elastic.SearchHit)
+				insHits[objectID] = make(map[int64]*fulltext.Hit)
 			}
 			insHits[objectID][metaID] = hit
 
@@ -610,7 +588,7 @@ func fullTextSearchForInstanceCond(objectID string, ids []int64) *metadata.Searc
 
 // fullTextSearchForInstance search instance result.
 func (s *Service) fullTextSearchForInstance(ctx *rest.Contexts, instMetadataConditions map[string][]int64,
-	insHits map[string]map[int64]*elastic.SearchHit, request FullTextSearchReq) []SearchResult {
+	insHits map[string]map[int64]*fulltext.Hit, request FullTextSearchReq) []SearchResult {
 
 	searchResults := make([]SearchResult, 0)
 	if len(instMetadataConditions) == 0 {
@@ -680,7 +658,7 @@ func (s *Service) fullTextSearchForInstance(ctx *rest.Contexts, instMetadataCond
 				// instance result
 				searchRes := SearchResult{}
 				rawString := strings.Trim(request.QueryString, "*")
-				searchRes.setHit(ctx.Kit.Ctx, insHits[objectID][id], request.BizID, rawString)
+				searchRes.setHit(insHits[objectID][id], request.BizID, rawString)
 				searchRes.Kind = metadata.DataKindInstance
 				searchRes.Key = objectID
 				searchRes.Source = instance
@@ -701,7 +679,7 @@ func (s *Service) fullTextSearchForInstance(ctx *rest.Contexts, instMetadataCond
 
 // fullTextSearchForObject search object result.
 func (s *Service) fullTextSearchForObject(ctx *rest.Contexts, objectIDs []string,
-	objHits map[string]*elastic.SearchHit, request FullTextSearchReq) []SearchResult {
+	objHits map[string]*fulltext.Hit, request FullTextSearchReq) []SearchResult {
 
 	modelCondition := &metadata.QueryCondition{
 		Fields:         request.Fields,
@@ -726,7 +704,7 @@ func (s *Service) fullTextSearchForObject(ctx *rest.Contexts, objectIDs []string
 	for _, object := range objects.Info {
 		searchRes := SearchResult{}
 		rawString := strings.Trim(request.QueryString, "*")
-		searchRes.setHit(ctx.Kit.Ctx, objHits[object.ObjectID], request.BizID, rawString)
+		searchRes.setHit(objHits[object.ObjectID], request.BizID, rawString)
 		searchRes.Kind = metadata.DataKindModel
 		searchRes.Key = object.ObjectID
 		searchRes.Source = object
@@ -738,8 +716,8 @@ func (s *Service) fullTextSearchForObject(ctx *rest.Contexts, objectIDs []string
 
 // FullTextSearch fulltext search service.
 func (s *Service) FullTextSearch(ctx *rest.Contexts) {
-	// check elastic client.
-	if s.Es.Client == nil {
+	// check fulltext search engine.
+	if s.FullText == nil {
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoFullTextClientNotInitialized))
 		return
 	}
@@ -762,27 +740,15 @@ func (s *Service) FullTextSearch(ctx *rest.Contexts) {
 		return
 	}
 
-	// generate elastic query.
-	esQuery, indexes, subCountQueries := request.GenerateESQuery()
-
-	mainESQuery, err := esQuery.Source()
-	if err != nil {
-		blog.Errorf("fulltext parse mainESQuery fail: err: %+v, rid: %s", err, ctx.Kit.Rid)
-		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoFullTextFindErr))
-		return
-	}
-	blog.V(5).Infof("fulltext main query[%s], indexes[%s], rid: %s", mainESQuery, indexes, ctx.Kit.Rid)
+	// generate fulltext search query.
+	mainQuery, indexes, subCountQueries := request.GenerateQuery()
+	blog.V(5).Infof("fulltext main query[%+v], indexes[%s], rid: %s", mainQuery, indexes, ctx.Kit.Rid)
 
 	// main search.
-	mainSearchResult, err := s.Es.Search(ctx.Kit.Ctx, esQuery, indexes, request.Page.Start, request.Page.Limit)
+	mainSearchResult, err := s.FullText.Search(ctx.Kit.Ctx, mainQuery, indexes, request.Page.Start,
+		request.Page.Limit)
 	if err != nil {
-		blog.Errorf("fulltext main search failed,mainESQuery: %s err: %+v, rid: %s", mainESQuery, err, ctx.Kit.Rid)
-		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoFullTextFindErr))
-		return
-	}
-
-	if mainSearchResult.Hits == nil || mainSearchResult.Hits.TotalHits == nil {
-		blog.Errorf("fulltext main search failed, invalid search result, rid: %s", ctx.Kit.Rid)
+		blog.Errorf("fulltext main search failed, query: %+v, err: %+v, rid: %s", mainQuery, err, ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoFullTextFindErr))
 		return
 	}
@@ -800,10 +766,10 @@ func (s *Service) FullTextSearch(ctx *rest.Contexts) {
 		total += agg.Count
 	}
 	// build response data.
-	// when objId is not nil,mainSearchResult.Hits.TotalHits.Value is inaccurate ,
+	// when objId is not nil,mainSearchResult.Total is inaccurate ,
 	// so we must use sum of each subCountQueries result
 	response := FullTextSearchResp{}
-	if mainSearchResult.Hits.TotalHits.Value == 0 {
+	if mainSearchResult.Total == 0 {
 		ctx.RespEntity(response)
 		return
 	}
@@ -811,7 +777,7 @@ func (s *Service) FullTextSearch(ctx *rest.Contexts) {
 	response.Aggregations = aggregations
 
 	// metadata search.
-	metadatas, err := s.fullTextMetadata(ctx, mainSearchResult.Hits.Hits, request)
+	metadatas, err := s.fullTextMetadata(ctx, mainSearchResult.Hits, request)
 	if err != nil {
 		blog.Errorf("fulltext metadata search failed, err: %+v, rid: %s", err, ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoFullTextFindErr))
@@ -886,28 +852,18 @@ func (s *Service) getObjAttrs(kit *rest.Kit, hits []SearchResult) (*AttrResult,
 }
 
 // setHit get highlight words.
-func (sr *SearchResult) setHit(ctx context.Context, searchHit *elastic.SearchHit, bkBizId, rawString string) {
+func (sr *SearchResult) setHit(searchHit *fulltext.Hit, bkBizId, rawString string) {
 
 	if searchHit == nil {
 		return
 	}
 
-	rid := util.ExtractRequestIDFromContext(ctx)
-	sourceTmp := make(map[string]interface{})
-
-	err := json.Unmarshal(searchHit.Source, &(sourceTmp))
-	if err != nil {
-		blog.Warnf("full_text_find unmarshal search result source err: %+v, rid: %s", err, rid)
-		sr.Source = nil
-		return
-	}
-
-	sr.dealHighlight(sourceTmp, searchHit.Highlight, bkBizId, rawString)
+	sr.dealHighlight(searchHit.Source, searchHit.Highlight, bkBizId, rawString)
 	return
 }
 
 // dealHighlight 此函数会处理掉一些不需要展示出来的内部关系id，防止高亮出一些用户原本不希望高亮的字段
-func (sr *SearchResult) dealHighlight(source map[string]interface{}, highlight elastic.SearchHitHighlight,
+func (sr *SearchResult) dealHighlight(source map[string]interface{}, highlight map[string][]string,
 	bkBizId, rawString string) {
 
 	isObject := true
@@ -0,0 +1,596 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kube
+
+import (
+	"errors"
+
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	acmeta "configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+)
+
+// CreateNsResource create namespace resource, such as service, ingress, configMap and persistentVolumeClaim
+func (s *service) CreateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		blog.Errorf("namespace resource kind is invalid, kind: %v, err: %v, rid: %s", kind, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	req := types.NsResCreateOption{Kind: kind}
+	if err := ctx.DecodeInto(&req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNsResource, Action: acmeta.Create},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	var data *metadata.RspIDs
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		res, err := s.createNsResource(ctx.Kit, kind, req)
+		if err != nil {
+			return err
+		}
+		data = res
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(data)
+}
+
+func (s *service) createNsResource(kit *rest.Kit, kind types.NsResourceType, req types.NsResCreateOption) (
+	*metadata.RspIDs, error) {
+
+	data, err := s.ClientSet.CoreService().Kube().CreateNsResource(kit.Ctx, kit.Header, kind, req.Data)
+	if err != nil {
+		blog.Errorf("create %s failed, data: %v, err: %v, rid: %s", kind, req, err, kit.Rid)
+		return nil, err
+	}
+
+	// audit log.
+	audit := auditlog.NewKubeAudit(s.ClientSet.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditCreate)
+	for idx := range req.Data {
+		base := req.Data[idx].GetNsResourceBase()
+		base.BizID = req.BizID
+		base.ID = data.IDs[idx]
+		base.SupplierAccount = kit.SupplierAccount
+		req.Data[idx].SetNsResourceBase(base)
+	}
+
+	auditLogs, err := audit.GenerateNsResourceAuditLog(auditParam, req.Data, kind)
+	if err != nil {
+		blog.Errorf("generate audit log failed, ids: %v, err: %v, rid: %s", data.IDs, err, kit.Rid)
+		return nil, err
+	}
+
+	if err = audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save audit log failed, ids: %v, err: %v, rid: %s", data.IDs, err, kit.Rid)
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UpdateNsResource update namespace resource
+func (s *service) UpdateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	req := new(types.NsResUpdateOption)
+	req.Kind = kind
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNsResource, Action: acmeta.Update},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	resData, err := s.checkNsResourceData(ctx.Kit, req.BizID, req.IDs, kind)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(resData) == 0 {
+		blog.Errorf("no %s founded, bizID: %d, data: %v, rid: %s", kind, req.BizID, req, ctx.Kit.Rid)
+		ctx.RespAutoError(errors.New("no namespace resource founded"))
+		return
+	}
+
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		err = s.ClientSet.CoreService().Kube().UpdateNsResource(ctx.Kit.Ctx, ctx.Kit.Header, kind,
+			&req.NsResUpdateByIDsOption)
+		if err != nil {
+			blog.Errorf("update %s failed, data: %v, err: %v, rid: %s", kind, req, err, ctx.Kit.Rid)
+			return err
+		}
+
+		audit := auditlog.NewKubeAudit(s.ClientSet.CoreService())
+		auditParam := auditlog.NewGenerateAuditCommonParameter(ctx.Kit, metadata.AuditUpdate)
+		updateFields, goErr := mapstr.Struct2Map(req.Data)
+		if goErr != nil {
+			blog.Errorf("update fields convert failed, err: %v, rid: %s", goErr, ctx.Kit.Rid)
+			return goErr
+		}
+		auditParam.WithUpdateFields(updateFields)
+		auditLogs, err := audit.GenerateNsResourceAuditLog(auditParam, resData, kind)
+		if err != nil {
+			blog.Errorf("generate audit log failed, data: %v, err: %v, rid: %s", resData, err, ctx.Kit.Rid)
+			return err
+		}
+		if err := audit.SaveAuditLog(ctx.Kit, auditLogs...); err != nil {
+			blog.Errorf("save audit log failed, data: %v, err: %v, rid: %s", resData, err, ctx.Kit.Rid)
+			return err
+		}
+
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteNsResource delete namespace resource
+func (s *service) DeleteNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	req := new(types.NsResDeleteOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNsResource, Action: acmeta.Delete},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	resData, err := s.checkNsResourceData(ctx.Kit, req.BizID, req.IDs, kind)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// if all namespace resources are already deleted, return
+	if len(resData) == 0 {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		err = s.ClientSet.CoreService().Kube().DeleteNsResource(ctx.Kit.Ctx, ctx.Kit.Header, kind,
+			&req.NsResDeleteByIDsOption)
+		if err != nil {
+			blog.Errorf("delete %s failed, data: %v, err: %v, rid: %s", kind, req, err, ctx.Kit.Rid)
+			return err
+		}
+
+		audit := auditlog.NewKubeAudit(s.ClientSet.CoreService())
+		auditParam := auditlog.NewGenerateAuditCommonParameter(ctx.Kit, metadata.AuditDelete)
+		auditLogs, err := audit.GenerateNsResourceAuditLog(auditParam, resData, kind)
+		if err != nil {
+			blog.Errorf("generate audit log failed, data: %v, err: %v, rid: %s", resData, err, ctx.Kit.Rid)
+			return err
+		}
+		if err := audit.SaveAuditLog(ctx.Kit, auditLogs...); err != nil {
+			blog.Errorf("save audit log failed, data: %v, err: %v, rid: %s", resData, err, ctx.Kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// checkNsResourceData get namespace resources by ids, and check if they belong to the biz or its shared namespaces
+func (s *service) checkNsResourceData(kit *rest.Kit, bizID int64, ids []int64, kind types.NsResourceType) (
+	[]types.NsResourceInterface, error) {
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: ids}},
+	}
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(kit.Ctx, kit.Header, query, kind)
+	if err != nil {
+		blog.Errorf("list %s failed, bizID: %d, ids: %+v, err: %v, rid: %s", kind, bizID, ids, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(resp.Info) == 0 {
+		return nil, nil
+	}
+
+	mismatchNsIDs := make([]int64, 0)
+	for _, res := range resp.Info {
+		base := res.GetNsResourceBase()
+		if base.BizID != bizID {
+			mismatchNsIDs = append(mismatchNsIDs, base.NamespaceID)
+		}
+	}
+
+	if len(mismatchNsIDs) > 0 {
+		mismatchNsMap := map[int64][]int64{bizID: mismatchNsIDs}
+		if err := s.Logics.KubeOperation().CheckPlatBizSharedNs(kit, mismatchNsMap); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Info, nil
+}
+
+// ListNsResource list namespace resource
+func (s *service) ListNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	table, err := kind.Table()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.NsResQueryOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(kind); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNsResource, Action: acmeta.Find},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	// compatible for shared cluster scenario
+	cond, err := s.Logics.KubeOperation().GenSharedNsListCond(ctx.Kit, string(kind), req.BizID, req.Filter)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if req.Page.EnableCount {
+		counts, err := s.ClientSet.CoreService().Count().GetCountByFilter(ctx.Kit.Ctx, ctx.Kit.Header, table,
+			[]map[string]interface{}{cond})
+		if err != nil {
+			blog.Errorf("count %s failed, table: %s, cond: %v, err: %v, rid: %s", kind, table, cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+		ctx.RespEntityWithCount(counts[0], make([]mapstr.MapStr, 0))
+		return
+	}
+
+	if req.Page.Sort == "" {
+		req.Page.Sort = common.BKFieldID
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Page:      req.Page,
+		Fields:    req.Fields,
+	}
+
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(ctx.Kit.Ctx, ctx.Kit.Header, query, kind)
+	if err != nil {
+		blog.Errorf("list %s failed, bizID: %d, cond: %v, err: %v, rid: %s", kind, req.BizID, query, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(resp.Info) == 0 {
+		ctx.RespEntityWithCount(0, []mapstr.MapStr{})
+		return
+	}
+
+	ctx.RespEntityWithCount(0, resp.Info)
+}
+
+// FindNsResourceRelation find the pods and workloads related to namespace resources. the relations are not stored,
+// they are resolved from the pods in the same namespace: service selects pods by labels, ingress routes to
+// services, configMap and persistentVolumeClaim are mounted by pods as volumes.
+func (s *service) FindNsResourceRelation(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	req := new(types.NsResRelationOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNsResource, Action: acmeta.Find},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	idFilter := filtertools.GenAtomFilter(types.BKIDField, filter.In, req.IDs)
+	cond, err := s.Logics.KubeOperation().GenSharedNsListCond(ctx.Kit, string(kind), req.BizID, idFilter)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Page:      metadata.BasePage{Limit: types.NsResRelationLimit},
+	}
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(ctx.Kit.Ctx, ctx.Kit.Header, query, kind)
+	if err != nil {
+		blog.Errorf("list %s failed, cond: %v, err: %v, rid: %s", kind, query, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.resolveNsResourceRelation(ctx.Kit, kind, resp.Info)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// resolveNsResourceRelation resolve the relations of namespace resources namespace by namespace
+func (s *service) resolveNsResourceRelation(kit *rest.Kit, kind types.NsResourceType,
+	resources []types.NsResourceInterface) ([]types.NsResRelation, error) {
+
+	nsResMap := make(map[int64][]types.NsResourceInterface)
+	for _, res := range resources {
+		nsID := res.GetNsResourceBase().NamespaceID
+		nsResMap[nsID] = append(nsResMap[nsID], res)
+	}
+
+	result := make([]types.NsResRelation, 0, len(resources))
+	for nsID, nsResources := range nsResMap {
+		pods, err := s.listNamespacePods(kit, nsID)
+		if err != nil {
+			return nil, err
+		}
+
+		var services []types.NsResourceInterface
+		if kind == types.KubeIngress {
+			services, err = s.listIngressBackendServices(kit, nsID, nsResources)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, res := range nsResources {
+			relation := types.NsResRelation{ID: res.GetNsResourceBase().ID}
+			matchedPods := make([]types.Pod, 0)
+
+			switch inst := res.(type) {
+			case *types.Service:
+				matchedPods = podsSelectedByService(inst, pods)
+			case *types.Ingress:
+				relation.Services = make([]types.KubeObjectInfo, 0)
+				backends := make(map[string]struct{})
+				for _, name := range inst.BackendServices() {
+					backends[name] = struct{}{}
+				}
+				for _, svc := range services {
+					base := svc.GetNsResourceBase()
+					if _, exists := backends[base.Name]; !exists {
+						continue
+					}
+					relation.Services = append(relation.Services, types.KubeObjectInfo{ID: base.ID,
+						Name: base.Name, Kind: string(types.KubeService)})
+					matchedPods = append(matchedPods, podsSelectedByService(svc.(*types.Service), pods)...)
+				}
+			case *types.ConfigMap:
+				for idx := range pods {
+					if inst.MountedBy(&pods[idx]) {
+						matchedPods = append(matchedPods, pods[idx])
+					}
+				}
+			case *types.PersistentVolumeClaim:
+				for idx := range pods {
+					if inst.MountedBy(&pods[idx]) {
+						matchedPods = append(matchedPods, pods[idx])
+					}
+				}
+			}
+
+			relation.Pods, relation.Workloads = buildPodRelationInfo(matchedPods)
+			result = append(result, relation)
+		}
+	}
+
+	return result, nil
+}
+
+// listNamespacePods list all pods in the namespace with the fields needed to resolve namespace resource relations
+func (s *service) listNamespacePods(kit *rest.Kit, nsID int64) ([]types.Pod, error) {
+	pods := make([]types.Pod, 0)
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{types.BKNamespaceIDField: nsID},
+		Fields: []string{types.BKIDField, types.KubeNameField, types.LabelsField, types.VolumesField,
+			types.RefField},
+		Page: metadata.BasePage{Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+	}
+
+	for {
+		resp, err := s.ClientSet.CoreService().Kube().ListPod(kit.Ctx, kit.Header, query)
+		if err != nil {
+			blog.Errorf("list pods failed, namespace id: %d, err: %v, rid: %s", nsID, err, kit.Rid)
+			return nil, err
+		}
+
+		pods = append(pods, resp.Info...)
+		if len(resp.Info) < common.BKMaxLimitSize {
+			break
+		}
+		query.Page.Start += common.BKMaxLimitSize
+	}
+
+	return pods, nil
+}
+
+// listIngressBackendServices list the services in the namespace that the ingresses route traffic to
+func (s *service) listIngressBackendServices(kit *rest.Kit, nsID int64, ingresses []types.NsResourceInterface) (
+	[]types.NsResourceInterface, error) {
+
+	names := make([]string, 0)
+	for _, res := range ingresses {
+		ingress, ok := res.(*types.Ingress)
+		if !ok {
+			continue
+		}
+		names = append(names, ingress.BackendServices()...)
+	}
+
+	if len(names) == 0 {
+		return make([]types.NsResourceInterface, 0), nil
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			types.BKNamespaceIDField: nsID,
+			types.KubeNameField:      mapstr.MapStr{common.BKDBIN: names},
+		},
+	}
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(kit.Ctx, kit.Header, query, types.KubeService)
+	if err != nil {
+		blog.Errorf("list ingress backend services failed, cond: %v, err: %v, rid: %s", query, err, kit.Rid)
+		return nil, err
+	}
+
+	return resp.Info, nil
+}
+
+// podsSelectedByService get the pods whose labels match the service selector
+func podsSelectedByService(svc *types.Service, pods []types.Pod) []types.Pod {
+	matched := make([]types.Pod, 0)
+	for _, pod := range pods {
+		if pod.Labels == nil {
+			continue
+		}
+		if svc.SelectsPod(*pod.Labels) {
+			matched = append(matched, pod)
+		}
+	}
+	return matched
+}
+
+// buildPodRelationInfo build the deduplicated pod and workload info of the related pods
+func buildPodRelationInfo(pods []types.Pod) ([]types.KubeObjectInfo, []types.KubeObjectInfo) {
+	podInfos := make([]types.KubeObjectInfo, 0)
+	wlInfos := make([]types.KubeObjectInfo, 0)
+	podExists := make(map[int64]struct{})
+	wlExists := make(map[types.Reference]struct{})
+
+	for _, pod := range pods {
+		if _, exists := podExists[pod.ID]; exists {
+			continue
+		}
+		podExists[pod.ID] = struct{}{}
+
+		info := types.KubeObjectInfo{ID: pod.ID, Kind: types.KubePod}
+		if pod.Name != nil {
+			info.Name = *pod.Name
+		}
+		podInfos = append(podInfos, info)
+
+		if pod.Ref == nil || pod.Ref.ID == 0 {
+			continue
+		}
+		if _, exists := wlExists[*pod.Ref]; exists {
+			continue
+		}
+		wlExists[*pod.Ref] = struct{}{}
+		wlInfos = append(wlInfos, types.KubeObjectInfo{ID: pod.Ref.ID, Name: pod.Ref.Name, Kind: string(pod.Ref.Kind)})
+	}
+
+	return podInfos, wlInfos
+}
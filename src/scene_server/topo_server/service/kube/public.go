@@ -585,6 +585,19 @@ func (s *service) GetKubeSubTopoObject(kit *rest.Kit, object string, id int64, b
 func (s *service) FindResourceAttrs(ctx *rest.Contexts) {
 
 	object := ctx.Request.PathParameter("object")
+	if descriptors, err := types.NsResourceType(object).SpecFieldsDescriptor(); err == nil {
+		result := make([]types.KubeAttrsRsp, 0)
+		for _, descriptor := range descriptors {
+			result = append(result, types.KubeAttrsRsp{
+				Field:    descriptor.Field,
+				Type:     string(descriptor.Type),
+				Required: descriptor.IsRequired,
+			})
+		}
+		ctx.RespEntity(result)
+		return
+	}
+
 	if !types.IsKubeTopoResource(object) {
 		blog.Errorf("the param is invalid and does not belong to the kube object(%s)", object)
 		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, "object"))
@@ -655,12 +668,14 @@ func (s *service) hasNextLevelResource(kit *rest.Kit, kind string, ids []int64)
 		tables = []string{types.BKTableNameBaseNamespace, types.BKTableNameBaseNode, types.BKTableNameBasePod}
 		workLoads := types.GetWorkLoadTables()
 		tables = append(tables, workLoads...)
+		tables = append(tables, types.GetNsResourceTables()...)
 		filter[types.BKClusterIDFiled] = map[string]interface{}{common.BKDBIN: ids}
 
 	case types.KubeNamespace:
 		tables = []string{types.BKTableNameBasePod}
 		workLoads := types.GetWorkLoadTables()
 		tables = append(tables, workLoads...)
+		tables = append(tables, types.GetNsResourceTables()...)
 		filter[types.BKNamespaceIDField] = map[string]interface{}{common.BKDBIN: ids}
 
 	default:
@@ -0,0 +1,191 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// SaveObjectLifecycle create or update the lifecycle state machine of the object
+func (s *Service) SaveObjectLifecycle(ctx *rest.Contexts) {
+	spec := new(metadata.LifecycleSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	opt := &metadata.SaveLifecycleOption{
+		ObjID:         ctx.Request.PathParameter(common.BKObjIDField),
+		LifecycleSpec: *spec,
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().Lifecycle().SaveLifecycle(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt); err != nil {
+		blog.Errorf("save object %s lifecycle failed, err: %v, opt: %+v, rid: %s", opt.ObjID, err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteObjectLifecycle delete the lifecycle state machine of the object
+func (s *Service) DeleteObjectLifecycle(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	if err := s.Engine.CoreAPI.CoreService().Lifecycle().DeleteLifecycle(ctx.Kit.Ctx, ctx.Kit.Header,
+		objID); err != nil {
+		blog.Errorf("delete object %s lifecycle failed, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// SearchObjectLifecycle search the lifecycle state machine of the object
+func (s *Service) SearchObjectLifecycle(ctx *rest.Contexts) {
+	opt := &metadata.FindLifecycleOption{ObjID: ctx.Request.PathParameter(common.BKObjIDField)}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	lifecycle, err := s.Engine.CoreAPI.CoreService().Lifecycle().FindLifecycle(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("search object %s lifecycle failed, err: %v, rid: %s", opt.ObjID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(lifecycle)
+}
+
+// TransitInstState transit the instance to another state of the object's lifecycle, the transition and its reason
+// are recorded in the audit log
+func (s *Service) TransitInstState(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	instID, err := strconv.ParseInt(ctx.Request.PathParameter("inst_id"), 10, 64)
+	if err != nil {
+		blog.Errorf("failed to parse the inst id, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, "inst_id"))
+		return
+	}
+
+	opt := new(metadata.TransitionInstOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	lifecycle, err := s.Engine.CoreAPI.CoreService().Lifecycle().FindLifecycle(ctx.Kit.Ctx, ctx.Kit.Header,
+		&metadata.FindLifecycleOption{ObjID: objID})
+	if err != nil {
+		blog.Errorf("search object %s lifecycle failed, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if lifecycle == nil {
+		blog.Errorf("object %s has no lifecycle, rid: %s", objID, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	cond := mapstr.MapStr{metadata.GetInstIDFieldByObjID(objID): instID}
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Page:      metadata.BasePage{Limit: 1},
+	}
+	insts, err := s.Engine.CoreAPI.CoreService().Instance().ReadInstance(ctx.Kit.Ctx, ctx.Kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("get object %s instance %d failed, err: %v, rid: %s", objID, instID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(insts.Info) == 0 {
+		blog.Errorf("object %s instance %d is not exist, rid: %s", objID, instID, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	detail := &metadata.LifecycleTransitionDetail{
+		PropertyID: lifecycle.PropertyID,
+		From:       util.GetStrByInterface(insts.Info[0][lifecycle.PropertyID]),
+		To:         opt.To,
+		Reason:     opt.Reason,
+	}
+	if detail.From == detail.To {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommLifecycleTransitionNotAllowed, detail.From,
+			detail.To))
+		return
+	}
+
+	data := mapstr.MapStr{lifecycle.PropertyID: opt.To}
+
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		audit := auditlog.NewInstanceAudit(s.Engine.CoreAPI.CoreService())
+		auditParam := auditlog.NewGenerateAuditCommonParameter(ctx.Kit, metadata.AuditTransition).
+			WithUpdateFields(data).WithTransition(detail)
+		auditLogs, err := audit.GenerateAuditLog(auditParam, objID, insts.Info)
+		if err != nil {
+			blog.Errorf("generate instance transition audit log failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			return err
+		}
+
+		// the transition is validated against the object's lifecycle by core service
+		header := util.CloneHeader(ctx.Kit.Header)
+		httpheader.SetLifecycleTransition(header)
+		updateOpt := &metadata.UpdateOption{Data: data, Condition: cond}
+		_, err = s.Engine.CoreAPI.CoreService().Instance().UpdateInstance(ctx.Kit.Ctx, header, objID, updateOpt)
+		if err != nil {
+			blog.Errorf("transit object %s instance %d state from %s to %s failed, err: %v, rid: %s", objID, instID,
+				detail.From, detail.To, err, ctx.Kit.Rid)
+			return err
+		}
+
+		if err := audit.SaveAuditLog(ctx.Kit, auditLogs...); err != nil {
+			blog.Errorf("save instance transition audit log failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			return ctx.Kit.CCError.Error(common.CCErrAuditSaveLogFailed)
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// ExportManifest export the models, field templates and business topology to a declarative manifest
+func (s *Service) ExportManifest(ctx *rest.Contexts) {
+	opt := new(metadata.ExportManifestOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	manifest, err := s.Logics.ManifestOperation().Export(ctx.Kit, opt)
+	if err != nil {
+		blog.Errorf("export manifest failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(manifest)
+}
+
+// PlanManifest compare the manifest with the live system, returns the changes that apply would make
+func (s *Service) PlanManifest(ctx *rest.Contexts) {
+	opt := new(metadata.PlanManifestOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	plan, err := s.Logics.ManifestOperation().Plan(ctx.Kit, &opt.Manifest)
+	if err != nil {
+		blog.Errorf("plan manifest failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(plan)
+}
+
+// ApplyManifest apply the reviewed plan of the manifest to the live system in a transaction
+func (s *Service) ApplyManifest(ctx *rest.Contexts) {
+	opt := new(metadata.ApplyManifestOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	plan, err := s.Logics.ManifestOperation().Plan(ctx.Kit, &opt.Manifest)
+	if err != nil {
+		blog.Errorf("plan manifest failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(plan.Conflicts) > 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommManifestHasConflicts, len(plan.Conflicts)))
+		return
+	}
+
+	if plan.Digest != opt.Digest {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommManifestPlanChanged))
+		return
+	}
+
+	// the tables of the new models can not be created in a transaction, so create them before applying
+	objIDs := make([]string, 0)
+	for _, change := range plan.Changes {
+		if change.Kind == metadata.ManifestKindModel && change.Action == metadata.ManifestActionCreate {
+			objIDs = append(objIDs, change.Key)
+		}
+	}
+
+	if len(objIDs) > 0 {
+		input := &metadata.CreateModelTable{IsMainLine: false, ObjectIDs: objIDs}
+		if err := s.Engine.CoreAPI.CoreService().Model().CreateModelTables(ctx.Kit.Ctx, ctx.Kit.Header,
+			input); err != nil {
+			blog.Errorf("create model tables failed, err: %v, objIDs: %v, rid: %s", err, objIDs, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		plan, err = s.Logics.ManifestOperation().Apply(ctx.Kit, &opt.Manifest, opt.Digest)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(plan)
+}
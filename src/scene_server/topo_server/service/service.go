@@ -29,7 +29,7 @@ import (
 	"configcenter/src/common/webservice/restfulservice"
 	"configcenter/src/scene_server/topo_server/app/options"
 	"configcenter/src/scene_server/topo_server/logics"
-	"configcenter/src/thirdparty/elasticsearch"
+	"configcenter/src/scene_server/topo_server/logics/fulltext"
 	"configcenter/src/thirdparty/logplatform/opentelemetry"
 
 	"github.com/emicklei/go-restful/v3"
@@ -41,7 +41,7 @@ type Service struct {
 	Logics      logics.Logics
 	Config      options.Config
 	AuthManager *extensions.AuthManager
-	Es          *elasticsearch.EsSrv
+	FullText    fulltext.Engine
 	Error       errors.CCErrorIf
 	Language    language.CCLanguageIf
 }
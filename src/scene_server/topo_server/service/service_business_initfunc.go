@@ -108,6 +108,42 @@ func (s *Service) initBusinessObjectUnique(web *restful.WebService) {
 	utility.AddToRestfulWebService(web)
 }
 
+func (s *Service) initBusinessObjectValidationRule(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectvalidationrule/object/{bk_obj_id}",
+		Handler: s.CreateObjectValidationRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut,
+		Path: "/update/objectvalidationrule/object/{bk_obj_id}/rule/{id}", Handler: s.UpdateObjectValidationRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/delete/objectvalidationrule/object/{bk_obj_id}/rule/{id}", Handler: s.DeleteObjectValidationRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectvalidationrule/object/{bk_obj_id}",
+		Handler: s.SearchObjectValidationRule})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *Service) initBusinessObjectLifecycle(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectlifecycle/object/{bk_obj_id}",
+		Handler: s.SaveObjectLifecycle})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/delete/objectlifecycle/object/{bk_obj_id}",
+		Handler: s.DeleteObjectLifecycle})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectlifecycle/object/{bk_obj_id}",
+		Handler: s.SearchObjectLifecycle})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/transition/instance/object/{bk_obj_id}/inst/{inst_id}", Handler: s.TransitInstState})
+
+	utility.AddToRestfulWebService(web)
+}
+
 func (s *Service) initBusinessObjectAttrGroup(web *restful.WebService) {
 	utility := rest.NewRestUtility(rest.Config{
 		ErrorIf:  s.Engine.CCErr,
@@ -214,6 +250,8 @@ func (s *Service) initBusinessAssociation(web *restful.WebService) {
 		Handler: s.SearchInstAssociationAndInstDetail})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/biz/{bk_biz_id}",
 		Handler: s.SearchAssociationInstWithBizID})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/graph",
+		Handler: s.SearchInstAssociationGraph})
 
 	// topo search methods
 	utility.AddHandler(rest.Action{Verb: http.MethodPost,
@@ -272,6 +310,8 @@ func (s *Service) initBusinessInst(web *restful.WebService) {
 		Handler: s.SearchObjectInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/count/instances/object/{bk_obj_id}",
 		Handler: s.CountObjectInstances})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/decrypt/instance/object/{bk_obj_id}/attribute",
+		Handler: s.DecryptInstAttr})
 
 	utility.AddToRestfulWebService(web)
 }
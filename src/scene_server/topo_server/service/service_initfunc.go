@@ -58,6 +58,10 @@ func (s *Service) initAuditLog(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/audit_list", Handler: s.SearchAuditList})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit", Handler: s.SearchAuditDetail})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/inst_audit", Handler: s.SearchInstAudit})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit/inst_snapshot",
+		Handler: s.SearchInstSnapshot})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit/inst_snapshot/diff",
+		Handler: s.DiffInstSnapshot})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -300,6 +304,41 @@ func (s *Service) initProject(web *restful.WebService) {
 	utility.AddToRestfulWebService(web)
 }
 
+func (s *Service) initAuthRole(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/auth_role", Handler: s.CreateAuthRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/auth_role/{id}", Handler: s.UpdateAuthRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/auth_role/{id}",
+		Handler: s.DeleteAuthRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/auth_role", Handler: s.SearchAuthRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/auth_role_binding",
+		Handler: s.CreateAuthRoleBinding})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/auth_role_binding/{id}",
+		Handler: s.DeleteAuthRoleBinding})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/auth_role_binding",
+		Handler: s.SearchAuthRoleBinding})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *Service) initManifest(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/manifest", Handler: s.ExportManifest})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/manifest/plan", Handler: s.PlanManifest})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/topo/manifest/apply",
+		Handler: s.ApplyManifest})
+
+	utility.AddToRestfulWebService(web)
+}
+
 func (s *Service) initService(web *restful.WebService) {
 	utility := rest.NewRestUtility(rest.Config{ErrorIf: s.Engine.CCErr, Language: s.Engine.Language})
 
@@ -321,11 +360,15 @@ func (s *Service) initService(web *restful.WebService) {
 	s.initObjectGroup(web)
 	s.initIdentifier(web)
 	s.initProject(web)
+	s.initAuthRole(web)
+	s.initManifest(web)
 
 	s.initBusinessObject(web)
 	s.initBusinessClassification(web)
 	s.initBusinessObjectAttribute(web)
 	s.initBusinessObjectUnique(web)
+	s.initBusinessObjectValidationRule(web)
+	s.initBusinessObjectLifecycle(web)
 	s.initBusinessObjectAttrGroup(web)
 	s.initBusinessAssociation(web)
 	s.initBusinessGraphics(web)
@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CreateObjectValidationRule create object cross-field validation rule
+func (s *Service) CreateObjectValidationRule(ctx *rest.Contexts) {
+	spec := new(metadata.ValidationRuleSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	opt := &metadata.CreateValidationRuleOption{
+		ObjID:              ctx.Request.PathParameter(common.BKObjIDField),
+		ValidationRuleSpec: *spec,
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().ValidationRule().CreateValidationRule(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt)
+	if err != nil {
+		blog.Errorf("create object %s validation rule failed, err: %v, opt: %+v, rid: %s", opt.ObjID, err, opt,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// UpdateObjectValidationRule update object cross-field validation rule
+func (s *Service) UpdateObjectValidationRule(ctx *rest.Contexts) {
+	spec := new(metadata.ValidationRuleSpec)
+	if err := ctx.DecodeInto(spec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	opt := &metadata.UpdateValidationRuleOption{
+		ObjID:              ctx.Request.PathParameter(common.BKObjIDField),
+		ID:                 id,
+		ValidationRuleSpec: *spec,
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().ValidationRule().UpdateValidationRule(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt); err != nil {
+		blog.Errorf("update object %s validation rule failed, err: %v, opt: %+v, rid: %s", opt.ObjID, err, opt,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteObjectValidationRule delete object cross-field validation rule
+func (s *Service) DeleteObjectValidationRule(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().ValidationRule().DeleteValidationRule(ctx.Kit.Ctx, ctx.Kit.Header,
+		objID, id); err != nil {
+		blog.Errorf("delete object %s validation rule %d failed, err: %v, rid: %s", objID, id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// SearchObjectValidationRule search object cross-field validation rules
+func (s *Service) SearchObjectValidationRule(ctx *rest.Contexts) {
+	opt := &metadata.ListValidationRuleOption{ObjID: ctx.Request.PathParameter(common.BKObjIDField)}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	rules, err := s.Engine.CoreAPI.CoreService().ValidationRule().ListValidationRule(ctx.Kit.Ctx, ctx.Kit.Header,
+		opt)
+	if err != nil {
+		blog.Errorf("search object %s validation rules failed, err: %v, rid: %s", opt.ObjID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(rules)
+}
@@ -0,0 +1,89 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package options
+
+import (
+	"errors"
+	"fmt"
+
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal/kafka"
+)
+
+// defaultEventTopicPrefix is the default prefix of the kafka topics that the watch events are published to
+const defaultEventTopicPrefix = "bk_cmdb_event"
+
+// EventSinkConfig is the config of publishing the watch events to kafka
+type EventSinkConfig struct {
+	// Enabled defines whether to publish the watch events to kafka
+	Enabled bool `mapstructure:"enabled"`
+	// TopicPrefix is the prefix of the topic, the events of a resource are published to topic ${prefix}_${resource}
+	// if the topic of the resource is not specified in Topics
+	TopicPrefix string `mapstructure:"topicPrefix"`
+	// Topics is the map of resource to the topic that its events are published to
+	Topics map[watch.CursorType]string `mapstructure:"topics"`
+	// Resources is the resources whose events are published, empty means all resources
+	Resources []watch.CursorType `mapstructure:"resources"`
+	// Kafka is the kafka config, it is parsed from kafka.event config
+	Kafka kafka.Config `mapstructure:"-"`
+}
+
+// Validate event sink config, and set the default values
+func (c *EventSinkConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.Kafka.Brokers) == 0 {
+		return errors.New("kafka brokers are not set")
+	}
+
+	supported := make(map[watch.CursorType]struct{})
+	for _, resource := range watch.ListCursorTypes() {
+		supported[resource] = struct{}{}
+	}
+
+	if len(c.Resources) == 0 {
+		c.Resources = watch.ListCursorTypes()
+	}
+
+	for _, resource := range c.Resources {
+		if _, exists := supported[resource]; !exists {
+			return fmt.Errorf("resource %s is not supported", resource)
+		}
+	}
+
+	for resource := range c.Topics {
+		if _, exists := supported[resource]; !exists {
+			return fmt.Errorf("topic resource %s is not supported", resource)
+		}
+	}
+
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = defaultEventTopicPrefix
+	}
+
+	return nil
+}
+
+// Topic returns the kafka topic of the resource
+func (c *EventSinkConfig) Topic(resource watch.CursorType) string {
+	if topic := c.Topics[resource]; topic != "" {
+		return topic
+	}
+	return c.TopicPrefix + "_" + string(resource)
+}
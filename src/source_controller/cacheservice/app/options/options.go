@@ -38,6 +38,7 @@ type Config struct {
 	WatchMongo mongo.Config
 	Redis      redis.Config
 	Auth       iam.AuthConfig
+	EventSink  EventSinkConfig
 }
 
 // NewServerOption create a ServerOption object
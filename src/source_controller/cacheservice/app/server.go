@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/iam"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
@@ -63,6 +64,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: cacheSvr.onCacheServiceConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv:  backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover, TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo:      svrInfo,
 	}
@@ -139,10 +141,30 @@ func initResource(cacheSvr *CacheServer) error {
 	}
 
 	cacheSvr.Config.Auth, err = iam.ParseConfigFromKV("authServer", nil)
-	if err != nil {
+	if err != nil && ac.EnableIAM() {
 		blog.Errorf("parse iam config failed: %v", err)
 		return err
 	}
 
+	if cc.IsExist("cacheService.eventSink") {
+		if err = cc.UnmarshalKey("cacheService.eventSink", &cacheSvr.Config.EventSink); err != nil {
+			blog.Errorf("parse event sink config failed, err: %v", err)
+			return err
+		}
+	}
+
+	if cacheSvr.Config.EventSink.Enabled {
+		cacheSvr.Config.EventSink.Kafka, err = cc.Kafka("kafka.event")
+		if err != nil {
+			blog.Errorf("parse event sink kafka config failed, err: %v", err)
+			return err
+		}
+	}
+
+	if err = cacheSvr.Config.EventSink.Validate(); err != nil {
+		blog.Errorf("event sink config is invalid, err: %v", err)
+		return err
+	}
+
 	return nil
 }
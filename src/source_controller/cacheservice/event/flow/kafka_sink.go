@@ -0,0 +1,269 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	"configcenter/src/source_controller/cacheservice/app/options"
+	tokenhandler "configcenter/src/source_controller/cacheservice/cache/token-handler"
+	"configcenter/src/source_controller/cacheservice/event"
+	watchcli "configcenter/src/source_controller/cacheservice/event/watch"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/kafka"
+	"configcenter/src/storage/driver/redis"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/Shopify/sarama"
+)
+
+// kafka message header keys of the published watch event
+const (
+	kafkaHeaderResource  = "bk_resource"
+	kafkaHeaderEventType = "bk_event_type"
+)
+
+// NewKafkaSink publishes the watch events of the configured resources to kafka on the master cache service.
+// the event cursor is used as the message key, and the cursor of the last published event is stored as the token
+// after the events are published, so the publishing resumes from the token after restart or master switching, and
+// only the last batch may be published again, the consumers can drop the duplicated events by the message key.
+func NewKafkaSink(conf *options.EventSinkConfig, isMaster discovery.ServiceManageInterface, watchDB dal.DB,
+	ccDB dal.DB) error {
+
+	producer, err := newEventProducer(conf.Kafka)
+	if err != nil {
+		blog.Errorf("create kafka event producer failed, err: %v", err)
+		return err
+	}
+
+	watchCli := watchcli.NewClient(watchDB, ccDB, redis.Client())
+	for _, resource := range conf.Resources {
+		key, err := event.GetResourceKeyWithCursorType(resource)
+		if err != nil {
+			blog.Errorf("get event key with cursor type %s failed, err: %v", resource, err)
+			return err
+		}
+
+		name := fmt.Sprintf("%s%s:%s", common.BKCacheKeyV3Prefix, "kafka_event_sink", resource)
+		publisher := &eventPublisher{
+			resource:     resource,
+			key:          key,
+			topic:        conf.Topic(resource),
+			isMaster:     isMaster,
+			watchCli:     watchCli,
+			producer:     producer,
+			tokenHandler: tokenhandler.NewSingleTokenHandler(name, ccDB),
+		}
+		go publisher.loopPublish(context.Background())
+		blog.Infof("start publish %s events to kafka topic %s", resource, publisher.topic)
+	}
+
+	return nil
+}
+
+// newEventProducer creates an idempotent kafka producer, so that the retried messages are not duplicated
+func newEventProducer(conf kafka.Config) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V0_11_0_0
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+	config.Producer.Idempotent = true
+	config.Net.MaxOpenRequests = 1
+	if conf.User != "" && conf.Password != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = conf.User
+		config.Net.SASL.Password = conf.Password
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &kafka.XDGSCRAMClient{HashGeneratorFcn: kafka.SHA512}
+		}
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+	}
+
+	return sarama.NewSyncProducer(conf.Brokers, config)
+}
+
+// eventWatcher watches the events of a resource, it is implemented by the watch client
+type eventWatcher interface {
+	WatchFromNow(kit *rest.Kit, key event.Key, opts *watch.WatchEventOptions) (*watch.WatchEventDetail, error)
+	WatchWithCursor(kit *rest.Kit, key event.Key, opts *watch.WatchEventOptions) ([]*watch.WatchEventDetail, error)
+}
+
+// sinkTokenHandler stores the cursor of the last published event as the token
+type sinkTokenHandler interface {
+	types.TokenHandler
+	ResetWatchToken(startAtTime types.TimeStamp) error
+}
+
+// eventPublisher publishes the watch events of one resource to its kafka topic
+type eventPublisher struct {
+	resource     watch.CursorType
+	key          event.Key
+	topic        string
+	isMaster     discovery.ServiceManageInterface
+	watchCli     eventWatcher
+	producer     sarama.SyncProducer
+	tokenHandler sinkTokenHandler
+}
+
+func (p *eventPublisher) loopPublish(ctx context.Context) {
+	prevStatus := false
+	opts := &watch.WatchEventOptions{
+		Resource: p.resource,
+	}
+
+	for {
+		isMaster := p.isMaster.IsMaster()
+		if !isMaster {
+			prevStatus = false
+			blog.V(4).Infof("publish %s event to kafka, but not master, skip.", p.resource)
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		// resume from the stored token when this node becomes master
+		if !prevStatus {
+			var err error
+			opts.Cursor, err = p.tokenHandler.GetStartWatchToken(ctx)
+			if err != nil {
+				blog.Errorf("get %s kafka sink start token failed, err: %v", p.resource, err)
+				time.Sleep(500 * time.Millisecond)
+				continue
+			}
+			prevStatus = isMaster
+		}
+
+		if err := p.publish(ctx, opts); err != nil {
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func (p *eventPublisher) publish(ctx context.Context, opts *watch.WatchEventOptions) error {
+	kit := &rest.Kit{
+		Rid:             util.GenerateRID(),
+		Header:          make(http.Header),
+		Ctx:             ctx,
+		CCError:         errors.NewFromCtx(errors.EmptyErrorsSetting).CreateDefaultCCErrorIf("zh-cn"),
+		User:            common.CCSystemOperatorUserName,
+		SupplierAccount: common.BKSuperOwnerID,
+	}
+
+	// no token is stored, start publishing from the latest event, the latest event itself is not published
+	if opts.Cursor == "" {
+		lastEvent, err := p.watchCli.WatchFromNow(kit, p.key, opts)
+		if err != nil {
+			blog.Errorf("watch %s event from now failed, err: %v, rid: %s", p.resource, err, kit.Rid)
+			return err
+		}
+		return p.setCursor(kit, opts, lastEvent.Cursor)
+	}
+
+	events, err := p.watchCli.WatchWithCursor(kit, p.key, opts)
+	if err != nil {
+		if ccErr, ok := err.(errors.CCErrorCoder); ok && ccErr.GetCode() == common.CCErrEventChainNodeNotExist {
+			// the events after the token are expired and can not be published anymore, publish from now
+			opts.Cursor = ""
+			if err = p.tokenHandler.ResetWatchToken(types.TimeStamp{Sec: uint32(time.Now().Unix())}); err != nil {
+				blog.Errorf("reset %s kafka sink token failed, err: %v, rid: %s", p.resource, err, kit.Rid)
+				return err
+			}
+
+			blog.Errorf("%s kafka sink token expired, publish from now, rid: %s", p.resource, kit.Rid)
+			return ccErr
+		}
+		blog.Errorf("watch %s event failed, err: %v, opt: %+v, rid: %s", p.resource, err, opts, kit.Rid)
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	messages, err := p.buildMessages(events)
+	if err != nil {
+		blog.Errorf("build %s event kafka messages failed, err: %v, rid: %s", p.resource, err, kit.Rid)
+		return err
+	}
+
+	if len(messages) > 0 {
+		if err = p.producer.SendMessages(messages); err != nil {
+			blog.Errorf("publish %d %s events to kafka topic %s failed, err: %v, rid: %s", len(messages),
+				p.resource, p.topic, err, kit.Rid)
+			return err
+		}
+		blog.V(4).Infof("publish %d %s events to kafka topic %s success, rid: %s", len(messages), p.resource,
+			p.topic, kit.Rid)
+	}
+
+	return p.setCursor(kit, opts, events[len(events)-1].Cursor)
+}
+
+// buildMessages converts the watch events to kafka messages, the events without detail only carry the latest
+// cursor and are not published
+func (p *eventPublisher) buildMessages(events []*watch.WatchEventDetail) ([]*sarama.ProducerMessage, error) {
+	messages := make([]*sarama.ProducerMessage, 0)
+	for _, e := range events {
+		if e.Detail == nil || e.EventType == "" {
+			continue
+		}
+
+		value, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		// encrypted attribute values are masked so that the ciphertext is not exposed to the consumers
+		value = []byte(cryptor.MaskAttrJson(string(value)))
+
+		messages = append(messages, &sarama.ProducerMessage{
+			Topic: p.topic,
+			Key:   sarama.StringEncoder(e.Cursor),
+			Value: sarama.ByteEncoder(value),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte(kafkaHeaderResource), Value: []byte(e.Resource)},
+				{Key: []byte(kafkaHeaderEventType), Value: []byte(e.EventType)},
+			},
+		})
+	}
+
+	return messages, nil
+}
+
+func (p *eventPublisher) setCursor(kit *rest.Kit, opts *watch.WatchEventOptions, cursor string) error {
+	if cursor == "" || cursor == opts.Cursor {
+		return nil
+	}
+
+	if err := p.tokenHandler.SetLastWatchToken(kit.Ctx, cursor); err != nil {
+		blog.Errorf("set %s kafka sink token to %s failed, err: %v, rid: %s", p.resource, cursor, err, kit.Rid)
+		return err
+	}
+	opts.Cursor = cursor
+	return nil
+}
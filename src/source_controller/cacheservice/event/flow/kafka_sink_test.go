@@ -0,0 +1,223 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"configcenter/src/common"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/watch"
+	"configcenter/src/source_controller/cacheservice/app/options"
+	"configcenter/src/source_controller/cacheservice/event"
+	"configcenter/src/storage/dal/kafka"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessages(t *testing.T) {
+	conf := &options.EventSinkConfig{
+		Enabled:   true,
+		Topics:    map[watch.CursorType]string{watch.Host: "cmdb_host"},
+		Resources: []watch.CursorType{watch.Host, watch.Biz},
+		Kafka:     kafka.Config{Brokers: []string{"127.0.0.1:9092"}},
+	}
+	require.NoError(t, conf.Validate())
+	require.Equal(t, "cmdb_host", conf.Topic(watch.Host))
+	require.Equal(t, "bk_cmdb_event_biz", conf.Topic(watch.Biz))
+
+	p := &eventPublisher{resource: watch.Host, topic: conf.Topic(watch.Host)}
+	events := []*watch.WatchEventDetail{
+		{Cursor: "c1", Resource: watch.Host, EventType: watch.Create, Detail: watch.JsonString(`{"bk_host_id":1}`)},
+		// event that only carries the latest cursor is skipped
+		{Cursor: "c2", Resource: watch.Host},
+	}
+
+	messages, err := p.buildMessages(events)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, "cmdb_host", messages[0].Topic)
+
+	key, err := messages[0].Key.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "c1", string(key))
+
+	value, err := messages[0].Value.Encode()
+	require.NoError(t, err)
+	detail := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(value, &detail))
+	require.Equal(t, "create", detail["bk_event_type"])
+	require.Equal(t, float64(1), detail["bk_detail"].(map[string]interface{})["bk_host_id"])
+}
+
+// fakeEventWatcher returns the events after the cursor, the cursors in expired are treated as expired
+type fakeEventWatcher struct {
+	latest  *watch.WatchEventDetail
+	events  map[string][]*watch.WatchEventDetail
+	expired map[string]bool
+	// cursors are the cursors that are watched with
+	cursors []string
+}
+
+func (w *fakeEventWatcher) WatchFromNow(_ *rest.Kit, _ event.Key, _ *watch.WatchEventOptions) (
+	*watch.WatchEventDetail, error) {
+
+	return w.latest, nil
+}
+
+func (w *fakeEventWatcher) WatchWithCursor(_ *rest.Kit, _ event.Key, opts *watch.WatchEventOptions) (
+	[]*watch.WatchEventDetail, error) {
+
+	w.cursors = append(w.cursors, opts.Cursor)
+	if w.expired[opts.Cursor] {
+		return nil, ccErr.New(common.CCErrEventChainNodeNotExist, "event chain node not exist")
+	}
+	return w.events[opts.Cursor], nil
+}
+
+type fakeTokenHandler struct {
+	token   string
+	resetAt *types.TimeStamp
+}
+
+func (h *fakeTokenHandler) SetLastWatchToken(_ context.Context, token string) error {
+	h.token = token
+	return nil
+}
+
+func (h *fakeTokenHandler) GetStartWatchToken(_ context.Context) (string, error) {
+	return h.token, nil
+}
+
+func (h *fakeTokenHandler) ResetWatchToken(startAtTime types.TimeStamp) error {
+	h.token = ""
+	h.resetAt = &startAtTime
+	return nil
+}
+
+type fakeProducer struct {
+	messages []*sarama.ProducerMessage
+	err      error
+}
+
+func (p *fakeProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return 0, 0, p.SendMessages([]*sarama.ProducerMessage{msg})
+}
+
+func (p *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msgs...)
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	return nil
+}
+
+func (p *fakeProducer) keys(t *testing.T) []string {
+	keys := make([]string, len(p.messages))
+	for idx, msg := range p.messages {
+		key, err := msg.Key.Encode()
+		require.NoError(t, err)
+		keys[idx] = string(key)
+	}
+	return keys
+}
+
+func newTestPublisher(watcher *fakeEventWatcher, handler *fakeTokenHandler,
+	producer *fakeProducer) *eventPublisher {
+
+	return &eventPublisher{resource: watch.Host, topic: "cmdb_host", watchCli: watcher, producer: producer,
+		tokenHandler: handler}
+}
+
+func newTestEvent(cursor string) *watch.WatchEventDetail {
+	return &watch.WatchEventDetail{Cursor: cursor, Resource: watch.Host, EventType: watch.Update,
+		Detail: watch.JsonString(`{"bk_host_id":1}`)}
+}
+
+func TestPublishResumeFromToken(t *testing.T) {
+	watcher := &fakeEventWatcher{events: map[string][]*watch.WatchEventDetail{
+		"c1": {newTestEvent("c2"), newTestEvent("c3")},
+		"c3": {newTestEvent("c4")},
+	}}
+	handler := &fakeTokenHandler{token: "c1"}
+	producer := &fakeProducer{}
+	p := newTestPublisher(watcher, handler, producer)
+
+	// resume from the stored token, the token is moved to the last published event
+	token, err := handler.GetStartWatchToken(context.Background())
+	require.NoError(t, err)
+	opts := &watch.WatchEventOptions{Resource: watch.Host, Cursor: token}
+	require.NoError(t, p.publish(context.Background(), opts))
+	require.Equal(t, []string{"c2", "c3"}, producer.keys(t))
+	require.Equal(t, "c3", handler.token)
+
+	require.NoError(t, p.publish(context.Background(), opts))
+	require.Equal(t, []string{"c2", "c3", "c4"}, producer.keys(t))
+	require.Equal(t, "c4", handler.token)
+	require.Equal(t, []string{"c1", "c3"}, watcher.cursors)
+
+	// the token is not moved if the events are not published, so they are published again after restart
+	watcher.events["c4"] = []*watch.WatchEventDetail{newTestEvent("c5")}
+	producer.err = errors.New("kafka is unavailable")
+	require.Error(t, p.publish(context.Background(), opts))
+	require.Equal(t, "c4", handler.token)
+
+	producer.err = nil
+	opts = &watch.WatchEventOptions{Resource: watch.Host, Cursor: handler.token}
+	require.NoError(t, p.publish(context.Background(), opts))
+	require.Equal(t, []string{"c2", "c3", "c4", "c5"}, producer.keys(t))
+	require.Equal(t, "c5", handler.token)
+}
+
+func TestPublishTokenExpired(t *testing.T) {
+	watcher := &fakeEventWatcher{
+		latest:  &watch.WatchEventDetail{Cursor: "c10", Resource: watch.Host},
+		events:  map[string][]*watch.WatchEventDetail{"c10": {newTestEvent("c11")}},
+		expired: map[string]bool{"c1": true},
+	}
+	handler := &fakeTokenHandler{token: "c1"}
+	producer := &fakeProducer{}
+	p := newTestPublisher(watcher, handler, producer)
+
+	// the expired token is reset, and the publishing starts from now
+	opts := &watch.WatchEventOptions{Resource: watch.Host, Cursor: handler.token}
+	err := p.publish(context.Background(), opts)
+	require.Error(t, err)
+	require.Equal(t, common.CCErrEventChainNodeNotExist, err.(ccErr.CCErrorCoder).GetCode())
+	require.Empty(t, opts.Cursor)
+	require.Empty(t, handler.token)
+	require.NotNil(t, handler.resetAt)
+
+	// the latest event itself is not published
+	require.NoError(t, p.publish(context.Background(), opts))
+	require.Empty(t, producer.messages)
+	require.Equal(t, "c10", handler.token)
+
+	require.NoError(t, p.publish(context.Background(), opts))
+	require.Equal(t, []string{"c11"}, producer.keys(t))
+	require.Equal(t, "c11", handler.token)
+}
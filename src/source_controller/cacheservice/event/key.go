@@ -490,6 +490,98 @@ var KubePodKey = Key{
 	},
 }
 
+// KubeServiceKey kube service event watch key
+var KubeServiceKey = Key{
+	namespace:          watchCacheNamespace + string(kubetypes.KubeService),
+	collection:         kubetypes.BKTableNameBaseService,
+	ttlSeconds:         6 * 60 * 60,
+	generalResCacheKey: general.KubeServiceKey,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubeIngressKey kube ingress event watch key
+var KubeIngressKey = Key{
+	namespace:          watchCacheNamespace + string(kubetypes.KubeIngress),
+	collection:         kubetypes.BKTableNameBaseIngress,
+	ttlSeconds:         6 * 60 * 60,
+	generalResCacheKey: general.KubeIngressKey,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubeConfigMapKey kube configMap event watch key
+var KubeConfigMapKey = Key{
+	namespace:          watchCacheNamespace + string(kubetypes.KubeConfigMap),
+	collection:         kubetypes.BKTableNameBaseConfigMap,
+	ttlSeconds:         6 * 60 * 60,
+	generalResCacheKey: general.KubeConfigMapKey,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubePersistentVolumeClaimKey kube persistentVolumeClaim event watch key
+var KubePersistentVolumeClaimKey = Key{
+	namespace:          watchCacheNamespace + string(kubetypes.KubePersistentVolumeClaim),
+	collection:         kubetypes.BKTableNameBasePersistentVolumeClaim,
+	ttlSeconds:         6 * 60 * 60,
+	generalResCacheKey: general.KubePersistentVolumeClaimKey,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
 var projectFields = []string{common.BKFieldID, common.BKProjectNameField}
 
 // ProjectKey project event watch key
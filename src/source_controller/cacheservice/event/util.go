@@ -28,26 +28,30 @@ import (
 )
 
 var resourceKeyMap = map[watch.CursorType]Key{
-	watch.Host:                    HostKey,
-	watch.ModuleHostRelation:      ModuleHostRelationKey,
-	watch.Biz:                     BizKey,
-	watch.Set:                     SetKey,
-	watch.Module:                  ModuleKey,
-	watch.ObjectBase:              ObjectBaseKey,
-	watch.Process:                 ProcessKey,
-	watch.ProcessInstanceRelation: ProcessInstanceRelationKey,
-	watch.HostIdentifier:          HostIdentityKey,
-	watch.MainlineInstance:        MainlineInstanceKey,
-	watch.InstAsst:                InstAsstKey,
-	watch.BizSet:                  BizSetKey,
-	watch.BizSetRelation:          BizSetRelationKey,
-	watch.Plat:                    PlatKey,
-	watch.KubeCluster:             KubeClusterKey,
-	watch.KubeNode:                KubeNodeKey,
-	watch.KubeNamespace:           KubeNamespaceKey,
-	watch.KubeWorkload:            KubeWorkloadKey,
-	watch.KubePod:                 KubePodKey,
-	watch.Project:                 ProjectKey,
+	watch.Host:                      HostKey,
+	watch.ModuleHostRelation:        ModuleHostRelationKey,
+	watch.Biz:                       BizKey,
+	watch.Set:                       SetKey,
+	watch.Module:                    ModuleKey,
+	watch.ObjectBase:                ObjectBaseKey,
+	watch.Process:                   ProcessKey,
+	watch.ProcessInstanceRelation:   ProcessInstanceRelationKey,
+	watch.HostIdentifier:            HostIdentityKey,
+	watch.MainlineInstance:          MainlineInstanceKey,
+	watch.InstAsst:                  InstAsstKey,
+	watch.BizSet:                    BizSetKey,
+	watch.BizSetRelation:            BizSetRelationKey,
+	watch.Plat:                      PlatKey,
+	watch.KubeCluster:               KubeClusterKey,
+	watch.KubeNode:                  KubeNodeKey,
+	watch.KubeNamespace:             KubeNamespaceKey,
+	watch.KubeWorkload:              KubeWorkloadKey,
+	watch.KubePod:                   KubePodKey,
+	watch.KubeService:               KubeServiceKey,
+	watch.KubeIngress:               KubeIngressKey,
+	watch.KubeConfigMap:             KubeConfigMapKey,
+	watch.KubePersistentVolumeClaim: KubePersistentVolumeClaimKey,
+	watch.Project:                   ProjectKey,
 }
 
 // GetResourceKeyWithCursorType get resource key
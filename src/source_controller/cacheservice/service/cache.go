@@ -26,6 +26,7 @@ import (
 	acmeta "configcenter/src/ac/meta"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
@@ -677,13 +678,31 @@ func (s *cacheService) generateWatchEventResp(startCursor string, rsc watch.Curs
 			result.Events = []*watch.WatchEventDetail{events[0]}
 		} else {
 			result.Watched = true
-			result.Events = events
+			result.Events = maskEncryptedEventDetail(events)
 		}
 	}
 
 	return result
 }
 
+// maskEncryptedEventDetail replace the encrypted attribute values in event details with mask, so that the
+// ciphertext of the encrypted attributes is not exposed to the watchers
+func maskEncryptedEventDetail(events []*watch.WatchEventDetail) []*watch.WatchEventDetail {
+	maskedEvents := make([]*watch.WatchEventDetail, len(events))
+	for idx, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok {
+			maskedEvents[idx] = event
+			continue
+		}
+
+		maskedEvent := *event
+		maskedEvent.Detail = watch.JsonString(cryptor.MaskAttrJson(string(detail)))
+		maskedEvents[idx] = &maskedEvent
+	}
+	return maskedEvents
+}
+
 // CreateFullSyncCond create full sync cache condition
 func (s *cacheService) CreateFullSyncCond(cts *rest.Contexts) {
 	opt := new(fullsynccond.CreateFullSyncCondOpt)
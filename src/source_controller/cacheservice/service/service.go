@@ -22,10 +22,10 @@ import (
 	"net/http"
 	"time"
 
+	"configcenter/src/ac"
 	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
 	"configcenter/src/common"
-	"configcenter/src/common/auth"
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
@@ -89,7 +89,7 @@ func (s *cacheService) SetConfig(cfg options.Config, engine *backbone.Engine, er
 	}
 
 	iamCli := new(iam.IAM)
-	if auth.EnableAuthorize() {
+	if ac.EnableIAM() {
 		var rawErr error
 		iamCli, rawErr = iam.NewIAM(cfg.Auth, engine.Metric().Registry())
 		if rawErr != nil {
@@ -141,6 +141,13 @@ func (s *cacheService) SetConfig(cfg options.Config, engine *backbone.Engine, er
 		return flowErr
 	}
 
+	if cfg.EventSink.Enabled {
+		if err := flow.NewKafkaSink(&cfg.EventSink, engine.ServiceManageInterface, watchDB, ccDB); err != nil {
+			blog.Errorf("new kafka event sink failed, err: %v", err)
+			return err
+		}
+	}
+
 	if err := identifier.NewIdentity(watcher, engine.ServiceManageInterface, watchDB, ccDB); err != nil {
 		blog.Errorf("new host identity event failed, err: %v", err)
 		return err
@@ -19,6 +19,7 @@ package options
 
 import (
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/redis"
 
@@ -35,6 +36,8 @@ type ServerOption struct {
 type Config struct {
 	Mongo mongo.Config
 	Redis redis.Config
+	// AttrCryptor is used to encrypt the encrypted model attributes' values, nil if it is not configured
+	AttrCryptor *cryptor.AttrCryptor
 }
 
 // NewServerOption create a ServerOption object
@@ -25,6 +25,7 @@ import (
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/types"
 	"configcenter/src/source_controller/coreservice/app/options"
@@ -63,6 +64,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	input := &backbone.BackboneParameter{
 		ConfigUpdate: coreSvr.onCoreServiceConfigUpdate,
 		ConfigPath:   op.ServConf.ExConfig,
+		ConfigDir:    op.ServConf.ConfigDir,
 		SrvRegdiscv: backbone.SrvRegdiscv{Regdiscv: op.ServConf.RegDiscover,
 			TLSConfig: op.ServConf.GetTLSClientConf()},
 		SrvInfo: svrInfo,
@@ -141,5 +143,19 @@ func initResource(coreSvr *CoreServer, op *options.ServerOption) error {
 		return initErr
 	}
 
+	attrCryptoConf, err := cc.AttrCrypto("attributeCrypto")
+	if err != nil {
+		blog.Errorf("get attribute crypto conf failed, err: %v", err)
+		return err
+	}
+
+	if attrCryptoConf != nil {
+		coreSvr.Config.AttrCryptor, err = cryptor.NewAttrCryptor(attrCryptoConf)
+		if err != nil {
+			blog.Errorf("new attribute cryptor failed, err: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,110 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package auditlog
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// snapshotResourceTypes are the resource types whose audit logs records the instance attributes
+var snapshotResourceTypes = []metadata.ResourceType{metadata.BusinessRes, metadata.BizSetRes, metadata.ProjectRes,
+	metadata.SetRes, metadata.ModuleRes, metadata.ProcessRes, metadata.HostRes, metadata.CloudAreaRes,
+	metadata.ModelInstanceRes, metadata.MainlineInstanceRes, metadata.ResourceDirRes}
+
+// SearchInstSnapshot reconstruct the instance at the specified time by replaying its audit logs
+func (m *auditManager) SearchInstSnapshot(kit *rest.Kit, opt *metadata.InstSnapshotOption) (*metadata.InstSnapshot,
+	error) {
+
+	snapshotTime, err := metadata.ParseSnapshotTime(opt.Time)
+	if err != nil {
+		blog.Errorf("parse snapshot time %s failed, err: %v, rid: %s", opt.Time, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "time")
+	}
+
+	cond := mapstr.MapStr{
+		common.BKDBOR:               buildInstSnapshotOrCond(opt.ObjID, opt.InstID),
+		common.BKOperationTimeField: mapstr.MapStr{common.BKDBLTE: snapshotTime},
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	snapshot := &metadata.InstSnapshot{
+		ObjID:        opt.ObjID,
+		InstID:       opt.InstID,
+		Time:         opt.Time,
+		Associations: make([]metadata.InstSnapshotAsst, 0),
+	}
+
+	// replay the audit logs page by page in the order of their ids, which is the order they are generated
+	for {
+		logs := make([]metadata.AuditLog, 0)
+		err := mongodb.Client().Table(common.BKTableNameAuditLog).Find(cond).Sort(common.BKFieldID).
+			Limit(common.BKMaxPageSize).All(kit.Ctx, &logs)
+		if err != nil {
+			blog.Errorf("get instance audit logs failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrAuditSelectFailed)
+		}
+
+		auditlog.ReplayInstAuditLogs(snapshot, logs...)
+
+		if len(logs) < common.BKMaxPageSize {
+			break
+		}
+
+		cond[common.BKFieldID] = mapstr.MapStr{common.BKDBGT: logs[len(logs)-1].ID}
+	}
+
+	return snapshot, nil
+}
+
+func buildInstSnapshotOrCond(objID string, instID int64) []mapstr.MapStr {
+	orCond := []mapstr.MapStr{
+		{
+			common.BKResourceTypeField:                                mapstr.MapStr{common.BKDBIN: snapshotResourceTypes},
+			common.BKResourceIDField:                                  instID,
+			common.BKOperationDetailField + "." + common.BKObjIDField: objID,
+		},
+		{
+			common.BKResourceTypeField:                    metadata.InstanceAssociationRes,
+			common.BKResourceIDField:                      instID,
+			common.BKOperationDetailField + ".src_obj_id": objID,
+		},
+		{
+			common.BKResourceTypeField:                      metadata.InstanceAssociationRes,
+			common.BKOperationDetailField + ".dest_obj_id":  objID,
+			common.BKOperationDetailField + ".dest_inst_id": instID,
+		},
+	}
+
+	if objID == common.BKInnerObjIDHost {
+		// host transfer audit logs has no object id, it records the host topology before and after the transfer
+		orCond = append(orCond, mapstr.MapStr{
+			common.BKResourceTypeField: metadata.HostRes,
+			common.BKResourceIDField:   instID,
+			common.BKActionField: mapstr.MapStr{common.BKDBIN: []metadata.ActionType{metadata.AuditTransferHostModule,
+				metadata.AuditAssignHost, metadata.AuditUnassignHost}},
+		})
+	}
+
+	return orCond
+}
@@ -0,0 +1,199 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package computed
+
+import (
+	"math"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// aggregateInstance calculates the aggregation value of the instance
+func aggregateInstance(kit *rest.Kit, objID string, instID int64, option *metadata.ComputedOption) (interface{},
+	error) {
+
+	var srcObjID string
+	var srcIDs []int64
+	var err error
+	switch option.Source {
+	case metadata.ComputedSourceHostRelation:
+		srcObjID = common.BKInnerObjIDHost
+		srcIDs, err = getRelatedHostIDs(kit, objID, instID)
+	case metadata.ComputedSourceAssociation:
+		srcObjID, srcIDs, err = getAssociatedInstIDs(kit, objID, instID, option.ObjAsstID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if option.Func == metadata.AggregateCount || len(srcIDs) == 0 {
+		return aggregate(option.Func, len(srcIDs), nil), nil
+	}
+
+	values, err := getFieldValues(kit, srcObjID, srcIDs, option.Field)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(option.Func, len(srcIDs), values), nil
+}
+
+// getRelatedHostIDs get the ids of the hosts in the biz, set or module instance
+func getRelatedHostIDs(kit *rest.Kit, objID string, instID int64) ([]int64, error) {
+	cond := mapstr.MapStr{common.GetInstIDField(objID): instID}
+	ids, err := mongodb.Client().Table(common.BKTableNameModuleHostConfig).Distinct(kit.Ctx, common.BKHostIDField,
+		cond)
+	if err != nil {
+		blog.Errorf("get host ids failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return util.SliceInterfaceToInt64(ids)
+}
+
+// getAssociatedInstIDs get the object id and instance ids of the instances associated with the instance
+func getAssociatedInstIDs(kit *rest.Kit, objID string, instID int64, objAsstID string) (string, []int64, error) {
+	asst, err := GetObjAssociation(kit, objAsstID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the instance is the source of the association if the object is, otherwise it is the destination
+	var asstObjID, idField string
+	var cond mapstr.MapStr
+	if asst.ObjectID == objID {
+		asstObjID, idField = asst.AsstObjID, common.BKAsstInstIDField
+		cond = mapstr.MapStr{common.BKObjIDField: objID, common.BKInstIDField: instID}
+	} else {
+		asstObjID, idField = asst.ObjectID, common.BKInstIDField
+		cond = mapstr.MapStr{common.BKAsstObjIDField: objID, common.BKAsstInstIDField: instID}
+	}
+	cond[common.AssociationObjAsstIDField] = objAsstID
+
+	table := common.GetObjectInstAsstTableName(objID, kit.SupplierAccount)
+	ids, err := mongodb.Client().Table(table).Distinct(kit.Ctx, idField, cond)
+	if err != nil {
+		blog.Errorf("get associated instance ids failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return "", nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	instIDs, err := util.SliceInterfaceToInt64(ids)
+	if err != nil {
+		return "", nil, err
+	}
+	return asstObjID, instIDs, nil
+}
+
+// GetObjAssociation get the object association by its id
+func GetObjAssociation(kit *rest.Kit, objAsstID string) (*metadata.Association, error) {
+	cond := mapstr.MapStr{common.AssociationObjAsstIDField: objAsstID}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	asst := new(metadata.Association)
+	if err := mongodb.Client().Table(common.BKTableNameObjAsst).Find(cond).One(kit.Ctx, asst); err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			blog.Errorf("object association %s not exists, rid: %s", objAsstID, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.AssociationObjAsstIDField)
+		}
+		blog.Errorf("get object association %s failed, err: %v, rid: %s", objAsstID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return asst, nil
+}
+
+// getFieldValues get the numeric values of the field of the instances, the empty values are skipped
+func getFieldValues(kit *rest.Kit, objID string, instIDs []int64, field string) ([]float64, error) {
+	idField := common.GetInstIDField(objID)
+	table := common.GetInstTableName(objID, kit.SupplierAccount)
+
+	values := make([]float64, 0)
+	for start := 0; start < len(instIDs); start += common.BKMaxPageSize {
+		end := start + common.BKMaxPageSize
+		if end > len(instIDs) {
+			end = len(instIDs)
+		}
+
+		cond := mapstr.MapStr{idField: mapstr.MapStr{common.BKDBIN: instIDs[start:end]}}
+		insts := make([]mapstr.MapStr, 0)
+		if err := mongodb.Client().Table(table).Find(cond).Fields(field).All(kit.Ctx, &insts); err != nil {
+			blog.Errorf("get %s instances failed, cond: %+v, err: %v, rid: %s", objID, cond, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+
+		for _, inst := range insts {
+			val, exists := inst[field]
+			if !exists || val == nil || val == "" {
+				continue
+			}
+
+			num, err := util.GetFloat64ByInterface(val)
+			if err != nil {
+				blog.Errorf("%s field %s value %v is not numeric, skip it, rid: %s", objID, field, val, kit.Rid)
+				continue
+			}
+			values = append(values, num)
+		}
+	}
+
+	return values, nil
+}
+
+// aggregate calculates the aggregation result, count is the count of the aggregated instances and values are the
+// values of their field. the result is nil when there is nothing to calculate an avg, min or max value from.
+func aggregate(fn metadata.AggregateFunc, count int, values []float64) interface{} {
+	switch fn {
+	case metadata.AggregateCount:
+		return int64(count)
+	case metadata.AggregateSum:
+		var sum float64
+		for _, val := range values {
+			sum += val
+		}
+		return sum
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	res := values[0]
+	switch fn {
+	case metadata.AggregateAvg:
+		for _, val := range values[1:] {
+			res += val
+		}
+		return res / float64(len(values))
+	case metadata.AggregateMin:
+		for _, val := range values[1:] {
+			res = math.Min(res, val)
+		}
+		return res
+	case metadata.AggregateMax:
+		for _, val := range values[1:] {
+			res = math.Max(res, val)
+		}
+		return res
+	default:
+		return nil
+	}
+}
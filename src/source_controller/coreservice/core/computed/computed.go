@@ -0,0 +1,242 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package computed calculates and saves the values of the computed attributes.
+package computed
+
+import (
+	"configcenter/pkg/formula"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// Attribute is a computed attribute with its parsed option
+type Attribute struct {
+	metadata.Attribute
+	Option *metadata.ComputedOption
+	// Formula is the parsed expression, only set for expression source
+	Formula *formula.Formula
+}
+
+// ParseAttributes parse the computed attributes in the attributes, the other attributes are ignored
+func ParseAttributes(attrs []metadata.Attribute) ([]Attribute, error) {
+	computedAttrs := make([]Attribute, 0)
+	for _, attr := range attrs {
+		if attr.PropertyType != common.FieldTypeComputed {
+			continue
+		}
+
+		option, err := metadata.ParseComputedOption(attr.Option)
+		if err != nil {
+			return nil, err
+		}
+
+		computedAttr := Attribute{Attribute: attr, Option: option}
+		if option.Source == metadata.ComputedSourceExpression {
+			computedAttr.Formula, err = formula.Parse(option.Expression)
+			if err != nil {
+				return nil, err
+			}
+		}
+		computedAttrs = append(computedAttrs, computedAttr)
+	}
+
+	return computedAttrs, nil
+}
+
+// GetAttributes get the computed attributes that matches the condition, returns object id to its computed attributes
+func GetAttributes(kit *rest.Kit, cond mapstr.MapStr) (map[string][]Attribute, error) {
+	filter := mapstr.MapStr{common.BKPropertyTypeField: common.FieldTypeComputed}
+	filter.Merge(cond)
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+
+	attrs := make([]metadata.Attribute, 0)
+	if err := mongodb.Client().Table(common.BKTableNameObjAttDes).Find(filter).All(kit.Ctx, &attrs); err != nil {
+		blog.Errorf("get computed attributes failed, cond: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	computedAttrs, err := ParseAttributes(attrs)
+	if err != nil {
+		blog.Errorf("parse computed attributes %+v failed, err: %v, rid: %s", attrs, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKOptionField)
+	}
+
+	attrMap := make(map[string][]Attribute)
+	for _, attr := range computedAttrs {
+		attrMap[attr.ObjectID] = append(attrMap[attr.ObjectID], attr)
+	}
+	return attrMap, nil
+}
+
+// FillCreateValues fills the computed values of the instance to be created, the values written by user are
+// overwritten. a new instance has no hosts or associated instances, so its aggregations start from empty.
+func FillCreateValues(data mapstr.MapStr, attrs []Attribute) error {
+	for _, attr := range attrs {
+		if attr.Option.IsAggregation() {
+			data[attr.PropertyID] = aggregate(attr.Option.Func, 0, nil)
+			continue
+		}
+
+		val, err := attr.Formula.Evaluate(data)
+		if err != nil {
+			return err
+		}
+		data[attr.PropertyID] = val
+	}
+
+	return nil
+}
+
+// RemoveValues removes the computed values from the data written by user
+func RemoveValues(data mapstr.MapStr, attrs []Attribute) {
+	for _, attr := range attrs {
+		delete(data, attr.PropertyID)
+	}
+}
+
+// UpdateExpressionValues recalculate the expression values of the updated instances and save the changed ones,
+// origins are the instances before they are updated with the update data.
+func UpdateExpressionValues(kit *rest.Kit, objID string, updateData mapstr.MapStr, origins []mapstr.MapStr,
+	attrs []Attribute) error {
+
+	exprAttrs := make([]Attribute, 0)
+	for _, attr := range attrs {
+		if attr.Formula == nil {
+			continue
+		}
+
+		for _, field := range attr.Formula.Fields() {
+			if _, exists := updateData[field]; exists {
+				exprAttrs = append(exprAttrs, attr)
+				break
+			}
+		}
+	}
+
+	if len(exprAttrs) == 0 {
+		return nil
+	}
+
+	for _, origin := range origins {
+		inst := origin.Clone()
+		inst.Merge(updateData)
+
+		if err := saveChangedValues(kit, objID, inst, exprAttrs, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefreshInstances recalculate all the computed values of the instances and save the changed ones
+func RefreshInstances(kit *rest.Kit, objID string, instIDs []int64, attrs []Attribute) error {
+	if len(instIDs) == 0 || len(attrs) == 0 {
+		return nil
+	}
+
+	idField := common.GetInstIDField(objID)
+	table := common.GetInstTableName(objID, kit.SupplierAccount)
+
+	for start := 0; start < len(instIDs); start += common.BKMaxPageSize {
+		end := start + common.BKMaxPageSize
+		if end > len(instIDs) {
+			end = len(instIDs)
+		}
+
+		cond := mapstr.MapStr{idField: mapstr.MapStr{common.BKDBIN: instIDs[start:end]}}
+		insts := make([]mapstr.MapStr, 0)
+		if err := mongodb.Client().Table(table).Find(cond).All(kit.Ctx, &insts); err != nil {
+			blog.Errorf("get %s instances failed, cond: %+v, err: %v, rid: %s", objID, cond, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+
+		for _, inst := range insts {
+			instID, err := util.GetInt64ByInterface(inst[idField])
+			if err != nil {
+				blog.Errorf("%s instance id is invalid, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+				return err
+			}
+
+			if err = saveChangedValues(kit, objID, inst, attrs, &instID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// saveChangedValues calculates the computed values of the instance, and saves them if they are changed. instID is
+// only needed for aggregations.
+func saveChangedValues(kit *rest.Kit, objID string, inst mapstr.MapStr, attrs []Attribute, instID *int64) error {
+	changed := mapstr.New()
+	for _, attr := range attrs {
+		var val interface{}
+		var err error
+		if attr.Option.IsAggregation() {
+			val, err = aggregateInstance(kit, objID, *instID, attr.Option)
+		} else {
+			val, err = attr.Formula.Evaluate(inst)
+		}
+		if err != nil {
+			blog.Errorf("calculate %s attribute %s failed, inst: %+v, err: %v, rid: %s", objID, attr.PropertyID,
+				inst, err, kit.Rid)
+			return err
+		}
+
+		if !isValueEqual(inst[attr.PropertyID], val) {
+			changed[attr.PropertyID] = val
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	idField := common.GetInstIDField(objID)
+	cond := mapstr.MapStr{idField: inst[idField]}
+	table := common.GetInstTableName(objID, kit.SupplierAccount)
+	if err := mongodb.Client().Table(table).Update(kit.Ctx, cond, changed); err != nil {
+		blog.Errorf("update %s computed values failed, cond: %+v, data: %+v, err: %v, rid: %s", objID, cond,
+			changed, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	return nil
+}
+
+func isValueEqual(origin, val interface{}) bool {
+	if origin == nil || val == nil {
+		return origin == val
+	}
+
+	originNum, err := util.GetFloat64ByInterface(origin)
+	if err != nil {
+		return false
+	}
+	num, err := util.GetFloat64ByInterface(val)
+	if err != nil {
+		return false
+	}
+	return originNum == num
+}
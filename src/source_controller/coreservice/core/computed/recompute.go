@@ -0,0 +1,499 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package computed
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	tokenhandler "configcenter/src/source_controller/cacheservice/cache/token-handler"
+	"configcenter/src/storage/driver/mongodb"
+	streamtypes "configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// reloadInterval is the interval to reload the computed attributes
+	reloadInterval = time.Minute
+	// watchTokenPrefix is the prefix of the event watch token key of the recomputer
+	watchTokenPrefix = common.BKCacheKeyV3Prefix + "computed_attribute:"
+)
+
+// Recomputer keeps the computed values up to date in the master coreservice. It recalculates the aggregations when
+// the host relations, instance associations and the fields of the aggregated hosts and instances change, and
+// refreshes all the instances of an object when its computed attributes are created or changed.
+// NOTE: the field changes of the aggregated biz, set and module instances are not watched.
+type Recomputer struct {
+	engine *backbone.Engine
+
+	lock sync.RWMutex
+	// aggregations is the object id to its computed attributes that are aggregations
+	aggregations map[string][]Attribute
+	// lastTimes is the computed attribute id to the last time of the attribute that has been refreshed
+	lastTimes map[int64]time.Time
+}
+
+// NewRecomputer new computed value recomputer
+func NewRecomputer(engine *backbone.Engine) *Recomputer {
+	return &Recomputer{
+		engine:       engine,
+		aggregations: make(map[string][]Attribute),
+		lastTimes:    make(map[int64]time.Time),
+	}
+}
+
+// Run starts to reload the computed attributes and watch the related events in background
+func (r *Recomputer) Run(ctx context.Context) {
+	go r.loopReload(ctx)
+	go r.loopWatch(ctx, watch.ModuleHostRelation, r.parseHostRelationEvents)
+	go r.loopWatch(ctx, watch.Host, r.parseHostEvents)
+	go r.loopWatch(ctx, watch.InstAsst, r.parseInstAsstEvents)
+	go r.loopWatch(ctx, watch.ObjectBase, r.parseObjectInstanceEvents)
+}
+
+func (r *Recomputer) newKit(ctx context.Context) *rest.Kit {
+	header := headerutil.GenCommonHeader(common.CCSystemOperatorUserName, common.BKDefaultOwnerID,
+		util.GenerateRID())
+	kit := rest.NewKitFromHeader(header, r.engine.CCErr)
+	kit.Ctx = util.SetContextValueByHTTPHeader(ctx, header)
+	return kit
+}
+
+func (r *Recomputer) loopReload(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.engine.Discovery().IsMaster() {
+			r.reload(r.newKit(ctx))
+		} else {
+			// refresh all the instances again when this coreservice becomes master
+			r.lock.Lock()
+			r.lastTimes = make(map[int64]time.Time)
+			r.lock.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reload the computed attributes, and refresh the instances of the objects whose computed attributes are changed
+func (r *Recomputer) reload(kit *rest.Kit) {
+	attrMap, err := GetAttributes(kit, nil)
+	if err != nil {
+		blog.Errorf("reload computed attributes failed, err: %v, rid: %s", err, kit.Rid)
+		return
+	}
+
+	aggregations := make(map[string][]Attribute)
+	lastTimes := make(map[int64]time.Time)
+	changedObjs := make([]string, 0)
+
+	r.lock.RLock()
+	for objID, attrs := range attrMap {
+		changed := false
+		for _, attr := range attrs {
+			lastTime := time.Time{}
+			if attr.LastTime != nil {
+				lastTime = attr.LastTime.Time
+			}
+			lastTimes[attr.ID] = lastTime
+
+			if prev, exists := r.lastTimes[attr.ID]; !exists || !prev.Equal(lastTime) {
+				changed = true
+			}
+
+			if attr.Option.IsAggregation() {
+				aggregations[objID] = append(aggregations[objID], attr)
+			}
+		}
+
+		if changed {
+			changedObjs = append(changedObjs, objID)
+		}
+	}
+	r.lock.RUnlock()
+
+	for _, objID := range changedObjs {
+		if err = r.refreshObject(kit, objID, attrMap[objID]); err != nil {
+			blog.Errorf("refresh %s computed values failed, retry later, err: %v, rid: %s", objID, err, kit.Rid)
+			for _, attr := range attrMap[objID] {
+				delete(lastTimes, attr.ID)
+			}
+		}
+	}
+
+	r.lock.Lock()
+	r.aggregations = aggregations
+	r.lastTimes = lastTimes
+	r.lock.Unlock()
+}
+
+// refreshObject refreshes the computed values of all the instances of the object
+func (r *Recomputer) refreshObject(kit *rest.Kit, objID string, attrs []Attribute) error {
+	idField := common.GetInstIDField(objID)
+	table := common.GetInstTableName(objID, kit.SupplierAccount)
+
+	var lastID int64
+	for {
+		cond := mapstr.MapStr{idField: mapstr.MapStr{common.BKDBGT: lastID}}
+		insts := make([]mapstr.MapStr, 0)
+		err := mongodb.Client().Table(table).Find(cond).Fields(idField).Sort(idField).Limit(common.BKMaxPageSize).
+			All(kit.Ctx, &insts)
+		if err != nil {
+			blog.Errorf("get %s instance ids failed, cond: %+v, err: %v, rid: %s", objID, cond, err, kit.Rid)
+			return err
+		}
+
+		if len(insts) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(insts))
+		for idx, inst := range insts {
+			ids[idx], err = util.GetInt64ByInterface(inst[idField])
+			if err != nil {
+				blog.Errorf("%s instance id is invalid, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+				return err
+			}
+		}
+
+		if err = RefreshInstances(kit, objID, ids, attrs); err != nil {
+			return err
+		}
+
+		if len(insts) < common.BKMaxPageSize {
+			return nil
+		}
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// eventParser parses the events to the instances whose aggregations need to be recalculated, returns the object id
+// to the instance ids.
+type eventParser func(kit *rest.Kit, events []*watch.WatchEventDetail) (map[string][]int64, error)
+
+func (r *Recomputer) loopWatch(ctx context.Context, resource watch.CursorType, parser eventParser) {
+	tokenHandler := tokenhandler.NewSingleTokenHandler(watchTokenPrefix+string(resource), mongodb.Client())
+
+	for ctx.Err() == nil {
+		r.lock.RLock()
+		hasAggregation := len(r.aggregations) > 0
+		r.lock.RUnlock()
+
+		if !r.engine.Discovery().IsMaster() || !hasAggregation {
+			select {
+			case <-ctx.Done():
+			case <-time.After(reloadInterval):
+			}
+			continue
+		}
+
+		if err := r.watchAndRecompute(r.newKit(ctx), resource, tokenHandler, parser); err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+func (r *Recomputer) watchAndRecompute(kit *rest.Kit, resource watch.CursorType,
+	tokenHandler *tokenhandler.SingleHandler, parser eventParser) error {
+
+	token, err := tokenHandler.GetStartWatchToken(kit.Ctx)
+	if err != nil {
+		blog.Errorf("get computed attribute %s watch token failed, err: %v, rid: %s", resource, err, kit.Rid)
+		return err
+	}
+
+	opts := &watch.WatchEventOptions{Resource: resource, Cursor: token}
+	if token == "" {
+		opts.StartFrom = time.Now().Unix()
+	}
+
+	resp, ccErr := r.engine.CoreAPI.CacheService().Cache().Event().InnerWatchEvent(kit.Ctx, kit.Header, opts)
+	if ccErr != nil {
+		if ccErr.GetCode() == common.CCErrEventChainNodeNotExist {
+			// the events after the token are expired, watch from now on and rely on the refresh of the reload
+			blog.Errorf("computed attribute %s watch token %s expired, reset it, rid: %s", resource, token, kit.Rid)
+			if err = tokenHandler.ResetWatchToken(streamtypes.TimeStamp{Sec: uint32(time.Now().Unix())}); err != nil {
+				blog.Errorf("reset computed attribute %s watch token failed, err: %v, rid: %s", resource, err,
+					kit.Rid)
+			}
+			return ccErr
+		}
+		blog.Errorf("watch computed attribute %s events failed, err: %v, rid: %s", resource, ccErr, kit.Rid)
+		return ccErr
+	}
+
+	if len(resp.Events) == 0 {
+		return nil
+	}
+
+	if resp.Watched {
+		targets, err := parser(kit, resp.Events)
+		if err != nil {
+			return err
+		}
+
+		for objID, instIDs := range targets {
+			r.lock.RLock()
+			attrs := r.aggregations[objID]
+			r.lock.RUnlock()
+
+			if err = RefreshInstances(kit, objID, util.IntArrayUnique(instIDs), attrs); err != nil {
+				blog.Errorf("recompute %s instances %v failed, err: %v, rid: %s", objID, instIDs, err, kit.Rid)
+				return err
+			}
+		}
+	}
+
+	lastCursor := resp.Events[len(resp.Events)-1].Cursor
+	if err = tokenHandler.SetLastWatchToken(kit.Ctx, lastCursor); err != nil {
+		return err
+	}
+	return nil
+}
+
+// getAggregations get the aggregation attributes that matches the filter, returns object id to the attributes
+func (r *Recomputer) getAggregations(filter func(objID string, attr Attribute) bool) map[string][]Attribute {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make(map[string][]Attribute)
+	for objID, attrs := range r.aggregations {
+		for _, attr := range attrs {
+			if filter(objID, attr) {
+				result[objID] = append(result[objID], attr)
+			}
+		}
+	}
+	return result
+}
+
+// parseHostRelationEvents the host count and host field aggregations of the biz, set and module of the changed
+// host relations need to be recalculated
+func (r *Recomputer) parseHostRelationEvents(_ *rest.Kit, events []*watch.WatchEventDetail) (map[string][]int64,
+	error) {
+
+	attrMap := r.getAggregations(func(_ string, attr Attribute) bool {
+		return attr.Option.Source == metadata.ComputedSourceHostRelation
+	})
+
+	targets := make(map[string][]int64)
+	for _, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok {
+			continue
+		}
+
+		for objID := range attrMap {
+			instID := gjson.Get(string(detail), common.GetInstIDField(objID)).Int()
+			if instID > 0 {
+				targets[objID] = append(targets[objID], instID)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// parseHostEvents the host field aggregations of the biz, set and module of the updated hosts, and the aggregations
+// of the instances associated with the updated hosts need to be recalculated
+func (r *Recomputer) parseHostEvents(kit *rest.Kit, events []*watch.WatchEventDetail) (map[string][]int64, error) {
+	hostIDs := make([]int64, 0)
+	for _, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok || event.EventType != watch.Update {
+			continue
+		}
+		hostIDs = append(hostIDs, gjson.Get(string(detail), common.BKHostIDField).Int())
+	}
+
+	if len(hostIDs) == 0 {
+		return nil, nil
+	}
+
+	targets, err := r.getAssociatedTargets(kit, common.BKInnerObjIDHost, hostIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	attrMap := r.getAggregations(func(_ string, attr Attribute) bool {
+		return attr.Option.Source == metadata.ComputedSourceHostRelation && attr.Option.Field != ""
+	})
+	if len(attrMap) == 0 {
+		return targets, nil
+	}
+
+	cond := mapstr.MapStr{common.BKHostIDField: mapstr.MapStr{common.BKDBIN: hostIDs}}
+	relations := make([]mapstr.MapStr, 0)
+	if err = mongodb.Client().Table(common.BKTableNameModuleHostConfig).Find(cond).All(kit.Ctx,
+		&relations); err != nil {
+		blog.Errorf("get host relations failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+
+	for _, relation := range relations {
+		for objID := range attrMap {
+			instID, err := util.GetInt64ByInterface(relation[common.GetInstIDField(objID)])
+			if err != nil {
+				continue
+			}
+			targets[objID] = append(targets[objID], instID)
+		}
+	}
+
+	return targets, nil
+}
+
+// parseInstAsstEvents the association aggregations of both sides of the changed instance associations need to be
+// recalculated
+func (r *Recomputer) parseInstAsstEvents(_ *rest.Kit, events []*watch.WatchEventDetail) (map[string][]int64,
+	error) {
+
+	targets := make(map[string][]int64)
+	for _, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok {
+			continue
+		}
+
+		asst := new(metadata.InstAsst)
+		if err := json.Unmarshal([]byte(detail), asst); err != nil {
+			blog.Errorf("unmarshal instance association %s failed, skip it, err: %v", detail, err)
+			continue
+		}
+
+		// the instance is aggregated from the source side if the association is self related
+		sides := []metadata.InstAsst{{ObjectID: asst.ObjectID, InstID: asst.InstID}}
+		if asst.AsstObjectID != asst.ObjectID {
+			sides = append(sides, metadata.InstAsst{ObjectID: asst.AsstObjectID, InstID: asst.AsstInstID})
+		}
+
+		for _, side := range sides {
+			attrMap := r.getAggregations(func(objID string, attr Attribute) bool {
+				return objID == side.ObjectID && attr.Option.Source == metadata.ComputedSourceAssociation &&
+					attr.Option.ObjAsstID == asst.ObjectAsstID
+			})
+
+			if len(attrMap) > 0 {
+				targets[side.ObjectID] = append(targets[side.ObjectID], side.InstID)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// parseObjectInstanceEvents the aggregations of the instances associated with the updated instances need to be
+// recalculated
+func (r *Recomputer) parseObjectInstanceEvents(kit *rest.Kit, events []*watch.WatchEventDetail) (map[string][]int64,
+	error) {
+
+	instIDMap := make(map[string][]int64)
+	for _, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok || event.EventType != watch.Update {
+			continue
+		}
+
+		objID := gjson.Get(string(detail), common.BKObjIDField).String()
+		instIDMap[objID] = append(instIDMap[objID], gjson.Get(string(detail), common.BKInstIDField).Int())
+	}
+
+	targets := make(map[string][]int64)
+	for objID, instIDs := range instIDMap {
+		objTargets, err := r.getAssociatedTargets(kit, objID, instIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for targetObjID, targetIDs := range objTargets {
+			targets[targetObjID] = append(targets[targetObjID], targetIDs...)
+		}
+	}
+
+	return targets, nil
+}
+
+// getAssociatedTargets get the instances whose association aggregations are calculated from the field of the
+// changed instances, returns object id to the instance ids.
+func (r *Recomputer) getAssociatedTargets(kit *rest.Kit, changedObjID string, changedIDs []int64) (
+	map[string][]int64, error) {
+
+	attrMap := r.getAggregations(func(_ string, attr Attribute) bool {
+		return attr.Option.Source == metadata.ComputedSourceAssociation && attr.Option.Field != ""
+	})
+
+	targets := make(map[string][]int64)
+	for objID, attrs := range attrMap {
+		for _, attr := range attrs {
+			asst, err := GetObjAssociation(kit, attr.Option.ObjAsstID)
+			if err != nil {
+				return nil, err
+			}
+
+			// the aggregated instances are on the other side of the association
+			var cond mapstr.MapStr
+			var idField string
+			switch {
+			case asst.ObjectID == objID && asst.AsstObjID == changedObjID:
+				cond = mapstr.MapStr{common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: changedIDs}}
+				idField = common.BKInstIDField
+			case asst.AsstObjID == objID && asst.ObjectID == changedObjID:
+				cond = mapstr.MapStr{common.BKInstIDField: mapstr.MapStr{common.BKDBIN: changedIDs}}
+				idField = common.BKAsstInstIDField
+			default:
+				continue
+			}
+			cond[common.AssociationObjAsstIDField] = asst.AssociationName
+
+			table := common.GetObjectInstAsstTableName(changedObjID, kit.SupplierAccount)
+			ids, err := mongodb.Client().Table(table).Distinct(kit.Ctx, idField, cond)
+			if err != nil {
+				blog.Errorf("get associated instance ids failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+				return nil, err
+			}
+
+			instIDs, err := util.SliceInterfaceToInt64(ids)
+			if err != nil {
+				return nil, err
+			}
+			targets[objID] = append(targets[objID], instIDs...)
+		}
+	}
+
+	return targets, nil
+}
@@ -130,6 +130,9 @@ type InstanceOperation interface {
 	DeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount, error)
 	CascadeDeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount,
 		error)
+	// DecryptInstAttr decrypt the encrypted attribute value of an instance
+	DecryptInstAttr(kit *rest.Kit, objID string, opt *metadata.DecryptInstAttrOption) (
+		*metadata.DecryptInstAttrResult, error)
 }
 
 // KubeOperation crud operations on kube data.
@@ -240,6 +243,7 @@ type AssociationOperation interface {
 type AuditOperation interface {
 	CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog) error
 	SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, error)
+	SearchInstSnapshot(kit *rest.Kit, opt *metadata.InstSnapshotOption) (*metadata.InstSnapshot, error)
 }
 
 // StatisticOperation TODO
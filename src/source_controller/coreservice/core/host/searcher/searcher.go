@@ -26,6 +26,7 @@ import (
 	"configcenter/src/apimachinery/cacheservice/cache/host"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/json"
 	"configcenter/src/common/metadata"
@@ -215,6 +216,7 @@ func (s *Searcher) listHostFromDB(kit *rest.Kit, finalFilter map[string]interfac
 
 	searchResult.Info = make([]map[string]interface{}, len(hosts))
 	for index, host := range hosts {
+		cryptor.MaskAttrValues(host)
 		searchResult.Info[index] = host
 	}
 	return searchResult, nil
@@ -286,6 +288,7 @@ func (s *Searcher) listAllBizHostsPage(ctx context.Context, fields []string, pag
 	}
 	searchResult.Info = make([]map[string]interface{}, len(hosts))
 	for index, host := range hosts {
+		cryptor.MaskAttrValues(host)
 		searchResult.Info[index] = host
 	}
 	return searchResult, nil
@@ -0,0 +1,120 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package instances
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// removeMaskedAttrValues removes the masked encrypted attribute values from the instance data, the masked values
+// returned by the search apis may be submitted back, they must not overwrite the actual values
+func removeMaskedAttrValues(data mapstr.MapStr, properties map[string]metadata.Attribute) {
+	for key, value := range data {
+		if attr, exists := properties[key]; exists && attr.IsEncrypted && value == cryptor.AttrValueMask {
+			delete(data, key)
+		}
+	}
+}
+
+// encryptAttrValues encrypts the values of the encrypted attributes in the instance data before it is stored
+func (m *instanceManager) encryptAttrValues(kit *rest.Kit, data mapstr.MapStr,
+	properties map[string]metadata.Attribute) error {
+
+	for key, value := range data {
+		attr, exists := properties[key]
+		if !exists || !attr.IsEncrypted {
+			continue
+		}
+
+		plaintext, ok := value.(string)
+		if !ok || plaintext == "" {
+			continue
+		}
+
+		if m.attrCryptor == nil {
+			blog.Errorf("attribute %s is encrypted, but attribute crypto is not configured, rid: %s", key, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommAttrCryptoNotConfigured)
+		}
+
+		// the ciphertext is never returned to the client, value with the encrypted prefix is not a valid input
+		if cryptor.IsEncryptedAttrValue(plaintext) {
+			blog.Errorf("attribute %s value has the encrypted prefix, rid: %s", key, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, key)
+		}
+
+		ciphertext, err := m.attrCryptor.Encrypt(plaintext)
+		if err != nil {
+			blog.Errorf("encrypt attribute %s value failed, err: %v, rid: %s", key, err, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommAttrCryptoFailed, key)
+		}
+		data[key] = ciphertext
+	}
+
+	return nil
+}
+
+// DecryptInstAttr decrypt the encrypted attribute value of an instance
+func (m *instanceManager) DecryptInstAttr(kit *rest.Kit, objID string, opt *metadata.DecryptInstAttrOption) (
+	*metadata.DecryptInstAttrResult, error) {
+
+	if m.attrCryptor == nil {
+		return nil, kit.CCError.CCError(common.CCErrCommAttrCryptoNotConfigured)
+	}
+
+	attrCond := mapstr.MapStr{
+		common.BKObjIDField:      objID,
+		common.BKPropertyIDField: opt.PropertyID,
+	}
+	attrCond = util.SetQueryOwner(attrCond, kit.SupplierAccount)
+
+	attrs := make([]metadata.Attribute, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjAttDes).Find(attrCond).Limit(1).All(kit.Ctx, &attrs)
+	if err != nil {
+		blog.Errorf("get attribute failed, cond: %+v, err: %v, rid: %s", attrCond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(attrs) == 0 || !attrs[0].IsEncrypted {
+		blog.Errorf("object %s attribute %s is not encrypted, rid: %s", objID, opt.PropertyID, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKPropertyIDField)
+	}
+
+	inst, err := m.getInstDataByID(kit, objID, opt.InstID)
+	if err != nil {
+		blog.Errorf("get %s instance %d failed, err: %v, rid: %s", objID, opt.InstID, err, kit.Rid)
+		if mongodb.Client().IsNotFoundError(err) {
+			return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+		}
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	plaintext, err := m.attrCryptor.Decrypt(util.GetStrByInterface(inst[opt.PropertyID]))
+	if err != nil {
+		blog.Errorf("decrypt %s instance %d attribute %s failed, err: %v, rid: %s", objID, opt.InstID,
+			opt.PropertyID, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommAttrCryptoFailed, opt.PropertyID)
+	}
+
+	return &metadata.DecryptInstAttrResult{PropertyID: opt.PropertyID, Value: plaintext}, nil
+}
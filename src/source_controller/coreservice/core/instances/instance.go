@@ -23,13 +23,16 @@ import (
 	"configcenter/src/apimachinery"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
+	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/language"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/source_controller/coreservice/core/computed"
 	"configcenter/src/storage/dal/types"
 	"configcenter/src/storage/driver/mongodb"
 	"configcenter/src/storage/driver/mongodb/instancemapping"
@@ -42,15 +45,18 @@ type instanceManager struct {
 	dependent OperationDependences
 	language  language.CCLanguageIf
 	clientSet apimachinery.ClientSetInterface
+	// attrCryptor is used to encrypt the encrypted attributes' values, nil if it is not configured
+	attrCryptor *cryptor.AttrCryptor
 }
 
 // New create a new instance manager instance
 func New(dependent OperationDependences, language language.CCLanguageIf,
-	clientSet apimachinery.ClientSetInterface) core.InstanceOperation {
+	clientSet apimachinery.ClientSetInterface, attrCryptor *cryptor.AttrCryptor) core.InstanceOperation {
 	return &instanceManager{
-		dependent: dependent,
-		language:  language,
-		clientSet: clientSet,
+		dependent:   dependent,
+		language:    language,
+		clientSet:   clientSet,
+		attrCryptor: attrCryptor,
 	}
 }
 
@@ -88,6 +94,7 @@ func (m *instanceManager) CreateModelInstance(kit *rest.Kit, objID string,
 		}
 	}
 
+	removeMaskedAttrValues(inputParam.Data, validator.properties)
 	err = m.validCreateInstanceData(kit, objID, inputParam.Data, validator)
 	if nil != err {
 		blog.Errorf("CreateModelInstance failed, validCreateInstanceData error:%v, objID:%s, data:%#v, rid:%s", err,
@@ -95,6 +102,10 @@ func (m *instanceManager) CreateModelInstance(kit *rest.Kit, objID string,
 		return nil, err
 	}
 
+	if err = m.encryptAttrValues(kit, inputParam.Data, validator.properties); err != nil {
+		return nil, err
+	}
+
 	id, err := m.save(kit, objID, inputParam.Data)
 	if err != nil {
 		blog.ErrorJSON("CreateModelInstance failed, save error:%v, objID:%s, data:%s, rid:%s",
@@ -139,7 +150,11 @@ func (m *instanceManager) CreateManyModelInstance(kit *rest.Kit,
 			return nil, kit.CCError.CCErrorf(common.CCErrCommNotFound)
 		}
 
+		removeMaskedAttrValues(item, validator.properties)
 		err = m.validCreateInstanceData(kit, objID, item, validator)
+		if err == nil {
+			err = m.encryptAttrValues(kit, item, validator.properties)
+		}
 		if err != nil {
 			blog.Errorf("valid create instance data(%#v) failed, err: %v, obj: %s, rid: %s", err, item, objID, kit.Rid)
 			// 由于此err返回的类型可能是mongo返回的error，也可能是经过转化之后的CCError，当返回值是mongo返回的error的场景下没有
@@ -223,12 +238,17 @@ func (m *instanceManager) BatchCreateModelInstance(kit *rest.Kit, objID string,
 			blog.Errorf("get validator failed, objID: %s, inst: %#v, rid: %s", objID, inputParam.Data[idx], kit.Rid)
 			return nil, kit.CCError.CCErrorf(common.CCErrCommNotFound)
 		}
+		removeMaskedAttrValues(inputParam.Data[idx], validator.properties)
 		err = m.validCreateInstanceData(kit, objID, inputParam.Data[idx], validator)
 		if err != nil {
 			blog.Errorf("valid create instance data(%#v) failed, err: %v, obj: %s, rid: %s", err, inputParam.Data[idx],
 				objID, kit.Rid)
 			return nil, err
 		}
+
+		if err = m.encryptAttrValues(kit, inputParam.Data[idx], validator.properties); err != nil {
+			return nil, err
+		}
 	}
 
 	ids, err := m.batchSave(kit, objID, inputParam.Data)
@@ -271,6 +291,7 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		return nil, err
 	}
 
+	var computedAttrs []computed.Attribute
 	for index, origin := range origins {
 		validator := instValidators[index]
 		if validator == nil {
@@ -278,6 +299,17 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 			return nil, kit.CCError.CCErrorf(common.CCErrCommNotFound)
 		}
 
+		// computed values are calculated by the system, they can not be updated by user
+		if index == 0 {
+			computedAttrs, err = computed.ParseAttributes(validator.propertySlice)
+			if err != nil {
+				blog.Errorf("parse %s computed attributes failed, err: %v, rid: %s", objID, err, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, err.Error())
+			}
+			computed.RemoveValues(inputParam.Data, computedAttrs)
+			removeMaskedAttrValues(inputParam.Data, validator.properties)
+		}
+
 		// it is not allowed to update multiple records if the updateData has a unique field
 		if index == 0 && len(origins) > 1 {
 			if err := validator.validUpdateUniqFieldInMulti(kit, inputParam.Data, m); err != nil {
@@ -292,13 +324,17 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		}
 
 		if err = m.validUpdateInstanceData(kit, objID, inputParam.Data, origin, validator, inputParam.CanEditAll,
-			isMainline); err != nil {
+			isMainline, httpheader.IsLifecycleTransition(kit.Header)); err != nil {
 			blog.Errorf("update instance validation failed, err: %v, objID: %s, update data: %#v, inst: %#v, rid: %s",
 				err, objID, inputParam.Data, origin, kit.Rid)
-			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, err.Error())
+			return nil, err
 		}
 	}
 
+	if err = m.encryptAttrValues(kit, inputParam.Data, instValidators[0].properties); err != nil {
+		return nil, err
+	}
+
 	err = m.update(kit, objID, inputParam.Data, inputParam.Condition)
 	if err != nil {
 		blog.Errorf("update objID(%s) inst failed, err: %v, condition: %#v, data: %#v rid: %s", objID, err,
@@ -312,6 +348,11 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		}
 	}
 
+	if err = computed.UpdateExpressionValues(kit, objID, inputParam.Data, origins, computedAttrs); err != nil {
+		blog.Errorf("update %s computed values failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+
 	return &metadata.UpdatedCount{Count: uint64(len(origins))}, nil
 }
 
@@ -742,6 +783,9 @@ func FindInst(kit *rest.Kit, fields []string, query types.Find, objID string) ([
 			inst.Remove(common.LastTimeField)
 		}
 
+		// the encrypted attribute values can only be got by the decrypt api that checks the caller's permission
+		cryptor.MaskAttrValues(inst)
+
 		insts[idx] = inst
 	}
 
@@ -31,15 +31,14 @@ import (
 func TestCreateOneInstance(t *testing.T) {
 
 	instMgr := newInstances(t)
-	objID := "bk_switch"
-	inputParams := metadata.CreateModelInstance{}
+	objID := testObjID
+	inputParams := metadata.CreateModelInstance{Data: mapstr.New()}
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 
 	// create a new bk_switch instance without bk_asset_id
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.NotNil(t, err)
-	require.NotNil(t, dataResult)
-	require.Equal(t, uint64(0), dataResult.Created.ID)
+	require.Nil(t, dataResult)
 	tmpErr, ok := err.(errors.CCErrorCoder)
 	require.True(t, ok, "err must be the errors of the cmdb")
 	require.Equal(t, common.CCErrCommParamsNeedSet, tmpErr.GetCode())
@@ -47,7 +46,7 @@ func TestCreateOneInstance(t *testing.T) {
 	// create a valid model  instance with valid params
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err = instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err = instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotEqual(t, uint64(0), dataResult.Created.ID)
 
@@ -56,7 +55,7 @@ func TestCreateOneInstance(t *testing.T) {
 func TestCreateManyInstance(t *testing.T) {
 
 	instMgr := newInstances(t)
-	objID := "bk_switch"
+	objID := testObjID
 	inputParams := metadata.CreateManyModelInstance{}
 	inputParams.Datas = append(inputParams.Datas, mapstr.MapStr{
 		common.BKInstNameField: xid.New().String(),
@@ -78,92 +77,104 @@ func TestCreateManyInstance(t *testing.T) {
 		common.BKAssetIDField:  xid.New().String(),
 	})
 
-	// create two new bk_switch instance without bk_asset_id
-	dataResult, err := instMgr.CreateManyModelInstance(defaultCtx, objID, inputParams)
+	// the third instance has the same bk_asset_id with the second one
+	dataResult, err := instMgr.CreateManyModelInstance(defaultKit, objID, inputParams)
 
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
-	require.NotEqual(t, 0, len(dataResult.Repeated))
-	require.NotEqual(t, 0, len(dataResult.Created))
+	require.Len(t, dataResult.Repeated, 1)
+	require.Equal(t, int64(2), dataResult.Repeated[0].OriginIndex)
+	require.Len(t, dataResult.Created, 3)
 }
 
 func TestUpdateOneInstance(t *testing.T) {
 
 	instMgr := newInstances(t)
-	objID := "bk_switch"
+	objID := testObjID
 
 	// create one bk_switch instance data
-	inputParams := metadata.CreateModelInstance{}
+	inputParams := metadata.CreateModelInstance{Data: mapstr.New()}
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
-	require.Equal(t, uint64(0), dataResult.Created.ID)
+	require.NotEqual(t, uint64(0), dataResult.Created.ID)
 
 	// update one bk_switch instance by condition
 	updateParams := metadata.UpdateOption{}
 	updateParams.Condition = mapstr.MapStr{"bk_sn": "cmdb_sn"}
 	updateParams.Data = mapstr.MapStr{"bk_operator": "test"}
-	updateResult, err := instMgr.UpdateModelInstance(defaultCtx, objID, updateParams)
+	updateResult, err := instMgr.UpdateModelInstance(defaultKit, objID, updateParams)
 
 	require.Nil(t, err)
 	require.NotNil(t, updateResult)
-	require.NotEqual(t, uint64(0), updateResult.Count)
+	require.Equal(t, uint64(1), updateResult.Count)
 
+	// the updated value can be searched
+	searchCond := metadata.QueryCondition{Condition: mapstr.MapStr{"bk_operator": "test"}}
+	searchResult, err := instMgr.SearchModelInstance(defaultKit, objID, searchCond)
+	require.Nil(t, err)
+	require.Len(t, searchResult.Info, 1)
+	require.Equal(t, "cmdb_sn", searchResult.Info[0]["bk_sn"])
 }
 
 func TestSearchAndDeleteInstance(t *testing.T) {
 	instMgr := newInstances(t)
-	objID := "bk_switch"
+	objID := testObjID
 
 	// create one bk_switch instance data
-	inputParams := metadata.CreateModelInstance{}
+	inputParams := metadata.CreateModelInstance{Data: mapstr.New()}
 	inputParams.Data.Set(common.BKInstNameField, "test_sw1")
 	inputParams.Data.Set(common.BKAssetIDField, "test_sw_001")
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
-	require.Equal(t, uint64(0), dataResult.Created.ID)
+	require.NotEqual(t, uint64(0), dataResult.Created.ID)
 
 	// search  this instance
-	searchCond := metadata.QueryCondition{}
+	searchCond := metadata.QueryCondition{Condition: mapstr.New()}
 	searchCond.Condition.Set("bk_sn", "cmdb_sn")
-	searchResult, err := instMgr.SearchModelInstance(defaultCtx, objID, searchCond)
+	searchResult, err := instMgr.SearchModelInstance(defaultKit, objID, searchCond)
 	require.Nil(t, err)
 	require.NotNil(t, searchResult)
-	require.NotEqual(t, uint64(0), searchResult.Count)
-	require.NotEqual(t, uint64(0), len(searchResult.Info))
+	require.Equal(t, uint64(1), searchResult.Count)
+	require.Len(t, searchResult.Info, 1)
+	require.Equal(t, "test_sw1", searchResult.Info[0][common.BKInstNameField])
 
 	// delete   this instance
-	deleteCond := metadata.DeleteOption{}
+	deleteCond := metadata.DeleteOption{Condition: mapstr.New()}
 	deleteCond.Condition.Set("bk_sn", "cmdb_sn")
-	deleteResult, err := instMgr.DeleteModelInstance(defaultCtx, objID, deleteCond)
+	deleteResult, err := instMgr.DeleteModelInstance(defaultKit, objID, deleteCond)
 	require.Nil(t, err)
 	require.NotNil(t, deleteResult)
-	require.NotEqual(t, uint64(0), deleteResult.Count)
+	require.Equal(t, uint64(1), deleteResult.Count)
+
+	searchResult, err = instMgr.SearchModelInstance(defaultKit, objID, searchCond)
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), searchResult.Count)
 }
 
 func TestCascadeDeleteInstance(t *testing.T) {
 	instMgr := newInstances(t)
-	objID := "bk_switch"
+	objID := testObjID
 
 	// create one bk_switch instance data
-	inputParams := metadata.CreateModelInstance{}
+	inputParams := metadata.CreateModelInstance{Data: mapstr.New()}
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
-	require.Equal(t, uint64(0), dataResult.Created.ID)
+	require.NotEqual(t, uint64(0), dataResult.Created.ID)
 
 	// delete   this instance
-	deleteCond := metadata.DeleteOption{}
+	deleteCond := metadata.DeleteOption{Condition: mapstr.New()}
 	deleteCond.Condition.Set("bk_sn", "cmdb_sn")
-	deleteResult, err := instMgr.CascadeDeleteModelInstance(defaultCtx, objID, deleteCond)
+	deleteResult, err := instMgr.CascadeDeleteModelInstance(defaultKit, objID, deleteCond)
 	require.Nil(t, err)
 	require.NotNil(t, deleteResult)
 	require.NotEqual(t, uint64(0), deleteResult.Count)
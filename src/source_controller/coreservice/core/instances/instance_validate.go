@@ -30,6 +30,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/common/valid"
+	"configcenter/src/source_controller/coreservice/core/computed"
 	"configcenter/src/storage/driver/mongodb"
 	"configcenter/src/thirdparty/hooks"
 )
@@ -162,10 +163,23 @@ func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, i
 			return valid.errIf.Errorf(common.CCErrCommParamsNeedSet, key)
 		}
 	}
+
+	valid.fillInitialState(instanceData)
 	if err := FillLostFieldValue(kit.Ctx, instanceData, valid.propertySlice); err != nil {
 		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
 	}
 
+	computedAttrs, err := computed.ParseAttributes(valid.propertySlice)
+	if err != nil {
+		blog.Errorf("parse %s computed attributes failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
+	if err = computed.FillCreateValues(instanceData, computedAttrs); err != nil {
+		blog.Errorf("fill computed values failed, inst: %+v, err: %v, rid: %s", instanceData, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
+	}
+
 	if err := m.validCloudID(kit, objID, instanceData); err != nil {
 		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
 	}
@@ -211,6 +225,14 @@ func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, i
 		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, err.Error())
 	}
 
+	if err := valid.validInstanceRules(kit, instanceData); err != nil {
+		return err
+	}
+
+	if err := valid.validCreateLifecycle(kit, instanceData); err != nil {
+		return err
+	}
+
 	switch objID {
 	case common.BKInnerObjIDModule:
 		// module instance's name must coincide with template
@@ -334,7 +356,7 @@ func (m *instanceManager) validateModuleCreate(kit *rest.Kit, instanceData mapst
 }
 
 func (m *instanceManager) validUpdateInstanceData(kit *rest.Kit, objID string, updateData, instanceData mapstr.MapStr,
-	valid *validator, canEditAll, isMainline bool) error {
+	valid *validator, canEditAll, isMainline, isTransition bool) error {
 
 	if err := m.validCloudID(kit, objID, updateData); err != nil {
 		return err
@@ -376,6 +398,17 @@ func (m *instanceManager) validUpdateInstanceData(kit *rest.Kit, objID string, u
 		return err
 	}
 
+	// cross-field validation rules are checked against the instance data after it is updated
+	mergedData := instanceData.Clone()
+	mergedData.Merge(updateData)
+	if err := valid.validInstanceRules(kit, mergedData); err != nil {
+		return err
+	}
+
+	if err := valid.validUpdateLifecycle(kit, instanceData, mergedData, isTransition); err != nil {
+		return err
+	}
+
 	skip, err := hooks.IsSkipValidateHook(kit, objID, instanceData)
 	if err != nil {
 		blog.Errorf("check is skip validate %s hook failed, err: %v, rid: %s", objID, err, kit.Rid)
@@ -19,58 +19,117 @@ package instances_test
 import (
 	"context"
 	"testing"
-	"time"
 
+	"configcenter/src/common"
 	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
 	"configcenter/src/common/language"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/source_controller/coreservice/core/instances"
 	"configcenter/src/storage/dal/mongo"
-	"configcenter/src/storage/dal/mongo/local"
+	"configcenter/src/storage/dal/types"
+	"configcenter/src/storage/driver/mongodb"
 
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
+// mockDependences returns the attributes of the test model, the other dependent operations do nothing
 type mockDependences struct {
+	attributes []metadata.Attribute
 }
 
-// IsInstanceExist used to check if the  instances  asst exist
-func (s *mockDependences) IsInstAsstExist(ctx core.ContextParams, objID string, instID uint64) (exists bool, err error) {
+// IsInstAsstExist used to check if the  instances  asst exist
+func (s *mockDependences) IsInstAsstExist(kit *rest.Kit, objID string, instID uint64) (exists bool, err error) {
 	return false, nil
 }
 
 // DeleteInstAsst used to delete inst asst
-func (s *mockDependences) DeleteInstAsst(ctx core.ContextParams, objID string, instID uint64) error {
+func (s *mockDependences) DeleteInstAsst(kit *rest.Kit, objID string, instID uint64) error {
 	return nil
 }
 
 // SelectObjectAttWithParams select object att with params
-func (s *mockDependences) SelectObjectAttWithParams(ctx core.ContextParams, objID string) (attribute []metadata.Attribute, err error) {
-	return nil, nil
+func (s *mockDependences) SelectObjectAttWithParams(kit *rest.Kit, objID string, bizIDs []int64) (
+	attribute []metadata.Attribute, err error) {
+	return s.attributes, nil
+}
+
+// SelectObjectAttributes select object attributes
+func (s *mockDependences) SelectObjectAttributes(kit *rest.Kit, objID string, bizIDs []int64) (
+	[]metadata.Attribute, error) {
+	return s.attributes, nil
 }
 
 // SearchUnique search unique attribute
-func (s *mockDependences) SearchUnique(ctx core.ContextParams, objID string) (uniqueAttr []metadata.ObjectUnique, err error) {
+func (s *mockDependences) SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []metadata.ObjectUnique, err error) {
 	return nil, nil
 }
 
+// DeleteQuotedInst delete quoted instances by source instance ids
+func (s *mockDependences) DeleteQuotedInst(kit *rest.Kit, objID string, instIDs []int64) error {
+	return nil
+}
+
+// AttachQuotedInst attach quoted instances with source instance
+func (s *mockDependences) AttachQuotedInst(kit *rest.Kit, objID string, instID uint64, data mapstr.MapStr) error {
+	return nil
+}
+
+const testObjID = "bk_switch"
+
+// newInstances creates an instance manager that runs on a new in memory db, so the tests need no mongodb
 func newInstances(t *testing.T) core.InstanceOperation {
+	require.Nil(t, mongodb.InitClient("", &mongo.Config{Engine: mongo.EngineMemory}))
+
+	// bk_asset_id is unique in the instance table, like the unique index created by the model unique rules
+	index := types.Index{
+		Keys:   bson.D{{Key: common.BKAssetIDField, Value: 1}},
+		Name:   "bkcc_unique_bk_asset_id",
+		Unique: true,
+	}
+	tableName := common.GetInstTableName(testObjID, defaultKit.SupplierAccount)
+	require.Nil(t, mongodb.Client().Table(tableName).CreateIndex(context.Background(), index))
 
-	db, err := local.NewMgo("mongodb://cc:cc@localhost:27010,localhost:27011,localhost:27012,localhost:27013/cmdb", time.Minute)
-	require.NoError(t, err)
-	return instances.New(db, &mockDependences{})
+	dependent := &mockDependences{
+		attributes: []metadata.Attribute{
+			newAttribute(1, common.BKInstNameField, true),
+			newAttribute(2, common.BKAssetIDField, true),
+			newAttribute(3, "bk_sn", false),
+			newAttribute(4, "bk_operator", false),
+			newAttribute(5, "bk_state", false),
+		},
+	}
+	return instances.New(dependent, defaultLang, nil, nil)
 }
 
-var defaultCtx = func() core.ContextParams {
-	err, _ := errors.New("../../../../../resources/errors/")
+func newAttribute(id int64, propertyID string, isRequired bool) metadata.Attribute {
+	return metadata.Attribute{
+		ID:           id,
+		OwnerID:      defaultKit.SupplierAccount,
+		ObjectID:     testObjID,
+		PropertyID:   propertyID,
+		PropertyName: propertyID,
+		PropertyType: common.FieldTypeSingleChar,
+		IsEditable:   true,
+		IsRequired:   isRequired,
+	}
+}
+
+var defaultLang = func() language.CCLanguageIf {
 	lan, _ := language.New("../../../../../resources/language/")
-	return core.ContextParams{
-		Context:         context.Background(),
-		ReqID:           "test_req_id",
-		SupplierAccount: "test_owner",
+	return lan
+}()
+
+var defaultKit = func() *rest.Kit {
+	errIf, _ := errors.NewFactory("../../../../../resources/errors/")
+	return &rest.Kit{
+		Rid:             "test_req_id",
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
 		User:            "test_user",
-		Error:           err.CreateDefaultCCErrorIf("en"),
-		Lang:            lan.CreateDefaultCCLanguageIf("en"),
+		SupplierAccount: "0",
 	}
 }()
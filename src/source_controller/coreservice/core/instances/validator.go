@@ -36,6 +36,10 @@ type validator struct {
 	dependent     OperationDependences
 	objID         string
 	language      language.CCLanguageIf
+	// validationRules is the object's cross-field validation rules
+	validationRules []metadata.ObjectValidationRule
+	// lifecycle is the object's lifecycle state machine, nil if the object has no lifecycle
+	lifecycle *metadata.ObjectLifecycle
 }
 
 // NewValidator TODO
@@ -79,6 +83,16 @@ func NewValidator(kit *rest.Kit, dependent OperationDependences, objID string, b
 	}
 	valid.uniqueAttrs = uniqueAttrs
 
+	valid.validationRules, err = getValidationRules(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid.lifecycle, err = getLifecycle(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
 	return valid, nil
 }
 
@@ -97,6 +111,16 @@ func NewValidators(kit *rest.Kit, dependent OperationDependences, objID string,
 		uniqueAttrs = make([]metadata.ObjectUnique, 0)
 	}
 
+	validationRules, err := getValidationRules(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycle, err := getLifecycle(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
 	attributes, err := dependent.SelectObjectAttributes(kit, objID, bizIDs)
 	if err != nil {
 		return nil, err
@@ -114,16 +138,18 @@ func NewValidators(kit *rest.Kit, dependent OperationDependences, objID string,
 		}
 
 		validator := &validator{
-			properties:    make(map[string]metadata.Attribute),
-			idToProperty:  make(map[int64]metadata.Attribute),
-			propertySlice: make([]metadata.Attribute, 0),
-			require:       make(map[string]bool),
-			requireFields: make([]string, 0),
-			uniqueAttrs:   uniqueAttrs,
-			objID:         objID,
-			errIf:         kit.CCError,
-			dependent:     dependent,
-			language:      language,
+			properties:      make(map[string]metadata.Attribute),
+			idToProperty:    make(map[int64]metadata.Attribute),
+			propertySlice:   make([]metadata.Attribute, 0),
+			require:         make(map[string]bool),
+			requireFields:   make([]string, 0),
+			uniqueAttrs:     uniqueAttrs,
+			validationRules: validationRules,
+			lifecycle:       lifecycle,
+			objID:           objID,
+			errIf:           kit.CCError,
+			dependent:       dependent,
+			language:        language,
 		}
 
 		// the instances in biz has both biz attributes and global attributes that has no biz id
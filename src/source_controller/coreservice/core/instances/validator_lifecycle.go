@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package instances
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	attrvalid "configcenter/src/common/valid/attribute"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// getLifecycle get the lifecycle state machine of the object, returns nil if the object has no lifecycle
+func getLifecycle(kit *rest.Kit, objID string) (*metadata.ObjectLifecycle, error) {
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: objID}, kit.SupplierAccount)
+	lifecycles := make([]metadata.ObjectLifecycle, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Find(cond).All(kit.Ctx, &lifecycles)
+	if err != nil {
+		blog.Errorf("get object %s lifecycle failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(lifecycles) == 0 {
+		return nil, nil
+	}
+	return &lifecycles[0], nil
+}
+
+// fillInitialState set the lifecycle's initial state for the newly created instance that does not specify one
+func (valid *validator) fillInitialState(instanceData mapstr.MapStr) {
+	if valid.lifecycle == nil || len(valid.lifecycle.InitialState) == 0 {
+		return
+	}
+
+	if attrvalid.IsEmptyValue(instanceData[valid.lifecycle.PropertyID]) {
+		instanceData[valid.lifecycle.PropertyID] = valid.lifecycle.InitialState
+	}
+}
+
+// validCreateLifecycle check if the created instance is in a state of the lifecycle and has set the state's
+// required fields
+func (valid *validator) validCreateLifecycle(kit *rest.Kit, instanceData mapstr.MapStr) error {
+	if valid.lifecycle == nil {
+		return nil
+	}
+
+	state := util.GetStrByInterface(instanceData[valid.lifecycle.PropertyID])
+	if len(state) == 0 {
+		return nil
+	}
+
+	return valid.validStateRequiredFields(kit, state, instanceData)
+}
+
+// validUpdateLifecycle check if the state change of the updated instance is an allowed transition that the user
+// may perform, and the updated instance has set the required fields of its state. the state can only be changed by
+// the state transition, so that the transition and its reason are recorded in the audit log.
+func (valid *validator) validUpdateLifecycle(kit *rest.Kit, origin, updated mapstr.MapStr, isTransition bool) error {
+	if valid.lifecycle == nil {
+		return nil
+	}
+
+	field := valid.lifecycle.PropertyID
+	from := util.GetStrByInterface(origin[field])
+	to := util.GetStrByInterface(updated[field])
+	if len(to) == 0 {
+		if len(from) == 0 {
+			return nil
+		}
+		blog.Errorf("instance state can not be reset from %s to empty, rid: %s", from, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommLifecycleTransitionNotAllowed, from, to)
+	}
+
+	// instances that are created before the lifecycle is defined can be set to any state once
+	if from != to && len(from) != 0 {
+		if !isTransition {
+			blog.Errorf("instance state can only be changed from %s to %s by transition, rid: %s", from, to, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommLifecycleTransitionRequired, field)
+		}
+
+		transition := valid.lifecycle.GetTransition(from, to)
+		if transition == nil {
+			blog.Errorf("instance state transition from %s to %s is not allowed, rid: %s", from, to, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommLifecycleTransitionNotAllowed, from, to)
+		}
+
+		if !transition.CanOperate(kit.User) {
+			blog.Errorf("user %s can not transit instance state from %s to %s, rid: %s", kit.User, from, to, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommLifecycleTransitionNoPermission, kit.User, from, to)
+		}
+	}
+
+	return valid.validStateRequiredFields(kit, to, updated)
+}
+
+func (valid *validator) validStateRequiredFields(kit *rest.Kit, state string, instanceData mapstr.MapStr) error {
+	lifecycleState := valid.lifecycle.GetState(state)
+	if lifecycleState == nil {
+		blog.Errorf("instance state %s is not defined in the lifecycle, rid: %s", state, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, valid.lifecycle.PropertyID)
+	}
+
+	for _, field := range lifecycleState.RequiredFields {
+		if attrvalid.IsEmptyValue(instanceData[field]) {
+			blog.Errorf("field %s is required in state %s, rid: %s", field, state, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommLifecycleStateFieldRequired, field, state)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package instances_test
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateLifecycleState(t *testing.T) {
+	instMgr := newInstances(t)
+
+	lifecycle := metadata.ObjectLifecycle{
+		ID:    1,
+		ObjID: testObjID,
+		LifecycleSpec: metadata.LifecycleSpec{
+			PropertyID:   "bk_state",
+			InitialState: "running",
+			States:       []metadata.LifecycleState{{ID: "running"}, {ID: "retired"}},
+			Transitions: []metadata.LifecycleTransition{{From: "running", To: "retired",
+				Operators: []string{"admin"}}},
+		},
+		OwnerID: defaultKit.SupplierAccount,
+	}
+	err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Insert(context.Background(), lifecycle)
+	require.NoError(t, err)
+
+	inputParams := metadata.CreateModelInstance{Data: mapstr.MapStr{
+		common.BKInstNameField: xid.New().String(),
+		common.BKAssetIDField:  xid.New().String(),
+	}}
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, testObjID, inputParams)
+	require.NoError(t, err)
+	cond := mapstr.MapStr{common.BKInstIDField: dataResult.Created.ID}
+
+	tests := []struct {
+		name         string
+		user         string
+		isTransition bool
+		wantErrCode  int
+	}{
+		{
+			name:        "state changed by generic update",
+			user:        "admin",
+			wantErrCode: common.CCErrCommLifecycleTransitionRequired,
+		},
+		{
+			name:         "user is not the operator",
+			user:         defaultKit.User,
+			isTransition: true,
+			wantErrCode:  common.CCErrCommLifecycleTransitionNoPermission,
+		},
+		{
+			name:         "system user is not the operator",
+			user:         common.CCSystemOperatorUserName,
+			isTransition: true,
+			wantErrCode:  common.CCErrCommLifecycleTransitionNoPermission,
+		},
+		{
+			name:         "operator transits the state",
+			user:         "admin",
+			isTransition: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kit := *defaultKit
+			kit.User = tt.user
+			kit.Header = util.CloneHeader(defaultKit.Header)
+			if tt.isTransition {
+				httpheader.SetLifecycleTransition(kit.Header)
+			}
+			updateParams := metadata.UpdateOption{Condition: cond.Clone(), Data: mapstr.MapStr{"bk_state": "retired"}}
+			_, err := instMgr.UpdateModelInstance(&kit, testObjID, updateParams)
+			if tt.wantErrCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			ccErr, ok := err.(errors.CCErrorCoder)
+			require.True(t, ok, "error %v should be a cc error", err)
+			require.Equal(t, tt.wantErrCode, ccErr.GetCode())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package instances
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	attrvalid "configcenter/src/common/valid/attribute"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// getValidationRules get the cross-field validation rules of the object
+func getValidationRules(kit *rest.Kit, objID string) ([]metadata.ObjectValidationRule, error) {
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: objID}, kit.SupplierAccount)
+	rules := make([]metadata.ObjectValidationRule, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjValidationRule).Find(cond).All(kit.Ctx, &rules)
+	if err != nil {
+		blog.Errorf("get object %s validation rules failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return rules, nil
+}
+
+// validInstanceRules check if the whole instance data satisfies the object's cross-field validation rules
+func (valid *validator) validInstanceRules(kit *rest.Kit, instanceData mapstr.MapStr) error {
+	if len(valid.validationRules) == 0 {
+		return nil
+	}
+
+	return attrvalid.CheckValidationRules(kit, instanceData, valid.validationRules)
+}
@@ -22,6 +22,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/errors"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/http/rest"
@@ -41,6 +42,8 @@ import (
 type modelAttribute struct {
 	model    *modelManager
 	language language.CCLanguageIf
+	// attrCryptor is used to encrypt the encrypted attributes' values, nil if it is not configured
+	attrCryptor *cryptor.AttrCryptor
 }
 
 var forbiddenCreateAttrObjList = []string{
@@ -37,6 +37,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/common/valid"
 	attrvalid "configcenter/src/common/valid/attribute"
+	"configcenter/src/source_controller/coreservice/core/computed"
 	"configcenter/src/storage/dal/types"
 	"configcenter/src/storage/driver/mongodb"
 
@@ -141,7 +142,7 @@ func (m *modelAttribute) save(kit *rest.Kit, attribute metadata.Attribute) (id u
 		switch attribute.PropertyType {
 		case common.FieldTypeSingleChar, common.FieldTypeLongChar, common.FieldTypeInt, common.FieldTypeFloat,
 			common.FieldTypeEnum, common.FieldTypeDate, common.FieldTypeTime, common.FieldTypeTimeZone,
-			common.FieldTypeBool, common.FieldTypeList, common.FieldTypeIDRule:
+			common.FieldTypeBool, common.FieldTypeList, common.FieldTypeIDRule, common.FieldTypeComputed:
 			isMultiple := false
 			attribute.IsMultiple = &isMultiple
 		case common.FieldTypeUser, common.FieldTypeOrganization, common.FieldTypeEnumQuote, common.FieldTypeEnumMulti:
@@ -157,6 +158,12 @@ func (m *modelAttribute) save(kit *rest.Kit, attribute metadata.Attribute) (id u
 
 		attribute.Default = nil
 	}
+	// 计算字段的值由系统计算生成，不能有默认值，也不能由用户编辑
+	if attribute.PropertyType == common.FieldTypeComputed {
+		attribute.Default = nil
+		attribute.IsRequired = false
+		attribute.IsEditable = false
+	}
 	if err = m.saveCheck(kit, attribute); err != nil {
 		return 0, err
 	}
@@ -502,6 +509,7 @@ var validAttrPropertyTypes = map[string]struct{}{
 	common.FieldTypeList:         {},
 	common.FieldTypeEnumQuote:    {},
 	common.FieldTypeIDRule:       {},
+	common.FieldTypeComputed:     {},
 }
 
 func (m *modelAttribute) checkAttributeValidity(kit *rest.Kit, attribute metadata.Attribute,
@@ -548,6 +556,10 @@ func (m *modelAttribute) checkAttributeValidity(kit *rest.Kit, attribute metadat
 		}
 	}
 
+	if err := m.checkEncryptedAttr(kit, attribute.IsEncrypted, propertyType); err != nil {
+		return err
+	}
+
 	if attribute.Default != nil && propertyType != common.FieldTypeEnum && propertyType != common.FieldTypeEnumMulti &&
 		propertyType != common.FieldTypeEnumQuote && propertyType != common.FieldTypeIDRule {
 
@@ -729,12 +741,22 @@ func (m *modelAttribute) update(kit *rest.Kit, data mapstr.MapStr, cond universa
 		blog.ErrorJSON("checkUpdate error. data:%s, cond:%s, rid:%s", data, cond, kit.Rid)
 		return cnt, err
 	}
+
+	toEncryptAttrs, err := m.getToEncryptAttrs(kit, data, cond)
+	if err != nil {
+		return cnt, err
+	}
+
 	cnt, err = mongodb.Client().Table(common.BKTableNameObjAttDes).UpdateMany(kit.Ctx, cond.ToMapStr(), data)
 	if nil != err {
 		blog.Errorf("request(%s): database operation is failed, error info is %s", kit.Rid, err.Error())
 		return 0, err
 	}
 
+	if err = m.encryptInstAttrValues(kit, toEncryptAttrs); err != nil {
+		return 0, err
+	}
+
 	return cnt, err
 }
 
@@ -1211,7 +1233,7 @@ func (m *modelAttribute) saveCheck(kit *rest.Kit, attr metadata.Attribute) error
 		return err
 	}
 
-	if attr.PropertyType != common.FieldTypeIDRule {
+	if attr.PropertyType != common.FieldTypeIDRule && attr.PropertyType != common.FieldTypeComputed {
 		return nil
 	}
 
@@ -1234,6 +1256,10 @@ func (m *modelAttribute) saveCheck(kit *rest.Kit, attr metadata.Attribute) error
 		return err
 	}
 
+	if attr.PropertyType == common.FieldTypeComputed {
+		return checkComputedAggregation(kit, attr.ObjectID, attr.Option)
+	}
+
 	if err = checkAddIDRule(kit, attr.ObjectID); err != nil {
 		blog.ErrorJSON("check add asset id, err: %s, data: %s, rid: %s", err, attr, kit.Ctx)
 		return err
@@ -1505,7 +1531,7 @@ func checkAttrOption(kit *rest.Kit, data mapstr.MapStr, dbAttributeArr []metadat
 	switch propertyType {
 	case common.FieldTypeEnum, common.FieldTypeEnumMulti:
 		extraOpt = isMultiple
-	case common.FieldTypeIDRule:
+	case common.FieldTypeIDRule, common.FieldTypeComputed:
 		dbAttrs := make([]metadata.Attribute, 0)
 		cond := mapstr.MapStr{common.BKObjIDField: dbAttributeArr[0].ObjectID}
 		util.SetQueryOwner(cond, kit.SupplierAccount)
@@ -1529,6 +1555,73 @@ func checkAttrOption(kit *rest.Kit, data mapstr.MapStr, dbAttributeArr []metadat
 		return err
 	}
 
+	if propertyType == common.FieldTypeComputed {
+		for _, dbAttribute := range dbAttributeArr {
+			if err = checkComputedAggregation(kit, dbAttribute.ObjectID, option); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkComputedAggregation check if the instances aggregated by the computed attribute of the object are valid
+func checkComputedAggregation(kit *rest.Kit, objID string, option interface{}) error {
+	computedOpt, err := metadata.ParseComputedOption(option)
+	if err != nil {
+		blog.Errorf("parse computed option %+v failed, err: %v, rid: %s", option, err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldOption)
+	}
+
+	var srcObjID string
+	switch computedOpt.Source {
+	case metadata.ComputedSourceHostRelation:
+		if objID != common.BKInnerObjIDApp && objID != common.BKInnerObjIDSet && objID != common.BKInnerObjIDModule {
+			blog.Errorf("object %s has no host relation to aggregate, rid: %s", objID, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldOption)
+		}
+		srcObjID = common.BKInnerObjIDHost
+	case metadata.ComputedSourceAssociation:
+		asst, err := computed.GetObjAssociation(kit, computedOpt.ObjAsstID)
+		if err != nil {
+			return err
+		}
+
+		switch objID {
+		case asst.ObjectID:
+			srcObjID = asst.AsstObjID
+		case asst.AsstObjID:
+			srcObjID = asst.ObjectID
+		default:
+			blog.Errorf("association %s is not related to object %s, rid: %s", computedOpt.ObjAsstID, objID, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, common.AssociationObjAsstIDField)
+		}
+	default:
+		return nil
+	}
+
+	if computedOpt.Field == "" {
+		return nil
+	}
+
+	cond := mapstr.MapStr{common.BKObjIDField: srcObjID, common.BKPropertyIDField: computedOpt.Field}
+	util.SetQueryOwner(cond, kit.SupplierAccount)
+	srcAttr := new(metadata.Attribute)
+	if err = mongodb.Client().Table(common.BKTableNameObjAttDes).Find(cond).One(kit.Ctx, srcAttr); err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			blog.Errorf("aggregated field %s of object %s not exists, rid: %s", computedOpt.Field, srcObjID, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldOption)
+		}
+		blog.Errorf("get aggregated attribute failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.Error(common.CCErrCommDBSelectFailed)
+	}
+
+	if srcAttr.PropertyType != common.FieldTypeInt && srcAttr.PropertyType != common.FieldTypeFloat {
+		blog.Errorf("aggregated field %s of object %s is not numeric, rid: %s", computedOpt.Field, srcObjID, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldOption)
+	}
+
 	return nil
 }
 
@@ -1662,6 +1755,9 @@ func (m *modelAttribute) checkUpdate(kit *rest.Kit, data mapstr.MapStr, cond uni
 		if err = m.checkChangeField(kit, dbAttribute, data); err != nil {
 			return err
 		}
+		if err = m.checkChangeEncryptedField(kit, dbAttribute, data); err != nil {
+			return err
+		}
 	}
 
 	return err
@@ -1756,6 +1852,57 @@ func (m *modelAttribute) checkChangeField(kit *rest.Kit, attr metadata.Attribute
 	return nil
 }
 
+// checkEncryptedAttr only the string type attributes can be encrypted, and the attribute crypto must be configured
+func (m *modelAttribute) checkEncryptedAttr(kit *rest.Kit, isEncrypted bool, propertyType string) error {
+	if !isEncrypted {
+		return nil
+	}
+
+	if m.attrCryptor == nil {
+		return kit.CCError.CCError(common.CCErrCommAttrCryptoNotConfigured)
+	}
+
+	if propertyType != common.FieldTypeSingleChar && propertyType != common.FieldTypeLongChar {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldIsEncrypted)
+	}
+	return nil
+}
+
+// checkChangeEncryptedField the encrypted attribute can not be changed back to plaintext, and the attribute in the
+// unique rules can not be encrypted, because the encrypted values are different even if the plaintexts are the same
+func (m *modelAttribute) checkChangeEncryptedField(kit *rest.Kit, attr metadata.Attribute,
+	attrInfo mapstr.MapStr) error {
+
+	if !attrInfo.Exists(metadata.AttributeFieldIsEncrypted) {
+		return nil
+	}
+
+	isEncrypted, ok := attrInfo[metadata.AttributeFieldIsEncrypted].(bool)
+	if !ok {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldIsEncrypted)
+	}
+
+	if isEncrypted == attr.IsEncrypted {
+		return nil
+	}
+
+	if !isEncrypted {
+		blog.Errorf("encrypted attribute %s can not be changed to plaintext, rid: %s", attr.PropertyID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldIsEncrypted)
+	}
+
+	inUnique, err := m.checkAttributeInUnique(kit, map[string][]int64{attr.ObjectID: {attr.ID}})
+	if err != nil {
+		return err
+	}
+
+	if inUnique {
+		blog.Errorf("attribute %s in unique rules can not be encrypted, rid: %s", attr.PropertyID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldIsEncrypted)
+	}
+	return nil
+}
+
 func (m *modelAttribute) getLangObjID(kit *rest.Kit, objID string) string {
 	langKey := "object_" + objID
 	language := httpheader.GetLanguage(kit.Header)
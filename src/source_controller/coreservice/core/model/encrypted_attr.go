@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package model
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/universalsql"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// getToEncryptAttrs get the attributes that are changed to be encrypted by the update data
+func (m *modelAttribute) getToEncryptAttrs(kit *rest.Kit, data mapstr.MapStr, cond universalsql.Condition) (
+	[]metadata.Attribute, error) {
+
+	if isEncrypted, ok := data[metadata.AttributeFieldIsEncrypted].(bool); !ok || !isEncrypted {
+		return nil, nil
+	}
+
+	attrs, err := m.search(kit, cond)
+	if err != nil {
+		blog.Errorf("search attributes failed, cond: %v, err: %v, rid: %s", cond.ToMapStr(), err, kit.Rid)
+		return nil, err
+	}
+
+	toEncryptAttrs := make([]metadata.Attribute, 0)
+	for _, attr := range attrs {
+		if !attr.IsEncrypted {
+			toEncryptAttrs = append(toEncryptAttrs, attr)
+		}
+	}
+	return toEncryptAttrs, nil
+}
+
+// encryptInstAttrValues encrypts the existing plaintext values of the attributes that are changed to be encrypted,
+// so that the plaintext values are not left in the instances until the re-encrypt task of the admin server is run
+func (m *modelAttribute) encryptInstAttrValues(kit *rest.Kit, attrs []metadata.Attribute) error {
+	for _, attr := range attrs {
+		table := common.GetInstTableName(attr.ObjectID, kit.SupplierAccount)
+		idField := common.GetInstIDField(attr.ObjectID)
+
+		var lastID int64
+		for {
+			cond := mapstr.MapStr{
+				idField:         mapstr.MapStr{common.BKDBGT: lastID},
+				attr.PropertyID: mapstr.MapStr{common.BKDBExists: true, common.BKDBNE: ""},
+			}
+
+			insts := make([]mapstr.MapStr, 0)
+			err := mongodb.Client().Table(table).Find(cond).Fields(idField, attr.PropertyID).Sort(idField).
+				Limit(pageSize).All(kit.Ctx, &insts)
+			if err != nil {
+				blog.Errorf("get instances from %s failed, cond: %v, err: %v, rid: %s", table, cond, err, kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+			}
+
+			for _, inst := range insts {
+				if lastID, err = util.GetInt64ByInterface(inst[idField]); err != nil {
+					blog.Errorf("parse instance id failed, inst: %v, err: %v, rid: %s", inst, err, kit.Rid)
+					return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, idField)
+				}
+
+				if err = m.encryptInstAttrValue(kit, table, idField, lastID, attr.PropertyID,
+					inst[attr.PropertyID]); err != nil {
+					return err
+				}
+			}
+
+			if len(insts) < pageSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// encryptInstAttrValue encrypts the plaintext attribute value of an instance
+func (m *modelAttribute) encryptInstAttrValue(kit *rest.Kit, table, idField string, instID int64,
+	propertyID string, value interface{}) error {
+
+	plaintext, ok := value.(string)
+	if !ok || cryptor.IsEncryptedAttrValue(plaintext) {
+		return nil
+	}
+
+	ciphertext, err := m.attrCryptor.Encrypt(plaintext)
+	if err != nil {
+		blog.Errorf("encrypt attribute %s value failed, err: %v, rid: %s", propertyID, err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommAttrCryptoFailed, propertyID)
+	}
+
+	cond := mapstr.MapStr{idField: instID}
+	if err = mongodb.Client().Table(table).Update(kit.Ctx, cond, mapstr.MapStr{propertyID: ciphertext}); err != nil {
+		blog.Errorf("update %s instance %d failed, err: %v, rid: %s", table, instID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/cryptor"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/language"
 	"configcenter/src/common/lock"
@@ -48,10 +49,11 @@ type modelManager struct {
 }
 
 // New create a new model manager instance
-func New(dependent OperationDependences, language language.CCLanguageIf) core.ModelOperation {
+func New(dependent OperationDependences, language language.CCLanguageIf,
+	attrCryptor *cryptor.AttrCryptor) core.ModelOperation {
 
 	coreMgr := &modelManager{dependent: dependent, language: language}
-	coreMgr.modelAttribute = &modelAttribute{model: coreMgr, language: language}
+	coreMgr.modelAttribute = &modelAttribute{model: coreMgr, language: language, attrCryptor: attrCryptor}
 	coreMgr.modelClassification = &modelClassification{model: coreMgr}
 	coreMgr.modelAttributeGroup = &modelAttributeGroup{model: coreMgr}
 	coreMgr.modelAttrUnique = &modelAttrUnique{}
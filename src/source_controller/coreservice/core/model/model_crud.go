@@ -388,6 +388,18 @@ func (m *modelManager) cascadeDelete(kit *rest.Kit, objIDs []string) (uint64, er
 		return 0, kit.CCError.Error(common.CCErrCommDBDeleteFailed)
 	}
 
+	// delete model validation rules
+	if err := mongodb.Client().Table(common.BKTableNameObjValidationRule).Delete(kit.Ctx, delCondMap); err != nil {
+		blog.Errorf("delete model validation rule error. err: %v, cond: %s, rid: %s", err, delCondMap, kit.Rid)
+		return 0, kit.CCError.Error(common.CCErrCommDBDeleteFailed)
+	}
+
+	// delete model lifecycle
+	if err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Delete(kit.Ctx, delCondMap); err != nil {
+		blog.Errorf("delete model lifecycle error. err: %v, cond: %s, rid: %s", err, delCondMap, kit.Rid)
+		return 0, kit.CCError.Error(common.CCErrCommDBDeleteFailed)
+	}
+
 	if err := m.updateSortNumWhenDelete(kit, delCondMap); err != nil {
 		blog.Errorf("failed to update object sort number when delete object, err: %v, cond: %v, rid: %s", err,
 			delCondMap, kit.Rid)
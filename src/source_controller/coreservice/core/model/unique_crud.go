@@ -287,6 +287,15 @@ func (m *modelAttrUnique) getUniqueProperties(kit *rest.Kit, objID string, keys
 		return nil, kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "keys")
 	}
 
+	// the encrypted values are different even if the plaintexts are the same, so they can not be unique keys
+	for _, property := range properties {
+		if property.IsEncrypted {
+			blog.Errorf("[ObjectUnique] encrypted attribute %s can not be unique key, rid: %s", property.PropertyID,
+				kit.Rid)
+			return nil, kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, "keys")
+		}
+	}
+
 	return properties, nil
 }
 
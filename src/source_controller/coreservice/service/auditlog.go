@@ -59,6 +59,29 @@ func (s *coreService) SearchAuditLog(ctx *rest.Contexts) {
 	ctx.RespEntityWithCount(int64(count), auditLogs)
 }
 
+// SearchInstSnapshot reconstruct the instance at the specified time from its audit logs
+func (s *coreService) SearchInstSnapshot(ctx *rest.Contexts) {
+	opt := new(metadata.InstSnapshotOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	snapshot, err := s.core.AuditOperation().SearchInstSnapshot(ctx.Kit, opt)
+	if err != nil {
+		blog.Errorf("search instance snapshot failed, err: %v, opt: %#v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(snapshot)
+}
+
 // CreateAuditLogDependence is a dependence for host to create service instance audit logs for transfer operation
 func (s *coreService) CreateAuditLogDependence(kit *rest.Kit, logs ...metadata.AuditLog) error {
 	return s.core.AuditOperation().CreateAuditLog(kit, logs...)
@@ -0,0 +1,364 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package authrole
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// CreateRole create auth role
+func (s *service) CreateRole(ctx *rest.Contexts) {
+	opt := new(metadata.AuthRoleSpec)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.checkRoleNameUnique(ctx.Kit, 0, opt.Name); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameAuthRole)
+	if err != nil {
+		blog.Errorf("generate auth role id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	now := metadata.Now()
+	role := &metadata.AuthRole{
+		ID:          int64(id),
+		Name:        opt.Name,
+		Description: opt.Description,
+		Policies:    opt.Policies,
+		OwnerID:     ctx.Kit.SupplierAccount,
+		Creator:     ctx.Kit.User,
+		Modifier:    ctx.Kit.User,
+		CreateTime:  now,
+		LastTime:    now,
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameAuthRole).Insert(ctx.Kit.Ctx, role); err != nil {
+		blog.Errorf("create auth role %+v failed, err: %v, rid: %s", role, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: role.ID})
+}
+
+// UpdateRole update auth role, its policies are replaced by the new ones
+func (s *service) UpdateRole(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	opt := new(metadata.AuthRoleSpec)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.checkRoleNameUnique(ctx.Kit, id, opt.Name); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetModOwner(mapstr.MapStr{common.BKFieldID: id}, ctx.Kit.SupplierAccount)
+	data := mapstr.MapStr{
+		common.BKFieldName:   opt.Name,
+		"description":        opt.Description,
+		"policies":           opt.Policies,
+		common.ModifierField: ctx.Kit.User,
+		common.LastTimeField: metadata.Now(),
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameAuthRole).Update(ctx.Kit.Ctx, cond, data); err != nil {
+		blog.Errorf("update auth role failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteRole delete auth role together with its bindings
+func (s *service) DeleteRole(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	bindingCond := util.SetModOwner(mapstr.MapStr{metadata.AuthRoleIDField: id}, ctx.Kit.SupplierAccount)
+	err = mongodb.Client().Table(common.BKTableNameAuthRoleBinding).Delete(ctx.Kit.Ctx, bindingCond)
+	if err != nil {
+		blog.Errorf("delete auth role bindings failed, cond: %+v, err: %v, rid: %s", bindingCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	cond := util.SetModOwner(mapstr.MapStr{common.BKFieldID: id}, ctx.Kit.SupplierAccount)
+	if err = mongodb.Client().Table(common.BKTableNameAuthRole).Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete auth role failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListRole list auth roles
+func (s *service) ListRole(ctx *rest.Contexts) {
+	opt := new(metadata.SearchAuthRoleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := util.SetQueryOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	table := mongodb.Client().Table(common.BKTableNameAuthRole)
+
+	result := metadata.AuthRoleList{Info: make([]metadata.AuthRole, 0)}
+	if opt.Page.EnableCount {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count auth role failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		result.Count = int64(count)
+		ctx.RespEntity(result)
+		return
+	}
+
+	err := table.Find(cond).Sort(opt.Page.Sort).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).
+		All(ctx.Kit.Ctx, &result.Info)
+	if err != nil {
+		blog.Errorf("list auth role failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// CreateRoleBinding bind auth role to users
+func (s *service) CreateRoleBinding(ctx *rest.Contexts) {
+	opt := new(metadata.CreateAuthRoleBindingOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	roleCond := util.SetQueryOwner(mapstr.MapStr{common.BKFieldID: opt.RoleID}, ctx.Kit.SupplierAccount)
+	count, err := mongodb.Client().Table(common.BKTableNameAuthRole).Find(roleCond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count auth role failed, cond: %+v, err: %v, rid: %s", roleCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if count == 0 {
+		blog.Errorf("auth role %d is not exist, rid: %s", opt.RoleID, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, metadata.AuthRoleIDField))
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameAuthRoleBinding)
+	if err != nil {
+		blog.Errorf("generate auth role binding id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	now := metadata.Now()
+	binding := &metadata.AuthRoleBinding{
+		ID:         int64(id),
+		RoleID:     opt.RoleID,
+		Users:      util.StrArrayUnique(opt.Users),
+		OwnerID:    ctx.Kit.SupplierAccount,
+		Creator:    ctx.Kit.User,
+		CreateTime: now,
+		LastTime:   now,
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameAuthRoleBinding).Insert(ctx.Kit.Ctx, binding); err != nil {
+		blog.Errorf("create auth role binding %+v failed, err: %v, rid: %s", binding, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: binding.ID})
+}
+
+// DeleteRoleBinding delete auth role binding
+func (s *service) DeleteRoleBinding(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	cond := util.SetModOwner(mapstr.MapStr{common.BKFieldID: id}, ctx.Kit.SupplierAccount)
+	if err = mongodb.Client().Table(common.BKTableNameAuthRoleBinding).Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete auth role binding failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListRoleBinding list auth role bindings
+func (s *service) ListRoleBinding(ctx *rest.Contexts) {
+	opt := new(metadata.SearchAuthRoleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := util.SetQueryOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	table := mongodb.Client().Table(common.BKTableNameAuthRoleBinding)
+
+	result := metadata.AuthRoleBindingList{Info: make([]metadata.AuthRoleBinding, 0)}
+	if opt.Page.EnableCount {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count auth role binding failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		result.Count = int64(count)
+		ctx.RespEntity(result)
+		return
+	}
+
+	err := table.Find(cond).Sort(opt.Page.Sort).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).
+		All(ctx.Kit.Ctx, &result.Info)
+	if err != nil {
+		blog.Errorf("list auth role binding failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// ListUserPolicy list the policies of all the roles that are bound to the user
+func (s *service) ListUserPolicy(ctx *rest.Contexts) {
+	opt := new(metadata.ListUserAuthPolicyOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	bindingCond := util.SetQueryOwner(mapstr.MapStr{metadata.AuthRoleBindingUsersField: opt.User},
+		ctx.Kit.SupplierAccount)
+	bindings := make([]metadata.AuthRoleBinding, 0)
+	err := mongodb.Client().Table(common.BKTableNameAuthRoleBinding).Find(bindingCond).
+		Fields(metadata.AuthRoleIDField).All(ctx.Kit.Ctx, &bindings)
+	if err != nil {
+		blog.Errorf("get user auth role bindings failed, cond: %+v, err: %v, rid: %s", bindingCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	roleIDs := make([]int64, 0)
+	for _, binding := range bindings {
+		roleIDs = append(roleIDs, binding.RoleID)
+	}
+
+	policies := make([]metadata.AuthPolicy, 0)
+	if len(roleIDs) == 0 {
+		ctx.RespEntity(policies)
+		return
+	}
+
+	roleCond := util.SetQueryOwner(mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: roleIDs}},
+		ctx.Kit.SupplierAccount)
+	roles := make([]metadata.AuthRole, 0)
+	err = mongodb.Client().Table(common.BKTableNameAuthRole).Find(roleCond).Fields("policies").
+		All(ctx.Kit.Ctx, &roles)
+	if err != nil {
+		blog.Errorf("get auth roles failed, cond: %+v, err: %v, rid: %s", roleCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	for _, role := range roles {
+		policies = append(policies, role.Policies...)
+	}
+
+	ctx.RespEntity(policies)
+}
+
+// checkRoleNameUnique check if the role name is used by another role
+func (s *service) checkRoleNameUnique(kit *rest.Kit, id int64, name string) error {
+	cond := mapstr.MapStr{common.BKFieldName: name}
+	if id != 0 {
+		cond[common.BKFieldID] = mapstr.MapStr{common.BKDBNE: id}
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	count, err := mongodb.Client().Table(common.BKTableNameAuthRole).Find(cond).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count auth role failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if count > 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,362 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package dynamicgroupexport
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// CreateExportSchedule create dynamic group export schedule
+func (s *service) CreateExportSchedule(ctx *rest.Contexts) {
+	schedule := new(metadata.DynamicGroupExportSchedule)
+	if err := ctx.DecodeInto(schedule); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameDynamicGroupExportSchedule)
+	if err != nil {
+		blog.Errorf("generate dynamic group export schedule id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	now := time.Now()
+	schedule.ID = int64(id)
+	schedule.OwnerID = ctx.Kit.SupplierAccount
+	schedule.Creator = ctx.Kit.User
+	schedule.Modifier = ctx.Kit.User
+	schedule.CreateTime = now
+	schedule.LastTime = now
+	schedule.LastRunTime = nil
+
+	err = mongodb.Client().Table(common.BKTableNameDynamicGroupExportSchedule).Insert(ctx.Kit.Ctx, schedule)
+	if err != nil {
+		blog.Errorf("create dynamic group export schedule %+v failed, err: %v, rid: %s", schedule, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: schedule.ID})
+}
+
+// UpdateExportSchedule update dynamic group export schedules
+func (s *service) UpdateExportSchedule(ctx *rest.Contexts) {
+	opt := new(metadata.UpdateOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(opt.Condition) == 0 || len(opt.Data) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "condition or data"))
+		return
+	}
+
+	// last run time is transferred as unix timestamp, convert it into time type before saving it
+	if lastRunTime, exists := opt.Data[metadata.DynamicGroupExportLastRunTimeField]; exists {
+		runTime, err := util.ConvToTime(lastRunTime)
+		if err != nil {
+			blog.Errorf("last run time %v is invalid, err: %v, rid: %s", lastRunTime, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid,
+				metadata.DynamicGroupExportLastRunTimeField))
+			return
+		}
+		opt.Data[metadata.DynamicGroupExportLastRunTimeField] = runTime
+	}
+
+	cond := util.SetModOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	opt.Data[common.LastTimeField] = time.Now()
+
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportSchedule).Update(ctx.Kit.Ctx, cond, opt.Data)
+	if err != nil {
+		blog.Errorf("update dynamic group export schedule failed, cond: %+v, err: %v, rid: %s", cond, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteExportSchedule delete dynamic group export schedules, the exported files are kept until they expire
+func (s *service) DeleteExportSchedule(ctx *rest.Contexts) {
+	opt := new(metadata.DeleteOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(opt.Condition) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "condition"))
+		return
+	}
+
+	cond := util.SetModOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportSchedule).Delete(ctx.Kit.Ctx, cond)
+	if err != nil {
+		blog.Errorf("delete dynamic group export schedule failed, cond: %+v, err: %v, rid: %s", cond, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListExportSchedule list dynamic group export schedules
+func (s *service) ListExportSchedule(ctx *rest.Contexts) {
+	opt := new(metadata.QueryCondition)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetQueryOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	table := mongodb.Client().Table(common.BKTableNameDynamicGroupExportSchedule)
+
+	result := metadata.DynamicGroupExportScheduleList{Info: make([]metadata.DynamicGroupExportSchedule, 0)}
+	if !opt.DisableCounter {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count dynamic group export schedule failed, cond: %+v, err: %v, rid: %s", cond, err,
+				ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		result.Count = int64(count)
+	}
+
+	err := table.Find(cond).Fields(opt.Fields...).Sort(opt.Page.Sort).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).All(ctx.Kit.Ctx, &result.Info)
+	if err != nil {
+		blog.Errorf("list dynamic group export schedule failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// CreateExportFile create dynamic group export file record, its content is uploaded in chunks later
+func (s *service) CreateExportFile(ctx *rest.Contexts) {
+	file := new(metadata.DynamicGroupExportFile)
+	if err := ctx.DecodeInto(file); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameDynamicGroupExportFile)
+	if err != nil {
+		blog.Errorf("generate dynamic group export file id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	now := time.Now()
+	file.ID = int64(id)
+	file.OwnerID = ctx.Kit.SupplierAccount
+	file.Creator = ctx.Kit.User
+	file.CreateTime = now
+	file.LastTime = now
+
+	if err = mongodb.Client().Table(common.BKTableNameDynamicGroupExportFile).Insert(ctx.Kit.Ctx, file); err != nil {
+		blog.Errorf("create dynamic group export file %+v failed, err: %v, rid: %s", file, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: file.ID})
+}
+
+// UpdateExportFile update dynamic group export file records
+func (s *service) UpdateExportFile(ctx *rest.Contexts) {
+	opt := new(metadata.UpdateOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(opt.Condition) == 0 || len(opt.Data) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "condition or data"))
+		return
+	}
+
+	cond := util.SetModOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	opt.Data[common.LastTimeField] = time.Now()
+
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportFile).Update(ctx.Kit.Ctx, cond, opt.Data)
+	if err != nil {
+		blog.Errorf("update dynamic group export file failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteExportFile delete dynamic group export files with their content chunks
+func (s *service) DeleteExportFile(ctx *rest.Contexts) {
+	opt := new(metadata.DeleteOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(opt.Condition) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "condition"))
+		return
+	}
+
+	// expired files are deleted by create time condition, convert it into time type
+	util.ConvParamsTime(map[string]interface{}(opt.Condition))
+
+	cond := util.SetModOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	files := make([]metadata.DynamicGroupExportFile, 0)
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportFile).Find(cond).Fields(common.BKFieldID).
+		All(ctx.Kit.Ctx, &files)
+	if err != nil {
+		blog.Errorf("find dynamic group export file failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if len(files) == 0 {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	fileIDs := make([]int64, len(files))
+	for idx, file := range files {
+		fileIDs[idx] = file.ID
+	}
+
+	chunkCond := util.SetModOwner(mapstr.MapStr{"file_id": mapstr.MapStr{common.BKDBIN: fileIDs}},
+		ctx.Kit.SupplierAccount)
+	if err = mongodb.Client().Table(common.BKTableNameDynamicGroupExportChunk).Delete(ctx.Kit.Ctx,
+		chunkCond); err != nil {
+		blog.Errorf("delete dynamic group export chunks failed, file ids: %v, err: %v, rid: %s", fileIDs, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	fileCond := util.SetModOwner(mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: fileIDs}},
+		ctx.Kit.SupplierAccount)
+	if err = mongodb.Client().Table(common.BKTableNameDynamicGroupExportFile).Delete(ctx.Kit.Ctx,
+		fileCond); err != nil {
+		blog.Errorf("delete dynamic group export files failed, ids: %v, err: %v, rid: %s", fileIDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListExportFile list dynamic group export file records
+func (s *service) ListExportFile(ctx *rest.Contexts) {
+	opt := new(metadata.QueryCondition)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetQueryOwner(opt.Condition, ctx.Kit.SupplierAccount)
+	table := mongodb.Client().Table(common.BKTableNameDynamicGroupExportFile)
+
+	result := metadata.DynamicGroupExportFileList{Info: make([]metadata.DynamicGroupExportFile, 0)}
+	if !opt.DisableCounter {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count dynamic group export file failed, cond: %+v, err: %v, rid: %s", cond, err,
+				ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		result.Count = int64(count)
+	}
+
+	err := table.Find(cond).Fields(opt.Fields...).Sort(opt.Page.Sort).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).All(ctx.Kit.Ctx, &result.Info)
+	if err != nil {
+		blog.Errorf("list dynamic group export file failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// CreateExportChunk create or replace a content chunk of the dynamic group export file
+func (s *service) CreateExportChunk(ctx *rest.Contexts) {
+	chunk := new(metadata.DynamicGroupExportFileChunk)
+	if err := ctx.DecodeInto(chunk); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(chunk.Data) > metadata.DynamicGroupExportChunkSize {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommXXExceedLimit, "data",
+			metadata.DynamicGroupExportChunkSize))
+		return
+	}
+
+	// upsert the chunk so that the retried export task can overwrite the chunks uploaded by the failed one
+	chunk.OwnerID = ctx.Kit.SupplierAccount
+	cond := mapstr.MapStr{"file_id": chunk.FileID, "index": chunk.Index}
+	if err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportChunk).Upsert(ctx.Kit.Ctx, cond,
+		chunk); err != nil {
+		blog.Errorf("create dynamic group export file %d chunk %d failed, err: %v, rid: %s", chunk.FileID,
+			chunk.Index, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// FindExportChunk find a content chunk of the dynamic group export file
+func (s *service) FindExportChunk(ctx *rest.Contexts) {
+	opt := new(metadata.FindDynamicGroupExportChunkOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetQueryOwner(mapstr.MapStr{"file_id": opt.FileID, "index": opt.Index}, ctx.Kit.SupplierAccount)
+	chunk := new(metadata.DynamicGroupExportFileChunk)
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroupExportChunk).Find(cond).One(ctx.Kit.Ctx, chunk)
+	if err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+			return
+		}
+		blog.Errorf("find dynamic group export chunk failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(chunk)
+}
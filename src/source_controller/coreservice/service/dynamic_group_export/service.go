@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package dynamicgroupexport defines the dynamic group export schedule and file service
+package dynamicgroupexport
+
+import (
+	"net/http"
+
+	"configcenter/src/common/http/rest"
+	"configcenter/src/source_controller/coreservice/service/capability"
+)
+
+type service struct{}
+
+// InitDynamicGroupExport init dynamic group export service
+func InitDynamicGroupExport(c *capability.Capability) {
+	s := &service{}
+
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/dynamicgroup/export_schedule",
+		Handler: s.CreateExportSchedule})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/dynamicgroup/export_schedule",
+		Handler: s.UpdateExportSchedule})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/dynamicgroup/export_schedule",
+		Handler: s.DeleteExportSchedule})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/dynamicgroup/export_schedule",
+		Handler: s.ListExportSchedule})
+
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/dynamicgroup/export_file",
+		Handler: s.CreateExportFile})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/dynamicgroup/export_file",
+		Handler: s.UpdateExportFile})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/dynamicgroup/export_file",
+		Handler: s.DeleteExportFile})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/dynamicgroup/export_file",
+		Handler: s.ListExportFile})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/dynamicgroup/export_file/chunk",
+		Handler: s.CreateExportChunk})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/dynamicgroup/export_file/chunk",
+		Handler: s.FindExportChunk})
+}
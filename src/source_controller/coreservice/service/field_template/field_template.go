@@ -193,12 +193,24 @@ func (s *service) FieldTemplateBindObject(ctx *rest.Contexts) {
 		return
 	}
 
+	// newly bound objects are pinned to the latest version of the field template
+	tmplCond := util.SetQueryOwner(mapstr.MapStr{common.BKFieldID: opt.ID}, kit.SupplierAccount)
+	template := new(metadata.FieldTemplate)
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplate).Find(tmplCond).Fields(common.BKVersion).
+		One(kit.Ctx, template)
+	if err != nil {
+		blog.Errorf("find field template failed, cond: %v, err: %v, rid: %s", tmplCond, err, kit.Rid)
+		ctx.RespAutoError(kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
 	rows := make([]metadata.ObjFieldTemplateRelation, 0)
 	for _, id := range ids {
 		rows = append(rows, metadata.ObjFieldTemplateRelation{
 			ObjectID:   id,
 			TemplateID: opt.ID,
 			OwnerID:    kit.SupplierAccount,
+			Version:    template.Version,
 		})
 	}
 
@@ -498,6 +510,8 @@ func (s *service) CreateFieldTemplate(ctx *rest.Contexts) {
 	}
 
 	template.ID = int64(id)
+	// version is generated when the field template version snapshot is created
+	template.Version = 0
 	template.OwnerID = ctx.Kit.SupplierAccount
 	template.Creator = ctx.Kit.User
 	template.Modifier = ctx.Kit.User
@@ -589,6 +603,13 @@ func (s *service) DeleteFieldTemplate(ctx *rest.Contexts) {
 		return
 	}
 
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplateVersion).Delete(ctx.Kit.Ctx, countCond)
+	if err != nil {
+		blog.Errorf("delete field template versions failed, cond: %v, err: %v, rid: %s", countCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(nil)
 }
 
@@ -628,6 +649,8 @@ func (s *service) UpdateFieldTemplate(ctx *rest.Contexts) {
 	opt.OwnerID = dbTmpl.OwnerID
 	opt.Creator = dbTmpl.Creator
 	opt.CreateTime = dbTmpl.CreateTime
+	// version can only be changed by creating a new field template version
+	opt.Version = dbTmpl.Version
 	opt.Modifier = ctx.Kit.User
 	now := time.Now()
 	opt.LastTime = &metadata.Time{Time: now}
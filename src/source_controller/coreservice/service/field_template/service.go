@@ -71,6 +71,14 @@ func InitFieldTemplate(c *capability.Capability) {
 	// field template relation
 	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/field_template/object/relation",
 		Handler: s.ListObjFieldTmplRel})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/field_template/object/version",
+		Handler: s.UpdateObjFieldTmplRelVersion})
+
+	// field template version
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/field_template/{bk_template_id}/version",
+		Handler: s.CreateFieldTemplateVersion})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/field_template/version",
+		Handler: s.ListFieldTemplateVersion})
 
 	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/field_template/simplify/by_unique_template_id",
 		Handler: s.FindFieldTmplSimplifyByUnique})
@@ -0,0 +1,240 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package fieldtmpl
+
+import (
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// CreateFieldTemplateVersion take a snapshot of the current field template attributes and uniques as a new version,
+// and set the field template's latest version to it.
+func (s *service) CreateFieldTemplateVersion(ctx *rest.Contexts) {
+	templateID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKTemplateID), 10, 64)
+	if err != nil {
+		blog.Errorf("failed to parse %s, err: %v, rid: %s", common.BKTemplateID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.BKTemplateID))
+		return
+	}
+	if templateID == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKTemplateID))
+		return
+	}
+
+	tmplCond := util.SetQueryOwner(mapstr.MapStr{common.BKFieldID: templateID}, ctx.Kit.SupplierAccount)
+	templates := make([]metadata.FieldTemplate, 0)
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplate).Find(tmplCond).
+		Fields(common.BKFieldID, common.BKVersion).All(ctx.Kit.Ctx, &templates)
+	if err != nil {
+		blog.Errorf("find field template failed, cond: %v, err: %v, rid: %s", tmplCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if len(templates) != 1 {
+		blog.Errorf("field template count is invalid, cond: %v, count: %d, rid: %s", tmplCond, len(templates),
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommNotFound, "field_template"))
+		return
+	}
+
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKTemplateID: templateID}, ctx.Kit.SupplierAccount)
+	attrs := make([]metadata.FieldTemplateAttr, 0)
+	err = mongodb.Client().Table(common.BKTableNameObjAttDesTemplate).Find(cond).Sort(common.BKPropertyIndexField).
+		All(ctx.Kit.Ctx, &attrs)
+	if err != nil {
+		blog.Errorf("list field template attrs failed, cond: %v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	// there must be at least one attribute on the field template
+	if len(attrs) == 0 {
+		blog.Errorf("no attribute founded, template id: %d, rid: %s", templateID, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKTemplateID))
+		return
+	}
+
+	uniques := make([]metadata.FieldTemplateUnique, 0)
+	err = mongodb.Client().Table(common.BKTableNameObjectUniqueTemplate).Find(cond).Sort(common.BKFieldID).
+		All(ctx.Kit.Ctx, &uniques)
+	if err != nil {
+		blog.Errorf("list field template uniques failed, cond: %v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameFieldTemplateVersion)
+	if err != nil {
+		blog.Errorf("get sequence id on the table (%s) failed, err: %v, rid: %s",
+			common.BKTableNameFieldTemplateVersion, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.New(common.CCErrObjectDBOpErrno, err.Error()))
+		return
+	}
+
+	version := &metadata.FieldTemplateVersion{
+		ID:         int64(id),
+		TemplateID: templateID,
+		Version:    templates[0].Version + 1,
+		Attributes: attrs,
+		Uniques:    uniques,
+		OwnerID:    ctx.Kit.SupplierAccount,
+		Creator:    ctx.Kit.User,
+		CreateTime: &metadata.Time{Time: time.Now()},
+	}
+
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplateVersion).Insert(ctx.Kit.Ctx, version)
+	if err != nil {
+		blog.Errorf("save field template version failed, data: %v, err: %v, rid: %s", version, err, ctx.Kit.Rid)
+		if mongodb.Client().IsDuplicatedError(err) {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, mongodb.GetDuplicateKey(err)))
+			return
+		}
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	updateData := mapstr.MapStr{common.BKVersion: version.Version}
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplate).Update(ctx.Kit.Ctx, tmplCond, updateData)
+	if err != nil {
+		blog.Errorf("update field template version failed, cond: %v, err: %v, rid: %s", tmplCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(version)
+}
+
+// ListFieldTemplateVersion list field template versions.
+func (s *service) ListFieldTemplateVersion(ctx *rest.Contexts) {
+	opt := new(metadata.CommonQueryOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	filter, err := opt.ToMgo()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	filter = util.SetQueryOwner(filter, ctx.Kit.SupplierAccount)
+
+	if opt.Page.EnableCount {
+		count, err := mongodb.Client().Table(common.BKTableNameFieldTemplateVersion).Find(filter).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count field template versions failed, err: %v, filter: %+v, rid: %v", err, filter,
+				ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+
+		ctx.RespEntity(metadata.FieldTemplateVersionInfo{Count: count})
+		return
+	}
+
+	versions := make([]metadata.FieldTemplateVersion, 0)
+	err = mongodb.Client().Table(common.BKTableNameFieldTemplateVersion).Find(filter).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).Sort(opt.Page.Sort).Fields(opt.Fields...).All(ctx.Kit.Ctx, &versions)
+	if err != nil {
+		blog.Errorf("list field template versions failed, err: %v, filter: %+v, rid: %v", err, filter, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.FieldTemplateVersionInfo{Info: versions})
+}
+
+// UpdateObjFieldTmplRelVersion pin the objects bound to the field template to the specified version.
+func (s *service) UpdateObjFieldTmplRelVersion(ctx *rest.Contexts) {
+	opt := new(metadata.UpgradeObjFieldTmplVersionOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	versionCond := mapstr.MapStr{
+		common.BKTemplateID: opt.TemplateID,
+		common.BKVersion:    opt.Version,
+	}
+	versionCond = util.SetQueryOwner(versionCond, ctx.Kit.SupplierAccount)
+
+	cnt, err := mongodb.Client().Table(common.BKTableNameFieldTemplateVersion).Find(versionCond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count field template version failed, cond: %v, err: %v, rid: %s", versionCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if cnt != 1 {
+		blog.Errorf("field template version is invalid, cond: %v, count: %d, rid: %s", versionCond, cnt, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKVersion))
+		return
+	}
+
+	objIDs := util.IntArrayUnique(opt.ObjectIDs)
+	relCond := mapstr.MapStr{
+		common.BKTemplateID:  opt.TemplateID,
+		common.ObjectIDField: mapstr.MapStr{common.BKDBIN: objIDs},
+	}
+	relCond = util.SetQueryOwner(relCond, ctx.Kit.SupplierAccount)
+
+	relCnt, err := mongodb.Client().Table(common.BKTableNameObjFieldTemplateRelation).Find(relCond).
+		Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count field template relation failed, cond: %v, err: %v, rid: %s", relCond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if int(relCnt) != len(objIDs) {
+		blog.Errorf("some objects are not bound to field template, cond: %v, count: %d, rid: %s", relCond, relCnt,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "object_ids"))
+		return
+	}
+
+	updateData := mapstr.MapStr{common.BKVersion: opt.Version}
+	err = mongodb.Client().Table(common.BKTableNameObjFieldTemplateRelation).Update(ctx.Kit.Ctx, relCond, updateData)
+	if err != nil {
+		blog.Errorf("update field template relation version failed, cond: %v, err: %v, rid: %s", relCond, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
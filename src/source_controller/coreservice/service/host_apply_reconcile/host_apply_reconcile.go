@@ -0,0 +1,173 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package hostapplyreconcile
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// UpdateReconcileSetting create or update the host apply reconcile setting of a business
+func (s *service) UpdateReconcileSetting(ctx *rest.Contexts) {
+	setting := new(metadata.HostApplyReconcileSetting)
+	if err := ctx.DecodeInto(setting); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if setting.BizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKAppIDField))
+		return
+	}
+
+	setting.Modifier = ctx.Kit.User
+	setting.LastTime = time.Now()
+	setting.OwnerID = ctx.Kit.SupplierAccount
+	cond := util.SetModOwner(mapstr.MapStr{common.BKAppIDField: setting.BizID}, ctx.Kit.SupplierAccount)
+	if err := mongodb.Client().Table(common.BKTableNameHostApplyReconcileSetting).Upsert(ctx.Kit.Ctx, cond,
+		setting); err != nil {
+		blog.Errorf("update biz %d host apply reconcile setting failed, err: %v, rid: %s", setting.BizID, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListReconcileSetting list the host apply reconcile settings
+func (s *service) ListReconcileSetting(ctx *rest.Contexts) {
+	opt := new(metadata.ListHostApplyReconcileSettingOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := mapstr.MapStr{}
+	if len(opt.BizIDs) > 0 {
+		cond[common.BKAppIDField] = mapstr.MapStr{common.BKDBIN: opt.BizIDs}
+	}
+	if opt.OnlyEnabled {
+		cond[metadata.HostApplyReconcileEnabledField] = true
+	}
+	cond = util.SetQueryOwner(cond, ctx.Kit.SupplierAccount)
+
+	settings := make([]metadata.HostApplyReconcileSetting, 0)
+	if err := mongodb.Client().Table(common.BKTableNameHostApplyReconcileSetting).Find(cond).
+		Sort(common.BKAppIDField).All(ctx.Kit.Ctx, &settings); err != nil {
+		blog.Errorf("list host apply reconcile settings failed, cond: %+v, err: %v, rid: %s", cond, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(settings)
+}
+
+// ReplaceConflict replace all the host apply conflicts of a business with the ones detected by the latest run
+func (s *service) ReplaceConflict(ctx *rest.Contexts) {
+	opt := new(metadata.ReplaceHostApplyConflictOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	table := mongodb.Client().Table(common.BKTableNameHostApplyConflict)
+	cond := util.SetModOwner(mapstr.MapStr{common.BKAppIDField: opt.BizID}, ctx.Kit.SupplierAccount)
+	if err := table.Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete biz %d host apply conflicts failed, err: %v, rid: %s", opt.BizID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	if len(opt.Conflicts) == 0 {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	for idx := range opt.Conflicts {
+		opt.Conflicts[idx].OwnerID = ctx.Kit.SupplierAccount
+	}
+
+	if err := table.Insert(ctx.Kit.Ctx, opt.Conflicts); err != nil {
+		blog.Errorf("insert biz %d host apply conflicts failed, err: %v, rid: %s", opt.BizID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListConflict list the host apply conflicts of a business
+func (s *service) ListConflict(ctx *rest.Contexts) {
+	opt := new(metadata.ListHostApplyConflictOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := mapstr.MapStr{common.BKAppIDField: opt.BizID}
+	if len(opt.HostIDs) > 0 {
+		cond[common.BKHostIDField] = mapstr.MapStr{common.BKDBIN: opt.HostIDs}
+	}
+	cond = util.SetQueryOwner(cond, ctx.Kit.SupplierAccount)
+
+	table := mongodb.Client().Table(common.BKTableNameHostApplyConflict)
+	if opt.Page.EnableCount {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count host apply conflicts failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntityWithCount(int64(count), make([]metadata.HostApplyConflict, 0))
+		return
+	}
+
+	sort := opt.Page.Sort
+	if sort == "" {
+		sort = common.BKHostIDField
+	}
+
+	conflicts := make([]metadata.HostApplyConflict, 0)
+	err := table.Find(cond).Sort(sort).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).
+		All(ctx.Kit.Ctx, &conflicts)
+	if err != nil {
+		blog.Errorf("list host apply conflicts failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntityWithCount(0, conflicts)
+}
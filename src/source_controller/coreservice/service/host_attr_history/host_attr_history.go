@@ -0,0 +1,185 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+package hostattrhistory
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// FindHostAttrHistory find the collected host attribute history periods
+func (s *service) FindHostAttrHistory(ctx *rest.Contexts) {
+	opt := new(metadata.FindHostAttrHistoryOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := genHostAttrCond(opt.HostIDs, opt.Fields, ctx.Kit.SupplierAccount)
+	if opt.StartTime > 0 {
+		cond[metadata.HostAttrLastSeenField] = mapstr.MapStr{common.BKDBGTE: time.Unix(opt.StartTime, 0)}
+	}
+	if opt.EndTime > 0 {
+		cond[metadata.HostAttrFirstSeenField] = mapstr.MapStr{common.BKDBLTE: time.Unix(opt.EndTime, 0)}
+	}
+
+	table := mongodb.Client().Table(common.BKTableNameHostAttrHistory)
+	if opt.Page.EnableCount {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count host attribute history failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntityWithCount(int64(count), make([]metadata.HostAttrHistory, 0))
+		return
+	}
+
+	sort := opt.Page.Sort
+	if sort == "" {
+		sort = common.BKHostIDField + "," + metadata.HostAttrFieldField + "," + metadata.HostAttrFirstSeenField
+	}
+
+	histories := make([]metadata.HostAttrHistory, 0)
+	err := table.Find(cond).Sort(sort).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).
+		All(ctx.Kit.Ctx, &histories)
+	if err != nil {
+		blog.Errorf("find host attribute history failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntityWithCount(0, histories)
+}
+
+// FindHostAttrValue find the first seen and last seen time of each collected value of the host attributes
+func (s *service) FindHostAttrValue(ctx *rest.Contexts) {
+	opt := new(metadata.FindHostAttrValueOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := genHostAttrCond(opt.HostIDs, opt.Fields, ctx.Kit.SupplierAccount)
+	pipeline := []mapstr.MapStr{
+		{common.BKDBMatch: cond},
+		{common.BKDBGroup: mapstr.MapStr{
+			"_id": mapstr.MapStr{
+				common.BKHostIDField:        "$" + common.BKHostIDField,
+				metadata.HostAttrFieldField: "$" + metadata.HostAttrFieldField,
+				metadata.HostAttrValueField: "$" + metadata.HostAttrValueField,
+			},
+			metadata.HostAttrFirstSeenField: mapstr.MapStr{"$min": "$" + metadata.HostAttrFirstSeenField},
+			metadata.HostAttrLastSeenField:  mapstr.MapStr{"$max": "$" + metadata.HostAttrLastSeenField},
+			"periods":                       mapstr.MapStr{common.BKDBSum: 1},
+		}},
+		{common.BKDBProject: mapstr.MapStr{
+			"_id":                           0,
+			common.BKHostIDField:            "$_id." + common.BKHostIDField,
+			metadata.HostAttrFieldField:     "$_id." + metadata.HostAttrFieldField,
+			metadata.HostAttrValueField:     "$_id." + metadata.HostAttrValueField,
+			metadata.HostAttrFirstSeenField: 1,
+			metadata.HostAttrLastSeenField:  1,
+			"periods":                       1,
+		}},
+		{common.BKDBSort: mapstr.MapStr{
+			common.BKHostIDField:            1,
+			metadata.HostAttrFieldField:     1,
+			metadata.HostAttrFirstSeenField: 1,
+		}},
+	}
+
+	values := make([]metadata.HostAttrValue, 0)
+	err := mongodb.Client().Table(common.BKTableNameHostAttrHistory).AggregateAll(ctx.Kit.Ctx, pipeline, &values)
+	if err != nil {
+		blog.Errorf("aggregate host attribute values failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(values)
+}
+
+// FindHostAttrDrift find the host attributes whose collected value disagrees with the maintained value
+func (s *service) FindHostAttrDrift(ctx *rest.Contexts) {
+	opt := new(metadata.FindHostAttrDriftOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := genHostAttrCond(opt.HostIDs, opt.Fields, ctx.Kit.SupplierAccount)
+	table := mongodb.Client().Table(common.BKTableNameHostAttrDrift)
+	if opt.Page.EnableCount {
+		count, err := table.Find(cond).Count(ctx.Kit.Ctx)
+		if err != nil {
+			blog.Errorf("count host attribute drift failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		ctx.RespEntityWithCount(int64(count), make([]metadata.HostAttrDrift, 0))
+		return
+	}
+
+	sort := opt.Page.Sort
+	if sort == "" {
+		sort = common.BKHostIDField + "," + metadata.HostAttrFieldField
+	}
+
+	drifts := make([]metadata.HostAttrDrift, 0)
+	err := table.Find(cond).Sort(sort).Start(uint64(opt.Page.Start)).Limit(uint64(opt.Page.Limit)).
+		All(ctx.Kit.Ctx, &drifts)
+	if err != nil {
+		blog.Errorf("find host attribute drift failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntityWithCount(0, drifts)
+}
+
+func genHostAttrCond(hostIDs []int64, fields []string, ownerID string) mapstr.MapStr {
+	cond := mapstr.MapStr{}
+	if len(hostIDs) > 0 {
+		cond[common.BKHostIDField] = mapstr.MapStr{common.BKDBIN: hostIDs}
+	}
+	if len(fields) > 0 {
+		cond[metadata.HostAttrFieldField] = mapstr.MapStr{common.BKDBIN: fields}
+	}
+	return util.SetQueryOwner(cond, ownerID)
+}
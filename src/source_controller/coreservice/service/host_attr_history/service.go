@@ -0,0 +1,38 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+// Package hostattrhistory defines the collected host attribute history and drift service
+package hostattrhistory
+
+import (
+	"net/http"
+
+	"configcenter/src/common/http/rest"
+	"configcenter/src/source_controller/coreservice/service/capability"
+)
+
+type service struct{}
+
+// InitHostAttrHistory init host attribute history service
+func InitHostAttrHistory(c *capability.Capability) {
+	s := &service{}
+
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host/attr_history",
+		Handler: s.FindHostAttrHistory})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host/attr_history/value",
+		Handler: s.FindHostAttrValue})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host/attr_drift",
+		Handler: s.FindHostAttrDrift})
+}
@@ -140,6 +140,28 @@ func (s *coreService) CascadeDeleteModelInstances(ctx *rest.Contexts) {
 	ctx.RespEntityWithError(s.core.InstanceOperation().CascadeDeleteModelInstance(ctx.Kit, ctx.Request.PathParameter("bk_obj_id"), inputData))
 }
 
+// DecryptInstAttr decrypt the encrypted attribute value of an instance
+func (s *coreService) DecryptInstAttr(ctx *rest.Contexts) {
+	opt := new(metadata.DecryptInstAttrOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.core.InstanceOperation().DecryptInstAttr(ctx.Kit, ctx.Request.PathParameter("bk_obj_id"), opt)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 // GetInstanceObjectMapping TODO
 func (s *coreService) GetInstanceObjectMapping(ctx *rest.Contexts) {
 	inputData := metadata.GetInstanceObjectMappingsOption{}
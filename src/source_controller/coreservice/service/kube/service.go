@@ -69,6 +69,16 @@ func InitKube(c *capability.Capability) {
 		Handler: s.DeleteWorkload})
 	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/workload/{kind}", Handler: s.ListWorkload})
 
+	// namespace resource, including service, ingress, configMap and persistentVolumeClaim
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/ns_resource/{kind}",
+		Handler: s.CreateNsResource})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/ns_resource/{kind}",
+		Handler: s.UpdateNsResource})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/ns_resource/{kind}",
+		Handler: s.DeleteNsResource})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/ns_resource/{kind}",
+		Handler: s.ListNsResource})
+
 	// pod
 	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/kube/pod", Handler: s.BatchCreatePod})
 	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/pod", Handler: s.ListPod})
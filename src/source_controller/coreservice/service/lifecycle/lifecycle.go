@@ -0,0 +1,195 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package lifecycle
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// SaveLifecycle create or update the lifecycle of the object
+func (s *service) SaveLifecycle(ctx *rest.Contexts) {
+	opt := new(metadata.SaveLifecycleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.validateLifecycleFields(ctx.Kit, opt.ObjID, &opt.LifecycleSpec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetModOwner(mapstr.MapStr{common.BKObjIDField: opt.ObjID}, ctx.Kit.SupplierAccount)
+	count, err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Find(cond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count object lifecycle failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	now := metadata.Now()
+	if count > 0 {
+		data := mapstr.MapStr{
+			common.BKPropertyIDField: opt.PropertyID,
+			"initial_state":          opt.InitialState,
+			"states":                 opt.States,
+			"transitions":            opt.Transitions,
+			common.ModifierField:     ctx.Kit.User,
+			common.LastTimeField:     now,
+		}
+
+		err = mongodb.Client().Table(common.BKTableNameObjLifecycle).Update(ctx.Kit.Ctx, cond, data)
+		if err != nil {
+			blog.Errorf("update object lifecycle failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+			return
+		}
+
+		ctx.RespEntity(nil)
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameObjLifecycle)
+	if err != nil {
+		blog.Errorf("generate object lifecycle id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	lifecycle := &metadata.ObjectLifecycle{
+		ID:            int64(id),
+		ObjID:         opt.ObjID,
+		LifecycleSpec: opt.LifecycleSpec,
+		OwnerID:       ctx.Kit.SupplierAccount,
+		Creator:       ctx.Kit.User,
+		Modifier:      ctx.Kit.User,
+		CreateTime:    now,
+		LastTime:      now,
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameObjLifecycle).Insert(ctx.Kit.Ctx, lifecycle); err != nil {
+		blog.Errorf("create object lifecycle %+v failed, err: %v, rid: %s", lifecycle, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteLifecycle delete the lifecycle of the object, the state field of the instances is kept as it is
+func (s *service) DeleteLifecycle(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	cond := util.SetModOwner(mapstr.MapStr{common.BKObjIDField: objID}, ctx.Kit.SupplierAccount)
+
+	if err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete object lifecycle failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// FindLifecycle find the lifecycle of the object, returns nil if the object has no lifecycle
+func (s *service) FindLifecycle(ctx *rest.Contexts) {
+	opt := new(metadata.FindLifecycleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: opt.ObjID}, ctx.Kit.SupplierAccount)
+	lifecycles := make([]metadata.ObjectLifecycle, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjLifecycle).Find(cond).All(ctx.Kit.Ctx, &lifecycles)
+	if err != nil {
+		blog.Errorf("find object lifecycle failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	if len(lifecycles) == 0 {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	ctx.RespEntity(lifecycles[0])
+}
+
+// validateLifecycleFields validate that the state field is a single choice enum attribute of the object whose
+// options contain all the states, and all the required fields are the object's attributes
+func (s *service) validateLifecycleFields(kit *rest.Kit, objID string, lifecycle *metadata.LifecycleSpec) error {
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: objID}, kit.SupplierAccount)
+	attrs := make([]metadata.Attribute, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjAttDes).Find(cond).All(kit.Ctx, &attrs)
+	if err != nil {
+		blog.Errorf("find object attributes failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	attrMap := make(map[string]metadata.Attribute)
+	for _, attr := range attrs {
+		attrMap[attr.PropertyID] = attr
+	}
+
+	for _, field := range lifecycle.Fields() {
+		if _, exists := attrMap[field]; !exists {
+			blog.Errorf("lifecycle field %s is not an attribute of object %s, rid: %s", field, objID, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, field)
+		}
+	}
+
+	stateAttr := attrMap[lifecycle.PropertyID]
+	if stateAttr.PropertyType != common.FieldTypeEnum || (stateAttr.IsMultiple != nil && *stateAttr.IsMultiple) {
+		blog.Errorf("lifecycle field %s is not a single choice enum, rid: %s", lifecycle.PropertyID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKPropertyIDField)
+	}
+
+	options, err := metadata.ParseEnumOption(stateAttr.Option)
+	if err != nil {
+		blog.Errorf("parse enum option %+v failed, err: %v, rid: %s", stateAttr.Option, err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKPropertyIDField)
+	}
+
+	optionIDs := make(map[string]struct{})
+	for _, option := range options {
+		optionIDs[option.ID] = struct{}{}
+	}
+
+	for _, state := range lifecycle.States {
+		if _, exists := optionIDs[state.ID]; !exists {
+			blog.Errorf("lifecycle state %s is not an option of field %s, rid: %s", state.ID, lifecycle.PropertyID,
+				kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "states")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package lifecycle defines the object instance lifecycle state machine service
+package lifecycle
+
+import (
+	"net/http"
+
+	"configcenter/src/common/http/rest"
+	"configcenter/src/source_controller/coreservice/service/capability"
+)
+
+type service struct{}
+
+// InitLifecycle init object lifecycle service
+func InitLifecycle(c *capability.Capability) {
+	s := &service{}
+
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/model/lifecycle",
+		Handler: s.SaveLifecycle})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/model/{bk_obj_id}/lifecycle",
+		Handler: s.DeleteLifecycle})
+	c.Utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/model/lifecycle",
+		Handler: s.FindLifecycle})
+}
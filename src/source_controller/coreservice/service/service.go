@@ -18,6 +18,7 @@
 package service
 
 import (
+	"context"
 	"net/http"
 
 	"configcenter/src/common"
@@ -34,6 +35,7 @@ import (
 	"configcenter/src/source_controller/coreservice/core/auth"
 	"configcenter/src/source_controller/coreservice/core/cloud"
 	coreCommon "configcenter/src/source_controller/coreservice/core/common"
+	"configcenter/src/source_controller/coreservice/core/computed"
 	"configcenter/src/source_controller/coreservice/core/datasynchronize"
 	"configcenter/src/source_controller/coreservice/core/host"
 	"configcenter/src/source_controller/coreservice/core/hostapplyrule"
@@ -109,10 +111,10 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 	s.rds = cache */
 
 	// connect the remote mongodb
-	instance := instances.New(s, lang, engine.CoreAPI)
+	instance := instances.New(s, lang, engine.CoreAPI, cfg.AttrCryptor)
 	hostApplyRuleCore := hostapplyrule.New(instance, engine.CoreAPI)
 	s.core = core.New(
-		model.New(s, lang),
+		model.New(s, lang, cfg.AttrCryptor),
 		instance,
 		kube.New(),
 		association.New(s),
@@ -130,6 +132,8 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 		auth.New(mongodb.Client()),
 		coreCommon.New(),
 	)
+
+	computed.NewRecomputer(engine).Run(context.Background())
 	return nil
 }
 
@@ -20,11 +20,17 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	authrole "configcenter/src/source_controller/coreservice/service/auth_role"
 	"configcenter/src/source_controller/coreservice/service/capability"
+	dgexport "configcenter/src/source_controller/coreservice/service/dynamic_group_export"
 	fieldtmpl "configcenter/src/source_controller/coreservice/service/field_template"
+	hostapplyreconcile "configcenter/src/source_controller/coreservice/service/host_apply_reconcile"
+	hostattrhistory "configcenter/src/source_controller/coreservice/service/host_attr_history"
 	"configcenter/src/source_controller/coreservice/service/id_rule"
 	"configcenter/src/source_controller/coreservice/service/kube"
+	"configcenter/src/source_controller/coreservice/service/lifecycle"
 	modelquote "configcenter/src/source_controller/coreservice/service/model_quote"
+	validationrule "configcenter/src/source_controller/coreservice/service/validation_rule"
 
 	"github.com/emicklei/go-restful/v3"
 )
@@ -172,6 +178,8 @@ func (s *coreService) initModelInstances(web *restful.WebService) {
 		Handler: s.CascadeDeleteModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/get/instance/object/mapping",
 		Handler: s.GetInstanceObjectMapping})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/decrypt/model/{bk_obj_id}/instance/attribute",
+		Handler: s.DecryptInstAttr})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -351,6 +359,8 @@ func (s *coreService) audit(web *restful.WebService) {
 		Handler: s.CreateAuditLog})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/read/auditlog",
 		Handler: s.SearchAuditLog})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/read/auditlog/inst_snapshot",
+		Handler: s.SearchInstSnapshot})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -590,6 +600,12 @@ func (s *coreService) initService(web *restful.WebService) {
 	s.initModelQuote(web)
 	fieldtmpl.InitFieldTemplate(c)
 	idrule.InitIDRule(c)
+	validationrule.InitValidationRule(c)
+	lifecycle.InitLifecycle(c)
+	dgexport.InitDynamicGroupExport(c)
+	authrole.InitAuthRole(c)
+	hostattrhistory.InitHostAttrHistory(c)
+	hostapplyreconcile.InitHostApplyReconcile(c)
 
 	c.Utility.AddToRestfulWebService(web)
 }
@@ -0,0 +1,228 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package validationrule
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	attrvalid "configcenter/src/common/valid/attribute"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// CreateValidationRule create object validation rule
+func (s *service) CreateValidationRule(ctx *rest.Contexts) {
+	opt := new(metadata.CreateValidationRuleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.validateRuleFields(ctx.Kit, opt.ObjID, &opt.ValidationRuleSpec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: opt.ObjID}, ctx.Kit.SupplierAccount)
+	count, err := mongodb.Client().Table(common.BKTableNameObjValidationRule).Find(cond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count object validation rules failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+	if count >= metadata.ValidationRuleMaxCount {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommXXExceedLimit, "validation rules",
+			metadata.ValidationRuleMaxCount))
+		return
+	}
+
+	id, err := mongodb.Client().NextSequence(ctx.Kit.Ctx, common.BKTableNameObjValidationRule)
+	if err != nil {
+		blog.Errorf("generate validation rule id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrObjectDBOpErrno))
+		return
+	}
+
+	now := metadata.Now()
+	rule := &metadata.ObjectValidationRule{
+		ID:                 int64(id),
+		ObjID:              opt.ObjID,
+		ValidationRuleSpec: opt.ValidationRuleSpec,
+		OwnerID:            ctx.Kit.SupplierAccount,
+		Creator:            ctx.Kit.User,
+		Modifier:           ctx.Kit.User,
+		CreateTime:         now,
+		LastTime:           now,
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameObjValidationRule).Insert(ctx.Kit.Ctx, rule); err != nil {
+		blog.Errorf("create object validation rule %+v failed, err: %v, rid: %s", rule, err, ctx.Kit.Rid)
+		if mongodb.Client().IsDuplicatedError(err) {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, rule.Name))
+			return
+		}
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(metadata.RspID{ID: rule.ID})
+}
+
+// UpdateValidationRule update object validation rule
+func (s *service) UpdateValidationRule(ctx *rest.Contexts) {
+	opt := new(metadata.UpdateValidationRuleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.validateRuleFields(ctx.Kit, opt.ObjID, &opt.ValidationRuleSpec); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond := mapstr.MapStr{
+		common.BKObjIDField: opt.ObjID,
+		common.BKFieldID:    opt.ID,
+	}
+	cond = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+
+	count, err := mongodb.Client().Table(common.BKTableNameObjValidationRule).Find(cond).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count object validation rule failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+	if count == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	data := mapstr.MapStr{
+		common.BKFieldName:   opt.Name,
+		"condition":          opt.Condition,
+		"required":           opt.Required,
+		"assertion":          opt.Assertion,
+		"comparisons":        opt.Comparisons,
+		"message":            opt.Message,
+		common.ModifierField: ctx.Kit.User,
+		common.LastTimeField: metadata.Now(),
+	}
+
+	if err = mongodb.Client().Table(common.BKTableNameObjValidationRule).Update(ctx.Kit.Ctx, cond, data); err != nil {
+		blog.Errorf("update object validation rule failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		if mongodb.Client().IsDuplicatedError(err) {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, opt.Name))
+			return
+		}
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteValidationRule delete object validation rule
+func (s *service) DeleteValidationRule(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKFieldID))
+		return
+	}
+
+	cond := mapstr.MapStr{
+		common.BKObjIDField: objID,
+		common.BKFieldID:    id,
+	}
+	cond = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+
+	if err = mongodb.Client().Table(common.BKTableNameObjValidationRule).Delete(ctx.Kit.Ctx, cond); err != nil {
+		blog.Errorf("delete object validation rule failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListValidationRule list object validation rules
+func (s *service) ListValidationRule(ctx *rest.Contexts) {
+	opt := new(metadata.ListValidationRuleOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := mapstr.MapStr{common.BKObjIDField: opt.ObjID}
+	if len(opt.IDs) > 0 {
+		cond[common.BKFieldID] = mapstr.MapStr{common.BKDBIN: opt.IDs}
+	}
+	cond = util.SetQueryOwner(cond, ctx.Kit.SupplierAccount)
+
+	rules := make([]metadata.ObjectValidationRule, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjValidationRule).Find(cond).Sort(common.BKFieldID).
+		All(ctx.Kit.Ctx, &rules)
+	if err != nil {
+		blog.Errorf("list object validation rules failed, cond: %+v, err: %v, rid: %s", cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(rules)
+}
+
+// validateRuleFields validate that all the fields of the validation rule are the object's attributes
+func (s *service) validateRuleFields(kit *rest.Kit, objID string, rule *metadata.ValidationRuleSpec) error {
+	cond := util.SetQueryOwner(mapstr.MapStr{common.BKObjIDField: objID}, kit.SupplierAccount)
+	attrs := make([]metadata.Attribute, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjAttDes).Find(cond).
+		Fields(common.BKPropertyIDField, common.BKPropertyTypeField).All(kit.Ctx, &attrs)
+	if err != nil {
+		blog.Errorf("find object attributes failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(attrs) == 0 {
+		blog.Errorf("object %s has no attributes, rid: %s", objID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKObjIDField)
+	}
+
+	attrTypeMap := make(map[string]string)
+	for _, attr := range attrs {
+		attrTypeMap[attr.PropertyID] = attr.PropertyType
+	}
+
+	return attrvalid.ValidValidationRule(kit, rule, attrTypeMap)
+}
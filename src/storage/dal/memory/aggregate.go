@@ -0,0 +1,533 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// parsePipeline converts the aggregate pipeline into ordered stages, the pipeline can be any slice of document like
+// values, such as []map[string]interface{}, []bson.M, []bson.D, mongo.Pipeline etc.
+func parsePipeline(pipeline interface{}) ([]bson.D, error) {
+	if pipeline == nil {
+		return make([]bson.D, 0), nil
+	}
+
+	rv := reflect.ValueOf(pipeline)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("aggregate pipeline must be an array, but got %T", pipeline)
+	}
+
+	stages := make([]bson.D, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		stage, err := toOrderedDocument(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if len(stage) != 1 {
+			return nil, fmt.Errorf("aggregate stage must have exactly one field, stage: %v", stage)
+		}
+		stages[i] = stage
+	}
+	return stages, nil
+}
+
+// aggregate runs the aggregate pipeline on the documents, the documents are not modified
+func aggregate(docs []document, stages []bson.D) ([]document, error) {
+	var err error
+	for _, stage := range stages {
+		name, spec := stage[0].Key, stage[0].Value
+		switch name {
+		case "$match":
+			filter, ok := spec.(document)
+			if !ok {
+				return nil, fmt.Errorf("$match stage must be a document")
+			}
+			docs, err = filterDocuments(docs, filter)
+		case "$project":
+			docs, err = projectStage(docs, spec)
+		case "$addFields", "$set":
+			docs, err = addFieldsStage(docs, spec)
+		case "$unset":
+			docs, err = unsetStage(docs, spec)
+		case "$group":
+			docs, err = groupStage(docs, spec)
+		case "$sort":
+			var sortDoc bson.D
+			if sortDoc, err = toOrderedDocument(spec); err == nil {
+				docs = sortDocuments(docs, sortDoc)
+			}
+		case "$skip":
+			skip, ok := toInt64(spec)
+			if !ok || skip < 0 {
+				return nil, fmt.Errorf("$skip stage must be a non-negative integer")
+			}
+			if skip > int64(len(docs)) {
+				skip = int64(len(docs))
+			}
+			docs = docs[skip:]
+		case "$limit":
+			limit, ok := toInt64(spec)
+			if !ok || limit <= 0 {
+				return nil, fmt.Errorf("$limit stage must be a positive integer")
+			}
+			if limit < int64(len(docs)) {
+				docs = docs[:limit]
+			}
+		case "$unwind":
+			docs, err = unwindStage(docs, spec)
+		case "$count":
+			field, ok := spec.(string)
+			if !ok || field == "" {
+				return nil, fmt.Errorf("$count stage must be a non-empty string")
+			}
+			if len(docs) == 0 {
+				docs = make([]document, 0)
+			} else {
+				docs = []document{{field: int32(len(docs))}}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported aggregate stage %s", name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// filterDocuments returns the documents that matches the filter
+func filterDocuments(docs []document, filter document) ([]document, error) {
+	matched := make([]document, 0)
+	for _, doc := range docs {
+		ok, err := matchDocument(doc, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+	return matched, nil
+}
+
+// sortDocuments sorts the documents stably by the sort document like {"a": 1, "b": -1}
+func sortDocuments(docs []document, sortDoc bson.D) []document {
+	if len(sortDoc) == 0 {
+		return docs
+	}
+
+	sorted := make([]document, len(docs))
+	copy(sorted, docs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, item := range sortDoc {
+			valI, _ := getSortValue(sorted[i], item.Key)
+			valJ, _ := getSortValue(sorted[j], item.Key)
+			cmp := compareValues(valI, valJ)
+			if cmp == 0 {
+				continue
+			}
+			if direction, _ := toInt64(item.Value); direction < 0 {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+	return sorted
+}
+
+// getSortValue returns the value used to sort the document, when the field path resolves to multiple values the
+// minimum one is used, which is close to the ascending behavior of mongodb
+func getSortValue(doc document, field string) (interface{}, bool) {
+	values, exists := lookupValues(doc, strings.Split(field, "."))
+	if !exists || len(values) == 0 {
+		return nil, false
+	}
+	if len(values) == 1 {
+		return values[0], true
+	}
+	min := values[0]
+	for _, val := range values[1:] {
+		if compareValues(val, min) < 0 {
+			min = val
+		}
+	}
+	return min, true
+}
+
+// projectDocument projects the document with the inclusion or exclusion fields
+func projectDocument(doc document, projection document) (document, error) {
+	inclusive := false
+	for field, val := range projection {
+		if field == "_id" {
+			continue
+		}
+		if _, isExpr := val.(string); isExpr || isTrue(val) {
+			inclusive = true
+			break
+		}
+	}
+
+	if !inclusive {
+		projected := copyDocument(doc)
+		for field := range projection {
+			unsetValue(projected, field)
+		}
+		return projected, nil
+	}
+
+	projected := make(document)
+	if idVal, exists := doc["_id"]; exists {
+		if spec, specified := projection["_id"]; !specified || isTrue(spec) {
+			projected["_id"] = deepCopy(idVal)
+		}
+	}
+
+	for field, spec := range projection {
+		if field == "_id" {
+			continue
+		}
+
+		if _, isBool := spec.(bool); !isBool && typeOrder(spec) != orderNumber {
+			val, err := evaluateExpression(doc, spec)
+			if err != nil {
+				return nil, err
+			}
+			if err := setValue(projected, field, val); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !isTrue(spec) {
+			continue
+		}
+		val, exists := getValue(doc, field)
+		if !exists {
+			continue
+		}
+		if err := setValue(projected, field, deepCopy(val)); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
+
+func projectStage(docs []document, spec interface{}) ([]document, error) {
+	projection, ok := spec.(document)
+	if !ok {
+		return nil, fmt.Errorf("$project stage must be a document")
+	}
+
+	projected := make([]document, len(docs))
+	for idx, doc := range docs {
+		var err error
+		if projected[idx], err = projectDocument(doc, projection); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
+
+func addFieldsStage(docs []document, spec interface{}) ([]document, error) {
+	fields, ok := spec.(document)
+	if !ok {
+		return nil, fmt.Errorf("$addFields stage must be a document")
+	}
+
+	result := make([]document, len(docs))
+	for idx, doc := range docs {
+		added := copyDocument(doc)
+		for field, expr := range fields {
+			val, err := evaluateExpression(doc, expr)
+			if err != nil {
+				return nil, err
+			}
+			if err := setValue(added, field, val); err != nil {
+				return nil, err
+			}
+		}
+		result[idx] = added
+	}
+	return result, nil
+}
+
+func unsetStage(docs []document, spec interface{}) ([]document, error) {
+	var fields []string
+	switch v := spec.(type) {
+	case string:
+		fields = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			field, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("$unset stage must be a string or an array of strings")
+			}
+			fields = append(fields, field)
+		}
+	default:
+		return nil, fmt.Errorf("$unset stage must be a string or an array of strings")
+	}
+
+	result := make([]document, len(docs))
+	for idx, doc := range docs {
+		result[idx] = copyDocument(doc)
+		for _, field := range fields {
+			unsetValue(result[idx], field)
+		}
+	}
+	return result, nil
+}
+
+func unwindStage(docs []document, spec interface{}) ([]document, error) {
+	path, preserve := "", false
+	switch v := spec.(type) {
+	case string:
+		path = v
+	case document:
+		path, _ = v["path"].(string)
+		preserve = isTrue(v["preserveNullAndEmptyArrays"])
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("$unwind path must be prefixed with $")
+	}
+	field := strings.TrimPrefix(path, "$")
+
+	result := make([]document, 0, len(docs))
+	for _, doc := range docs {
+		val, exists := getValue(doc, field)
+		arr, isArr := val.([]interface{})
+		switch {
+		case !exists || val == nil || (isArr && len(arr) == 0):
+			if preserve {
+				result = append(result, doc)
+			}
+		case !isArr:
+			result = append(result, doc)
+		default:
+			for _, item := range arr {
+				unwound := copyDocument(doc)
+				if err := setValue(unwound, field, deepCopy(item)); err != nil {
+					return nil, err
+				}
+				result = append(result, unwound)
+			}
+		}
+	}
+	return result, nil
+}
+
+// evaluateExpression evaluates the aggregate expression, supports field path like "$field", $literal, and documents
+// and arrays that composed of expressions
+func evaluateExpression(doc document, expr interface{}) (interface{}, error) {
+	switch v := expr.(type) {
+	case string:
+		if strings.HasPrefix(v, "$") {
+			val, _ := getValue(doc, strings.TrimPrefix(v, "$"))
+			return deepCopy(val), nil
+		}
+		return v, nil
+	case document:
+		if literal, exists := v["$literal"]; exists && len(v) == 1 {
+			return deepCopy(literal), nil
+		}
+
+		result := make(document, len(v))
+		for key, sub := range v {
+			if strings.HasPrefix(key, "$") {
+				return nil, fmt.Errorf("unsupported aggregate expression operator %s", key)
+			}
+			val, err := evaluateExpression(doc, sub)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = val
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for idx, sub := range v {
+			val, err := evaluateExpression(doc, sub)
+			if err != nil {
+				return nil, err
+			}
+			result[idx] = val
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+type groupAccumulator struct {
+	field    string
+	operator string
+	expr     interface{}
+}
+
+type groupResult struct {
+	id     interface{}
+	values map[string]interface{}
+	counts map[string]int64
+	sets   map[string][]interface{}
+}
+
+// NOCC:golint/fnsize(分组与累加器逻辑整体处理)
+func groupStage(docs []document, spec interface{}) ([]document, error) {
+	groupSpec, ok := spec.(document)
+	if !ok {
+		return nil, fmt.Errorf("$group stage must be a document")
+	}
+
+	idExpr, exists := groupSpec["_id"]
+	if !exists {
+		return nil, fmt.Errorf("$group stage must specify an _id")
+	}
+
+	accumulators := make([]groupAccumulator, 0, len(groupSpec))
+	for field, accSpec := range groupSpec {
+		if field == "_id" {
+			continue
+		}
+		accDoc, ok := accSpec.(document)
+		if !ok || len(accDoc) != 1 {
+			return nil, fmt.Errorf("the group field %s must be an accumulator object", field)
+		}
+		for op, expr := range accDoc {
+			accumulators = append(accumulators, groupAccumulator{field: field, operator: op, expr: expr})
+		}
+	}
+
+	groups := make([]*groupResult, 0)
+	for _, doc := range docs {
+		id, err := evaluateExpression(doc, idExpr)
+		if err != nil {
+			return nil, err
+		}
+
+		var group *groupResult
+		for _, g := range groups {
+			if valuesEqual(g.id, id) {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &groupResult{id: id, values: make(map[string]interface{}), counts: make(map[string]int64),
+				sets: make(map[string][]interface{})}
+			groups = append(groups, group)
+		}
+
+		for _, acc := range accumulators {
+			if err := accumulate(group, doc, acc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make([]document, len(groups))
+	for idx, group := range groups {
+		doc := document{"_id": group.id}
+		for _, acc := range accumulators {
+			switch acc.operator {
+			case "$avg":
+				if group.counts[acc.field] == 0 {
+					doc[acc.field] = nil
+				} else {
+					doc[acc.field] = toFloat(group.values[acc.field]) / float64(group.counts[acc.field])
+				}
+			case "$push", "$addToSet":
+				doc[acc.field] = group.sets[acc.field]
+			default:
+				doc[acc.field] = group.values[acc.field]
+			}
+		}
+		result[idx] = doc
+	}
+	return result, nil
+}
+
+func accumulate(group *groupResult, doc document, acc groupAccumulator) error {
+	val, err := evaluateExpression(doc, acc.expr)
+	if err != nil {
+		return err
+	}
+
+	current, initialized := group.values[acc.field]
+	switch acc.operator {
+	case "$sum", "$count":
+		if acc.operator == "$count" {
+			val = int32(1)
+		}
+		if !initialized {
+			current = int32(0)
+		}
+		if typeOrder(val) == orderNumber {
+			current = addNumbers(current, val)
+		}
+		group.values[acc.field] = current
+	case "$avg":
+		if typeOrder(val) != orderNumber {
+			return nil
+		}
+		if !initialized {
+			current = int32(0)
+		}
+		group.values[acc.field] = addNumbers(current, val)
+		group.counts[acc.field]++
+	case "$min", "$max":
+		if val == nil {
+			return nil
+		}
+		cmp := compareValues(val, current)
+		if !initialized || current == nil || (acc.operator == "$min" && cmp < 0) ||
+			(acc.operator == "$max" && cmp > 0) {
+			group.values[acc.field] = val
+		}
+	case "$first":
+		if !initialized {
+			group.values[acc.field] = val
+		}
+	case "$last":
+		group.values[acc.field] = val
+	case "$push":
+		group.sets[acc.field] = append(group.sets[acc.field], val)
+	case "$addToSet":
+		if !containsValue(group.sets[acc.field], val) {
+			group.sets[acc.field] = append(group.sets[acc.field], val)
+		}
+	default:
+		return fmt.Errorf("unsupported group accumulator %s", acc.operator)
+	}
+
+	if _, exists := group.sets[acc.field]; !exists && (acc.operator == "$push" || acc.operator == "$addToSet") {
+		group.sets[acc.field] = make([]interface{}, 0)
+	}
+	return nil
+}
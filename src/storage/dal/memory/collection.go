@@ -0,0 +1,581 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util/table"
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collection implement types.Table interface
+type Collection struct {
+	collName string // 集合名
+	*Memory
+}
+
+// Find 查询多个并反序列化到 Result
+func (c *Collection) Find(filter types.Filter, opts ...*types.FindOpts) types.Find {
+	find := &Find{
+		Collection: c,
+		filter:     filter,
+		projection: make(map[string]int),
+	}
+
+	find.Option(opts...)
+
+	return find
+}
+
+// Find define a find operation
+type Find struct {
+	*Collection
+
+	projection map[string]int
+	filter     types.Filter
+	start      int64
+	limit      int64
+	sort       bson.D
+
+	option types.FindOpts
+}
+
+// Fields 查询字段
+func (f *Find) Fields(fields ...string) types.Find {
+	for _, field := range fields {
+		if len(field) <= 0 {
+			continue
+		}
+		f.projection[field] = 1
+	}
+	return f
+}
+
+// Sort 查询排序, 格式与mongodb实现相同
+// sort值为"host_id, -host_name"和sort值为"host_id:1, host_name:-1"是一样的，都代表先按host_id递增排序，再按host_name递减排序
+func (f *Find) Sort(sort string) types.Find {
+	if sort == "" {
+		return f
+	}
+
+	f.sort = bson.D{}
+	for _, sortItem := range strings.Split(sort, ",") {
+		sortItemArr := strings.Split(strings.TrimSpace(sortItem), ":")
+		sortKey := strings.TrimLeft(sortItemArr[0], "+-")
+
+		desc := strings.HasPrefix(sortItemArr[0], "-")
+		if len(sortItemArr) == 2 {
+			desc = strings.TrimSpace(sortItemArr[1]) == "-1"
+		}
+
+		if desc {
+			f.sort = append(f.sort, bson.E{Key: sortKey, Value: -1})
+		} else {
+			f.sort = append(f.sort, bson.E{Key: sortKey, Value: 1})
+		}
+	}
+	return f
+}
+
+// Start 查询上标
+func (f *Find) Start(start uint64) types.Find {
+	f.start = int64(start)
+	return f
+}
+
+// Limit 查询限制
+func (f *Find) Limit(limit uint64) types.Find {
+	f.limit = int64(limit)
+	return f
+}
+
+// Option set the find options
+func (f *Find) Option(opts ...*types.FindOpts) {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.WithObjectID != nil {
+			f.option.WithObjectID = opt.WithObjectID
+		}
+		if opt.WithCount != nil {
+			f.option.WithCount = opt.WithCount
+		}
+	}
+}
+
+// matchedDocs returns the documents that matches the filter in the sort order
+func (f *Find) matchedDocs(ctx context.Context) ([]document, error) {
+	filter, err := toDocument(f.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := filterDocuments(f.view(ctx, f.collName).docs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortDocuments(docs, f.sort), nil
+}
+
+// findDocs returns the paged and projected documents that matches the filter
+func (f *Find) findDocs(docs []document) ([]document, error) {
+	if f.start > 0 {
+		if f.start >= int64(len(docs)) {
+			return make([]document, 0), nil
+		}
+		docs = docs[f.start:]
+	}
+	if f.limit > 0 && f.limit < int64(len(docs)) {
+		docs = docs[:f.limit]
+	}
+
+	// do not return _id field unless it's specified, which is the same as the mongodb implementation
+	projection := make(document, len(f.projection)+1)
+	for field, val := range f.projection {
+		projection[field] = val
+	}
+	if f.option.WithObjectID != nil && *f.option.WithObjectID {
+		if len(projection) > 0 {
+			projection["_id"] = 1
+		}
+	} else if _, exists := projection["_id"]; !exists {
+		projection["_id"] = 0
+	}
+
+	projected := make([]document, len(docs))
+	for idx, doc := range docs {
+		var err error
+		if projected[idx], err = projectDocument(doc, projection); err != nil {
+			return nil, err
+		}
+	}
+	return projected, nil
+}
+
+// All 查询多个
+func (f *Find) All(ctx context.Context, result interface{}) error {
+	docs, err := f.matchedDocs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if docs, err = f.findDocs(docs); err != nil {
+		return err
+	}
+	return decodeDocuments(docs, result)
+}
+
+// List 查询多个数据， 当分页中start值为零的时候返回满足条件总行数
+func (f *Find) List(ctx context.Context, result interface{}) (int64, error) {
+	docs, err := f.matchedDocs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if f.start == 0 || (f.option.WithCount != nil && *f.option.WithCount) {
+		total = int64(len(docs))
+	}
+
+	if docs, err = f.findDocs(docs); err != nil {
+		return 0, err
+	}
+	return total, decodeDocuments(docs, result)
+}
+
+// One 查询一个
+func (f *Find) One(ctx context.Context, result interface{}) error {
+	docs, err := f.matchedDocs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if docs, err = f.findDocs(docs); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return types.ErrDocumentNotFound
+	}
+	return decodeDocument(docs[0], result)
+}
+
+// Count 统计数量
+func (f *Find) Count(ctx context.Context) (uint64, error) {
+	docs, err := f.matchedDocs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(docs)), nil
+}
+
+// Insert 插入数据, docs 可以为 单个数据 或者 多个数据
+func (c *Collection) Insert(ctx context.Context, docs interface{}) error {
+	rows, err := toDocuments(docs)
+	if err != nil {
+		return err
+	}
+
+	return c.write(ctx, c.collName, func(tbl *tableData) error {
+		written := make([]int, len(rows))
+		for idx, row := range rows {
+			if _, exists := row["_id"]; !exists {
+				row["_id"] = primitive.NewObjectID()
+			}
+			written[idx] = len(tbl.docs)
+			tbl.docs = append(tbl.docs, row)
+		}
+		return checkUniqueIndexes(c.collName, tbl.indexes, tbl.docs, written)
+	})
+}
+
+// updateDocs updates the documents that matches the filter with the update operators, returns the modified count
+func (c *Collection) updateDocs(ctx context.Context, filter types.Filter, update interface{}, multi, upsert bool) (
+	uint64, error) {
+
+	filterDoc, err := toDocument(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	updateDoc, err := toDocument(update)
+	if err != nil {
+		return 0, err
+	}
+
+	var modified uint64
+	err = c.write(ctx, c.collName, func(tbl *tableData) error {
+		modified = 0
+		matched := false
+		written := make([]int, 0)
+		for idx, doc := range tbl.docs {
+			ok, err := matchDocument(doc, filterDoc)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			matched = true
+			updated, err := applyUpdate(doc, updateDoc, false)
+			if err != nil {
+				return err
+			}
+			if !valuesEqual(doc, updated) {
+				tbl.docs[idx] = updated
+				written = append(written, idx)
+				modified++
+			}
+
+			if !multi {
+				break
+			}
+		}
+
+		if !matched && upsert {
+			inserted, err := upsertDocument(filterDoc, updateDoc)
+			if err != nil {
+				return err
+			}
+			if _, exists := inserted["_id"]; !exists {
+				inserted["_id"] = primitive.NewObjectID()
+			}
+			written = append(written, len(tbl.docs))
+			tbl.docs = append(tbl.docs, inserted)
+		}
+
+		return checkUniqueIndexes(c.collName, tbl.indexes, tbl.docs, written)
+	})
+	return modified, err
+}
+
+// Update 更新数据
+func (c *Collection) Update(ctx context.Context, filter types.Filter, doc interface{}) error {
+	_, err := c.updateDocs(ctx, filter, bson.M{"$set": doc}, true, false)
+	return err
+}
+
+// UpdateMany 更新数据, 返回修改成功的条数
+func (c *Collection) UpdateMany(ctx context.Context, filter types.Filter, doc interface{}) (uint64, error) {
+	return c.updateDocs(ctx, filter, bson.M{"$set": doc}, true, false)
+}
+
+// Upsert 数据存在更新数据，否则新加数据。
+func (c *Collection) Upsert(ctx context.Context, filter types.Filter, doc interface{}) error {
+	_, err := c.updateDocs(ctx, filter, bson.M{"$set": doc}, false, true)
+	return err
+}
+
+// UpdateMultiModel 根据不同的操作符去更新数据
+func (c *Collection) UpdateMultiModel(ctx context.Context, filter types.Filter, updateModel ...types.ModeUpdate) error {
+	data := bson.M{}
+	for _, item := range updateModel {
+		if _, ok := data["$"+item.Op]; ok {
+			return errors.New(item.Op + " appear multiple times")
+		}
+		data["$"+item.Op] = item.Doc
+	}
+
+	_, err := c.updateDocs(ctx, filter, data, true, false)
+	return err
+}
+
+// Delete 删除数据
+func (c *Collection) Delete(ctx context.Context, filter types.Filter) error {
+	_, err := c.DeleteMany(ctx, filter)
+	return err
+}
+
+// DeleteMany 删除数据， 返回删除的行数
+func (c *Collection) DeleteMany(ctx context.Context, filter types.Filter) (uint64, error) {
+	filterDoc, err := toDocument(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := make([]document, 0)
+	err = c.write(ctx, c.collName, func(tbl *tableData) error {
+		deleted = deleted[:0]
+		remain := make([]document, 0, len(tbl.docs))
+		for _, doc := range tbl.docs {
+			ok, err := matchDocument(doc, filterDoc)
+			if err != nil {
+				return err
+			}
+			if ok {
+				deleted = append(deleted, doc)
+				continue
+			}
+			remain = append(remain, doc)
+		}
+		tbl.docs = remain
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.archiveDeletedDocs(ctx, deleted); err != nil {
+		return 0, err
+	}
+	return uint64(len(deleted)), nil
+}
+
+// archiveDeletedDocs archives the deleted documents to the delete archive table like the mongodb implementation
+func (c *Collection) archiveDeletedDocs(ctx context.Context, docs []document) error {
+	delArchiveTable, exists := table.GetDelArchiveTable(c.collName)
+	if !exists || len(docs) == 0 {
+		return nil
+	}
+
+	// only archive the specified fields for delete docs
+	fields := table.GetDelArchiveFields(c.collName)
+	archives := make([]interface{}, len(docs))
+	for idx, doc := range docs {
+		detail := copyDocument(doc)
+		if len(fields) > 0 {
+			detail = make(document)
+			for _, field := range fields {
+				if val, exists := getValue(doc, field); exists {
+					detail[field] = deepCopy(val)
+				}
+			}
+		}
+		delete(detail, "_id")
+
+		oid, _ := doc["_id"].(primitive.ObjectID)
+		archives[idx] = metadata.DeleteArchive{
+			Oid:    oid.Hex(),
+			Detail: detail,
+			Time:   time.Now(),
+			Coll:   c.collName,
+		}
+	}
+
+	return c.Table(delArchiveTable).Insert(ctx, archives)
+}
+
+// CreateIndex 创建索引
+func (c *Collection) CreateIndex(ctx context.Context, index types.Index) error {
+	return c.BatchCreateIndexes(ctx, []types.Index{index})
+}
+
+// BatchCreateIndexes 批量创建索引
+func (c *Collection) BatchCreateIndexes(ctx context.Context, indexes []types.Index) error {
+	// indexes are not transactional, so they are always created on the committed table
+	return c.write(context.Background(), c.collName, func(tbl *tableData) error {
+		for _, index := range indexes {
+			name := indexName(index)
+			exists := false
+			for _, existIndex := range tbl.indexes {
+				// ignore the index that is the same as the existing one, or has same keys with the existing one
+				if indexName(existIndex) == name || compareValues(existIndex.Keys, index.Keys) == 0 {
+					exists = true
+					break
+				}
+			}
+			if exists {
+				continue
+			}
+
+			index.Name = name
+			if index.Unique {
+				written := make([]int, len(tbl.docs))
+				for idx := range tbl.docs {
+					written[idx] = idx
+				}
+				if err := checkUniqueIndexes(c.collName, []types.Index{index}, tbl.docs, written); err != nil {
+					return err
+				}
+			}
+			tbl.indexes = append(tbl.indexes, index)
+		}
+		return nil
+	})
+}
+
+// DropIndex remove index by name
+func (c *Collection) DropIndex(ctx context.Context, indexName string) error {
+	return c.write(context.Background(), c.collName, func(tbl *tableData) error {
+		indexes := make([]types.Index, 0, len(tbl.indexes))
+		for _, index := range tbl.indexes {
+			if index.Name != indexName {
+				indexes = append(indexes, index)
+			}
+		}
+		tbl.indexes = indexes
+		return nil
+	})
+}
+
+// Indexes get all indexes for the collection
+func (c *Collection) Indexes(ctx context.Context) ([]types.Index, error) {
+	indexes := []types.Index{{Keys: bson.D{{Key: "_id", Value: int32(1)}}, Name: idIndexName}}
+	return append(indexes, c.view(ctx, c.collName).indexes...), nil
+}
+
+// AddColumn add a new column for the collection
+func (c *Collection) AddColumn(ctx context.Context, column string, value interface{}) error {
+	selector := bson.M{column: bson.M{"$exists": false}}
+	_, err := c.updateDocs(ctx, selector, bson.M{"$set": bson.M{column: value}}, true, false)
+	return err
+}
+
+// RenameColumn rename a column for the collection
+func (c *Collection) RenameColumn(ctx context.Context, filter types.Filter, oldName, newColumn string) error {
+	_, err := c.updateDocs(ctx, filter, bson.M{"$rename": bson.M{oldName: newColumn}}, true, false)
+	return err
+}
+
+// DropColumn remove a column by the name
+func (c *Collection) DropColumn(ctx context.Context, field string) error {
+	_, err := c.updateDocs(ctx, nil, bson.M{"$unset": bson.M{field: ""}}, true, false)
+	return err
+}
+
+// DropColumns remove many columns by the name
+func (c *Collection) DropColumns(ctx context.Context, filter types.Filter, fields []string) error {
+	unsetFields := make(map[string]interface{})
+	for _, field := range fields {
+		unsetFields[field] = ""
+	}
+
+	_, err := c.updateDocs(ctx, filter, bson.M{"$unset": unsetFields}, true, false)
+	return err
+}
+
+// DropDocsColumn remove a column by the name for doc use filter
+func (c *Collection) DropDocsColumn(ctx context.Context, field string, filter types.Filter) error {
+	_, err := c.updateDocs(ctx, filter, bson.M{"$unset": bson.M{field: ""}}, true, false)
+	return err
+}
+
+// AggregateAll aggregate all operation
+func (c *Collection) AggregateAll(ctx context.Context, pipeline interface{}, result interface{},
+	opts ...*types.AggregateOpts) error {
+
+	docs, err := c.aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return decodeDocuments(docs, result)
+}
+
+// AggregateOne aggregate one operation
+func (c *Collection) AggregateOne(ctx context.Context, pipeline interface{}, result interface{}) error {
+	docs, err := c.aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return types.ErrDocumentNotFound
+	}
+	return decodeDocument(docs[0], result)
+}
+
+func (c *Collection) aggregate(ctx context.Context, pipeline interface{}) ([]document, error) {
+	stages, err := parsePipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(c.view(ctx, c.collName).docs, stages)
+}
+
+// Distinct Finds the distinct values for a specified field across a single collection
+func (c *Collection) Distinct(ctx context.Context, field string, filter types.Filter) ([]interface{}, error) {
+	filterDoc, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := filterDocuments(c.view(ctx, c.collName).docs, filterDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0)
+	for _, doc := range docs {
+		values, exists := lookupValues(doc, strings.Split(field, "."))
+		if !exists {
+			continue
+		}
+
+		for _, val := range values {
+			items := []interface{}{val}
+			// distinct values of array fields are the elements of the array, which is the same as mongodb
+			if arr, ok := val.([]interface{}); ok {
+				items = arr
+			}
+			for _, item := range items {
+				if !containsValue(results, item) {
+					results = append(results, deepCopy(item))
+				}
+			}
+		}
+	}
+	return results, nil
+}
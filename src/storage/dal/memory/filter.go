@@ -0,0 +1,395 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// matchDocument checks if the document matches the mongodb query filter
+func matchDocument(doc document, filter document) (bool, error) {
+	for key, cond := range filter {
+		matched, err := matchFilterElement(doc, key, cond)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchFilterElement(doc document, key string, cond interface{}) (bool, error) {
+	switch key {
+	case "$and", "$or", "$nor":
+		subFilters, ok := cond.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s value must be an array", key)
+		}
+
+		for _, sub := range subFilters {
+			subFilter, ok := sub.(document)
+			if !ok {
+				return false, fmt.Errorf("%s element must be a document", key)
+			}
+
+			matched, err := matchDocument(doc, subFilter)
+			if err != nil {
+				return false, err
+			}
+
+			switch {
+			case key == "$and" && !matched:
+				return false, nil
+			case key == "$or" && matched:
+				return true, nil
+			case key == "$nor" && matched:
+				return false, nil
+			}
+		}
+		return key != "$or", nil
+	case "$comment":
+		return true, nil
+	}
+
+	if strings.HasPrefix(key, "$") {
+		return false, fmt.Errorf("unsupported top level query operator %s", key)
+	}
+
+	values, exists := lookupValues(doc, strings.Split(key, "."))
+	return matchCondition(values, exists, cond)
+}
+
+// isOperatorDocument checks if the condition is an operator expression like {"$gt": 1}
+func isOperatorDocument(cond interface{}) (document, bool) {
+	condDoc, ok := cond.(document)
+	if !ok || len(condDoc) == 0 {
+		return nil, false
+	}
+	for key := range condDoc {
+		if !strings.HasPrefix(key, "$") {
+			return nil, false
+		}
+	}
+	return condDoc, true
+}
+
+// matchCondition checks if the field values matches the condition, values are the values of the field path with the
+// arrays in the path resolved, exists represents if the field path exists in the document.
+func matchCondition(values []interface{}, exists bool, cond interface{}) (bool, error) {
+	operators, isOperator := isOperatorDocument(cond)
+	if !isOperator {
+		return matchEqual(values, exists, cond), nil
+	}
+
+	for op, operand := range operators {
+		matched, err := matchOperator(values, exists, op, operand, operators)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// expandValues returns the values with the elements of array values, mongodb matches both the array and its elements
+func expandValues(values []interface{}) []interface{} {
+	expanded := make([]interface{}, 0, len(values))
+	for _, val := range values {
+		expanded = append(expanded, val)
+		if arr, ok := val.([]interface{}); ok {
+			expanded = append(expanded, arr...)
+		}
+	}
+	return expanded
+}
+
+func matchEqual(values []interface{}, exists bool, target interface{}) bool {
+	if regex, ok := target.(primitive.Regex); ok {
+		matched, err := matchRegex(values, regex.Pattern, regex.Options)
+		return err == nil && matched
+	}
+
+	if target == nil {
+		if !exists {
+			return true
+		}
+	}
+
+	for _, val := range expandValues(values) {
+		if valuesEqual(val, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// NOCC:golint/fnsize(各个操作符逐一判断)
+func matchOperator(values []interface{}, exists bool, op string, operand interface{}, operators document) (bool,
+	error) {
+
+	switch op {
+	case "$eq":
+		return matchEqual(values, exists, operand), nil
+	case "$ne":
+		return !matchEqual(values, exists, operand), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, val := range expandValues(values) {
+			if typeOrder(val) != typeOrder(operand) {
+				continue
+			}
+			cmp := compareValues(val, operand)
+			if (op == "$gt" && cmp > 0) || (op == "$gte" && cmp >= 0) || (op == "$lt" && cmp < 0) ||
+				(op == "$lte" && cmp <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$in", "$nin":
+		targets, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s value must be an array", op)
+		}
+		matched := false
+		for _, target := range targets {
+			if matchEqual(values, exists, target) {
+				matched = true
+				break
+			}
+		}
+		if op == "$in" {
+			return matched, nil
+		}
+		return !matched, nil
+	case "$exists":
+		return exists == isTrue(operand), nil
+	case "$regex":
+		pattern, options := "", ""
+		switch regex := operand.(type) {
+		case string:
+			pattern = regex
+		case primitive.Regex:
+			pattern, options = regex.Pattern, regex.Options
+		default:
+			return false, fmt.Errorf("$regex value must be a string")
+		}
+		if opt, ok := operators["$options"].(string); ok {
+			options = opt
+		}
+		return matchRegex(values, pattern, options)
+	case "$options":
+		// used with $regex
+		return true, nil
+	case "$not":
+		matched, err := matchCondition(values, exists, operand)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	case "$all":
+		targets, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all value must be an array")
+		}
+		if len(targets) == 0 {
+			return false, nil
+		}
+		for _, target := range targets {
+			if !matchEqual(values, exists, target) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$size":
+		size, ok := toInt64(operand)
+		if !ok {
+			return false, fmt.Errorf("$size value must be an integer")
+		}
+		for _, val := range values {
+			if arr, ok := val.([]interface{}); ok && int64(len(arr)) == size {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$elemMatch":
+		return matchElement(values, operand)
+	case "$type":
+		return matchType(values, operand)
+	default:
+		return false, fmt.Errorf("unsupported query operator %s", op)
+	}
+}
+
+func matchElement(values []interface{}, operand interface{}) (bool, error) {
+	cond, ok := operand.(document)
+	if !ok {
+		return false, fmt.Errorf("$elemMatch value must be a document")
+	}
+
+	_, isOperator := isOperatorDocument(cond)
+	for _, val := range values {
+		arr, ok := val.([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, elem := range arr {
+			var matched bool
+			var err error
+			if isOperator {
+				matched, err = matchCondition([]interface{}{elem}, true, cond)
+			} else {
+				elemDoc, isDoc := elem.(document)
+				if !isDoc {
+					continue
+				}
+				matched, err = matchDocument(elemDoc, cond)
+			}
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// bsonTypeAliases are the bson type aliases and numbers used by $type operator
+var bsonTypeAliases = map[string][]interface{}{
+	"double":     {int32(1), int64(1), float64(1)},
+	"string":     {int32(2), int64(2), float64(2)},
+	"object":     {int32(3), int64(3), float64(3)},
+	"array":      {int32(4), int64(4), float64(4)},
+	"binData":    {int32(5), int64(5), float64(5)},
+	"objectId":   {int32(7), int64(7), float64(7)},
+	"bool":       {int32(8), int64(8), float64(8)},
+	"date":       {int32(9), int64(9), float64(9)},
+	"null":       {int32(10), int64(10), float64(10)},
+	"regex":      {int32(11), int64(11), float64(11)},
+	"int":        {int32(16), int64(16), float64(16)},
+	"long":       {int32(18), int64(18), float64(18)},
+	"decimal":    {int32(19), int64(19), float64(19)},
+	"timestamp":  {int32(17), int64(17), float64(17)},
+	"javascript": {int32(13), int64(13), float64(13)},
+}
+
+// valueTypeAlias returns the bson type alias of the in memory value
+func valueTypeAlias(val interface{}) string {
+	switch val.(type) {
+	case float32, float64:
+		return "double"
+	case string:
+		return "string"
+	case document, bson.D:
+		return "object"
+	case []interface{}:
+		return "array"
+	case primitive.Binary:
+		return "binData"
+	case primitive.ObjectID:
+		return "objectId"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "date"
+	case nil:
+		return "null"
+	case primitive.Regex:
+		return "regex"
+	case int32, int8, int16, uint8, uint16:
+		return "int"
+	case int, int64, uint, uint32, uint64:
+		return "long"
+	case primitive.Decimal128:
+		return "decimal"
+	default:
+		return ""
+	}
+}
+
+func matchType(values []interface{}, operand interface{}) (bool, error) {
+	types := []interface{}{operand}
+	if arr, ok := operand.([]interface{}); ok {
+		types = arr
+	}
+
+	for _, val := range expandValues(values) {
+		alias := valueTypeAlias(val)
+		for _, typ := range types {
+			if typ == "number" && typeOrder(val) == orderNumber {
+				return true, nil
+			}
+			if typ == alias {
+				return true, nil
+			}
+			if typeOrder(typ) == orderNumber && containsValue(bsonTypeAliases[alias], typ) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func matchRegex(values []interface{}, pattern, options string) (bool, error) {
+	flags := ""
+	for _, opt := range options {
+		switch opt {
+		case 'i', 'm', 's':
+			flags += string(opt)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid regex %s, err: %v", pattern, err)
+	}
+
+	for _, val := range expandValues(values) {
+		if str, ok := val.(string); ok && regex.MatchString(str) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isTrue(val interface{}) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case nil:
+		return false
+	default:
+		if typeOrder(v) == orderNumber {
+			return toFloat(v) != 0
+		}
+		return true
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// idIndexName is the name of the default unique index of the _id field
+const idIndexName = "_id_"
+
+// indexName returns the name of the index, uses the mongodb default name format when the name is not set
+func indexName(index types.Index) string {
+	if index.Name != "" {
+		return index.Name
+	}
+
+	parts := make([]string, 0, len(index.Keys))
+	for _, key := range index.Keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// indexKey returns the key of the document in the unique index, returns false if the document is not indexed because
+// of the partial filter expression
+func indexKey(index types.Index, partialFilter document, doc document) (bson.D, bool, error) {
+	if len(partialFilter) > 0 {
+		matched, err := matchDocument(doc, partialFilter)
+		if err != nil {
+			return nil, false, err
+		}
+		if !matched {
+			return nil, false, nil
+		}
+	}
+
+	key := make(bson.D, len(index.Keys))
+	for idx, field := range index.Keys {
+		val, _ := getValue(doc, field.Key)
+		key[idx] = bson.E{Key: field.Key, Value: val}
+	}
+	return key, true, nil
+}
+
+// checkUniqueIndexes checks if the written documents violates the unique indexes of the table, docs are all the
+// documents of the table after the write operation, written are the indexes of the written documents in docs.
+func checkUniqueIndexes(collName string, indexes []types.Index, docs []document, written []int) error {
+	if len(written) == 0 {
+		return nil
+	}
+
+	allIndexes := append([]types.Index{{Keys: bson.D{{Key: "_id", Value: 1}}, Name: idIndexName, Unique: true}},
+		indexes...)
+
+	for _, index := range allIndexes {
+		if !index.Unique {
+			continue
+		}
+
+		partialFilter, err := toDocument(index.PartialFilterExpression)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]bson.D, len(docs))
+		for idx, doc := range docs {
+			key, indexed, err := indexKey(index, partialFilter, doc)
+			if err != nil {
+				return err
+			}
+			if indexed {
+				keys[idx] = key
+			}
+		}
+
+		for _, writtenIdx := range written {
+			if keys[writtenIdx] == nil {
+				continue
+			}
+			for idx, key := range keys {
+				if idx != writtenIdx && key != nil && compareValues(key, keys[writtenIdx]) == 0 {
+					return duplicateKeyError(collName, indexName(index), keys[writtenIdx])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// duplicateKeyError generates the same duplicate key error as mongodb, so that the callers can parse the duplicated
+// key from the error message
+func duplicateKeyError(collName, name string, key bson.D) error {
+	parts := make([]string, len(key))
+	for idx, item := range key {
+		if str, ok := item.Value.(string); ok {
+			parts[idx] = fmt.Sprintf("%s: \"%s\"", item.Key, str)
+			continue
+		}
+		parts[idx] = fmt.Sprintf("%s: %v", item.Key, item.Value)
+	}
+	return fmt.Errorf("write exception: write errors: [E11000 duplicate key error collection: %s index: %s "+
+		"dup key: { %s }]", collName, name, strings.Join(parts, ", "))
+}
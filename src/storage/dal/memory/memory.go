@@ -0,0 +1,383 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package memory is an in memory implementation of the dal.DB interface, it's used for unit tests and local
+// development that do not have a mongodb environment. It supports the commonly used query and update operators,
+// aggregate stages, unique indexes, sequences and transactions of mongodb, but the data is not persisted.
+//
+// The data lives in the memory of the process that creates the db, it is not shared between processes. So all the
+// services that use it must run in the same process, e.g. a unit test or a single all-in-one development process,
+// the services deployed as separate processes each see their own empty db. Change streams are not supported either,
+// so the event watch and cache features that rely on them do not work on it.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/redis"
+	"configcenter/src/storage/dal/types"
+	// register the bson codecs of cmdb, so that the decoded results are the same as the mongodb implementation
+	_ "configcenter/src/storage/dal/mongo/local"
+)
+
+// idGeneratorTable is the table that stores the sequences, which is the same as the mongodb implementation
+const idGeneratorTable = "cc_idgenerator"
+
+// errWriteConflict is returned when the transaction conflicts with another write operation, the error message
+// contains "WriteConflict" so that it can be recognized like the mongodb write conflict error
+var errWriteConflict = errors.New("WriteConflict: transaction conflicts with another write operation, please retry")
+
+// Memory is the in memory db implementation of dal.DB
+type Memory struct {
+	lock   sync.RWMutex
+	tables map[string]*tableData
+	// txns is the map of transaction session id to its uncommitted changes
+	txns map[string]*txn
+	// conflicts records the sessions that failed because of write conflict, so that the abort returns retry
+	conflicts map[string]bool
+}
+
+// New creates a new empty in memory db
+func New() *Memory {
+	return &Memory{
+		tables:    make(map[string]*tableData),
+		txns:      make(map[string]*txn),
+		conflicts: make(map[string]bool),
+	}
+}
+
+// tableData is the data of a collection, its documents are never modified in place, writes are applied on a clone of
+// the table and the clone replaces the original one if the write succeeds.
+type tableData struct {
+	docs    []document
+	indexes []types.Index
+	// version is increased on each committed write, it's used to detect transaction write conflicts
+	version uint64
+}
+
+func (t *tableData) clone() *tableData {
+	if t == nil {
+		return &tableData{docs: make([]document, 0), indexes: make([]types.Index, 0)}
+	}
+
+	return &tableData{
+		docs:    append(make([]document, 0, len(t.docs)), t.docs...),
+		indexes: append(make([]types.Index, 0, len(t.indexes)), t.indexes...),
+		version: t.version,
+	}
+}
+
+// txn is the uncommitted changes of a transaction
+type txn struct {
+	tables map[string]*txnTable
+}
+
+type txnTable struct {
+	data *tableData
+	// baseVersion is the version of the committed table when the transaction first writes it
+	baseVersion uint64
+}
+
+// parseTxnID parses the transaction session id from the context, returns false if not in transaction environment
+func parseTxnID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(common.TransactionIdHeader).(string)
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// view returns the table that the context can see, the returned table must not be modified
+func (m *Memory) view(ctx context.Context, collName string) *tableData {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if id, useTxn := parseTxnID(ctx); useTxn {
+		if session, exists := m.txns[id]; exists {
+			if tbl, exists := session.tables[collName]; exists {
+				return tbl.data
+			}
+		}
+	}
+
+	tbl := m.tables[collName]
+	if tbl == nil {
+		return &tableData{}
+	}
+	return tbl
+}
+
+// write applies the write operation on a clone of the table, the clone takes effect only when the operation succeeds.
+// when in transaction environment, the changes are stored in the transaction until it's committed.
+func (m *Memory) write(ctx context.Context, collName string, do func(tbl *tableData) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	committed := m.tables[collName]
+
+	id, useTxn := parseTxnID(ctx)
+	if !useTxn {
+		tbl := committed.clone()
+		if err := do(tbl); err != nil {
+			return err
+		}
+		tbl.version++
+		m.tables[collName] = tbl
+		return nil
+	}
+
+	session, exists := m.txns[id]
+	if !exists {
+		session = &txn{tables: make(map[string]*txnTable)}
+		m.txns[id] = session
+	}
+
+	txnTbl, exists := session.tables[collName]
+	if !exists {
+		txnTbl = &txnTable{data: committed.clone()}
+		if committed != nil {
+			txnTbl.baseVersion = committed.version
+		}
+	}
+
+	if committed != nil && committed.version != txnTbl.baseVersion {
+		m.conflicts[id] = true
+		return errWriteConflict
+	}
+
+	tbl := txnTbl.data.clone()
+	if err := do(tbl); err != nil {
+		return err
+	}
+	session.tables[collName] = &txnTable{data: tbl, baseVersion: txnTbl.baseVersion}
+	return nil
+}
+
+// Table collection operation
+func (m *Memory) Table(collName string) types.Table {
+	return &Collection{collName: collName, Memory: m}
+}
+
+// NextSequence 获取新序列号(非事务)
+func (m *Memory) NextSequence(ctx context.Context, sequenceName string) (uint64, error) {
+	sequences, err := m.NextSequences(ctx, sequenceName, 1)
+	if err != nil {
+		return 0, err
+	}
+	return sequences[0], nil
+}
+
+// NextSequences 批量获取新序列号(非事务)
+func (m *Memory) NextSequences(ctx context.Context, sequenceName string, num int) ([]uint64, error) {
+	if num == 0 {
+		return make([]uint64, 0), nil
+	}
+
+	sequenceName = redirectTable(sequenceName)
+
+	// use a new context to make sure that the sequence is not generated in transaction, which is the same as mongodb
+	var sequenceID uint64
+	err := m.write(context.Background(), idGeneratorTable, func(tbl *tableData) error {
+		now := time.Now()
+		update := document{
+			"$inc":         document{"SequenceID": int64(num)},
+			"$setOnInsert": document{"create_time": now},
+			"$set":         document{"last_time": now},
+		}
+
+		for idx, doc := range tbl.docs {
+			if !valuesEqual(doc["_id"], sequenceName) {
+				continue
+			}
+			updated, err := applyUpdate(doc, update, false)
+			if err != nil {
+				return err
+			}
+			tbl.docs[idx] = updated
+			sequenceID = uint64(toFloat(updated["SequenceID"]))
+			return nil
+		}
+
+		inserted, err := upsertDocument(document{"_id": sequenceName}, update)
+		if err != nil {
+			return err
+		}
+		tbl.docs = append(tbl.docs, inserted)
+		sequenceID = uint64(toFloat(inserted["SequenceID"]))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := make([]uint64, num)
+	for i := 0; i < num; i++ {
+		sequences[i] = uint64(i-num+1) + sequenceID
+	}
+	return sequences, nil
+}
+
+func redirectTable(tableName string) string {
+	if common.IsObjectInstShardingTable(tableName) {
+		tableName = common.BKTableNameBaseInst
+	} else if common.IsObjectInstAsstShardingTable(tableName) {
+		tableName = common.BKTableNameInstAsst
+	}
+	return tableName
+}
+
+// Ping 健康检查
+func (m *Memory) Ping() error {
+	return nil
+}
+
+// HasTable 判断是否存在集合
+func (m *Memory) HasTable(ctx context.Context, name string) (bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	_, exists := m.tables[name]
+	return exists, nil
+}
+
+// ListTables 获取所有的表名
+func (m *Memory) ListTables(ctx context.Context) ([]string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	names := make([]string, 0, len(m.tables))
+	for name := range m.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DropTable 移除集合
+func (m *Memory) DropTable(ctx context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.tables, name)
+	return nil
+}
+
+// CreateTable 创建集合
+func (m *Memory) CreateTable(ctx context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, exists := m.tables[name]; exists {
+		return fmt.Errorf("(NamespaceExists) collection %s already exists", name)
+	}
+	m.tables[name] = (*tableData)(nil).clone()
+	return nil
+}
+
+// RenameTable 更新集合名称
+func (m *Memory) RenameTable(ctx context.Context, prevName, currName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	tbl, exists := m.tables[prevName]
+	if !exists {
+		return fmt.Errorf("(NamespaceNotFound) source namespace %s does not exist", prevName)
+	}
+	if _, exists := m.tables[currName]; exists {
+		return fmt.Errorf("(NamespaceExists) target namespace %s exists", currName)
+	}
+
+	m.tables[currName] = tbl
+	delete(m.tables, prevName)
+	return nil
+}
+
+// IsDuplicatedError check duplicated error
+func (m *Memory) IsDuplicatedError(err error) bool {
+	if err != nil && strings.Contains(err.Error(), "E11000 duplicate") {
+		return true
+	}
+	return err == types.ErrDuplicated
+}
+
+// IsNotFoundError check the not found error
+func (m *Memory) IsNotFoundError(err error) bool {
+	return err == types.ErrDocumentNotFound
+}
+
+// Close 关闭连接
+func (m *Memory) Close() error {
+	return nil
+}
+
+// CommitTransaction 提交事务
+func (m *Memory) CommitTransaction(ctx context.Context, cap *metadata.TxnCapable) error {
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	session, exists := m.txns[cap.SessionID]
+	if !exists {
+		blog.Infof("commit transaction: %s but no transaction need to commit, *skip*, rid: %s", cap.SessionID, rid)
+		return nil
+	}
+	delete(m.txns, cap.SessionID)
+
+	for name, tbl := range session.tables {
+		if committed := m.tables[name]; committed != nil && committed.version != tbl.baseVersion {
+			m.conflicts[cap.SessionID] = true
+			return fmt.Errorf("commit transaction: %s failed, err: %v, rid: %v", cap.SessionID, errWriteConflict, rid)
+		}
+	}
+
+	for name, tbl := range session.tables {
+		tbl.data.version = tbl.baseVersion + 1
+		m.tables[name] = tbl.data
+	}
+	delete(m.conflicts, cap.SessionID)
+	return nil
+}
+
+// AbortTransaction 取消事务, 返回事务是否需要重试
+func (m *Memory) AbortTransaction(ctx context.Context, cap *metadata.TxnCapable) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.txns, cap.SessionID)
+
+	// retry when the transaction error type is write conflict, which means the transaction conflicts with another one
+	retry := m.conflicts[cap.SessionID]
+	delete(m.conflicts, cap.SessionID)
+	return retry, nil
+}
+
+// InitTxnManager the transactions of memory db are managed in memory, so the redis client is not used
+func (m *Memory) InitTxnManager(r redis.Client) error {
+	return nil
+}
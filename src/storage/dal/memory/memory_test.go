@@ -0,0 +1,282 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/types"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type testHost struct {
+	ID     int64    `bson:"bk_host_id"`
+	Name   string   `bson:"bk_host_name"`
+	CPU    int64    `bson:"bk_cpu"`
+	Tags   []string `bson:"tags"`
+	BizID  int64    `bson:"bk_biz_id"`
+	Remark string   `bson:"remark,omitempty"`
+}
+
+func prepareHosts(t *testing.T, db *Memory) {
+	hosts := []testHost{
+		{ID: 1, Name: "host-a", CPU: 4, Tags: []string{"db", "prod"}, BizID: 1},
+		{ID: 2, Name: "host-b", CPU: 8, Tags: []string{"web"}, BizID: 1},
+		{ID: 3, Name: "Host-c", CPU: 2, Tags: []string{"web", "prod"}, BizID: 2},
+		{ID: 4, Name: "host-d", CPU: 16, BizID: 2, Remark: "big"},
+	}
+	require.NoError(t, db.Table("host").Insert(context.Background(), hosts))
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	prepareHosts(t, db)
+
+	cases := []struct {
+		filter types.Filter
+		ids    []int64
+	}{
+		{filter: nil, ids: []int64{1, 2, 3, 4}},
+		{filter: mapstr.MapStr{"bk_biz_id": 1}, ids: []int64{1, 2}},
+		{filter: mapstr.MapStr{"bk_cpu": mapstr.MapStr{common.BKDBGTE: 4, common.BKDBLT: 16}}, ids: []int64{1, 2}},
+		{filter: mapstr.MapStr{"tags": "prod"}, ids: []int64{1, 3}},
+		{filter: mapstr.MapStr{"tags": mapstr.MapStr{common.BKDBAll: []string{"web", "prod"}}}, ids: []int64{3}},
+		{filter: mapstr.MapStr{"bk_host_id": mapstr.MapStr{common.BKDBNIN: []int64{1, 2}}}, ids: []int64{3, 4}},
+		{filter: mapstr.MapStr{"remark": mapstr.MapStr{common.BKDBExists: true}}, ids: []int64{4}},
+		{filter: mapstr.MapStr{"tags": nil}, ids: []int64{4}},
+		{filter: mapstr.MapStr{"bk_host_name": mapstr.MapStr{common.BKDBLIKE: "^host", common.BKDBOPTIONS: "i"}},
+			ids: []int64{1, 2, 3, 4}},
+		{filter: mapstr.MapStr{"bk_host_name": mapstr.MapStr{common.BKDBLIKE: "^host"}}, ids: []int64{1, 2, 4}},
+		{filter: mapstr.MapStr{common.BKDBOR: []mapstr.MapStr{{"bk_host_id": 1}, {"bk_cpu": 2}}}, ids: []int64{1, 3}},
+		{filter: mapstr.MapStr{"bk_cpu": mapstr.MapStr{common.BKDBNot: mapstr.MapStr{common.BKDBGT: 4}}},
+			ids: []int64{1, 3}},
+	}
+
+	for _, c := range cases {
+		hosts := make([]testHost, 0)
+		require.NoError(t, db.Table("host").Find(c.filter).Sort("bk_host_id").All(ctx, &hosts))
+		ids := make([]int64, len(hosts))
+		for idx, host := range hosts {
+			ids[idx] = host.ID
+		}
+		require.Equal(t, c.ids, ids, "filter: %v", c.filter)
+	}
+}
+
+func TestFindPageAndProjection(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	prepareHosts(t, db)
+
+	hosts := make([]mapstr.MapStr, 0)
+	total, err := db.Table("host").Find(nil).Fields("bk_host_id").Sort("-bk_cpu").Start(0).Limit(2).
+		List(ctx, &hosts)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), total)
+	require.Len(t, hosts, 2)
+	require.Equal(t, mapstr.MapStr{"bk_host_id": int64(4)}, hosts[0])
+	require.Equal(t, mapstr.MapStr{"bk_host_id": int64(2)}, hosts[1])
+
+	host := make(mapstr.MapStr)
+	err = db.Table("host").Find(nil).Sort("bk_biz_id:-1,bk_cpu:1").Start(1).One(ctx, &host)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), host["bk_host_id"])
+	_, exists := host["_id"]
+	require.False(t, exists)
+
+	err = db.Table("host").Find(mapstr.MapStr{"bk_host_id": 10}).One(ctx, &host)
+	require.True(t, db.IsNotFoundError(err))
+
+	cnt, err := db.Table("host").Find(mapstr.MapStr{"bk_biz_id": 2}).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), cnt)
+
+	values, err := db.Table("host").Distinct(ctx, "tags", nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []interface{}{"db", "prod", "web", nil}, values)
+}
+
+func TestUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	prepareHosts(t, db)
+	table := db.Table("host")
+
+	cnt, err := table.UpdateMany(ctx, mapstr.MapStr{"bk_biz_id": 1}, mapstr.MapStr{"bk_biz_id": 3})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), cnt)
+
+	err = table.UpdateMultiModel(ctx, mapstr.MapStr{"bk_host_id": 2},
+		types.ModeUpdate{Op: types.UpdateOpAddToSet, Doc: mapstr.MapStr{"tags": "web"}},
+		types.ModeUpdate{Op: "inc", Doc: mapstr.MapStr{"bk_cpu": 2}})
+	require.NoError(t, err)
+	err = table.UpdateMultiModel(ctx, mapstr.MapStr{"bk_host_id": 3},
+		types.ModeUpdate{Op: types.UpdateOpPull, Doc: mapstr.MapStr{"tags": "prod"}})
+	require.NoError(t, err)
+
+	hosts := make([]testHost, 0)
+	require.NoError(t, table.Find(mapstr.MapStr{"bk_host_id": mapstr.MapStr{common.BKDBIN: []int64{2, 3}}}).
+		Sort("bk_host_id").All(ctx, &hosts))
+	require.Equal(t, []string{"web"}, hosts[0].Tags)
+	require.Equal(t, int64(10), hosts[0].CPU)
+	require.Equal(t, int64(3), hosts[0].BizID)
+	require.Equal(t, []string{"web"}, hosts[1].Tags)
+
+	require.NoError(t, table.Upsert(ctx, mapstr.MapStr{"bk_host_id": 5}, mapstr.MapStr{"bk_host_name": "host-e"}))
+	host := testHost{}
+	require.NoError(t, table.Find(mapstr.MapStr{"bk_host_id": 5}).One(ctx, &host))
+	require.Equal(t, "host-e", host.Name)
+
+	deleted, err := table.DeleteMany(ctx, mapstr.MapStr{"bk_biz_id": 3})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), deleted)
+
+	cnt, err = table.Find(nil).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), cnt)
+}
+
+func TestAggregate(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	prepareHosts(t, db)
+
+	pipeline := []mapstr.MapStr{
+		{common.BKDBMatch: mapstr.MapStr{"bk_cpu": mapstr.MapStr{common.BKDBGT: 2}}},
+		{common.BKDBGroup: mapstr.MapStr{
+			"_id":   "$bk_biz_id",
+			"count": mapstr.MapStr{common.BKDBSum: 1},
+			"cpu":   mapstr.MapStr{common.BKDBSum: "$bk_cpu"},
+			"ids":   mapstr.MapStr{"$push": "$bk_host_id"},
+		}},
+		{common.BKDBSort: mapstr.MapStr{"_id": 1}},
+	}
+
+	result := make([]struct {
+		BizID int64   `bson:"_id"`
+		Count int64   `bson:"count"`
+		CPU   int64   `bson:"cpu"`
+		IDs   []int64 `bson:"ids"`
+	}, 0)
+	require.NoError(t, db.Table("host").AggregateAll(ctx, pipeline, &result))
+	require.Len(t, result, 2)
+	require.Equal(t, int64(1), result[0].BizID)
+	require.Equal(t, int64(2), result[0].Count)
+	require.Equal(t, int64(12), result[0].CPU)
+	require.Equal(t, []int64{1, 2}, result[0].IDs)
+	require.Equal(t, int64(2), result[1].BizID)
+	require.Equal(t, []int64{4}, result[1].IDs)
+
+	unwind := []mapstr.MapStr{
+		{common.BKDBUnwind: "$tags"},
+		{common.BKDBGroup: mapstr.MapStr{"_id": "$tags", "count": mapstr.MapStr{common.BKDBSum: 1}}},
+		{common.BKDBSort: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{common.BKDBLimit: 1},
+	}
+	top := mapstr.MapStr{}
+	require.NoError(t, db.Table("host").AggregateOne(ctx, unwind, &top))
+	require.Equal(t, "prod", top["_id"])
+}
+
+func TestUniqueIndex(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	prepareHosts(t, db)
+	table := db.Table("host")
+
+	index := types.Index{Name: "bk_idx_name", Keys: bson.D{{Key: "bk_host_name", Value: 1}}, Unique: true}
+	require.NoError(t, table.CreateIndex(ctx, index))
+
+	err := table.Insert(ctx, testHost{ID: 5, Name: "host-a"})
+	require.True(t, db.IsDuplicatedError(err))
+
+	err = table.Update(ctx, mapstr.MapStr{"bk_host_id": 2}, mapstr.MapStr{"bk_host_name": "host-a"})
+	require.True(t, db.IsDuplicatedError(err))
+
+	// partial index only checks the matched documents
+	partial := types.Index{Name: "bk_idx_remark", Keys: bson.D{{Key: "remark", Value: 1}}, Unique: true,
+		PartialFilterExpression: map[string]interface{}{"remark": map[string]interface{}{common.BKDBType: "string"}}}
+	require.NoError(t, table.CreateIndex(ctx, partial))
+	require.NoError(t, table.Insert(ctx, testHost{ID: 6, Name: "host-f"}))
+
+	indexes, err := table.Indexes(ctx)
+	require.NoError(t, err)
+	require.Len(t, indexes, 3)
+}
+
+func TestSequence(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+
+	id, err := db.NextSequence(ctx, "host")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), id)
+
+	ids, err := db.NextSequences(ctx, "host", 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3, 4}, ids)
+
+	id, err = db.NextSequence(ctx, common.GetObjectInstTableName("biz_set", "0"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), id)
+}
+
+func TestTransaction(t *testing.T) {
+	db := New()
+	cap := &metadata.TxnCapable{SessionID: "txn-1"}
+	txnCtx := context.WithValue(context.Background(), common.TransactionIdHeader, cap.SessionID)
+	ctx := context.Background()
+
+	require.NoError(t, db.Table("host").Insert(txnCtx, testHost{ID: 1}))
+
+	// the uncommitted data is only visible in the transaction
+	cnt, err := db.Table("host").Find(nil).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), cnt)
+	cnt, err = db.Table("host").Find(nil).Count(txnCtx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), cnt)
+
+	require.NoError(t, db.CommitTransaction(ctx, cap))
+	cnt, err = db.Table("host").Find(nil).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), cnt)
+
+	// aborted transaction discards the changes
+	require.NoError(t, db.Table("host").Insert(txnCtx, testHost{ID: 2}))
+	retry, err := db.AbortTransaction(ctx, cap)
+	require.NoError(t, err)
+	require.False(t, retry)
+	cnt, err = db.Table("host").Find(nil).Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), cnt)
+
+	// write conflict with another write operation
+	require.NoError(t, db.Table("host").Insert(txnCtx, testHost{ID: 3}))
+	require.NoError(t, db.Table("host").Insert(ctx, testHost{ID: 4}))
+	require.Error(t, db.CommitTransaction(ctx, cap))
+	retry, err = db.AbortTransaction(ctx, cap)
+	require.NoError(t, err)
+	require.True(t, retry)
+}
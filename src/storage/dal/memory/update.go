@@ -0,0 +1,221 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// applyUpdate applies the mongodb update operators to a copy of the document and returns the updated copy,
+// setOnInsert represents if the update is an upsert that inserts a new document.
+func applyUpdate(doc document, update document, setOnInsert bool) (document, error) {
+	updated := copyDocument(doc)
+	for op, operand := range update {
+		fields, ok := operand.(document)
+		if !ok {
+			return nil, fmt.Errorf("%s value must be a document", op)
+		}
+
+		for field, value := range fields {
+			if err := applyUpdateOperator(updated, op, field, value, setOnInsert); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return updated, nil
+}
+
+// NOCC:golint/fnsize(各个操作符逐一处理)
+func applyUpdateOperator(doc document, op, field string, value interface{}, setOnInsert bool) error {
+	if field == "_id" && op != "$setOnInsert" {
+		if existing, exists := doc["_id"]; exists && (op != "$set" || !valuesEqual(existing, value)) {
+			return fmt.Errorf("performing an update on the path '_id' would modify the immutable field '_id'")
+		}
+	}
+
+	switch op {
+	case "$set":
+		return setValue(doc, field, deepCopy(value))
+	case "$setOnInsert":
+		if !setOnInsert {
+			return nil
+		}
+		return setValue(doc, field, deepCopy(value))
+	case "$unset":
+		unsetValue(doc, field)
+		return nil
+	case "$inc":
+		if typeOrder(value) != orderNumber {
+			return fmt.Errorf("cannot increment with non-numeric argument: {%s: %v}", field, value)
+		}
+		current, exists := getValue(doc, field)
+		if !exists || current == nil {
+			return setValue(doc, field, value)
+		}
+		if typeOrder(current) != orderNumber {
+			return fmt.Errorf("cannot apply $inc to a value of non-numeric type, field: %s", field)
+		}
+		return setValue(doc, field, addNumbers(current, value))
+	case "$rename":
+		newField, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("$rename target of %s must be a string", field)
+		}
+		current, exists := getValue(doc, field)
+		if !exists {
+			return nil
+		}
+		unsetValue(doc, field)
+		return setValue(doc, newField, current)
+	case "$push", "$addToSet":
+		current, exists := getValue(doc, field)
+		var arr []interface{}
+		if exists && current != nil {
+			existArr, ok := current.([]interface{})
+			if !ok {
+				return fmt.Errorf("the field %s must be an array but is of type %T", field, current)
+			}
+			arr = append(make([]interface{}, 0, len(existArr)+1), existArr...)
+		}
+
+		items := []interface{}{value}
+		if modifiers, ok := value.(document); ok {
+			if each, exists := modifiers["$each"]; exists {
+				eachArr, ok := each.([]interface{})
+				if !ok {
+					return fmt.Errorf("the argument to $each in %s must be an array", op)
+				}
+				items = eachArr
+			}
+		}
+
+		for _, item := range items {
+			if op == "$addToSet" && containsValue(arr, item) {
+				continue
+			}
+			arr = append(arr, deepCopy(item))
+		}
+		if arr == nil {
+			arr = make([]interface{}, 0)
+		}
+		return setValue(doc, field, arr)
+	case "$pull", "$pullAll":
+		current, exists := getValue(doc, field)
+		if !exists || current == nil {
+			return nil
+		}
+		existArr, ok := current.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot apply %s to a non-array value, field: %s", op, field)
+		}
+
+		arr := make([]interface{}, 0, len(existArr))
+		for _, item := range existArr {
+			pulled, err := isPulled(op, item, value)
+			if err != nil {
+				return err
+			}
+			if !pulled {
+				arr = append(arr, item)
+			}
+		}
+		return setValue(doc, field, arr)
+	case "$min", "$max":
+		current, exists := getValue(doc, field)
+		cmp := compareValues(value, current)
+		if !exists || (op == "$min" && cmp < 0) || (op == "$max" && cmp > 0) {
+			return setValue(doc, field, deepCopy(value))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported update operator %s", op)
+	}
+}
+
+func isPulled(op string, item, value interface{}) (bool, error) {
+	if op == "$pullAll" {
+		values, ok := value.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$pullAll requires an array argument")
+		}
+		return containsValue(values, item), nil
+	}
+
+	cond, isDoc := value.(document)
+	if !isDoc {
+		return valuesEqual(item, value), nil
+	}
+
+	if _, isOperator := isOperatorDocument(cond); isOperator {
+		return matchCondition([]interface{}{item}, true, cond)
+	}
+
+	itemDoc, ok := item.(document)
+	if !ok {
+		return false, nil
+	}
+	return matchDocument(itemDoc, cond)
+}
+
+func containsValue(arr []interface{}, value interface{}) bool {
+	for _, item := range arr {
+		if valuesEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// addNumbers adds two numbers, integers are added as int64 unless both of them are int32
+func addNumbers(a, b interface{}) interface{} {
+	intA, okA := toInt64(a)
+	intB, okB := toInt64(b)
+	if okA && okB {
+		_, isInt32A := a.(int32)
+		_, isInt32B := b.(int32)
+		if isInt32A && isInt32B {
+			return int32(intA + intB)
+		}
+		return intA + intB
+	}
+	return toFloat(a) + toFloat(b)
+}
+
+// upsertDocument generates the document to insert for an upsert operation, the equality conditions of the filter are
+// used as the base of the document, which is the same as mongodb
+func upsertDocument(filter document, update document) (document, error) {
+	base := make(document)
+	for key, cond := range filter {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		if _, isOperator := isOperatorDocument(cond); isOperator {
+			if eq, exists := cond.(document)["$eq"]; exists {
+				if err := setValue(base, key, deepCopy(eq)); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := setValue(base, key, deepCopy(cond)); err != nil {
+			return nil, err
+		}
+	}
+
+	return applyUpdate(base, update, true)
+}
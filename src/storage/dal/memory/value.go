@@ -0,0 +1,582 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// document is the in memory representation of a mongodb document, nested documents are also documents, arrays are
+// []interface{}, date time values are time.Time, other values keeps the type decoded from bson.
+// documents stored in a table are never modified in place, any modification is done on a copy of the document.
+type document = map[string]interface{}
+
+// toDocument converts a document like value (map, struct, bson.D etc.) to the in memory document
+func toDocument(val interface{}) (document, error) {
+	if val == nil {
+		return make(document), nil
+	}
+
+	raw, err := bson.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %T value to document failed, err: %v", val, err)
+	}
+
+	return fromRawDocument(raw)
+}
+
+// toOrderedDocument converts a document like value to bson.D which keeps the order of the elements
+func toOrderedDocument(val interface{}) (bson.D, error) {
+	if val == nil {
+		return bson.D{}, nil
+	}
+
+	raw, err := bson.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %T value to document failed, err: %v", val, err)
+	}
+
+	elements, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(bson.D, len(elements))
+	for idx, element := range elements {
+		value, err := fromRawValue(element.Value())
+		if err != nil {
+			return nil, err
+		}
+		doc[idx] = bson.E{Key: element.Key(), Value: value}
+	}
+	return doc, nil
+}
+
+// toDocuments converts a slice of document like values to in memory documents
+func toDocuments(val interface{}) ([]document, error) {
+	if val == nil {
+		return make([]document, 0), nil
+	}
+
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		doc, err := toDocument(val)
+		if err != nil {
+			return nil, err
+		}
+		return []document{doc}, nil
+	}
+
+	docs := make([]document, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		doc, err := toDocument(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+	return docs, nil
+}
+
+func fromRawDocument(raw bson.Raw) (document, error) {
+	elements, err := raw.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(document, len(elements))
+	for _, element := range elements {
+		value, err := fromRawValue(element.Value())
+		if err != nil {
+			return nil, err
+		}
+		doc[element.Key()] = value
+	}
+	return doc, nil
+}
+
+func fromRawValue(val bson.RawValue) (interface{}, error) {
+	switch val.Type {
+	case bsontype.EmbeddedDocument:
+		return fromRawDocument(val.Document())
+	case bsontype.Array:
+		values, err := val.Array().Values()
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]interface{}, len(values))
+		for idx, item := range values {
+			if arr[idx], err = fromRawValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case bsontype.DateTime:
+		return val.Time(), nil
+	case bsontype.Timestamp:
+		t, _ := val.Timestamp()
+		return time.Unix(int64(t), 0), nil
+	case bsontype.Null, bsontype.Undefined:
+		return nil, nil
+	case bsontype.Int32:
+		return val.Int32(), nil
+	case bsontype.Int64:
+		return val.Int64(), nil
+	case bsontype.Double:
+		return val.Double(), nil
+	case bsontype.String:
+		return val.StringValue(), nil
+	case bsontype.Boolean:
+		return val.Boolean(), nil
+	case bsontype.ObjectID:
+		return val.ObjectID(), nil
+	case bsontype.Regex:
+		pattern, options := val.Regex()
+		return primitive.Regex{Pattern: pattern, Options: options}, nil
+	case bsontype.Binary:
+		subtype, data := val.Binary()
+		return primitive.Binary{Subtype: subtype, Data: data}, nil
+	case bsontype.Decimal128:
+		return val.Decimal128(), nil
+	default:
+		return nil, fmt.Errorf("unsupported bson type %s", val.Type)
+	}
+}
+
+// decodeDocument decodes the in memory document into the result by bson, so that the custom bson unmarshalers of
+// the result type takes effect just like decoding from the mongodb cursor
+func decodeDocument(doc document, result interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+// decodeDocuments decodes the in memory documents into the result, the result must be a pointer of slice
+func decodeDocuments(docs []document, result interface{}) error {
+	resultv := reflect.ValueOf(result)
+	if resultv.Kind() != reflect.Ptr || resultv.Elem().Kind() != reflect.Slice {
+		return errors.New("result argument must be a slice address")
+	}
+
+	elemt := resultv.Elem().Type().Elem()
+	slice := reflect.MakeSlice(resultv.Elem().Type(), 0, len(docs))
+	for _, doc := range docs {
+		elemp := reflect.New(elemt)
+		if err := decodeDocument(doc, elemp.Interface()); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elemp.Elem())
+	}
+	resultv.Elem().Set(slice)
+	return nil
+}
+
+// deepCopy copies the in memory value
+func deepCopy(val interface{}) interface{} {
+	switch v := val.(type) {
+	case document:
+		return copyDocument(v)
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for idx, item := range v {
+			arr[idx] = deepCopy(item)
+		}
+		return arr
+	case bson.D:
+		doc := make(bson.D, len(v))
+		for idx, item := range v {
+			doc[idx] = bson.E{Key: item.Key, Value: deepCopy(item.Value)}
+		}
+		return doc
+	default:
+		return v
+	}
+}
+
+func copyDocument(doc document) document {
+	if doc == nil {
+		return nil
+	}
+	copied := make(document, len(doc))
+	for key, val := range doc {
+		copied[key] = deepCopy(val)
+	}
+	return copied
+}
+
+// lookupValues returns the values of the dot separated field path in the document, when an array is met in the
+// path, the path is resolved for each element of the array, which is the same as mongodb
+func lookupValues(val interface{}, path []string) ([]interface{}, bool) {
+	if len(path) == 0 {
+		return []interface{}{val}, true
+	}
+
+	switch v := val.(type) {
+	case document:
+		child, exists := v[path[0]]
+		if !exists {
+			return nil, false
+		}
+		return lookupValues(child, path[1:])
+	case []interface{}:
+		if idx, err := strconv.Atoi(path[0]); err == nil {
+			if idx >= 0 && idx < len(v) {
+				return lookupValues(v[idx], path[1:])
+			}
+			return nil, false
+		}
+
+		values := make([]interface{}, 0)
+		exists := false
+		for _, item := range v {
+			if _, ok := item.(document); !ok {
+				continue
+			}
+			itemValues, itemExists := lookupValues(item, path)
+			if itemExists {
+				exists = true
+				values = append(values, itemValues...)
+			}
+		}
+		return values, exists
+	default:
+		return nil, false
+	}
+}
+
+// getValue returns the value of the dot separated field path, the values are collected into an array if the path
+// goes through an array, which is the same as the field path expression in mongodb aggregation
+func getValue(doc document, field string) (interface{}, bool) {
+	values, exists := lookupValues(doc, strings.Split(field, "."))
+	if !exists {
+		return nil, false
+	}
+
+	if len(values) == 1 && !pathCrossArray(doc, strings.Split(field, ".")) {
+		return values[0], true
+	}
+	return values, true
+}
+
+func pathCrossArray(val interface{}, path []string) bool {
+	for _, key := range path {
+		switch v := val.(type) {
+		case document:
+			val = v[key]
+		case []interface{}:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// setValue sets the value of the dot separated field path in the document, intermediate documents are created if
+// they do not exist. the document must be a copy that can be modified.
+func setValue(doc document, field string, value interface{}) error {
+	path := strings.Split(field, ".")
+	var current interface{} = doc
+	for idx, key := range path {
+		last := idx == len(path)-1
+		switch v := current.(type) {
+		case document:
+			if last {
+				v[key] = value
+				return nil
+			}
+			child, exists := v[key]
+			if !exists || child == nil {
+				child = make(document)
+				v[key] = child
+			}
+			current = child
+		case []interface{}:
+			pos, err := strconv.Atoi(key)
+			if err != nil || pos < 0 || pos >= len(v) {
+				return fmt.Errorf("cannot set field %s, %s is not a valid array index", field, key)
+			}
+			if last {
+				v[pos] = value
+				return nil
+			}
+			current = v[pos]
+		default:
+			return fmt.Errorf("cannot set field %s, %s is not a document", field, strings.Join(path[:idx], "."))
+		}
+	}
+	return nil
+}
+
+// unsetValue removes the dot separated field path from the document, the document must be a copy
+func unsetValue(doc document, field string) {
+	path := strings.Split(field, ".")
+	var current interface{} = doc
+	for idx, key := range path {
+		v, ok := current.(document)
+		if !ok {
+			return
+		}
+		if idx == len(path)-1 {
+			delete(v, key)
+			return
+		}
+		current = v[key]
+	}
+}
+
+// bson type orders used to compare values of different types, which is the same as mongodb
+const (
+	orderNull = iota + 1
+	orderNumber
+	orderString
+	orderDocument
+	orderArray
+	orderBinary
+	orderObjectID
+	orderBool
+	orderDate
+	orderRegex
+	orderOther
+)
+
+func typeOrder(val interface{}) int {
+	switch val.(type) {
+	case nil:
+		return orderNull
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64,
+		primitive.Decimal128:
+		return orderNumber
+	case string:
+		return orderString
+	case document, bson.D:
+		return orderDocument
+	case []interface{}:
+		return orderArray
+	case primitive.Binary:
+		return orderBinary
+	case primitive.ObjectID:
+		return orderObjectID
+	case bool:
+		return orderBool
+	case time.Time:
+		return orderDate
+	case primitive.Regex:
+		return orderRegex
+	default:
+		return orderOther
+	}
+}
+
+func toFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case primitive.Decimal128:
+		f, _ := strconv.ParseFloat(v.String(), 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+func toInt64(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// compareValues compares two in memory values by the mongodb comparison order
+func compareValues(a, b interface{}) int {
+	orderA, orderB := typeOrder(a), typeOrder(b)
+	if orderA != orderB {
+		return compareInt(orderA, orderB)
+	}
+
+	switch orderA {
+	case orderNull:
+		return 0
+	case orderNumber:
+		intA, okA := toInt64(a)
+		intB, okB := toInt64(b)
+		if okA && okB {
+			return compareInt64(intA, intB)
+		}
+		floatA, floatB := toFloat(a), toFloat(b)
+		switch {
+		case floatA < floatB:
+			return -1
+		case floatA > floatB:
+			return 1
+		default:
+			return 0
+		}
+	case orderString:
+		return strings.Compare(a.(string), b.(string))
+	case orderDocument:
+		return compareDocuments(a, b)
+	case orderArray:
+		arrA, arrB := a.([]interface{}), b.([]interface{})
+		for idx := 0; idx < len(arrA) && idx < len(arrB); idx++ {
+			if cmp := compareValues(arrA[idx], arrB[idx]); cmp != 0 {
+				return cmp
+			}
+		}
+		return compareInt(len(arrA), len(arrB))
+	case orderBinary:
+		return bytes.Compare(a.(primitive.Binary).Data, b.(primitive.Binary).Data)
+	case orderObjectID:
+		idA, idB := a.(primitive.ObjectID), b.(primitive.ObjectID)
+		return bytes.Compare(idA[:], idB[:])
+	case orderBool:
+		boolA, boolB := a.(bool), b.(bool)
+		switch {
+		case boolA == boolB:
+			return 0
+		case !boolA:
+			return -1
+		default:
+			return 1
+		}
+	case orderDate:
+		timeA, timeB := a.(time.Time), b.(time.Time)
+		switch {
+		case timeA.Before(timeB):
+			return -1
+		case timeA.After(timeB):
+			return 1
+		default:
+			return 0
+		}
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func compareDocuments(a, b interface{}) int {
+	docA, docB := orderedElements(a), orderedElements(b)
+	for idx := 0; idx < len(docA) && idx < len(docB); idx++ {
+		if cmp := strings.Compare(docA[idx].Key, docB[idx].Key); cmp != 0 {
+			return cmp
+		}
+		if cmp := compareValues(docA[idx].Value, docB[idx].Value); cmp != 0 {
+			return cmp
+		}
+	}
+	return compareInt(len(docA), len(docB))
+}
+
+// orderedElements returns the elements of the document, map documents are sorted by key since they have no order
+func orderedElements(val interface{}) bson.D {
+	switch v := val.(type) {
+	case bson.D:
+		return v
+	case document:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		elements := make(bson.D, len(keys))
+		for idx, key := range keys {
+			elements[idx] = bson.E{Key: key, Value: v[key]}
+		}
+		return elements
+	default:
+		return bson.D{}
+	}
+}
+
+func compareInt(a, b int) int {
+	return compareInt64(int64(a), int64(b))
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// valuesEqual checks if two in memory values are equal
+func valuesEqual(a, b interface{}) bool {
+	return compareValues(a, b) == 0
+}
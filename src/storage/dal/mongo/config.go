@@ -48,8 +48,17 @@ const (
 	MinimumSocketTimeout = 5
 )
 
+const (
+	// EngineMongo the db engine is mongodb, it's the default engine
+	EngineMongo = "mongodb"
+	// EngineMemory the db engine is the in memory db, it's used for unit tests and local development
+	EngineMemory = "memory"
+)
+
 // Config config
 type Config struct {
+	// Engine is the db engine, default is mongodb
+	Engine        string
 	Connect       string
 	Address       string
 	User          string
@@ -65,6 +74,11 @@ type Config struct {
 	TLSConf       *ssl.TLSClientConfig
 }
 
+// IsMemoryEngine check if the db engine is the in memory db
+func (c Config) IsMemoryEngine() bool {
+	return c.Engine == EngineMemory
+}
+
 // BuildURI return mongo uri according to  https://docs.mongodb.com/manual/reference/connection-string/
 // format example: mongodb://[username:password@]host1[:port1][,host2[:port2],...[,hostN[:portN]]][/[database][?options]]
 func (c Config) BuildURI() string {
@@ -27,6 +27,7 @@ import (
 	"configcenter/src/common/metric"
 	"configcenter/src/common/types"
 	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/memory"
 	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/mongo/local"
 	dbType "configcenter/src/storage/dal/types"
@@ -65,6 +66,10 @@ func ParseConfig(prefix string, configMap map[string]string) (*mongo.Config, err
 	if err != nil {
 		return nil, errors.NewCCError(common.CCErrCommConfMissItem, "can't find mongo configuration")
 	}
+	if config.IsMemoryEngine() {
+		// the in memory db need no connection configuration
+		return &config, nil
+	}
 	if config.Address == "" {
 		lastConfigErr = errors.NewCCError(common.CCErrCommConfMissItem,
 			"Configuration file missing ["+prefix+".host] configuration item")
@@ -92,6 +97,12 @@ func ParseConfig(prefix string, configMap map[string]string) (*mongo.Config, err
 // InitClient init mongodb client
 func InitClient(prefix string, config *mongo.Config) errors.CCErrorCoder {
 	lastInitErr = nil
+	if config.IsMemoryEngine() {
+		blog.Warnf("%s db uses the in memory engine, the data will not be persisted", prefix)
+		dbMap[prefix] = memory.New()
+		return nil
+	}
+
 	var dbErr error
 	dbMap[prefix], dbErr = local.NewMgo(config.GetMongoConf(), time.Minute)
 	if dbErr != nil {
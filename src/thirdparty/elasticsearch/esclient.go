@@ -123,17 +123,21 @@ type EsConfig struct {
 	EsUser          string
 	EsPassword      string
 	TLSClientConfig ssl.TLSClientConfig
+	// Engine is the fulltext search engine type, elasticsearch or local, elasticsearch is used if not set
+	Engine string
 }
 
 // ParseConfigFromKV returns a new config
 func ParseConfigFromKV(prefix string, configMap map[string]string) (EsConfig, error) {
 	fullTextSearch, _ := cc.String(prefix + ".fullTextSearch")
+	engine, _ := cc.String(prefix + ".engine")
 	url, _ := cc.String(prefix + ".url")
 	usr, _ := cc.String(prefix + ".usr")
 	pwd, _ := cc.String(prefix + ".pwd")
 
 	conf := EsConfig{
 		FullTextSearch: fullTextSearch,
+		Engine:         engine,
 		EsUrl:          url,
 		EsUser:         usr,
 		EsPassword:     pwd,
@@ -23,6 +23,7 @@ import (
 	"strings"
 	"time"
 
+	"configcenter/src/common/etcdclient"
 	"configcenter/src/common/ssl"
 	"configcenter/src/common/zkclient"
 	"configcenter/src/storage/dal"
@@ -40,6 +41,8 @@ var Conf *Config
 type Config struct {
 	ZkAddr      string
 	ZkTLS       ssl.TLSClientConfig
+	EtcdAddr    string
+	EtcdTLS     ssl.TLSClientConfig
 	MongoURI    string
 	MongoRsName string
 	RedisConf   redis.Config
@@ -60,6 +63,19 @@ func (c *Config) AddFlags(cmd *cobra.Command) {
 		"the path of TLS key file for zookeeper, corresponding environment variable is ZK_TLS_KEY_FILE")
 	cmd.PersistentFlags().StringVar(&c.ZkTLS.Password, "zk-tls-password", os.Getenv("ZK_TLS_PASSWORD"),
 		"the password of TLS for zookeeper, corresponding environment variable is ZK_TLS_PASSWORD")
+	cmd.PersistentFlags().StringVar(&c.EtcdAddr, "etcd-addr", os.Getenv("ETCD_ADDR"),
+		"the ip address and port for the etcd hosts, separated by comma, corresponding environment variable is ETCD_ADDR")
+	cmd.PersistentFlags().StringVar(&c.EtcdTLS.CAFile, "etcd-tls-ca-file", os.Getenv("ETCD_TLS_CA_FILE"),
+		"the path of TLS CA file for the etcd hosts, corresponding environment variable is ETCD_TLS_CA_FILE")
+	cmd.PersistentFlags().BoolVar(&c.EtcdTLS.InsecureSkipVerify,
+		"etcd-tls-skip-verify", os.Getenv("ETCD_TLS_SKIP_VERIFY") == "true",
+		"the flag of TLS certificate skip verify for etcd, corresponding environment variable is ETCD_TLS_SKIP_VERIFY")
+	cmd.PersistentFlags().StringVar(&c.EtcdTLS.CertFile, "etcd-tls-certfile", os.Getenv("ETCD_TLS_CERT_FILE"),
+		"the path of TLS cert file for etcd, corresponding environment variable is ETCD_TLS_CERT_FILE")
+	cmd.PersistentFlags().StringVar(&c.EtcdTLS.KeyFile, "etcd-tls-keyfile", os.Getenv("ETCD_TLS_KEY_FILE"),
+		"the path of TLS key file for etcd, corresponding environment variable is ETCD_TLS_KEY_FILE")
+	cmd.PersistentFlags().StringVar(&c.EtcdTLS.Password, "etcd-tls-password", os.Getenv("ETCD_TLS_PASSWORD"),
+		"the password of TLS for etcd, corresponding environment variable is ETCD_TLS_PASSWORD")
 	cmd.PersistentFlags().StringVar(&c.MongoURI, "mongo-uri", os.Getenv("MONGO_URI"),
 		"the mongodb URI, eg. mongodb://127.0.0.1:27017/cmdb, corresponding environment variable is MONGO_URI")
 	cmd.PersistentFlags().StringVar(&c.MongoRsName, "mongo-rs-name", "rs0", "mongodb replica set name")
@@ -79,6 +95,7 @@ func (c *Config) AddFlags(cmd *cobra.Command) {
 // Service TODO
 type Service struct {
 	ZkCli   *zkclient.ZkClient
+	EtcdCli *etcdclient.EtcdClient
 	DbProxy dal.RDB
 }
 
@@ -96,6 +113,23 @@ func NewZkService(zkAddr string, tlsConfig *ssl.TLSClientConfig) (*Service, erro
 	return service, nil
 }
 
+// NewEtcdService new service with etcd client
+func NewEtcdService(etcdAddr string, tlsConfig *ssl.TLSClientConfig) (*Service, error) {
+	if etcdAddr == "" {
+		return nil, errors.New("etcd-addr must set via flag or environment variable")
+	}
+	client, err := etcdclient.NewEtcdClient(strings.Split(etcdAddr, ","), tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(); err != nil {
+		return nil, err
+	}
+	return &Service{
+		EtcdCli: client,
+	}, nil
+}
+
 // NewMongoService TODO
 func NewMongoService(mongoURI string, mongoRsName string) (*Service, error) {
 	if mongoURI == "" {
@@ -0,0 +1,172 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"configcenter/src/common/ssl"
+	"configcenter/src/tools/cmdb_ctl/app/config"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewEtcdCommand())
+}
+
+type etcdConf struct {
+	path   string
+	prefix bool
+}
+
+// NewEtcdCommand new etcd command, it's the equivalent of zk command for the etcd regdiscv
+func NewEtcdCommand() *cobra.Command {
+	conf := new(etcdConf)
+
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "etcd operations",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	subCmds := make([]*cobra.Command, 0)
+
+	subCmds = append(subCmds, &cobra.Command{
+		Use:   "ls",
+		Short: "list children of specified etcd path",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEtcdLsCmd(conf)
+		},
+	})
+
+	subCmds = append(subCmds, &cobra.Command{
+		Use:   "get",
+		Short: "get value of specified etcd key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEtcdGetCmd(conf)
+		},
+	})
+
+	delCmd := &cobra.Command{
+		Use:   "del",
+		Short: "delete specified etcd key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEtcdDelCmd(conf)
+		},
+	}
+	delCmd.Flags().BoolVar(&conf.prefix, "prefix", false, "delete all the keys with the etcd-path prefix")
+	subCmds = append(subCmds, delCmd)
+
+	value := new(string)
+	setCmd := &cobra.Command{
+		Use:   "set",
+		Short: "set value of specified etcd key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEtcdSetCmd(conf, *value)
+		},
+	}
+	setCmd.Flags().StringVar(value, "value", "", "the value to be set")
+	_ = setCmd.MarkFlagRequired("value")
+	subCmds = append(subCmds, setCmd)
+
+	for _, subCmd := range subCmds {
+		cmd.AddCommand(subCmd)
+	}
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *etcdConf) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&c.path, "etcd-path", "", "the etcd resource path")
+}
+
+type etcdService struct {
+	service *config.Service
+	path    string
+}
+
+func newEtcdService(etcdAddr string, tlsConfig *ssl.TLSClientConfig, path string) (*etcdService, error) {
+	if path == "" {
+		return nil, errors.New("etcd-path must be set")
+	}
+	service, err := config.NewEtcdService(etcdAddr, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &etcdService{
+		service: service,
+		path:    path,
+	}, nil
+}
+
+func runEtcdLsCmd(c *etcdConf) error {
+	srv, err := newEtcdService(config.Conf.EtcdAddr, &config.Conf.EtcdTLS, c.path)
+	if err != nil {
+		return err
+	}
+	children, err := srv.service.EtcdCli.GetChildren(srv.path)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		fmt.Fprintf(os.Stdout, "%s\n", child)
+	}
+	return nil
+}
+
+func runEtcdGetCmd(c *etcdConf) error {
+	srv, err := newEtcdService(config.Conf.EtcdAddr, &config.Conf.EtcdTLS, c.path)
+	if err != nil {
+		return err
+	}
+	data, err := srv.service.EtcdCli.Get(srv.path)
+	if err != nil {
+		return err
+	}
+	var pretty bytes.Buffer
+	err = json.Indent(&pretty, []byte(data), "", "\t")
+	if err != nil {
+		fmt.Fprintln(os.Stdout, data)
+		return nil
+	}
+	fmt.Fprintln(os.Stdout, pretty.String())
+	return nil
+}
+
+func runEtcdDelCmd(c *etcdConf) error {
+	srv, err := newEtcdService(config.Conf.EtcdAddr, &config.Conf.EtcdTLS, c.path)
+	if err != nil {
+		return err
+	}
+	return srv.service.EtcdCli.Delete(srv.path, c.prefix)
+}
+
+func runEtcdSetCmd(c *etcdConf, value string) error {
+	srv, err := newEtcdService(config.Conf.EtcdAddr, &config.Conf.EtcdTLS, c.path)
+	if err != nil {
+		return err
+	}
+	return srv.service.EtcdCli.Put(srv.path, value)
+}
@@ -43,6 +43,12 @@ const (
 ./tool_ctl limiter ls
 # 配置策略，对url限制请求次数
 ./tool_ctl limiter set --rule='{"rulename":"rule1","appcode":"gse","user":"admin","ip":"","method":"POST","url":"^/api/v3/module/search/[^\\s/]+/[0-9]+/[0-9]+/?$","limit":1000,"ttl":60,"denyall":false}'
+# 配置策略，使用令牌桶对url限流，每秒补充100个令牌，最多允许200个突发请求
+./tool_ctl limiter set --rule='{"rulename":"rule2","appcode":"gse","url":"^/api/v3/findmany/hosts/search/?$","rate":100,"burst":200}'
+# 配置策略，限制每个调用方(appcode+user)同时处理的请求数
+./tool_ctl limiter set --rule='{"rulename":"rule3","appcode":"esb","maxinflight":10,"priority":"low"}'
+# 配置策略，将页面的请求设置为高优先级，后端繁忙时优先处理
+./tool_ctl limiter set --rule='{"rulename":"rule4","appcode":"cc","maxinflight":100,"priority":"high"}'
 # 配置策略，将url直接禁掉
 ./tool_ctl limiter set --rule='{"rulename":"rule1","appcode":"gse","user":"admin","url":"^/api/v3/module/search/[^\\s/]+/[0-9]+/[0-9]+/?$","denyall":true}'
 # 获取某些策略详情
@@ -66,10 +72,17 @@ const (
 | url      | string | 否   | api的url正则表达式                                           |
 | limit    | int64  | 否   | api请求限制总次数                                            |
 | ttl      | int64  | 否   | 策略存活时间，单位为秒                                       |
-| denyall  | bool   | 否   | 是否直接禁掉请求，默认为false，为true时忽略limit和ttl参数    |
+| rate     | float  | 否   | 令牌桶每秒补充的令牌数                                       |
+| burst    | int64  | 否   | 令牌桶容量，即允许的突发请求数，不配置时为rate向上取整       |
+| maxinflight | int64 | 否 | 每个调用方(appcode+user)同时处理的最大请求数                 |
+| priority | string | 否   | 请求的优先级，可选值为high、normal、low，默认为normal        |
+| denyall  | bool   | 否   | 是否直接禁掉请求，默认为false，为true时忽略其它限流参数      |
  
 appcode、user、ip、method、url需要至少配置一项  
-denyall配置为false的情况下，limit和ttl配置才能生效
+denyall配置为false的情况下，limit和ttl、rate和burst、maxinflight配置才能生效，且需要至少配置其中一组  
+limit和ttl需要同时配置，burst需要和rate一起配置  
+priority在apiServer.limiter.maxInFlight配置大于0时生效，后端繁忙时优先处理高优先级的请求，并优先丢弃低优先级的请求  
+命中限流的请求的响应头中会通过X-RateLimit-Rule、X-RateLimit-Limit、X-RateLimit-Remaining返回剩余的配额
 ********************************************************
 		`
 )
@@ -85,7 +98,9 @@ type limiterConf struct {
 
 func (c *limiterConf) addFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&c.rule, "rule", "",
-		`the api limiter rule to set, a json like '{"rulename":"rule1","appcode":"gse","user":"","ip":"","method":"POST","url":"^/api/v3/module/search/[^\\s/]+/[0-9]+/[0-9]+/?$","limit":1000,"ttl":60,"denyall":false}'`)
+		`the api limiter rule to set, a json like '{"rulename":"rule1","appcode":"gse","user":"","ip":"",`+
+			`"method":"POST","url":"^/api/v3/module/search/[^\\s/]+/[0-9]+/[0-9]+/?$","limit":1000,"ttl":60,`+
+			`"rate":0,"burst":0,"maxinflight":0,"priority":"normal","denyall":false}'`)
 	cmd.PersistentFlags().StringVar(&c.rulenames, "rulenames", "",
 		`the api limiter rule names to get or del, multiple names is separated with ',',like 'name1,name2'`)
 }
@@ -0,0 +1,321 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/apimachinery/util"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone/service_mange/zk"
+	headerutil "configcenter/src/common/http/header/util"
+	"configcenter/src/common/metadata"
+	"configcenter/src/tools/cmdb_ctl/app/config"
+
+	yl "github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+const manifestIntro = `
+********************************************************
+示例:
+# 导出所有模型和蓝鲸业务的拓扑到清单文件
+./tool_ctl manifest export --file=topo.yaml --biz-names=蓝鲸
+# 预览清单文件与当前系统的差异
+./tool_ctl manifest plan --file=topo.yaml
+# 预览确认后将清单文件应用到当前系统
+./tool_ctl manifest apply --file=topo.yaml
+********************************************************
+`
+
+func init() {
+	rootCmd.AddCommand(NewManifestCommand())
+}
+
+type manifestConf struct {
+	file     string
+	format   string
+	env      string
+	bizNames []string
+	objIDs   []string
+	yes      bool
+}
+
+// NewManifestCommand new topology manifest command
+func NewManifestCommand() *cobra.Command {
+	conf := new(manifestConf)
+
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "export, plan and apply the declarative topology manifest",
+		Long:  manifestIntro,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the models and business topology to the manifest file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifestExportCmd(conf)
+		},
+	}
+	exportCmd.Flags().StringSliceVar(&conf.bizNames, "biz-names", nil,
+		"the names of the businesses whose topology is exported, separated by comma")
+	exportCmd.Flags().StringSliceVar(&conf.objIDs, "obj-ids", nil,
+		"the ids of the models to export, separated by comma, all the models are exported if not set")
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "show the changes between the manifest file and the live system",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifestPlanCmd(conf)
+		},
+	}
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "apply the changes of the manifest file to the live system",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifestApplyCmd(conf)
+		},
+	}
+	applyCmd.Flags().BoolVarP(&conf.yes, "yes", "y", false, "apply the changes without confirmation")
+
+	cmd.AddCommand(exportCmd, planCmd, applyCmd)
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *manifestConf) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&c.file, "file", "f", "", "the path of the manifest file")
+	cmd.PersistentFlags().StringVar(&c.format, "format", "",
+		"the format of the manifest file, yaml or json, judged by the file extension if not set")
+	cmd.PersistentFlags().StringVarP(&c.env, "environment", "e", "", "the environment for service discovery")
+}
+
+// isJSON returns if the manifest file is in json format, yaml is the default format
+func (c *manifestConf) isJSON() (bool, error) {
+	format := c.format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(c.file), ".")
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return true, nil
+	case "yaml", "yml", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("manifest format %s is invalid, only yaml and json are supported", format)
+	}
+}
+
+func (c *manifestConf) readManifest() (*metadata.Manifest, error) {
+	if c.file == "" {
+		return nil, errors.New("manifest file must be set via file flag")
+	}
+
+	isJSON, err := c.isJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(c.file)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest file %s failed, err: %v", c.file, err)
+	}
+
+	// yaml is converted to json first, so the manifest only needs the json tags
+	if !isJSON {
+		if data, err = yl.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("parse manifest file %s failed, err: %v", c.file, err)
+		}
+	}
+
+	manifest := new(metadata.Manifest)
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest file %s failed, err: %v", c.file, err)
+	}
+	return manifest, nil
+}
+
+func (c *manifestConf) writeManifest(manifest *metadata.Manifest) error {
+	isJSON, err := c.isJSON()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if isJSON {
+		data, err = json.MarshalIndent(manifest, "", "  ")
+	} else {
+		data, err = yl.Marshal(manifest)
+	}
+	if err != nil {
+		return fmt.Errorf("marshal manifest failed, err: %v", err)
+	}
+
+	if c.file == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return ioutil.WriteFile(c.file, data, 0644)
+}
+
+func newManifestClient(c *manifestConf) (apimachinery.ClientSetInterface, error) {
+	client := zk.NewZkClient(config.Conf.ZkAddr, 40*time.Second, &config.Conf.ZkTLS)
+	if err := client.Start(); err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	if err := client.Ping(); err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	serviceDiscovery, err := discovery.NewServiceDiscovery(client, c.env)
+	if err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	apiMachineryConfig := &util.APIMachineryConfig{
+		QPS:       1000,
+		Burst:     2000,
+		TLSConfig: nil,
+	}
+	clientSet, err := apimachinery.NewApiMachinery(apiMachineryConfig, serviceDiscovery)
+	if err != nil {
+		return nil, fmt.Errorf("new api machinery failed, err: %v", err)
+	}
+	return clientSet, nil
+}
+
+func runManifestExportCmd(c *manifestConf) error {
+	clientSet, err := newManifestClient(c)
+	if err != nil {
+		return err
+	}
+
+	header := headerutil.BuildHeader("admin", common.BKDefaultOwnerID)
+	opt := &metadata.ExportManifestOption{ObjectIDs: c.objIDs, BizNames: c.bizNames}
+	manifest, err := clientSet.TopoServer().Manifest().ExportManifest(context.Background(), header, opt)
+	if err != nil {
+		return fmt.Errorf("export manifest failed, err: %v", err)
+	}
+
+	return c.writeManifest(manifest)
+}
+
+func runManifestPlanCmd(c *manifestConf) error {
+	clientSet, err := newManifestClient(c)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = planManifest(c, clientSet)
+	return err
+}
+
+func runManifestApplyCmd(c *manifestConf) error {
+	clientSet, err := newManifestClient(c)
+	if err != nil {
+		return err
+	}
+
+	manifest, plan, err := planManifest(c, clientSet)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Conflicts) > 0 {
+		return fmt.Errorf("manifest has %d conflicts, resolve them before applying", len(plan.Conflicts))
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !c.yes {
+		fmt.Print("apply the changes above? only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			fmt.Println("apply cancelled")
+			return nil
+		}
+	}
+
+	header := headerutil.BuildHeader("admin", common.BKDefaultOwnerID)
+	opt := &metadata.ApplyManifestOption{Manifest: *manifest, Digest: plan.Digest}
+	if _, err := clientSet.TopoServer().Manifest().ApplyManifest(context.Background(), header, opt); err != nil {
+		return fmt.Errorf("apply manifest failed, err: %v", err)
+	}
+
+	fmt.Print(WithGreenColor(fmt.Sprintf("apply complete, %d changes applied", len(plan.Changes))))
+	return nil
+}
+
+// planManifest plans the manifest file against the live system and prints the plan
+func planManifest(c *manifestConf, clientSet apimachinery.ClientSetInterface) (*metadata.Manifest,
+	*metadata.ManifestPlan, error) {
+
+	manifest, err := c.readManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := headerutil.BuildHeader("admin", common.BKDefaultOwnerID)
+	opt := &metadata.PlanManifestOption{Manifest: *manifest}
+	plan, err := clientSet.TopoServer().Manifest().PlanManifest(context.Background(), header, opt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("plan manifest failed, err: %v", err)
+	}
+
+	printManifestPlan(plan)
+	return manifest, plan, nil
+}
+
+func printManifestPlan(plan *metadata.ManifestPlan) {
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case metadata.ManifestActionCreate:
+			fmt.Print(WithGreenColor(fmt.Sprintf("+ %s %s", change.Kind, change.Key)))
+		default:
+			fmt.Print(WithBlueColor(fmt.Sprintf("~ %s %s", change.Kind, change.Key)))
+		}
+
+		for _, field := range change.Fields {
+			oldVal, _ := json.Marshal(field.Old)
+			newVal, _ := json.Marshal(field.New)
+			fmt.Printf("    %s: %s => %s\n", field.Field, oldVal, newVal)
+		}
+	}
+
+	for _, conflict := range plan.Conflicts {
+		fmt.Print(WithRedColor(fmt.Sprintf("! %s %s: %s", conflict.Kind, conflict.Key, conflict.Message)))
+	}
+
+	fmt.Printf("plan: %d changes, %d conflicts, digest: %s\n", len(plan.Changes), len(plan.Conflicts), plan.Digest)
+}
@@ -16,8 +16,20 @@ const vendors = [{
 }, {
   id: '2',
   name: '腾讯云'
+}, {
+  id: '9',
+  name: '阿里云'
+}, {
+  id: '15',
+  name: '华为云'
+}, {
+  id: '19',
+  name: 'OpenStack'
 }]
 
+// 需要填写接入地址的云厂商，OpenStack为Keystone v3的地址
+export const ENDPOINT_REQUIRED_VENDORS = ['19']
+
 export default vendors
 
 export const formatter = function (id) {
@@ -16,6 +16,10 @@
 
 package common
 
+import (
+	"configcenter/src/common"
+)
+
 // ResourcePath TODO
 var ResourcePath string = "/tmp"
 
@@ -27,3 +31,14 @@ const IsSkipLogin = "skiplogin"
 
 // InaccessibleHtml inaccessible html
 const InaccessibleHtml string = "403.html"
+
+// IsLoginWithoutOrganization returns if the login system does not offer the organization info, only blueking login
+// system offers it through esb
+func IsLoginWithoutOrganization(loginVersion string) bool {
+	switch loginVersion {
+	case common.BKOpenSourceLoginPluginVersion, common.BKSkipLoginPluginVersion, common.BKOIDCLoginPluginVersion,
+		common.BKLDAPLoginPluginVersion:
+		return true
+	}
+	return false
+}
@@ -29,6 +29,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/resource/esb"
 	"configcenter/src/web_server/app/options"
+	webCommon "configcenter/src/web_server/common"
 
 	"github.com/gin-gonic/gin"
 )
@@ -36,8 +37,7 @@ import (
 // GetDepartment get department info from paas
 func (lgc *Logics) GetDepartment(c *gin.Context, config *options.Config) (*metadata.DepartmentData,
 	errors.CCErrorCoder) {
-	if config.LoginVersion == common.BKOpenSourceLoginPluginVersion ||
-		config.LoginVersion == common.BKSkipLoginPluginVersion {
+	if webCommon.IsLoginWithoutOrganization(config.LoginVersion) {
 		return &metadata.DepartmentData{}, nil
 	}
 
@@ -61,8 +61,7 @@ func (lgc *Logics) GetDepartment(c *gin.Context, config *options.Config) (*metad
 // GetDepartmentProfile get department profile from paas
 func (lgc *Logics) GetDepartmentProfile(c *gin.Context, config *options.Config) (*metadata.DepartmentProfileData,
 	errors.CCErrorCoder) {
-	if config.LoginVersion == common.BKOpenSourceLoginPluginVersion ||
-		config.LoginVersion == common.BKSkipLoginPluginVersion {
+	if webCommon.IsLoginWithoutOrganization(config.LoginVersion) {
 		return &metadata.DepartmentProfileData{}, nil
 	}
 
@@ -85,8 +84,7 @@ func (lgc *Logics) GetDepartmentProfile(c *gin.Context, config *options.Config)
 // GetAllDepartment get department info from paas
 func (lgc *Logics) GetAllDepartment(c *gin.Context, config *options.Config, orgIDs []int64) (*metadata.DepartmentData,
 	errors.CCErrorCoder) {
-	if config.LoginVersion == common.BKOpenSourceLoginPluginVersion ||
-		config.LoginVersion == common.BKSkipLoginPluginVersion {
+	if webCommon.IsLoginWithoutOrganization(config.LoginVersion) {
 		return &metadata.DepartmentData{}, nil
 	}
 
@@ -19,13 +19,32 @@ package plugins
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 	"configcenter/src/web_server/middleware/user/plugins/manager"
 
 	// register plugins
 	_ "configcenter/src/web_server/middleware/user/plugins/register"
+
+	"github.com/gin-gonic/gin"
 )
 
+// PasswordLoginPlugin is the login plugin that verifies the user name and password submitted by the login page
+type PasswordLoginPlugin interface {
+	// AuthenticateUser verifies the user name and password, and keeps the user in session if they are correct
+	AuthenticateUser(c *gin.Context, userName, password string) *errors.RawErrorInfo
+}
+
+// RedirectLoginPlugin is the login plugin that authenticates the user by redirecting to an identity provider
+type RedirectLoginPlugin interface {
+	// RedirectLogin redirects the user to the identity provider, the user is redirected to the redirect url after
+	// the login is finished
+	RedirectLogin(c *gin.Context, redirectURL string) *errors.RawErrorInfo
+	// LoginCallback handles the request redirected back by the identity provider, keeps the user in session and
+	// returns the redirect url
+	LoginCallback(c *gin.Context) (string, *errors.RawErrorInfo)
+}
+
 // CurrentPlugin get current login plugin
 func CurrentPlugin(version string) metadata.LoginUserPluginInerface {
 	if "" == version {
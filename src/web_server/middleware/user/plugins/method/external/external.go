@@ -0,0 +1,132 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package external defines the helpers shared by the login methods that authenticate users with an external
+// identity provider, such as the group mapping and the login user kept in session.
+package external
+
+import (
+	"encoding/json"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	webCommon "configcenter/src/web_server/common"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionUserKey is the session key of the user authenticated by the identity provider
+const sessionUserKey = "external_login_user"
+
+// GroupMapping maps a group of the identity provider to the cmdb role and supplier account
+type GroupMapping struct {
+	// Group is the group name returned by the identity provider
+	Group string `mapstructure:"group"`
+	// Role is the role of the users in the group
+	Role string `mapstructure:"role"`
+	// OwnerID is the supplier account of the users in the group, default supplier account is used if it is empty
+	OwnerID string `mapstructure:"ownerId"`
+}
+
+// MapGroups returns the role and supplier account of the first group mapping that the user's groups match, the
+// mappings are matched in the configured order. matched is false if none of the mappings matches.
+func MapGroups(groups []string, mappings []GroupMapping) (role string, ownerID string, matched bool) {
+	groupSet := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		groupSet[group] = struct{}{}
+	}
+
+	for _, mapping := range mappings {
+		if _, exists := groupSet[mapping.Group]; !exists {
+			continue
+		}
+
+		ownerID = mapping.OwnerID
+		if ownerID == "" {
+			ownerID = common.BKDefaultOwnerID
+		}
+		return mapping.Role, ownerID, true
+	}
+
+	return "", common.BKDefaultOwnerID, false
+}
+
+// sessionUser is the login user kept in session, the fields of metadata.LoginUserInfo are not all serialized
+type sessionUser struct {
+	Version   string `json:"version"`
+	UserName  string `json:"username"`
+	ChName    string `json:"chname"`
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	OwnerID   string `json:"owner_id"`
+	LoginTime int64  `json:"login_time"`
+}
+
+// SaveUser keeps the user authenticated by the login method of the version in session
+func SaveUser(c *gin.Context, version string, user *metadata.LoginUserInfo) error {
+	data, err := json.Marshal(sessionUser{
+		Version:   version,
+		UserName:  user.UserName,
+		ChName:    user.ChName,
+		Phone:     user.Phone,
+		Email:     user.Email,
+		Role:      user.Role,
+		OwnerID:   user.OnwerUin,
+		LoginTime: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	session := sessions.Default(c)
+	session.Set(sessionUserKey, string(data))
+	return session.Save()
+}
+
+// LoadUser returns the user kept in session by the login method of the version, the user is not logged in if it
+// is authenticated longer than the ttl ago.
+func LoadUser(c *gin.Context, version string, ttl time.Duration) (*metadata.LoginUserInfo, bool) {
+	data, ok := sessions.Default(c).Get(sessionUserKey).(string)
+	if !ok || data == "" {
+		return nil, false
+	}
+
+	user := new(sessionUser)
+	if err := json.Unmarshal([]byte(data), user); err != nil {
+		return nil, false
+	}
+
+	if user.Version != version || user.UserName == "" {
+		return nil, false
+	}
+
+	if time.Since(time.Unix(user.LoginTime, 0)) > ttl {
+		return nil, false
+	}
+
+	return &metadata.LoginUserInfo{
+		UserName: user.UserName,
+		ChName:   user.ChName,
+		Phone:    user.Phone,
+		Email:    user.Email,
+		Role:     user.Role,
+		OnwerUin: user.OwnerID,
+		Language: webCommon.GetLanguageByHTTPRequest(c),
+	}, true
+}
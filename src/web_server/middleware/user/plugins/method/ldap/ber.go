@@ -0,0 +1,233 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ber classes and universal tags used by the ldap protocol
+const (
+	classUniversal   byte = 0x00
+	classApplication byte = 0x40
+	classContext     byte = 0x80
+
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x10
+	tagSet         = 0x11
+
+	// maxPacketSize is the max size of a packet received from the server
+	maxPacketSize = 16 << 20
+)
+
+// packet is a ber encoded element, the constructed element has children instead of value
+type packet struct {
+	class       byte
+	constructed bool
+	tag         int
+	value       []byte
+	children    []*packet
+}
+
+func newPrimitive(class byte, tag int, value []byte) *packet {
+	return &packet{class: class, tag: tag, value: value}
+}
+
+func newConstructed(class byte, tag int, children ...*packet) *packet {
+	return &packet{class: class, constructed: true, tag: tag, children: children}
+}
+
+func newString(value string) *packet {
+	return newPrimitive(classUniversal, tagOctetString, []byte(value))
+}
+
+func newInteger(tag int, value int64) *packet {
+	return newPrimitive(classUniversal, tag, encodeInteger(value))
+}
+
+func newBoolean(value bool) *packet {
+	if value {
+		return newPrimitive(classUniversal, tagBoolean, []byte{0xff})
+	}
+	return newPrimitive(classUniversal, tagBoolean, []byte{0x00})
+}
+
+func newSequence(children ...*packet) *packet {
+	return newConstructed(classUniversal, tagSequence, children...)
+}
+
+// encode returns the ber encoding of the packet, only the tags less than 31 are supported which is enough for ldap
+func (p *packet) encode() []byte {
+	content := p.value
+	identifier := p.class | byte(p.tag)
+	if p.constructed {
+		identifier |= 0x20
+		content = make([]byte, 0)
+		for _, child := range p.children {
+			content = append(content, child.encode()...)
+		}
+	}
+
+	data := []byte{identifier}
+	data = append(data, encodeLength(len(content))...)
+	return append(data, content...)
+}
+
+// int returns the integer value of the packet
+func (p *packet) int() (int64, error) {
+	if p.constructed || len(p.value) == 0 || len(p.value) > 8 {
+		return 0, errors.New("invalid ber integer")
+	}
+
+	value := int64(int8(p.value[0]))
+	for _, b := range p.value[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// str returns the octet string value of the packet
+func (p *packet) str() string {
+	return string(p.value)
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+
+	data := make([]byte, 0)
+	for l := length; l > 0; l >>= 8 {
+		data = append([]byte{byte(l)}, data...)
+	}
+	return append([]byte{0x80 | byte(len(data))}, data...)
+}
+
+func encodeInteger(value int64) []byte {
+	data := []byte{byte(value)}
+	for value > 127 || value < -128 {
+		value >>= 8
+		data = append([]byte{byte(value)}, data...)
+	}
+	return data
+}
+
+// readPacket reads a ber encoded packet from the reader
+func readPacket(r *bufio.Reader) (*packet, error) {
+	identifier, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if identifier&0x1f == 0x1f {
+		return nil, errors.New("ber tag number larger than 30 is not supported")
+	}
+
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return parsePacket(identifier, content)
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if first&0x80 == 0 {
+		return int(first), nil
+	}
+
+	count := int(first & 0x7f)
+	if count == 0 || count > 4 {
+		return 0, fmt.Errorf("unsupported ber length of %d bytes", count)
+	}
+
+	length := 0
+	for i := 0; i < count; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+
+	if length > maxPacketSize {
+		return 0, fmt.Errorf("ber packet size %d exceeds the limit", length)
+	}
+	return length, nil
+}
+
+func parsePacket(identifier byte, content []byte) (*packet, error) {
+	p := &packet{
+		class:       identifier & 0xc0,
+		constructed: identifier&0x20 != 0,
+		tag:         int(identifier & 0x1f),
+	}
+
+	if !p.constructed {
+		p.value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 || content[0]&0x1f == 0x1f {
+			return nil, errors.New("invalid ber packet")
+		}
+
+		length, offset := int(content[1]), 2
+		if content[1]&0x80 != 0 {
+			count := int(content[1] & 0x7f)
+			if count == 0 || count > 4 || len(content) < 2+count {
+				return nil, errors.New("invalid ber length")
+			}
+			length = 0
+			for _, b := range content[2 : 2+count] {
+				length = length<<8 | int(b)
+			}
+			offset += count
+		}
+
+		if length < 0 || len(content) < offset+length {
+			return nil, errors.New("ber packet is truncated")
+		}
+
+		child, err := parsePacket(content[0], content[offset:offset+length])
+		if err != nil {
+			return nil, err
+		}
+		p.children = append(p.children, child)
+		content = content[offset+length:]
+	}
+
+	return p, nil
+}
@@ -0,0 +1,311 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ldap protocol operation tags defined by rfc4511
+const (
+	opBindRequest           = 0
+	opBindResponse          = 1
+	opUnbindRequest         = 2
+	opSearchRequest         = 3
+	opSearchResultEntry     = 4
+	opSearchResultDone      = 5
+	opSearchResultReference = 19
+
+	resultSuccess            = 0
+	resultSizeLimitExceeded  = 4
+	resultInvalidCredentials = 49
+
+	scopeWholeSubtree = 2
+	derefNever        = 0
+)
+
+// resultError is the error result returned by the ldap server
+type resultError struct {
+	code    int64
+	message string
+}
+
+// Error returns the error message
+func (e *resultError) Error() string {
+	return fmt.Sprintf("ldap result code %d: %s", e.code, e.message)
+}
+
+// isInvalidCredentials returns if the error is caused by the wrong dn or password
+func isInvalidCredentials(err error) bool {
+	resultErr, ok := err.(*resultError)
+	return ok && resultErr.code == resultInvalidCredentials
+}
+
+// entry is an entry returned by the search, the attribute names are lower cased
+type entry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// first returns the first value of the attribute
+func (e *entry) first(attr string) string {
+	values := e.attributes[strings.ToLower(attr)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// searchRequest is the subtree search request
+type searchRequest struct {
+	baseDN     string
+	filter     string
+	attributes []string
+	sizeLimit  int64
+}
+
+// conn is a connection to the ldap server, the requests are sent one by one
+type conn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	msgID   int64
+	timeout time.Duration
+}
+
+// dial connects to the ldap server of the url, ldaps scheme uses tls
+func dial(serverURL string, tlsConf *tls.Config, timeout time.Duration) (*conn, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse ldap url %s failed, err: %v", serverURL, err)
+	}
+
+	host := parsed.Host
+	dialer := &net.Dialer{Timeout: timeout}
+	var netConn net.Conn
+	switch parsed.Scheme {
+	case "ldap":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(host, "389")
+		}
+		netConn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if parsed.Port() == "" {
+			host = net.JoinHostPort(host, "636")
+		}
+		if tlsConf == nil {
+			tlsConf = new(tls.Config)
+		}
+		if tlsConf.ServerName == "" && !tlsConf.InsecureSkipVerify {
+			tlsConf = tlsConf.Clone()
+			tlsConf.ServerName = parsed.Hostname()
+		}
+		netConn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConf)
+	default:
+		return nil, fmt.Errorf("unsupported ldap url scheme %s", parsed.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to ldap server %s failed, err: %v", host, err)
+	}
+
+	return &conn{conn: netConn, reader: bufio.NewReader(netConn), timeout: timeout}, nil
+}
+
+// bind authenticates the connection with the dn and password by simple bind, the empty password is rejected so
+// that an unauthenticated bind is never regarded as success
+func (c *conn) bind(dn, password string) error {
+	if password == "" {
+		return &resultError{code: resultInvalidCredentials, message: "empty password"}
+	}
+
+	request := newConstructed(classApplication, opBindRequest,
+		newInteger(tagInteger, 3),
+		newString(dn),
+		newPrimitive(classContext, 0, []byte(password)),
+	)
+
+	msgID, err := c.send(request)
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive(msgID)
+	if err != nil {
+		return err
+	}
+
+	if op.class != classApplication || op.tag != opBindResponse {
+		return fmt.Errorf("unexpected ldap response %d to bind request", op.tag)
+	}
+	return parseResult(op)
+}
+
+// search searches the entries in the subtree of the base dn
+func (c *conn) search(req *searchRequest) ([]*entry, error) {
+	filter, err := compileFilter(req.filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap filter %s, err: %v", req.filter, err)
+	}
+
+	attributes := newSequence()
+	for _, attr := range req.attributes {
+		attributes.children = append(attributes.children, newString(attr))
+	}
+
+	request := newConstructed(classApplication, opSearchRequest,
+		newString(req.baseDN),
+		newInteger(tagEnumerated, scopeWholeSubtree),
+		newInteger(tagEnumerated, derefNever),
+		newInteger(tagInteger, req.sizeLimit),
+		newInteger(tagInteger, int64(c.timeout/time.Second)),
+		newBoolean(false),
+		filter,
+		attributes,
+	)
+
+	msgID, err := c.send(request)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*entry, 0)
+	for {
+		op, err := c.receive(msgID)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.class != classApplication {
+			return nil, errors.New("unexpected ldap search response")
+		}
+
+		switch op.tag {
+		case opSearchResultEntry:
+			e, err := parseEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		case opSearchResultReference:
+			continue
+		case opSearchResultDone:
+			if err := parseResult(op); err != nil {
+				resultErr, ok := err.(*resultError)
+				if ok && resultErr.code == resultSizeLimitExceeded {
+					return entries, nil
+				}
+				return nil, err
+			}
+			return entries, nil
+		default:
+			return nil, fmt.Errorf("unexpected ldap response %d to search request", op.tag)
+		}
+	}
+}
+
+// close unbinds and closes the connection
+func (c *conn) close() {
+	c.send(newPrimitive(classApplication, opUnbindRequest, nil))
+	c.conn.Close()
+}
+
+func (c *conn) send(op *packet) (int64, error) {
+	c.msgID++
+	message := newSequence(newInteger(tagInteger, c.msgID), op)
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	if _, err := c.conn.Write(message.encode()); err != nil {
+		return 0, fmt.Errorf("send ldap request failed, err: %v", err)
+	}
+	return c.msgID, nil
+}
+
+// receive reads the protocol operation of the message with the id
+func (c *conn) receive(msgID int64) (*packet, error) {
+	for {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+
+		message, err := readPacket(c.reader)
+		if err != nil {
+			return nil, fmt.Errorf("read ldap response failed, err: %v", err)
+		}
+
+		if !message.constructed || len(message.children) < 2 {
+			return nil, errors.New("invalid ldap message")
+		}
+
+		id, err := message.children[0].int()
+		if err != nil {
+			return nil, err
+		}
+
+		// the unsolicited notification has message id 0, which means the server is going to close the connection
+		if id == 0 {
+			return nil, errors.New("ldap server sends notice of disconnection")
+		}
+
+		if id == msgID {
+			return message.children[1], nil
+		}
+	}
+}
+
+// parseResult parses the LDAPResult, it returns error if the result code is not success
+func parseResult(op *packet) error {
+	if len(op.children) < 3 {
+		return errors.New("invalid ldap result")
+	}
+
+	code, err := op.children[0].int()
+	if err != nil {
+		return err
+	}
+
+	if code != resultSuccess {
+		return &resultError{code: code, message: op.children[2].str()}
+	}
+	return nil
+}
+
+func parseEntry(op *packet) (*entry, error) {
+	if len(op.children) < 2 {
+		return nil, errors.New("invalid ldap search result entry")
+	}
+
+	e := &entry{dn: op.children[0].str(), attributes: make(map[string][]string)}
+	for _, attr := range op.children[1].children {
+		if len(attr.children) < 2 {
+			return nil, errors.New("invalid ldap search result attribute")
+		}
+
+		name := strings.ToLower(attr.children[0].str())
+		for _, value := range attr.children[1].children {
+			e.attributes[name] = append(e.attributes[name], value.str())
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+)
+
+// configKey is the config key of the ldap login method
+const configKey = "webServer.login.ldap"
+
+// config is the ldap login method config
+type config struct {
+	// URL is the ldap server url, such as ldap://127.0.0.1:389 or ldaps://ldap.example.com
+	URL string `mapstructure:"url"`
+	// BindDN and BindPassword is the service account used to search the users and groups, anonymous search is
+	// used if it is not set
+	BindDN       string `mapstructure:"bindDn"`
+	BindPassword string `mapstructure:"bindPassword"`
+	// BaseDN is the dn that the users are searched in
+	BaseDN string `mapstructure:"baseDn"`
+	// UserFilter is the filter to search the user, %s is replaced by the escaped user name, default is (uid=%s)
+	UserFilter string `mapstructure:"userFilter"`
+	// UsernameAttribute is the attribute of the user name, default is uid
+	UsernameAttribute    string `mapstructure:"usernameAttribute"`
+	DisplayNameAttribute string `mapstructure:"displayNameAttribute"`
+	EmailAttribute       string `mapstructure:"emailAttribute"`
+	PhoneAttribute       string `mapstructure:"phoneAttribute"`
+	// GroupBaseDN is the dn that the groups are searched in, default is the base dn
+	GroupBaseDN string `mapstructure:"groupBaseDn"`
+	// GroupFilter is the filter to search the user's groups, %s is replaced by the escaped user dn,
+	// default is (member=%s)
+	GroupFilter string `mapstructure:"groupFilter"`
+	// GroupNameAttribute is the attribute of the group name that is matched with the group mappings, default is cn
+	GroupNameAttribute string                  `mapstructure:"groupNameAttribute"`
+	GroupMappings      []external.GroupMapping `mapstructure:"groupMappings"`
+	// RequireGroupMapping marks only the users whose groups match a group mapping are allowed to login
+	RequireGroupMapping bool `mapstructure:"requireGroupMapping"`
+	// TimeoutSeconds is the timeout of the requests to the ldap server, default is 10 seconds
+	TimeoutSeconds int `mapstructure:"timeoutSeconds"`
+	// UserListLimit is the max count of the users returned by the user list, default is 1000
+	UserListLimit int64 `mapstructure:"userListLimit"`
+
+	tlsConf *tls.Config
+}
+
+// loadConfig loads the ldap config, the defaults are set for the optional items
+func loadConfig() (*config, error) {
+	conf := new(config)
+	if err := cc.UnmarshalKey(configKey, conf); err != nil {
+		return nil, fmt.Errorf("parse %s config failed, err: %v", configKey, err)
+	}
+
+	if cc.IsExist(configKey + ".tls") {
+		tlsConf, err := cc.GetClientTLSConfig(configKey + ".tls")
+		if err != nil {
+			return nil, fmt.Errorf("get ldap tls config failed, err: %v", err)
+		}
+		conf.tlsConf = tlsConf
+	}
+
+	return conf, conf.complete()
+}
+
+// complete validates the config and sets the defaults
+func (c *config) complete() error {
+	if c.URL == "" || c.BaseDN == "" {
+		return errors.New("ldap url and baseDn must be set")
+	}
+
+	if c.UserFilter == "" {
+		c.UserFilter = "(uid=%s)"
+	}
+	if c.UsernameAttribute == "" {
+		c.UsernameAttribute = "uid"
+	}
+	if c.DisplayNameAttribute == "" {
+		c.DisplayNameAttribute = "cn"
+	}
+	if c.EmailAttribute == "" {
+		c.EmailAttribute = "mail"
+	}
+	if c.PhoneAttribute == "" {
+		c.PhoneAttribute = "telephoneNumber"
+	}
+	if c.GroupBaseDN == "" {
+		c.GroupBaseDN = c.BaseDN
+	}
+	if c.GroupFilter == "" {
+		c.GroupFilter = "(member=%s)"
+	}
+	if c.GroupNameAttribute == "" {
+		c.GroupNameAttribute = "cn"
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = 10
+	}
+	if c.UserListLimit <= 0 {
+		c.UserListLimit = 1000
+	}
+
+	return nil
+}
+
+func (c *config) timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
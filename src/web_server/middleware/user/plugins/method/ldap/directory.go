@@ -0,0 +1,158 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+)
+
+// connect connects to the ldap server and binds the service account if it is set
+func connect(conf *config) (*conn, error) {
+	c, err := dial(conf.URL, conf.tlsConf, conf.timeout())
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.BindDN != "" {
+		if err := c.bind(conf.BindDN, conf.BindPassword); err != nil {
+			c.close()
+			return nil, fmt.Errorf("bind ldap service account failed, err: %v", err)
+		}
+	}
+
+	return c, nil
+}
+
+// authenticate searches the user by the user name, binds with the user's dn and password to verify the password,
+// then searches the user's groups to map the role and supplier account.
+func authenticate(conf *config, userName, password string) (*metadata.LoginUserInfo, *errors.RawErrorInfo, error) {
+	c, err := connect(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer c.close()
+
+	users, err := c.search(&searchRequest{
+		baseDN: conf.BaseDN,
+		filter: fmt.Sprintf(conf.UserFilter, escapeFilterValue(userName)),
+		attributes: []string{conf.UsernameAttribute, conf.DisplayNameAttribute, conf.EmailAttribute,
+			conf.PhoneAttribute},
+		sizeLimit: 2,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("search ldap user failed, err: %v", err)
+	}
+
+	// the user name must identify exactly one user, otherwise it can not be decided whose password to verify
+	if len(users) != 1 {
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebUsernamePasswdWrong}, nil
+	}
+	ldapUser := users[0]
+
+	if err := c.bind(ldapUser.dn, password); err != nil {
+		if isInvalidCredentials(err) {
+			return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebUsernamePasswdWrong}, nil
+		}
+		return nil, nil, fmt.Errorf("bind ldap user failed, err: %v", err)
+	}
+
+	// rebind the service account to search the groups, the user may not have the permission to read them
+	if conf.BindDN != "" {
+		if err := c.bind(conf.BindDN, conf.BindPassword); err != nil {
+			return nil, nil, fmt.Errorf("bind ldap service account failed, err: %v", err)
+		}
+	}
+
+	groupEntries, err := c.search(&searchRequest{
+		baseDN:     conf.GroupBaseDN,
+		filter:     fmt.Sprintf(conf.GroupFilter, escapeFilterValue(ldapUser.dn)),
+		attributes: []string{conf.GroupNameAttribute},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("search ldap user groups failed, err: %v", err)
+	}
+
+	groups := make([]string, 0, len(groupEntries))
+	for _, group := range groupEntries {
+		if name := group.first(conf.GroupNameAttribute); name != "" {
+			groups = append(groups, name)
+		}
+	}
+
+	role, ownerID, matched := external.MapGroups(groups, conf.GroupMappings)
+	if !matched && conf.RequireGroupMapping {
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginGroupNotAllowed}, nil
+	}
+
+	name := ldapUser.first(conf.UsernameAttribute)
+	if name == "" {
+		name = userName
+	}
+	chName := ldapUser.first(conf.DisplayNameAttribute)
+	if chName == "" {
+		chName = name
+	}
+
+	return &metadata.LoginUserInfo{
+		UserName: name,
+		ChName:   chName,
+		Phone:    ldapUser.first(conf.PhoneAttribute),
+		Email:    ldapUser.first(conf.EmailAttribute),
+		Role:     role,
+		OnwerUin: ownerID,
+	}, nil, nil
+}
+
+// listUsers lists the users in the base dn that match the user filter
+func listUsers(conf *config) ([]*metadata.LoginSystemUserInfo, error) {
+	c, err := connect(conf)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	entries, err := c.search(&searchRequest{
+		baseDN:     conf.BaseDN,
+		filter:     fmt.Sprintf(conf.UserFilter, "*"),
+		attributes: []string{conf.UsernameAttribute, conf.DisplayNameAttribute},
+		sizeLimit:  conf.UserListLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search ldap users failed, err: %v", err)
+	}
+
+	users := make([]*metadata.LoginSystemUserInfo, 0, len(entries))
+	for _, e := range entries {
+		name := e.first(conf.UsernameAttribute)
+		if name == "" {
+			continue
+		}
+
+		chName := e.first(conf.DisplayNameAttribute)
+		if chName == "" {
+			chName = name
+		}
+		users = append(users, &metadata.LoginSystemUserInfo{CnName: chName, EnName: name})
+	}
+
+	return users, nil
+}
@@ -0,0 +1,268 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+)
+
+// stubEntry is an entry of the stub ldap directory
+type stubEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// stubServer is a local ldap server that serves the bind and search requests with the entries
+type stubServer struct {
+	listener net.Listener
+	entries  []stubEntry
+}
+
+func newStubServer(t *testing.T, entries []stubEntry) *stubServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed, err: %v", err)
+	}
+
+	s := &stubServer{listener: listener, entries: entries}
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(netConn)
+		}
+	}()
+	return s
+}
+
+func (s *stubServer) serve(netConn net.Conn) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	for {
+		message, err := readPacket(reader)
+		if err != nil || len(message.children) < 2 {
+			return
+		}
+		msgID, _ := message.children[0].int()
+		op := message.children[1]
+
+		reply := func(resp *packet) {
+			netConn.Write(newSequence(newInteger(tagInteger, msgID), resp).encode())
+		}
+		result := func(tag int, code int64) *packet {
+			return newConstructed(classApplication, tag, newInteger(tagEnumerated, code), newString(""),
+				newString(""))
+		}
+
+		switch op.tag {
+		case opBindRequest:
+			dn, password := op.children[1].str(), op.children[2].str()
+			code := int64(resultInvalidCredentials)
+			for _, e := range s.entries {
+				if e.dn == dn && e.password != "" && e.password == password {
+					code = resultSuccess
+				}
+			}
+			reply(result(opBindResponse, code))
+		case opSearchRequest:
+			baseDN, filter := op.children[0].str(), op.children[6]
+			for _, e := range s.entries {
+				if !strings.HasSuffix(e.dn, baseDN) || !matchFilter(filter, e.attrs) {
+					continue
+				}
+				attrs := newSequence()
+				for name, values := range e.attrs {
+					vals := newConstructed(classUniversal, tagSet)
+					for _, value := range values {
+						vals.children = append(vals.children, newString(value))
+					}
+					attrs.children = append(attrs.children, newSequence(newString(name), vals))
+				}
+				reply(newConstructed(classApplication, opSearchResultEntry, newString(e.dn), attrs))
+			}
+			reply(result(opSearchResultDone, resultSuccess))
+		case opUnbindRequest:
+			return
+		}
+	}
+}
+
+// matchFilter evaluates the and, or, not, equality, present and substrings filters on the attributes
+func matchFilter(filter *packet, attrs map[string][]string) bool {
+	switch filter.tag {
+	case filterAnd, filterOr:
+		for _, child := range filter.children {
+			matched := matchFilter(child, attrs)
+			if filter.tag == filterAnd && !matched {
+				return false
+			}
+			if filter.tag == filterOr && matched {
+				return true
+			}
+		}
+		return filter.tag == filterAnd
+	case filterNot:
+		return !matchFilter(filter.children[0], attrs)
+	case filterPresent:
+		return len(attrs[filter.str()]) > 0
+	case filterEqualityMatch:
+		for _, value := range attrs[filter.children[0].str()] {
+			if value == filter.children[1].str() {
+				return true
+			}
+		}
+	case filterSubstrings:
+		for _, value := range attrs[filter.children[0].str()] {
+			matched := true
+			for _, sub := range filter.children[1].children {
+				switch sub.tag {
+				case substringInitial:
+					matched = matched && strings.HasPrefix(value, sub.str())
+				case substringFinal:
+					matched = matched && strings.HasSuffix(value, sub.str())
+				default:
+					matched = matched && strings.Contains(value, sub.str())
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := newStubServer(t, []stubEntry{
+		{dn: "cn=admin,dc=example,dc=com", password: "adminpwd"},
+		{dn: "uid=alice,ou=people,dc=example,dc=com", password: "alicepwd", attrs: map[string][]string{
+			"objectclass": {"person"}, "uid": {"alice"}, "cn": {"Alice"}, "mail": {"alice@example.com"}}},
+		{dn: "uid=bob,ou=people,dc=example,dc=com", password: "bobpwd", attrs: map[string][]string{
+			"objectclass": {"person"}, "uid": {"bob"}, "cn": {"Bob"}}},
+		{dn: "cn=ops,ou=groups,dc=example,dc=com", attrs: map[string][]string{
+			"cn": {"ops"}, "member": {"uid=alice,ou=people,dc=example,dc=com"}}},
+	})
+	defer server.listener.Close()
+
+	conf := &config{
+		URL:           "ldap://" + server.listener.Addr().String(),
+		BindDN:        "cn=admin,dc=example,dc=com",
+		BindPassword:  "adminpwd",
+		BaseDN:        "ou=people,dc=example,dc=com",
+		UserFilter:    "(&(objectclass=person)(uid=%s))",
+		GroupBaseDN:   "ou=groups,dc=example,dc=com",
+		GroupMappings: []external.GroupMapping{{Group: "ops", Role: "operator", OwnerID: "1"}},
+	}
+	if err := conf.complete(); err != nil {
+		t.Fatalf("complete config failed, err: %v", err)
+	}
+
+	loginUser, rawErr, err := authenticate(conf, "alice", "alicepwd")
+	if err != nil || rawErr != nil {
+		t.Fatalf("authenticate alice failed, rawErr: %v, err: %v", rawErr, err)
+	}
+	if loginUser.UserName != "alice" || loginUser.ChName != "Alice" || loginUser.Email != "alice@example.com" ||
+		loginUser.Role != "operator" || loginUser.OnwerUin != "1" {
+		t.Errorf("login user is not as expected, actual: %+v", loginUser)
+	}
+
+	cases := []struct {
+		userName string
+		password string
+		errCode  int
+	}{
+		{userName: "alice", password: "wrong", errCode: common.CCErrWebUsernamePasswdWrong},
+		{userName: "alice", password: "", errCode: common.CCErrWebUsernamePasswdWrong},
+		{userName: "carol", password: "alicepwd", errCode: common.CCErrWebUsernamePasswdWrong},
+		{userName: "*", password: "alicepwd", errCode: common.CCErrWebUsernamePasswdWrong},
+		{userName: "alice)(uid=*", password: "alicepwd", errCode: common.CCErrWebUsernamePasswdWrong},
+	}
+	for _, c := range cases {
+		_, rawErr, err := authenticate(conf, c.userName, c.password)
+		if err != nil || rawErr == nil || rawErr.ErrCode != c.errCode {
+			t.Errorf("authenticate %s should fail with %d, rawErr: %v, err: %v", c.userName, c.errCode, rawErr, err)
+		}
+	}
+
+	loginUser, rawErr, err = authenticate(conf, "bob", "bobpwd")
+	if err != nil || rawErr != nil || loginUser.Role != "" || loginUser.OnwerUin != common.BKDefaultOwnerID {
+		t.Errorf("authenticate bob without group is not as expected, user: %+v, rawErr: %v, err: %v", loginUser,
+			rawErr, err)
+	}
+
+	conf.RequireGroupMapping = true
+	_, rawErr, err = authenticate(conf, "bob", "bobpwd")
+	if err != nil || rawErr == nil || rawErr.ErrCode != common.CCErrWebLoginGroupNotAllowed {
+		t.Errorf("bob without mapped group should not be allowed, rawErr: %v, err: %v", rawErr, err)
+	}
+
+	users, err := listUsers(conf)
+	if err != nil || len(users) != 2 {
+		t.Errorf("list users is not as expected, users: %v, err: %v", users, err)
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	attrs := map[string][]string{"uid": {"alice"}, "cn": {"Alice Smith"}, "mail": {"a(1)@example.com"}}
+	cases := []struct {
+		filter  string
+		matched bool
+	}{
+		{filter: "(uid=alice)", matched: true},
+		{filter: "uid=alice", matched: true},
+		{filter: "(&(uid=alice)(cn=Alice*))", matched: true},
+		{filter: "(|(uid=bob)(cn=*Smith))", matched: true},
+		{filter: "(!(uid=alice))", matched: false},
+		{filter: "(mail=*)", matched: true},
+		{filter: "(mail=a\\281\\29@example.com)", matched: true},
+		{filter: "(uid=" + escapeFilterValue("ali*") + ")", matched: false},
+	}
+
+	for _, c := range cases {
+		p, err := compileFilter(c.filter)
+		if err != nil {
+			t.Errorf("compile filter %s failed, err: %v", c.filter, err)
+			continue
+		}
+
+		// decode the encoded filter to make sure the encoding is valid
+		decoded, err := readPacket(bufio.NewReader(strings.NewReader(string(p.encode()))))
+		if err != nil {
+			t.Errorf("decode filter %s failed, err: %v", c.filter, err)
+			continue
+		}
+
+		if matchFilter(decoded, attrs) != c.matched {
+			t.Errorf("filter %s matched should be %v", c.filter, c.matched)
+		}
+	}
+
+	for _, filter := range []string{"(uid=alice", "(&)", "(uid:dn:=alice)", "(=alice)", "(uid=\\2)"} {
+		if _, err := compileFilter(filter); err == nil {
+			t.Errorf("compile invalid filter %s should fail", filter)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// filter choice tags defined by rfc4511
+const (
+	filterAnd            = 0
+	filterOr             = 1
+	filterNot            = 2
+	filterEqualityMatch  = 3
+	filterSubstrings     = 4
+	filterGreaterOrEqual = 5
+	filterLessOrEqual    = 6
+	filterPresent        = 7
+	filterApproxMatch    = 8
+
+	substringInitial = 0
+	substringAny     = 1
+	substringFinal   = 2
+)
+
+// escapeFilterValue escapes the special characters of the value used in a search filter, so that the user input
+// can not change the filter
+func escapeFilterValue(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\', '*', '(', ')', 0:
+			builder.WriteString(fmt.Sprintf("\\%02x", c))
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// compileFilter compiles the string representation of a search filter defined by rfc4515 to ber packet,
+// the extensible match is not supported.
+func compileFilter(filter string) (*packet, error) {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+
+	p, pos, err := parseFilter(filter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if pos != len(filter) {
+		return nil, fmt.Errorf("unexpected content after filter at %d", pos)
+	}
+	return p, nil
+}
+
+// parseFilter parses the filter starting with "(" at pos, and returns the position after the closing ")"
+func parseFilter(filter string, pos int) (*packet, int, error) {
+	if pos >= len(filter) || filter[pos] != '(' {
+		return nil, pos, fmt.Errorf("filter should start with ( at %d", pos)
+	}
+	pos++
+
+	if pos >= len(filter) {
+		return nil, pos, fmt.Errorf("filter is truncated")
+	}
+
+	switch filter[pos] {
+	case '&', '|':
+		tag := filterAnd
+		if filter[pos] == '|' {
+			tag = filterOr
+		}
+		pos++
+
+		children := make([]*packet, 0)
+		for pos < len(filter) && filter[pos] == '(' {
+			child, next, err := parseFilter(filter, pos)
+			if err != nil {
+				return nil, next, err
+			}
+			children = append(children, child)
+			pos = next
+		}
+
+		if len(children) == 0 {
+			return nil, pos, fmt.Errorf("empty filter set at %d", pos)
+		}
+		return closeFilter(filter, pos, newConstructed(classContext, tag, children...))
+	case '!':
+		child, next, err := parseFilter(filter, pos+1)
+		if err != nil {
+			return nil, next, err
+		}
+		return closeFilter(filter, next, newConstructed(classContext, filterNot, child))
+	}
+
+	end := strings.IndexByte(filter[pos:], ')')
+	if end < 0 {
+		return nil, pos, fmt.Errorf("filter is not closed at %d", pos)
+	}
+
+	item, err := parseFilterItem(filter[pos : pos+end])
+	if err != nil {
+		return nil, pos, err
+	}
+	return item, pos + end + 1, nil
+}
+
+func closeFilter(filter string, pos int, p *packet) (*packet, int, error) {
+	if pos >= len(filter) || filter[pos] != ')' {
+		return nil, pos, fmt.Errorf("filter is not closed at %d", pos)
+	}
+	return p, pos + 1, nil
+}
+
+// parseFilterItem parses the simple filter item such as "uid=alice", "cn>=a", "mail=*" and "cn=a*b*"
+func parseFilterItem(item string) (*packet, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("invalid filter item %s", item)
+	}
+
+	attr, value, tag := item[:eq], item[eq+1:], filterEqualityMatch
+	switch attr[len(attr)-1] {
+	case '>':
+		attr, tag = attr[:len(attr)-1], filterGreaterOrEqual
+	case '<':
+		attr, tag = attr[:len(attr)-1], filterLessOrEqual
+	case '~':
+		attr, tag = attr[:len(attr)-1], filterApproxMatch
+	case ':':
+		return nil, fmt.Errorf("extensible match filter %s is not supported", item)
+	}
+
+	if attr == "" {
+		return nil, fmt.Errorf("invalid filter item %s", item)
+	}
+
+	if tag == filterEqualityMatch && value == "*" {
+		return newPrimitive(classContext, filterPresent, []byte(attr)), nil
+	}
+
+	if tag == filterEqualityMatch && strings.Contains(value, "*") {
+		return parseSubstrings(attr, value)
+	}
+
+	unescaped, err := unescapeFilterValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return newConstructed(classContext, tag, newString(attr), newString(unescaped)), nil
+}
+
+func parseSubstrings(attr, value string) (*packet, error) {
+	parts := strings.Split(value, "*")
+	substrings := newSequence()
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		unescaped, err := unescapeFilterValue(part)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := substringAny
+		switch i {
+		case 0:
+			tag = substringInitial
+		case len(parts) - 1:
+			tag = substringFinal
+		}
+		substrings.children = append(substrings.children, newPrimitive(classContext, tag, []byte(unescaped)))
+	}
+
+	return newConstructed(classContext, filterSubstrings, newString(attr), substrings), nil
+}
+
+// unescapeFilterValue converts the "\XX" escaped characters of the filter value
+func unescapeFilterValue(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			builder.WriteByte(value[i])
+			continue
+		}
+
+		if i+3 > len(value) {
+			return "", fmt.Errorf("invalid escaped filter value %s", value)
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escaped filter value %s", value)
+		}
+		builder.Write(decoded)
+		i += 2
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package ldap defines the ldap login method, the user name and password submitted by the login page are verified
+// by binding to the ldap directory, and the role and supplier account are mapped from the user's groups.
+package ldap
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/metadata"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "ldap login system",
+		Version:    common.BKLDAPLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+// sessionTTL is the time that the user keeps logged in after authenticated by the ldap directory
+const sessionTTL = 24 * time.Hour
+
+type user struct{}
+
+// LoginUser user login
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo,
+	bool) {
+
+	return external.LoadUser(c, common.BKLDAPLoginPluginVersion, sessionTTL)
+}
+
+// GetLoginUrl get login url, which is the login page of web server
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	var siteURL string
+	var err error
+	if common.LogoutHTTPSchemeHTTPS == input.HTTPScheme {
+		siteURL, err = cc.String("webServer.site.httpsDomainUrl")
+	} else {
+		siteURL, err = cc.String("webServer.site.domainUrl")
+	}
+	if err != nil {
+		siteURL = ""
+	}
+	siteURL = strings.TrimRight(siteURL, "/")
+	return fmt.Sprintf("%s/login?c_url=%s", siteURL, url.QueryEscape(siteURL+c.Request.URL.String()))
+}
+
+// GetUserList get user list from the ldap directory
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo,
+	*errors.RawErrorInfo) {
+
+	rid := httpheader.GetRid(c.Request.Header)
+	conf, err := loadConfig()
+	if err != nil {
+		blog.Errorf("load ldap config failed, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrCommConfMissItem, Args: []interface{}{configKey}}
+	}
+
+	users, err := listUsers(conf)
+	if err != nil {
+		blog.Errorf("list ldap users failed, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrCommHTTPDoRequestFailed}
+	}
+
+	return users, nil
+}
+
+// AuthenticateUser verifies the user name and password with the ldap directory, the user is kept in session if
+// the password is correct
+func (m *user) AuthenticateUser(c *gin.Context, userName, password string) *errors.RawErrorInfo {
+	rid := httpheader.GetRid(c.Request.Header)
+
+	conf, err := loadConfig()
+	if err != nil {
+		blog.Errorf("load ldap config failed, err: %v, rid: %s", err, rid)
+		return &errors.RawErrorInfo{ErrCode: common.CCErrCommConfMissItem, Args: []interface{}{configKey}}
+	}
+
+	loginUser, rawErr, err := authenticate(conf, userName, password)
+	if err != nil {
+		blog.Errorf("authenticate ldap user %s failed, err: %v, rid: %s", userName, err, rid)
+		return &errors.RawErrorInfo{ErrCode: common.CCErrCommHTTPDoRequestFailed}
+	}
+
+	if rawErr != nil {
+		blog.Errorf("ldap user %s is not allowed to login, errCode: %d, rid: %s", userName, rawErr.ErrCode, rid)
+		return rawErr
+	}
+
+	if err := external.SaveUser(c, common.BKLDAPLoginPluginVersion, loginUser); err != nil {
+		blog.Errorf("save ldap login user failed, err: %v, rid: %s", err, rid)
+		return &errors.RawErrorInfo{ErrCode: common.CCErrCommHTTPDoRequestFailed}
+	}
+
+	return nil
+}
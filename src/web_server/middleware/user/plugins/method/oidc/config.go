@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+)
+
+const (
+	// configKey is the config key of the oidc login method
+	configKey = "webServer.login.oidc"
+	// callbackPath is the path of the web server that the provider redirects the user to after authorization
+	callbackPath = "/login/oidc/callback"
+)
+
+// config is the oidc login method config
+type config struct {
+	// Issuer is the issuer url of the provider, the provider metadata is discovered from it
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"clientId"`
+	ClientSecret string `mapstructure:"clientSecret"`
+	// RedirectURL is the callback url registered in the provider, default is the site url with callback path
+	RedirectURL string   `mapstructure:"redirectUrl"`
+	Scopes      []string `mapstructure:"scopes"`
+	// UsernameClaim is the id token claim used as the user name, default is preferred_username
+	UsernameClaim    string `mapstructure:"usernameClaim"`
+	DisplayNameClaim string `mapstructure:"displayNameClaim"`
+	EmailClaim       string `mapstructure:"emailClaim"`
+	PhoneClaim       string `mapstructure:"phoneClaim"`
+	// GroupsClaim is the id token claim of the user's groups, default is groups
+	GroupsClaim   string                  `mapstructure:"groupsClaim"`
+	GroupMappings []external.GroupMapping `mapstructure:"groupMappings"`
+	// RequireGroupMapping marks only the users whose groups match a group mapping are allowed to login
+	RequireGroupMapping bool `mapstructure:"requireGroupMapping"`
+	// TimeoutSeconds is the timeout of the requests to the provider, default is 10 seconds
+	TimeoutSeconds int `mapstructure:"timeoutSeconds"`
+}
+
+// loadConfig loads the oidc config, the defaults are set for the optional items
+func loadConfig(siteURL string) (*config, error) {
+	conf := new(config)
+	if err := cc.UnmarshalKey(configKey, conf); err != nil {
+		return nil, fmt.Errorf("parse %s config failed, err: %v", configKey, err)
+	}
+
+	if conf.Issuer == "" || conf.ClientID == "" {
+		return nil, errors.New("oidc issuer and clientId must be set")
+	}
+
+	if conf.RedirectURL == "" {
+		conf.RedirectURL = strings.TrimRight(siteURL, "/") + callbackPath
+	}
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = []string{"openid", "profile", "email"}
+	}
+	if conf.UsernameClaim == "" {
+		conf.UsernameClaim = "preferred_username"
+	}
+	if conf.DisplayNameClaim == "" {
+		conf.DisplayNameClaim = "name"
+	}
+	if conf.EmailClaim == "" {
+		conf.EmailClaim = "email"
+	}
+	if conf.PhoneClaim == "" {
+		conf.PhoneClaim = "phone_number"
+	}
+	if conf.GroupsClaim == "" {
+		conf.GroupsClaim = "groups"
+	}
+	if conf.TimeoutSeconds <= 0 {
+		conf.TimeoutSeconds = 10
+	}
+
+	return conf, nil
+}
+
+var (
+	providerLock sync.Mutex
+	providers    = make(map[string]*provider)
+)
+
+// getProvider returns the cached provider of the issuer, so that the metadata and keys are not fetched every login
+func getProvider(conf *config) (*provider, error) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if p, exists := providers[conf.Issuer]; exists {
+		return p, nil
+	}
+
+	client := &http.Client{Timeout: time.Duration(conf.TimeoutSeconds) * time.Second}
+	if cc.IsExist(configKey + ".tls") {
+		tlsConf, err := cc.GetClientTLSConfig(configKey + ".tls")
+		if err != nil {
+			return nil, fmt.Errorf("get oidc tls config failed, err: %v", err)
+		}
+		client.Transport = &http.Transport{TLSClientConfig: tlsConf, Proxy: http.ProxyFromEnvironment}
+	}
+
+	p := newProvider(conf.Issuer, client)
+	providers[conf.Issuer] = p
+	return p, nil
+}
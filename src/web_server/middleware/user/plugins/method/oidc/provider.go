@@ -0,0 +1,358 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// discoveryDocument is the OpenID provider metadata returned by the discovery endpoint
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse is the response of the token endpoint
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// jsonWebKey is a public key in the json web key set of the provider
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// signingMethods is the signing methods of the id token that are accepted
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// provider is an OpenID provider, its metadata and signing keys are cached after the first use
+type provider struct {
+	issuer string
+	client *http.Client
+
+	lock      sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]interface{}
+}
+
+func newProvider(issuer string, client *http.Client) *provider {
+	return &provider{
+		issuer: strings.TrimRight(issuer, "/"),
+		client: client,
+		keys:   make(map[string]interface{}),
+	}
+}
+
+// discover returns the provider metadata, which must be issued by the configured issuer
+func (p *provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	doc := new(discoveryDocument)
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", doc); err != nil {
+		return nil, fmt.Errorf("get provider metadata failed, err: %v", err)
+	}
+
+	if strings.TrimRight(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("provider issuer %s does not match the configured issuer %s", doc.Issuer, p.issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JwksURI == "" {
+		return nil, errors.New("provider metadata lacks authorization, token or jwks endpoint")
+	}
+
+	p.discovery = doc
+	return doc, nil
+}
+
+// authCodeURL returns the url of the authorization endpoint that the user is redirected to, the code challenge
+// is derived from the verifier with S256 method.
+func (p *provider) authCodeURL(ctx context.Context, conf *config, state, nonce, verifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse authorization endpoint %s failed, err: %v", doc.AuthorizationEndpoint, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", conf.ClientID)
+	query.Set("redirect_uri", conf.RedirectURL)
+	query.Set("scope", strings.Join(conf.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// exchange exchanges the authorization code for the tokens with the code verifier
+func (p *provider) exchange(ctx context.Context, conf *config, code, verifier string) (*tokenResponse, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", conf.RedirectURL)
+	form.Set("client_id", conf.ClientID)
+	form.Set("code_verifier", verifier)
+	if conf.ClientSecret != "" {
+		form.Set("client_secret", conf.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request token endpoint failed, err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read token response failed, err: %v", err)
+	}
+
+	token := new(tokenResponse)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("decode token response failed, status: %d, err: %v", resp.StatusCode, err)
+	}
+
+	if token.Error != "" {
+		return nil, fmt.Errorf("token endpoint returns error %s: %s", token.Error, token.ErrorDescription)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returns status %d", resp.StatusCode)
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("token response lacks id token")
+	}
+
+	return token, nil
+}
+
+// verifyIDToken validates the signature, issuer, audience, expiry and nonce of the id token, and returns its claims
+func (p *provider) verifyIDToken(ctx context.Context, conf *config, rawToken, nonce string) (jwt.MapClaims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: signingMethods}
+	_, err = parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, doc, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token, err: %v", err)
+	}
+
+	if !claims.VerifyIssuer(doc.Issuer, true) {
+		return nil, fmt.Errorf("id token is not issued by %s", doc.Issuer)
+	}
+
+	if !claims.VerifyAudience(conf.ClientID, true) {
+		return nil, fmt.Errorf("id token is not issued to client %s", conf.ClientID)
+	}
+
+	if _, exists := claims["exp"]; !exists {
+		return nil, errors.New("id token lacks expiry")
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("id token nonce does not match")
+	}
+
+	return claims, nil
+}
+
+// signingKey returns the key of the kid, the key set is refreshed if the kid is not found, which happens when the
+// provider rotates its keys. If kid is empty, the only key of the set is used.
+func (p *provider) signingKey(ctx context.Context, doc *discoveryDocument, kid string) (interface{}, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if key, exists := p.findKey(kid); exists {
+		return key, nil
+	}
+
+	keySet := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := p.getJSON(ctx, doc.JwksURI, &keySet); err != nil {
+		return nil, fmt.Errorf("get provider key set failed, err: %v", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+
+	if key, exists := p.findKey(kid); exists {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %s is not found", kid)
+}
+
+func (p *provider) findKey(kid string) (interface{}, bool) {
+	if kid != "" {
+		key, exists := p.keys[kid]
+		return key, exists
+	}
+
+	if len(p.keys) != 1 {
+		return nil, false
+	}
+	for _, key := range p.keys {
+		return key, true
+	}
+	return nil, false
+}
+
+func (p *provider) getJSON(ctx context.Context, target string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returns status %d", target, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(result)
+}
+
+// publicKey converts the json web key to rsa or ecdsa public key
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+// randomString returns a url safe random string, it is used as state, nonce and code verifier
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// codeChallenge returns the S256 code challenge of the code verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,189 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// stubProvider is a local OpenID provider that issues the id token for the authorization code
+type stubProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key failed, err: %v", err)
+	}
+
+	s := &stubProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                s.server.URL,
+			AuthorizationEndpoint: s.server.URL + "/authorize",
+			TokenEndpoint:         s.server.URL + "/token",
+			JwksURI:               s.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jsonWebKey{{
+			Kid: "key1",
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "code1" ||
+			codeChallenge(r.PostForm.Get("code_verifier")) != s.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{"iss": s.server.URL, "aud": "cmdb", "nonce": s.nonce,
+			"exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range s.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key1"
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: idToken})
+	})
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	stub := newStubProvider(t)
+	defer stub.server.Close()
+
+	conf := &config{
+		Issuer:        stub.server.URL,
+		ClientID:      "cmdb",
+		RedirectURL:   "http://cmdb.local/login/oidc/callback",
+		Scopes:        []string{"openid"},
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+		GroupMappings: []external.GroupMapping{{Group: "ops", Role: "operator", OwnerID: "1"}},
+	}
+	p := newProvider(conf.Issuer, stub.server.Client())
+	ctx := context.Background()
+
+	authURL, err := p.authCodeURL(ctx, conf, "state1", "nonce1", "verifier1")
+	if err != nil {
+		t.Fatalf("generate authorization url failed, err: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("state") != "state1" ||
+		query.Get("redirect_uri") != conf.RedirectURL {
+		t.Fatalf("authorization url is invalid: %s", authURL)
+	}
+	stub.challenge = query.Get("code_challenge")
+	stub.nonce = "nonce1"
+	stub.claims = jwt.MapClaims{"preferred_username": "alice", "groups": []string{"dev", "ops"}}
+
+	if _, err := p.exchange(ctx, conf, "code1", "wrong-verifier"); err == nil {
+		t.Fatalf("exchange with wrong verifier should fail")
+	}
+
+	token, err := p.exchange(ctx, conf, "code1", "verifier1")
+	if err != nil {
+		t.Fatalf("exchange code failed, err: %v", err)
+	}
+
+	if _, err := p.verifyIDToken(ctx, conf, token.IDToken, "nonce2"); err == nil {
+		t.Errorf("verify id token with wrong nonce should fail")
+	}
+
+	otherConf := *conf
+	otherConf.ClientID = "other"
+	if _, err := p.verifyIDToken(ctx, &otherConf, token.IDToken, "nonce1"); err == nil {
+		t.Errorf("verify id token issued to other client should fail")
+	}
+
+	claims, err := p.verifyIDToken(ctx, conf, token.IDToken, "nonce1")
+	if err != nil {
+		t.Fatalf("verify id token failed, err: %v", err)
+	}
+
+	loginUser, rawErr := claimsToUser(conf, claims)
+	if rawErr != nil {
+		t.Fatalf("convert claims to user failed, err: %v", rawErr)
+	}
+	if loginUser.UserName != "alice" || loginUser.Role != "operator" || loginUser.OnwerUin != "1" {
+		t.Errorf("login user is not as expected, actual: %+v", loginUser)
+	}
+
+	conf.RequireGroupMapping = true
+	claims["groups"] = "dev,test"
+	if _, rawErr := claimsToUser(conf, claims); rawErr == nil || rawErr.ErrCode != common.CCErrWebLoginGroupNotAllowed {
+		t.Errorf("user without mapped group should not be allowed, err: %v", rawErr)
+	}
+}
+
+func TestVerifyForgedIDToken(t *testing.T) {
+	stub := newStubProvider(t)
+	defer stub.server.Close()
+
+	conf := &config{Issuer: stub.server.URL, ClientID: "cmdb"}
+	p := newProvider(conf.Issuer, stub.server.Client())
+
+	forgedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key failed, err: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"iss": stub.server.URL, "aud": "cmdb",
+		"nonce": "nonce1", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "key1"
+	forged, _ := token.SignedString(forgedKey)
+	if _, err := p.verifyIDToken(context.Background(), conf, forged, "nonce1"); err == nil {
+		t.Errorf("verify id token signed by forged key should fail")
+	}
+
+	hsToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": stub.server.URL, "aud": "cmdb",
+		"nonce": "nonce1", "exp": time.Now().Add(time.Hour).Unix()})
+	hsSigned, _ := hsToken.SignedString([]byte("secret"))
+	if _, err := p.verifyIDToken(context.Background(), conf, hsSigned, "nonce1"); err == nil {
+		t.Errorf("verify id token signed with hmac should fail")
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package oidc defines the OpenID Connect login method, the user is authenticated by the provider with the
+// authorization code flow and PKCE, and the role and supplier account are mapped from the groups claim.
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	httpheader "configcenter/src/common/http/header"
+	"configcenter/src/common/metadata"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+	"configcenter/src/web_server/middleware/user/plugins/method/external"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "oidc login system",
+		Version:    common.BKOIDCLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+const (
+	// sessionTTL is the time that the user keeps logged in after authenticated by the provider
+	sessionTTL = 24 * time.Hour
+	// authStateKey is the session key of the authorization request state
+	authStateKey = "oidc_auth_state"
+	// authStateTTL is the time that the user must finish the authorization in
+	authStateTTL = 10 * time.Minute
+)
+
+// authState is the state of an authorization request kept in session until the provider redirects back
+type authState struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	Verifier    string `json:"verifier"`
+	RedirectURL string `json:"redirect_url"`
+	CreateTime  int64  `json:"create_time"`
+}
+
+type user struct{}
+
+// LoginUser user login
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo,
+	bool) {
+
+	return external.LoadUser(c, common.BKOIDCLoginPluginVersion, sessionTTL)
+}
+
+// GetLoginUrl get login url, which starts the authorization code flow
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	siteURL := getSiteURL(input.HTTPScheme)
+	return fmt.Sprintf("%s/login/oidc?c_url=%s", siteURL, url.QueryEscape(siteURL+c.Request.URL.String()))
+}
+
+// GetUserList get user list, the provider does not offer the user list, so only the current user is returned
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo,
+	*errors.RawErrorInfo) {
+
+	users := make([]*metadata.LoginSystemUserInfo, 0)
+	if loginUser, ok := external.LoadUser(c, common.BKOIDCLoginPluginVersion, sessionTTL); ok {
+		users = append(users, &metadata.LoginSystemUserInfo{CnName: loginUser.ChName, EnName: loginUser.UserName})
+	}
+	return users, nil
+}
+
+// RedirectLogin redirects the user to the authorization endpoint of the provider, the user is redirected to the
+// redirect url after the login is finished.
+func (m *user) RedirectLogin(c *gin.Context, redirectURL string) *errors.RawErrorInfo {
+	rid := httpheader.GetRid(c.Request.Header)
+
+	conf, err := loadConfig(getSiteURL(common.LogoutHTTPSchemeHTTP))
+	if err != nil {
+		blog.Errorf("load oidc config failed, err: %v, rid: %s", err, rid)
+		return loginFailedErr(err)
+	}
+
+	p, err := getProvider(conf)
+	if err != nil {
+		blog.Errorf("get oidc provider failed, err: %v, rid: %s", err, rid)
+		return loginFailedErr(err)
+	}
+
+	state := &authState{RedirectURL: redirectURL, CreateTime: time.Now().Unix()}
+	for _, field := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		if *field, err = randomString(); err != nil {
+			blog.Errorf("generate oidc auth state failed, err: %v, rid: %s", err, rid)
+			return loginFailedErr(err)
+		}
+	}
+
+	authURL, err := p.authCodeURL(c.Request.Context(), conf, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		blog.Errorf("generate oidc authorization url failed, err: %v, rid: %s", err, rid)
+		return loginFailedErr(err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return loginFailedErr(err)
+	}
+
+	session := sessions.Default(c)
+	session.Set(authStateKey, string(data))
+	if err := session.Save(); err != nil {
+		blog.Errorf("save oidc auth state failed, err: %v, rid: %s", err, rid)
+		return loginFailedErr(err)
+	}
+
+	c.Redirect(302, authURL)
+	return nil
+}
+
+// LoginCallback finishes the authorization code flow when the provider redirects back, the id token is validated
+// and the user is kept in session. It returns the url that the user is redirected to.
+func (m *user) LoginCallback(c *gin.Context) (string, *errors.RawErrorInfo) {
+	rid := httpheader.GetRid(c.Request.Header)
+
+	session := sessions.Default(c)
+	stateData, _ := session.Get(authStateKey).(string)
+	session.Delete(authStateKey)
+	if err := session.Save(); err != nil {
+		blog.Warnf("delete oidc auth state failed, err: %v, rid: %s", err, rid)
+	}
+
+	state := new(authState)
+	if err := json.Unmarshal([]byte(stateData), state); err != nil || state.State == "" {
+		blog.Errorf("oidc auth state is not found in session, rid: %s", rid)
+		return "", loginFailedErr(fmt.Errorf("authorization request is not found"))
+	}
+
+	if time.Since(time.Unix(state.CreateTime, 0)) > authStateTTL {
+		return "", loginFailedErr(fmt.Errorf("authorization request is expired"))
+	}
+
+	if c.Query("state") != state.State {
+		blog.Errorf("oidc callback state does not match, rid: %s", rid)
+		return "", loginFailedErr(fmt.Errorf("state does not match"))
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		blog.Errorf("oidc provider returns error %s: %s, rid: %s", errCode, c.Query("error_description"), rid)
+		return "", loginFailedErr(fmt.Errorf("%s: %s", errCode, c.Query("error_description")))
+	}
+
+	conf, err := loadConfig(getSiteURL(common.LogoutHTTPSchemeHTTP))
+	if err != nil {
+		blog.Errorf("load oidc config failed, err: %v, rid: %s", err, rid)
+		return "", loginFailedErr(err)
+	}
+
+	p, err := getProvider(conf)
+	if err != nil {
+		blog.Errorf("get oidc provider failed, err: %v, rid: %s", err, rid)
+		return "", loginFailedErr(err)
+	}
+
+	token, err := p.exchange(c.Request.Context(), conf, c.Query("code"), state.Verifier)
+	if err != nil {
+		blog.Errorf("exchange oidc authorization code failed, err: %v, rid: %s", err, rid)
+		return "", loginFailedErr(err)
+	}
+
+	claims, err := p.verifyIDToken(c.Request.Context(), conf, token.IDToken, state.Nonce)
+	if err != nil {
+		blog.Errorf("verify oidc id token failed, err: %v, rid: %s", err, rid)
+		return "", loginFailedErr(err)
+	}
+
+	loginUser, rawErr := claimsToUser(conf, claims)
+	if rawErr != nil {
+		blog.Errorf("oidc user %s is not allowed to login, errCode: %d, rid: %s",
+			claimString(claims, conf.UsernameClaim), rawErr.ErrCode, rid)
+		return "", rawErr
+	}
+
+	if err := external.SaveUser(c, common.BKOIDCLoginPluginVersion, loginUser); err != nil {
+		blog.Errorf("save oidc login user failed, err: %v, rid: %s", err, rid)
+		return "", loginFailedErr(err)
+	}
+
+	return state.RedirectURL, nil
+}
+
+// claimsToUser converts the id token claims to the login user, the role and supplier account are mapped from
+// the groups claim.
+func claimsToUser(conf *config, claims jwt.MapClaims) (*metadata.LoginUserInfo, *errors.RawErrorInfo) {
+	userName := claimString(claims, conf.UsernameClaim)
+	if userName == "" {
+		return nil, loginFailedErr(fmt.Errorf("id token lacks user name claim %s", conf.UsernameClaim))
+	}
+
+	role, ownerID, matched := external.MapGroups(claimStrings(claims, conf.GroupsClaim), conf.GroupMappings)
+	if !matched && conf.RequireGroupMapping {
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginGroupNotAllowed}
+	}
+
+	chName := claimString(claims, conf.DisplayNameClaim)
+	if chName == "" {
+		chName = userName
+	}
+
+	return &metadata.LoginUserInfo{
+		UserName: userName,
+		ChName:   chName,
+		Phone:    claimString(claims, conf.PhoneClaim),
+		Email:    claimString(claims, conf.EmailClaim),
+		Role:     role,
+		OnwerUin: ownerID,
+	}, nil
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings returns the values of an array claim, a string claim is split by comma or space
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	case []string:
+		return value
+	case string:
+		return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	default:
+		return nil
+	}
+}
+
+func getSiteURL(httpScheme string) string {
+	var siteURL string
+	var err error
+	if common.LogoutHTTPSchemeHTTPS == httpScheme {
+		siteURL, err = cc.String("webServer.site.httpsDomainUrl")
+	} else {
+		siteURL, err = cc.String("webServer.site.domainUrl")
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(siteURL, "/")
+}
+
+func loginFailedErr(err error) *errors.RawErrorInfo {
+	return &errors.RawErrorInfo{ErrCode: common.CCErrWebOIDCLoginFailed, Args: []interface{}{err.Error()}}
+}
@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 Tencent. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Service) initDynamicGroupExport(ws *gin.Engine) {
+	ws.GET("/dynamicgroup/export/file/:bk_biz_id/:id/:file_id/download", s.DownloadDynamicGroupExportFile)
+}
+
+var dynamicGroupExportContentType = map[metadata.DynamicGroupExportFormat]string{
+	metadata.DynamicGroupExportCSV:       "text/csv",
+	metadata.DynamicGroupExportJSONLines: "application/x-ndjson",
+	metadata.DynamicGroupExportXLSX:      "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// DownloadDynamicGroupExportFile download the generated dynamic group export file chunk by chunk
+func (s *Service) DownloadDynamicGroupExportFile(c *gin.Context) {
+	kit := rest.NewKitFromHeader(c.Request.Header, s.CCErr)
+
+	bizID, err := strconv.ParseInt(c.Param(common.BKAppIDField), 10, 64)
+	if err != nil {
+		blog.Errorf("parse biz id %s failed, err: %v, rid: %s", c.Param(common.BKAppIDField), err, kit.Rid)
+		c.JSON(http.StatusOK, metadata.BaseResp{Code: common.CCErrCommParamsIsInvalid,
+			ErrMsg: kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField).Error()})
+		return
+	}
+
+	fileID, err := strconv.ParseInt(c.Param("file_id"), 10, 64)
+	if err != nil {
+		blog.Errorf("parse file id %s failed, err: %v, rid: %s", c.Param("file_id"), err, kit.Rid)
+		c.JSON(http.StatusOK, metadata.BaseResp{Code: common.CCErrCommParamsIsInvalid,
+			ErrMsg: kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "file_id").Error()})
+		return
+	}
+	groupID := c.Param(common.BKFieldID)
+
+	opt := &metadata.SearchDynamicGroupExportOption{
+		GroupID: groupID,
+		IDs:     []int64{fileID},
+		Page:    metadata.BasePage{Limit: 1},
+	}
+	files, ccErr := s.ApiCli.SearchDynamicGroupExportFile(kit.Ctx, kit.Header, bizID, opt)
+	if ccErr != nil {
+		blog.Errorf("search dynamic group export file failed, opt: %+v, err: %v, rid: %s", opt, ccErr, kit.Rid)
+		c.JSON(http.StatusOK, metadata.BaseResp{Code: ccErr.GetCode(), ErrMsg: ccErr.Error()})
+		return
+	}
+
+	if len(files.Info) == 0 || files.Info[0].Status != metadata.DynamicGroupExportFinished {
+		blog.Errorf("dynamic group export file %d is not exist or not finished, rid: %s", fileID, kit.Rid)
+		c.JSON(http.StatusOK, metadata.BaseResp{Code: common.CCErrCommNotFound,
+			ErrMsg: kit.CCError.CCError(common.CCErrCommNotFound).Error()})
+		return
+	}
+	file := files.Info[0]
+
+	if file.ChunkCount == 0 {
+		addDynamicGroupExportHeader(c, &file)
+		return
+	}
+
+	for index := int64(0); index < file.ChunkCount; index++ {
+		chunk, ccErr := s.ApiCli.GetDynamicGroupExportFileChunk(kit.Ctx, kit.Header, bizID, groupID, fileID, index)
+		if ccErr != nil {
+			blog.Errorf("get dynamic group export file %d chunk %d failed, err: %v, rid: %s", fileID, index, ccErr,
+				kit.Rid)
+			if index == 0 {
+				c.JSON(http.StatusOK, metadata.BaseResp{Code: ccErr.GetCode(), ErrMsg: ccErr.Error()})
+			}
+			return
+		}
+
+		// the response header is written after the first chunk is got, so that errors can still be responded
+		if index == 0 {
+			addDynamicGroupExportHeader(c, &file)
+		}
+
+		if _, err := c.Writer.Write(chunk.Data); err != nil {
+			blog.Errorf("write dynamic group export file %d chunk %d failed, err: %v, rid: %s", fileID, index, err,
+				kit.Rid)
+			return
+		}
+	}
+}
+
+func addDynamicGroupExportHeader(c *gin.Context, file *metadata.DynamicGroupExportFile) {
+	c.Header("Content-Type", dynamicGroupExportContentType[file.Format])
+	c.Header("Content-Disposition", "attachment; filename="+file.FileName)
+	c.Header("Cache-Control", "must-revalidate, post-check=0, pre-check=0")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+}
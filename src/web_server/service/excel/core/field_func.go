@@ -35,6 +35,7 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/resource/esb"
 	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
 	"configcenter/src/web_server/middleware/user/plugins"
 )
 
@@ -274,7 +275,7 @@ func (d *Client) GetInstWithOrgName(kit *rest.Kit, ccLang language.DefaultCCLang
 func getAllOrganization(kit *rest.Kit, orgIDs []int64) (*metadata.DepartmentData, errors.CCErrorCoder) {
 
 	loginVersion, _ := cc.String("webServer.login.version")
-	if loginVersion == common.BKOpenSourceLoginPluginVersion || loginVersion == common.BKSkipLoginPluginVersion {
+	if webCommon.IsLoginWithoutOrganization(loginVersion) {
 		return &metadata.DepartmentData{}, nil
 	}
 
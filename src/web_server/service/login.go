@@ -17,6 +17,7 @@
 package service
 
 import (
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -24,9 +25,11 @@ import (
 	"configcenter/src/common"
 	cc "configcenter/src/common/backbone/configcenter"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	httpheader "configcenter/src/common/http/header"
 	"configcenter/src/common/metadata"
 	"configcenter/src/web_server/middleware/user"
+	"configcenter/src/web_server/middleware/user/plugins"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -83,7 +86,24 @@ func (s *Service) LoginUser(c *gin.Context) {
 		c.HTML(200, "login.html", gin.H{
 			"error": defErr.CCError(common.CCErrWebNeedFillinUsernamePasswd).Error(),
 		})
+		return
+	}
+
+	// the login plugin such as ldap verifies the user name and password by itself
+	if plugin, ok := plugins.CurrentPlugin(s.Config.LoginVersion).(plugins.PasswordLoginPlugin); ok {
+		if rawErr := plugin.AuthenticateUser(c, userName, password); rawErr != nil {
+			c.HTML(200, "login.html", gin.H{
+				"error": rawErr.ToCCError(defErr).Error(),
+			})
+			return
+		}
+
+		userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli, s.ApiCli)
+		userManger.LoginUser(c)
+		c.Redirect(302, s.parseRedirectURL(c.Query("c_url"), rid))
+		return
 	}
+
 	userInfo, err := cc.String("webServer.session.userInfo")
 	if err != nil {
 		c.HTML(200, "login.html", gin.H{
@@ -121,6 +141,53 @@ func (s *Service) LoginUser(c *gin.Context) {
 	return
 }
 
+// RedirectLogin redirects the user to the identity provider of the login plugin such as oidc
+func (s *Service) RedirectLogin(c *gin.Context) {
+	rid := httpheader.GetRid(c.Request.Header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(c.Request.Header))
+
+	plugin, ok := plugins.CurrentPlugin(s.Config.LoginVersion).(plugins.RedirectLoginPlugin)
+	if !ok {
+		s.respLoginErr(c, defErr.CCErrorf(common.CCErrWebUnknownLoginVersion, s.Config.LoginVersion))
+		return
+	}
+
+	redirectURL := s.parseRedirectURL(c.Query("c_url"), rid)
+	if rawErr := plugin.RedirectLogin(c, redirectURL); rawErr != nil {
+		blog.Errorf("redirect to login failed, err: %s, rid: %s", rawErr.ToCCError(defErr).Error(), rid)
+		s.respLoginErr(c, rawErr.ToCCError(defErr))
+		return
+	}
+}
+
+// LoginCallback handles the request redirected back by the identity provider after the user is authenticated
+func (s *Service) LoginCallback(c *gin.Context) {
+	rid := httpheader.GetRid(c.Request.Header)
+	defErr := s.CCErr.CreateDefaultCCErrorIf(httpheader.GetLanguage(c.Request.Header))
+
+	plugin, ok := plugins.CurrentPlugin(s.Config.LoginVersion).(plugins.RedirectLoginPlugin)
+	if !ok {
+		s.respLoginErr(c, defErr.CCErrorf(common.CCErrWebUnknownLoginVersion, s.Config.LoginVersion))
+		return
+	}
+
+	redirectURL, rawErr := plugin.LoginCallback(c)
+	if rawErr != nil {
+		blog.Errorf("handle login callback failed, err: %s, rid: %s", rawErr.ToCCError(defErr).Error(), rid)
+		s.respLoginErr(c, rawErr.ToCCError(defErr))
+		return
+	}
+
+	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli, s.ApiCli)
+	userManger.LoginUser(c)
+	c.Redirect(302, s.parseRedirectURL(redirectURL, rid))
+}
+
+func (s *Service) respLoginErr(c *gin.Context, err errors.CCErrorCoder) {
+	ret := metadata.BaseResp{Result: false, Code: err.GetCode(), ErrMsg: err.Error()}
+	c.JSON(http.StatusUnauthorized, ret)
+}
+
 func (s *Service) parseRedirectURL(redirectURL, rid string) string {
 	if redirectURL == "" {
 		return s.Config.Site.DomainUrl
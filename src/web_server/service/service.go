@@ -105,6 +105,9 @@ func (s *Service) WebService() *gin.Engine {
 	// resource count, only for ui
 	s.initResourceCount(ws)
 
+	// dynamic group export file download
+	s.initDynamicGroupExport(ws)
+
 	c := &capability.Capability{
 		Ws:        ws,
 		Engine:    s.Engine,
@@ -139,6 +142,8 @@ func (s *Service) initService(ws *gin.Engine) {
 	ws.GET("/login", s.Login)
 	ws.GET("/is_login", s.IsLogin)
 	ws.POST("/login", s.LoginUser)
+	ws.GET("/login/oidc", s.RedirectLogin)
+	ws.GET("/login/oidc/callback", s.LoginCallback)
 	ws.POST("/object/exportmany", s.BatchExportObject)
 	ws.POST("/object/importmany/analysis", s.BatchImportObjectAnalysis)
 	ws.POST("/object/importmany", s.BatchImportObject)